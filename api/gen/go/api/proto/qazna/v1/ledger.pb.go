@@ -22,10 +22,276 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type AccountType int32
+
+const (
+	AccountType_ACCOUNT_TYPE_UNSPECIFIED AccountType = 0
+	AccountType_ACCOUNT_TYPE_RESERVE     AccountType = 1
+	AccountType_ACCOUNT_TYPE_SETTLEMENT  AccountType = 2
+	AccountType_ACCOUNT_TYPE_FEE         AccountType = 3
+	AccountType_ACCOUNT_TYPE_SUSPENSE    AccountType = 4
+)
+
+// Enum value maps for AccountType.
+var (
+	AccountType_name = map[int32]string{
+		0: "ACCOUNT_TYPE_UNSPECIFIED",
+		1: "ACCOUNT_TYPE_RESERVE",
+		2: "ACCOUNT_TYPE_SETTLEMENT",
+		3: "ACCOUNT_TYPE_FEE",
+		4: "ACCOUNT_TYPE_SUSPENSE",
+	}
+	AccountType_value = map[string]int32{
+		"ACCOUNT_TYPE_UNSPECIFIED": 0,
+		"ACCOUNT_TYPE_RESERVE":     1,
+		"ACCOUNT_TYPE_SETTLEMENT":  2,
+		"ACCOUNT_TYPE_FEE":         3,
+		"ACCOUNT_TYPE_SUSPENSE":    4,
+	}
+)
+
+func (x AccountType) Enum() *AccountType {
+	p := new(AccountType)
+	*p = x
+	return p
+}
+
+func (x AccountType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AccountType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_qazna_v1_ledger_proto_enumTypes[0].Descriptor()
+}
+
+func (AccountType) Type() protoreflect.EnumType {
+	return &file_api_proto_qazna_v1_ledger_proto_enumTypes[0]
+}
+
+func (x AccountType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AccountType.Descriptor instead.
+func (AccountType) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{0}
+}
+
+type AccountStatus int32
+
+const (
+	AccountStatus_ACCOUNT_STATUS_UNSPECIFIED AccountStatus = 0
+	AccountStatus_ACCOUNT_STATUS_ACTIVE      AccountStatus = 1
+	AccountStatus_ACCOUNT_STATUS_FROZEN      AccountStatus = 2
+	AccountStatus_ACCOUNT_STATUS_CLOSED      AccountStatus = 3
+)
+
+// Enum value maps for AccountStatus.
+var (
+	AccountStatus_name = map[int32]string{
+		0: "ACCOUNT_STATUS_UNSPECIFIED",
+		1: "ACCOUNT_STATUS_ACTIVE",
+		2: "ACCOUNT_STATUS_FROZEN",
+		3: "ACCOUNT_STATUS_CLOSED",
+	}
+	AccountStatus_value = map[string]int32{
+		"ACCOUNT_STATUS_UNSPECIFIED": 0,
+		"ACCOUNT_STATUS_ACTIVE":      1,
+		"ACCOUNT_STATUS_FROZEN":      2,
+		"ACCOUNT_STATUS_CLOSED":      3,
+	}
+)
+
+func (x AccountStatus) Enum() *AccountStatus {
+	p := new(AccountStatus)
+	*p = x
+	return p
+}
+
+func (x AccountStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AccountStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_qazna_v1_ledger_proto_enumTypes[1].Descriptor()
+}
+
+func (AccountStatus) Type() protoreflect.EnumType {
+	return &file_api_proto_qazna_v1_ledger_proto_enumTypes[1]
+}
+
+func (x AccountStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AccountStatus.Descriptor instead.
+func (AccountStatus) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{1}
+}
+
+type ReversalStatus int32
+
+const (
+	ReversalStatus_REVERSAL_STATUS_UNSPECIFIED        ReversalStatus = 0
+	ReversalStatus_REVERSAL_STATUS_NOT_REVERSED       ReversalStatus = 1
+	ReversalStatus_REVERSAL_STATUS_PARTIALLY_REVERSED ReversalStatus = 2
+	ReversalStatus_REVERSAL_STATUS_REVERSED           ReversalStatus = 3
+)
+
+// Enum value maps for ReversalStatus.
+var (
+	ReversalStatus_name = map[int32]string{
+		0: "REVERSAL_STATUS_UNSPECIFIED",
+		1: "REVERSAL_STATUS_NOT_REVERSED",
+		2: "REVERSAL_STATUS_PARTIALLY_REVERSED",
+		3: "REVERSAL_STATUS_REVERSED",
+	}
+	ReversalStatus_value = map[string]int32{
+		"REVERSAL_STATUS_UNSPECIFIED":        0,
+		"REVERSAL_STATUS_NOT_REVERSED":       1,
+		"REVERSAL_STATUS_PARTIALLY_REVERSED": 2,
+		"REVERSAL_STATUS_REVERSED":           3,
+	}
+)
+
+func (x ReversalStatus) Enum() *ReversalStatus {
+	p := new(ReversalStatus)
+	*p = x
+	return p
+}
+
+func (x ReversalStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReversalStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_qazna_v1_ledger_proto_enumTypes[2].Descriptor()
+}
+
+func (ReversalStatus) Type() protoreflect.EnumType {
+	return &file_api_proto_qazna_v1_ledger_proto_enumTypes[2]
+}
+
+func (x ReversalStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReversalStatus.Descriptor instead.
+func (ReversalStatus) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{2}
+}
+
+type EntryDirection int32
+
+const (
+	EntryDirection_ENTRY_DIRECTION_UNSPECIFIED EntryDirection = 0
+	EntryDirection_ENTRY_DIRECTION_DEBIT       EntryDirection = 1
+	EntryDirection_ENTRY_DIRECTION_CREDIT      EntryDirection = 2
+)
+
+// Enum value maps for EntryDirection.
+var (
+	EntryDirection_name = map[int32]string{
+		0: "ENTRY_DIRECTION_UNSPECIFIED",
+		1: "ENTRY_DIRECTION_DEBIT",
+		2: "ENTRY_DIRECTION_CREDIT",
+	}
+	EntryDirection_value = map[string]int32{
+		"ENTRY_DIRECTION_UNSPECIFIED": 0,
+		"ENTRY_DIRECTION_DEBIT":       1,
+		"ENTRY_DIRECTION_CREDIT":      2,
+	}
+)
+
+func (x EntryDirection) Enum() *EntryDirection {
+	p := new(EntryDirection)
+	*p = x
+	return p
+}
+
+func (x EntryDirection) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EntryDirection) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_qazna_v1_ledger_proto_enumTypes[3].Descriptor()
+}
+
+func (EntryDirection) Type() protoreflect.EnumType {
+	return &file_api_proto_qazna_v1_ledger_proto_enumTypes[3]
+}
+
+func (x EntryDirection) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EntryDirection.Descriptor instead.
+func (EntryDirection) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{3}
+}
+
+type HoldStatus int32
+
+const (
+	HoldStatus_HOLD_STATUS_UNSPECIFIED HoldStatus = 0
+	HoldStatus_HOLD_STATUS_PENDING     HoldStatus = 1
+	HoldStatus_HOLD_STATUS_CAPTURED    HoldStatus = 2
+	HoldStatus_HOLD_STATUS_VOIDED      HoldStatus = 3
+	HoldStatus_HOLD_STATUS_EXPIRED     HoldStatus = 4
+)
+
+// Enum value maps for HoldStatus.
+var (
+	HoldStatus_name = map[int32]string{
+		0: "HOLD_STATUS_UNSPECIFIED",
+		1: "HOLD_STATUS_PENDING",
+		2: "HOLD_STATUS_CAPTURED",
+		3: "HOLD_STATUS_VOIDED",
+		4: "HOLD_STATUS_EXPIRED",
+	}
+	HoldStatus_value = map[string]int32{
+		"HOLD_STATUS_UNSPECIFIED": 0,
+		"HOLD_STATUS_PENDING":     1,
+		"HOLD_STATUS_CAPTURED":    2,
+		"HOLD_STATUS_VOIDED":      3,
+		"HOLD_STATUS_EXPIRED":     4,
+	}
+)
+
+func (x HoldStatus) Enum() *HoldStatus {
+	p := new(HoldStatus)
+	*p = x
+	return p
+}
+
+func (x HoldStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HoldStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_qazna_v1_ledger_proto_enumTypes[4].Descriptor()
+}
+
+func (HoldStatus) Type() protoreflect.EnumType {
+	return &file_api_proto_qazna_v1_ledger_proto_enumTypes[4]
+}
+
+func (x HoldStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HoldStatus.Descriptor instead.
+func (HoldStatus) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{4}
+}
+
 type CreateAccountRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Currency      string                 `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
 	InitialAmount int64                  `protobuf:"varint,2,opt,name=initial_amount,json=initialAmount,proto3" json:"initial_amount,omitempty"`
+	Type          AccountType            `protobuf:"varint,3,opt,name=type,proto3,enum=qazna.v1.AccountType" json:"type,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,4,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	ExternalRef   string                 `protobuf:"bytes,5,opt,name=external_ref,json=externalRef,proto3" json:"external_ref,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -74,13 +340,39 @@ func (x *CreateAccountRequest) GetInitialAmount() int64 {
 	return 0
 }
 
+func (x *CreateAccountRequest) GetType() AccountType {
+	if x != nil {
+		return x.Type
+	}
+	return AccountType_ACCOUNT_TYPE_UNSPECIFIED
+}
+
+func (x *CreateAccountRequest) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetExternalRef() string {
+	if x != nil {
+		return x.ExternalRef
+	}
+	return ""
+}
+
 type Account struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Balances      map[string]int64       `protobuf:"bytes,3,rep,name=balances,proto3" json:"balances,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Balances       map[string]int64       `protobuf:"bytes,3,rep,name=balances,proto3" json:"balances,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	OrganizationId string                 `protobuf:"bytes,4,opt,name=organization_id,json=organizationId,proto3" json:"organization_id,omitempty"`
+	Type           AccountType            `protobuf:"varint,5,opt,name=type,proto3,enum=qazna.v1.AccountType" json:"type,omitempty"`
+	DisplayName    string                 `protobuf:"bytes,6,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	ExternalRef    string                 `protobuf:"bytes,7,opt,name=external_ref,json=externalRef,proto3" json:"external_ref,omitempty"`
+	Status         AccountStatus          `protobuf:"varint,8,opt,name=status,proto3,enum=qazna.v1.AccountStatus" json:"status,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Account) Reset() {
@@ -134,6 +426,93 @@ func (x *Account) GetBalances() map[string]int64 {
 	return nil
 }
 
+func (x *Account) GetOrganizationId() string {
+	if x != nil {
+		return x.OrganizationId
+	}
+	return ""
+}
+
+func (x *Account) GetType() AccountType {
+	if x != nil {
+		return x.Type
+	}
+	return AccountType_ACCOUNT_TYPE_UNSPECIFIED
+}
+
+func (x *Account) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *Account) GetExternalRef() string {
+	if x != nil {
+		return x.ExternalRef
+	}
+	return ""
+}
+
+func (x *Account) GetStatus() AccountStatus {
+	if x != nil {
+		return x.Status
+	}
+	return AccountStatus_ACCOUNT_STATUS_UNSPECIFIED
+}
+
+type SetAccountStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status        AccountStatus          `protobuf:"varint,2,opt,name=status,proto3,enum=qazna.v1.AccountStatus" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAccountStatusRequest) Reset() {
+	*x = SetAccountStatusRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAccountStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAccountStatusRequest) ProtoMessage() {}
+
+func (x *SetAccountStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAccountStatusRequest.ProtoReflect.Descriptor instead.
+func (*SetAccountStatusRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SetAccountStatusRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SetAccountStatusRequest) GetStatus() AccountStatus {
+	if x != nil {
+		return x.Status
+	}
+	return AccountStatus_ACCOUNT_STATUS_UNSPECIFIED
+}
+
 type TransferRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	FromId         string                 `protobuf:"bytes,1,opt,name=from_id,json=fromId,proto3" json:"from_id,omitempty"`
@@ -147,7 +526,7 @@ type TransferRequest struct {
 
 func (x *TransferRequest) Reset() {
 	*x = TransferRequest{}
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[2]
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -159,7 +538,7 @@ func (x *TransferRequest) String() string {
 func (*TransferRequest) ProtoMessage() {}
 
 func (x *TransferRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[2]
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -172,7 +551,7 @@ func (x *TransferRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TransferRequest.ProtoReflect.Descriptor instead.
 func (*TransferRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{2}
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *TransferRequest) GetFromId() string {
@@ -219,7 +598,7 @@ type TransferResponse struct {
 
 func (x *TransferResponse) Reset() {
 	*x = TransferResponse{}
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[3]
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -231,7 +610,7 @@ func (x *TransferResponse) String() string {
 func (*TransferResponse) ProtoMessage() {}
 
 func (x *TransferResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[3]
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -244,7 +623,7 @@ func (x *TransferResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TransferResponse.ProtoReflect.Descriptor instead.
 func (*TransferResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{3}
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *TransferResponse) GetTransaction() *Transaction {
@@ -264,13 +643,22 @@ type Transaction struct {
 	Amount         int64                  `protobuf:"varint,6,opt,name=amount,proto3" json:"amount,omitempty"`
 	IdempotencyKey string                 `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
 	Sequence       uint64                 `protobuf:"varint,8,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Entries        []*Entry               `protobuf:"bytes,9,rep,name=entries,proto3" json:"entries,omitempty"`
+	ReversalOf     string                 `protobuf:"bytes,10,opt,name=reversal_of,json=reversalOf,proto3" json:"reversal_of,omitempty"`
+	ReversalReason string                 `protobuf:"bytes,11,opt,name=reversal_reason,json=reversalReason,proto3" json:"reversal_reason,omitempty"`
+	ReversedAmount int64                  `protobuf:"varint,12,opt,name=reversed_amount,json=reversedAmount,proto3" json:"reversed_amount,omitempty"`
+	ReversalStatus ReversalStatus         `protobuf:"varint,13,opt,name=reversal_status,json=reversalStatus,proto3,enum=qazna.v1.ReversalStatus" json:"reversal_status,omitempty"`
+	FxRateId       string                 `protobuf:"bytes,14,opt,name=fx_rate_id,json=fxRateId,proto3" json:"fx_rate_id,omitempty"`
+	FxRate         string                 `protobuf:"bytes,15,opt,name=fx_rate,json=fxRate,proto3" json:"fx_rate,omitempty"`
+	Kind           string                 `protobuf:"bytes,16,opt,name=kind,proto3" json:"kind,omitempty"`
+	Fee            *Fee                   `protobuf:"bytes,17,opt,name=fee,proto3" json:"fee,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Transaction) Reset() {
 	*x = Transaction{}
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[4]
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -282,7 +670,7 @@ func (x *Transaction) String() string {
 func (*Transaction) ProtoMessage() {}
 
 func (x *Transaction) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[4]
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -295,7 +683,7 @@ func (x *Transaction) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
 func (*Transaction) Descriptor() ([]byte, []int) {
-	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{4}
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Transaction) GetId() string {
@@ -354,81 +742,1171 @@ func (x *Transaction) GetSequence() uint64 {
 	return 0
 }
 
-type ListTransactionsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AfterSequence uint64                 `protobuf:"varint,1,opt,name=after_sequence,json=afterSequence,proto3" json:"after_sequence,omitempty"`
-	Limit         uint32                 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *ListTransactionsRequest) Reset() {
-	*x = ListTransactionsRequest{}
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[5]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *ListTransactionsRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Transaction) GetEntries() []*Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
 }
 
-func (*ListTransactionsRequest) ProtoMessage() {}
-
-func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[5]
+func (x *Transaction) GetReversalOf() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.ReversalOf
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
-func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{5}
+func (x *Transaction) GetReversalReason() string {
+	if x != nil {
+		return x.ReversalReason
+	}
+	return ""
 }
 
-func (x *ListTransactionsRequest) GetAfterSequence() uint64 {
+func (x *Transaction) GetReversedAmount() int64 {
 	if x != nil {
-		return x.AfterSequence
+		return x.ReversedAmount
 	}
 	return 0
 }
 
-func (x *ListTransactionsRequest) GetLimit() uint32 {
+func (x *Transaction) GetReversalStatus() ReversalStatus {
 	if x != nil {
-		return x.Limit
+		return x.ReversalStatus
 	}
-	return 0
+	return ReversalStatus_REVERSAL_STATUS_UNSPECIFIED
 }
 
-type ListTransactionsResponse struct {
+func (x *Transaction) GetFxRateId() string {
+	if x != nil {
+		return x.FxRateId
+	}
+	return ""
+}
+
+func (x *Transaction) GetFxRate() string {
+	if x != nil {
+		return x.FxRate
+	}
+	return ""
+}
+
+func (x *Transaction) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Transaction) GetFee() *Fee {
+	if x != nil {
+		return x.Fee
+	}
+	return nil
+}
+
+type Fee struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ScheduleVersion int32                  `protobuf:"varint,1,opt,name=schedule_version,json=scheduleVersion,proto3" json:"schedule_version,omitempty"`
+	ParticipantType string                 `protobuf:"bytes,2,opt,name=participant_type,json=participantType,proto3" json:"participant_type,omitempty"`
+	Rate            string                 `protobuf:"bytes,3,opt,name=rate,proto3" json:"rate,omitempty"`
+	Alpha           string                 `protobuf:"bytes,4,opt,name=alpha,proto3" json:"alpha,omitempty"`
+	Beta            string                 `protobuf:"bytes,5,opt,name=beta,proto3" json:"beta,omitempty"`
+	Rounding        string                 `protobuf:"bytes,6,opt,name=rounding,proto3" json:"rounding,omitempty"`
+	AccountId       string                 `protobuf:"bytes,7,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Currency        string                 `protobuf:"bytes,8,opt,name=currency,proto3" json:"currency,omitempty"`
+	Amount          int64                  `protobuf:"varint,9,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Fee) Reset() {
+	*x = Fee{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fee) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fee) ProtoMessage() {}
+
+func (x *Fee) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fee.ProtoReflect.Descriptor instead.
+func (*Fee) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Fee) GetScheduleVersion() int32 {
+	if x != nil {
+		return x.ScheduleVersion
+	}
+	return 0
+}
+
+func (x *Fee) GetParticipantType() string {
+	if x != nil {
+		return x.ParticipantType
+	}
+	return ""
+}
+
+func (x *Fee) GetRate() string {
+	if x != nil {
+		return x.Rate
+	}
+	return ""
+}
+
+func (x *Fee) GetAlpha() string {
+	if x != nil {
+		return x.Alpha
+	}
+	return ""
+}
+
+func (x *Fee) GetBeta() string {
+	if x != nil {
+		return x.Beta
+	}
+	return ""
+}
+
+func (x *Fee) GetRounding() string {
+	if x != nil {
+		return x.Rounding
+	}
+	return ""
+}
+
+func (x *Fee) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *Fee) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Fee) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type ReverseRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId  string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Amount         int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Reason         string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReverseRequest) Reset() {
+	*x = ReverseRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseRequest) ProtoMessage() {}
+
+func (x *ReverseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseRequest.ProtoReflect.Descriptor instead.
+func (*ReverseRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ReverseRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ReverseRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ReverseRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ReverseRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type ReverseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReverseResponse) Reset() {
+	*x = ReverseResponse{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseResponse) ProtoMessage() {}
+
+func (x *ReverseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseResponse.ProtoReflect.Descriptor instead.
+func (*ReverseResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReverseResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+type FXTransferRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FromId         string                 `protobuf:"bytes,1,opt,name=from_id,json=fromId,proto3" json:"from_id,omitempty"`
+	ToId           string                 `protobuf:"bytes,2,opt,name=to_id,json=toId,proto3" json:"to_id,omitempty"`
+	Currency       string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	Amount         int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	TargetCurrency string                 `protobuf:"bytes,5,opt,name=target_currency,json=targetCurrency,proto3" json:"target_currency,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FXTransferRequest) Reset() {
+	*x = FXTransferRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FXTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FXTransferRequest) ProtoMessage() {}
+
+func (x *FXTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FXTransferRequest.ProtoReflect.Descriptor instead.
+func (*FXTransferRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FXTransferRequest) GetFromId() string {
+	if x != nil {
+		return x.FromId
+	}
+	return ""
+}
+
+func (x *FXTransferRequest) GetToId() string {
+	if x != nil {
+		return x.ToId
+	}
+	return ""
+}
+
+func (x *FXTransferRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *FXTransferRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *FXTransferRequest) GetTargetCurrency() string {
+	if x != nil {
+		return x.TargetCurrency
+	}
+	return ""
+}
+
+func (x *FXTransferRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type FXTransferResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FXTransferResponse) Reset() {
+	*x = FXTransferResponse{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FXTransferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FXTransferResponse) ProtoMessage() {}
+
+func (x *FXTransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FXTransferResponse.ProtoReflect.Descriptor instead.
+func (*FXTransferResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FXTransferResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+type Entry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Direction     EntryDirection         `protobuf:"varint,2,opt,name=direction,proto3,enum=qazna.v1.EntryDirection" json:"direction,omitempty"`
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	Amount        int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Entry) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *Entry) GetDirection() EntryDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return EntryDirection_ENTRY_DIRECTION_UNSPECIFIED
+}
+
+func (x *Entry) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Entry) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type PostEntriesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Entries        []*Entry               `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PostEntriesRequest) Reset() {
+	*x = PostEntriesRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PostEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PostEntriesRequest) ProtoMessage() {}
+
+func (x *PostEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PostEntriesRequest.ProtoReflect.Descriptor instead.
+func (*PostEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PostEntriesRequest) GetEntries() []*Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *PostEntriesRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type PostEntriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PostEntriesResponse) Reset() {
+	*x = PostEntriesResponse{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PostEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PostEntriesResponse) ProtoMessage() {}
+
+func (x *PostEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PostEntriesResponse.ProtoReflect.Descriptor instead.
+func (*PostEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PostEntriesResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+type ListTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AfterSequence uint64                 `protobuf:"varint,1,opt,name=after_sequence,json=afterSequence,proto3" json:"after_sequence,omitempty"`
+	Limit         uint32                 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsRequest) Reset() {
+	*x = ListTransactionsRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsRequest) ProtoMessage() {}
+
+func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListTransactionsRequest) GetAfterSequence() uint64 {
+	if x != nil {
+		return x.AfterSequence
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Transaction         `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	NextAfter     uint64                 `protobuf:"varint,2,opt,name=next_after,json=nextAfter,proto3" json:"next_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsResponse) Reset() {
+	*x = ListTransactionsResponse{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsResponse) ProtoMessage() {}
+
+func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListTransactionsResponse) GetItems() []*Transaction {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListTransactionsResponse) GetNextAfter() uint64 {
+	if x != nil {
+		return x.NextAfter
+	}
+	return 0
+}
+
+type ListAccountTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Direction     EntryDirection         `protobuf:"varint,2,opt,name=direction,proto3,enum=qazna.v1.EntryDirection" json:"direction,omitempty"`
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	AfterSequence uint64                 `protobuf:"varint,6,opt,name=after_sequence,json=afterSequence,proto3" json:"after_sequence,omitempty"`
+	Limit         uint32                 `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccountTransactionsRequest) Reset() {
+	*x = ListAccountTransactionsRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccountTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccountTransactionsRequest) ProtoMessage() {}
+
+func (x *ListAccountTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccountTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListAccountTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListAccountTransactionsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ListAccountTransactionsRequest) GetDirection() EntryDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return EntryDirection_ENTRY_DIRECTION_UNSPECIFIED
+}
+
+func (x *ListAccountTransactionsRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ListAccountTransactionsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListAccountTransactionsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ListAccountTransactionsRequest) GetAfterSequence() uint64 {
+	if x != nil {
+		return x.AfterSequence
+	}
+	return 0
+}
+
+func (x *ListAccountTransactionsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetAccountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountRequest) Reset() {
+	*x = GetAccountRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountRequest) ProtoMessage() {}
+
+func (x *GetAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetAccountRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceRequest) Reset() {
+	*x = GetBalanceRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceRequest) ProtoMessage() {}
+
+func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetBalanceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetBalanceRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type Balance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Currency      string                 `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Held          int64                  `protobuf:"varint,3,opt,name=held,proto3" json:"held,omitempty"`
+	Available     int64                  `protobuf:"varint,4,opt,name=available,proto3" json:"available,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Balance) Reset() {
+	*x = Balance{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Balance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Balance) ProtoMessage() {}
+
+func (x *Balance) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Balance.ProtoReflect.Descriptor instead.
+func (*Balance) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Balance) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Balance) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Balance) GetHeld() int64 {
+	if x != nil {
+		return x.Held
+	}
+	return 0
+}
+
+func (x *Balance) GetAvailable() int64 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+type Hold struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	FromAccountId  string                 `protobuf:"bytes,4,opt,name=from_account_id,json=fromAccountId,proto3" json:"from_account_id,omitempty"`
+	ToAccountId    string                 `protobuf:"bytes,5,opt,name=to_account_id,json=toAccountId,proto3" json:"to_account_id,omitempty"`
+	Currency       string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	Amount         int64                  `protobuf:"varint,7,opt,name=amount,proto3" json:"amount,omitempty"`
+	CapturedAmount int64                  `protobuf:"varint,8,opt,name=captured_amount,json=capturedAmount,proto3" json:"captured_amount,omitempty"`
+	Status         HoldStatus             `protobuf:"varint,9,opt,name=status,proto3,enum=qazna.v1.HoldStatus" json:"status,omitempty"`
+	TransactionId  string                 `protobuf:"bytes,10,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,11,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Hold) Reset() {
+	*x = Hold{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hold) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hold) ProtoMessage() {}
+
+func (x *Hold) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hold.ProtoReflect.Descriptor instead.
+func (*Hold) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Hold) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Hold) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Hold) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Hold) GetFromAccountId() string {
+	if x != nil {
+		return x.FromAccountId
+	}
+	return ""
+}
+
+func (x *Hold) GetToAccountId() string {
+	if x != nil {
+		return x.ToAccountId
+	}
+	return ""
+}
+
+func (x *Hold) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Hold) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Hold) GetCapturedAmount() int64 {
+	if x != nil {
+		return x.CapturedAmount
+	}
+	return 0
+}
+
+func (x *Hold) GetStatus() HoldStatus {
+	if x != nil {
+		return x.Status
+	}
+	return HoldStatus_HOLD_STATUS_UNSPECIFIED
+}
+
+func (x *Hold) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *Hold) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type CreateHoldRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FromId         string                 `protobuf:"bytes,1,opt,name=from_id,json=fromId,proto3" json:"from_id,omitempty"`
+	ToId           string                 `protobuf:"bytes,2,opt,name=to_id,json=toId,proto3" json:"to_id,omitempty"`
+	Currency       string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	Amount         int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	TtlSeconds     uint32                 `protobuf:"varint,5,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateHoldRequest) Reset() {
+	*x = CreateHoldRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateHoldRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateHoldRequest) ProtoMessage() {}
+
+func (x *CreateHoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateHoldRequest.ProtoReflect.Descriptor instead.
+func (*CreateHoldRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CreateHoldRequest) GetFromId() string {
+	if x != nil {
+		return x.FromId
+	}
+	return ""
+}
+
+func (x *CreateHoldRequest) GetToId() string {
+	if x != nil {
+		return x.ToId
+	}
+	return ""
+}
+
+func (x *CreateHoldRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *CreateHoldRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CreateHoldRequest) GetTtlSeconds() uint32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *CreateHoldRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type GetHoldRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Items         []*Transaction         `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
-	NextAfter     uint64                 `protobuf:"varint,2,opt,name=next_after,json=nextAfter,proto3" json:"next_after,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListTransactionsResponse) Reset() {
-	*x = ListTransactionsResponse{}
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[6]
+func (x *GetHoldRequest) Reset() {
+	*x = GetHoldRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListTransactionsResponse) String() string {
+func (x *GetHoldRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListTransactionsResponse) ProtoMessage() {}
+func (*GetHoldRequest) ProtoMessage() {}
 
-func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[6]
+func (x *GetHoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -439,47 +1917,144 @@ func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
-func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use GetHoldRequest.ProtoReflect.Descriptor instead.
+func (*GetHoldRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *ListTransactionsResponse) GetItems() []*Transaction {
+func (x *GetHoldRequest) GetId() string {
 	if x != nil {
-		return x.Items
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-func (x *ListTransactionsResponse) GetNextAfter() uint64 {
+type CaptureHoldRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureHoldRequest) Reset() {
+	*x = CaptureHoldRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureHoldRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureHoldRequest) ProtoMessage() {}
+
+func (x *CaptureHoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[23]
 	if x != nil {
-		return x.NextAfter
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureHoldRequest.ProtoReflect.Descriptor instead.
+func (*CaptureHoldRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CaptureHoldRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CaptureHoldRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
 	}
 	return 0
 }
 
-type GetAccountRequest struct {
+type CaptureHoldResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hold          *Hold                  `protobuf:"bytes,1,opt,name=hold,proto3" json:"hold,omitempty"`
+	Transaction   *Transaction           `protobuf:"bytes,2,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureHoldResponse) Reset() {
+	*x = CaptureHoldResponse{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureHoldResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureHoldResponse) ProtoMessage() {}
+
+func (x *CaptureHoldResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureHoldResponse.ProtoReflect.Descriptor instead.
+func (*CaptureHoldResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CaptureHoldResponse) GetHold() *Hold {
+	if x != nil {
+		return x.Hold
+	}
+	return nil
+}
+
+func (x *CaptureHoldResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+type VoidHoldRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAccountRequest) Reset() {
-	*x = GetAccountRequest{}
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[7]
+func (x *VoidHoldRequest) Reset() {
+	*x = VoidHoldRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAccountRequest) String() string {
+func (x *VoidHoldRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAccountRequest) ProtoMessage() {}
+func (*VoidHoldRequest) ProtoMessage() {}
 
-func (x *GetAccountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[7]
+func (x *VoidHoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -490,41 +2065,43 @@ func (x *GetAccountRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAccountRequest.ProtoReflect.Descriptor instead.
-func (*GetAccountRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use VoidHoldRequest.ProtoReflect.Descriptor instead.
+func (*VoidHoldRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *GetAccountRequest) GetId() string {
+func (x *VoidHoldRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-type GetBalanceRequest struct {
+type GetBalanceAtRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	AsOfSequence  uint64                 `protobuf:"varint,3,opt,name=as_of_sequence,json=asOfSequence,proto3" json:"as_of_sequence,omitempty"`
+	AsOfTime      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=as_of_time,json=asOfTime,proto3" json:"as_of_time,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetBalanceRequest) Reset() {
-	*x = GetBalanceRequest{}
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[8]
+func (x *GetBalanceAtRequest) Reset() {
+	*x = GetBalanceAtRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetBalanceRequest) String() string {
+func (x *GetBalanceAtRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetBalanceRequest) ProtoMessage() {}
+func (*GetBalanceAtRequest) ProtoMessage() {}
 
-func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[8]
+func (x *GetBalanceAtRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -535,48 +2112,63 @@ func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
-func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use GetBalanceAtRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceAtRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *GetBalanceRequest) GetId() string {
+func (x *GetBalanceAtRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-func (x *GetBalanceRequest) GetCurrency() string {
+func (x *GetBalanceAtRequest) GetCurrency() string {
 	if x != nil {
 		return x.Currency
 	}
 	return ""
 }
 
-type Balance struct {
+func (x *GetBalanceAtRequest) GetAsOfSequence() uint64 {
+	if x != nil {
+		return x.AsOfSequence
+	}
+	return 0
+}
+
+func (x *GetBalanceAtRequest) GetAsOfTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOfTime
+	}
+	return nil
+}
+
+type HistoricalBalance struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Currency      string                 `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
 	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Sequence      uint64                 `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Balance) Reset() {
-	*x = Balance{}
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[9]
+func (x *HistoricalBalance) Reset() {
+	*x = HistoricalBalance{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Balance) String() string {
+func (x *HistoricalBalance) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Balance) ProtoMessage() {}
+func (*HistoricalBalance) ProtoMessage() {}
 
-func (x *Balance) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[9]
+func (x *HistoricalBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -587,41 +2179,127 @@ func (x *Balance) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Balance.ProtoReflect.Descriptor instead.
-func (*Balance) Descriptor() ([]byte, []int) {
-	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use HistoricalBalance.ProtoReflect.Descriptor instead.
+func (*HistoricalBalance) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *Balance) GetCurrency() string {
+func (x *HistoricalBalance) GetCurrency() string {
 	if x != nil {
 		return x.Currency
 	}
 	return ""
 }
 
-func (x *Balance) GetAmount() int64 {
+func (x *HistoricalBalance) GetAmount() int64 {
 	if x != nil {
 		return x.Amount
 	}
 	return 0
 }
 
+func (x *HistoricalBalance) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+type WatchTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AfterSequence uint64                 `protobuf:"varint,1,opt,name=after_sequence,json=afterSequence,proto3" json:"after_sequence,omitempty"`
+	AccountId     string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Direction     EntryDirection         `protobuf:"varint,3,opt,name=direction,proto3,enum=qazna.v1.EntryDirection" json:"direction,omitempty"`
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTransactionsRequest) Reset() {
+	*x = WatchTransactionsRequest{}
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTransactionsRequest) ProtoMessage() {}
+
+func (x *WatchTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_qazna_v1_ledger_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*WatchTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_qazna_v1_ledger_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *WatchTransactionsRequest) GetAfterSequence() uint64 {
+	if x != nil {
+		return x.AfterSequence
+	}
+	return 0
+}
+
+func (x *WatchTransactionsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *WatchTransactionsRequest) GetDirection() EntryDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return EntryDirection_ENTRY_DIRECTION_UNSPECIFIED
+}
+
+func (x *WatchTransactionsRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
 var File_api_proto_qazna_v1_ledger_proto protoreflect.FileDescriptor
 
 const file_api_proto_qazna_v1_ledger_proto_rawDesc = "" +
 	"\n" +
-	"\x1fapi/proto/qazna/v1/ledger.proto\x12\bqazna.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"Y\n" +
+	"\x1fapi/proto/qazna/v1/ledger.proto\x12\bqazna.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xca\x01\n" +
 	"\x14CreateAccountRequest\x12\x1a\n" +
 	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12%\n" +
-	"\x0einitial_amount\x18\x02 \x01(\x03R\rinitialAmount\"\xce\x01\n" +
+	"\x0einitial_amount\x18\x02 \x01(\x03R\rinitialAmount\x12)\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x15.qazna.v1.AccountTypeR\x04type\x12!\n" +
+	"\fdisplay_name\x18\x04 \x01(\tR\vdisplayName\x12!\n" +
+	"\fexternal_ref\x18\x05 \x01(\tR\vexternalRef\"\x99\x03\n" +
 	"\aAccount\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
 	"\n" +
 	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12;\n" +
-	"\bbalances\x18\x03 \x03(\v2\x1f.qazna.v1.Account.BalancesEntryR\bbalances\x1a;\n" +
+	"\bbalances\x18\x03 \x03(\v2\x1f.qazna.v1.Account.BalancesEntryR\bbalances\x12'\n" +
+	"\x0forganization_id\x18\x04 \x01(\tR\x0eorganizationId\x12)\n" +
+	"\x04type\x18\x05 \x01(\x0e2\x15.qazna.v1.AccountTypeR\x04type\x12!\n" +
+	"\fdisplay_name\x18\x06 \x01(\tR\vdisplayName\x12!\n" +
+	"\fexternal_ref\x18\a \x01(\tR\vexternalRef\x12/\n" +
+	"\x06status\x18\b \x01(\x0e2\x17.qazna.v1.AccountStatusR\x06status\x1a;\n" +
 	"\rBalancesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x9c\x01\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"Z\n" +
+	"\x17SetAccountStatusRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12/\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x17.qazna.v1.AccountStatusR\x06status\"\x9c\x01\n" +
 	"\x0fTransferRequest\x12\x17\n" +
 	"\afrom_id\x18\x01 \x01(\tR\x06fromId\x12\x13\n" +
 	"\x05to_id\x18\x02 \x01(\tR\x04toId\x12\x1a\n" +
@@ -629,7 +2307,7 @@ const file_api_proto_qazna_v1_ledger_proto_rawDesc = "" +
 	"\x06amount\x18\x04 \x01(\x03R\x06amount\x12'\n" +
 	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\"K\n" +
 	"\x10TransferResponse\x127\n" +
-	"\vtransaction\x18\x01 \x01(\v2\x15.qazna.v1.TransactionR\vtransaction\"\x9d\x02\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x15.qazna.v1.TransactionR\vtransaction\"\xea\x04\n" +
 	"\vTransaction\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
 	"\n" +
@@ -639,22 +2317,159 @@ const file_api_proto_qazna_v1_ledger_proto_rawDesc = "" +
 	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12\x16\n" +
 	"\x06amount\x18\x06 \x01(\x03R\x06amount\x12'\n" +
 	"\x0fidempotency_key\x18\a \x01(\tR\x0eidempotencyKey\x12\x1a\n" +
-	"\bsequence\x18\b \x01(\x04R\bsequence\"V\n" +
+	"\bsequence\x18\b \x01(\x04R\bsequence\x12)\n" +
+	"\aentries\x18\t \x03(\v2\x0f.qazna.v1.EntryR\aentries\x12\x1f\n" +
+	"\vreversal_of\x18\n" +
+	" \x01(\tR\n" +
+	"reversalOf\x12'\n" +
+	"\x0freversal_reason\x18\v \x01(\tR\x0ereversalReason\x12'\n" +
+	"\x0freversed_amount\x18\f \x01(\x03R\x0ereversedAmount\x12A\n" +
+	"\x0freversal_status\x18\r \x01(\x0e2\x18.qazna.v1.ReversalStatusR\x0ereversalStatus\x12\x1c\n" +
+	"\n" +
+	"fx_rate_id\x18\x0e \x01(\tR\bfxRateId\x12\x17\n" +
+	"\afx_rate\x18\x0f \x01(\tR\x06fxRate\x12\x12\n" +
+	"\x04kind\x18\x10 \x01(\tR\x04kind\x12\x1f\n" +
+	"\x03fee\x18\x11 \x01(\v2\r.qazna.v1.FeeR\x03fee\"\x88\x02\n" +
+	"\x03Fee\x12)\n" +
+	"\x10schedule_version\x18\x01 \x01(\x05R\x0fscheduleVersion\x12)\n" +
+	"\x10participant_type\x18\x02 \x01(\tR\x0fparticipantType\x12\x12\n" +
+	"\x04rate\x18\x03 \x01(\tR\x04rate\x12\x14\n" +
+	"\x05alpha\x18\x04 \x01(\tR\x05alpha\x12\x12\n" +
+	"\x04beta\x18\x05 \x01(\tR\x04beta\x12\x1a\n" +
+	"\brounding\x18\x06 \x01(\tR\brounding\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\a \x01(\tR\taccountId\x12\x1a\n" +
+	"\bcurrency\x18\b \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06amount\x18\t \x01(\x03R\x06amount\"\x90\x01\n" +
+	"\x0eReverseRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\"J\n" +
+	"\x0fReverseResponse\x127\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x15.qazna.v1.TransactionR\vtransaction\"\xc7\x01\n" +
+	"\x11FXTransferRequest\x12\x17\n" +
+	"\afrom_id\x18\x01 \x01(\tR\x06fromId\x12\x13\n" +
+	"\x05to_id\x18\x02 \x01(\tR\x04toId\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\x12'\n" +
+	"\x0ftarget_currency\x18\x05 \x01(\tR\x0etargetCurrency\x12'\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tR\x0eidempotencyKey\"M\n" +
+	"\x12FXTransferResponse\x127\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x15.qazna.v1.TransactionR\vtransaction\"\x92\x01\n" +
+	"\x05Entry\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x126\n" +
+	"\tdirection\x18\x02 \x01(\x0e2\x18.qazna.v1.EntryDirectionR\tdirection\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\"h\n" +
+	"\x12PostEntriesRequest\x12)\n" +
+	"\aentries\x18\x01 \x03(\v2\x0f.qazna.v1.EntryR\aentries\x12'\n" +
+	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKey\"N\n" +
+	"\x13PostEntriesResponse\x127\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x15.qazna.v1.TransactionR\vtransaction\"V\n" +
 	"\x17ListTransactionsRequest\x12%\n" +
 	"\x0eafter_sequence\x18\x01 \x01(\x04R\rafterSequence\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\rR\x05limit\"f\n" +
 	"\x18ListTransactionsResponse\x12+\n" +
 	"\x05items\x18\x01 \x03(\v2\x15.qazna.v1.TransactionR\x05items\x12\x1d\n" +
 	"\n" +
-	"next_after\x18\x02 \x01(\x04R\tnextAfter\"#\n" +
+	"next_after\x18\x02 \x01(\x04R\tnextAfter\"\xac\x02\n" +
+	"\x1eListAccountTransactionsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x126\n" +
+	"\tdirection\x18\x02 \x01(\x0e2\x18.qazna.v1.EntryDirectionR\tdirection\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12.\n" +
+	"\x04from\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12%\n" +
+	"\x0eafter_sequence\x18\x06 \x01(\x04R\rafterSequence\x12\x14\n" +
+	"\x05limit\x18\a \x01(\rR\x05limit\"#\n" +
 	"\x11GetAccountRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"?\n" +
 	"\x11GetBalanceRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
-	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"=\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"o\n" +
 	"\aBalance\x12\x1a\n" +
 	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12\x16\n" +
-	"\x06amount\x18\x02 \x01(\x03R\x06amount2\xed\x02\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x12\n" +
+	"\x04held\x18\x03 \x01(\x03R\x04held\x12\x1c\n" +
+	"\tavailable\x18\x04 \x01(\x03R\tavailable\"\xb3\x03\n" +
+	"\x04Hold\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12&\n" +
+	"\x0ffrom_account_id\x18\x04 \x01(\tR\rfromAccountId\x12\"\n" +
+	"\rto_account_id\x18\x05 \x01(\tR\vtoAccountId\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06amount\x18\a \x01(\x03R\x06amount\x12'\n" +
+	"\x0fcaptured_amount\x18\b \x01(\x03R\x0ecapturedAmount\x12,\n" +
+	"\x06status\x18\t \x01(\x0e2\x14.qazna.v1.HoldStatusR\x06status\x12%\n" +
+	"\x0etransaction_id\x18\n" +
+	" \x01(\tR\rtransactionId\x12'\n" +
+	"\x0fidempotency_key\x18\v \x01(\tR\x0eidempotencyKey\"\xbf\x01\n" +
+	"\x11CreateHoldRequest\x12\x17\n" +
+	"\afrom_id\x18\x01 \x01(\tR\x06fromId\x12\x13\n" +
+	"\x05to_id\x18\x02 \x01(\tR\x04toId\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\x12\x1f\n" +
+	"\vttl_seconds\x18\x05 \x01(\rR\n" +
+	"ttlSeconds\x12'\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tR\x0eidempotencyKey\" \n" +
+	"\x0eGetHoldRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"<\n" +
+	"\x12CaptureHoldRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\"r\n" +
+	"\x13CaptureHoldResponse\x12\"\n" +
+	"\x04hold\x18\x01 \x01(\v2\x0e.qazna.v1.HoldR\x04hold\x127\n" +
+	"\vtransaction\x18\x02 \x01(\v2\x15.qazna.v1.TransactionR\vtransaction\"!\n" +
+	"\x0fVoidHoldRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xa1\x01\n" +
+	"\x13GetBalanceAtRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\x12$\n" +
+	"\x0eas_of_sequence\x18\x03 \x01(\x04R\fasOfSequence\x128\n" +
+	"\n" +
+	"as_of_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\basOfTime\"c\n" +
+	"\x11HistoricalBalance\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bsequence\x18\x03 \x01(\x04R\bsequence\"\xb4\x01\n" +
+	"\x18WatchTransactionsRequest\x12%\n" +
+	"\x0eafter_sequence\x18\x01 \x01(\x04R\rafterSequence\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x126\n" +
+	"\tdirection\x18\x03 \x01(\x0e2\x18.qazna.v1.EntryDirectionR\tdirection\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency*\x93\x01\n" +
+	"\vAccountType\x12\x1c\n" +
+	"\x18ACCOUNT_TYPE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14ACCOUNT_TYPE_RESERVE\x10\x01\x12\x1b\n" +
+	"\x17ACCOUNT_TYPE_SETTLEMENT\x10\x02\x12\x14\n" +
+	"\x10ACCOUNT_TYPE_FEE\x10\x03\x12\x19\n" +
+	"\x15ACCOUNT_TYPE_SUSPENSE\x10\x04*\x80\x01\n" +
+	"\rAccountStatus\x12\x1e\n" +
+	"\x1aACCOUNT_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15ACCOUNT_STATUS_ACTIVE\x10\x01\x12\x19\n" +
+	"\x15ACCOUNT_STATUS_FROZEN\x10\x02\x12\x19\n" +
+	"\x15ACCOUNT_STATUS_CLOSED\x10\x03*\x99\x01\n" +
+	"\x0eReversalStatus\x12\x1f\n" +
+	"\x1bREVERSAL_STATUS_UNSPECIFIED\x10\x00\x12 \n" +
+	"\x1cREVERSAL_STATUS_NOT_REVERSED\x10\x01\x12&\n" +
+	"\"REVERSAL_STATUS_PARTIALLY_REVERSED\x10\x02\x12\x1c\n" +
+	"\x18REVERSAL_STATUS_REVERSED\x10\x03*h\n" +
+	"\x0eEntryDirection\x12\x1f\n" +
+	"\x1bENTRY_DIRECTION_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15ENTRY_DIRECTION_DEBIT\x10\x01\x12\x1a\n" +
+	"\x16ENTRY_DIRECTION_CREDIT\x10\x02*\x8d\x01\n" +
+	"\n" +
+	"HoldStatus\x12\x1b\n" +
+	"\x17HOLD_STATUS_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13HOLD_STATUS_PENDING\x10\x01\x12\x18\n" +
+	"\x14HOLD_STATUS_CAPTURED\x10\x02\x12\x16\n" +
+	"\x12HOLD_STATUS_VOIDED\x10\x03\x12\x17\n" +
+	"\x13HOLD_STATUS_EXPIRED\x10\x042\x86\t\n" +
 	"\rLedgerService\x12B\n" +
 	"\rCreateAccount\x12\x1e.qazna.v1.CreateAccountRequest\x1a\x11.qazna.v1.Account\x12<\n" +
 	"\n" +
@@ -662,7 +2477,20 @@ const file_api_proto_qazna_v1_ledger_proto_rawDesc = "" +
 	"\n" +
 	"GetBalance\x12\x1b.qazna.v1.GetBalanceRequest\x1a\x11.qazna.v1.Balance\x12A\n" +
 	"\bTransfer\x12\x19.qazna.v1.TransferRequest\x1a\x1a.qazna.v1.TransferResponse\x12Y\n" +
-	"\x10ListTransactions\x12!.qazna.v1.ListTransactionsRequest\x1a\".qazna.v1.ListTransactionsResponseB,Z*qazna.org/api/gen/go/api/proto/qazna/v1;v1b\x06proto3"
+	"\x10ListTransactions\x12!.qazna.v1.ListTransactionsRequest\x1a\".qazna.v1.ListTransactionsResponse\x12J\n" +
+	"\vPostEntries\x12\x1c.qazna.v1.PostEntriesRequest\x1a\x1d.qazna.v1.PostEntriesResponse\x12H\n" +
+	"\x10SetAccountStatus\x12!.qazna.v1.SetAccountStatusRequest\x1a\x11.qazna.v1.Account\x12g\n" +
+	"\x17ListAccountTransactions\x12(.qazna.v1.ListAccountTransactionsRequest\x1a\".qazna.v1.ListTransactionsResponse\x12J\n" +
+	"\fGetBalanceAt\x12\x1d.qazna.v1.GetBalanceAtRequest\x1a\x1b.qazna.v1.HistoricalBalance\x12>\n" +
+	"\aReverse\x12\x18.qazna.v1.ReverseRequest\x1a\x19.qazna.v1.ReverseResponse\x129\n" +
+	"\n" +
+	"CreateHold\x12\x1b.qazna.v1.CreateHoldRequest\x1a\x0e.qazna.v1.Hold\x123\n" +
+	"\aGetHold\x12\x18.qazna.v1.GetHoldRequest\x1a\x0e.qazna.v1.Hold\x12J\n" +
+	"\vCaptureHold\x12\x1c.qazna.v1.CaptureHoldRequest\x1a\x1d.qazna.v1.CaptureHoldResponse\x125\n" +
+	"\bVoidHold\x12\x19.qazna.v1.VoidHoldRequest\x1a\x0e.qazna.v1.Hold\x12G\n" +
+	"\n" +
+	"FXTransfer\x12\x1b.qazna.v1.FXTransferRequest\x1a\x1c.qazna.v1.FXTransferResponse\x12P\n" +
+	"\x11WatchTransactions\x12\".qazna.v1.WatchTransactionsRequest\x1a\x15.qazna.v1.Transaction0\x01B,Z*qazna.org/api/gen/go/api/proto/qazna/v1;v1b\x06proto3"
 
 var (
 	file_api_proto_qazna_v1_ledger_proto_rawDescOnce sync.Once
@@ -676,42 +2504,111 @@ func file_api_proto_qazna_v1_ledger_proto_rawDescGZIP() []byte {
 	return file_api_proto_qazna_v1_ledger_proto_rawDescData
 }
 
-var file_api_proto_qazna_v1_ledger_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_api_proto_qazna_v1_ledger_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_api_proto_qazna_v1_ledger_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_api_proto_qazna_v1_ledger_proto_goTypes = []any{
-	(*CreateAccountRequest)(nil),     // 0: qazna.v1.CreateAccountRequest
-	(*Account)(nil),                  // 1: qazna.v1.Account
-	(*TransferRequest)(nil),          // 2: qazna.v1.TransferRequest
-	(*TransferResponse)(nil),         // 3: qazna.v1.TransferResponse
-	(*Transaction)(nil),              // 4: qazna.v1.Transaction
-	(*ListTransactionsRequest)(nil),  // 5: qazna.v1.ListTransactionsRequest
-	(*ListTransactionsResponse)(nil), // 6: qazna.v1.ListTransactionsResponse
-	(*GetAccountRequest)(nil),        // 7: qazna.v1.GetAccountRequest
-	(*GetBalanceRequest)(nil),        // 8: qazna.v1.GetBalanceRequest
-	(*Balance)(nil),                  // 9: qazna.v1.Balance
-	nil,                              // 10: qazna.v1.Account.BalancesEntry
-	(*timestamppb.Timestamp)(nil),    // 11: google.protobuf.Timestamp
+	(AccountType)(0),                       // 0: qazna.v1.AccountType
+	(AccountStatus)(0),                     // 1: qazna.v1.AccountStatus
+	(ReversalStatus)(0),                    // 2: qazna.v1.ReversalStatus
+	(EntryDirection)(0),                    // 3: qazna.v1.EntryDirection
+	(HoldStatus)(0),                        // 4: qazna.v1.HoldStatus
+	(*CreateAccountRequest)(nil),           // 5: qazna.v1.CreateAccountRequest
+	(*Account)(nil),                        // 6: qazna.v1.Account
+	(*SetAccountStatusRequest)(nil),        // 7: qazna.v1.SetAccountStatusRequest
+	(*TransferRequest)(nil),                // 8: qazna.v1.TransferRequest
+	(*TransferResponse)(nil),               // 9: qazna.v1.TransferResponse
+	(*Transaction)(nil),                    // 10: qazna.v1.Transaction
+	(*Fee)(nil),                            // 11: qazna.v1.Fee
+	(*ReverseRequest)(nil),                 // 12: qazna.v1.ReverseRequest
+	(*ReverseResponse)(nil),                // 13: qazna.v1.ReverseResponse
+	(*FXTransferRequest)(nil),              // 14: qazna.v1.FXTransferRequest
+	(*FXTransferResponse)(nil),             // 15: qazna.v1.FXTransferResponse
+	(*Entry)(nil),                          // 16: qazna.v1.Entry
+	(*PostEntriesRequest)(nil),             // 17: qazna.v1.PostEntriesRequest
+	(*PostEntriesResponse)(nil),            // 18: qazna.v1.PostEntriesResponse
+	(*ListTransactionsRequest)(nil),        // 19: qazna.v1.ListTransactionsRequest
+	(*ListTransactionsResponse)(nil),       // 20: qazna.v1.ListTransactionsResponse
+	(*ListAccountTransactionsRequest)(nil), // 21: qazna.v1.ListAccountTransactionsRequest
+	(*GetAccountRequest)(nil),              // 22: qazna.v1.GetAccountRequest
+	(*GetBalanceRequest)(nil),              // 23: qazna.v1.GetBalanceRequest
+	(*Balance)(nil),                        // 24: qazna.v1.Balance
+	(*Hold)(nil),                           // 25: qazna.v1.Hold
+	(*CreateHoldRequest)(nil),              // 26: qazna.v1.CreateHoldRequest
+	(*GetHoldRequest)(nil),                 // 27: qazna.v1.GetHoldRequest
+	(*CaptureHoldRequest)(nil),             // 28: qazna.v1.CaptureHoldRequest
+	(*CaptureHoldResponse)(nil),            // 29: qazna.v1.CaptureHoldResponse
+	(*VoidHoldRequest)(nil),                // 30: qazna.v1.VoidHoldRequest
+	(*GetBalanceAtRequest)(nil),            // 31: qazna.v1.GetBalanceAtRequest
+	(*HistoricalBalance)(nil),              // 32: qazna.v1.HistoricalBalance
+	(*WatchTransactionsRequest)(nil),       // 33: qazna.v1.WatchTransactionsRequest
+	nil,                                    // 34: qazna.v1.Account.BalancesEntry
+	(*timestamppb.Timestamp)(nil),          // 35: google.protobuf.Timestamp
 }
 var file_api_proto_qazna_v1_ledger_proto_depIdxs = []int32{
-	11, // 0: qazna.v1.Account.created_at:type_name -> google.protobuf.Timestamp
-	10, // 1: qazna.v1.Account.balances:type_name -> qazna.v1.Account.BalancesEntry
-	4,  // 2: qazna.v1.TransferResponse.transaction:type_name -> qazna.v1.Transaction
-	11, // 3: qazna.v1.Transaction.created_at:type_name -> google.protobuf.Timestamp
-	4,  // 4: qazna.v1.ListTransactionsResponse.items:type_name -> qazna.v1.Transaction
-	0,  // 5: qazna.v1.LedgerService.CreateAccount:input_type -> qazna.v1.CreateAccountRequest
-	7,  // 6: qazna.v1.LedgerService.GetAccount:input_type -> qazna.v1.GetAccountRequest
-	8,  // 7: qazna.v1.LedgerService.GetBalance:input_type -> qazna.v1.GetBalanceRequest
-	2,  // 8: qazna.v1.LedgerService.Transfer:input_type -> qazna.v1.TransferRequest
-	5,  // 9: qazna.v1.LedgerService.ListTransactions:input_type -> qazna.v1.ListTransactionsRequest
-	1,  // 10: qazna.v1.LedgerService.CreateAccount:output_type -> qazna.v1.Account
-	1,  // 11: qazna.v1.LedgerService.GetAccount:output_type -> qazna.v1.Account
-	9,  // 12: qazna.v1.LedgerService.GetBalance:output_type -> qazna.v1.Balance
-	3,  // 13: qazna.v1.LedgerService.Transfer:output_type -> qazna.v1.TransferResponse
-	6,  // 14: qazna.v1.LedgerService.ListTransactions:output_type -> qazna.v1.ListTransactionsResponse
-	10, // [10:15] is the sub-list for method output_type
-	5,  // [5:10] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	0,  // 0: qazna.v1.CreateAccountRequest.type:type_name -> qazna.v1.AccountType
+	35, // 1: qazna.v1.Account.created_at:type_name -> google.protobuf.Timestamp
+	34, // 2: qazna.v1.Account.balances:type_name -> qazna.v1.Account.BalancesEntry
+	0,  // 3: qazna.v1.Account.type:type_name -> qazna.v1.AccountType
+	1,  // 4: qazna.v1.Account.status:type_name -> qazna.v1.AccountStatus
+	1,  // 5: qazna.v1.SetAccountStatusRequest.status:type_name -> qazna.v1.AccountStatus
+	10, // 6: qazna.v1.TransferResponse.transaction:type_name -> qazna.v1.Transaction
+	35, // 7: qazna.v1.Transaction.created_at:type_name -> google.protobuf.Timestamp
+	16, // 8: qazna.v1.Transaction.entries:type_name -> qazna.v1.Entry
+	2,  // 9: qazna.v1.Transaction.reversal_status:type_name -> qazna.v1.ReversalStatus
+	11, // 10: qazna.v1.Transaction.fee:type_name -> qazna.v1.Fee
+	10, // 11: qazna.v1.ReverseResponse.transaction:type_name -> qazna.v1.Transaction
+	10, // 12: qazna.v1.FXTransferResponse.transaction:type_name -> qazna.v1.Transaction
+	3,  // 13: qazna.v1.Entry.direction:type_name -> qazna.v1.EntryDirection
+	16, // 14: qazna.v1.PostEntriesRequest.entries:type_name -> qazna.v1.Entry
+	10, // 15: qazna.v1.PostEntriesResponse.transaction:type_name -> qazna.v1.Transaction
+	10, // 16: qazna.v1.ListTransactionsResponse.items:type_name -> qazna.v1.Transaction
+	3,  // 17: qazna.v1.ListAccountTransactionsRequest.direction:type_name -> qazna.v1.EntryDirection
+	35, // 18: qazna.v1.ListAccountTransactionsRequest.from:type_name -> google.protobuf.Timestamp
+	35, // 19: qazna.v1.ListAccountTransactionsRequest.to:type_name -> google.protobuf.Timestamp
+	35, // 20: qazna.v1.Hold.created_at:type_name -> google.protobuf.Timestamp
+	35, // 21: qazna.v1.Hold.expires_at:type_name -> google.protobuf.Timestamp
+	4,  // 22: qazna.v1.Hold.status:type_name -> qazna.v1.HoldStatus
+	25, // 23: qazna.v1.CaptureHoldResponse.hold:type_name -> qazna.v1.Hold
+	10, // 24: qazna.v1.CaptureHoldResponse.transaction:type_name -> qazna.v1.Transaction
+	35, // 25: qazna.v1.GetBalanceAtRequest.as_of_time:type_name -> google.protobuf.Timestamp
+	3,  // 26: qazna.v1.WatchTransactionsRequest.direction:type_name -> qazna.v1.EntryDirection
+	5,  // 27: qazna.v1.LedgerService.CreateAccount:input_type -> qazna.v1.CreateAccountRequest
+	22, // 28: qazna.v1.LedgerService.GetAccount:input_type -> qazna.v1.GetAccountRequest
+	23, // 29: qazna.v1.LedgerService.GetBalance:input_type -> qazna.v1.GetBalanceRequest
+	8,  // 30: qazna.v1.LedgerService.Transfer:input_type -> qazna.v1.TransferRequest
+	19, // 31: qazna.v1.LedgerService.ListTransactions:input_type -> qazna.v1.ListTransactionsRequest
+	17, // 32: qazna.v1.LedgerService.PostEntries:input_type -> qazna.v1.PostEntriesRequest
+	7,  // 33: qazna.v1.LedgerService.SetAccountStatus:input_type -> qazna.v1.SetAccountStatusRequest
+	21, // 34: qazna.v1.LedgerService.ListAccountTransactions:input_type -> qazna.v1.ListAccountTransactionsRequest
+	31, // 35: qazna.v1.LedgerService.GetBalanceAt:input_type -> qazna.v1.GetBalanceAtRequest
+	12, // 36: qazna.v1.LedgerService.Reverse:input_type -> qazna.v1.ReverseRequest
+	26, // 37: qazna.v1.LedgerService.CreateHold:input_type -> qazna.v1.CreateHoldRequest
+	27, // 38: qazna.v1.LedgerService.GetHold:input_type -> qazna.v1.GetHoldRequest
+	28, // 39: qazna.v1.LedgerService.CaptureHold:input_type -> qazna.v1.CaptureHoldRequest
+	30, // 40: qazna.v1.LedgerService.VoidHold:input_type -> qazna.v1.VoidHoldRequest
+	14, // 41: qazna.v1.LedgerService.FXTransfer:input_type -> qazna.v1.FXTransferRequest
+	33, // 42: qazna.v1.LedgerService.WatchTransactions:input_type -> qazna.v1.WatchTransactionsRequest
+	6,  // 43: qazna.v1.LedgerService.CreateAccount:output_type -> qazna.v1.Account
+	6,  // 44: qazna.v1.LedgerService.GetAccount:output_type -> qazna.v1.Account
+	24, // 45: qazna.v1.LedgerService.GetBalance:output_type -> qazna.v1.Balance
+	9,  // 46: qazna.v1.LedgerService.Transfer:output_type -> qazna.v1.TransferResponse
+	20, // 47: qazna.v1.LedgerService.ListTransactions:output_type -> qazna.v1.ListTransactionsResponse
+	18, // 48: qazna.v1.LedgerService.PostEntries:output_type -> qazna.v1.PostEntriesResponse
+	6,  // 49: qazna.v1.LedgerService.SetAccountStatus:output_type -> qazna.v1.Account
+	20, // 50: qazna.v1.LedgerService.ListAccountTransactions:output_type -> qazna.v1.ListTransactionsResponse
+	32, // 51: qazna.v1.LedgerService.GetBalanceAt:output_type -> qazna.v1.HistoricalBalance
+	13, // 52: qazna.v1.LedgerService.Reverse:output_type -> qazna.v1.ReverseResponse
+	25, // 53: qazna.v1.LedgerService.CreateHold:output_type -> qazna.v1.Hold
+	25, // 54: qazna.v1.LedgerService.GetHold:output_type -> qazna.v1.Hold
+	29, // 55: qazna.v1.LedgerService.CaptureHold:output_type -> qazna.v1.CaptureHoldResponse
+	25, // 56: qazna.v1.LedgerService.VoidHold:output_type -> qazna.v1.Hold
+	15, // 57: qazna.v1.LedgerService.FXTransfer:output_type -> qazna.v1.FXTransferResponse
+	10, // 58: qazna.v1.LedgerService.WatchTransactions:output_type -> qazna.v1.Transaction
+	43, // [43:59] is the sub-list for method output_type
+	27, // [27:43] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_qazna_v1_ledger_proto_init() }
@@ -724,13 +2621,14 @@ func file_api_proto_qazna_v1_ledger_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_qazna_v1_ledger_proto_rawDesc), len(file_api_proto_qazna_v1_ledger_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   11,
+			NumEnums:      5,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_proto_qazna_v1_ledger_proto_goTypes,
 		DependencyIndexes: file_api_proto_qazna_v1_ledger_proto_depIdxs,
+		EnumInfos:         file_api_proto_qazna_v1_ledger_proto_enumTypes,
 		MessageInfos:      file_api_proto_qazna_v1_ledger_proto_msgTypes,
 	}.Build()
 	File_api_proto_qazna_v1_ledger_proto = out.File
@@ -36,7 +36,7 @@ func main() {
 
 	log.Printf("Launching AI demo: base=%s workers=%d duration=%s", *baseURL, *workers, *duration)
 
-	token, err := issueToken(ctx, *baseURL)
+	token, err := issueToken(ctx, *baseURL, os.Getenv("QAZNA_EMAIL"), os.Getenv("QAZNA_PASSWORD"))
 	if err != nil {
 		log.Fatalf("issue token: %v", err)
 	}
@@ -77,8 +77,13 @@ func main() {
 					"amount":          transfer.Amount,
 					"idempotency_key": idem,
 				}
+				endpoint := "/v1/transfers"
+				if transfer.TargetCurrency != "" {
+					payload["target_currency"] = transfer.TargetCurrency
+					endpoint = "/v1/fx/transfers"
+				}
 				body, _ := json.Marshal(payload)
-				req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v1/transfers", *baseURL), bytes.NewReader(body))
+				req, err := http.NewRequestWithContext(ctx, http.MethodPost, *baseURL+endpoint, bytes.NewReader(body))
 				if err != nil {
 					log.Printf("worker %d request: %v", id, err)
 					atomic.AddInt64(&failures, 1)
@@ -138,10 +143,14 @@ func main() {
 	}
 }
 
-func issueToken(ctx context.Context, baseURL string) (string, error) {
+func issueToken(ctx context.Context, baseURL, email, password string) (string, error) {
+	if email == "" || password == "" {
+		return "", errors.New("QAZNA_EMAIL and QAZNA_PASSWORD must be set")
+	}
 	payload := map[string]any{
-		"user":  "demo-ai",
-		"roles": []string{"admin"},
+		"grant_type": "password",
+		"email":      email,
+		"password":   password,
 	}
 	body, _ := json.Marshal(payload)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v1/auth/token", baseURL), bytes.NewReader(body))
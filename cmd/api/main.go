@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"html/template"
 	"log"
@@ -10,6 +11,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -17,11 +20,16 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	v1 "qazna.org/api/gen/go/api/proto/qazna/v1"
+	"qazna.org/internal/audit"
 	"qazna.org/internal/auth"
 	"qazna.org/internal/httpapi"
 	"qazna.org/internal/ledger"
 	"qazna.org/internal/ledger/remote"
+	"qazna.org/internal/netting"
 	"qazna.org/internal/obs"
+	"qazna.org/internal/outbox"
+	"qazna.org/internal/reconcile"
+	"qazna.org/internal/screening"
 	"qazna.org/internal/store/pg"
 	"qazna.org/internal/stream"
 
@@ -47,6 +55,8 @@ func main() {
 		authSvc      *auth.Service
 		rbacSvc      *auth.RBACService
 		pgStore      *pg.Store
+		cycles       netting.Store
+		auditSink    *audit.PGSink
 	)
 
 	if dsn := os.Getenv("QAZNA_PG_DSN"); dsn != "" {
@@ -58,7 +68,11 @@ func main() {
 		storeClose = store.Close
 		pgStore = store
 
-		svc, err := auth.NewService(db)
+		var authOpts []auth.Option
+		if v := os.Getenv("QAZNA_AUTH_PERMISSION_CLAIMS"); strings.EqualFold(v, "1") || strings.EqualFold(v, "true") {
+			authOpts = append(authOpts, auth.WithPermissionClaims(true))
+		}
+		svc, err := auth.NewService(db, authOpts...)
 		if err != nil {
 			log.Fatalf("init auth service: %v", err)
 		}
@@ -69,6 +83,9 @@ func main() {
 			log.Fatalf("init rbac service: %v", err)
 		}
 		rbacSvc = rsvc
+
+		auditSink = audit.NewPGSink(db)
+		audit.SetSink(auditSink)
 	}
 
 	if addr := os.Getenv("QAZNA_LEDGER_GRPC_ADDR"); addr != "" {
@@ -83,8 +100,24 @@ func main() {
 		log.Printf("Using remote ledger at %s", addr)
 	} else if pgStore != nil {
 		ledgerSvc = pgStore
+	} else if dir := os.Getenv("QAZNA_LEDGER_DATA_DIR"); dir != "" {
+		durable, err := ledger.OpenDurable(dir, durableOptions()...)
+		if err != nil {
+			log.Fatalf("open ledger data dir: %v", err)
+		}
+		// Netting cycles are journaled next to the ledger, so a restart does
+		// not lose them or strand one that was settling.
+		journal, err := netting.OpenFileStore(filepath.Join(dir, "netting.journal"))
+		if err != nil {
+			log.Fatalf("open netting journal: %v", err)
+		}
+		ledgerSvc = durable
+		cycles = journal
+		storeClose = func() error { return errors.Join(journal.Close(), durable.Close()) }
+		log.Printf("Using durable in-memory ledger at %s", dir)
 	} else {
 		ledgerSvc = ledger.NewInMemory()
+		log.Println("running with a volatile in-memory ledger; set QAZNA_LEDGER_DATA_DIR to persist it")
 	}
 
 	if authSvc == nil {
@@ -98,7 +131,36 @@ func main() {
 	evtStream := stream.New()
 
 	// HTTP API setup.
-	api := httpapi.New(rp, version, ledgerSvc, evtStream, tmpl, authSvc, rbacSvc)
+	var apiOpts []httpapi.Option
+	ledgerOpts := []httpapi.LedgerGRPCOption{httpapi.WithLedgerRBAC(rbacSvc)}
+	if auditSink != nil {
+		apiOpts = append(apiOpts, httpapi.WithAuditReader(auditSink))
+	}
+	// Postings written to Postgres reach the stream through the outbox, so
+	// every API replica sees every transfer, including those made elsewhere.
+	var stopOutbox func()
+	if pgStore != nil && remoteClient == nil {
+		stopOutbox = startOutbox(pgStore, evtStream)
+		apiOpts = append(apiOpts, httpapi.WithOutboxStream())
+	}
+	// Funding accounts at creation bypasses issuance; it stays off unless
+	// asked for, as for a backend that cannot mint.
+	if v := os.Getenv("QAZNA_ALLOW_INITIAL_FUNDING"); strings.EqualFold(v, "1") || strings.EqualFold(v, "true") {
+		apiOpts = append(apiOpts, httpapi.WithInitialFunding())
+		ledgerOpts = append(ledgerOpts, httpapi.WithLedgerInitialFunding())
+	}
+	if path := os.Getenv("QAZNA_SANCTIONS_LIST"); path != "" {
+		apiOpts = append(apiOpts, httpapi.WithScreener(sanctionsScreener(path)))
+		if pgStore != nil {
+			apiOpts = append(apiOpts, httpapi.WithReviewQueue(pgStore))
+		}
+	}
+	if pgStore != nil {
+		apiOpts = append(apiOpts, httpapi.WithNettingStore(pgStore))
+	} else if cycles != nil {
+		apiOpts = append(apiOpts, httpapi.WithNettingStore(cycles))
+	}
+	api := httpapi.New(rp, version, ledgerSvc, evtStream, tmpl, authSvc, rbacSvc, apiOpts...)
 
 	srv := &http.Server{
 		Addr:              ":8080",
@@ -134,6 +196,43 @@ func main() {
 		log.Fatalf("grpc listen: %v", err)
 	}
 
+	// LedgerService trusts the caller identity in its metadata and checks
+	// its RBAC permissions, so it is only served on a listener of its own,
+	// off unless configured, for other internal services to reach.
+	var ledgerSrv *grpc.Server
+	if addr := os.Getenv("QAZNA_LEDGER_SERVICE_ADDR"); addr != "" {
+		ledgerLis, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatalf("ledger grpc listen: %v", err)
+		}
+		ledgerSrv = grpc.NewServer()
+		v1.RegisterLedgerServiceServer(ledgerSrv, httpapi.NewLedgerGRPCServer(ledgerSvc, append(ledgerOpts, api.LedgerScreening())...))
+		log.Printf("internal LedgerService listening on %s", addr)
+		go func() {
+			if err := ledgerSrv.Serve(ledgerLis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				log.Fatalf("ledger grpc serve: %v", err)
+			}
+		}()
+	}
+
+	var stopSnapshots func()
+	if v := os.Getenv("QAZNA_BALANCE_SNAPSHOT_INTERVAL"); v != "" && pgStore != nil && remoteClient == nil {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			log.Fatalf("invalid QAZNA_BALANCE_SNAPSHOT_INTERVAL %q", v)
+		}
+		stopSnapshots = startBalanceSnapshots(pgStore, interval)
+	}
+
+	var stopReconcile func()
+	if v := os.Getenv("QAZNA_RECONCILE_INTERVAL"); v != "" && pgStore != nil && remoteClient == nil {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			log.Fatalf("invalid QAZNA_RECONCILE_INTERVAL %q", v)
+		}
+		stopReconcile = startReconciliation(pgStore, interval)
+	}
+
 	var stopDemo func()
 	if v := os.Getenv("QAZNA_STREAM_DEMO"); strings.EqualFold(v, "1") || strings.EqualFold(v, "true") {
 		stopDemo = evtStream.StartDemo(3 * time.Second)
@@ -158,20 +257,176 @@ func main() {
 	_ = srv.Shutdown(ctx)
 	grpcSrv.GracefulStop()
 	_ = lis.Close()
+	if ledgerSrv != nil {
+		ledgerSrv.GracefulStop()
+	}
 	if stopDemo != nil {
 		stopDemo()
 	}
+	if stopSnapshots != nil {
+		stopSnapshots()
+	}
+	if stopOutbox != nil {
+		stopOutbox()
+	}
+	if stopReconcile != nil {
+		stopReconcile()
+	}
 	if remoteClient != nil {
 		_ = remoteClient.Close()
 	}
 	if storeClose != nil {
-		_ = storeClose()
+		if err := storeClose(); err != nil {
+			log.Printf("close store: %v", err)
+		}
 	} else if db != nil {
 		_ = db.Close()
 	}
 	log.Println("Stopped")
 }
 
+// startBalanceSnapshots periodically records all balances so point-in-time
+// queries only replay the transactions since the last snapshot.
+func startBalanceSnapshots(store *pg.Store, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				seq, err := store.SnapshotBalances(ctx)
+				switch {
+				case err == nil:
+					log.Printf("balance snapshot taken at sequence %d", seq)
+				case ctx.Err() == nil:
+					log.Printf("balance snapshot: %v", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// startReconciliation periodically checks the stored balances against the
+// transaction history and exports the number of problems found.
+func startReconciliation(store *pg.Store, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := reconcile.Run(ctx, store)
+				switch {
+				case err == nil:
+					obs.SetLedgerDrift(report.Problems())
+					if !report.OK() {
+						out, _ := json.Marshal(report)
+						log.Printf("ledger reconciliation found drift: %s", out)
+					}
+				case ctx.Err() == nil:
+					log.Printf("ledger reconciliation: %v", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// startOutbox feeds the event stream from the Postgres outbox. The stream
+// consumer is per host and starts at the head: it serves live subscribers
+// only, so there is no point replaying history into it on first start.
+func startOutbox(store *pg.Store, s *stream.Stream) func() {
+	opts := []outbox.Option{outbox.WithCursorObserver(obs.SetOutboxCursor)}
+	if v := os.Getenv("QAZNA_OUTBOX_POLL_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			log.Fatalf("invalid QAZNA_OUTBOX_POLL_INTERVAL %q", v)
+		}
+		opts = append(opts, outbox.WithPollInterval(interval))
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "local"
+	}
+	d := outbox.NewDispatcher(store, opts...)
+	d.AddSink("stream@"+host, outbox.StreamSink{Stream: s}, outbox.StartAtHead())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = d.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// durableOptions reads the snapshot and fsync batching settings of the
+// durable in-memory ledger. Snapshots default to every five minutes.
+func durableOptions() []ledger.DurableOption {
+	opts := []ledger.DurableOption{ledger.WithSnapshotInterval(5 * time.Minute)}
+	if v := os.Getenv("QAZNA_LEDGER_SNAPSHOT_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			log.Fatalf("invalid QAZNA_LEDGER_SNAPSHOT_INTERVAL %q", v)
+		}
+		opts = append(opts, ledger.WithSnapshotInterval(interval))
+	}
+	if v := os.Getenv("QAZNA_LEDGER_SYNC_DELAY"); v != "" {
+		delay, err := time.ParseDuration(v)
+		if err != nil || delay < 0 {
+			log.Fatalf("invalid QAZNA_LEDGER_SYNC_DELAY %q", v)
+		}
+		opts = append(opts, ledger.WithSyncDelay(delay))
+	}
+	return opts
+}
+
+// sanctionsScreener loads the sanctions list at path, with the score
+// thresholds overridden by QAZNA_SANCTIONS_HOLD_SCORE and
+// QAZNA_SANCTIONS_REJECT_SCORE.
+func sanctionsScreener(path string) *screening.SanctionsScreener {
+	entries, err := screening.LoadSanctionsList(path)
+	if err != nil {
+		log.Fatalf("load sanctions list: %v", err)
+	}
+	hold, reject := screening.DefaultHoldScore, screening.DefaultRejectScore
+	for name, score := range map[string]*float64{"QAZNA_SANCTIONS_HOLD_SCORE": &hold, "QAZNA_SANCTIONS_REJECT_SCORE": &reject} {
+		if v := os.Getenv(name); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				log.Fatalf("invalid %s %q", name, v)
+			}
+			*score = f
+		}
+	}
+	s, err := screening.NewSanctionsScreener(entries, screening.WithScoreThresholds(hold, reject))
+	if err != nil {
+		log.Fatalf("sanctions screener: %v", err)
+	}
+	log.Printf("screening transfers against %d sanctions entries from %s", len(entries), path)
+	return s
+}
+
 func mustParseTemplates() *template.Template {
 	base := template.New("base")
 	patterns := []string{
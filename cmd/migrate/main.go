@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -11,6 +12,7 @@ import (
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
+	"qazna.org/internal/audit"
 	"qazna.org/internal/migrate"
 )
 
@@ -27,7 +29,7 @@ func main() {
 		log.Fatal("missing DSN: provide via -dsn or QAZNA_PG_DSN")
 	}
 	if len(flag.Args()) == 0 {
-		log.Fatal("usage: migrate [up|down|seed|status]")
+		log.Fatal("usage: migrate [up|down|seed|status|audit-verify]")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -56,6 +58,17 @@ func main() {
 				fmt.Println(item)
 			}
 		}
+	case "audit-verify":
+		var report audit.VerifyReport
+		report, err = audit.NewPGSink(db).Verify(ctx)
+		if err == nil {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(report)
+			if !report.OK() {
+				os.Exit(1)
+			}
+		}
 	default:
 		log.Fatalf("unknown command %q", flag.Arg(0))
 	}
@@ -0,0 +1,51 @@
+// Command reconcile replays the Postgres ledger history and compares it with
+// the stored balances. It prints a JSON report and exits 1 when anything
+// drifted, 2 when the check could not run.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"qazna.org/internal/reconcile"
+	"qazna.org/internal/store/pg"
+)
+
+func main() {
+	log.SetFlags(0)
+	var (
+		dsn     = flag.String("dsn", os.Getenv("QAZNA_PG_DSN"), "PostgreSQL DSN")
+		timeout = flag.Duration("timeout", 10*time.Minute, "Give up after this long")
+	)
+	flag.Parse()
+
+	if *dsn == "" {
+		log.Fatal("missing DSN: provide via -dsn or QAZNA_PG_DSN")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	store, err := pg.Open(*dsn)
+	if err != nil {
+		log.Printf("open db: %v", err)
+		os.Exit(2)
+	}
+	defer store.Close()
+
+	report, err := reconcile.Run(ctx, store)
+	if err != nil {
+		log.Printf("reconcile: %v", err)
+		os.Exit(2)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(report)
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
@@ -5,9 +5,13 @@ use tonic::{transport::Server, Request, Response, Status};
 
 use crate::proto::qazna::v1::ledger_service_server::{LedgerService, LedgerServiceServer};
 use crate::proto::qazna::v1::{
-    Account as ProtoAccount, Balance as ProtoBalance, CreateAccountRequest, GetAccountRequest,
-    GetBalanceRequest, ListTransactionsRequest, ListTransactionsResponse,
-    Transaction as ProtoTransaction, TransferRequest, TransferResponse,
+    Account as ProtoAccount, AccountStatus, AccountType, Balance as ProtoBalance,
+    CaptureHoldRequest, CaptureHoldResponse, CreateAccountRequest, CreateHoldRequest,
+    FxTransferRequest, FxTransferResponse, GetAccountRequest, GetBalanceAtRequest, GetBalanceRequest, GetHoldRequest,
+    HistoricalBalance, Hold, ListAccountTransactionsRequest, ListTransactionsRequest,
+    ListTransactionsResponse, PostEntriesRequest, PostEntriesResponse, ReversalStatus,
+    ReverseRequest, ReverseResponse, SetAccountStatusRequest, Transaction as ProtoTransaction,
+    TransferRequest, TransferResponse, VoidHoldRequest, WatchTransactionsRequest,
 };
 use crate::{Account, Ledger, LedgerError, Money, Transaction};
 use prost_types::Timestamp;
@@ -56,6 +60,8 @@ impl LedgerService for GRpcLedger {
             Ok(money) => Ok(Response::new(ProtoBalance {
                 currency: money.currency,
                 amount: money.amount,
+                held: 0,
+                available: money.amount,
             })),
             Err(err) => Err(map_error(err)),
         }
@@ -103,6 +109,82 @@ impl LedgerService for GRpcLedger {
             Err(err) => Err(map_error(err)),
         }
     }
+
+    async fn post_entries(
+        &self,
+        _request: Request<PostEntriesRequest>,
+    ) -> Result<Response<PostEntriesResponse>, Status> {
+        Err(Status::unimplemented("batch postings are not supported by ledgerd"))
+    }
+
+    async fn set_account_status(
+        &self,
+        _request: Request<SetAccountStatusRequest>,
+    ) -> Result<Response<ProtoAccount>, Status> {
+        Err(Status::unimplemented("account lifecycle is not supported by ledgerd"))
+    }
+
+    async fn list_account_transactions(
+        &self,
+        _request: Request<ListAccountTransactionsRequest>,
+    ) -> Result<Response<ListTransactionsResponse>, Status> {
+        Err(Status::unimplemented("account history is not supported by ledgerd"))
+    }
+
+    async fn get_balance_at(
+        &self,
+        _request: Request<GetBalanceAtRequest>,
+    ) -> Result<Response<HistoricalBalance>, Status> {
+        Err(Status::unimplemented("historical balances are not supported by ledgerd"))
+    }
+
+    async fn reverse(
+        &self,
+        _request: Request<ReverseRequest>,
+    ) -> Result<Response<ReverseResponse>, Status> {
+        Err(Status::unimplemented("reversals are not supported by ledgerd"))
+    }
+
+    async fn create_hold(
+        &self,
+        _request: Request<CreateHoldRequest>,
+    ) -> Result<Response<Hold>, Status> {
+        Err(Status::unimplemented("holds are not supported by ledgerd"))
+    }
+
+    async fn get_hold(&self, _request: Request<GetHoldRequest>) -> Result<Response<Hold>, Status> {
+        Err(Status::unimplemented("holds are not supported by ledgerd"))
+    }
+
+    async fn capture_hold(
+        &self,
+        _request: Request<CaptureHoldRequest>,
+    ) -> Result<Response<CaptureHoldResponse>, Status> {
+        Err(Status::unimplemented("holds are not supported by ledgerd"))
+    }
+
+    async fn void_hold(
+        &self,
+        _request: Request<VoidHoldRequest>,
+    ) -> Result<Response<Hold>, Status> {
+        Err(Status::unimplemented("holds are not supported by ledgerd"))
+    }
+
+    async fn fx_transfer(
+        &self,
+        _request: Request<FxTransferRequest>,
+    ) -> Result<Response<FxTransferResponse>, Status> {
+        Err(Status::unimplemented("fx transfers are not supported by ledgerd"))
+    }
+
+    type WatchTransactionsStream = tonic::codegen::BoxStream<ProtoTransaction>;
+
+    async fn watch_transactions(
+        &self,
+        _request: Request<WatchTransactionsRequest>,
+    ) -> Result<Response<Self::WatchTransactionsStream>, Status> {
+        Err(Status::unimplemented("transaction feeds are not supported by ledgerd"))
+    }
 }
 
 fn map_error(err: LedgerError) -> Status {
@@ -120,6 +202,14 @@ fn to_proto_account(acc: Account) -> ProtoAccount {
         id: acc.id,
         created_at: Some(timestamp(acc.created_at)),
         balances: acc.balances.into_iter().collect(),
+        // ledgerd does not track ownership; tenant scoping is enforced by
+        // the Go ledger services.
+        organization_id: String::new(),
+        // Every ledgerd account is an active settlement account.
+        r#type: AccountType::Settlement as i32,
+        display_name: String::new(),
+        external_ref: String::new(),
+        status: AccountStatus::Active as i32,
     }
 }
 
@@ -133,6 +223,15 @@ fn to_proto_transaction(tx: Transaction) -> ProtoTransaction {
         amount: tx.amount,
         idempotency_key: tx.idempotency_key.unwrap_or_default(),
         sequence: tx.sequence,
+        entries: Vec::new(),
+        reversal_of: String::new(),
+        reversal_reason: String::new(),
+        reversed_amount: 0,
+        reversal_status: ReversalStatus::NotReversed as i32,
+        fx_rate_id: String::new(),
+        fx_rate: String::new(),
+        kind: String::new(),
+        fee: None,
     }
 }
 
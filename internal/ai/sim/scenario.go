@@ -13,11 +13,12 @@ type Account struct {
 }
 
 type Transfer struct {
-	FromID    string
-	ToID      string
-	Amount    int64
-	Currency  string
-	Narrative string
+	FromID         string
+	ToID           string
+	Amount         int64
+	Currency       string
+	TargetCurrency string // set when the accounts hold different currencies
+	Narrative      string
 }
 
 type Scenario struct {
@@ -66,16 +67,21 @@ func (g Generator) NextTransfer() Transfer {
 	}
 	from := accs[fromIdx]
 	to := accs[toIdx]
-	// Ensure currency alignment: if mismatch, convert heuristically.
+	// Cross-currency pairs settle as FX transfers at the registered rate.
 	currency := from.Currency
+	var target string
+	if to.Currency != from.Currency {
+		target = to.Currency
+	}
 	amount := int64(g.rnd.Intn(900_000)+100_000) * 100 // 0.1M - 1.0M major units
 	narrative := g.scenario.Narratives[g.rnd.Intn(len(g.scenario.Narratives))]
 	return Transfer{
-		FromID:    from.ID,
-		ToID:      to.ID,
-		Currency:  currency,
-		Amount:    amount,
-		Narrative: narrative,
+		FromID:         from.ID,
+		ToID:           to.ID,
+		Currency:       currency,
+		TargetCurrency: target,
+		Amount:         amount,
+		Narrative:      narrative,
 	}
 }
 
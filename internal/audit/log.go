@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -36,13 +37,16 @@ func requestIDFromContext(ctx context.Context) string {
 }
 
 // LogEvent writes an audit log entry enriched with request and user context.
+// When a sink is installed (see SetSink) the event is persisted as well;
+// resource_type and resource_id fields become the event's resource columns.
 func LogEvent(ctx context.Context, event string, fields map[string]any) error {
 	event = strings.TrimSpace(event)
 	if event == "" {
 		return errors.New("event name is required")
 	}
+	now := time.Now().UTC()
 	entry := map[string]any{
-		"ts":    time.Now().UTC().Format(time.RFC3339Nano),
+		"ts":    now.Format(time.RFC3339Nano),
 		"type":  "audit",
 		"event": event,
 	}
@@ -67,5 +71,35 @@ func LogEvent(ctx context.Context, event string, fields map[string]any) error {
 		return err
 	}
 	obs.Logger().Println(string(data))
+
+	if s := currentSink(); s != nil {
+		return s.Write(ctx, buildEvent(ctx, event, now, fields))
+	}
 	return nil
 }
+
+func buildEvent(ctx context.Context, action string, at time.Time, fields map[string]any) Event {
+	ev := Event{
+		OccurredAt: at,
+		Action:     action,
+		TraceID:    requestIDFromContext(ctx),
+	}
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		ev.ActorUserID = userID
+	}
+	metadata := make(map[string]any, len(fields))
+	for k, v := range fields {
+		switch k {
+		case "resource_type":
+			ev.ResourceType = fmt.Sprint(v)
+		case "resource_id":
+			ev.ResourceID = fmt.Sprint(v)
+		default:
+			metadata[k] = v
+		}
+	}
+	if raw, err := json.Marshal(metadata); err == nil {
+		ev.Metadata = raw
+	}
+	return ev
+}
@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"qazna.org/internal/ids"
+)
+
+// MemorySink keeps a hash-chained trail in process memory. It is meant for
+// development and tests; the trail is lost on restart.
+type MemorySink struct {
+	mu     sync.RWMutex
+	events []Event
+}
+
+var (
+	_ Sink   = (*MemorySink)(nil)
+	_ Reader = (*MemorySink)(nil)
+)
+
+// NewMemorySink creates an empty in-memory trail.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Write appends ev to the chain.
+func (s *MemorySink) Write(_ context.Context, ev Event) error {
+	if strings.TrimSpace(ev.Action) == "" {
+		return errors.New("audit action is required")
+	}
+	if ev.ID == "" {
+		ev.ID = ids.New()
+	}
+	if ev.OccurredAt.IsZero() {
+		ev.OccurredAt = time.Now()
+	}
+	ev.OccurredAt = ev.OccurredAt.UTC()
+	metadata, err := CanonicalMetadata(ev.Metadata)
+	if err != nil {
+		return err
+	}
+	ev.Metadata = metadata
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := len(s.events); n > 0 {
+		ev.Seq = s.events[n-1].Seq + 1
+		ev.PrevHash = s.events[n-1].Hash
+	} else {
+		ev.Seq = 1
+		ev.PrevHash = ""
+	}
+	ev.Hash = ComputeHash(ev)
+	s.events = append(s.events, ev)
+	return nil
+}
+
+// Query returns events matching f in seq order.
+func (s *MemorySink) Query(_ context.Context, f Filter) ([]Event, error) {
+	limit := f.limit()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Event
+	for _, ev := range s.events {
+		if !f.match(ev) {
+			continue
+		}
+		ev.Metadata = append(json.RawMessage(nil), ev.Metadata...)
+		out = append(out, ev)
+		if len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Verify checks the in-memory chain.
+func (s *MemorySink) Verify(context.Context) (VerifyReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return VerifyChain(s.events), nil
+}
@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"qazna.org/internal/ids"
+)
+
+// chainLockKey serialises writers so each row links to the latest one.
+const chainLockKey int64 = 0x71617564 // "qaud"
+
+// PGSink writes hash-chained events into the audit_log table.
+type PGSink struct {
+	db *sql.DB
+}
+
+var (
+	_ Sink   = (*PGSink)(nil)
+	_ Reader = (*PGSink)(nil)
+)
+
+// NewPGSink creates a Postgres-backed audit sink.
+func NewPGSink(db *sql.DB) *PGSink {
+	return &PGSink{db: db}
+}
+
+// Write appends ev to the chain. Seq, PrevHash and Hash are assigned here;
+// ID and OccurredAt are filled in when empty.
+func (s *PGSink) Write(ctx context.Context, ev Event) error {
+	if strings.TrimSpace(ev.Action) == "" {
+		return errors.New("audit action is required")
+	}
+	if ev.ID == "" {
+		ev.ID = ids.New()
+	}
+	if ev.OccurredAt.IsZero() {
+		ev.OccurredAt = time.Now()
+	}
+	// Postgres keeps microseconds; hash exactly what will be read back.
+	ev.OccurredAt = ev.OccurredAt.UTC().Truncate(time.Microsecond)
+	metadata, err := CanonicalMetadata(ev.Metadata)
+	if err != nil {
+		return err
+	}
+	ev.Metadata = metadata
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `select pg_advisory_xact_lock($1)`, chainLockKey); err != nil {
+		return err
+	}
+
+	var lastSeq int64
+	var lastHash string
+	err = tx.QueryRowContext(ctx, `
+		select seq, hash from audit_log
+		where seq is not null
+		order by seq desc
+		limit 1
+	`).Scan(&lastSeq, &lastHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	ev.Seq = lastSeq + 1
+	ev.PrevHash = lastHash
+	ev.Hash = ComputeHash(ev)
+
+	if _, err := tx.ExecContext(ctx, `
+		insert into audit_log(id, occurred_at, actor_user_id, actor_org_id, action, resource_type, resource_id, metadata, trace_id, seq, prev_hash, hash)
+		values ($1,$2,nullif($3,''),nullif($4,''),$5,$6,$7,$8,nullif($9,''),$10,$11,$12)
+	`, ev.ID, ev.OccurredAt, ev.ActorUserID, ev.ActorOrgID, ev.Action, ev.ResourceType, ev.ResourceID,
+		string(ev.Metadata), ev.TraceID, ev.Seq, ev.PrevHash, ev.Hash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Query returns events matching f in seq order. Only the filters that are
+// set end up in the where clause so resource lookups can use
+// idx_audit_resource.
+func (s *PGSink) Query(ctx context.Context, f Filter) ([]Event, error) {
+	var (
+		conds = []string{"seq > $1"}
+		args  = []any{f.AfterSeq}
+	)
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ResourceType != "" {
+		add("resource_type = $%d", f.ResourceType)
+	}
+	if f.ResourceID != "" {
+		add("resource_id = $%d", f.ResourceID)
+	}
+	if f.ActorUserID != "" {
+		add("actor_user_id = $%d", f.ActorUserID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if !f.From.IsZero() {
+		add("occurred_at >= $%d", f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		add("occurred_at < $%d", f.To.UTC())
+	}
+	args = append(args, f.limit())
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		select seq, id, occurred_at, coalesce(actor_user_id,''), coalesce(actor_org_id,''),
+		       action, resource_type, resource_id, metadata::text, coalesce(trace_id,''),
+		       prev_hash, hash
+		from audit_log
+		where %s
+		order by seq asc
+		limit $%d
+	`, strings.Join(conds, " and "), len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Event
+	for rows.Next() {
+		ev, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ev)
+	}
+	return out, rows.Err()
+}
+
+// Verify walks the whole audit_log in seq order and reports gaps, broken
+// links and rows whose content no longer matches their hash.
+func (s *PGSink) Verify(ctx context.Context) (VerifyReport, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		select coalesce(seq,0), id, occurred_at, coalesce(actor_user_id,''), coalesce(actor_org_id,''),
+		       action, resource_type, resource_id, metadata::text, coalesce(trace_id,''),
+		       prev_hash, hash
+		from audit_log
+		order by seq asc nulls first
+	`)
+	if err != nil {
+		return VerifyReport{}, err
+	}
+	defer rows.Close()
+
+	var v Verifier
+	for rows.Next() {
+		ev, err := scanEvent(rows)
+		if err != nil {
+			return VerifyReport{}, err
+		}
+		v.Add(ev)
+	}
+	if err := rows.Err(); err != nil {
+		return VerifyReport{}, err
+	}
+	return v.Report(), nil
+}
+
+func scanEvent(rows *sql.Rows) (Event, error) {
+	var (
+		ev       Event
+		metadata string
+	)
+	if err := rows.Scan(&ev.Seq, &ev.ID, &ev.OccurredAt, &ev.ActorUserID, &ev.ActorOrgID,
+		&ev.Action, &ev.ResourceType, &ev.ResourceID, &metadata, &ev.TraceID,
+		&ev.PrevHash, &ev.Hash); err != nil {
+		return Event{}, err
+	}
+	canonical, err := CanonicalMetadata([]byte(metadata))
+	if err != nil {
+		return Event{}, err
+	}
+	ev.OccurredAt = ev.OccurredAt.UTC()
+	ev.Metadata = canonical
+	return ev, nil
+}
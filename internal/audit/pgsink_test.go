@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestPGSinkWriteChainsToPreviousRow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	ev := Event{
+		ID:           "evt-8",
+		OccurredAt:   at,
+		ActorUserID:  "user-1",
+		Action:       "ledger.account.create",
+		ResourceType: "account",
+		ResourceID:   "acc-1",
+		Metadata:     json.RawMessage(`{"b":"2","a":"1"}`),
+	}
+	want := ev
+	want.Seq = 8
+	want.PrevHash = "prev-hash"
+	want.OccurredAt = at.Truncate(time.Microsecond)
+	want.Metadata = json.RawMessage(`{"a":"1","b":"2"}`)
+	want.Hash = ComputeHash(want)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("select pg_advisory_xact_lock").WithArgs(chainLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("select seq, hash from audit_log").WillReturnRows(sqlmock.NewRows([]string{"seq", "hash"}).AddRow(7, "prev-hash"))
+	mock.ExpectExec("insert into audit_log").
+		WithArgs("evt-8", want.OccurredAt, "user-1", "", "ledger.account.create", "account", "acc-1",
+			`{"a":"1","b":"2"}`, "", int64(8), "prev-hash", want.Hash).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := NewPGSink(db).Write(context.Background(), ev); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestPGSinkVerifyDetectsTampering(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	chain := buildChain(3)
+	cols := []string{"seq", "id", "occurred_at", "actor_user_id", "actor_org_id", "action", "resource_type", "resource_id", "metadata", "trace_id", "prev_hash", "hash"}
+	rows := sqlmock.NewRows(cols)
+	for i, ev := range chain {
+		metadata := `{"amount": "100"}`
+		if i == 1 {
+			metadata = `{"amount": "1"}`
+		}
+		rows.AddRow(ev.Seq, ev.ID, ev.OccurredAt, ev.ActorUserID, ev.ActorOrgID, ev.Action, ev.ResourceType, ev.ResourceID, metadata, ev.TraceID, ev.PrevHash, ev.Hash)
+	}
+	mock.ExpectQuery("from audit_log").WillReturnRows(rows)
+
+	report, err := NewPGSink(db).Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if report.Rows != 3 || len(report.Problems) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if p := report.Problems[0]; p.Seq != 2 || p.Kind != "hash_mismatch" {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+}
+
+func TestPGSinkQueryUsesOnlySetFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cols := []string{"seq", "id", "occurred_at", "actor_user_id", "actor_org_id", "action", "resource_type", "resource_id", "metadata", "trace_id", "prev_hash", "hash"}
+	mock.ExpectQuery(`where seq > \$1 and resource_type = \$2 and resource_id = \$3 and occurred_at >= \$4\s+order by seq asc\s+limit \$5`).
+		WithArgs(int64(10), "account", "acc-1", from, 50).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(int64(11), "evt-11", from, "user-1", "", "ledger.account.create", "account", "acc-1", `{"currency": "QZN"}`, "", "h10", "h11"))
+
+	events, err := NewPGSink(db).Query(context.Background(), Filter{
+		ResourceType: "account",
+		ResourceID:   "acc-1",
+		From:         from,
+		AfterSeq:     10,
+		Limit:        50,
+	})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(events) != 1 || events[0].Seq != 11 || string(events[0].Metadata) != `{"currency":"QZN"}` {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Event is a single audit trail record as persisted by a Sink.
+type Event struct {
+	Seq          int64           `json:"seq"`
+	ID           string          `json:"id"`
+	OccurredAt   time.Time       `json:"occurred_at"`
+	ActorUserID  string          `json:"actor_user_id,omitempty"`
+	ActorOrgID   string          `json:"actor_org_id,omitempty"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Metadata     json.RawMessage `json:"metadata"`
+	TraceID      string          `json:"trace_id,omitempty"`
+	PrevHash     string          `json:"prev_hash"`
+	Hash         string          `json:"hash"`
+}
+
+// Sink persists audit events. Implementations assign Seq, PrevHash and Hash.
+type Sink interface {
+	Write(ctx context.Context, ev Event) error
+}
+
+var (
+	sinkMu sync.RWMutex
+	sink   Sink
+)
+
+// SetSink installs the sink used by LogEvent in addition to the log output.
+// Passing nil disables persistence.
+func SetSink(s Sink) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	sink = s
+}
+
+func currentSink() Sink {
+	sinkMu.RLock()
+	defer sinkMu.RUnlock()
+	return sink
+}
+
+// ComputeHash returns the chain hash of ev. The hash covers every persisted
+// column plus the previous row's hash, so editing, deleting or reordering a
+// row breaks the chain from that point on.
+func ComputeHash(ev Event) string {
+	metadata := ev.Metadata
+	if len(metadata) == 0 {
+		metadata = json.RawMessage(`{}`)
+	}
+	payload, _ := json.Marshal([]any{
+		ev.Seq,
+		ev.ID,
+		ev.OccurredAt.UTC().Format(time.RFC3339Nano),
+		ev.ActorUserID,
+		ev.ActorOrgID,
+		ev.Action,
+		ev.ResourceType,
+		ev.ResourceID,
+		metadata,
+		ev.TraceID,
+		ev.PrevHash,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// CanonicalMetadata re-encodes raw JSON with sorted keys so that the hash is
+// stable across storage round-trips (for example, Postgres jsonb).
+func CanonicalMetadata(raw []byte) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return json.RawMessage(`{}`), nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Problem describes a broken link found while verifying the chain.
+type Problem struct {
+	Seq    int64  `json:"seq"`
+	ID     string `json:"id"`
+	Kind   string `json:"kind"` // gap, chain_break, hash_mismatch
+	Detail string `json:"detail"`
+}
+
+// VerifyReport summarises a chain verification run.
+type VerifyReport struct {
+	Rows     int64     `json:"rows"`
+	HeadSeq  int64     `json:"head_seq"`
+	HeadHash string    `json:"head_hash"`
+	Problems []Problem `json:"problems,omitempty"`
+}
+
+// OK reports whether the chain verified without problems.
+func (r VerifyReport) OK() bool { return len(r.Problems) == 0 }
+
+// Verifier checks events in sequence order. Feed it with Add and read the
+// result with Report; it keeps only the previous row in memory.
+type Verifier struct {
+	report  VerifyReport
+	started bool
+}
+
+// Add checks ev against the previously added event.
+func (v *Verifier) Add(ev Event) {
+	r := &v.report
+	if v.started {
+		if ev.Seq != r.HeadSeq+1 {
+			r.Problems = append(r.Problems, Problem{
+				Seq: ev.Seq, ID: ev.ID, Kind: "gap",
+				Detail: fmt.Sprintf("expected seq %d, got %d", r.HeadSeq+1, ev.Seq),
+			})
+		}
+		if ev.PrevHash != r.HeadHash {
+			r.Problems = append(r.Problems, Problem{
+				Seq: ev.Seq, ID: ev.ID, Kind: "chain_break",
+				Detail: "prev_hash does not match the preceding row",
+			})
+		}
+	} else if ev.Seq == 1 && ev.PrevHash != "" {
+		r.Problems = append(r.Problems, Problem{
+			Seq: ev.Seq, ID: ev.ID, Kind: "chain_break",
+			Detail: "first row must not reference a previous hash",
+		})
+	} else if ev.Seq != 1 {
+		r.Problems = append(r.Problems, Problem{
+			Seq: ev.Seq, ID: ev.ID, Kind: "gap",
+			Detail: fmt.Sprintf("chain starts at seq %d", ev.Seq),
+		})
+	}
+	if want := ComputeHash(ev); want != ev.Hash {
+		r.Problems = append(r.Problems, Problem{
+			Seq: ev.Seq, ID: ev.ID, Kind: "hash_mismatch",
+			Detail: "row content does not match its hash",
+		})
+	}
+	v.started = true
+	r.Rows++
+	r.HeadSeq = ev.Seq
+	r.HeadHash = ev.Hash
+}
+
+// Report returns the verification result so far.
+func (v *Verifier) Report() VerifyReport { return v.report }
+
+// VerifyChain verifies a complete, seq-ordered slice of events.
+func VerifyChain(events []Event) VerifyReport {
+	var v Verifier
+	for _, ev := range events {
+		v.Add(ev)
+	}
+	return v.Report()
+}
+
+// Filter selects audit events. Empty fields match everything; From is
+// inclusive and To exclusive. Results are ordered by Seq after AfterSeq.
+type Filter struct {
+	ActorUserID  string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         time.Time
+	To           time.Time
+	AfterSeq     int64
+	Limit        int
+}
+
+// Reader queries persisted audit events.
+type Reader interface {
+	Query(ctx context.Context, f Filter) ([]Event, error)
+}
+
+func (f Filter) match(ev Event) bool {
+	switch {
+	case ev.Seq <= f.AfterSeq:
+		return false
+	case f.ActorUserID != "" && ev.ActorUserID != f.ActorUserID:
+		return false
+	case f.Action != "" && ev.Action != f.Action:
+		return false
+	case f.ResourceType != "" && ev.ResourceType != f.ResourceType:
+		return false
+	case f.ResourceID != "" && ev.ResourceID != f.ResourceID:
+		return false
+	case !f.From.IsZero() && ev.OccurredAt.Before(f.From):
+		return false
+	case !f.To.IsZero() && !ev.OccurredAt.Before(f.To):
+		return false
+	}
+	return true
+}
+
+func (f Filter) limit() int {
+	if f.Limit <= 0 || f.Limit > 1000 {
+		return 100
+	}
+	return f.Limit
+}
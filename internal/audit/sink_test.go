@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"qazna.org/internal/auth"
+	"qazna.org/internal/obs"
+)
+
+func buildChain(n int) []Event {
+	events := make([]Event, 0, n)
+	prev := ""
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= n; i++ {
+		ev := Event{
+			Seq:          int64(i),
+			ID:           fmt.Sprintf("evt-%d", i),
+			OccurredAt:   base.Add(time.Duration(i) * time.Second),
+			ActorUserID:  "user-1",
+			Action:       "ledger.transfer.execute",
+			ResourceType: "transaction",
+			ResourceID:   fmt.Sprintf("tx-%d", i),
+			Metadata:     json.RawMessage(`{"amount":"100"}`),
+			PrevHash:     prev,
+		}
+		ev.Hash = ComputeHash(ev)
+		prev = ev.Hash
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestVerifyChain(t *testing.T) {
+	t.Run("intact", func(t *testing.T) {
+		report := VerifyChain(buildChain(5))
+		if !report.OK() || report.Rows != 5 || report.HeadSeq != 5 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("edited row", func(t *testing.T) {
+		events := buildChain(5)
+		events[2].Metadata = json.RawMessage(`{"amount":"999"}`)
+		report := VerifyChain(events)
+		if report.OK() || report.Problems[0].Seq != 3 || report.Problems[0].Kind != "hash_mismatch" {
+			t.Fatalf("expected hash mismatch at seq 3, got %+v", report.Problems)
+		}
+	})
+
+	t.Run("deleted row", func(t *testing.T) {
+		events := buildChain(5)
+		events = append(events[:2], events[3:]...)
+		report := VerifyChain(events)
+		kinds := map[string]bool{}
+		for _, p := range report.Problems {
+			if p.Seq != 4 {
+				t.Fatalf("unexpected problem location: %+v", p)
+			}
+			kinds[p.Kind] = true
+		}
+		if !kinds["gap"] || !kinds["chain_break"] {
+			t.Fatalf("expected gap and chain break, got %+v", report.Problems)
+		}
+	})
+
+	t.Run("rehashed edit", func(t *testing.T) {
+		events := buildChain(5)
+		events[1].ResourceID = "tx-forged"
+		events[1].Hash = ComputeHash(events[1])
+		report := VerifyChain(events)
+		if report.OK() || report.Problems[0].Seq != 3 || report.Problems[0].Kind != "chain_break" {
+			t.Fatalf("expected chain break at seq 3, got %+v", report.Problems)
+		}
+	})
+}
+
+func TestCanonicalMetadata(t *testing.T) {
+	got, err := CanonicalMetadata([]byte(`{"b": 1, "a": {"z": "x", "y": 12345678901234567890}}`))
+	if err != nil {
+		t.Fatalf("CanonicalMetadata: %v", err)
+	}
+	if want := `{"a":{"y":12345678901234567890,"z":"x"},"b":1}`; string(got) != want {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+}
+
+type recordingSink struct {
+	events []Event
+}
+
+func (r *recordingSink) Write(_ context.Context, ev Event) error {
+	r.events = append(r.events, ev)
+	return nil
+}
+
+func TestLogEventWritesToSink(t *testing.T) {
+	logger := obs.Logger()
+	original := logger.Writer()
+	logger.SetOutput(&bytes.Buffer{})
+	defer logger.SetOutput(original)
+
+	rec := &recordingSink{}
+	SetSink(rec)
+	defer SetSink(nil)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = auth.ContextWithUser(ctx, "user-42", nil)
+	err := LogEvent(ctx, "ledger.account.create", map[string]any{
+		"resource_type": "account",
+		"resource_id":   "acc-1",
+		"currency":      "QZN",
+	})
+	if err != nil {
+		t.Fatalf("LogEvent: %v", err)
+	}
+	if len(rec.events) != 1 {
+		t.Fatalf("expected one event, got %d", len(rec.events))
+	}
+	ev := rec.events[0]
+	if ev.Action != "ledger.account.create" || ev.ResourceType != "account" || ev.ResourceID != "acc-1" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if ev.ActorUserID != "user-42" || ev.TraceID != "req-1" {
+		t.Fatalf("unexpected actor/trace: %+v", ev)
+	}
+	if string(ev.Metadata) != `{"currency":"QZN"}` {
+		t.Fatalf("unexpected metadata: %s", ev.Metadata)
+	}
+}
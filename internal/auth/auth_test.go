@@ -127,7 +127,7 @@ func TestIssueAndExchangeAuthCode(t *testing.T) {
 	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
 
 	mock.ExpectQuery("select secret, redirect_uri from oauth_clients").WithArgs("demo-client").WillReturnRows(sqlmock.NewRows([]string{"secret", "redirect_uri"}).AddRow("demo-secret", "http://localhost/callback"))
-	mock.ExpectExec("insert into oauth_auth_codes").WithArgs(sqlmock.AnyArg(), "demo-client", challenge, "S256", "http://localhost/callback", "demo-user", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("insert into oauth_auth_codes").WithArgs(sqlmock.AnyArg(), "demo-client", challenge, "S256", "http://localhost/callback", "demo-user", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	code, err := svc.IssueAuthCode(context.Background(), AuthCodeRequest{
 		ClientID:            "demo-client",
@@ -135,7 +135,6 @@ func TestIssueAndExchangeAuthCode(t *testing.T) {
 		CodeChallenge:       challenge,
 		CodeChallengeMethod: "S256",
 		UserID:              "demo-user",
-		Roles:               []string{"admin"},
 	})
 	if err != nil {
 		t.Fatalf("IssueAuthCode: %v", err)
@@ -144,9 +143,12 @@ func TestIssueAndExchangeAuthCode(t *testing.T) {
 		t.Fatalf("expected code")
 	}
 
-	rolesRaw, _ := json.Marshal([]string{"admin"})
 	expires := time.Now().Add(2 * time.Minute)
-	mock.ExpectQuery("select a.code_challenge").WithArgs(code.Code, "demo-client").WillReturnRows(sqlmock.NewRows([]string{"code_challenge", "code_challenge_method", "redirect_uri", "user_id", "roles", "expires_at", "consumed_at", "secret"}).AddRow(challenge, "S256", "http://localhost/callback", "demo-user", rolesRaw, expires, nil, "demo-secret"))
+	mock.ExpectQuery("select a.code_challenge").WithArgs(code.Code, "demo-client").WillReturnRows(sqlmock.NewRows([]string{"code_challenge", "code_challenge_method", "redirect_uri", "user_id", "expires_at", "consumed_at", "secret"}).AddRow(challenge, "S256", "http://localhost/callback", "demo-user", expires, nil, "demo-secret"))
+	// The token carries the user's grants as stored, not what the client asked for.
+	mock.ExpectQuery("select organization_id, email, status from users").WithArgs("demo-user").WillReturnRows(sqlmock.NewRows([]string{"organization_id", "email", "status"}).AddRow("org-demo", "demo@example.com", "active"))
+	mock.ExpectQuery("select distinct r.name").WithArgs("demo-user").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("viewer"))
+	mock.ExpectQuery("select distinct p.key").WithArgs("demo-user").WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("ledger.read"))
 	mock.ExpectExec("update oauth_auth_codes set consumed_at").WithArgs(sqlmock.AnyArg(), code.Code).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	token, exp, err := svc.ExchangeAuthCode(context.Background(), AuthCodeExchangeRequest{
@@ -164,6 +166,25 @@ func TestIssueAndExchangeAuthCode(t *testing.T) {
 	if !exp.After(time.Now()) {
 		t.Fatalf("unexpected expiry: %v", exp)
 	}
+	claims, err := svc.ParseAndValidate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("ParseAndValidate: %v", err)
+	}
+	if claims.Subject != "demo-user" || claims.OrganizationID != "org-demo" || len(claims.Roles) != 1 || claims.Roles[0] != "viewer" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	// Codes of users disabled since are refused.
+	mock.ExpectQuery("select a.code_challenge").WithArgs("code-2", "demo-client").WillReturnRows(sqlmock.NewRows([]string{"code_challenge", "code_challenge_method", "redirect_uri", "user_id", "expires_at", "consumed_at", "secret"}).AddRow(challenge, "S256", "http://localhost/callback", "demo-user", expires, nil, "demo-secret"))
+	mock.ExpectQuery("select organization_id, email, status from users").WithArgs("demo-user").WillReturnRows(sqlmock.NewRows([]string{"organization_id", "email", "status"}).AddRow("org-demo", "demo@example.com", "disabled"))
+	if _, _, err := svc.ExchangeAuthCode(context.Background(), AuthCodeExchangeRequest{
+		ClientID:     "demo-client",
+		ClientSecret: "demo-secret",
+		Code:         "code-2",
+		CodeVerifier: verifier,
+	}); err == nil {
+		t.Fatal("expected the code of a disabled user to be refused")
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Fatalf("unmet expectations: %v", err)
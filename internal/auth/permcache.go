@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultPermissionCacheTTL bounds how long a resolved permission set is
+// reused. Invalidation is local to the process, so this is also the upper
+// bound on staleness when another replica changes role assignments.
+const defaultPermissionCacheTTL = time.Minute
+
+// PermissionCache memoizes resolved permissions per access token. Entries
+// live until the token expires or the cache TTL passes, whichever is first,
+// and are dropped when RBAC changes could affect them.
+type PermissionCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]permissionEntry
+}
+
+type permissionEntry struct {
+	userID  string
+	perms   []string
+	expires time.Time
+}
+
+// NewPermissionCache creates a cache whose entries live at most ttl.
+func NewPermissionCache(ttl time.Duration) *PermissionCache {
+	if ttl <= 0 {
+		ttl = defaultPermissionCacheTTL
+	}
+	return &PermissionCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]permissionEntry),
+	}
+}
+
+// Get returns the cached permissions for tokenID.
+func (c *PermissionCache) Get(tokenID string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[tokenID]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, tokenID)
+		return nil, false
+	}
+	return e.perms, true
+}
+
+// Put caches perms for tokenID until tokenExpiry or the cache TTL.
+func (c *PermissionCache) Put(tokenID, userID string, perms []string, tokenExpiry time.Time) {
+	now := c.now()
+	expires := now.Add(c.ttl)
+	if !tokenExpiry.IsZero() && tokenExpiry.Before(expires) {
+		expires = tokenExpiry
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, id)
+		}
+	}
+	c.entries[tokenID] = permissionEntry{userID: userID, perms: perms, expires: expires}
+}
+
+// InvalidateUser drops every entry resolved for userID.
+func (c *PermissionCache) InvalidateUser(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, e := range c.entries {
+		if e.userID == userID {
+			delete(c.entries, id)
+		}
+	}
+}
+
+// InvalidateAll drops every entry; used when a role's permission set
+// changes and the affected users are not known.
+func (c *PermissionCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]permissionEntry)
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPermissionCacheExpiryAndInvalidation(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewPermissionCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Put("tok-1", "alice", []string{"ledger.read"}, now.Add(time.Hour))
+	c.Put("tok-2", "alice", []string{"ledger.read"}, now.Add(10*time.Second))
+	c.Put("tok-3", "bob", []string{"ledger.transfer"}, now.Add(time.Hour))
+
+	if perms, ok := c.Get("tok-1"); !ok || perms[0] != "ledger.read" {
+		t.Fatalf("expected cached permissions, got %v %v", perms, ok)
+	}
+
+	now = now.Add(30 * time.Second)
+	if _, ok := c.Get("tok-2"); ok {
+		t.Fatalf("entry must not outlive its token")
+	}
+
+	c.InvalidateUser("alice")
+	if _, ok := c.Get("tok-1"); ok {
+		t.Fatalf("expected alice's entries to be dropped")
+	}
+	if _, ok := c.Get("tok-3"); !ok {
+		t.Fatalf("bob's entry should survive")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("tok-3"); ok {
+		t.Fatalf("entry must not outlive the cache ttl")
+	}
+}
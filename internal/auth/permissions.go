@@ -5,4 +5,21 @@ const (
 	PermissionManageUsers         = "auth.manage_users"
 	PermissionManageRoles         = "auth.manage_roles"
 	PermissionManagePermissions   = "auth.manage_permissions"
+	PermissionObserve             = "platform.observe"
+	PermissionScreeningReview     = "screening.review"
+	PermissionNettingManage       = "netting.manage"
+
+	PermissionLedgerRead           = "ledger.read"
+	PermissionLedgerTransfer       = "ledger.transfer"
+	PermissionLedgerAccountCreate  = "ledger.account.create"
+	PermissionLedgerAccountStatus  = "ledger.account.status"
+	PermissionLedgerReverse        = "ledger.reverse"
+	PermissionLedgerFXManage       = "ledger.fx.manage"
+	PermissionLedgerCurrencyManage = "ledger.currency.manage"
+	PermissionLedgerIssuanceManage = "ledger.issuance.manage"
+	PermissionLedgerFeeManage      = "ledger.fee.manage"
+	PermissionLedgerLimitsManage   = "ledger.limits.manage"
+	// PermissionLedgerCrossOrg lifts the organization scope on ledger routes,
+	// for platform operators that act across tenants.
+	PermissionLedgerCrossOrg = "ledger.cross_org"
 )
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -28,12 +29,37 @@ const (
 	UserStatusDisabled = userStatusDisabled
 )
 
+// Participant types classify organizations for the transfer fee model.
+const (
+	ParticipantSovereign   = "sovereign"
+	ParticipantInstitution = "institution"
+	ParticipantCorporate   = "corporate"
+	ParticipantRetail      = "retail"
+)
+
+// DefaultParticipantType is assigned to organizations created without one.
+const DefaultParticipantType = ParticipantCorporate
+
+// NormalizeParticipantType lower-cases t and checks that it is a known
+// participant type; empty means DefaultParticipantType.
+func NormalizeParticipantType(t string) (string, error) {
+	t = strings.ToLower(strings.TrimSpace(t))
+	switch t {
+	case "":
+		return DefaultParticipantType, nil
+	case ParticipantSovereign, ParticipantInstitution, ParticipantCorporate, ParticipantRetail:
+		return t, nil
+	}
+	return "", fmt.Errorf("%w: unknown participant_type %q", ErrInvalidInput, t)
+}
+
 type Organization struct {
-	ID        string         `json:"id"`
-	Name      string         `json:"name"`
-	Metadata  map[string]any `json:"metadata,omitempty"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	ParticipantType string         `json:"participant_type"`
+	Metadata        map[string]any `json:"metadata,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
 }
 
 type User struct {
@@ -62,7 +88,7 @@ type UserRoleAssignment struct {
 }
 
 type RBACStore interface {
-	CreateOrganization(ctx context.Context, name string, metadata map[string]any) (Organization, error)
+	CreateOrganization(ctx context.Context, name, participantType string, metadata map[string]any) (Organization, error)
 	ListOrganizations(ctx context.Context) ([]Organization, error)
 	GetOrganization(ctx context.Context, id string) (Organization, error)
 	UpdateOrganization(ctx context.Context, id string, upd OrganizationUpdate) (Organization, error)
@@ -88,8 +114,9 @@ type RBACStore interface {
 }
 
 type OrganizationUpdate struct {
-	Name     *string
-	Metadata map[string]any
+	Name            *string
+	ParticipantType *string
+	Metadata        map[string]any
 }
 
 type UserUpdate struct {
@@ -105,24 +132,29 @@ type RoleUpdate struct {
 
 type RBACService struct {
 	store RBACStore
+	perms *PermissionCache
 }
 
 func NewRBACService(store RBACStore) (*RBACService, error) {
 	if store == nil {
 		return nil, errors.New("rbac store is required")
 	}
-	return &RBACService{store: store}, nil
+	return &RBACService{store: store, perms: NewPermissionCache(defaultPermissionCacheTTL)}, nil
 }
 
-func (s *RBACService) CreateOrganization(ctx context.Context, name string, metadata map[string]any) (Organization, error) {
+func (s *RBACService) CreateOrganization(ctx context.Context, name, participantType string, metadata map[string]any) (Organization, error) {
 	name = strings.TrimSpace(name)
 	if name == "" {
 		return Organization{}, fmt.Errorf("%w: organization name is required", ErrInvalidInput)
 	}
+	participantType, err := NormalizeParticipantType(participantType)
+	if err != nil {
+		return Organization{}, err
+	}
 	if metadata == nil {
 		metadata = map[string]any{}
 	}
-	return s.store.CreateOrganization(ctx, name, metadata)
+	return s.store.CreateOrganization(ctx, name, participantType, metadata)
 }
 
 func (s *RBACService) ListOrganizations(ctx context.Context) ([]Organization, error) {
@@ -149,6 +181,16 @@ func (s *RBACService) UpdateOrganization(ctx context.Context, id string, upd Org
 		}
 		upd.Name = &trimmed
 	}
+	if upd.ParticipantType != nil {
+		if strings.TrimSpace(*upd.ParticipantType) == "" {
+			return Organization{}, fmt.Errorf("%w: participant_type is required", ErrInvalidInput)
+		}
+		t, err := NormalizeParticipantType(*upd.ParticipantType)
+		if err != nil {
+			return Organization{}, err
+		}
+		upd.ParticipantType = &t
+	}
 	return s.store.UpdateOrganization(ctx, id, upd)
 }
 
@@ -157,7 +199,11 @@ func (s *RBACService) DeleteOrganization(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("%w: organization_id is required", ErrInvalidInput)
 	}
-	return s.store.DeleteOrganization(ctx, id)
+	if err := s.store.DeleteOrganization(ctx, id); err != nil {
+		return err
+	}
+	s.perms.InvalidateAll()
+	return nil
 }
 
 func (s *RBACService) CreateUser(ctx context.Context, organizationID, email, password, status string) (User, error) {
@@ -237,7 +283,12 @@ func (s *RBACService) UpdateUser(ctx context.Context, userID string, upd UserUpd
 		}
 		upd.Password = &hash
 	}
-	return s.store.UpdateUser(ctx, userID, upd)
+	user, err := s.store.UpdateUser(ctx, userID, upd)
+	if err != nil {
+		return User{}, err
+	}
+	s.perms.InvalidateUser(userID)
+	return user, nil
 }
 
 func (s *RBACService) DeleteUser(ctx context.Context, userID string) error {
@@ -245,7 +296,11 @@ func (s *RBACService) DeleteUser(ctx context.Context, userID string) error {
 	if userID == "" {
 		return fmt.Errorf("%w: user_id is required", ErrInvalidInput)
 	}
-	return s.store.DeleteUser(ctx, userID)
+	if err := s.store.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+	s.perms.InvalidateUser(userID)
+	return nil
 }
 
 func (s *RBACService) CreateRole(ctx context.Context, organizationID, name, description string) (Role, error) {
@@ -301,7 +356,11 @@ func (s *RBACService) DeleteRole(ctx context.Context, roleID string) error {
 	if roleID == "" {
 		return fmt.Errorf("%w: role_id is required", ErrInvalidInput)
 	}
-	return s.store.DeleteRole(ctx, roleID)
+	if err := s.store.DeleteRole(ctx, roleID); err != nil {
+		return err
+	}
+	s.perms.InvalidateAll()
+	return nil
 }
 
 func (s *RBACService) SetRolePermissions(ctx context.Context, roleID string, permissions []string) error {
@@ -310,7 +369,11 @@ func (s *RBACService) SetRolePermissions(ctx context.Context, roleID string, per
 		return fmt.Errorf("%w: role_id is required", ErrInvalidInput)
 	}
 	keys := dedupeStrings(permissions)
-	return s.store.SetRolePermissions(ctx, roleID, keys)
+	if err := s.store.SetRolePermissions(ctx, roleID, keys); err != nil {
+		return err
+	}
+	s.perms.InvalidateAll()
+	return nil
 }
 
 func (s *RBACService) AssignRoleToUser(ctx context.Context, userID, roleID string) (UserRoleAssignment, error) {
@@ -319,7 +382,12 @@ func (s *RBACService) AssignRoleToUser(ctx context.Context, userID, roleID strin
 	if userID == "" || roleID == "" {
 		return UserRoleAssignment{}, fmt.Errorf("%w: user_id and role_id are required", ErrInvalidInput)
 	}
-	return s.store.AssignRoleToUser(ctx, userID, roleID)
+	assignment, err := s.store.AssignRoleToUser(ctx, userID, roleID)
+	if err != nil {
+		return UserRoleAssignment{}, err
+	}
+	s.perms.InvalidateUser(userID)
+	return assignment, nil
 }
 
 func (s *RBACService) RemoveRoleAssignment(ctx context.Context, userID, roleID string) error {
@@ -328,7 +396,11 @@ func (s *RBACService) RemoveRoleAssignment(ctx context.Context, userID, roleID s
 	if userID == "" || roleID == "" {
 		return fmt.Errorf("%w: user_id and role_id are required", ErrInvalidInput)
 	}
-	return s.store.RemoveRoleAssignment(ctx, userID, roleID)
+	if err := s.store.RemoveRoleAssignment(ctx, userID, roleID); err != nil {
+		return err
+	}
+	s.perms.InvalidateUser(userID)
+	return nil
 }
 
 func (s *RBACService) ListRoleAssignments(ctx context.Context, userID string) ([]UserRoleAssignment, error) {
@@ -347,6 +419,24 @@ func (s *RBACService) UserPermissions(ctx context.Context, userID string) ([]str
 	return s.store.UserPermissions(ctx, userID)
 }
 
+// TokenPermissions resolves the permissions of the user behind an access
+// token, reusing the result for later requests carrying the same token until
+// a role change invalidates it.
+func (s *RBACService) TokenPermissions(ctx context.Context, tokenID, userID string, expiresAt time.Time) ([]string, error) {
+	if tokenID == "" {
+		return s.UserPermissions(ctx, userID)
+	}
+	if perms, ok := s.perms.Get(tokenID); ok {
+		return perms, nil
+	}
+	perms, err := s.UserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.perms.Put(tokenID, strings.TrimSpace(userID), perms, expiresAt)
+	return perms, nil
+}
+
 func dedupeStrings(values []string) []string {
 	if len(values) == 0 {
 		return nil
@@ -391,3 +481,34 @@ func hashPassword(password string) (string, error) {
 		base64.RawStdEncoding.EncodeToString(hash),
 	), nil
 }
+
+// verifyPassword checks password against an encoded argon2id hash as
+// produced by hashPassword, using the parameters recorded in the hash.
+func verifyPassword(password, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errors.New("unsupported password hash format")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, errors.New("unsupported argon2 version")
+	}
+	var (
+		memory      uint32
+		iterations  uint32
+		parallelism uint8
+	)
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return false, fmt.Errorf("parse argon2 parameters: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("decode salt: %w", err)
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return false, errors.New("decode password hash")
+	}
+	got := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
@@ -34,6 +34,12 @@ var (
 
 type Claims struct {
 	Roles []string `json:"roles"`
+	// Permissions is only present when the issuer embeds permission claims;
+	// see WithPermissionClaims.
+	Permissions []string `json:"permissions,omitempty"`
+	// OrganizationID is the subject's organization. Ledger access is scoped
+	// to it unless the subject holds PermissionLedgerCrossOrg.
+	OrganizationID string `json:"org,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -51,6 +57,9 @@ type Service struct {
 	rotateIn time.Duration
 	codeTTL  time.Duration
 
+	refreshTTL time.Duration
+	permClaims bool
+
 	mu         sync.RWMutex
 	active     *keyRecord
 	verifyMu   sync.RWMutex
@@ -91,6 +100,15 @@ func WithAuthCodeTTL(d time.Duration) Option {
 	}
 }
 
+// WithPermissionClaims embeds the user's resolved permissions in tokens
+// issued by Login and Refresh, so resource servers can authorize without an
+// RBAC lookup. Embedded permissions are fixed until the token expires.
+func WithPermissionClaims(enabled bool) Option {
+	return func(s *Service) {
+		s.permClaims = enabled
+	}
+}
+
 func NewService(db *sql.DB, opts ...Option) (*Service, error) {
 	if db == nil {
 		return nil, errors.New("auth service requires database connection")
@@ -101,6 +119,7 @@ func NewService(db *sql.DB, opts ...Option) (*Service, error) {
 		keyTTL:     defaultKeyTTL,
 		rotateIn:   defaultRotationWindow,
 		codeTTL:    5 * time.Minute,
+		refreshTTL: defaultRefreshTTL,
 		verifyKeys: make(map[string]*rsa.PublicKey),
 	}
 	for _, opt := range opts {
@@ -113,6 +132,22 @@ func NewService(db *sql.DB, opts ...Option) (*Service, error) {
 }
 
 func (s *Service) GenerateToken(ctx context.Context, userID string, roles []string, ttl time.Duration) (string, time.Time, error) {
+	return s.signToken(ctx, userID, "", roles, nil, ttl)
+}
+
+// GenerateTokenWithPermissions issues a token that carries permission claims
+// alongside the roles.
+func (s *Service) GenerateTokenWithPermissions(ctx context.Context, userID string, roles, permissions []string, ttl time.Duration) (string, time.Time, error) {
+	return s.signToken(ctx, userID, "", roles, permissions, ttl)
+}
+
+// GenerateIdentityToken issues a token for ident carrying its organization,
+// roles and permissions.
+func (s *Service) GenerateIdentityToken(ctx context.Context, ident Identity, ttl time.Duration) (string, time.Time, error) {
+	return s.signToken(ctx, ident.UserID, ident.OrganizationID, ident.Roles, ident.Permissions, ttl)
+}
+
+func (s *Service) signToken(ctx context.Context, userID, organizationID string, roles, permissions []string, ttl time.Duration) (string, time.Time, error) {
 	userID = strings.TrimSpace(userID)
 	if userID == "" {
 		return "", time.Time{}, errors.New("userID is required")
@@ -137,7 +172,9 @@ func (s *Service) GenerateToken(ctx context.Context, userID string, roles []stri
 
 	now := time.Now().UTC()
 	claims := Claims{
-		Roles: dedupeRoles(roles),
+		Roles:          dedupeRoles(roles),
+		Permissions:    dedupeStrings(permissions),
+		OrganizationID: strings.TrimSpace(organizationID),
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    s.issuer,
 			Subject:   userID,
@@ -156,13 +193,15 @@ func (s *Service) GenerateToken(ctx context.Context, userID string, roles []stri
 	return signed, now.Add(ttl), nil
 }
 
+// AuthCodeRequest asks for an authorization code on behalf of UserID, who
+// the caller must already have authenticated. The token the code is
+// exchanged for carries the user's current grants, not any the client names.
 type AuthCodeRequest struct {
 	ClientID            string
 	RedirectURI         string
 	CodeChallenge       string
 	CodeChallengeMethod string
 	UserID              string
-	Roles               []string
 }
 
 type AuthCode struct {
@@ -217,24 +256,22 @@ func (s *Service) IssueAuthCode(ctx context.Context, req AuthCodeRequest) (*Auth
 		return nil, errors.New("redirect_uri mismatch")
 	}
 
-	roles := dedupeRoles(req.Roles)
-	rolesJSON, err := json.Marshal(roles)
-	if err != nil {
-		return nil, err
-	}
 	code := uuid.NewString()
 	expires := time.Now().UTC().Add(s.codeTTL)
 
 	if _, err := s.db.ExecContext(ctx, `
 		insert into oauth_auth_codes(code, client_id, code_challenge, code_challenge_method, redirect_uri, user_id, roles, expires_at)
-		values ($1,$2,$3,$4,$5,$6,$7,$8)
-	`, code, req.ClientID, challenge, method, req.RedirectURI, user, rolesJSON, expires); err != nil {
+		values ($1,$2,$3,$4,$5,$6,'[]',$7)
+	`, code, req.ClientID, challenge, method, req.RedirectURI, user, expires); err != nil {
 		return nil, err
 	}
 
 	return &AuthCode{Code: code, RedirectURI: req.RedirectURI, ExpiresAt: expires}, nil
 }
 
+// ExchangeAuthCode redeems an authorization code for an access token. The
+// token carries the organization, roles and permissions the user holds at
+// exchange time; codes of users no longer active are refused.
 func (s *Service) ExchangeAuthCode(ctx context.Context, req AuthCodeExchangeRequest) (string, time.Time, error) {
 	if s.db == nil {
 		return "", time.Time{}, errors.New("auth service missing database connection")
@@ -257,18 +294,17 @@ func (s *Service) ExchangeAuthCode(ctx context.Context, req AuthCodeExchangeRequ
 		method       string
 		redirectURI  string
 		userID       string
-		rolesRaw     []byte
 		expires      time.Time
 		consumed     sql.NullTime
 		clientSecret string
 	)
 	row := s.db.QueryRowContext(ctx, `
-		select a.code_challenge, a.code_challenge_method, a.redirect_uri, a.user_id, a.roles, a.expires_at, a.consumed_at, c.secret
+		select a.code_challenge, a.code_challenge_method, a.redirect_uri, a.user_id, a.expires_at, a.consumed_at, c.secret
 		from oauth_auth_codes a
 		join oauth_clients c on c.id = a.client_id
 		where a.code = $1 and a.client_id = $2
 	`, req.Code, req.ClientID)
-	if err := row.Scan(&challenge, &method, &redirectURI, &userID, &rolesRaw, &expires, &consumed, &clientSecret); err != nil {
+	if err := row.Scan(&challenge, &method, &redirectURI, &userID, &expires, &consumed, &clientSecret); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return "", time.Time{}, errors.New("authorization code not found")
 		}
@@ -299,14 +335,24 @@ func (s *Service) ExchangeAuthCode(ctx context.Context, req AuthCodeExchangeRequ
 		return "", time.Time{}, fmt.Errorf("unsupported code challenge method %s", method)
 	}
 
-	var roles []string
-	if len(rolesRaw) > 0 {
-		if err := json.Unmarshal(rolesRaw, &roles); err != nil {
-			return "", time.Time{}, err
-		}
+	var (
+		ident  = Identity{UserID: userID}
+		status string
+	)
+	err := s.db.QueryRowContext(ctx, `
+		select organization_id, email, status from users where id = $1
+	`, userID).Scan(&ident.OrganizationID, &ident.Email, &status)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", time.Time{}, err
+	}
+	if status != userStatusActive {
+		return "", time.Time{}, errors.New("user is not active")
+	}
+	if err := loadGrants(ctx, s.db, &ident); err != nil {
+		return "", time.Time{}, err
 	}
 
-	token, expiresAt, err := s.GenerateToken(ctx, userID, roles, 15*time.Minute)
+	token, expiresAt, err := s.accessToken(ctx, ident, 15*time.Minute)
 	if err != nil {
 		return "", time.Time{}, err
 	}
@@ -541,6 +587,7 @@ type ctxKey string
 const (
 	userIDKey ctxKey = "auth_user_id"
 	rolesKey  ctxKey = "auth_roles"
+	claimsKey ctxKey = "auth_claims"
 )
 
 func ContextWithUser(ctx context.Context, userID string, roles []string) context.Context {
@@ -551,6 +598,31 @@ func ContextWithUser(ctx context.Context, userID string, roles []string) context
 	return ctx
 }
 
+// ContextWithClaims records the validated token claims so authorization can
+// use the token id, expiry and any embedded permissions.
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	if claims == nil {
+		return ctx
+	}
+	ctx = ContextWithUser(ctx, claims.Subject, claims.Roles)
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	c, ok := ctx.Value(claimsKey).(*Claims)
+	return c, ok && c != nil
+}
+
+// OrganizationIDFromContext returns the organization claim of the caller's
+// token, if any.
+func OrganizationIDFromContext(ctx context.Context) (string, bool) {
+	c, ok := ClaimsFromContext(ctx)
+	if !ok || c.OrganizationID == "" {
+		return "", false
+	}
+	return c.OrganizationID, true
+}
+
 func UserIDFromContext(ctx context.Context) (string, bool) {
 	v, ok := ctx.Value(userIDKey).(string)
 	if !ok || strings.TrimSpace(v) == "" {
@@ -0,0 +1,339 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultRefreshTTL = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when a refresh token that has already
+	// been rotated is presented again. The whole token family is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// dummyPasswordHash is verified against when the email is unknown so that
+// lookups for missing and existing users take comparable time.
+var dummyPasswordHash, _ = hashPassword("qazna-dummy-password")
+
+// Identity is an authenticated user with the roles and permissions granted
+// through user_roles and role_permissions.
+type Identity struct {
+	UserID         string   `json:"user_id"`
+	OrganizationID string   `json:"organization_id"`
+	Email          string   `json:"email"`
+	Roles          []string `json:"roles"`
+	Permissions    []string `json:"permissions"`
+}
+
+// Session is an access token paired with the refresh token that renews it.
+type Session struct {
+	Identity         Identity
+	AccessToken      string
+	ExpiresAt        time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+func WithRefreshTTL(d time.Duration) Option {
+	return func(s *Service) {
+		if d > 0 {
+			s.refreshTTL = d
+		}
+	}
+}
+
+// Authenticate checks email and password against the stored argon2id hash.
+// Unknown emails, disabled users and wrong passwords all yield
+// ErrInvalidCredentials.
+func (s *Service) Authenticate(ctx context.Context, email, password string) (Identity, error) {
+	email = strings.TrimSpace(strings.ToLower(email))
+	// CreateUser and UpdateUser hash the trimmed password.
+	password = strings.TrimSpace(password)
+	if email == "" || password == "" {
+		return Identity{}, ErrInvalidCredentials
+	}
+
+	var (
+		ident  Identity
+		hash   string
+		status string
+	)
+	err := s.db.QueryRowContext(ctx, `
+		select id, organization_id, email, password_hash, status
+		from users
+		where email = $1
+	`, email).Scan(&ident.UserID, &ident.OrganizationID, &ident.Email, &hash, &status)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, _ = verifyPassword(password, dummyPasswordHash)
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Identity{}, err
+	}
+
+	ok, err := verifyPassword(password, hash)
+	if err != nil || !ok || status != userStatusActive {
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err := loadGrants(ctx, s.db, &ident); err != nil {
+		return Identity{}, err
+	}
+	return ident, nil
+}
+
+// Login authenticates the user and opens a new refresh token family.
+func (s *Service) Login(ctx context.Context, email, password string, ttl time.Duration) (Session, error) {
+	ident, err := s.Authenticate(ctx, email, password)
+	if err != nil {
+		return Session{}, err
+	}
+
+	sess := Session{Identity: ident}
+	sess.AccessToken, sess.ExpiresAt, err = s.accessToken(ctx, ident, ttl)
+	if err != nil {
+		return Session{}, err
+	}
+
+	id := uuid.NewString()
+	sess.RefreshToken, sess.RefreshExpiresAt, err = s.insertRefreshToken(ctx, s.db, id, ident.UserID, id)
+	if err != nil {
+		return Session{}, err
+	}
+	return sess, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. The presented token is revoked; presenting it again revokes every
+// token in its family.
+func (s *Service) Refresh(ctx context.Context, refreshToken string, ttl time.Duration) (Session, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return Session{}, ErrInvalidRefreshToken
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Session{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		id, userID, familyID string
+		expiresAt            time.Time
+		revoked              bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		select id, user_id, family_id, expires_at, revoked
+		from refresh_tokens
+		where token_hash = $1
+		for update
+	`, hashRefreshToken(refreshToken)).Scan(&id, &userID, &familyID, &expiresAt, &revoked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Session{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return Session{}, err
+	}
+	if revoked {
+		if err := revokeFamily(ctx, tx, familyID); err != nil {
+			return Session{}, err
+		}
+		if err := tx.Commit(); err != nil {
+			return Session{}, err
+		}
+		return Session{}, ErrRefreshTokenReused
+	}
+	if time.Now().UTC().After(expiresAt) {
+		return Session{}, ErrInvalidRefreshToken
+	}
+
+	var (
+		ident  = Identity{UserID: userID}
+		status string
+	)
+	if err := tx.QueryRowContext(ctx, `
+		select organization_id, email, status from users where id = $1
+	`, userID).Scan(&ident.OrganizationID, &ident.Email, &status); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Session{}, err
+	}
+	if status != userStatusActive {
+		if err := revokeFamily(ctx, tx, familyID); err != nil {
+			return Session{}, err
+		}
+		if err := tx.Commit(); err != nil {
+			return Session{}, err
+		}
+		return Session{}, ErrInvalidRefreshToken
+	}
+	// Roles are re-read on every refresh so grants changed since login apply.
+	if err := loadGrants(ctx, tx, &ident); err != nil {
+		return Session{}, err
+	}
+
+	sess := Session{Identity: ident}
+	newID := uuid.NewString()
+	sess.RefreshToken, sess.RefreshExpiresAt, err = s.insertRefreshToken(ctx, tx, newID, userID, familyID)
+	if err != nil {
+		return Session{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		update refresh_tokens set revoked = true, revoked_at = $2, replaced_by = $3
+		where id = $1
+	`, id, time.Now().UTC(), newID); err != nil {
+		return Session{}, err
+	}
+	sess.AccessToken, sess.ExpiresAt, err = s.accessToken(ctx, ident, ttl)
+	if err != nil {
+		return Session{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Session{}, err
+	}
+	return sess, nil
+}
+
+// RevokeRefreshToken revokes the family the token belongs to, ending that
+// login session. It returns the owning user id.
+func (s *Service) RevokeRefreshToken(ctx context.Context, refreshToken string) (string, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return "", ErrInvalidRefreshToken
+	}
+	var userID, familyID string
+	err := s.db.QueryRowContext(ctx, `
+		select user_id, family_id from refresh_tokens where token_hash = $1
+	`, hashRefreshToken(refreshToken)).Scan(&userID, &familyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := revokeFamily(ctx, s.db, familyID); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// RevokeUserTokens revokes every outstanding refresh token of the user and
+// reports how many were revoked.
+func (s *Service) RevokeUserTokens(ctx context.Context, userID string) (int64, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return 0, fmt.Errorf("%w: user_id is required", ErrInvalidInput)
+	}
+	res, err := s.db.ExecContext(ctx, `
+		update refresh_tokens set revoked = true, revoked_at = $2
+		where user_id = $1 and not revoked
+	`, userID, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *Service) accessToken(ctx context.Context, ident Identity, ttl time.Duration) (string, time.Time, error) {
+	if s.permClaims {
+		return s.GenerateIdentityToken(ctx, ident, ttl)
+	}
+	return s.signToken(ctx, ident.UserID, ident.OrganizationID, ident.Roles, nil, ttl)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (s *Service) insertRefreshToken(ctx context.Context, db execer, id, userID, familyID string) (string, time.Time, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, fmt.Errorf("generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	now := time.Now().UTC()
+	expires := now.Add(s.refreshTTL)
+	if _, err := db.ExecContext(ctx, `
+		insert into refresh_tokens(id, user_id, family_id, token_hash, expires_at, created_at)
+		values ($1,$2,$3,$4,$5,$6)
+	`, id, userID, familyID, hashRefreshToken(token), expires, now); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expires, nil
+}
+
+func revokeFamily(ctx context.Context, db execer, familyID string) error {
+	_, err := db.ExecContext(ctx, `
+		update refresh_tokens set revoked = true, revoked_at = $2
+		where family_id = $1 and not revoked
+	`, familyID, time.Now().UTC())
+	return err
+}
+
+// loadGrants fills in the role names and permission keys of ident.UserID.
+func loadGrants(ctx context.Context, db querier, ident *Identity) error {
+	roles, err := queryStrings(ctx, db, `
+		select distinct r.name
+		from user_roles ur
+		join roles r on r.id = ur.role_id
+		where ur.user_id = $1
+		order by r.name
+	`, ident.UserID)
+	if err != nil {
+		return err
+	}
+	perms, err := queryStrings(ctx, db, `
+		select distinct p.key
+		from user_roles ur
+		join role_permissions rp on rp.role_id = ur.role_id
+		join permissions p on p.id = rp.permission_id
+		where ur.user_id = $1
+		order by p.key
+	`, ident.UserID)
+	if err != nil {
+		return err
+	}
+	ident.Roles = roles
+	ident.Permissions = perms
+	return nil
+}
+
+func queryStrings(ctx context.Context, db querier, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// hashRefreshToken is what gets stored; the raw token is only ever returned
+// to the client.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newSessionTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	mock.ExpectQuery("select kid, private_pem, public_pem, expires_at.*from auth_keys").WillReturnError(sql.ErrNoRows)
+	mock.ExpectBegin()
+	mock.ExpectExec("update auth_keys set status = 'retired'").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("insert into auth_keys").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	svc, err := NewService(db, WithIssuer("test"), WithKeyTTL(time.Hour), WithRotateWindow(15*time.Minute))
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	return svc, mock
+}
+
+func expectGrants(mock sqlmock.Sqlmock, userID string, roles, perms []string) {
+	roleRows := sqlmock.NewRows([]string{"name"})
+	for _, r := range roles {
+		roleRows.AddRow(r)
+	}
+	permRows := sqlmock.NewRows([]string{"key"})
+	for _, p := range perms {
+		permRows.AddRow(p)
+	}
+	mock.ExpectQuery("select distinct r.name").WithArgs(userID).WillReturnRows(roleRows)
+	mock.ExpectQuery("select distinct p.key").WithArgs(userID).WillReturnRows(permRows)
+}
+
+func TestVerifyPassword(t *testing.T) {
+	hash, err := hashPassword("s3cret")
+	if err != nil {
+		t.Fatalf("hashPassword: %v", err)
+	}
+	if ok, err := verifyPassword("s3cret", hash); err != nil || !ok {
+		t.Fatalf("expected match, got ok=%v err=%v", ok, err)
+	}
+	if ok, _ := verifyPassword("wrong", hash); ok {
+		t.Fatalf("expected mismatch")
+	}
+	if ok, _ := verifyPassword("s3cret", "$argon2id$v=19$m=65536,t=2,p=1$demo$hash"); ok {
+		t.Fatalf("expected placeholder seed hash to never match")
+	}
+	if _, err := verifyPassword("s3cret", "plaintext"); err == nil {
+		t.Fatalf("expected malformed hash to be rejected")
+	}
+}
+
+func TestLoginIssuesSessionFromStoredGrants(t *testing.T) {
+	svc, mock := newSessionTestService(t)
+	hash, _ := hashPassword("s3cret")
+
+	mock.ExpectQuery("select id, organization_id, email, password_hash, status").WithArgs("ops@bank.kz").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organization_id", "email", "password_hash", "status"}).
+			AddRow("usr-1", "org-1", "ops@bank.kz", hash, "active"))
+	expectGrants(mock, "usr-1", []string{"operator"}, []string{"ledger.transfer"})
+	mock.ExpectExec("insert into refresh_tokens").
+		WithArgs(sqlmock.AnyArg(), "usr-1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	sess, err := svc.Login(context.Background(), " Ops@Bank.kz ", "s3cret", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if sess.RefreshToken == "" || sess.Identity.OrganizationID != "org-1" {
+		t.Fatalf("unexpected session: %+v", sess)
+	}
+	claims, err := svc.ParseAndValidate(context.Background(), sess.AccessToken)
+	if err != nil {
+		t.Fatalf("ParseAndValidate: %v", err)
+	}
+	if claims.Subject != "usr-1" || claims.OrganizationID != "org-1" || len(claims.Roles) != 1 || claims.Roles[0] != "operator" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAuthenticateRejectsBadCredentials(t *testing.T) {
+	svc, mock := newSessionTestService(t)
+	hash, _ := hashPassword("s3cret")
+	cols := []string{"id", "organization_id", "email", "password_hash", "status"}
+
+	mock.ExpectQuery("from users").WithArgs("ops@bank.kz").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("usr-1", "org-1", "ops@bank.kz", hash, "active"))
+	if _, err := svc.Authenticate(context.Background(), "ops@bank.kz", "guess"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password: expected ErrInvalidCredentials, got %v", err)
+	}
+
+	mock.ExpectQuery("from users").WithArgs("ops@bank.kz").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("usr-1", "org-1", "ops@bank.kz", hash, "disabled"))
+	if _, err := svc.Authenticate(context.Background(), "ops@bank.kz", "s3cret"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("disabled user: expected ErrInvalidCredentials, got %v", err)
+	}
+
+	mock.ExpectQuery("from users").WithArgs("nobody@bank.kz").WillReturnError(sql.ErrNoRows)
+	if _, err := svc.Authenticate(context.Background(), "nobody@bank.kz", "s3cret"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("unknown user: expected ErrInvalidCredentials, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	svc, mock := newSessionTestService(t)
+	old := "old-refresh-token"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("from refresh_tokens").WithArgs(hashRefreshToken(old)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "expires_at", "revoked"}).
+			AddRow("rt-1", "usr-1", "fam-1", time.Now().Add(time.Hour), false))
+	mock.ExpectQuery("select organization_id, email, status from users").WithArgs("usr-1").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "email", "status"}).AddRow("org-1", "ops@bank.kz", "active"))
+	expectGrants(mock, "usr-1", []string{"operator", "reviewer"}, []string{"ledger.transfer"})
+	mock.ExpectExec("insert into refresh_tokens").
+		WithArgs(sqlmock.AnyArg(), "usr-1", "fam-1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("update refresh_tokens set revoked = true, revoked_at = \\$2, replaced_by = \\$3").
+		WithArgs("rt-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	sess, err := svc.Refresh(context.Background(), old, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if sess.RefreshToken == "" || sess.RefreshToken == old || len(sess.Identity.Roles) != 2 {
+		t.Fatalf("unexpected session: %+v", sess)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	svc, mock := newSessionTestService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("from refresh_tokens").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "expires_at", "revoked"}).
+			AddRow("rt-1", "usr-1", "fam-1", time.Now().Add(time.Hour), true))
+	mock.ExpectExec("update refresh_tokens set revoked = true.*where family_id = \\$1").
+		WithArgs("fam-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	if _, err := svc.Refresh(context.Background(), "stolen", 5*time.Minute); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("expected ErrRefreshTokenReused, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestLoginEmbedsPermissionClaims(t *testing.T) {
+	svc, mock := newSessionTestService(t)
+	WithPermissionClaims(true)(svc)
+	hash, _ := hashPassword("s3cret")
+
+	mock.ExpectQuery("from users").WithArgs("ops@bank.kz").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organization_id", "email", "password_hash", "status"}).
+			AddRow("usr-1", "org-1", "ops@bank.kz", hash, "active"))
+	expectGrants(mock, "usr-1", []string{"operator"}, []string{"ledger.read", "ledger.transfer"})
+	mock.ExpectExec("insert into refresh_tokens").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	sess, err := svc.Login(context.Background(), "ops@bank.kz", "s3cret", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	claims, err := svc.ParseAndValidate(context.Background(), sess.AccessToken)
+	if err != nil {
+		t.Fatalf("ParseAndValidate: %v", err)
+	}
+	if len(claims.Permissions) != 2 || claims.Permissions[1] != "ledger.transfer" {
+		t.Fatalf("expected embedded permissions, got %v", claims.Permissions)
+	}
+}
@@ -0,0 +1,183 @@
+package httpapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"qazna.org/internal/audit"
+	"qazna.org/internal/auth"
+)
+
+// exportPageSize bounds each query issued while streaming an export.
+const exportPageSize = 1000
+
+type listAuditEventsResponse struct {
+	Items     []audit.Event `json:"items"`
+	NextAfter int64         `json:"next_after"`
+	AsOf      time.Time     `json:"as_of"`
+}
+
+func (a *API) handleAuditEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionObserve) {
+		return
+	}
+	if a.auditLog == nil {
+		writeError(w, r, http.StatusServiceUnavailable, "audit store unavailable")
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch auditFormat(r) {
+	case "ndjson":
+		a.exportAuditEvents(w, r, filter, "application/x-ndjson", func(w http.ResponseWriter) func(audit.Event) error {
+			enc := json.NewEncoder(w)
+			return func(ev audit.Event) error { return enc.Encode(ev) }
+		})
+	case "csv":
+		a.exportAuditEvents(w, r, filter, "text/csv; charset=utf-8", func(w http.ResponseWriter) func(audit.Event) error {
+			cw := csv.NewWriter(w)
+			_ = cw.Write([]string{"seq", "id", "occurred_at", "actor_user_id", "actor_org_id", "action", "resource_type", "resource_id", "trace_id", "metadata", "prev_hash", "hash"})
+			return func(ev audit.Event) error {
+				err := cw.Write([]string{
+					strconv.FormatInt(ev.Seq, 10),
+					ev.ID,
+					ev.OccurredAt.UTC().Format(time.RFC3339Nano),
+					ev.ActorUserID,
+					ev.ActorOrgID,
+					ev.Action,
+					ev.ResourceType,
+					ev.ResourceID,
+					ev.TraceID,
+					string(ev.Metadata),
+					ev.PrevHash,
+					ev.Hash,
+				})
+				cw.Flush()
+				if err != nil {
+					return err
+				}
+				return cw.Error()
+			}
+		})
+	case "json":
+		items, err := a.auditLog.Query(r.Context(), filter)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "audit query failed")
+			return
+		}
+		resp := listAuditEventsResponse{
+			Items: items,
+			AsOf:  time.Now().UTC(),
+		}
+		if resp.Items == nil {
+			resp.Items = []audit.Event{}
+		}
+		if n := len(items); n > 0 {
+			resp.NextAfter = items[n-1].Seq
+		}
+		writeJSON(w, http.StatusOK, resp)
+	default:
+		writeError(w, r, http.StatusBadRequest, "format must be json, ndjson or csv")
+	}
+}
+
+// exportAuditEvents streams every event matching filter, starting after the
+// cursor, paging through the store so large exports stay bounded in memory.
+func (a *API) exportAuditEvents(w http.ResponseWriter, r *http.Request, filter audit.Filter, contentType string, newWriter func(http.ResponseWriter) func(audit.Event) error) {
+	filter.Limit = exportPageSize
+	first, err := a.auditLog.Query(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "audit query failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	write := newWriter(w)
+
+	page := first
+	for {
+		for _, ev := range page {
+			if err := write(ev); err != nil {
+				return
+			}
+		}
+		if len(page) < filter.Limit {
+			return
+		}
+		filter.AfterSeq = page[len(page)-1].Seq
+		if page, err = a.auditLog.Query(r.Context(), filter); err != nil {
+			// Headers are already sent; truncating the stream is all we can do.
+			return
+		}
+	}
+}
+
+func auditFormat(r *http.Request) string {
+	if f := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format"))); f != "" {
+		return f
+	}
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "application/x-ndjson"):
+		return "ndjson"
+	case strings.Contains(accept, "text/csv"):
+		return "csv"
+	default:
+		return "json"
+	}
+}
+
+func parseAuditFilter(r *http.Request) (audit.Filter, error) {
+	q := r.URL.Query()
+	limit, err := parsePositiveInt(q.Get("limit"), 100, 1, 1000)
+	if err != nil {
+		return audit.Filter{}, err
+	}
+	f := audit.Filter{
+		ActorUserID:  strings.TrimSpace(q.Get("actor")),
+		Action:       strings.TrimSpace(q.Get("action")),
+		ResourceType: strings.TrimSpace(q.Get("resource_type")),
+		ResourceID:   strings.TrimSpace(q.Get("resource_id")),
+		Limit:        limit,
+	}
+	if raw := strings.TrimSpace(q.Get("after")); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 0 {
+			return audit.Filter{}, errors.New("after must be a non-negative integer")
+		}
+		f.AfterSeq = v
+	}
+	if raw := strings.TrimSpace(q.Get("from")); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return audit.Filter{}, errors.New("from must be an RFC3339 timestamp")
+		}
+		f.From = t
+	}
+	if raw := strings.TrimSpace(q.Get("to")); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return audit.Filter{}, errors.New("to must be an RFC3339 timestamp")
+		}
+		f.To = t
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return audit.Filter{}, errors.New("from must be before to")
+	}
+	return f, nil
+}
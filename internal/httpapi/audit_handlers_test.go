@@ -0,0 +1,135 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"qazna.org/internal/audit"
+	"qazna.org/internal/auth"
+)
+
+func seedAuditEvents(t *testing.T) *audit.MemorySink {
+	t.Helper()
+	sink := audit.NewMemorySink()
+	base := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	events := []audit.Event{
+		{OccurredAt: base, ActorUserID: "alice", Action: "ledger.account.create", ResourceType: "account", ResourceID: "acc-1"},
+		{OccurredAt: base.Add(time.Minute), ActorUserID: "bob", Action: "ledger.transfer.execute", ResourceType: "transaction", ResourceID: "tx-1"},
+		{OccurredAt: base.Add(2 * time.Minute), ActorUserID: "alice", Action: "ledger.transfer.execute", ResourceType: "transaction", ResourceID: "tx-2"},
+		{OccurredAt: base.Add(3 * time.Minute), ActorUserID: "alice", Action: "ledger.account.create", ResourceType: "account", ResourceID: "acc-2"},
+	}
+	for _, ev := range events {
+		if err := sink.Write(context.Background(), ev); err != nil {
+			t.Fatalf("seed audit event: %v", err)
+		}
+	}
+	return sink
+}
+
+func observerStore() *stubRBACStore {
+	return &stubRBACStore{
+		userPermissionsFn: func(_ context.Context, _ string) ([]string, error) {
+			return []string{auth.PermissionObserve}, nil
+		},
+	}
+}
+
+func TestAuditEventsRequiresPermission(t *testing.T) {
+	store := &stubRBACStore{
+		userPermissionsFn: func(_ context.Context, _ string) ([]string, error) {
+			return []string{auth.PermissionManageUsers}, nil
+		},
+	}
+	api := newTestAPI(t, store, WithAuditReader(seedAuditEvents(t)))
+	token := api.obtainToken("operator", []string{"admin"})
+
+	resp := api.get("/v1/audit/events", nil, map[string]string{"Authorization": "Bearer " + token})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestAuditEventsFiltersAndPagination(t *testing.T) {
+	api := newTestAPI(t, observerStore(), WithAuditReader(seedAuditEvents(t)))
+	token := api.obtainToken("auditor", []string{"auditor"})
+	headers := map[string]string{"Authorization": "Bearer " + token}
+
+	resp := api.get("/v1/audit/events", url.Values{"actor": {"alice"}, "limit": {"2"}}, headers)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %d", resp.StatusCode)
+	}
+	page := decode[listAuditEventsResponse](t, resp)
+	if len(page.Items) != 2 || page.Items[0].ResourceID != "acc-1" || page.Items[1].ResourceID != "tx-2" {
+		t.Fatalf("unexpected first page: %+v", page.Items)
+	}
+
+	resp = api.get("/v1/audit/events", url.Values{
+		"actor": {"alice"},
+		"limit": {"2"},
+		"after": {strconv.FormatInt(page.NextAfter, 10)},
+	}, headers)
+	page = decode[listAuditEventsResponse](t, resp)
+	if len(page.Items) != 1 || page.Items[0].ResourceID != "acc-2" {
+		t.Fatalf("unexpected second page: %+v", page.Items)
+	}
+
+	resp = api.get("/v1/audit/events", url.Values{
+		"resource_type": {"transaction"},
+		"from":          {"2025-05-01T10:01:30Z"},
+		"to":            {"2025-05-01T11:00:00Z"},
+	}, headers)
+	page = decode[listAuditEventsResponse](t, resp)
+	if len(page.Items) != 1 || page.Items[0].ResourceID != "tx-2" {
+		t.Fatalf("unexpected time range result: %+v", page.Items)
+	}
+
+	resp = api.get("/v1/audit/events", url.Values{"from": {"yesterday"}}, headers)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad time, got %d", resp.StatusCode)
+	}
+}
+
+func TestAuditEventsExport(t *testing.T) {
+	api := newTestAPI(t, observerStore(), WithAuditReader(seedAuditEvents(t)))
+	token := api.obtainToken("auditor", []string{"auditor"})
+
+	resp := api.get("/v1/audit/events", url.Values{"action": {"ledger.transfer.execute"}}, map[string]string{
+		"Authorization": "Bearer " + token,
+		"Accept":        "application/x-ndjson",
+	})
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+	var events []audit.Event
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var ev audit.Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			t.Fatalf("invalid ndjson line: %v", err)
+		}
+		events = append(events, ev)
+	}
+	resp.Body.Close()
+	if len(events) != 2 || events[0].ResourceID != "tx-1" || events[1].ResourceID != "tx-2" {
+		t.Fatalf("unexpected ndjson export: %+v", events)
+	}
+
+	resp = api.get("/v1/audit/events", url.Values{"format": {"csv"}}, map[string]string{"Authorization": "Bearer " + token})
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	if len(records) != 5 || records[0][0] != "seq" || records[4][7] != "acc-2" {
+		t.Fatalf("unexpected csv export: %v", records)
+	}
+}
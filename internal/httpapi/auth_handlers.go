@@ -1,6 +1,7 @@
 package httpapi
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -10,8 +11,14 @@ import (
 )
 
 type tokenRequest struct {
-	User  string   `json:"user"`
-	Roles []string `json:"roles"`
+	GrantType    string `json:"grant_type"`
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type tokenResponse struct {
@@ -19,13 +26,23 @@ type tokenResponse struct {
 	ExpiresAt time.Time `json:"expires_at"`
 }
 
+type sessionResponse struct {
+	Token            string    `json:"token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	UserID           string    `json:"user_id"`
+	OrganizationID   string    `json:"organization_id"`
+	Roles            []string  `json:"roles"`
+	Permissions      []string  `json:"permissions"`
+}
+
 type oauthAuthorizeRequest struct {
-	ClientID            string   `json:"client_id"`
-	RedirectURI         string   `json:"redirect_uri"`
-	CodeChallenge       string   `json:"code_challenge"`
-	CodeChallengeMethod string   `json:"code_challenge_method"`
-	User                string   `json:"user"`
-	Roles               []string `json:"roles"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
 }
 
 type authCodeResponse struct {
@@ -59,43 +76,137 @@ func (a *API) handleAuthToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user := strings.TrimSpace(req.User)
-	if user == "" {
-		writeError(w, r, http.StatusBadRequest, "user is required")
+	switch strings.TrimSpace(req.GrantType) {
+	case "", "password":
+		a.passwordGrant(w, r, req.Email, req.Password)
+	case "refresh_token":
+		a.refreshGrant(w, r, req.RefreshToken)
+	default:
+		writeError(w, r, http.StatusBadRequest, "grant_type must be password or refresh_token")
+	}
+}
+
+func (a *API) handleAuthRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
 		return
 	}
-	roles := make([]string, 0, len(req.Roles))
-	for _, role := range req.Roles {
-		role = strings.TrimSpace(role)
-		if role == "" {
-			continue
-		}
-		roles = append(roles, role)
+	if a.auth == nil {
+		writeError(w, r, http.StatusNotImplemented, "authentication service unavailable")
+		return
+	}
+	var req refreshTokenRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	a.refreshGrant(w, r, req.RefreshToken)
+}
+
+// handleAuthRevoke ends the login session the refresh token belongs to.
+// Revoking an unknown token is not an error so clients can always log out.
+func (a *API) handleAuthRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	if a.auth == nil {
+		writeError(w, r, http.StatusNotImplemented, "authentication service unavailable")
+		return
+	}
+	var req refreshTokenRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(req.RefreshToken) == "" {
+		writeError(w, r, http.StatusBadRequest, "refresh_token is required")
+		return
 	}
-	if len(roles) == 0 {
-		writeError(w, r, http.StatusBadRequest, "roles are required")
+	userID, err := a.auth.RevokeRefreshToken(r.Context(), req.RefreshToken)
+	switch {
+	case errors.Is(err, auth.ErrInvalidRefreshToken):
+	case err != nil:
+		writeError(w, r, http.StatusInternalServerError, "token revocation failed")
 		return
+	default:
+		ctx := auth.ContextWithUser(r.Context(), userID, nil)
+		a.audit(ctx, "auth.session.revoke", "user", userID, nil)
 	}
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	token, expiresAt, err := a.auth.GenerateToken(r.Context(), user, roles, tokenTTL)
+func (a *API) passwordGrant(w http.ResponseWriter, r *http.Request, email, password string) {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" || password == "" {
+		writeError(w, r, http.StatusBadRequest, "email and password are required")
+		return
+	}
+	sess, err := a.auth.Login(r.Context(), email, password, tokenTTL)
 	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			a.audit(r.Context(), "auth.login.failed", "user", "", map[string]string{
+				"email": email,
+			})
+			setWWWAuthenticate(w, "invalid_grant", "invalid email or password")
+			writeError(w, r, http.StatusUnauthorized, "invalid email or password")
+			return
+		}
 		writeError(w, r, http.StatusInternalServerError, "token generation failed")
 		return
 	}
+	a.writeSession(w, r, "auth.token.issued", "password", sess)
+}
 
-	fields := map[string]any{
-		"user":       user,
-		"roles":      roles,
-		"expires_at": expiresAt.Format(time.RFC3339),
+func (a *API) refreshGrant(w http.ResponseWriter, r *http.Request, refreshToken string) {
+	if strings.TrimSpace(refreshToken) == "" {
+		writeError(w, r, http.StatusBadRequest, "refresh_token is required")
+		return
 	}
-	_ = audit.LogEvent(r.Context(), "auth.token.issued", fields)
+	sess, err := a.auth.Refresh(r.Context(), refreshToken, tokenTTL)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrRefreshTokenReused):
+			a.audit(r.Context(), "auth.refresh.reuse_detected", "", "", nil)
+		case errors.Is(err, auth.ErrInvalidRefreshToken):
+		default:
+			writeError(w, r, http.StatusInternalServerError, "token refresh failed")
+			return
+		}
+		setWWWAuthenticate(w, "invalid_grant", "refresh token is invalid or expired")
+		writeError(w, r, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+	a.writeSession(w, r, "auth.token.refreshed", "refresh_token", sess)
+}
 
-	writeJSON(w, http.StatusOK, tokenResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
+func (a *API) writeSession(w http.ResponseWriter, r *http.Request, action, grant string, sess auth.Session) {
+	ident := sess.Identity
+	ctx := auth.ContextWithUser(r.Context(), ident.UserID, ident.Roles)
+	a.audit(ctx, action, "user", ident.UserID, map[string]string{
+		"grant_type":      grant,
+		"organization_id": ident.OrganizationID,
+		"roles":           strings.Join(ident.Roles, ","),
+		"expires_at":      sess.ExpiresAt.Format(time.RFC3339),
+	})
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, sessionResponse{
+		Token:            sess.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        sess.ExpiresAt,
+		RefreshToken:     sess.RefreshToken,
+		RefreshExpiresAt: sess.RefreshExpiresAt,
+		UserID:           ident.UserID,
+		OrganizationID:   ident.OrganizationID,
+		Roles:            ident.Roles,
+		Permissions:      ident.Permissions,
 	})
 }
 
+// handleOAuthAuthorize issues an authorization code to the signed-in caller.
+// The route is not public: the code is bound to the user of the bearer
+// token, and the token it is exchanged for carries that user's grants.
 func (a *API) handleOAuthAuthorize(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		methodNotAllowed(w, r, http.MethodPost)
@@ -112,13 +223,11 @@ func (a *API) handleOAuthAuthorize(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	roles := make([]string, 0, len(req.Roles))
-	for _, role := range req.Roles {
-		role = strings.TrimSpace(role)
-		if role == "" {
-			continue
-		}
-		roles = append(roles, role)
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		setWWWAuthenticate(w, "invalid_token", "missing authentication context")
+		writeError(w, r, http.StatusUnauthorized, "authentication required")
+		return
 	}
 
 	code, err := a.auth.IssueAuthCode(r.Context(), auth.AuthCodeRequest{
@@ -126,8 +235,7 @@ func (a *API) handleOAuthAuthorize(w http.ResponseWriter, r *http.Request) {
 		RedirectURI:         req.RedirectURI,
 		CodeChallenge:       req.CodeChallenge,
 		CodeChallengeMethod: req.CodeChallengeMethod,
-		UserID:              req.User,
-		Roles:               roles,
+		UserID:              userID,
 	})
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err.Error())
@@ -135,7 +243,7 @@ func (a *API) handleOAuthAuthorize(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fields := map[string]any{
-		"user":                  strings.TrimSpace(req.User),
+		"user":                  userID,
 		"client_id":             strings.TrimSpace(req.ClientID),
 		"code_challenge_method": strings.TrimSpace(req.CodeChallengeMethod),
 	}
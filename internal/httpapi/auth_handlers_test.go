@@ -0,0 +1,139 @@
+package httpapi
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/crypto/argon2"
+)
+
+func testPasswordHash(password string) string {
+	salt := []byte("qazna-test-salt!")
+	key := argon2.IDKey([]byte(password), salt, 1, 8*1024, 1, 32)
+	return fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$%s$%s", 8*1024, 1, 1,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func TestPasswordGrantIssuesSession(t *testing.T) {
+	api := newTestAPI(t, nil)
+
+	api.mock.ExpectQuery("from users").WithArgs("ops@bank.kz").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organization_id", "email", "password_hash", "status"}).
+			AddRow("usr-ops", "org-bank", "ops@bank.kz", testPasswordHash("s3cret"), "active"))
+	api.mock.ExpectQuery("select distinct r.name").WithArgs("usr-ops").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("operator"))
+	api.mock.ExpectQuery("select distinct p.key").WithArgs("usr-ops").
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("ledger.transfer"))
+	api.mock.ExpectExec("insert into refresh_tokens").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	resp := api.post("/v1/auth/token", map[string]any{
+		"grant_type": "password",
+		"email":      "ops@bank.kz",
+		"password":   "s3cret",
+	}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if cc := resp.Header.Get("Cache-Control"); cc != "no-store" {
+		t.Fatalf("expected Cache-Control no-store, got %q", cc)
+	}
+	sess := decode[sessionResponse](t, resp)
+	if sess.Token == "" || sess.RefreshToken == "" || sess.TokenType != "Bearer" {
+		t.Fatalf("unexpected session: %+v", sess)
+	}
+	if sess.UserID != "usr-ops" || len(sess.Permissions) != 1 || sess.Permissions[0] != "ledger.transfer" {
+		t.Fatalf("unexpected grants: %+v", sess)
+	}
+	if !sess.RefreshExpiresAt.After(sess.ExpiresAt) {
+		t.Fatalf("refresh token should outlive the access token: %+v", sess)
+	}
+}
+
+func TestPasswordGrantRejectsWrongPassword(t *testing.T) {
+	api := newTestAPI(t, nil)
+
+	api.mock.ExpectQuery("from users").WithArgs("ops@bank.kz").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organization_id", "email", "password_hash", "status"}).
+			AddRow("usr-ops", "org-bank", "ops@bank.kz", testPasswordHash("s3cret"), "active"))
+
+	resp := api.post("/v1/auth/token", map[string]any{"email": "ops@bank.kz", "password": "guess"}, nil)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("WWW-Authenticate") == "" {
+		t.Fatalf("expected WWW-Authenticate header")
+	}
+}
+
+func TestTokenEndpointRejectsLegacyRoleClaims(t *testing.T) {
+	api := newTestAPI(t, nil)
+
+	resp := api.post("/v1/auth/token", map[string]any{"user": "demo", "roles": []string{"admin"}}, nil)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestRefreshEndpoint(t *testing.T) {
+	api := newTestAPI(t, nil)
+
+	t.Run("unknown token", func(t *testing.T) {
+		api.mock.ExpectBegin()
+		api.mock.ExpectQuery("from refresh_tokens").WillReturnError(sql.ErrNoRows)
+		api.mock.ExpectRollback()
+
+		resp := api.post("/v1/auth/refresh", map[string]any{"refresh_token": "nope"}, nil)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("rotates", func(t *testing.T) {
+		api.mock.ExpectBegin()
+		api.mock.ExpectQuery("from refresh_tokens").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "expires_at", "revoked"}).
+				AddRow("rt-1", "usr-ops", "rt-1", time.Now().Add(time.Hour), false))
+		api.mock.ExpectQuery("select organization_id, email, status from users").WithArgs("usr-ops").
+			WillReturnRows(sqlmock.NewRows([]string{"organization_id", "email", "status"}).AddRow("org-bank", "ops@bank.kz", "active"))
+		api.mock.ExpectQuery("select distinct r.name").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("operator"))
+		api.mock.ExpectQuery("select distinct p.key").WillReturnRows(sqlmock.NewRows([]string{"key"}))
+		api.mock.ExpectExec("insert into refresh_tokens").WillReturnResult(sqlmock.NewResult(1, 1))
+		api.mock.ExpectExec("update refresh_tokens set revoked = true").WillReturnResult(sqlmock.NewResult(0, 1))
+		api.mock.ExpectCommit()
+
+		resp := api.post("/v1/auth/token", map[string]any{
+			"grant_type":    "refresh_token",
+			"refresh_token": "current",
+		}, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		sess := decode[sessionResponse](t, resp)
+		if sess.RefreshToken == "" || sess.RefreshToken == "current" {
+			t.Fatalf("expected a rotated refresh token, got %+v", sess)
+		}
+	})
+}
+
+func TestRevokeEndpoint(t *testing.T) {
+	api := newTestAPI(t, nil)
+
+	api.mock.ExpectQuery("select user_id, family_id from refresh_tokens").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "family_id"}).AddRow("usr-ops", "fam-1"))
+	api.mock.ExpectExec("update refresh_tokens set revoked = true").WithArgs("fam-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp := api.post("/v1/auth/revoke", map[string]any{"refresh_token": "current"}, nil)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+}
@@ -15,8 +15,9 @@ const (
 
 var publicPaths = []string{
 	"/v1/auth/token",
+	"/v1/auth/refresh",
+	"/v1/auth/revoke",
 	"/v1/auth/oauth/token",
-	"/v1/auth/oauth/authorize",
 	"/metrics",
 	"/healthz",
 	"/readyz",
@@ -59,7 +60,7 @@ func (a *API) withAuth(next http.Handler) http.Handler {
 			return
 		}
 
-		ctx := auth.ContextWithUser(r.Context(), claims.Subject, claims.Roles)
+		ctx := auth.ContextWithClaims(r.Context(), claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -93,18 +94,16 @@ func (a *API) ensurePermissions(w http.ResponseWriter, r *http.Request, perms ..
 	if len(perms) == 0 {
 		return true
 	}
-	if a == nil || a.rbac == nil {
+	granted, err := a.callerPermissions(r)
+	switch {
+	case errors.Is(err, errRBACUnavailable):
 		writeError(w, r, http.StatusServiceUnavailable, "rbac service unavailable")
 		return false
-	}
-	userID, ok := auth.UserIDFromContext(r.Context())
-	if !ok {
+	case errors.Is(err, errUnauthenticated):
 		setWWWAuthenticate(w, "invalid_token", "missing authentication context")
 		writeError(w, r, http.StatusUnauthorized, "authentication required")
 		return false
-	}
-	granted, err := a.rbac.UserPermissions(r.Context(), userID)
-	if err != nil {
+	case err != nil:
 		writeError(w, r, http.StatusInternalServerError, "permission lookup failed")
 		return false
 	}
@@ -116,6 +115,33 @@ func (a *API) ensurePermissions(w http.ResponseWriter, r *http.Request, perms ..
 	return true
 }
 
+var (
+	errRBACUnavailable = errors.New("rbac service unavailable")
+	errUnauthenticated = errors.New("authentication required")
+)
+
+// callerPermissions resolves the permissions of the authenticated caller:
+// embedded token claims first, then the per-token cache, then RBAC.
+func (a *API) callerPermissions(r *http.Request) ([]string, error) {
+	claims, _ := auth.ClaimsFromContext(r.Context())
+	embedded := claims != nil && len(claims.Permissions) > 0
+	if !embedded && (a == nil || a.rbac == nil) {
+		return nil, errRBACUnavailable
+	}
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		return nil, errUnauthenticated
+	}
+	switch {
+	case embedded:
+		return claims.Permissions, nil
+	case claims != nil && claims.ExpiresAt != nil:
+		return a.rbac.TokenPermissions(r.Context(), claims.ID, userID, claims.ExpiresAt.Time)
+	default:
+		return a.rbac.UserPermissions(r.Context(), userID)
+	}
+}
+
 func hasAllPermissions(granted []string, required []string) bool {
 	if len(required) == 0 {
 		return true
@@ -1,8 +1,10 @@
 package httpapi
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"qazna.org/internal/auth"
@@ -59,3 +61,90 @@ func TestRequireRoleRejectsMissingUser(t *testing.T) {
 		t.Fatalf("expected WWW-Authenticate header set")
 	}
 }
+
+func TestBankOperatorTransfersWithoutAdmin(t *testing.T) {
+	store := &stubRBACStore{
+		userPermissionsFn: func(_ context.Context, userID string) ([]string, error) {
+			if userID == "treasurer" {
+				return []string{auth.PermissionLedgerAccountCreate, auth.PermissionLedgerRead}, nil
+			}
+			return []string{auth.PermissionLedgerTransfer, auth.PermissionLedgerRead}, nil
+		},
+	}
+	api := newTestAPI(t, store)
+	treasurer := map[string]string{"Authorization": "Bearer " + api.obtainToken("treasurer", []string{"treasury"})}
+	operator := map[string]string{"Authorization": "Bearer " + api.obtainToken("operator", []string{"bank_operator"})}
+
+	from := decode[map[string]any](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 500}, treasurer))
+	to := decode[map[string]any](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 0}, treasurer))
+
+	resp := api.post("/v1/accounts", map[string]any{"currency": "QZN"}, operator)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("operator account create: expected 403, got %d", resp.StatusCode)
+	}
+
+	resp = api.post("/v1/transfers", map[string]any{
+		"from_id":  from["id"],
+		"to_id":    to["id"],
+		"currency": "QZN",
+		"amount":   100,
+	}, operator)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("operator transfer: expected 201, got %d", resp.StatusCode)
+	}
+}
+
+func TestPermissionsCachedPerTokenUntilRoleChange(t *testing.T) {
+	var lookups atomic.Int32
+	store := &stubRBACStore{
+		userPermissionsFn: func(_ context.Context, _ string) ([]string, error) {
+			lookups.Add(1)
+			return []string{auth.PermissionLedgerRead, auth.PermissionManageUsers}, nil
+		},
+		assignRoleFn: func(_ context.Context, userID, roleID string) (auth.UserRoleAssignment, error) {
+			return auth.UserRoleAssignment{UserID: userID, RoleID: roleID}, nil
+		},
+	}
+	api := newTestAPI(t, store)
+	headers := map[string]string{"Authorization": "Bearer " + api.obtainToken("ops", []string{"operator"})}
+
+	for i := 0; i < 3; i++ {
+		resp := api.get("/v1/ledger/transactions", nil, headers)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+	}
+	if n := lookups.Load(); n != 1 {
+		t.Fatalf("expected a single permission lookup, got %d", n)
+	}
+
+	resp := api.post("/v1/users/ops/assignments", map[string]any{"role_id": "role-2"}, headers)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("assign role: expected 201, got %d", resp.StatusCode)
+	}
+	resp = api.get("/v1/ledger/transactions", nil, headers)
+	resp.Body.Close()
+	if n := lookups.Load(); n != 2 {
+		t.Fatalf("expected role change to invalidate the cache, got %d lookups", n)
+	}
+}
+
+func TestEmbeddedPermissionClaimsSkipRBAC(t *testing.T) {
+	api := newTestAPI(t, nil)
+	token := api.obtainTokenWithPermissions("auditor", auth.PermissionLedgerRead)
+
+	resp := api.get("/v1/ledger/transactions", nil, map[string]string{"Authorization": "Bearer " + token})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/transfers", map[string]any{}, map[string]string{"Authorization": "Bearer " + token})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.StatusCode)
+	}
+}
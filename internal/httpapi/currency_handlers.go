@@ -0,0 +1,256 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ledger"
+)
+
+// putCurrencyRequest updates a currency; omitted fields keep their current
+// value. New currencies must give an exponent and start enabled.
+type putCurrencyRequest struct {
+	Name      *string `json:"name"`
+	Exponent  *int    `json:"exponent"`
+	Enabled   *bool   `json:"enabled"`
+	MinAmount *int64  `json:"min_amount"`
+	MaxAmount *int64  `json:"max_amount"`
+}
+
+// handleCurrencies serves GET /v1/currencies, GET /v1/currencies/{code} and
+// PUT /v1/currencies/{code}.
+func (a *API) handleCurrencies(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToUpper(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/currencies"), "/"))
+	if code == "" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) || !a.requireCurrencies(w, r) {
+			return
+		}
+		items, err := a.currencies.ListCurrencies(r.Context())
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": items})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) || !a.requireCurrencies(w, r) {
+			return
+		}
+		c, err := a.currencies.GetCurrency(r.Context(), code)
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, c)
+	case http.MethodPut:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerCurrencyManage) || !a.requireCurrencies(w, r) {
+			return
+		}
+		a.putCurrency(w, r, code)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPut)
+	}
+}
+
+func (a *API) putCurrency(w http.ResponseWriter, r *http.Request, code string) {
+	var req putCurrencyRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	c, err := a.currencies.GetCurrency(r.Context(), code)
+	created := errors.Is(err, ledger.ErrNotFound)
+	switch {
+	case created:
+		if req.Exponent == nil {
+			writeError(w, r, http.StatusBadRequest, "exponent is required for a new currency")
+			return
+		}
+		c = ledger.Currency{Code: code, Enabled: true}
+	case err != nil:
+		handleLedgerError(w, r, err)
+		return
+	}
+	if req.Name != nil {
+		c.Name = *req.Name
+	}
+	if req.Exponent != nil {
+		c.Exponent = *req.Exponent
+	}
+	if req.Enabled != nil {
+		c.Enabled = *req.Enabled
+	}
+	if req.MinAmount != nil {
+		c.MinAmount = *req.MinAmount
+	}
+	if req.MaxAmount != nil {
+		c.MaxAmount = *req.MaxAmount
+	}
+
+	c, err = a.currencies.PutCurrency(r.Context(), c)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	a.audit(r.Context(), "ledger.currency.put", "currency", c.Code, map[string]string{
+		"exponent":   strconv.Itoa(c.Exponent),
+		"enabled":    strconv.FormatBool(c.Enabled),
+		"min_amount": strconv.FormatInt(c.MinAmount, 10),
+		"max_amount": strconv.FormatInt(c.MaxAmount, 10),
+	})
+	status := http.StatusOK
+	if created {
+		w.Header().Set("Location", "/v1/currencies/"+c.Code)
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, c)
+}
+
+func (a *API) requireCurrencies(w http.ResponseWriter, r *http.Request) bool {
+	if a.currencies == nil {
+		writeError(w, r, http.StatusServiceUnavailable, "currency registry unavailable")
+		return false
+	}
+	return true
+}
+
+// amountFormatter renders minor units in major units for one response,
+// looking each currency up once. Currencies it cannot resolve format as
+// empty strings, which the views omit.
+type amountFormatter struct {
+	ctx  context.Context
+	reg  ledger.CurrencyRegistry
+	exps map[string]int
+}
+
+func (a *API) formatter(ctx context.Context) *amountFormatter {
+	return &amountFormatter{ctx: ctx, reg: a.currencies, exps: make(map[string]int)}
+}
+
+func (f *amountFormatter) format(currency string, amount int64) string {
+	exp, ok := f.exponent(currency)
+	if !ok {
+		return ""
+	}
+	return ledger.FormatAmount(amount, exp)
+}
+
+// exponent returns the minor-unit digits of currency, or false when it
+// cannot be resolved.
+func (f *amountFormatter) exponent(currency string) (int, bool) {
+	exp, ok := f.exps[currency]
+	if !ok {
+		exp = -1
+		if f.reg != nil {
+			if c, err := f.reg.GetCurrency(f.ctx, currency); err == nil {
+				exp = c.Exponent
+			}
+		} else if c, ok := ledger.BuiltinCurrency(currency); ok {
+			exp = c.Exponent
+		}
+		f.exps[currency] = exp
+	}
+	return exp, exp >= 0
+}
+
+// The views below add major-unit renderings next to the minor-unit amounts
+// of ledger types.
+
+type accountView struct {
+	ledger.Account
+	FormattedBalances map[string]string `json:"formatted_balances"`
+}
+
+func (f *amountFormatter) account(acc ledger.Account) accountView {
+	v := accountView{Account: acc, FormattedBalances: make(map[string]string, len(acc.Balances))}
+	for cur, amt := range acc.Balances {
+		if s := f.format(cur, amt); s != "" {
+			v.FormattedBalances[cur] = s
+		}
+	}
+	return v
+}
+
+type balanceView struct {
+	ledger.Balance
+	FormattedAmount    string `json:"formatted_amount,omitempty"`
+	FormattedHeld      string `json:"formatted_held,omitempty"`
+	FormattedAvailable string `json:"formatted_available,omitempty"`
+}
+
+func (f *amountFormatter) balance(b ledger.Balance) balanceView {
+	return balanceView{
+		Balance:            b,
+		FormattedAmount:    f.format(b.Currency, b.Amount),
+		FormattedHeld:      f.format(b.Currency, b.Held),
+		FormattedAvailable: f.format(b.Currency, b.Available),
+	}
+}
+
+type historicalBalanceView struct {
+	ledger.HistoricalBalance
+	FormattedAmount string `json:"formatted_amount,omitempty"`
+}
+
+func (f *amountFormatter) historicalBalance(b ledger.HistoricalBalance) historicalBalanceView {
+	return historicalBalanceView{HistoricalBalance: b, FormattedAmount: f.format(b.Currency, b.Amount)}
+}
+
+type entryView struct {
+	ledger.Entry
+	FormattedAmount string `json:"formatted_amount,omitempty"`
+}
+
+type transactionView struct {
+	ledger.Transaction
+	FormattedAmount string      `json:"formatted_amount,omitempty"`
+	FormattedFee    string      `json:"formatted_fee,omitempty"`
+	Entries         []entryView `json:"entries,omitempty"`
+}
+
+func (f *amountFormatter) transaction(tx ledger.Transaction) transactionView {
+	v := transactionView{Transaction: tx}
+	if tx.Currency != "" {
+		v.FormattedAmount = f.format(tx.Currency, tx.Amount)
+	}
+	if tx.Fee != nil {
+		v.FormattedFee = f.format(tx.Fee.Currency, tx.Fee.Amount)
+	}
+	for _, e := range tx.Entries {
+		v.Entries = append(v.Entries, entryView{Entry: e, FormattedAmount: f.format(e.Currency, e.Amount)})
+	}
+	return v
+}
+
+func (f *amountFormatter) transactions(txs []ledger.Transaction) []transactionView {
+	out := make([]transactionView, 0, len(txs))
+	for _, tx := range txs {
+		out = append(out, f.transaction(tx))
+	}
+	return out
+}
+
+type holdView struct {
+	ledger.Hold
+	FormattedAmount         string `json:"formatted_amount,omitempty"`
+	FormattedCapturedAmount string `json:"formatted_captured_amount,omitempty"`
+}
+
+func (f *amountFormatter) hold(h ledger.Hold) holdView {
+	v := holdView{Hold: h, FormattedAmount: f.format(h.Currency, h.Amount)}
+	if h.CapturedAmount != 0 {
+		v.FormattedCapturedAmount = f.format(h.Currency, h.CapturedAmount)
+	}
+	return v
+}
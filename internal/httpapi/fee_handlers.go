@@ -0,0 +1,169 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ledger"
+)
+
+type createFeeScheduleRequest struct {
+	Rates         map[string]string `json:"rates"`
+	Alpha         string            `json:"alpha"`
+	Beta          string            `json:"beta"`
+	Rounding      string            `json:"rounding"`
+	AccountID     string            `json:"account_id"`
+	EffectiveFrom *time.Time        `json:"effective_from"`
+}
+
+// handleFeeSchedules serves GET and POST /v1/fees/schedules. Schedules are
+// never changed once added; a new version replaces the current one from its
+// effective_from.
+func (a *API) handleFeeSchedules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) || !a.requireFees(w, r) {
+			return
+		}
+		schedules, err := a.fees.FeeSchedules(r.Context())
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		if schedules == nil {
+			schedules = []ledger.FeeSchedule{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": schedules})
+	case http.MethodPost:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerFeeManage) || !a.requireFees(w, r) {
+			return
+		}
+		var req createFeeScheduleRequest
+		if err := decodeJSON(w, r, &req); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		in := ledger.FeeSchedule{
+			Rates:     req.Rates,
+			Alpha:     req.Alpha,
+			Beta:      req.Beta,
+			Rounding:  ledger.Rounding(req.Rounding),
+			AccountID: req.AccountID,
+		}
+		if req.EffectiveFrom != nil {
+			in.EffectiveFrom = *req.EffectiveFrom
+		}
+		fs, err := a.fees.AddFeeSchedule(r.Context(), in)
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		a.audit(r.Context(), "ledger.fee.schedule.create", "fee_schedule", strconv.Itoa(fs.Version), map[string]string{
+			"alpha":          fs.Alpha,
+			"beta":           fs.Beta,
+			"rounding":       string(fs.Rounding),
+			"account_id":     fs.AccountID,
+			"effective_from": fs.EffectiveFrom.Format(time.RFC3339),
+		})
+		writeJSON(w, http.StatusCreated, fs)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+	}
+}
+
+// feeCharger routes transfers and hold captures through the fee engine when
+// the ledger has one, so every entry point charges the payer the same fee.
+type feeCharger struct {
+	ledger ledger.Service
+	fees   ledger.FeeEngine
+	rbac   *auth.RBACService
+}
+
+func (a *API) feeCharger() feeCharger {
+	return feeCharger{ledger: a.ledger, fees: a.fees, rbac: a.rbac}
+}
+
+func (c feeCharger) transfer(ctx context.Context, fromID, toID string, amt ledger.Money, idem string) (ledger.Transaction, error) {
+	if c.fees == nil {
+		return c.ledger.Transfer(ctx, fromID, toID, amt, idem)
+	}
+	participant, err := c.participantType(ctx, fromID)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	return c.fees.TransferWithFee(ctx, fromID, toID, amt, participant, idem)
+}
+
+func (c feeCharger) captureHold(ctx context.Context, id string, amount int64) (ledger.Hold, ledger.Transaction, error) {
+	if c.fees == nil {
+		return c.ledger.CaptureHold(ctx, id, amount)
+	}
+	h, err := c.ledger.GetHold(ctx, id)
+	if err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	participant, err := c.participantType(ctx, h.FromAccountID)
+	if err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	return c.fees.CaptureHoldWithFee(ctx, id, amount, participant)
+}
+
+// quote returns the fee the payer fromID would be charged on amt now, nil
+// without a fee engine or a schedule in force.
+func (c feeCharger) quote(ctx context.Context, fromID string, amt ledger.Money) (*ledger.Fee, error) {
+	if c.fees == nil {
+		return nil, nil
+	}
+	schedules, err := c.fees.FeeSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schedule, ok := ledger.CurrentFeeSchedule(schedules, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	participant, err := c.participantType(ctx, fromID)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := schedule.Compute(participant, amt)
+	if err != nil {
+		return nil, err
+	}
+	return &fee, nil
+}
+
+// participantType classifies the payer of a transfer for the fee schedule by
+// the organization owning the debited account. Accounts without an
+// organization, or deployments without RBAC, classify as no participant type
+// and pay no fee.
+func (c feeCharger) participantType(ctx context.Context, fromID string) (string, error) {
+	acc, err := c.ledger.GetAccount(ctx, fromID)
+	if err != nil {
+		return "", err
+	}
+	if acc.OrganizationID == "" || c.rbac == nil {
+		return "", nil
+	}
+	org, err := c.rbac.GetOrganization(ctx, acc.OrganizationID)
+	if errors.Is(err, auth.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return org.ParticipantType, nil
+}
+
+func (a *API) requireFees(w http.ResponseWriter, r *http.Request) bool {
+	if a.fees == nil {
+		writeError(w, r, http.StatusServiceUnavailable, "fee engine unavailable")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,258 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ledger"
+)
+
+type fxTransferRequest struct {
+	FromID         string `json:"from_id"`
+	ToID           string `json:"to_id"`
+	Currency       string `json:"currency"`
+	Amount         int64  `json:"amount"`
+	TargetCurrency string `json:"target_currency"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+type createFXRateRequest struct {
+	Base      string     `json:"base"`
+	Quote     string     `json:"quote"`
+	Rate      string     `json:"rate"`
+	ValidFrom *time.Time `json:"valid_from,omitempty"`
+	ValidTo   *time.Time `json:"valid_to,omitempty"`
+}
+
+type fxLiquidityRequest struct {
+	AccountID string `json:"account_id"`
+}
+
+func (a *API) handleFXTransfers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionLedgerTransfer) {
+		return
+	}
+
+	var req fxTransferRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	idem, err := idempotencyKey(r, req.IdempotencyKey)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	fromID := strings.TrimSpace(req.FromID)
+	toID := strings.TrimSpace(req.ToID)
+	if fromID == "" || toID == "" {
+		writeError(w, r, http.StatusBadRequest, "from_id and to_id are required")
+		return
+	}
+	if len(fromID) > 64 || len(toID) > 64 {
+		writeError(w, r, http.StatusBadRequest, "account identifiers must be <=64 characters")
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	target := strings.ToUpper(strings.TrimSpace(req.TargetCurrency))
+	if currency == "" || target == "" {
+		writeError(w, r, http.StatusBadRequest, "currency and target_currency are required")
+		return
+	}
+	if len(currency) > 8 || len(target) > 8 {
+		writeError(w, r, http.StatusBadRequest, "currency code too long")
+		return
+	}
+	if currency == target {
+		writeError(w, r, http.StatusBadRequest, "target_currency must differ from currency")
+		return
+	}
+	if req.Amount <= 0 {
+		writeError(w, r, http.StatusBadRequest, "amount must be > 0")
+		return
+	}
+
+	ctx := a.ledgerContext(r)
+	amt := ledger.Money{Currency: currency, Amount: req.Amount}
+	if err := a.transferScreen().posting(ctx, fromID, toID, amt); err != nil {
+		handleScreenedError(w, r, err)
+		return
+	}
+	start := time.Now().UTC()
+	tx, err := a.ledger.FXTransfer(ctx, fromID, toID, amt, target, idem)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	replayed := idem != "" && !tx.CreatedAt.After(start)
+	if idem != "" {
+		w.Header().Set("Idempotency-Key", idem)
+	}
+
+	meta := map[string]string{
+		"from_account":    fromID,
+		"to_account":      toID,
+		"currency":        currency,
+		"amount":          strconv.FormatInt(req.Amount, 10),
+		"target_currency": target,
+		"fx_rate_id":      tx.FXRateID,
+		"fx_rate":         tx.FXRate,
+	}
+	if idem != "" {
+		meta["idempotency_key"] = idem
+	}
+	event := "ledger.fx.transfer"
+	if replayed {
+		event = "ledger.fx.transfer.idempotent_replay"
+	}
+	a.audit(r.Context(), event, "transaction", tx.ID, meta)
+
+	writeJSON(w, http.StatusCreated, a.formatter(r.Context()).transaction(tx))
+}
+
+func (a *API) handleFXRates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) || !a.requireFX(w, r) {
+			return
+		}
+		q := r.URL.Query()
+		base := strings.ToUpper(strings.TrimSpace(q.Get("base")))
+		quote := strings.ToUpper(strings.TrimSpace(q.Get("quote")))
+		rates, err := a.fx.ListFXRates(r.Context(), base, quote)
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		if rates == nil {
+			rates = []ledger.FXRate{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": rates})
+	case http.MethodPost:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerFXManage) || !a.requireFX(w, r) {
+			return
+		}
+		var req createFXRateRequest
+		if err := decodeJSON(w, r, &req); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		in := ledger.FXRate{Base: req.Base, Quote: req.Quote, Rate: req.Rate}
+		if req.ValidFrom != nil {
+			in.ValidFrom = *req.ValidFrom
+		}
+		if req.ValidTo != nil {
+			in.ValidTo = *req.ValidTo
+		}
+		rate, err := a.fx.AddFXRate(r.Context(), in)
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		meta := map[string]string{
+			"base":       rate.Base,
+			"quote":      rate.Quote,
+			"rate":       rate.Rate,
+			"valid_from": rate.ValidFrom.Format(time.RFC3339),
+		}
+		if !rate.ValidTo.IsZero() {
+			meta["valid_to"] = rate.ValidTo.Format(time.RFC3339)
+		}
+		a.audit(r.Context(), "ledger.fx.rate.create", "fx_rate", rate.ID, meta)
+		writeJSON(w, http.StatusCreated, rate)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (a *API) handleFXRateResource(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1/fx/rates/")
+	id, action, ok := strings.Cut(path, "/")
+	if !ok || action != "expire" || id == "" {
+		writeError(w, r, http.StatusNotFound, "resource not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionLedgerFXManage) || !a.requireFX(w, r) {
+		return
+	}
+	rate, err := a.fx.ExpireFXRate(r.Context(), id)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	a.audit(r.Context(), "ledger.fx.rate.expire", "fx_rate", rate.ID, map[string]string{
+		"base":     rate.Base,
+		"quote":    rate.Quote,
+		"valid_to": rate.ValidTo.Format(time.RFC3339),
+	})
+	writeJSON(w, http.StatusOK, rate)
+}
+
+// handleFXLiquidity serves GET /v1/fx/liquidity and
+// PUT /v1/fx/liquidity/{currency}.
+func (a *API) handleFXLiquidity(w http.ResponseWriter, r *http.Request) {
+	currency := strings.ToUpper(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/fx/liquidity"), "/"))
+	if currency == "" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) || !a.requireFX(w, r) {
+			return
+		}
+		accounts, err := a.fx.FXLiquidityAccounts(r.Context())
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"accounts": accounts})
+		return
+	}
+
+	if r.Method != http.MethodPut {
+		methodNotAllowed(w, r, http.MethodPut)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionLedgerFXManage) || !a.requireFX(w, r) {
+		return
+	}
+	if len(currency) > 8 {
+		writeError(w, r, http.StatusBadRequest, "currency code too long")
+		return
+	}
+	var req fxLiquidityRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	accountID := strings.TrimSpace(req.AccountID)
+	if accountID == "" {
+		writeError(w, r, http.StatusBadRequest, "account_id is required")
+		return
+	}
+	if err := a.fx.SetFXLiquidityAccount(r.Context(), currency, accountID); err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	a.audit(r.Context(), "ledger.fx.liquidity.set", "account", accountID, map[string]string{"currency": currency})
+	writeJSON(w, http.StatusOK, map[string]string{"currency": currency, "account_id": accountID})
+}
+
+func (a *API) requireFX(w http.ResponseWriter, r *http.Request) bool {
+	if a.fx == nil {
+		writeError(w, r, http.StatusServiceUnavailable, "fx registry unavailable")
+		return false
+	}
+	return true
+}
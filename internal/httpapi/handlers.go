@@ -16,7 +16,9 @@ import (
 	"qazna.org/internal/audit"
 	"qazna.org/internal/auth"
 	"qazna.org/internal/ledger"
+	"qazna.org/internal/netting"
 	"qazna.org/internal/obs"
+	"qazna.org/internal/screening"
 	"qazna.org/internal/stream"
 )
 
@@ -46,11 +48,99 @@ type API struct {
 	auth        *auth.Service
 	rbac        *auth.RBACService
 	templates   *template.Template
+	auditLog    audit.Reader
+	fx          ledger.FXRegistry
+	currencies  ledger.CurrencyRegistry
+	issuance    ledger.Issuance
+	fees        ledger.FeeEngine
+	limits      ledger.Limits
+	screener    screening.Screener
+	reviews     screening.ReviewQueue
+	netting     *netting.Manager
+	cycles      netting.Store
+	outbox      bool // stream events come from the outbox dispatcher
+	funding     bool // accounts may be created with an initial amount
 	bodyMaxSize int64
 	rateBurst   int
 	ratePerSec  int
 }
 
+// Option customises the API.
+type Option func(*API)
+
+// WithAuditReader enables GET /v1/audit/events backed by r.
+func WithAuditReader(r audit.Reader) Option {
+	return func(a *API) {
+		a.auditLog = r
+	}
+}
+
+// WithOutboxStream stops handlers from publishing transfer events to the
+// stream themselves, for deployments where an outbox dispatcher feeds it
+// with every committed posting.
+func WithOutboxStream() Option {
+	return func(a *API) {
+		a.outbox = true
+	}
+}
+
+// WithFXRegistry sets the registry behind the FX rate and liquidity account
+// endpoints. It defaults to the ledger service when that implements
+// ledger.FXRegistry.
+func WithFXRegistry(reg ledger.FXRegistry) Option {
+	return func(a *API) {
+		a.fx = reg
+	}
+}
+
+// WithCurrencyRegistry sets the registry behind the currency endpoints and
+// the formatted amounts in responses. It defaults to the ledger service when
+// that implements ledger.CurrencyRegistry.
+func WithCurrencyRegistry(reg ledger.CurrencyRegistry) Option {
+	return func(a *API) {
+		a.currencies = reg
+	}
+}
+
+// WithScreener screens transfers, FX transfers, holds and their captures
+// with s before they are committed. Held transfers wait in the review queue,
+// which defaults to the ledger service when that implements
+// screening.ReviewQueue and to an in-memory queue otherwise; held postings
+// of the other kinds are refused.
+func WithScreener(s screening.Screener) Option {
+	return func(a *API) {
+		a.screener = s
+	}
+}
+
+// WithReviewQueue sets the queue behind the screening review endpoints.
+func WithReviewQueue(q screening.ReviewQueue) Option {
+	return func(a *API) {
+		a.reviews = q
+	}
+}
+
+// WithNettingStore sets the store behind the netting cycle endpoints. It
+// defaults to the ledger service when that implements netting.Store and to
+// an in-memory store otherwise, except for a durable ledger: cycles kept in
+// memory would not survive a restart that the ledger does, so without a
+// store of its own netting is unavailable.
+func WithNettingStore(s netting.Store) Option {
+	return func(a *API) {
+		a.cycles = s
+	}
+}
+
+// WithInitialFunding accepts accounts created with a non-zero
+// initial_amount, for backends without issuance and legacy clients. The
+// funding is not a ledger transaction; supply reports it as opening. By
+// default money only enters circulation through POST /v1/mint.
+func WithInitialFunding() Option {
+	return func(a *API) {
+		a.funding = true
+	}
+}
+
 func New(
 	r readinessChecker,
 	version string,
@@ -59,6 +149,7 @@ func New(
 	tmpl *template.Template,
 	authSvc *auth.Service,
 	rbacSvc *auth.RBACService,
+	opts ...Option,
 ) *API {
 	a := &API{
 		mux:         http.NewServeMux(),
@@ -74,6 +165,49 @@ func New(
 		ratePerSec:  200,
 	}
 
+	for _, opt := range opts {
+		opt(a)
+	}
+	if reg, ok := ledgerService.(ledger.FXRegistry); ok && a.fx == nil {
+		a.fx = reg
+	}
+	if reg, ok := ledgerService.(ledger.CurrencyRegistry); ok && a.currencies == nil {
+		a.currencies = reg
+	}
+	if iss, ok := ledgerService.(ledger.Issuance); ok {
+		a.issuance = iss
+	}
+	if fees, ok := ledgerService.(ledger.FeeEngine); ok {
+		a.fees = fees
+	}
+	if limits, ok := ledgerService.(ledger.Limits); ok {
+		a.limits = limits
+	}
+	if a.screener != nil && a.reviews == nil {
+		if q, ok := ledgerService.(screening.ReviewQueue); ok {
+			a.reviews = q
+		} else {
+			a.reviews = screening.NewMemoryQueue()
+		}
+	}
+	if a.cycles == nil {
+		if s, ok := ledgerService.(netting.Store); ok {
+			a.cycles = s
+		} else if _, durable := ledgerService.(*ledger.Durable); !durable {
+			a.cycles = netting.NewMemoryStore()
+		}
+	}
+	if a.cycles != nil {
+		nettingOpts := []netting.Option{netting.WithFees(a.quoteNettingFee)}
+		if a.currencies != nil {
+			nettingOpts = append(nettingOpts, netting.WithCurrencies(a.currencies))
+		}
+		if a.screener != nil {
+			nettingOpts = append(nettingOpts, netting.WithScreen(a.screenNettingPayment))
+		}
+		a.netting = netting.NewManager(a.cycles, ledgerService, nettingOpts...)
+	}
+
 	a.rateBurst = envInt("QAZNA_RATE_LIMIT_BURST", a.rateBurst)
 	a.ratePerSec = envInt("QAZNA_RATE_LIMIT_RPS", a.ratePerSec)
 
@@ -82,6 +216,8 @@ func New(
 	a.mux.HandleFunc("/readyz", a.Ready)
 	a.mux.HandleFunc("/v1/info", a.Info)
 	a.mux.HandleFunc("/v1/auth/token", a.handleAuthToken)
+	a.mux.HandleFunc("/v1/auth/refresh", a.handleAuthRefresh)
+	a.mux.HandleFunc("/v1/auth/revoke", a.handleAuthRevoke)
 	a.mux.HandleFunc("/v1/auth/jwks", a.handleJWKS)
 	a.mux.HandleFunc("/v1/auth/oauth/authorize", a.handleOAuthAuthorize)
 	a.mux.HandleFunc("/v1/auth/oauth/token", a.handleOAuthToken)
@@ -96,10 +232,34 @@ func New(
 	a.mux.HandleFunc("/v1/stream", a.Stream)
 
 	// Ledger endpoints
-	a.mux.Handle("/v1/accounts", RequireRole("admin")(http.HandlerFunc(a.handleAccountsCollection)))
+	a.mux.HandleFunc("/v1/accounts", a.handleAccountsCollection)
 	a.mux.HandleFunc("/v1/accounts/", a.handleAccountResource)
-	a.mux.Handle("/v1/transfers", RequireRole("admin")(http.HandlerFunc(a.handleTransfers)))
+	a.mux.HandleFunc("/v1/transfers", a.handleTransfers)
 	a.mux.HandleFunc("/v1/ledger/transactions", a.handleTransactions)
+	a.mux.HandleFunc("/v1/ledger/transactions/", a.handleTransactionResource)
+	a.mux.HandleFunc("/v1/holds", a.handleHolds)
+	a.mux.HandleFunc("/v1/holds/", a.handleHoldResource)
+	a.mux.HandleFunc("/v1/fx/transfers", a.handleFXTransfers)
+	a.mux.HandleFunc("/v1/fx/rates", a.handleFXRates)
+	a.mux.HandleFunc("/v1/fx/rates/", a.handleFXRateResource)
+	a.mux.HandleFunc("/v1/fx/liquidity", a.handleFXLiquidity)
+	a.mux.HandleFunc("/v1/fx/liquidity/", a.handleFXLiquidity)
+	a.mux.HandleFunc("/v1/currencies", a.handleCurrencies)
+	a.mux.HandleFunc("/v1/currencies/", a.handleCurrencies)
+	a.mux.HandleFunc("/v1/mint", a.handleMint)
+	a.mux.HandleFunc("/v1/burn", a.handleBurn)
+	a.mux.HandleFunc("/v1/issuers", a.handleIssuers)
+	a.mux.HandleFunc("/v1/issuers/", a.handleIssuers)
+	a.mux.HandleFunc("/v1/supply", a.handleSupply)
+	a.mux.HandleFunc("/v1/fees/schedules", a.handleFeeSchedules)
+	a.mux.HandleFunc("/v1/limits", a.handleLimits)
+	a.mux.HandleFunc("/v1/limits/", a.handleLimits)
+	a.mux.HandleFunc("/v1/screening/reviews", a.handleScreeningReviews)
+	a.mux.HandleFunc("/v1/screening/reviews/", a.handleScreeningReviews)
+	a.mux.HandleFunc("/v1/iso20022/messages", a.handleISO20022Messages)
+	a.mux.HandleFunc("/v1/iso20022/transactions", a.handleISO20022Transactions)
+	a.mux.HandleFunc("/v1/netting/cycles", a.handleNettingCycles)
+	a.mux.HandleFunc("/v1/netting/cycles/", a.handleNettingCycles)
 
 	// RBAC management endpoints
 	a.mux.Handle("/v1/organizations", http.HandlerFunc(a.handleOrganizations))
@@ -107,6 +267,9 @@ func New(
 	a.mux.HandleFunc("/v1/roles/", a.handleRoleResource)
 	a.mux.HandleFunc("/v1/users/", a.handleUserResource)
 
+	// Audit trail
+	a.mux.HandleFunc("/v1/audit/events", a.handleAuditEvents)
+
 	// Prometheus metrics
 	a.mux.Handle("/metrics", obs.Handler())
 
@@ -463,6 +626,12 @@ func envInt(name string, def int) int {
 }
 
 func (a *API) audit(ctx context.Context, action, resourceType, resourceID string, metadata map[string]string) {
+	logAudit(ctx, action, resourceType, resourceID, metadata)
+}
+
+// logAudit records an audit event, logging rather than failing when the
+// audit sink is unavailable.
+func logAudit(ctx context.Context, action, resourceType, resourceID string, metadata map[string]string) {
 	fields := map[string]any{}
 	if resourceType != "" {
 		fields["resource_type"] = resourceType
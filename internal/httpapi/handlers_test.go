@@ -2,19 +2,27 @@ package httpapi
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"qazna.org/internal/audit"
 	"qazna.org/internal/auth"
+	"qazna.org/internal/iso20022"
 	"qazna.org/internal/ledger"
+	"qazna.org/internal/netting"
+	"qazna.org/internal/screening"
 	"qazna.org/internal/stream"
 )
 
@@ -23,9 +31,10 @@ type apiClient struct {
 	client  *http.Client
 	t       *testing.T
 	mock    sqlmock.Sqlmock
+	auth    *auth.Service
 }
 
-func newTestAPI(t *testing.T, store auth.RBACStore) *apiClient {
+func newTestAPI(t *testing.T, store auth.RBACStore, opts ...Option) *apiClient {
 	t.Helper()
 
 	db, mock, err := sqlmock.New()
@@ -53,7 +62,9 @@ func newTestAPI(t *testing.T, store auth.RBACStore) *apiClient {
 		}
 	}
 
-	api := New(ReadyProbe{}, "test", ledger.NewInMemory(), stream.New(), nil, authSvc, rbacSvc)
+	// Most tests fund accounts as they create them.
+	opts = append([]Option{WithInitialFunding()}, opts...)
+	api := New(ReadyProbe{}, "test", ledger.NewInMemory(), stream.New(), nil, authSvc, rbacSvc, opts...)
 	api.rateBurst = 100
 	api.ratePerSec = 100
 
@@ -71,20 +82,35 @@ func newTestAPI(t *testing.T, store auth.RBACStore) *apiClient {
 		client:  srv.Client(),
 		t:       t,
 		mock:    mock,
+		auth:    authSvc,
 	}
 }
 
 func (c *apiClient) post(path string, body any, headers map[string]string) *http.Response {
+	c.t.Helper()
+	return c.send(http.MethodPost, path, body, headers)
+}
+
+func (c *apiClient) put(path string, body any, headers map[string]string) *http.Response {
+	c.t.Helper()
+	return c.send(http.MethodPut, path, body, headers)
+}
+
+func (c *apiClient) send(method, path string, body any, headers map[string]string) *http.Response {
 	c.t.Helper()
 	var payload []byte
-	if body != nil {
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		payload = b
+	default:
 		var err error
 		payload, err = json.Marshal(body)
 		if err != nil {
 			c.t.Fatalf("marshal body: %v", err)
 		}
 	}
-	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	req, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(payload))
 	if err != nil {
 		c.t.Fatalf("new request: %v", err)
 	}
@@ -122,24 +148,42 @@ func (c *apiClient) get(path string, params url.Values, headers map[string]strin
 	return resp
 }
 
+// obtainToken signs an access token directly; the password grant itself is
+// covered in auth_handlers_test.go.
 func (c *apiClient) obtainToken(user string, roles []string) string {
 	c.t.Helper()
-	resp := c.post("/v1/auth/token", map[string]any{
-		"user":  user,
-		"roles": roles,
-	}, nil)
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		c.t.Fatalf("unexpected token status: %d", resp.StatusCode)
+	token, _, err := c.auth.GenerateToken(context.Background(), user, roles, tokenTTL)
+	if err != nil {
+		c.t.Fatalf("GenerateToken: %v", err)
 	}
-	var payload tokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		c.t.Fatalf("decode token response: %v", err)
+	return token
+}
+
+// obtainTokenWithPermissions signs a token carrying permission claims, so
+// routes can be exercised without an RBAC store.
+func (c *apiClient) obtainTokenWithPermissions(user string, perms ...string) string {
+	c.t.Helper()
+	token, _, err := c.auth.GenerateTokenWithPermissions(context.Background(), user, []string{"operator"}, perms, tokenTTL)
+	if err != nil {
+		c.t.Fatalf("GenerateTokenWithPermissions: %v", err)
 	}
-	if payload.Token == "" {
-		c.t.Fatalf("empty token issued")
+	return token
+}
+
+// obtainOrgToken signs a token for a member of orgID with embedded
+// permission claims.
+func (c *apiClient) obtainOrgToken(user, orgID string, perms ...string) string {
+	c.t.Helper()
+	token, _, err := c.auth.GenerateIdentityToken(context.Background(), auth.Identity{
+		UserID:         user,
+		OrganizationID: orgID,
+		Roles:          []string{"operator"},
+		Permissions:    perms,
+	}, tokenTTL)
+	if err != nil {
+		c.t.Fatalf("GenerateIdentityToken: %v", err)
 	}
-	return payload.Token
+	return token
 }
 
 func decode[T any](t *testing.T, r *http.Response) T {
@@ -154,7 +198,7 @@ func decode[T any](t *testing.T, r *http.Response) T {
 
 func TestAPIAccountsTransferFlow(t *testing.T) {
 	api := newTestAPI(t, nil)
-	token := api.obtainToken("demo", []string{"admin"})
+	token := api.obtainTokenWithPermissions("demo", auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead)
 	authHeader := map[string]string{"Authorization": "Bearer " + token}
 
 	// Create account A with initial QZN balance.
@@ -265,7 +309,7 @@ func TestAPIEnforcesAuth(t *testing.T) {
 func TestTokenEndpointValidation(t *testing.T) {
 	api := newTestAPI(t, nil)
 
-	resp := api.post("/v1/auth/token", map[string]any{"user": ""}, nil)
+	resp := api.post("/v1/auth/token", map[string]any{"email": "", "password": ""}, nil)
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", resp.StatusCode)
@@ -279,17 +323,34 @@ func TestOAuthPKCEFlow(t *testing.T) {
 	sum := sha256.Sum256([]byte(verifier))
 	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
 
-	api.mock.ExpectQuery("select secret, redirect_uri from oauth_clients").WithArgs("demo-client").WillReturnRows(sqlmock.NewRows([]string{"secret", "redirect_uri"}).AddRow("demo-secret", "http://localhost/callback"))
-	api.mock.ExpectExec("insert into oauth_auth_codes").WithArgs(sqlmock.AnyArg(), "demo-client", challenge, "S256", "http://localhost/callback", "demo-user", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
-
-	resp := api.post("/v1/auth/oauth/authorize", map[string]any{
+	authorize := map[string]any{
+		"client_id":             "demo-client",
+		"redirect_uri":          "http://localhost/callback",
+		"code_challenge":        challenge,
+		"code_challenge_method": "S256",
+	}
+	resp := api.post("/v1/auth/oauth/authorize", authorize, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("authorize without a session: expected 401, got %d", resp.StatusCode)
+	}
+	session := map[string]string{"Authorization": "Bearer " + api.obtainToken("demo-user", []string{"viewer"})}
+	resp = api.post("/v1/auth/oauth/authorize", map[string]any{
 		"client_id":             "demo-client",
 		"redirect_uri":          "http://localhost/callback",
 		"code_challenge":        challenge,
 		"code_challenge_method": "S256",
-		"user":                  "demo-user",
+		"user":                  "admin",
 		"roles":                 []string{"admin"},
-	}, nil)
+	}, session)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("authorize naming a user: expected 400, got %d", resp.StatusCode)
+	}
+
+	api.mock.ExpectQuery("select secret, redirect_uri from oauth_clients").WithArgs("demo-client").WillReturnRows(sqlmock.NewRows([]string{"secret", "redirect_uri"}).AddRow("demo-secret", "http://localhost/callback"))
+	api.mock.ExpectExec("insert into oauth_auth_codes").WithArgs(sqlmock.AnyArg(), "demo-client", challenge, "S256", "http://localhost/callback", "demo-user", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	resp = api.post("/v1/auth/oauth/authorize", authorize, session)
 	if resp.StatusCode != http.StatusOK {
 		t.Fatalf("authorize expected 200, got %d", resp.StatusCode)
 	}
@@ -302,9 +363,11 @@ func TestOAuthPKCEFlow(t *testing.T) {
 		t.Fatalf("expected authorization code")
 	}
 
-	rolesRaw, _ := json.Marshal([]string{"admin"})
 	expires := time.Now().Add(3 * time.Minute)
-	api.mock.ExpectQuery("select a.code_challenge").WithArgs(authResp.Code, "demo-client").WillReturnRows(sqlmock.NewRows([]string{"code_challenge", "code_challenge_method", "redirect_uri", "user_id", "roles", "expires_at", "consumed_at", "secret"}).AddRow(challenge, "S256", "http://localhost/callback", "demo-user", rolesRaw, expires, nil, "demo-secret"))
+	api.mock.ExpectQuery("select a.code_challenge").WithArgs(authResp.Code, "demo-client").WillReturnRows(sqlmock.NewRows([]string{"code_challenge", "code_challenge_method", "redirect_uri", "user_id", "expires_at", "consumed_at", "secret"}).AddRow(challenge, "S256", "http://localhost/callback", "demo-user", expires, nil, "demo-secret"))
+	api.mock.ExpectQuery("select organization_id, email, status from users").WithArgs("demo-user").WillReturnRows(sqlmock.NewRows([]string{"organization_id", "email", "status"}).AddRow("org-demo", "demo@example.com", "active"))
+	api.mock.ExpectQuery("select distinct r.name").WithArgs("demo-user").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("viewer"))
+	api.mock.ExpectQuery("select distinct p.key").WithArgs("demo-user").WillReturnRows(sqlmock.NewRows([]string{"key"}))
 	api.mock.ExpectExec("update oauth_auth_codes set consumed_at").WithArgs(sqlmock.AnyArg(), authResp.Code).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	resp = api.post("/v1/auth/oauth/token", map[string]any{
@@ -324,4 +387,1667 @@ func TestOAuthPKCEFlow(t *testing.T) {
 	if tok.Token == "" {
 		t.Fatalf("expected token in response")
 	}
+	claims, err := api.auth.ParseAndValidate(context.Background(), tok.Token)
+	if err != nil || claims.Subject != "demo-user" || len(claims.Roles) != 1 || claims.Roles[0] != "viewer" {
+		t.Fatalf("expected the token to carry the user's stored grants, got %+v, %v", claims, err)
+	}
+}
+
+func TestLedgerTenantIsolation(t *testing.T) {
+	api := newTestAPI(t, nil)
+	perms := []string{auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead}
+	bankA := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("ops-a", "org-a", perms...)}
+	bankB := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("ops-b", "org-b", perms...)}
+	platform := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("root", "org-central",
+		append(perms, auth.PermissionLedgerCrossOrg)...)}
+
+	create := func(headers map[string]string, body map[string]any) *http.Response {
+		t.Helper()
+		return api.post("/v1/accounts", body, headers)
+	}
+
+	resp := create(bankA, map[string]any{"currency": "QZN", "initial_amount": 1000})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create A: expected 201, got %d", resp.StatusCode)
+	}
+	accA := decode[ledger.Account](t, resp)
+	if accA.OrganizationID != "org-a" {
+		t.Fatalf("expected account owned by org-a, got %q", accA.OrganizationID)
+	}
+	resp = create(bankB, map[string]any{"currency": "QZN", "initial_amount": 1000})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create B: expected 201, got %d", resp.StatusCode)
+	}
+	accB := decode[ledger.Account](t, resp)
+
+	resp = api.get("/v1/accounts/"+accA.ID, nil, bankB)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("cross-org read: expected 404, got %d", resp.StatusCode)
+	}
+	resp = api.get("/v1/accounts/"+accA.ID+"/balance", url.Values{"currency": {"QZN"}}, bankB)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("cross-org balance: expected 404, got %d", resp.StatusCode)
+	}
+
+	resp = api.post("/v1/transfers", map[string]any{"from_id": accA.ID, "to_id": accB.ID, "currency": "QZN", "amount": 10}, bankB)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("debit of foreign account: expected 404, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/transfers", map[string]any{"from_id": accB.ID, "to_id": accA.ID, "currency": "QZN", "amount": 10}, bankB)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("payment to foreign account: expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = api.get("/v1/ledger/transactions", nil, bankA)
+	list := decode[listTransactionsResponse](t, resp)
+	if len(list.Items) != 1 || list.Items[0].ToAccountID != accA.ID {
+		t.Fatalf("org-a should see only the incoming payment, got %+v", list.Items)
+	}
+
+	resp = create(bankA, map[string]any{"currency": "QZN", "organization_id": "org-b"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("create for another org without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = create(platform, map[string]any{"currency": "QZN", "organization_id": "org-b"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("cross-org create: expected 201, got %d", resp.StatusCode)
+	}
+	if acc := decode[ledger.Account](t, resp); acc.OrganizationID != "org-b" {
+		t.Fatalf("expected account owned by org-b, got %q", acc.OrganizationID)
+	}
+	resp = api.get("/v1/accounts/"+accA.ID, nil, platform)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("cross-org read with permission: expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestAccountLifecycleEndpoints(t *testing.T) {
+	api := newTestAPI(t, nil)
+	ops := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("ops",
+		auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead)}
+	compliance := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("compliance",
+		auth.PermissionLedgerAccountStatus)}
+
+	resp := api.post("/v1/accounts", map[string]any{
+		"currency":       "QZN",
+		"initial_amount": 100,
+		"type":           "reserve",
+		"display_name":   "Reserve KZ",
+		"external_ref":   "KZ-RES-1",
+	}, ops)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d", resp.StatusCode)
+	}
+	acc := decode[ledger.Account](t, resp)
+	if acc.Type != ledger.AccountTypeReserve || acc.DisplayName != "Reserve KZ" || acc.Status != ledger.AccountActive {
+		t.Fatalf("unexpected account: %+v", acc)
+	}
+	resp = api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 0, "type": "nostro"}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown type: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 0}, ops)
+	other := decode[ledger.Account](t, resp)
+
+	resp = api.post("/v1/accounts/"+acc.ID+"/freeze", map[string]any{"reason": "sanctions screening"}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("freeze without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/accounts/"+acc.ID+"/freeze", map[string]any{"reason": "sanctions screening"}, compliance)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("freeze: expected 200, got %d", resp.StatusCode)
+	}
+	if frozen := decode[ledger.Account](t, resp); frozen.Status != ledger.AccountFrozen {
+		t.Fatalf("expected frozen account, got %+v", frozen)
+	}
+
+	transfer := map[string]any{"from_id": acc.ID, "to_id": other.ID, "currency": "QZN", "amount": 100}
+	resp = api.post("/v1/transfers", transfer, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("debit from frozen account: expected 409, got %d", resp.StatusCode)
+	}
+
+	resp = api.post("/v1/accounts/"+acc.ID+"/unfreeze", nil, compliance)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unfreeze: expected 200, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/transfers", transfer, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("transfer after unfreeze: expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = api.post("/v1/accounts/"+other.ID+"/close", nil, compliance)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("close funded account: expected 409, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/accounts/"+acc.ID+"/close", nil, compliance)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("close: expected 200, got %d", resp.StatusCode)
+	}
+	if closed := decode[ledger.Account](t, resp); closed.Status != ledger.AccountClosed {
+		t.Fatalf("expected closed account, got %+v", closed)
+	}
+	resp = api.post("/v1/accounts/"+acc.ID+"/unfreeze", nil, compliance)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("reopen closed account: expected 409, got %d", resp.StatusCode)
+	}
+	resp = api.get("/v1/accounts/"+acc.ID+"/close", nil, compliance)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("GET on action: expected 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestAccountTransactionsEndpoint(t *testing.T) {
+	api := newTestAPI(t, nil)
+	headers := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("ops",
+		auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead)}
+
+	resp := api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1000}, headers)
+	a := decode[ledger.Account](t, resp)
+	resp = api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1000}, headers)
+	b := decode[ledger.Account](t, resp)
+	for _, tr := range []map[string]any{
+		{"from_id": a.ID, "to_id": b.ID, "currency": "QZN", "amount": 10},
+		{"from_id": b.ID, "to_id": a.ID, "currency": "QZN", "amount": 20},
+		{"from_id": a.ID, "to_id": b.ID, "currency": "QZN", "amount": 30},
+	} {
+		resp = api.post("/v1/transfers", tr, headers)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("transfer: expected 201, got %d", resp.StatusCode)
+		}
+	}
+
+	resp = api.get("/v1/accounts/"+a.ID+"/transactions", url.Values{"direction": {"debit"}, "limit": {"1"}}, headers)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	page := decode[listTransactionsResponse](t, resp)
+	if len(page.Items) != 1 || page.Items[0].Amount != 10 {
+		t.Fatalf("unexpected first page: %+v", page.Items)
+	}
+	resp = api.get("/v1/accounts/"+a.ID+"/transactions", url.Values{
+		"direction": {"debit"},
+		"after":     {strconv.FormatUint(page.NextAfter, 10)},
+	}, headers)
+	page = decode[listTransactionsResponse](t, resp)
+	if len(page.Items) != 1 || page.Items[0].Amount != 30 {
+		t.Fatalf("unexpected second page: %+v", page.Items)
+	}
+
+	resp = api.get("/v1/accounts/"+a.ID+"/transactions", url.Values{
+		"from": {time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)},
+		"to":   {time.Now().Add(time.Hour).UTC().Format(time.RFC3339)},
+	}, headers)
+	if all := decode[listTransactionsResponse](t, resp); len(all.Items) != 3 {
+		t.Fatalf("expected 3 transactions in range, got %d", len(all.Items))
+	}
+
+	for _, bad := range []url.Values{
+		{"direction": {"sideways"}},
+		{"from": {"yesterday"}},
+		{"from": {"2025-01-02T00:00:00Z"}, "to": {"2025-01-01T00:00:00Z"}},
+	} {
+		resp = api.get("/v1/accounts/"+a.ID+"/transactions", bad, headers)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%v: expected 400, got %d", bad, resp.StatusCode)
+		}
+	}
+	resp = api.get("/v1/accounts/missing/transactions", nil, headers)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown account: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestBalanceAsOfEndpoint(t *testing.T) {
+	api := newTestAPI(t, nil)
+	headers := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("ops",
+		auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead)}
+
+	resp := api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1000}, headers)
+	a := decode[ledger.Account](t, resp)
+	resp = api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 0}, headers)
+	b := decode[ledger.Account](t, resp)
+	var first ledger.Transaction
+	for i, amount := range []int64{100, 200} {
+		resp = api.post("/v1/transfers", map[string]any{"from_id": a.ID, "to_id": b.ID, "currency": "QZN", "amount": amount}, headers)
+		tx := decode[ledger.Transaction](t, resp)
+		if i == 0 {
+			first = tx
+		}
+	}
+
+	resp = api.get("/v1/accounts/"+a.ID+"/balance", url.Values{
+		"currency":       {"qzn"},
+		"as_of_sequence": {strconv.FormatUint(first.Sequence, 10)},
+	}, headers)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if bal := decode[ledger.HistoricalBalance](t, resp); bal.Amount != 900 || bal.Currency != "QZN" || bal.Sequence != first.Sequence {
+		t.Fatalf("unexpected balance after first transfer: %+v", bal)
+	}
+
+	resp = api.get("/v1/accounts/"+b.ID+"/balance", url.Values{
+		"currency": {"QZN"},
+		"as_of":    {time.Now().Add(time.Minute).UTC().Format(time.RFC3339)},
+	}, headers)
+	if bal := decode[ledger.HistoricalBalance](t, resp); bal.Amount != 300 {
+		t.Fatalf("unexpected current balance: %+v", bal)
+	}
+
+	for _, bad := range []url.Values{
+		{"currency": {"QZN"}, "as_of_sequence": {"0"}},
+		{"currency": {"QZN"}, "as_of": {"yesterday"}},
+		{"currency": {"QZN"}, "as_of_sequence": {"1"}, "as_of": {"2025-01-01T00:00:00Z"}},
+	} {
+		resp = api.get("/v1/accounts/"+a.ID+"/balance", bad, headers)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%v: expected 400, got %d", bad, resp.StatusCode)
+		}
+	}
+}
+
+func TestReverseEndpoint(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	api := newTestAPI(t, nil)
+	ops := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("ops",
+		auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead)}
+	supervisor := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("supervisor",
+		auth.PermissionLedgerReverse, auth.PermissionLedgerRead)}
+
+	resp := api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1000}, ops)
+	a := decode[ledger.Account](t, resp)
+	resp = api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 0}, ops)
+	b := decode[ledger.Account](t, resp)
+	resp = api.post("/v1/transfers", map[string]any{"from_id": a.ID, "to_id": b.ID, "currency": "QZN", "amount": 300}, ops)
+	orig := decode[ledger.Transaction](t, resp)
+	path := "/v1/ledger/transactions/" + orig.ID + "/reverse"
+
+	resp = api.post(path, map[string]any{"reason": "wrong beneficiary"}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("reverse without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.post(path, map[string]any{"amount": 100}, supervisor)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("missing reason: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.post(path, map[string]any{"amount": 100, "reason": "wrong beneficiary"}, supervisor)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("partial reversal: expected 201, got %d", resp.StatusCode)
+	}
+	if rev := decode[ledger.Transaction](t, resp); rev.ReversalOf != orig.ID || rev.Amount != 100 || rev.FromAccountID != b.ID {
+		t.Fatalf("unexpected reversal: %+v", rev)
+	}
+	resp = api.post(path, map[string]any{"amount": 500, "reason": "wrong beneficiary"}, supervisor)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("over-reversal: expected 409, got %d", resp.StatusCode)
+	}
+	resp = api.post(path, map[string]any{"reason": "wrong beneficiary"}, supervisor)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("full reversal: expected 201, got %d", resp.StatusCode)
+	}
+	resp = api.post(path, map[string]any{"reason": "wrong beneficiary"}, supervisor)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("double reversal: expected 409, got %d", resp.StatusCode)
+	}
+
+	resp = api.get("/v1/accounts/"+a.ID+"/transactions", nil, supervisor)
+	page := decode[listTransactionsResponse](t, resp)
+	if len(page.Items) != 3 || page.Items[0].ReversalStatus != ledger.Reversed || page.Items[0].ReversedAmount != 300 {
+		t.Fatalf("unexpected history: %+v", page.Items)
+	}
+
+	events, err := sink.Query(context.Background(), audit.Filter{Action: "ledger.transfer.reverse"})
+	if err != nil {
+		t.Fatalf("query audit: %v", err)
+	}
+	if len(events) != 2 || !bytes.Contains(events[0].Metadata, []byte(orig.ID)) {
+		t.Fatalf("unexpected reversal audit events: %+v", events)
+	}
+
+	resp = api.post("/v1/ledger/transactions/missing/reverse", map[string]any{"reason": "typo"}, supervisor)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown transaction: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestHoldEndpoints(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	api := newTestAPI(t, nil)
+	ops := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("ops",
+		auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead)}
+	reader := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("auditor", auth.PermissionLedgerRead)}
+
+	resp := api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1000}, ops)
+	a := decode[ledger.Account](t, resp)
+	resp = api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 0}, ops)
+	b := decode[ledger.Account](t, resp)
+	holdReq := map[string]any{"from_id": a.ID, "to_id": b.ID, "currency": "qzn", "amount": 400, "ttl_seconds": 600}
+
+	resp = api.post("/v1/holds", holdReq, reader)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("hold without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/holds", map[string]any{"from_id": a.ID, "to_id": b.ID, "currency": "QZN", "amount": 1, "ttl_seconds": -1}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("negative ttl: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/holds", holdReq, ops)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create hold: expected 201, got %d", resp.StatusCode)
+	}
+	h := decode[ledger.Hold](t, resp)
+	if h.Status != ledger.HoldPending || h.Currency != "QZN" || h.ExpiresAt.Sub(h.CreatedAt) != 10*time.Minute {
+		t.Fatalf("unexpected hold: %+v", h)
+	}
+
+	resp = api.get("/v1/accounts/"+a.ID+"/balance", url.Values{"currency": {"QZN"}}, reader)
+	if bal := decode[ledger.Balance](t, resp); bal.Amount != 1000 || bal.Held != 400 || bal.Available != 600 {
+		t.Fatalf("unexpected balance with hold: %+v", bal)
+	}
+	resp = api.get("/v1/holds/"+h.ID, nil, reader)
+	if got := decode[ledger.Hold](t, resp); got.ID != h.ID || got.Amount != 400 {
+		t.Fatalf("unexpected hold lookup: %+v", got)
+	}
+
+	resp = api.post("/v1/holds/"+h.ID+"/capture", map[string]any{"amount": 500}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("over-capture: expected 409, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/holds/"+h.ID+"/capture", map[string]any{"amount": 150}, ops)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("capture: expected 200, got %d", resp.StatusCode)
+	}
+	captured := decode[captureHoldResponse](t, resp)
+	if captured.Hold.Status != ledger.HoldCaptured || captured.Transaction.Amount != 150 || captured.Hold.TransactionID != captured.Transaction.ID {
+		t.Fatalf("unexpected capture: %+v", captured)
+	}
+	resp = api.get("/v1/accounts/"+a.ID+"/balance", url.Values{"currency": {"QZN"}}, reader)
+	if bal := decode[ledger.Balance](t, resp); bal.Amount != 850 || bal.Held != 0 || bal.Available != 850 {
+		t.Fatalf("unexpected balance after capture: %+v", bal)
+	}
+	resp = api.post("/v1/holds/"+h.ID+"/void", nil, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("void after capture: expected 409, got %d", resp.StatusCode)
+	}
+
+	resp = api.post("/v1/holds", map[string]any{"from_id": a.ID, "to_id": b.ID, "currency": "QZN", "amount": 100}, ops)
+	v := decode[ledger.Hold](t, resp)
+	resp = api.post("/v1/holds/"+v.ID+"/void", nil, ops)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("void: expected 200, got %d", resp.StatusCode)
+	}
+	if got := decode[ledger.Hold](t, resp); got.Status != ledger.HoldVoided {
+		t.Fatalf("unexpected voided hold: %+v", got)
+	}
+
+	for _, action := range []string{"ledger.hold.create", "ledger.hold.capture", "ledger.hold.void"} {
+		events, err := sink.Query(context.Background(), audit.Filter{Action: action})
+		if err != nil {
+			t.Fatalf("query audit: %v", err)
+		}
+		if len(events) == 0 {
+			t.Fatalf("expected %s audit event", action)
+		}
+	}
+
+	resp = api.get("/v1/holds/missing", nil, reader)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown hold: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestFXEndpoints(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	api := newTestAPI(t, nil)
+	ops := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("ops",
+		auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead)}
+	treasury := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("treasury",
+		auth.PermissionLedgerFXManage, auth.PermissionLedgerRead)}
+
+	newAccount := func(currency string, amount int64) ledger.Account {
+		resp := api.post("/v1/accounts", map[string]any{"currency": currency, "initial_amount": amount}, ops)
+		return decode[ledger.Account](t, resp)
+	}
+	from := newAccount("QZN", 100_000)
+	to := newAccount("USD", 0)
+	qznLiq := newAccount("QZN", 0)
+	usdLiq := newAccount("USD", 50_000)
+
+	rateReq := map[string]any{"base": "QZN", "quote": "USD", "rate": "0.25"}
+	resp := api.post("/v1/fx/rates", rateReq, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("add rate without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/fx/rates", map[string]any{"base": "QZN", "quote": "USD", "rate": "-1"}, treasury)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("negative rate: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/fx/rates", rateReq, treasury)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("add rate: expected 201, got %d", resp.StatusCode)
+	}
+	rate := decode[ledger.FXRate](t, resp)
+
+	transferReq := map[string]any{"from_id": from.ID, "to_id": to.ID, "currency": "QZN", "amount": 1_001, "target_currency": "usd"}
+	resp = api.post("/v1/fx/transfers", transferReq, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("transfer without liquidity: expected 409, got %d", resp.StatusCode)
+	}
+	for currency, id := range map[string]string{"qzn": qznLiq.ID, "USD": usdLiq.ID} {
+		resp = api.put("/v1/fx/liquidity/"+currency, map[string]any{"account_id": id}, treasury)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("set liquidity %s: expected 200, got %d", currency, resp.StatusCode)
+		}
+	}
+	resp = api.get("/v1/fx/liquidity", nil, ops)
+	if got := decode[map[string]map[string]string](t, resp); got["accounts"]["QZN"] != qznLiq.ID || got["accounts"]["USD"] != usdLiq.ID {
+		t.Fatalf("unexpected liquidity accounts: %+v", got)
+	}
+
+	resp = api.post("/v1/fx/transfers", map[string]any{"from_id": from.ID, "to_id": to.ID, "currency": "QZN", "amount": 1, "target_currency": "QZN"}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("same currency: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/fx/transfers", transferReq, ops)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("fx transfer: expected 201, got %d", resp.StatusCode)
+	}
+	tx := decode[ledger.Transaction](t, resp)
+	if tx.FXRateID != rate.ID || tx.FXRate != "0.25" || len(tx.Entries) != 4 {
+		t.Fatalf("unexpected fx transaction: %+v", tx)
+	}
+	resp = api.get("/v1/accounts/"+to.ID+"/balance", url.Values{"currency": {"USD"}}, ops)
+	if bal := decode[ledger.Balance](t, resp); bal.Amount != 250 {
+		t.Fatalf("expected 250 USD credited, got %+v", bal)
+	}
+
+	resp = api.post("/v1/fx/rates/"+rate.ID+"/expire", nil, treasury)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expire rate: expected 200, got %d", resp.StatusCode)
+	}
+	if got := decode[ledger.FXRate](t, resp); got.ValidTo.IsZero() {
+		t.Fatalf("expected closed validity window, got %+v", got)
+	}
+	resp = api.post("/v1/fx/transfers", transferReq, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("transfer after expiry: expected 409, got %d", resp.StatusCode)
+	}
+	resp = api.get("/v1/fx/rates", url.Values{"base": {"qzn"}}, ops)
+	if got := decode[map[string][]ledger.FXRate](t, resp); len(got["items"]) != 1 {
+		t.Fatalf("unexpected rate list: %+v", got)
+	}
+	resp = api.post("/v1/fx/rates/missing/expire", nil, treasury)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown rate: expected 404, got %d", resp.StatusCode)
+	}
+
+	for _, action := range []string{"ledger.fx.rate.create", "ledger.fx.rate.expire", "ledger.fx.liquidity.set", "ledger.fx.transfer"} {
+		events, err := sink.Query(context.Background(), audit.Filter{Action: action})
+		if err != nil {
+			t.Fatalf("query audit: %v", err)
+		}
+		if len(events) == 0 {
+			t.Fatalf("expected %s audit event", action)
+		}
+	}
+}
+
+func TestCurrencyEndpoints(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	api := newTestAPI(t, nil)
+	ops := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("ops",
+		auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead)}
+	admin := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("admin",
+		auth.PermissionLedgerCurrencyManage, auth.PermissionLedgerRead)}
+
+	resp := api.get("/v1/currencies/kwd", nil, ops)
+	if c := decode[ledger.Currency](t, resp); c.Code != "KWD" || c.Exponent != 3 || !c.Enabled {
+		t.Fatalf("unexpected built-in currency: %+v", c)
+	}
+	resp = api.post("/v1/accounts", map[string]any{"currency": "EKZT", "initial_amount": 0}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown currency: expected 400, got %d", resp.StatusCode)
+	}
+
+	newCurrency := map[string]any{"name": "Digital Tenge", "exponent": 2, "max_amount": 100_000}
+	resp = api.put("/v1/currencies/ekzt", newCurrency, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("put without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.put("/v1/currencies/EKZT", map[string]any{"name": "Digital Tenge"}, admin)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("new currency without exponent: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.put("/v1/currencies/ekzt", newCurrency, admin)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("register currency: expected 201, got %d", resp.StatusCode)
+	}
+	if c := decode[ledger.Currency](t, resp); c.Code != "EKZT" || c.Kind != ledger.CurrencyDigital || !c.Enabled || c.MaxAmount != 100_000 {
+		t.Fatalf("unexpected registered currency: %+v", c)
+	}
+
+	resp = api.post("/v1/accounts", map[string]any{"currency": "EKZT", "initial_amount": 123_456}, ops)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create account: expected 201, got %d", resp.StatusCode)
+	}
+	from := decode[accountView](t, resp)
+	if from.FormattedBalances["EKZT"] != "1234.56" {
+		t.Fatalf("unexpected formatted balances: %+v", from.FormattedBalances)
+	}
+	resp = api.post("/v1/accounts", map[string]any{"currency": "EKZT", "initial_amount": 0}, ops)
+	to := decode[ledger.Account](t, resp)
+
+	resp = api.post("/v1/transfers", map[string]any{"from_id": from.ID, "to_id": to.ID, "currency": "EKZT", "amount": 100_001}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("transfer above the limit: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/transfers", map[string]any{"from_id": from.ID, "to_id": to.ID, "currency": "EKZT", "amount": 1_005}, ops)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("transfer: expected 201, got %d", resp.StatusCode)
+	}
+	if tx := decode[transactionView](t, resp); tx.FormattedAmount != "10.05" {
+		t.Fatalf("unexpected formatted amount: %+v", tx)
+	}
+	resp = api.get("/v1/accounts/"+to.ID+"/balance", url.Values{"currency": {"EKZT"}}, ops)
+	if bal := decode[balanceView](t, resp); bal.FormattedAmount != "10.05" || bal.FormattedAvailable != "10.05" {
+		t.Fatalf("unexpected formatted balance: %+v", bal)
+	}
+
+	resp = api.put("/v1/currencies/EKZT", map[string]any{"enabled": false}, admin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("disable currency: expected 200, got %d", resp.StatusCode)
+	}
+	if c := decode[ledger.Currency](t, resp); c.Enabled || c.Exponent != 2 || c.Name != "Digital Tenge" {
+		t.Fatalf("partial update lost fields: %+v", c)
+	}
+	resp = api.post("/v1/transfers", map[string]any{"from_id": from.ID, "to_id": to.ID, "currency": "EKZT", "amount": 1}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("transfer in disabled currency: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp = api.get("/v1/currencies", nil, ops)
+	items := decode[map[string][]ledger.Currency](t, resp)["items"]
+	found := false
+	for _, c := range items {
+		found = found || (c.Code == "EKZT" && !c.Enabled)
+	}
+	if !found {
+		t.Fatalf("registered currency missing from %d listed", len(items))
+	}
+	resp = api.get("/v1/currencies/NOPE", nil, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown currency: expected 404, got %d", resp.StatusCode)
+	}
+
+	events, err := sink.Query(context.Background(), audit.Filter{Action: "ledger.currency.put"})
+	if err != nil {
+		t.Fatalf("query audit: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 ledger.currency.put audit events, got %d", len(events))
+	}
+}
+
+func TestIssuanceEndpoints(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	// The default: money only enters through mints.
+	api := newTestAPI(t, nil, func(a *API) { a.funding = false })
+	ops := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("ops",
+		auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead)}
+	issuer := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("issuer",
+		auth.PermissionLedgerIssuanceManage, auth.PermissionLedgerRead)}
+
+	resp := api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 100}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("initial funding: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/accounts", map[string]any{"currency": "QZN", "type": "reserve"}, ops)
+	reserve := decode[ledger.Account](t, resp)
+	resp = api.post("/v1/accounts", map[string]any{"currency": "QZN"}, ops)
+	holder := decode[ledger.Account](t, resp)
+
+	resp = api.put("/v1/issuers/QZN", map[string]any{"account_id": reserve.ID}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("designate without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.put("/v1/issuers/qzn", map[string]any{"account_id": reserve.ID}, issuer)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("designate issuer: expected 200, got %d", resp.StatusCode)
+	}
+
+	mint := map[string]any{"account_id": reserve.ID, "currency": "QZN", "amount": 10_000, "idempotency_key": "mint-1"}
+	resp = api.post("/v1/mint", mint, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("mint without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/mint", map[string]any{"account_id": holder.ID, "currency": "QZN", "amount": 10_000}, issuer)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("mint into a non-issuer: expected 409, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/mint", mint, issuer)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("mint: expected 201, got %d", resp.StatusCode)
+	}
+	if tx := decode[transactionView](t, resp); tx.Kind != ledger.KindMint || tx.ToAccountID != reserve.ID || tx.FormattedAmount != "100.00" {
+		t.Fatalf("unexpected mint: %+v", tx)
+	}
+	resp = api.post("/v1/mint", mint, issuer)
+	resp.Body.Close()
+
+	resp = api.post("/v1/transfers", map[string]any{"from_id": reserve.ID, "to_id": holder.ID, "currency": "QZN", "amount": 4_000}, ops)
+	resp.Body.Close()
+	resp = api.post("/v1/transfers", map[string]any{"from_id": holder.ID, "to_id": ledger.IssuanceAccountID("QZN"), "currency": "QZN", "amount": 1}, ops)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("transfer to the issuance account: expected 409, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/burn", map[string]any{"account_id": reserve.ID, "currency": "QZN", "amount": 2_500}, issuer)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("burn: expected 201, got %d", resp.StatusCode)
+	}
+	if tx := decode[transactionView](t, resp); tx.Kind != ledger.KindBurn {
+		t.Fatalf("unexpected burn: %+v", tx)
+	}
+
+	resp = api.get("/v1/supply", nil, ops)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("supply: expected 200, got %d", resp.StatusCode)
+	}
+	items := decode[map[string][]supplyView](t, resp)["items"]
+	want := ledger.Supply{Currency: "QZN", Outstanding: 7_500, Issued: 7_500, Minted: 10_000, Burned: 2_500}
+	if len(items) != 1 || items[0].Supply != want || items[0].FormattedOutstanding != "75.00" {
+		t.Fatalf("unexpected supply: %+v", items)
+	}
+
+	for action, n := range map[string]int{"ledger.mint": 1, "ledger.mint.idempotent_replay": 1, "ledger.burn": 1, "ledger.issuer.set": 1} {
+		events, err := sink.Query(context.Background(), audit.Filter{Action: action})
+		if err != nil {
+			t.Fatalf("query audit: %v", err)
+		}
+		if len(events) != n {
+			t.Fatalf("expected %d %s audit events, got %d", n, action, len(events))
+		}
+	}
+}
+
+func TestTransferFees(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	store := &stubRBACStore{
+		getOrgFn: func(_ context.Context, id string) (auth.Organization, error) {
+			switch id {
+			case "org-corp":
+				return auth.Organization{ID: id, ParticipantType: auth.ParticipantCorporate}, nil
+			case "org-sov":
+				return auth.Organization{ID: id, ParticipantType: auth.ParticipantSovereign}, nil
+			}
+			return auth.Organization{}, auth.ErrNotFound
+		},
+	}
+	api := newTestAPI(t, store)
+	perms := []string{auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead}
+	corp := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("corp", "org-corp", perms...)}
+	sov := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("sov", "org-sov", perms...)}
+	admin := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("admin",
+		append(perms, auth.PermissionLedgerFeeManage, auth.PermissionLedgerCrossOrg)...)}
+
+	corpAcct := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1_000_000}, corp))
+	sovAcct := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1_000_000}, sov))
+	feeAcct := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "type": "fee"}, admin))
+
+	schedule := map[string]any{
+		"rates":      map[string]string{"sovereign": "0", "institution": "0.0003", "corporate": "0.0015"},
+		"alpha":      "1.1",
+		"beta":       "1.2",
+		"rounding":   "half_even",
+		"account_id": feeAcct.ID,
+	}
+	resp := api.post("/v1/fees/schedules", schedule, corp)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("add schedule without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/fees/schedules", map[string]any{"alpha": "2", "beta": "1", "account_id": feeAcct.ID}, admin)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("out of range alpha: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/fees/schedules", schedule, admin)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("add schedule: expected 201, got %d", resp.StatusCode)
+	}
+	if fs := decode[ledger.FeeSchedule](t, resp); fs.Version != 1 || fs.Rounding != ledger.RoundHalfEven {
+		t.Fatalf("unexpected schedule: %+v", fs)
+	}
+
+	// 100000 × 0.0015 × 1.1 × 1.2 = 198
+	resp = api.post("/v1/transfers", map[string]any{"from_id": corpAcct.ID, "to_id": sovAcct.ID, "currency": "QZN", "amount": 100_000, "idempotency_key": "fee-1"}, corp)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("corporate transfer: expected 201, got %d", resp.StatusCode)
+	}
+	tx := decode[transactionView](t, resp)
+	if tx.Fee == nil || tx.Fee.Amount != 198 || tx.Fee.ParticipantType != "corporate" || tx.Fee.AccountID != feeAcct.ID || tx.FormattedFee != "1.98" || len(tx.Entries) != 4 {
+		t.Fatalf("unexpected corporate transfer: %+v", tx)
+	}
+	resp = api.post("/v1/transfers", map[string]any{"from_id": sovAcct.ID, "to_id": corpAcct.ID, "currency": "QZN", "amount": 100_000}, sov)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("sovereign transfer: expected 201, got %d", resp.StatusCode)
+	}
+	if tx := decode[transactionView](t, resp); tx.Fee == nil || tx.Fee.Amount != 0 || tx.FromAccountID != sovAcct.ID {
+		t.Fatalf("unexpected sovereign transfer: %+v", tx)
+	}
+
+	// Hold captures pay the fee on the captured amount: 50000 × 0.0015 × 1.1 × 1.2 = 99
+	resp = api.post("/v1/holds", map[string]any{"from_id": corpAcct.ID, "to_id": sovAcct.ID, "currency": "QZN", "amount": 60_000}, corp)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create hold: expected 201, got %d", resp.StatusCode)
+	}
+	hold := decode[holdView](t, resp)
+	resp = api.post("/v1/holds/"+hold.ID+"/capture", map[string]any{"amount": 50_000}, corp)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("capture hold: expected 200, got %d", resp.StatusCode)
+	}
+	if c := decode[captureHoldResponse](t, resp); c.Transaction.Fee == nil || c.Transaction.Fee.Amount != 99 || len(c.Transaction.Entries) != 4 {
+		t.Fatalf("unexpected capture: %+v", c.Transaction)
+	}
+
+	resp = api.get("/v1/accounts/"+feeAcct.ID, nil, admin)
+	if acc := decode[ledger.Account](t, resp); acc.Balances["QZN"] != 198+99 {
+		t.Fatalf("unexpected fee account balance: %+v", acc.Balances)
+	}
+	resp = api.get("/v1/fees/schedules", nil, corp)
+	if items := decode[map[string][]ledger.FeeSchedule](t, resp)["items"]; len(items) != 1 || items[0].Rates["corporate"] != "0.0015" {
+		t.Fatalf("unexpected schedules: %+v", items)
+	}
+
+	events, err := sink.Query(context.Background(), audit.Filter{Action: "ledger.transfer.execute"})
+	if err != nil || len(events) != 2 {
+		t.Fatalf("unexpected transfer audit events: %+v %v", events, err)
+	}
+	var meta map[string]string
+	if err := json.Unmarshal(events[0].Metadata, &meta); err != nil || meta["fee"] == "" || meta["fee_schedule_version"] != "1" {
+		t.Fatalf("unexpected transfer audit metadata: %s %v", events[0].Metadata, err)
+	}
+	if events, _ := sink.Query(context.Background(), audit.Filter{Action: "ledger.fee.schedule.create"}); len(events) != 1 {
+		t.Fatalf("expected one schedule audit event, got %+v", events)
+	}
+}
+
+func TestTransferLimits(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	api := newTestAPI(t, nil)
+	perms := []string{auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead}
+	operator := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("operator", perms...)}
+	admin := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("admin",
+		append(perms, auth.PermissionLedgerLimitsManage)...)}
+
+	from := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1_000_000}, operator))
+	to := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN"}, operator))
+	path := "/v1/limits/account/" + from.ID + "/QZN"
+
+	resp := api.put(path, map[string]any{"max_amount": 10_000}, operator)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("set limit without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.put(path, map[string]any{"window_count": 2}, admin)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("window cap without a window: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.put("/v1/limits/account/missing/QZN", map[string]any{"max_amount": 1}, admin)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("limit on an unknown account: expected 404, got %d", resp.StatusCode)
+	}
+	resp = api.put(path, map[string]any{"max_amount": 10_000, "window_amount": 15_000, "window_seconds": 3600}, admin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("set limit: expected 200, got %d", resp.StatusCode)
+	}
+	if l := decode[ledger.Limit](t, resp); l.MaxAmount != 10_000 || l.WindowSeconds != 3600 {
+		t.Fatalf("unexpected limit: %+v", l)
+	}
+
+	transfer := func(amount int64) *http.Response {
+		return api.post("/v1/transfers", map[string]any{"from_id": from.ID, "to_id": to.ID, "currency": "QZN", "amount": amount}, operator)
+	}
+	resp = transfer(10_001)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("transfer over the single cap: expected 422, got %d", resp.StatusCode)
+	}
+	if body := decode[map[string]string](t, resp); !strings.Contains(body["error"], "max_amount") {
+		t.Fatalf("unexpected error: %+v", body)
+	}
+	resp = transfer(10_000)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("transfer within limits: expected 201, got %d", resp.StatusCode)
+	}
+	resp = transfer(5_001)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("transfer over the window volume: expected 422, got %d", resp.StatusCode)
+	}
+
+	resp = api.get(path+"/utilization", nil, admin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("utilization: expected 200, got %d", resp.StatusCode)
+	}
+	if u := decode[ledger.Utilization](t, resp); u.WindowAmount != 10_000 || u.WindowCount != 1 || u.DailyAmount != 10_000 {
+		t.Fatalf("unexpected utilization: %+v", u)
+	}
+	resp = api.get("/v1/limits", url.Values{"scope": {"account"}}, admin)
+	if items := decode[map[string][]ledger.Limit](t, resp)["items"]; len(items) != 1 || items[0].Subject != from.ID {
+		t.Fatalf("unexpected limits: %+v", items)
+	}
+
+	resp = api.send(http.MethodDelete, path, nil, admin)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete limit: expected 204, got %d", resp.StatusCode)
+	}
+	resp = transfer(5_001)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("transfer after the limit was lifted: expected 201, got %d", resp.StatusCode)
+	}
+	resp = api.get(path+"/utilization", nil, admin)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("utilization of a deleted limit: expected 404, got %d", resp.StatusCode)
+	}
+
+	for action, want := range map[string]int{"ledger.limit.set": 1, "ledger.limit.delete": 1} {
+		if events, _ := sink.Query(context.Background(), audit.Filter{Action: action}); len(events) != want {
+			t.Fatalf("expected %d %s audit events, got %+v", want, action, events)
+		}
+	}
+}
+
+func TestTransferScreening(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	orgs := map[string]auth.Organization{
+		"org-payer": {ID: "org-payer", Name: "Steppe Grain Cooperative"},
+		"org-ok":    {ID: "org-ok", Name: "Almaty Bakery"},
+		"org-hit":   {ID: "org-hit", Name: "Borealis Shipping Trading Ltd"},
+		"org-near":  {ID: "org-near", Name: "Caspian Logistics", Metadata: map[string]any{"beneficial_owners": []any{"Sergei Ivanov"}}},
+	}
+	store := &stubRBACStore{
+		getOrgFn: func(_ context.Context, id string) (auth.Organization, error) {
+			if org, ok := orgs[id]; ok {
+				return org, nil
+			}
+			return auth.Organization{}, auth.ErrNotFound
+		},
+	}
+	screener, err := screening.NewSanctionsScreener([]screening.SanctionsEntry{
+		{ID: "SL-1", Name: "Borealis Shipping Trading LLC", Program: "UN-1718"},
+		{ID: "SL-2", Name: "Ivanov Sergei Petrovich", Program: "EU-833"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := newTestAPI(t, store, WithScreener(screener))
+	perms := []string{auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead}
+	payer := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("payer", "org-payer", perms...)}
+	admin := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("admin",
+		append(perms, auth.PermissionLedgerCrossOrg)...)}
+	officer := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("officer",
+		append(perms, auth.PermissionScreeningReview)...)}
+
+	from := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1_000_000}, payer))
+	payees := map[string]ledger.Account{}
+	for _, org := range []string{"org-ok", "org-hit", "org-near"} {
+		payees[org] = decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "organization_id": org}, admin))
+	}
+	transfer := func(org, idem string) *http.Response {
+		body := map[string]any{"from_id": from.ID, "to_id": payees[org].ID, "currency": "QZN", "amount": 1000}
+		if idem != "" {
+			body["idempotency_key"] = idem
+		}
+		return api.post("/v1/transfers", body, payer)
+	}
+	balance := func(org string) int64 {
+		return decode[ledger.Account](t, api.get("/v1/accounts/"+payees[org].ID, nil, admin)).Balances["QZN"]
+	}
+
+	resp := transfer("org-ok", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("clean transfer: expected 201, got %d", resp.StatusCode)
+	}
+	resp = transfer("org-hit", "")
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("listed payee: expected 403, got %d", resp.StatusCode)
+	}
+	if body := decode[map[string]string](t, resp); strings.Contains(body["error"], "SL-1") {
+		t.Fatalf("expected the rejection not to reveal the match: %+v", body)
+	}
+	if got := balance("org-hit"); got != 0 {
+		t.Fatalf("expected the rejected transfer not to commit, balance %d", got)
+	}
+
+	resp = transfer("org-near", "held-1")
+	if resp.StatusCode != http.StatusAccepted || resp.Header.Get("Location") == "" {
+		t.Fatalf("near match: expected 202 with a location, got %d", resp.StatusCode)
+	}
+	held := decode[heldTransferResponse](t, resp)
+	if held.ReviewID == "" || held.Status != screening.ReviewPending {
+		t.Fatalf("unexpected held transfer: %+v", held)
+	}
+	if again := decode[heldTransferResponse](t, transfer("org-near", "held-1")); again.ReviewID != held.ReviewID {
+		t.Fatalf("expected the retry to wait in the same review, got %+v", again)
+	}
+	if got := balance("org-near"); got != 0 {
+		t.Fatalf("expected the held transfer not to commit, balance %d", got)
+	}
+
+	resp = api.get("/v1/screening/reviews", nil, payer)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("list reviews without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.get("/v1/screening/reviews", url.Values{"status": {"pending"}}, officer)
+	items := decode[map[string][]screening.Review](t, resp)["items"]
+	if len(items) != 1 || items[0].ID != held.ReviewID || items[0].RequestedBy != "payer" || items[0].Result.Matches[0].EntryID != "SL-2" {
+		t.Fatalf("unexpected pending reviews: %+v", items)
+	}
+
+	resp = api.post("/v1/screening/reviews/"+held.ReviewID+"/approve", map[string]any{"note": "different person, checked passport"}, officer)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("approve: expected 200, got %d", resp.StatusCode)
+	}
+	approved := decode[screening.Review](t, resp)
+	if approved.Status != screening.ReviewApproved || approved.TransactionID == "" || approved.DecidedBy != "officer" {
+		t.Fatalf("unexpected approved review: %+v", approved)
+	}
+	if got := balance("org-near"); got != 1000 {
+		t.Fatalf("expected the approved transfer to commit, balance %d", got)
+	}
+	resp = api.post("/v1/screening/reviews/"+held.ReviewID+"/reject", nil, officer)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("second decision: expected 409, got %d", resp.StatusCode)
+	}
+	resp = transfer("org-near", "held-1")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("retry after approval: expected 201, got %d", resp.StatusCode)
+	}
+	if tx := decode[transactionView](t, resp); tx.ID != approved.TransactionID {
+		t.Fatalf("expected the retry to replay the approved transaction, got %+v", tx)
+	}
+
+	other := decode[heldTransferResponse](t, transfer("org-near", ""))
+	resp = api.post("/v1/screening/reviews/"+other.ReviewID+"/reject", map[string]any{"note": "confirmed match"}, officer)
+	if rejected := decode[screening.Review](t, resp); rejected.Status != screening.ReviewRejected || rejected.TransactionID != "" {
+		t.Fatalf("unexpected rejected review: %+v", rejected)
+	}
+	if got := balance("org-near"); got != 1000 {
+		t.Fatalf("expected the rejected review not to commit, balance %d", got)
+	}
+
+	for action, want := range map[string]int{"screening.decision": 6, "screening.review.approve": 1, "screening.review.reject": 1} {
+		if events, _ := sink.Query(context.Background(), audit.Filter{Action: action}); len(events) != want {
+			t.Fatalf("expected %d %s audit events, got %d", want, action, len(events))
+		}
+	}
+}
+
+func TestPostingScreening(t *testing.T) {
+	orgs := map[string]auth.Organization{
+		"org-payer": {ID: "org-payer", Name: "Steppe Grain Cooperative"},
+		"org-ok":    {ID: "org-ok", Name: "Almaty Bakery"},
+		"org-hit":   {ID: "org-hit", Name: "Borealis Shipping Trading Ltd"},
+		"org-near":  {ID: "org-near", Name: "Caspian Logistics", Metadata: map[string]any{"beneficial_owners": []any{"Sergei Ivanov"}}},
+	}
+	store := &stubRBACStore{
+		getOrgFn: func(_ context.Context, id string) (auth.Organization, error) {
+			if org, ok := orgs[id]; ok {
+				return org, nil
+			}
+			return auth.Organization{}, auth.ErrNotFound
+		},
+	}
+	screener, err := screening.NewSanctionsScreener([]screening.SanctionsEntry{
+		{ID: "SL-1", Name: "Borealis Shipping Trading LLC", Program: "UN-1718"},
+		{ID: "SL-2", Name: "Ivanov Sergei Petrovich", Program: "EU-833"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := newTestAPI(t, store, WithScreener(screener))
+	perms := []string{auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead}
+	payer := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("payer", "org-payer", perms...)}
+	admin := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("admin",
+		append(perms, auth.PermissionLedgerCrossOrg, auth.PermissionLedgerFXManage, auth.PermissionScreeningReview)...)}
+
+	from := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1_000_000}, payer))
+	payees := map[string]ledger.Account{}
+	for _, org := range []string{"org-ok", "org-hit", "org-near"} {
+		payees[org] = decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "USD", "organization_id": org}, admin))
+	}
+	qznLiq := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN"}, admin))
+	usdLiq := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "USD", "initial_amount": 50_000}, admin))
+	api.post("/v1/fx/rates", map[string]any{"base": "QZN", "quote": "USD", "rate": "0.25"}, admin).Body.Close()
+	api.put("/v1/fx/liquidity/QZN", map[string]any{"account_id": qznLiq.ID}, admin).Body.Close()
+	api.put("/v1/fx/liquidity/USD", map[string]any{"account_id": usdLiq.ID}, admin).Body.Close()
+
+	fx := func(org string) int {
+		resp := api.post("/v1/fx/transfers", map[string]any{"from_id": from.ID, "to_id": payees[org].ID, "currency": "QZN", "amount": 1000, "target_currency": "USD"}, payer)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	hold := func(org string) *http.Response {
+		return api.post("/v1/holds", map[string]any{"from_id": from.ID, "to_id": payees[org].ID, "currency": "QZN", "amount": 500}, payer)
+	}
+	if code := fx("org-ok"); code != http.StatusCreated {
+		t.Fatalf("clean fx transfer: expected 201, got %d", code)
+	}
+	if code := fx("org-hit"); code != http.StatusForbidden {
+		t.Fatalf("listed fx payee: expected 403, got %d", code)
+	}
+	// FX transfers and holds cannot wait for review, so a near match is refused.
+	if code := fx("org-near"); code != http.StatusForbidden {
+		t.Fatalf("near fx payee: expected 403, got %d", code)
+	}
+	for _, org := range []string{"org-hit", "org-near"} {
+		resp := hold(org)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("hold to %s: expected 403, got %d", org, resp.StatusCode)
+		}
+	}
+	if items := decode[map[string][]screening.Review](t, api.get("/v1/screening/reviews", nil, admin))["items"]; len(items) != 0 {
+		t.Fatalf("expected no review for refused postings, got %+v", items)
+	}
+
+	// A payee listed after its hold was created cannot be paid by capture.
+	resp := hold("org-ok")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("clean hold: expected 201, got %d", resp.StatusCode)
+	}
+	h := decode[holdView](t, resp)
+	orgs["org-ok"] = auth.Organization{ID: "org-ok", Name: "Borealis Shipping Trading Ltd"}
+	resp = api.post("/v1/holds/"+h.ID+"/capture", nil, payer)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("capture to a newly listed payee: expected 403, got %d", resp.StatusCode)
+	}
+	if got := decode[holdView](t, api.get("/v1/holds/"+h.ID, nil, payer)); got.Status != ledger.HoldPending {
+		t.Fatalf("expected the hold to stay pending, got %+v", got)
+	}
+}
+
+func TestISO20022ImportExport(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	orgs := map[string]auth.Organization{
+		"org-bank":   {ID: "org-bank", Name: "Steppe Commercial Bank", Metadata: map[string]any{"bic": "stcbkzka"}},
+		"org-almaty": {ID: "org-almaty", Name: "Almaty Savings Bank"},
+	}
+	store := &stubRBACStore{
+		getOrgFn: func(_ context.Context, id string) (auth.Organization, error) {
+			if org, ok := orgs[id]; ok {
+				return org, nil
+			}
+			return auth.Organization{}, auth.ErrNotFound
+		},
+	}
+	api := newTestAPI(t, store)
+	perms := []string{auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead}
+	bank := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("bank", "org-bank", perms...)}
+	admin := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("admin",
+		append(perms, auth.PermissionLedgerCrossOrg)...)}
+	reader := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("reader", "org-bank", auth.PermissionLedgerRead)}
+
+	payer := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "KZT", "initial_amount": 1_000_000}, bank))
+	payee := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "KZT", "organization_id": "org-almaty"}, admin))
+	credit := func(e2e, amount, from, to string) iso20022.CreditTransferTx {
+		return iso20022.CreditTransferTx{
+			EndToEndID: e2e,
+			Amount:     iso20022.Amount{Currency: "KZT", Value: amount},
+			Debtor:     iso20022.Party{Name: "Steppe Grain Cooperative", Account: from},
+			Creditor:   iso20022.Party{Name: "Almaty Bakery", Account: to},
+		}
+	}
+	submit := func(headers map[string]string, txs ...iso20022.CreditTransferTx) iso20022.StatusReport {
+		t.Helper()
+		msg, err := iso20022.MarshalCreditTransfer(iso20022.CreditTransfer{
+			Message: iso20022.Pacs008, MsgID: "MSG-" + txs[0].EndToEndID, CreatedAt: time.Now(), Transactions: txs,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp := api.post("/v1/iso20022/messages", msg, headers)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/xml") {
+			t.Fatalf("import: expected 200 XML, got %d: %s", resp.StatusCode, body)
+		}
+		rep, err := iso20022.ParseStatusReport(body)
+		if err != nil {
+			t.Fatalf("parse status report: %v\n%s", err, body)
+		}
+		return rep
+	}
+	balance := func(id string) int64 {
+		return decode[ledger.Account](t, api.get("/v1/accounts/"+id, nil, admin)).Balances["KZT"]
+	}
+
+	rep := submit(bank,
+		credit("E2E-1", "1250.50", payer.ID, payee.ID),
+		credit("E2E-2", "20000.00", payer.ID, payee.ID),
+		credit("E2E-1", "1250.50", payer.ID, payee.ID),
+		credit("E2E-3", "1.00", payee.ID, payer.ID),
+		credit("E2E-4", "1.005", payer.ID, payee.ID),
+	)
+	if rep.OriginalMsgID != "MSG-E2E-1" || rep.OriginalMessage != iso20022.Pacs008 || rep.GroupStatus != iso20022.StatusPartial || len(rep.Transactions) != 5 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	for i, want := range []struct{ status, reason string }{
+		{iso20022.StatusSettled, ""},
+		{iso20022.StatusRejected, iso20022.ReasonInsufficientFunds},
+		{iso20022.StatusRejected, iso20022.ReasonDuplicate},
+		{iso20022.StatusRejected, iso20022.ReasonIncorrectAccount},
+		{iso20022.StatusRejected, iso20022.ReasonInvalidAmount},
+	} {
+		if st := rep.Transactions[i]; st.Status != want.status || st.Reason != want.reason {
+			t.Fatalf("transaction %d: expected %s %s, got %+v", i+1, want.status, want.reason, st)
+		}
+	}
+	settled := rep.Transactions[0]
+	if settled.ClearingRef == "" || settled.AcceptedAt.IsZero() || settled.OriginalEndToEndID != "E2E-1" {
+		t.Fatalf("unexpected settled status: %+v", settled)
+	}
+	if got := balance(payee.ID); got != 125050 {
+		t.Fatalf("expected 1250.50 to settle, balance %d", got)
+	}
+
+	again := submit(bank, credit("E2E-1", "1250.50", payer.ID, payee.ID))
+	if st := again.Transactions[0]; st.Status != iso20022.StatusSettled || st.ClearingRef != settled.ClearingRef {
+		t.Fatalf("expected the resubmission to report the settled transaction, got %+v", st)
+	}
+	reused := submit(bank, credit("E2E-1", "99.00", payer.ID, payee.ID))
+	if st := reused.Transactions[0]; st.Status != iso20022.StatusRejected || st.Reason != iso20022.ReasonDuplicate {
+		t.Fatalf("expected a reused EndToEndId to be rejected, got %+v", st)
+	}
+	if got := balance(payee.ID); got != 125050 {
+		t.Fatalf("expected resubmissions not to move funds, balance %d", got)
+	}
+
+	for name, tc := range map[string]struct {
+		body    []byte
+		headers map[string]string
+		want    int
+	}{
+		"malformed":          {[]byte("<Document>"), bank, http.StatusBadRequest},
+		"unsupported":        {[]byte(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"/>`), bank, http.StatusBadRequest},
+		"missing permission": {[]byte("<Document/>"), reader, http.StatusForbidden},
+	} {
+		resp := api.post("/v1/iso20022/messages", tc.body, tc.headers)
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Fatalf("%s: expected %d, got %d", name, tc.want, resp.StatusCode)
+		}
+	}
+
+	resp := api.get("/v1/iso20022/transactions", url.Values{"message": {"pacs.008"}}, reader)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	cursor := resp.Header.Get("X-Next-After")
+	if resp.StatusCode != http.StatusOK || cursor == "" {
+		t.Fatalf("export: expected 200 with a cursor, got %d: %s", resp.StatusCode, body)
+	}
+	exported, err := iso20022.ParseCreditTransfer(body)
+	if err != nil {
+		t.Fatalf("parse export: %v\n%s", err, body)
+	}
+	if len(exported.Transactions) != 1 {
+		t.Fatalf("expected one exported transfer, got %+v", exported.Transactions)
+	}
+	x := exported.Transactions[0]
+	if x.EndToEndID != "E2E-1" || x.TxID != settled.ClearingRef || x.Amount != (iso20022.Amount{Currency: "KZT", Value: "1250.50"}) ||
+		x.Debtor.Account != payer.ID || x.Debtor.Name != "Steppe Commercial Bank" || x.DebtorAgent != (iso20022.Agent{BIC: "STCBKZKA", ID: "org-bank"}) ||
+		x.Creditor.Account != payee.ID || x.Creditor.Name != "Almaty Savings Bank" || x.CreditorAgent != (iso20022.Agent{ID: "org-almaty"}) {
+		t.Fatalf("unexpected exported transfer: %+v", x)
+	}
+	// Importing the export replays the transfer it came from.
+	resp = api.post("/v1/iso20022/messages", body, bank)
+	replayed := decodeStatusReport(t, resp)
+	if st := replayed.Transactions[0]; st.Status != iso20022.StatusSettled || st.ClearingRef != settled.ClearingRef {
+		t.Fatalf("expected the exported transfer to replay, got %+v", st)
+	}
+
+	statuses := decodeStatusReport(t, api.get("/v1/iso20022/transactions", url.Values{"message": {iso20022.Pacs002}}, reader))
+	if len(statuses.Transactions) != 1 || statuses.Transactions[0].Status != iso20022.StatusSettled || statuses.Transactions[0].ClearingRef != settled.ClearingRef {
+		t.Fatalf("unexpected status export: %+v", statuses)
+	}
+	resp = api.get("/v1/iso20022/transactions", url.Values{"after": {cursor}}, reader)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("export past the end: expected 204, got %d", resp.StatusCode)
+	}
+	resp = api.get("/v1/iso20022/transactions", url.Values{"message": {"camt.053"}}, reader)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown message: expected 400, got %d", resp.StatusCode)
+	}
+
+	if events, _ := sink.Query(context.Background(), audit.Filter{Action: "ledger.iso20022.import"}); len(events) != 4 {
+		t.Fatalf("expected 4 import audit events, got %d", len(events))
+	}
+}
+
+func TestAccountStatements(t *testing.T) {
+	store := &stubRBACStore{
+		getOrgFn: func(_ context.Context, id string) (auth.Organization, error) {
+			if id == "org-bank" {
+				return auth.Organization{ID: id, Name: "Steppe Commercial Bank", Metadata: map[string]any{"bic": "STCBKZKA"}}, nil
+			}
+			return auth.Organization{}, auth.ErrNotFound
+		},
+	}
+	api := newTestAPI(t, store)
+	perms := []string{auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead}
+	bank := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("bank", "org-bank", perms...)}
+	other := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("other", "org-other", perms...)}
+
+	payer := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "KZT", "initial_amount": 1_000_000}, bank))
+	payee := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "KZT"}, bank))
+	for i, amount := range []int{125_050, 30_000} {
+		resp := api.post("/v1/transfers", map[string]any{
+			"from_id": payer.ID, "to_id": payee.ID, "currency": "KZT", "amount": amount, "idempotency_key": "stmt-" + strconv.Itoa(i),
+		}, bank)
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+			t.Fatalf("transfer: unexpected status %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	}
+	statement := func(id string, params url.Values, headers map[string]string) (int, iso20022.Statement) {
+		t.Helper()
+		resp := api.get("/v1/accounts/"+id+"/statements", params, headers)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, iso20022.Statement{}
+		}
+		s, err := iso20022.ParseStatement(body)
+		if err != nil {
+			t.Fatalf("parse statement: %v\n%s", err, body)
+		}
+		return resp.StatusCode, s
+	}
+
+	code, s := statement(payer.ID, url.Values{"currency": {"kzt"}, "message": {"camt.052"}}, bank)
+	if code != http.StatusOK || s.Message != iso20022.Camt052 || s.Account.ID != payer.ID || s.Account.Owner != "Steppe Commercial Bank" ||
+		s.Account.Servicer.BIC != "STCBKZKA" || len(s.Entries) != 2 {
+		t.Fatalf("unexpected report (%d): %+v", code, s)
+	}
+	open, _ := s.Balance(iso20022.BalanceOpeningBooked)
+	interim, _ := s.Balance(iso20022.BalanceInterimBooked)
+	if open.Amount.Value != "10000.00" || interim.Amount.Value != "8449.50" || s.Summary.Debits != (iso20022.Total{Count: 2, Sum: "1550.50"}) ||
+		s.Entries[0].EndToEndID != "stmt-0" || s.Entries[0].Counterparty != payee.ID {
+		t.Fatalf("unexpected balances or entries: %+v", s)
+	}
+
+	// Yesterday's end-of-day statement predates both accounts.
+	if code, s := statement(payee.ID, url.Values{"currency": {"KZT"}}, bank); code != http.StatusOK || s.Message != iso20022.Camt053 ||
+		len(s.Entries) != 0 || s.Balances[1].Type != iso20022.BalanceClosingBooked || s.Balances[1].Amount.Value != "0.00" {
+		t.Fatalf("unexpected statement (%d): %+v", code, s)
+	}
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	for _, tc := range []struct {
+		params url.Values
+		code   int
+	}{
+		{url.Values{}, http.StatusBadRequest},
+		{url.Values{"currency": {"KZT"}, "message": {"camt.054"}}, http.StatusBadRequest},
+		{url.Values{"currency": {"KZT"}, "to": {today}}, http.StatusBadRequest},
+		{url.Values{"currency": {"KZT"}, "from": {"2026-10-02"}, "to": {"2026-10-01"}}, http.StatusBadRequest},
+		{url.Values{"currency": {"KZT"}, "from": {"yesterday"}}, http.StatusBadRequest},
+		{url.Values{"currency": {"KZT"}, "message": {"camt.052"}, "from": {"2026-10-02"}}, http.StatusBadRequest},
+		{url.Values{"currency": {"XXX"}}, http.StatusBadRequest},
+	} {
+		if code, _ := statement(payer.ID, tc.params, bank); code != tc.code {
+			t.Fatalf("%v: expected %d, got %d", tc.params, tc.code, code)
+		}
+	}
+	if code, _ := statement(payer.ID, url.Values{"currency": {"KZT"}}, other); code != http.StatusNotFound {
+		t.Fatalf("expected another organization's account to be hidden, got %d", code)
+	}
+	resp := api.post("/v1/accounts/"+payer.ID+"/statements", map[string]any{}, bank)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", resp.StatusCode)
+	}
+}
+
+func decodeStatusReport(t *testing.T, resp *http.Response) iso20022.StatusReport {
+	t.Helper()
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	rep, err := iso20022.ParseStatusReport(body)
+	if err != nil {
+		t.Fatalf("parse status report: %v\n%s", err, body)
+	}
+	return rep
+}
+
+func TestNettingCycles(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	api := newTestAPI(t, nil)
+	perms := []string{auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead}
+	alpha := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("alpha", "org-alpha", perms...)}
+	beta := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("beta", "org-beta", perms...)}
+	operator := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("operator",
+		append(perms, auth.PermissionNettingManage, auth.PermissionLedgerCrossOrg)...)}
+
+	a := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "KZT", "initial_amount": 10_000}, alpha))
+	b := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "KZT", "initial_amount": 1_000}, beta))
+	settlement := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "KZT"}, operator))
+
+	resp := api.post("/v1/netting/cycles", map[string]any{"settlement_account_id": settlement.ID}, alpha)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("open without permission: expected 403, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/netting/cycles", map[string]any{}, operator)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("open without a settlement account: expected 400, got %d", resp.StatusCode)
+	}
+	resp = api.post("/v1/netting/cycles", map[string]any{"settlement_account_id": settlement.ID}, operator)
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Location") == "" {
+		t.Fatalf("open: expected 201 with a location, got %d", resp.StatusCode)
+	}
+	cycle := decode[netting.Cycle](t, resp)
+	path := "/v1/netting/cycles/" + cycle.ID
+
+	submit := func(from, to string, amount int64, headers map[string]string) *http.Response {
+		return api.post(path+"/payments", map[string]any{"from_id": from, "to_id": to, "currency": "kzt", "amount": amount}, headers)
+	}
+	resp = submit(a.ID, b.ID, 8_000, beta)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("payment from another organization's account: expected 404, got %d", resp.StatusCode)
+	}
+	resp = submit(a.ID, settlement.ID, 8_000, alpha)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("payment to the settlement account: expected 400, got %d", resp.StatusCode)
+	}
+	resp = submit(a.ID, b.ID, 8_000, alpha)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("submit: expected 201, got %d", resp.StatusCode)
+	}
+	if p := decode[netting.Payment](t, resp); p.Status != netting.PaymentPending || p.Currency != "KZT" || p.SubmittedBy != "alpha" {
+		t.Fatalf("unexpected payment: %+v", p)
+	}
+	// b cannot pay 7500 gross out of 1000, but is owed 500 net.
+	resp = submit(b.ID, a.ID, 7_500, beta)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("submit: expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = api.post(path+"/settle", nil, operator)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("settle an open cycle: expected 409, got %d", resp.StatusCode)
+	}
+	resp = api.post(path+"/close", nil, operator)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("close: expected 200, got %d", resp.StatusCode)
+	}
+	resp = submit(a.ID, b.ID, 1, alpha)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("submit to a closed cycle: expected 409, got %d", resp.StatusCode)
+	}
+	resp = api.post(path+"/settle", nil, operator)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("settle: expected 200, got %d", resp.StatusCode)
+	}
+	if c := decode[netting.Cycle](t, resp); c.Status != netting.CycleSettled || c.TransactionID == "" {
+		t.Fatalf("unexpected settled cycle: %+v", c)
+	}
+	for id, want := range map[string]int64{a.ID: 9_500, b.ID: 1_500, settlement.ID: 0} {
+		if got := decode[ledger.Account](t, api.get("/v1/accounts/"+id, nil, operator)).Balances["KZT"]; got != want {
+			t.Fatalf("balance of %s: expected %d, got %d", id, want, got)
+		}
+	}
+
+	resp = api.get(path+"/report", nil, operator)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("report: expected 200, got %d", resp.StatusCode)
+	}
+	rep := decode[netting.Report](t, resp)
+	if len(rep.Currencies) != 1 || rep.Currencies[0].Gross != 15_500 || rep.Currencies[0].Saved != 15_000 || len(rep.Positions) != 2 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	resp = api.get("/v1/netting/cycles", url.Values{"status": {"settled"}}, operator)
+	if items := decode[map[string][]netting.Cycle](t, resp)["items"]; len(items) != 1 || items[0].ID != cycle.ID {
+		t.Fatalf("unexpected settled cycles: %+v", items)
+	}
+	resp = api.post(path+"/cancel", map[string]any{"note": "too late"}, operator)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("cancel a settled cycle: expected 409, got %d", resp.StatusCode)
+	}
+
+	for action, want := range map[string]int{"netting.cycle.open": 1, "netting.payment.submit": 2, "netting.cycle.close": 1, "netting.cycle.settle": 1} {
+		if events, _ := sink.Query(context.Background(), audit.Filter{Action: action}); len(events) != want {
+			t.Fatalf("expected %d %s audit events, got %+v", want, action, events)
+		}
+	}
+}
+
+// Netted payments are checked against the currency registry and screened
+// when submitted, charged the fee a transfer would be, and screened again
+// when their cycle settles.
+func TestNettingDurableLedgerNeedsStore(t *testing.T) {
+	durable, err := ledger.OpenDurable(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer durable.Close()
+
+	api := New(ReadyProbe{}, "test", durable, stream.New(), nil, nil, nil)
+	rec := httptest.NewRecorder()
+	if api.requireNetting(rec, httptest.NewRequest(http.MethodGet, "/v1/netting/cycles", nil)) || rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected netting on a durable ledger to need a store, got %d", rec.Code)
+	}
+
+	cycles := netting.NewMemoryStore()
+	api = New(ReadyProbe{}, "test", durable, stream.New(), nil, nil, nil, WithNettingStore(cycles))
+	if api.netting == nil || api.netting.Store() != netting.Store(cycles) {
+		t.Fatalf("expected the configured store to back netting")
+	}
+}
+
+func TestNettingPaymentChecks(t *testing.T) {
+	orgs := map[string]auth.Organization{
+		"org-payer": {ID: "org-payer", Name: "Steppe Grain Cooperative", ParticipantType: auth.ParticipantCorporate},
+		"org-ok":    {ID: "org-ok", Name: "Almaty Bakery"},
+		"org-late":  {ID: "org-late", Name: "Aral Fisheries"},
+		"org-hit":   {ID: "org-hit", Name: "Borealis Shipping Trading Ltd"},
+		"org-near":  {ID: "org-near", Name: "Caspian Logistics", Metadata: map[string]any{"beneficial_owners": []any{"Sergei Ivanov"}}},
+	}
+	store := &stubRBACStore{
+		getOrgFn: func(_ context.Context, id string) (auth.Organization, error) {
+			if org, ok := orgs[id]; ok {
+				return org, nil
+			}
+			return auth.Organization{}, auth.ErrNotFound
+		},
+	}
+	screener, err := screening.NewSanctionsScreener([]screening.SanctionsEntry{
+		{ID: "SL-1", Name: "Borealis Shipping Trading LLC", Program: "UN-1718"},
+		{ID: "SL-2", Name: "Ivanov Sergei Petrovich", Program: "EU-833"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := newTestAPI(t, store, WithScreener(screener))
+	perms := []string{auth.PermissionLedgerAccountCreate, auth.PermissionLedgerTransfer, auth.PermissionLedgerRead}
+	payer := map[string]string{"Authorization": "Bearer " + api.obtainOrgToken("payer", "org-payer", perms...)}
+	admin := map[string]string{"Authorization": "Bearer " + api.obtainTokenWithPermissions("admin",
+		append(perms, auth.PermissionLedgerCrossOrg, auth.PermissionNettingManage, auth.PermissionLedgerFeeManage,
+			auth.PermissionLedgerCurrencyManage, auth.PermissionScreeningReview)...)}
+
+	from := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "initial_amount": 1_000_000}, payer))
+	payees := map[string]ledger.Account{}
+	for _, org := range []string{"org-ok", "org-late", "org-hit", "org-near"} {
+		payees[org] = decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "organization_id": org}, admin))
+	}
+	feeAcct := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN", "type": "fee"}, admin))
+	settlement := decode[ledger.Account](t, api.post("/v1/accounts", map[string]any{"currency": "QZN"}, admin))
+	api.post("/v1/fees/schedules", map[string]any{"rates": map[string]string{"corporate": "0.0015"}, "alpha": "1", "beta": "1", "account_id": feeAcct.ID}, admin).Body.Close()
+	api.put("/v1/currencies/USD", map[string]any{"enabled": false}, admin).Body.Close()
+	api.put("/v1/currencies/QZN", map[string]any{"min_amount": 100}, admin).Body.Close()
+
+	cycle := decode[netting.Cycle](t, api.post("/v1/netting/cycles", map[string]any{"settlement_account_id": settlement.ID}, admin))
+	path := "/v1/netting/cycles/" + cycle.ID
+	submit := func(org, currency string, amount int64) *http.Response {
+		return api.post(path+"/payments", map[string]any{"from_id": from.ID, "to_id": payees[org].ID, "currency": currency, "amount": amount}, payer)
+	}
+	for _, tc := range []struct {
+		currency string
+		amount   int64
+	}{{"QQQ", 1_000}, {"USD", 1_000}, {"QZN", 50}} {
+		resp := submit("org-ok", tc.currency, tc.amount)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s %d: expected 400, got %d", tc.currency, tc.amount, resp.StatusCode)
+		}
+	}
+
+	// 100000 × 0.0015 = 150 and 20000 × 0.0015 = 30
+	resp := submit("org-ok", "QZN", 100_000)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("clean payment: expected 201, got %d", resp.StatusCode)
+	}
+	if p := decode[netting.Payment](t, resp); p.Fee == nil || p.Fee.Amount != 150 || p.Fee.AccountID != feeAcct.ID {
+		t.Fatalf("unexpected payment fee: %+v", p.Fee)
+	}
+	resp = submit("org-late", "QZN", 20_000)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("clean payment: expected 201, got %d", resp.StatusCode)
+	}
+	resp = submit("org-hit", "QZN", 1_000)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("listed payee: expected 403, got %d", resp.StatusCode)
+	}
+	resp = submit("org-near", "QZN", 1_000)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("near payee: expected 202, got %d", resp.StatusCode)
+	}
+	held := decode[heldTransferResponse](t, resp)
+	if items := decode[map[string][]screening.Review](t, api.get("/v1/screening/reviews", nil, admin))["items"]; len(items) != 1 || items[0].ID != held.ReviewID {
+		t.Fatalf("expected the held payment in review, got %+v", items)
+	}
+	if items := decode[map[string][]netting.Payment](t, api.get(path+"/payments", nil, admin))["items"]; len(items) != 2 {
+		t.Fatalf("expected only the clean payments queued, got %+v", items)
+	}
+
+	// A payee listed after its payment was queued is not paid.
+	orgs["org-late"] = auth.Organization{ID: "org-late", Name: "Borealis Shipping Trading Ltd"}
+	api.post(path+"/close", nil, admin).Body.Close()
+	resp = api.post(path+"/settle", nil, admin)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("settle: expected 200, got %d", resp.StatusCode)
+	}
+	payments := decode[map[string][]netting.Payment](t, api.get(path+"/payments", nil, admin))["items"]
+	if payments[0].Status != netting.PaymentSettled || payments[1].Status != netting.PaymentRemoved || payments[1].Reason != netting.ReasonScreened {
+		t.Fatalf("unexpected payments: %+v", payments)
+	}
+	for id, want := range map[string]int64{from.ID: 899_850, payees["org-ok"].ID: 100_000, payees["org-late"].ID: 0, feeAcct.ID: 150, settlement.ID: 0} {
+		if got := decode[ledger.Account](t, api.get("/v1/accounts/"+id, nil, admin)).Balances["QZN"]; got != want {
+			t.Fatalf("balance of %s: expected %d, got %d", id, want, got)
+		}
+	}
 }
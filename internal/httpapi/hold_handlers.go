@@ -0,0 +1,220 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/stream"
+)
+
+type createHoldRequest struct {
+	FromID         string `json:"from_id"`
+	ToID           string `json:"to_id"`
+	Currency       string `json:"currency"`
+	Amount         int64  `json:"amount"`
+	TTLSeconds     int64  `json:"ttl_seconds"` // 0 uses ledger.DefaultHoldTTL
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+type captureHoldRequest struct {
+	Amount int64 `json:"amount"` // 0 captures the full hold
+}
+
+type captureHoldResponse struct {
+	Hold        holdView        `json:"hold"`
+	Transaction transactionView `json:"transaction"`
+}
+
+func (a *API) handleHolds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerTransfer) {
+			return
+		}
+		a.createHold(w, r)
+	default:
+		methodNotAllowed(w, r, http.MethodPost)
+	}
+}
+
+func (a *API) handleHoldResource(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1/holds/")
+	id, action, _ := strings.Cut(path, "/")
+	if id == "" {
+		writeError(w, r, http.StatusNotFound, "resource not found")
+		return
+	}
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) {
+			return
+		}
+		h, err := a.ledger.GetHold(a.ledgerContext(r), id)
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, a.formatter(r.Context()).hold(h))
+	case "capture", "void":
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerTransfer) {
+			return
+		}
+		if action == "capture" {
+			a.captureHold(w, r, id)
+		} else {
+			a.voidHold(w, r, id)
+		}
+	default:
+		writeError(w, r, http.StatusNotFound, "resource not found")
+	}
+}
+
+func (a *API) createHold(w http.ResponseWriter, r *http.Request) {
+	var req createHoldRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	idem, err := idempotencyKey(r, req.IdempotencyKey)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	fromID := strings.TrimSpace(req.FromID)
+	toID := strings.TrimSpace(req.ToID)
+	if fromID == "" || toID == "" {
+		writeError(w, r, http.StatusBadRequest, "from_id and to_id are required")
+		return
+	}
+	if len(fromID) > 64 || len(toID) > 64 {
+		writeError(w, r, http.StatusBadRequest, "account identifiers must be <=64 characters")
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		writeError(w, r, http.StatusBadRequest, "currency is required")
+		return
+	}
+	if len(currency) > 8 {
+		writeError(w, r, http.StatusBadRequest, "currency code too long")
+		return
+	}
+	if req.Amount <= 0 {
+		writeError(w, r, http.StatusBadRequest, "amount must be > 0")
+		return
+	}
+	if req.TTLSeconds < 0 || req.TTLSeconds > int64(ledger.MaxHoldTTL/time.Second) {
+		writeError(w, r, http.StatusBadRequest, ledger.ErrInvalidHoldTTL.Error())
+		return
+	}
+
+	ctx := a.ledgerContext(r)
+	amt := ledger.Money{Currency: currency, Amount: req.Amount}
+	if err := a.transferScreen().posting(ctx, fromID, toID, amt); err != nil {
+		handleScreenedError(w, r, err)
+		return
+	}
+	start := time.Now().UTC()
+	h, err := a.ledger.CreateHold(ctx, fromID, toID, amt, time.Duration(req.TTLSeconds)*time.Second, idem)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	replayed := idem != "" && h.CreatedAt.Before(start)
+	if idem != "" {
+		w.Header().Set("Idempotency-Key", idem)
+	}
+
+	meta := map[string]string{
+		"from_account": fromID,
+		"to_account":   toID,
+		"currency":     currency,
+		"amount":       strconv.FormatInt(req.Amount, 10),
+		"expires_at":   h.ExpiresAt.Format(time.RFC3339),
+	}
+	if idem != "" {
+		meta["idempotency_key"] = idem
+	}
+	event := "ledger.hold.create"
+	if replayed {
+		event = "ledger.hold.create.idempotent_replay"
+	}
+	a.audit(r.Context(), event, "hold", h.ID, meta)
+
+	writeJSON(w, http.StatusCreated, a.formatter(r.Context()).hold(h))
+}
+
+func (a *API) captureHold(w http.ResponseWriter, r *http.Request, id string) {
+	var req captureHoldRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, &req); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.Amount < 0 {
+		writeError(w, r, http.StatusBadRequest, "amount must be >= 0")
+		return
+	}
+
+	ctx := a.ledgerContext(r)
+	if err := a.transferScreen().capture(ctx, id, req.Amount); err != nil {
+		handleScreenedError(w, r, err)
+		return
+	}
+	h, tx, err := a.feeCharger().captureHold(ctx, id, req.Amount)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+
+	if a.stream != nil && !a.outbox {
+		a.stream.Publish(stream.TransferEvent{
+			From:      a.resolveLocation(h.FromAccountID),
+			To:        a.resolveLocation(h.ToAccountID),
+			Amount:    tx.Amount,
+			Currency:  tx.Currency,
+			Timestamp: time.Now().UTC(),
+		})
+	}
+
+	meta := map[string]string{
+		"transaction": tx.ID,
+		"currency":    h.Currency,
+		"held":        strconv.FormatInt(h.Amount, 10),
+		"captured":    strconv.FormatInt(h.CapturedAmount, 10),
+	}
+	if tx.Fee != nil {
+		meta["fee"] = strconv.FormatInt(tx.Fee.Amount, 10)
+		meta["fee_schedule_version"] = strconv.Itoa(tx.Fee.ScheduleVersion)
+	}
+	a.audit(r.Context(), "ledger.hold.capture", "hold", h.ID, meta)
+
+	f := a.formatter(r.Context())
+	writeJSON(w, http.StatusOK, captureHoldResponse{Hold: f.hold(h), Transaction: f.transaction(tx)})
+}
+
+func (a *API) voidHold(w http.ResponseWriter, r *http.Request, id string) {
+	h, err := a.ledger.VoidHold(a.ledgerContext(r), id)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	a.audit(r.Context(), "ledger.hold.void", "hold", h.ID, map[string]string{
+		"currency": h.Currency,
+		"amount":   strconv.FormatInt(h.Amount, 10),
+	})
+	writeJSON(w, http.StatusOK, a.formatter(r.Context()).hold(h))
+}
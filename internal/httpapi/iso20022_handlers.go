@@ -0,0 +1,386 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ids"
+	"qazna.org/internal/iso20022"
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/screening"
+)
+
+// exportMessages maps the message query parameter of the export, short or
+// full, to the message definition rendered.
+var exportMessages = map[string]string{
+	"pacs.008": iso20022.Pacs008, iso20022.Pacs008: iso20022.Pacs008,
+	"pacs.009": iso20022.Pacs009, iso20022.Pacs009: iso20022.Pacs009,
+	"pacs.002": iso20022.Pacs002, iso20022.Pacs002: iso20022.Pacs002,
+}
+
+// handleISO20022Messages serves POST /v1/iso20022/messages: a pacs.008 or
+// pacs.009 credit transfer, answered with a pacs.002 status report.
+func (a *API) handleISO20022Messages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionLedgerTransfer) {
+		return
+	}
+	body := http.MaxBytesReader(w, r.Body, 4<<20)
+	defer body.Close()
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	ct, err := iso20022.ParseCreditTransfer(raw)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := a.ledgerContext(r)
+	f := a.formatter(r.Context())
+	rep := iso20022.StatusReport{
+		MsgID:             ids.New(),
+		OriginalMsgID:     ct.MsgID,
+		OriginalMessage:   ct.Message,
+		OriginalCreatedAt: ct.CreatedAt,
+	}
+	seen := make(map[string]bool, len(ct.Transactions))
+	counts := make(map[string]int)
+	for _, t := range ct.Transactions {
+		st, err := a.settleCreditTransfer(ctx, r, f, t, seen)
+		if err != nil {
+			// Transactions settled so far stay settled; their EndToEndIds make
+			// resubmitting the message safe.
+			handleLedgerError(w, r, err)
+			return
+		}
+		rep.Transactions = append(rep.Transactions, st)
+		counts[st.Status]++
+	}
+	rep.CreatedAt = time.Now().UTC()
+	rep.GroupStatus = iso20022.GroupStatus(rep.Transactions)
+
+	a.audit(r.Context(), "ledger.iso20022.import", "message", ct.MsgID, map[string]string{
+		"message":      ct.Message,
+		"transactions": strconv.Itoa(len(ct.Transactions)),
+		"settled":      strconv.Itoa(counts[iso20022.StatusSettled]),
+		"pending":      strconv.Itoa(counts[iso20022.StatusPending]),
+		"rejected":     strconv.Itoa(counts[iso20022.StatusRejected]),
+		"group_status": rep.GroupStatus,
+		"report_id":    rep.MsgID,
+	})
+	out, err := iso20022.MarshalStatusReport(rep)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeXML(w, http.StatusOK, out)
+}
+
+// settleCreditTransfer commits one transaction of a credit transfer under
+// its EndToEndId as idempotency key and returns its status. Transactions
+// the ledger or screening turns down are rejected with a reason code; an
+// error is only returned for failures of the ledger itself.
+func (a *API) settleCreditTransfer(ctx context.Context, r *http.Request, f *amountFormatter, t iso20022.CreditTransferTx, seen map[string]bool) (iso20022.TransactionStatus, error) {
+	if err := t.Validate(); err != nil {
+		return t.Reject(err), nil
+	}
+	if seen[t.EndToEndID] {
+		return t.Reject(&iso20022.ReasonError{Code: iso20022.ReasonDuplicate, Message: "duplicate EndToEndId in message"}), nil
+	}
+	seen[t.EndToEndID] = true
+	exp, ok := f.exponent(t.Amount.Currency)
+	if !ok {
+		return t.Reject(&iso20022.ReasonError{Code: iso20022.ReasonCurrency, Message: "unknown currency " + t.Amount.Currency}), nil
+	}
+	amt, err := t.Amount.Money(exp)
+	if err != nil {
+		return t.Reject(&iso20022.ReasonError{Code: iso20022.ReasonInvalidAmount, Message: err.Error()}), nil
+	}
+	from, to, idem := t.Debtor.Account, t.Creditor.Account, t.EndToEndID
+
+	if a.screener != nil {
+		res, rv, err := a.transferScreen().transfer(ctx, from, to, amt, idem)
+		if err != nil {
+			return rejectLedgerError(t, err)
+		}
+		rejected := &iso20022.ReasonError{Code: iso20022.ReasonRegulatory, Message: "rejected by screening"}
+		switch {
+		case res.Decision == screening.Reject, res.Decision == screening.Hold && rv.Status == screening.ReviewRejected:
+			return t.Reject(rejected), nil
+		case res.Decision == screening.Hold && rv.Status != screening.ReviewApproved:
+			st := t.Original(iso20022.StatusPending)
+			st.AdditionalInfo = "held for screening review " + rv.ID
+			return st, nil
+		}
+	}
+
+	tx, err := a.commitTransfer(ctx, r, from, to, amt, idem)
+	if err != nil {
+		return rejectLedgerError(t, err)
+	}
+	// The EndToEndId may already have committed a different transfer.
+	legs := tx.Legs()
+	if len(legs) < 2 ||
+		legs[0] != (ledger.Entry{AccountID: from, Direction: ledger.Debit, Currency: amt.Currency, Amount: amt.Amount}) ||
+		legs[1] != (ledger.Entry{AccountID: to, Direction: ledger.Credit, Currency: amt.Currency, Amount: amt.Amount}) {
+		return t.Reject(&iso20022.ReasonError{Code: iso20022.ReasonDuplicate, Message: "EndToEndId already settled another transfer"}), nil
+	}
+	return t.Settled(tx), nil
+}
+
+// rejectLedgerError rejects t for a ledger error the API reports as a
+// client error and passes any other error on.
+func rejectLedgerError(t iso20022.CreditTransferTx, err error) (iso20022.TransactionStatus, error) {
+	if errors.Is(err, errScreeningUnavailable) || ledgerErrorStatus(err) == http.StatusInternalServerError {
+		return iso20022.TransactionStatus{}, err
+	}
+	return t.Reject(&iso20022.ReasonError{Code: iso20022.LedgerReason(err), Message: err.Error()}), nil
+}
+
+// handleISO20022Transactions serves GET /v1/iso20022/transactions: a page
+// of the journal rendered as one pacs.008 or pacs.009 message, or as a
+// pacs.002 report settling each transfer. Transactions that are not
+// transfers between two accounts are skipped; X-Next-After carries the
+// cursor of the next page either way.
+func (a *API) handleISO20022Transactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) {
+		return
+	}
+	message := iso20022.Pacs008
+	if raw := strings.TrimSpace(r.URL.Query().Get("message")); raw != "" {
+		var ok bool
+		if message, ok = exportMessages[raw]; !ok {
+			writeError(w, r, http.StatusBadRequest, "message must be pacs.008, pacs.009 or pacs.002")
+			return
+		}
+	}
+	limit, after, err := parseTransactionPage(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, next, err := a.ledger.ListTransactions(a.ledgerContext(r), limit, after)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	w.Header().Set("X-Next-After", strconv.FormatUint(next, 10))
+
+	f := a.formatter(r.Context())
+	parties := make(map[string]exportParty)
+	now := time.Now().UTC()
+	ct := iso20022.CreditTransfer{Message: message, MsgID: ids.New(), CreatedAt: now}
+	rep := iso20022.StatusReport{MsgID: ct.MsgID, CreatedAt: now}
+	for _, tx := range items {
+		exp, ok := f.exponent(tx.Legs()[0].Currency)
+		if !ok {
+			continue
+		}
+		t, ok := iso20022.FromTransaction(tx, exp)
+		if !ok {
+			continue
+		}
+		if message == iso20022.Pacs002 {
+			rep.Transactions = append(rep.Transactions, t.Settled(tx))
+			continue
+		}
+		for _, side := range []struct {
+			party *iso20022.Party
+			agent *iso20022.Agent
+		}{{&t.Debtor, &t.DebtorAgent}, {&t.Creditor, &t.CreditorAgent}} {
+			p, err := a.exportParty(r, parties, side.party.Account)
+			if err != nil {
+				handleLedgerError(w, r, err)
+				return
+			}
+			side.party.Name, side.party.BIC, *side.agent = p.name, p.agent.BIC, p.agent
+		}
+		ct.Transactions = append(ct.Transactions, t)
+	}
+
+	var out []byte
+	switch {
+	case message == iso20022.Pacs002 && len(rep.Transactions) > 0:
+		out, err = iso20022.MarshalStatusReport(rep)
+	case message != iso20022.Pacs002 && len(ct.Transactions) > 0:
+		out, err = iso20022.MarshalCreditTransfer(ct)
+	default:
+		// A message needs at least one transaction.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeXML(w, http.StatusOK, out)
+}
+
+// exportParty describes the holder of an exported account.
+type exportParty struct {
+	name  string
+	agent iso20022.Agent
+}
+
+// exportParty names the holder of an account by its organization, and its
+// agent by the organization's "bic" metadata and ID. Accounts without an
+// organization fall back to their display name. Lookups are cached in seen.
+func (a *API) exportParty(r *http.Request, seen map[string]exportParty, accountID string) (exportParty, error) {
+	if p, ok := seen[accountID]; ok {
+		return p, nil
+	}
+	// The counterparty of a visible transaction may belong to any
+	// organization.
+	acc, err := a.ledger.GetAccount(ledger.WithoutOrganizationScope(r.Context()), accountID)
+	if err != nil {
+		return exportParty{}, err
+	}
+	p := exportParty{name: acc.DisplayName, agent: iso20022.Agent{ID: acc.OrganizationID}}
+	if acc.OrganizationID != "" && a.rbac != nil {
+		org, err := a.rbac.GetOrganization(r.Context(), acc.OrganizationID)
+		switch {
+		case err == nil:
+			p.name = org.Name
+			if bic, ok := org.Metadata["bic"].(string); ok && iso20022.ValidBIC(strings.ToUpper(strings.TrimSpace(bic))) {
+				p.agent.BIC = strings.ToUpper(strings.TrimSpace(bic))
+			}
+		case !errors.Is(err, auth.ErrNotFound):
+			return exportParty{}, err
+		}
+	}
+	seen[accountID] = p
+	return p, nil
+}
+
+// statementMessages maps the message query parameter of a statement to the
+// message definition rendered.
+var statementMessages = map[string]string{
+	"camt.053": iso20022.Camt053, iso20022.Camt053: iso20022.Camt053,
+	"camt.052": iso20022.Camt052, iso20022.Camt052: iso20022.Camt052,
+}
+
+// getStatement serves GET /v1/accounts/{id}/statements: the account's
+// statement in one currency as a camt.053 over whole days or a camt.052
+// intraday report.
+func (a *API) getStatement(w http.ResponseWriter, r *http.Request, id string) {
+	q := r.URL.Query()
+	currency := strings.ToUpper(strings.TrimSpace(q.Get("currency")))
+	if currency == "" {
+		writeError(w, r, http.StatusBadRequest, "currency query parameter is required")
+		return
+	}
+	message := iso20022.Camt053
+	if raw := strings.TrimSpace(q.Get("message")); raw != "" {
+		var ok bool
+		if message, ok = statementMessages[raw]; !ok {
+			writeError(w, r, http.StatusBadRequest, "message must be camt.053 or camt.052")
+			return
+		}
+	}
+	now := time.Now().UTC()
+	from, to, err := parseStatementPeriod(r, message, now)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	exp, ok := a.formatter(r.Context()).exponent(currency)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "unknown currency "+currency)
+		return
+	}
+
+	s, err := iso20022.GenerateStatement(a.ledgerContext(r), a.ledger, message, id, currency, from, to, exp)
+	if errors.Is(err, iso20022.ErrStatementTooLarge) {
+		writeError(w, r, http.StatusBadRequest, err.Error()+"; narrow the period")
+		return
+	}
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	p, err := a.exportParty(r, make(map[string]exportParty), id)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	s.MsgID, s.CreatedAt = ids.New(), now
+	s.Account.Owner, s.Account.Servicer = p.name, p.agent
+	out, err := iso20022.MarshalStatement(s)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeXML(w, http.StatusOK, out)
+}
+
+// parseStatementPeriod reads the period of a statement. A camt.053 covers
+// the whole UTC days from and to, both dates and yesterday by default, and
+// only days that have ended. A camt.052 covers the RFC3339 times from
+// (midnight by default) to (now by default), cut off at now.
+func parseStatementPeriod(r *http.Request, message string, now time.Time) (from, to time.Time, err error) {
+	q := r.URL.Query()
+	rawFrom, rawTo := strings.TrimSpace(q.Get("from")), strings.TrimSpace(q.Get("to"))
+	today := now.Truncate(24 * time.Hour)
+	if message == iso20022.Camt053 {
+		from, to = today.AddDate(0, 0, -1), today.AddDate(0, 0, -1)
+		if rawFrom != "" {
+			if from, err = time.Parse(time.DateOnly, rawFrom); err != nil {
+				return from, to, errors.New("from must be a date (YYYY-MM-DD)")
+			}
+		}
+		if rawTo != "" {
+			if to, err = time.Parse(time.DateOnly, rawTo); err != nil {
+				return from, to, errors.New("to must be a date (YYYY-MM-DD)")
+			}
+		}
+		to = to.AddDate(0, 0, 1)
+		switch {
+		case !from.Before(to):
+			return from, to, errors.New("from must not be after to")
+		case to.After(today):
+			return from, to, errors.New("camt.053 covers days that have ended; use camt.052 for today")
+		}
+		return from, to, nil
+	}
+
+	from, to = today, now
+	if rawFrom != "" {
+		if from, err = time.Parse(time.RFC3339, rawFrom); err != nil {
+			return from, to, errors.New("from must be an RFC3339 timestamp")
+		}
+	}
+	if rawTo != "" {
+		if to, err = time.Parse(time.RFC3339, rawTo); err != nil {
+			return from, to, errors.New("to must be an RFC3339 timestamp")
+		}
+	}
+	if to.After(now) {
+		to = now
+	}
+	if !from.Before(to) {
+		return from, to, errors.New("from must be before to and now")
+	}
+	return from.UTC(), to.UTC(), nil
+}
+
+func writeXML(w http.ResponseWriter, code int, body []byte) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
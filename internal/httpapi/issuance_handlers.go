@@ -0,0 +1,194 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ledger"
+)
+
+type issuanceRequest struct {
+	AccountID      string `json:"account_id"`
+	Currency       string `json:"currency"`
+	Amount         int64  `json:"amount"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+type issuerAccountRequest struct {
+	AccountID string `json:"account_id"`
+}
+
+// handleMint serves POST /v1/mint.
+func (a *API) handleMint(w http.ResponseWriter, r *http.Request) {
+	a.issue(w, r, ledger.KindMint)
+}
+
+// handleBurn serves POST /v1/burn.
+func (a *API) handleBurn(w http.ResponseWriter, r *http.Request) {
+	a.issue(w, r, ledger.KindBurn)
+}
+
+func (a *API) issue(w http.ResponseWriter, r *http.Request, kind ledger.TransactionKind) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionLedgerIssuanceManage) || !a.requireIssuance(w, r) {
+		return
+	}
+
+	var req issuanceRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	idem, err := idempotencyKey(r, req.IdempotencyKey)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	accountID := strings.TrimSpace(req.AccountID)
+	if accountID == "" {
+		writeError(w, r, http.StatusBadRequest, "account_id is required")
+		return
+	}
+	if len(accountID) > 64 {
+		writeError(w, r, http.StatusBadRequest, "account identifiers must be <=64 characters")
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		writeError(w, r, http.StatusBadRequest, "currency is required")
+		return
+	}
+	if len(currency) > 8 {
+		writeError(w, r, http.StatusBadRequest, "currency code too long")
+		return
+	}
+	if req.Amount <= 0 {
+		writeError(w, r, http.StatusBadRequest, "amount must be > 0")
+		return
+	}
+
+	amt := ledger.Money{Currency: currency, Amount: req.Amount}
+	start := time.Now().UTC()
+	var tx ledger.Transaction
+	if kind == ledger.KindMint {
+		tx, err = a.issuance.Mint(a.ledgerContext(r), accountID, amt, idem)
+	} else {
+		tx, err = a.issuance.Burn(a.ledgerContext(r), accountID, amt, idem)
+	}
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	replayed := idem != "" && !tx.CreatedAt.After(start)
+	if idem != "" {
+		w.Header().Set("Idempotency-Key", idem)
+	}
+
+	meta := map[string]string{
+		"issuer_account": accountID,
+		"currency":       currency,
+		"amount":         strconv.FormatInt(req.Amount, 10),
+	}
+	if idem != "" {
+		meta["idempotency_key"] = idem
+	}
+	event := "ledger." + string(kind)
+	if replayed {
+		event += ".idempotent_replay"
+	}
+	a.audit(r.Context(), event, "transaction", tx.ID, meta)
+
+	writeJSON(w, http.StatusCreated, a.formatter(r.Context()).transaction(tx))
+}
+
+// handleIssuers serves GET /v1/issuers and PUT /v1/issuers/{currency}.
+func (a *API) handleIssuers(w http.ResponseWriter, r *http.Request) {
+	currency := strings.ToUpper(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/issuers"), "/"))
+	if currency == "" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) || !a.requireIssuance(w, r) {
+			return
+		}
+		accounts, err := a.issuance.IssuerAccounts(r.Context())
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"accounts": accounts})
+		return
+	}
+
+	if r.Method != http.MethodPut {
+		methodNotAllowed(w, r, http.MethodPut)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionLedgerIssuanceManage) || !a.requireIssuance(w, r) {
+		return
+	}
+	if len(currency) > 8 {
+		writeError(w, r, http.StatusBadRequest, "currency code too long")
+		return
+	}
+	var req issuerAccountRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	accountID := strings.TrimSpace(req.AccountID)
+	if accountID == "" {
+		writeError(w, r, http.StatusBadRequest, "account_id is required")
+		return
+	}
+	if err := a.issuance.SetIssuerAccount(r.Context(), currency, accountID); err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	a.audit(r.Context(), "ledger.issuer.set", "account", accountID, map[string]string{"currency": currency})
+	writeJSON(w, http.StatusOK, map[string]string{"currency": currency, "account_id": accountID})
+}
+
+type supplyView struct {
+	ledger.Supply
+	FormattedOutstanding string `json:"formatted_outstanding,omitempty"`
+}
+
+// handleSupply serves GET /v1/supply: the outstanding amount of every
+// currency, which always equals the sum of its holder balances, and how
+// much of it was minted, burned or funded at account creation.
+func (a *API) handleSupply(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) || !a.requireIssuance(w, r) {
+		return
+	}
+	supply, err := a.issuance.Supply(r.Context())
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	f := a.formatter(r.Context())
+	items := make([]supplyView, 0, len(supply))
+	for _, s := range supply {
+		items = append(items, supplyView{Supply: s, FormattedOutstanding: f.format(s.Currency, s.Outstanding)})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (a *API) requireIssuance(w http.ResponseWriter, r *http.Request) bool {
+	if a.issuance == nil {
+		writeError(w, r, http.StatusServiceUnavailable, "issuance unavailable")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,726 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	v1 "qazna.org/api/gen/go/api/proto/qazna/v1"
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/screening"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const ledgerErrorDomain = "ledger.qazna.org"
+
+// LedgerGRPCServer exposes any ledger.Service as qazna.v1.LedgerService.
+//
+// Caller identity is taken from the x-qazna-user-id / x-qazna-roles metadata
+// attached by the remote ledger client, and the organization scope from
+// x-qazna-org-scope. Each RPC requires the permission of the matching HTTP
+// route (ledger.read, ledger.transfer, ledger.reverse, ...), resolved from
+// the roles the caller is assigned in RBAC. A scoped caller must belong to
+// the organization it names; a call without a scope acts across all
+// organizations and also requires ledger.cross_org. The metadata itself is
+// not authenticated, so the server must only be registered on a listener
+// reachable by internal services, never on the public gRPC port (see
+// QAZNA_LEDGER_SERVICE_ADDR in cmd/api).
+//
+// Transfers and hold captures charge the fee of the current schedule when svc
+// is a ledger.FeeEngine, and every posting is screened when a screener is
+// configured, as they are over HTTP.
+type LedgerGRPCServer struct {
+	v1.UnimplementedLedgerServiceServer
+
+	ledger  ledger.Service
+	rbac    *auth.RBACService
+	fees    feeCharger
+	screen  transferScreen
+	funding bool
+}
+
+// LedgerGRPCOption configures a LedgerGRPCServer.
+type LedgerGRPCOption func(*LedgerGRPCServer)
+
+// WithLedgerRBAC authorizes callers by their permissions, classifies payers
+// for the fee schedule by the participant type of their organization, and
+// names organizations for the screener. Without it every call fails with
+// UNAVAILABLE.
+func WithLedgerRBAC(rbac *auth.RBACService) LedgerGRPCOption {
+	return func(s *LedgerGRPCServer) {
+		s.rbac = rbac
+		s.fees.rbac = rbac
+		s.screen.rbac = rbac
+	}
+}
+
+// WithLedgerInitialFunding accepts CreateAccount requests with a non-zero
+// initial amount, as WithInitialFunding does over HTTP.
+func WithLedgerInitialFunding() LedgerGRPCOption {
+	return func(s *LedgerGRPCServer) {
+		s.funding = true
+	}
+}
+
+// WithLedgerScreening screens every posting with screener before it is
+// committed. A held Transfer waits in reviews like one sent over HTTP; held
+// postings of other kinds are refused.
+func WithLedgerScreening(screener screening.Screener, reviews screening.ReviewQueue) LedgerGRPCOption {
+	return func(s *LedgerGRPCServer) {
+		s.screen.screener, s.screen.reviews = screener, reviews
+	}
+}
+
+// LedgerScreening screens LedgerService postings with the screener and
+// review queue of a, so transfers held over gRPC are decided at
+// /v1/screening/reviews like the rest.
+func (a *API) LedgerScreening() LedgerGRPCOption {
+	return WithLedgerScreening(a.screener, a.reviews)
+}
+
+// NewLedgerGRPCServer wraps svc (in-memory, Postgres or remote) for gRPC.
+func NewLedgerGRPCServer(svc ledger.Service, opts ...LedgerGRPCOption) *LedgerGRPCServer {
+	s := &LedgerGRPCServer{ledger: svc, fees: feeCharger{ledger: svc}, screen: transferScreen{ledger: svc}}
+	if fees, ok := svc.(ledger.FeeEngine); ok {
+		s.fees.fees = fees
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateAccount opens an account. A non-zero initial amount is refused
+// unless initial funding is enabled.
+func (s *LedgerGRPCServer) CreateAccount(ctx context.Context, req *v1.CreateAccountRequest) (*v1.Account, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerAccountCreate)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetInitialAmount() != 0 && !s.funding {
+		return nil, status.Error(codes.InvalidArgument, "initial_amount is disabled; mint into an issuer account and transfer instead")
+	}
+	acc, err := s.ledger.CreateAccount(ctx, ledger.Money{
+		Currency: strings.TrimSpace(req.GetCurrency()),
+		Amount:   req.GetInitialAmount(),
+	},
+		ledger.WithAccountType(fromProtoAccountType(req.GetType())),
+		ledger.WithDisplayName(req.GetDisplayName()),
+		ledger.WithExternalRef(req.GetExternalRef()),
+	)
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return toProtoAccount(acc), nil
+}
+
+// GetAccount returns an account with all balances.
+func (s *LedgerGRPCServer) GetAccount(ctx context.Context, req *v1.GetAccountRequest) (*v1.Account, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerRead)
+	if err != nil {
+		return nil, err
+	}
+	acc, err := s.ledger.GetAccount(ctx, req.GetId())
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return toProtoAccount(acc), nil
+}
+
+// SetAccountStatus freezes, unfreezes or closes an account.
+func (s *LedgerGRPCServer) SetAccountStatus(ctx context.Context, req *v1.SetAccountStatusRequest) (*v1.Account, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerAccountStatus)
+	if err != nil {
+		return nil, err
+	}
+	acc, err := s.ledger.SetAccountStatus(ctx, req.GetId(), fromProtoAccountStatus(req.GetStatus()))
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return toProtoAccount(acc), nil
+}
+
+// GetBalance returns the balance of an account in one currency.
+func (s *LedgerGRPCServer) GetBalance(ctx context.Context, req *v1.GetBalanceRequest) (*v1.Balance, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerRead)
+	if err != nil {
+		return nil, err
+	}
+	currency := strings.TrimSpace(req.GetCurrency())
+	if currency == "" {
+		return nil, ledgerStatusError(ledger.ErrInvalidCurrency)
+	}
+	bal, err := s.ledger.GetBalance(ctx, req.GetId(), currency)
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return &v1.Balance{Currency: bal.Currency, Amount: bal.Amount, Held: bal.Held, Available: bal.Available}, nil
+}
+
+// GetBalanceAt recomputes a balance as of a past sequence or time.
+func (s *LedgerGRPCServer) GetBalanceAt(ctx context.Context, req *v1.GetBalanceAtRequest) (*v1.HistoricalBalance, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerRead)
+	if err != nil {
+		return nil, err
+	}
+	currency := strings.TrimSpace(req.GetCurrency())
+	if currency == "" {
+		return nil, ledgerStatusError(ledger.ErrInvalidCurrency)
+	}
+	at := ledger.BalancePoint{Sequence: req.GetAsOfSequence()}
+	if req.GetAsOfTime() != nil {
+		at.Time = req.GetAsOfTime().AsTime()
+	}
+	bal, err := s.ledger.GetBalanceAt(ctx, req.GetId(), currency, at)
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return &v1.HistoricalBalance{Currency: bal.Currency, Amount: bal.Amount, Sequence: bal.Sequence}, nil
+}
+
+// Transfer moves funds between two accounts, charging the payer's fee.
+func (s *LedgerGRPCServer) Transfer(ctx context.Context, req *v1.TransferRequest) (*v1.TransferResponse, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerTransfer)
+	if err != nil {
+		return nil, err
+	}
+	amt := ledger.Money{Currency: strings.TrimSpace(req.GetCurrency()), Amount: req.GetAmount()}
+	idem := strings.TrimSpace(req.GetIdempotencyKey())
+	if err := s.screenTransfer(ctx, req.GetFromId(), req.GetToId(), amt, idem); err != nil {
+		return nil, err
+	}
+	tx, err := s.fees.transfer(ctx, req.GetFromId(), req.GetToId(), amt, idem)
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return &v1.TransferResponse{Transaction: toProtoTransaction(tx)}, nil
+}
+
+// screenTransfer screens a transfer about to be committed. A held transfer
+// waits in the review queue and fails with FAILED_PRECONDITION, reason
+// SCREENING_REVIEW_PENDING and the review_id in the ErrorInfo metadata; once
+// approved, retrying it with the same idempotency key replays the transfer
+// the approval committed.
+func (s *LedgerGRPCServer) screenTransfer(ctx context.Context, fromID, toID string, amt ledger.Money, idem string) error {
+	if s.screen.screener == nil {
+		return nil
+	}
+	res, rv, err := s.screen.transfer(ctx, fromID, toID, amt, idem)
+	if err != nil {
+		return ledgerStatusError(err)
+	}
+	switch {
+	case res.Decision == screening.Allow, res.Decision == screening.Hold && rv.Status == screening.ReviewApproved:
+		return nil
+	case res.Decision == screening.Hold && rv.Status == screening.ReviewPending:
+		st, detailErr := status.New(codes.FailedPrecondition, "transfer held for screening review").WithDetails(&errdetails.ErrorInfo{
+			Reason:   "SCREENING_REVIEW_PENDING",
+			Domain:   ledgerErrorDomain,
+			Metadata: map[string]string{"review_id": rv.ID},
+		})
+		if detailErr != nil {
+			return status.Error(codes.FailedPrecondition, "transfer held for screening review")
+		}
+		return st.Err()
+	default:
+		return ledgerStatusError(errScreeningRejected)
+	}
+}
+
+// ListTransactions pages through transactions in sequence order.
+func (s *LedgerGRPCServer) ListTransactions(ctx context.Context, req *v1.ListTransactionsRequest) (*v1.ListTransactionsResponse, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerRead)
+	if err != nil {
+		return nil, err
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	items, next, err := s.ledger.ListTransactions(ctx, limit, req.GetAfterSequence())
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	resp := &v1.ListTransactionsResponse{
+		Items:     make([]*v1.Transaction, 0, len(items)),
+		NextAfter: next,
+	}
+	for _, tx := range items {
+		resp.Items = append(resp.Items, toProtoTransaction(tx))
+	}
+	return resp, nil
+}
+
+// ListAccountTransactions pages the history of one account.
+func (s *LedgerGRPCServer) ListAccountTransactions(ctx context.Context, req *v1.ListAccountTransactionsRequest) (*v1.ListTransactionsResponse, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerRead)
+	if err != nil {
+		return nil, err
+	}
+	f := ledger.TransactionFilter{
+		Direction: fromProtoDirection(req.GetDirection()),
+		Currency:  strings.TrimSpace(req.GetCurrency()),
+		AfterSeq:  req.GetAfterSequence(),
+		Limit:     int(req.GetLimit()),
+	}
+	if req.GetFrom() != nil {
+		f.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		f.To = req.GetTo().AsTime()
+	}
+	items, next, err := s.ledger.ListAccountTransactions(ctx, req.GetAccountId(), f)
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	resp := &v1.ListTransactionsResponse{
+		Items:     make([]*v1.Transaction, 0, len(items)),
+		NextAfter: next,
+	}
+	for _, tx := range items {
+		resp.Items = append(resp.Items, toProtoTransaction(tx))
+	}
+	return resp, nil
+}
+
+// PostEntries commits a multi-leg batch atomically.
+func (s *LedgerGRPCServer) PostEntries(ctx context.Context, req *v1.PostEntriesRequest) (*v1.PostEntriesResponse, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerTransfer)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]ledger.Entry, 0, len(req.GetEntries()))
+	for _, e := range req.GetEntries() {
+		entries = append(entries, fromProtoEntry(e))
+	}
+	if err := s.screen.entries(ctx, entries); err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	tx, err := s.ledger.PostEntries(ctx, entries, strings.TrimSpace(req.GetIdempotencyKey()))
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return &v1.PostEntriesResponse{Transaction: toProtoTransaction(tx)}, nil
+}
+
+// Reverse posts a compensating transaction for an earlier one.
+func (s *LedgerGRPCServer) Reverse(ctx context.Context, req *v1.ReverseRequest) (*v1.ReverseResponse, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerReverse)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.ledger.Reverse(ctx, strings.TrimSpace(req.GetTransactionId()), req.GetAmount(), req.GetReason(), strings.TrimSpace(req.GetIdempotencyKey()))
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return &v1.ReverseResponse{Transaction: toProtoTransaction(tx)}, nil
+}
+
+// FXTransfer converts funds into another currency at the current rate.
+func (s *LedgerGRPCServer) FXTransfer(ctx context.Context, req *v1.FXTransferRequest) (*v1.FXTransferResponse, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerTransfer)
+	if err != nil {
+		return nil, err
+	}
+	amt := ledger.Money{Currency: strings.TrimSpace(req.GetCurrency()), Amount: req.GetAmount()}
+	if err := s.screen.posting(ctx, req.GetFromId(), req.GetToId(), amt); err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	tx, err := s.ledger.FXTransfer(ctx, req.GetFromId(), req.GetToId(), amt,
+		strings.TrimSpace(req.GetTargetCurrency()), strings.TrimSpace(req.GetIdempotencyKey()))
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return &v1.FXTransferResponse{Transaction: toProtoTransaction(tx)}, nil
+}
+
+// CreateHold reserves funds for a later capture.
+func (s *LedgerGRPCServer) CreateHold(ctx context.Context, req *v1.CreateHoldRequest) (*v1.Hold, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerTransfer)
+	if err != nil {
+		return nil, err
+	}
+	amt := ledger.Money{Currency: strings.TrimSpace(req.GetCurrency()), Amount: req.GetAmount()}
+	if err := s.screen.posting(ctx, req.GetFromId(), req.GetToId(), amt); err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	h, err := s.ledger.CreateHold(ctx, req.GetFromId(), req.GetToId(), amt,
+		time.Duration(req.GetTtlSeconds())*time.Second, strings.TrimSpace(req.GetIdempotencyKey()))
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return toProtoHold(h), nil
+}
+
+// GetHold returns a hold by id.
+func (s *LedgerGRPCServer) GetHold(ctx context.Context, req *v1.GetHoldRequest) (*v1.Hold, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerRead)
+	if err != nil {
+		return nil, err
+	}
+	h, err := s.ledger.GetHold(ctx, strings.TrimSpace(req.GetId()))
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return toProtoHold(h), nil
+}
+
+// CaptureHold transfers all or part of a pending hold, charging the payer's
+// fee.
+func (s *LedgerGRPCServer) CaptureHold(ctx context.Context, req *v1.CaptureHoldRequest) (*v1.CaptureHoldResponse, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerTransfer)
+	if err != nil {
+		return nil, err
+	}
+	id := strings.TrimSpace(req.GetId())
+	if err := s.screen.capture(ctx, id, req.GetAmount()); err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	h, tx, err := s.fees.captureHold(ctx, id, req.GetAmount())
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return &v1.CaptureHoldResponse{Hold: toProtoHold(h), Transaction: toProtoTransaction(tx)}, nil
+}
+
+// VoidHold releases a pending hold without moving funds.
+func (s *LedgerGRPCServer) VoidHold(ctx context.Context, req *v1.VoidHoldRequest) (*v1.Hold, error) {
+	ctx, err := s.authorize(ctx, auth.PermissionLedgerTransfer)
+	if err != nil {
+		return nil, err
+	}
+	h, err := s.ledger.VoidHold(ctx, strings.TrimSpace(req.GetId()))
+	if err != nil {
+		return nil, ledgerStatusError(err)
+	}
+	return toProtoHold(h), nil
+}
+
+// WatchTransactions replays the transactions after the requested sequence
+// and then streams new ones as they commit. Clients resume after a
+// disconnect by passing the last sequence they received.
+func (s *LedgerGRPCServer) WatchTransactions(req *v1.WatchTransactionsRequest, stream v1.LedgerService_WatchTransactionsServer) error {
+	w, ok := s.ledger.(ledger.Watcher)
+	if !ok {
+		return status.Error(codes.Unimplemented, "ledger backend cannot stream transactions")
+	}
+	ctx, err := s.authorize(stream.Context(), auth.PermissionLedgerRead)
+	if err != nil {
+		return err
+	}
+	f := ledger.WatchFilter{
+		AccountID: strings.TrimSpace(req.GetAccountId()),
+		Direction: fromProtoDirection(req.GetDirection()),
+		Currency:  strings.TrimSpace(req.GetCurrency()),
+	}
+	if f.AccountID != "" {
+		// Other organizations' accounts read as not found, as in
+		// ListAccountTransactions.
+		if _, err := s.ledger.GetAccount(ctx, f.AccountID); err != nil {
+			return ledgerStatusError(err)
+		}
+	}
+	err = w.WatchTransactions(ctx, req.GetAfterSequence(), func(tx ledger.Transaction) error {
+		if !f.Match(tx) {
+			return nil
+		}
+		return stream.Send(toProtoTransaction(tx))
+	})
+	return ledgerStatusError(err)
+}
+
+// authorize reads the caller from the metadata of ctx and checks that its
+// permissions include perms, failing with UNAUTHENTICATED without a user id
+// and PERMISSION_DENIED when a permission is missing. An unscoped call needs
+// ledger.cross_org as well; a scoped one needs the user to belong to the
+// organization in scope, unless it holds ledger.cross_org.
+func (s *LedgerGRPCServer) authorize(ctx context.Context, perms ...string) (context.Context, error) {
+	ctx = incomingWithIdentity(ctx)
+	if s.rbac == nil {
+		return nil, status.Error(codes.Unavailable, "rbac service unavailable")
+	}
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "x-qazna-user-id is required")
+	}
+	granted, err := s.rbac.UserPermissions(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "permission lookup failed")
+	}
+	crossOrg := hasAllPermissions(granted, []string{auth.PermissionLedgerCrossOrg})
+	orgID, scoped := ledger.OrganizationScope(ctx)
+	if !hasAllPermissions(granted, perms) || (!scoped && !crossOrg) {
+		return nil, status.Error(codes.PermissionDenied, "missing required permission")
+	}
+	if scoped && !crossOrg {
+		if _, err := s.rbac.GetUser(ctx, orgID, userID); errors.Is(err, auth.ErrNotFound) || errors.Is(err, auth.ErrInvalidInput) {
+			return nil, status.Error(codes.PermissionDenied, "caller does not belong to the organization in scope")
+		} else if err != nil {
+			return nil, status.Error(codes.Internal, "organization lookup failed")
+		}
+	}
+	return ctx, nil
+}
+
+// incomingWithIdentity copies the caller identity and organization scope
+// from gRPC metadata into ctx. A missing scope header means an unrestricted
+// caller.
+func incomingWithIdentity(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if vals := md.Get("x-qazna-org-scope"); len(vals) > 0 {
+		ctx = ledger.WithOrganizationScope(ctx, strings.TrimSpace(vals[0]))
+	}
+	var userID string
+	if vals := md.Get("x-qazna-user-id"); len(vals) > 0 {
+		userID = strings.TrimSpace(vals[0])
+	}
+	var roles []string
+	for _, v := range md.Get("x-qazna-roles") {
+		for _, role := range strings.Split(v, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+	}
+	if userID == "" && len(roles) == 0 {
+		return ctx
+	}
+	return auth.ContextWithUser(ctx, userID, roles)
+}
+
+// ledgerStatusError converts ledger errors into gRPC statuses. The status
+// message is the ledger error text so remote clients can map it back; the
+// ErrorInfo detail carries a stable machine-readable reason.
+func ledgerStatusError(err error) error {
+	var (
+		code   codes.Code
+		reason string
+		msg    = err.Error()
+	)
+	switch {
+	case errors.Is(err, ledger.ErrNotFound):
+		code, reason, msg = codes.NotFound, "NOT_FOUND", ledger.ErrNotFound.Error()
+	case errors.Is(err, ledger.ErrInvalidAmount):
+		code, reason, msg = codes.InvalidArgument, "INVALID_AMOUNT", ledger.ErrInvalidAmount.Error()
+	case errors.Is(err, ledger.ErrInvalidCurrency):
+		code, reason, msg = codes.InvalidArgument, "INVALID_CURRENCY", ledger.ErrInvalidCurrency.Error()
+	case errors.Is(err, ledger.ErrUnbalanced):
+		code, reason, msg = codes.InvalidArgument, "UNBALANCED_ENTRIES", ledger.ErrUnbalanced.Error()
+	case errors.Is(err, ledger.ErrInvalidAccountType):
+		code, reason, msg = codes.InvalidArgument, "INVALID_ACCOUNT_TYPE", ledger.ErrInvalidAccountType.Error()
+	case errors.Is(err, ledger.ErrAccountLabelTooLong):
+		code, reason, msg = codes.InvalidArgument, "ACCOUNT_LABEL_TOO_LONG", ledger.ErrAccountLabelTooLong.Error()
+	case errors.Is(err, ledger.ErrInvalidAccountStatus):
+		code, reason, msg = codes.InvalidArgument, "INVALID_ACCOUNT_STATUS", ledger.ErrInvalidAccountStatus.Error()
+	case errors.Is(err, ledger.ErrInvalidBalancePoint):
+		code, reason, msg = codes.InvalidArgument, "INVALID_BALANCE_POINT", ledger.ErrInvalidBalancePoint.Error()
+	case errors.Is(err, ledger.ErrInvalidReversalReason):
+		code, reason, msg = codes.InvalidArgument, "INVALID_REVERSAL_REASON", ledger.ErrInvalidReversalReason.Error()
+	case errors.Is(err, ledger.ErrInvalidHoldTTL):
+		code, reason, msg = codes.InvalidArgument, "INVALID_HOLD_TTL", ledger.ErrInvalidHoldTTL.Error()
+	case errors.Is(err, ledger.ErrInvalidFXRate):
+		code, reason, msg = codes.InvalidArgument, "INVALID_FX_RATE", ledger.ErrInvalidFXRate.Error()
+	case errors.Is(err, ledger.ErrInsufficientFunds):
+		code, reason, msg = codes.FailedPrecondition, "INSUFFICIENT_FUNDS", ledger.ErrInsufficientFunds.Error()
+	case errors.Is(err, ledger.ErrAccountFrozen):
+		code, reason, msg = codes.FailedPrecondition, "ACCOUNT_FROZEN", ledger.ErrAccountFrozen.Error()
+	case errors.Is(err, ledger.ErrAccountClosed):
+		code, reason, msg = codes.FailedPrecondition, "ACCOUNT_CLOSED", ledger.ErrAccountClosed.Error()
+	case errors.Is(err, ledger.ErrAccountNotEmpty):
+		code, reason, msg = codes.FailedPrecondition, "ACCOUNT_NOT_EMPTY", ledger.ErrAccountNotEmpty.Error()
+	case errors.Is(err, ledger.ErrAlreadyReversed):
+		code, reason, msg = codes.FailedPrecondition, "ALREADY_REVERSED", ledger.ErrAlreadyReversed.Error()
+	case errors.Is(err, ledger.ErrReversalExceedsOriginal):
+		code, reason, msg = codes.FailedPrecondition, "REVERSAL_EXCEEDS_ORIGINAL", ledger.ErrReversalExceedsOriginal.Error()
+	case errors.Is(err, ledger.ErrNotReversible):
+		code, reason, msg = codes.FailedPrecondition, "NOT_REVERSIBLE", ledger.ErrNotReversible.Error()
+	case errors.Is(err, ledger.ErrHoldNotPending):
+		code, reason, msg = codes.FailedPrecondition, "HOLD_NOT_PENDING", ledger.ErrHoldNotPending.Error()
+	case errors.Is(err, ledger.ErrHoldExpired):
+		code, reason, msg = codes.FailedPrecondition, "HOLD_EXPIRED", ledger.ErrHoldExpired.Error()
+	case errors.Is(err, ledger.ErrCaptureExceedsHold):
+		code, reason, msg = codes.FailedPrecondition, "CAPTURE_EXCEEDS_HOLD", ledger.ErrCaptureExceedsHold.Error()
+	case errors.Is(err, ledger.ErrNoFXRate):
+		code, reason, msg = codes.FailedPrecondition, "NO_FX_RATE", ledger.ErrNoFXRate.Error()
+	case errors.Is(err, ledger.ErrNoFXLiquidity):
+		code, reason, msg = codes.FailedPrecondition, "NO_FX_LIQUIDITY", ledger.ErrNoFXLiquidity.Error()
+	case errors.Is(err, ledger.ErrNotIssuer):
+		code, reason, msg = codes.FailedPrecondition, "NOT_ISSUER", ledger.ErrNotIssuer.Error()
+	case errors.Is(err, ledger.ErrIssuanceAccount):
+		code, reason, msg = codes.FailedPrecondition, "ISSUANCE_ACCOUNT", ledger.ErrIssuanceAccount.Error()
+	case errors.Is(err, ledger.ErrLimitExceeded):
+		// Keep the message, which names the limit that was hit.
+		code, reason = codes.ResourceExhausted, "LIMIT_EXCEEDED"
+	case errors.Is(err, ledger.ErrInvalidLimit):
+		code, reason, msg = codes.InvalidArgument, "INVALID_LIMIT", ledger.ErrInvalidLimit.Error()
+	case errors.Is(err, errScreeningRejected):
+		code, reason, msg = codes.PermissionDenied, "SCREENING_REJECTED", errScreeningRejected.Error()
+	case errors.Is(err, errScreeningHeld):
+		code, reason, msg = codes.PermissionDenied, "SCREENING_HELD", errScreeningHeld.Error()
+	case errors.Is(err, errScreeningUnavailable):
+		code, reason, msg = codes.Unavailable, "SCREENING_UNAVAILABLE", errScreeningUnavailable.Error()
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, msg)
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, msg)
+	default:
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, "internal error")
+	}
+	st, detailErr := status.New(code, msg).WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: ledgerErrorDomain,
+	})
+	if detailErr != nil {
+		return status.Error(code, msg)
+	}
+	return st.Err()
+}
+
+func toProtoAccount(acc ledger.Account) *v1.Account {
+	balances := make(map[string]int64, len(acc.Balances))
+	for k, v := range acc.Balances {
+		balances[k] = v
+	}
+	return &v1.Account{
+		Id:             acc.ID,
+		CreatedAt:      timestamppb.New(acc.CreatedAt),
+		Balances:       balances,
+		OrganizationId: acc.OrganizationID,
+		Type:           toProtoAccountType(acc.Type),
+		DisplayName:    acc.DisplayName,
+		ExternalRef:    acc.ExternalRef,
+		Status:         toProtoAccountStatus(acc.Status),
+	}
+}
+
+// The proto enums are the upper-cased ledger values behind a prefix;
+// unspecified maps to the empty string.
+
+func toProtoAccountType(t ledger.AccountType) v1.AccountType {
+	return v1.AccountType(v1.AccountType_value["ACCOUNT_TYPE_"+strings.ToUpper(string(t))])
+}
+
+func fromProtoAccountType(t v1.AccountType) ledger.AccountType {
+	if t == v1.AccountType_ACCOUNT_TYPE_UNSPECIFIED {
+		return ""
+	}
+	return ledger.AccountType(strings.ToLower(strings.TrimPrefix(t.String(), "ACCOUNT_TYPE_")))
+}
+
+func toProtoAccountStatus(st ledger.AccountStatus) v1.AccountStatus {
+	return v1.AccountStatus(v1.AccountStatus_value["ACCOUNT_STATUS_"+strings.ToUpper(string(st))])
+}
+
+func fromProtoAccountStatus(st v1.AccountStatus) ledger.AccountStatus {
+	if st == v1.AccountStatus_ACCOUNT_STATUS_UNSPECIFIED {
+		return ""
+	}
+	return ledger.AccountStatus(strings.ToLower(strings.TrimPrefix(st.String(), "ACCOUNT_STATUS_")))
+}
+
+func toProtoTransaction(tx ledger.Transaction) *v1.Transaction {
+	out := &v1.Transaction{
+		Id:             tx.ID,
+		CreatedAt:      timestamppb.New(tx.CreatedAt),
+		FromAccountId:  tx.FromAccountID,
+		ToAccountId:    tx.ToAccountID,
+		Currency:       tx.Currency,
+		Amount:         tx.Amount,
+		IdempotencyKey: tx.IdempotencyKey,
+		Sequence:       tx.Sequence,
+		ReversalOf:     tx.ReversalOf,
+		ReversalReason: tx.ReversalReason,
+		ReversedAmount: tx.ReversedAmount,
+		ReversalStatus: toProtoReversalStatus(tx.ReversalStatus),
+		FxRateId:       tx.FXRateID,
+		FxRate:         tx.FXRate,
+		Kind:           string(tx.Kind),
+	}
+	if f := tx.Fee; f != nil {
+		out.Fee = &v1.Fee{
+			ScheduleVersion: int32(f.ScheduleVersion),
+			ParticipantType: f.ParticipantType,
+			Rate:            f.Rate,
+			Alpha:           f.Alpha,
+			Beta:            f.Beta,
+			Rounding:        string(f.Rounding),
+			AccountId:       f.AccountID,
+			Currency:        f.Currency,
+			Amount:          f.Amount,
+		}
+	}
+	for _, e := range tx.Entries {
+		dir := v1.EntryDirection_ENTRY_DIRECTION_UNSPECIFIED
+		switch e.Direction {
+		case ledger.Debit:
+			dir = v1.EntryDirection_ENTRY_DIRECTION_DEBIT
+		case ledger.Credit:
+			dir = v1.EntryDirection_ENTRY_DIRECTION_CREDIT
+		}
+		out.Entries = append(out.Entries, &v1.Entry{
+			AccountId: e.AccountID,
+			Direction: dir,
+			Currency:  e.Currency,
+			Amount:    e.Amount,
+		})
+	}
+	return out
+}
+
+func toProtoReversalStatus(st ledger.ReversalStatus) v1.ReversalStatus {
+	switch st {
+	case ledger.PartiallyReversed:
+		return v1.ReversalStatus_REVERSAL_STATUS_PARTIALLY_REVERSED
+	case ledger.Reversed:
+		return v1.ReversalStatus_REVERSAL_STATUS_REVERSED
+	}
+	return v1.ReversalStatus_REVERSAL_STATUS_NOT_REVERSED
+}
+
+func toProtoHold(h ledger.Hold) *v1.Hold {
+	return &v1.Hold{
+		Id:             h.ID,
+		CreatedAt:      timestamppb.New(h.CreatedAt),
+		ExpiresAt:      timestamppb.New(h.ExpiresAt),
+		FromAccountId:  h.FromAccountID,
+		ToAccountId:    h.ToAccountID,
+		Currency:       h.Currency,
+		Amount:         h.Amount,
+		CapturedAmount: h.CapturedAmount,
+		Status:         v1.HoldStatus(v1.HoldStatus_value["HOLD_STATUS_"+strings.ToUpper(string(h.Status))]),
+		TransactionId:  h.TransactionID,
+		IdempotencyKey: h.IdempotencyKey,
+	}
+}
+
+func fromProtoDirection(d v1.EntryDirection) ledger.Direction {
+	switch d {
+	case v1.EntryDirection_ENTRY_DIRECTION_DEBIT:
+		return ledger.Debit
+	case v1.EntryDirection_ENTRY_DIRECTION_CREDIT:
+		return ledger.Credit
+	}
+	return ""
+}
+
+func fromProtoEntry(e *v1.Entry) ledger.Entry {
+	return ledger.Entry{
+		AccountID: e.GetAccountId(),
+		Direction: fromProtoDirection(e.GetDirection()),
+		Currency:  strings.TrimSpace(e.GetCurrency()),
+		Amount:    e.GetAmount(),
+	}
+}
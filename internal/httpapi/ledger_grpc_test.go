@@ -0,0 +1,945 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "qazna.org/api/gen/go/api/proto/qazna/v1"
+	"qazna.org/internal/audit"
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/ledger/remote"
+	"qazna.org/internal/screening"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// ledgerOperator is the caller of LedgerService calls made without an
+// identity of their own in the tests.
+const ledgerOperator = "ledger-operator"
+
+// ledgerRBAC authorizes LedgerService callers through store, granting every
+// user all ledger permissions across organizations unless store says
+// otherwise.
+func ledgerRBAC(t *testing.T, store *stubRBACStore) *auth.RBACService {
+	t.Helper()
+	if store.userPermissionsFn == nil {
+		store.userPermissionsFn = func(context.Context, string) ([]string, error) {
+			return []string{auth.PermissionLedgerRead, auth.PermissionLedgerTransfer, auth.PermissionLedgerAccountCreate,
+				auth.PermissionLedgerAccountStatus, auth.PermissionLedgerReverse, auth.PermissionLedgerCrossOrg}, nil
+		}
+	}
+	rbac, err := auth.NewRBACService(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rbac
+}
+
+// asLedgerCaller returns ctx as the server sees a call from userID scoped to
+// orgID, or unscoped when orgID is empty.
+func asLedgerCaller(ctx context.Context, userID, orgID string) context.Context {
+	md := metadata.Pairs("x-qazna-user-id", userID)
+	if orgID != "" {
+		md.Set("x-qazna-org-scope", orgID)
+	}
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+// withLedgerOperator sends calls without a caller identity as ledgerOperator.
+func withLedgerOperator(ctx context.Context) context.Context {
+	if md, _ := metadata.FromOutgoingContext(ctx); len(md.Get("x-qazna-user-id")) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "x-qazna-user-id", ledgerOperator)
+}
+
+func startLedgerGRPC(t *testing.T, svc ledger.Service, opts ...LedgerGRPCOption) (*remote.Client, *grpc.ClientConn, func()) {
+	t.Helper()
+
+	listener := bufconn.Listen(bufSize)
+	server := grpc.NewServer()
+	opts = append([]LedgerGRPCOption{WithLedgerInitialFunding(), WithLedgerRBAC(ledgerRBAC(t, &stubRBACStore{}))}, opts...)
+	v1.RegisterLedgerServiceServer(server, NewLedgerGRPCServer(svc, opts...))
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			t.Logf("grpc serve error: %v", err)
+		}
+	}()
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.Dial()
+	}
+	dialOpts := []grpc.DialOption{
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(withLedgerOperator(ctx), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(withLedgerOperator(ctx), desc, cc, method, opts...)
+		}),
+	}
+	client, err := remote.Dial(context.Background(), "passthrough:///bufnet", dialOpts...)
+	if err != nil {
+		t.Fatalf("dial bufnet: %v", err)
+	}
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	if err != nil {
+		t.Fatalf("dial bufnet: %v", err)
+	}
+
+	cleanup := func() {
+		server.GracefulStop()
+		_ = client.Close()
+		_ = conn.Close()
+		_ = listener.Close()
+	}
+	return client, conn, cleanup
+}
+
+func TestLedgerGRPCServer_RoundTrip(t *testing.T) {
+	client, _, cleanup := startLedgerGRPC(t, ledger.NewInMemory())
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	svc := remote.NewService(client)
+	a, err := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 1000})
+	if err != nil {
+		t.Fatalf("create account a: %v", err)
+	}
+	b, err := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+	if err != nil {
+		t.Fatalf("create account b: %v", err)
+	}
+
+	tx, err := svc.Transfer(ctx, a.ID, b.ID, ledger.Money{Currency: "QZN", Amount: 250}, "idem-1")
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if tx.Sequence != 1 || tx.Amount != 250 || tx.IdempotencyKey != "idem-1" {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+
+	again, err := svc.Transfer(ctx, a.ID, b.ID, ledger.Money{Currency: "QZN", Amount: 250}, "idem-1")
+	if err != nil {
+		t.Fatalf("idempotent transfer: %v", err)
+	}
+	if again.ID != tx.ID {
+		t.Fatalf("expected idempotent replay, got %s vs %s", again.ID, tx.ID)
+	}
+
+	bal, err := svc.GetBalance(ctx, b.ID, "QZN")
+	if err != nil {
+		t.Fatalf("get balance: %v", err)
+	}
+	if bal.Amount != 250 {
+		t.Fatalf("expected balance 250, got %d", bal.Amount)
+	}
+
+	acc, err := svc.GetAccount(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("get account: %v", err)
+	}
+	if acc.Balances["QZN"] != 750 || acc.CreatedAt.IsZero() {
+		t.Fatalf("unexpected account: %+v", acc)
+	}
+
+	items, next, err := svc.ListTransactions(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("list transactions: %v", err)
+	}
+	if len(items) != 1 || next != 1 || items[0].ID != tx.ID {
+		t.Fatalf("unexpected list: %+v next=%d", items, next)
+	}
+}
+
+// Without initial funding, accounts only receive money through mints, so
+// supply has no opening balance to account for.
+func TestLedgerGRPCServer_InitialFunding(t *testing.T) {
+	ctx := context.Background()
+	mem := ledger.NewInMemory()
+	rbac := WithLedgerRBAC(ledgerRBAC(t, &stubRBACStore{}))
+	srv := NewLedgerGRPCServer(mem, rbac)
+	call := asLedgerCaller(ctx, ledgerOperator, "")
+	_, err := srv.CreateAccount(call, &v1.CreateAccountRequest{Currency: "QZN", InitialAmount: 500})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+	if _, err := srv.CreateAccount(call, &v1.CreateAccountRequest{Currency: "QZN"}); err != nil {
+		t.Fatal(err)
+	}
+	supply, err := mem.Supply(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range supply {
+		if s.Opening != 0 || s.Outstanding != s.Issued {
+			t.Fatalf("money outside issuance: %+v", s)
+		}
+	}
+
+	funded := NewLedgerGRPCServer(mem, rbac, WithLedgerInitialFunding())
+	if _, err := funded.CreateAccount(call, &v1.CreateAccountRequest{Currency: "QZN", InitialAmount: 500}); err != nil {
+		t.Fatal(err)
+	}
+	if supply, _ := mem.Supply(ctx); len(supply) != 1 || supply[0].Opening != 500 {
+		t.Fatalf("expected the legacy funding reported as opening, got %+v", supply)
+	}
+}
+
+func TestLedgerGRPCServer_Authorization(t *testing.T) {
+	ctx := context.Background()
+	mem := ledger.NewInMemory()
+	grants := map[string][]string{
+		"reader":   {auth.PermissionLedgerRead},
+		"teller":   {auth.PermissionLedgerRead, auth.PermissionLedgerTransfer},
+		"operator": {auth.PermissionLedgerRead, auth.PermissionLedgerTransfer, auth.PermissionLedgerCrossOrg},
+	}
+	srv := NewLedgerGRPCServer(mem, WithLedgerRBAC(ledgerRBAC(t, &stubRBACStore{
+		userPermissionsFn: func(_ context.Context, userID string) ([]string, error) {
+			return grants[userID], nil
+		},
+		getUserFn: func(_ context.Context, orgID, userID string) (auth.User, error) {
+			if orgID != "org-a" {
+				return auth.User{}, auth.ErrNotFound
+			}
+			return auth.User{ID: userID, OrganizationID: orgID}, nil
+		},
+	})))
+	a, _ := mem.CreateAccount(ledger.WithOrganizationScope(ctx, "org-a"), ledger.Money{Currency: "QZN", Amount: 100})
+	b, _ := mem.CreateAccount(ctx, ledger.Money{Currency: "QZN"})
+	transfer := &v1.TransferRequest{FromId: a.ID, ToId: b.ID, Currency: "QZN", Amount: 10}
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+		call func(context.Context) error
+		want codes.Code
+	}{
+		{"read role reads", asLedgerCaller(ctx, "reader", "org-a"), func(ctx context.Context) error {
+			_, err := srv.GetAccount(ctx, &v1.GetAccountRequest{Id: a.ID})
+			return err
+		}, codes.OK},
+		{"read role cannot transfer", asLedgerCaller(ctx, "reader", "org-a"), func(ctx context.Context) error {
+			_, err := srv.Transfer(ctx, transfer)
+			return err
+		}, codes.PermissionDenied},
+		{"read role cannot void", asLedgerCaller(ctx, "reader", "org-a"), func(ctx context.Context) error {
+			_, err := srv.VoidHold(ctx, &v1.VoidHoldRequest{Id: "missing"})
+			return err
+		}, codes.PermissionDenied},
+		{"transfer role cannot reverse", asLedgerCaller(ctx, "teller", "org-a"), func(ctx context.Context) error {
+			_, err := srv.Reverse(ctx, &v1.ReverseRequest{TransactionId: "missing", Reason: "duplicate"})
+			return err
+		}, codes.PermissionDenied},
+		{"unscoped call needs cross_org", asLedgerCaller(ctx, "teller", ""), func(ctx context.Context) error {
+			_, err := srv.GetAccount(ctx, &v1.GetAccountRequest{Id: a.ID})
+			return err
+		}, codes.PermissionDenied},
+		{"scope of another organization", asLedgerCaller(ctx, "teller", "org-b"), func(ctx context.Context) error {
+			_, err := srv.GetAccount(ctx, &v1.GetAccountRequest{Id: b.ID})
+			return err
+		}, codes.PermissionDenied},
+		{"anonymous caller", ctx, func(ctx context.Context) error {
+			_, err := srv.GetAccount(ctx, &v1.GetAccountRequest{Id: a.ID})
+			return err
+		}, codes.Unauthenticated},
+		{"transfer role transfers", asLedgerCaller(ctx, "teller", "org-a"), func(ctx context.Context) error {
+			_, err := srv.Transfer(ctx, transfer)
+			return err
+		}, codes.OK},
+		{"cross_org operator acts unscoped", asLedgerCaller(ctx, "operator", ""), func(ctx context.Context) error {
+			_, err := srv.Transfer(ctx, transfer)
+			return err
+		}, codes.OK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(tc.ctx); status.Code(err) != tc.want {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+	if bal, _ := mem.GetBalance(ctx, b.ID, "QZN"); bal.Amount != 20 {
+		t.Fatalf("expected only the authorized transfers to post, got %+v", bal)
+	}
+
+	if _, err := NewLedgerGRPCServer(mem).GetAccount(asLedgerCaller(ctx, "operator", ""), &v1.GetAccountRequest{Id: a.ID}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable without RBAC, got %v", err)
+	}
+}
+
+func TestLedgerGRPCServer_ErrorMapping(t *testing.T) {
+	client, conn, cleanup := startLedgerGRPC(t, ledger.NewInMemory())
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	svc := remote.NewService(client)
+	a, err := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 10})
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{
+			name: "not found",
+			call: func() error {
+				_, err := svc.GetAccount(ctx, "missing")
+				return err
+			},
+			want: ledger.ErrNotFound,
+		},
+		{
+			name: "insufficient funds",
+			call: func() error {
+				_, err := svc.Transfer(ctx, a.ID, a.ID, ledger.Money{Currency: "QZN", Amount: 100}, "")
+				return err
+			},
+			want: ledger.ErrInsufficientFunds,
+		},
+		{
+			name: "invalid amount",
+			call: func() error {
+				_, err := svc.Transfer(ctx, a.ID, a.ID, ledger.Money{Currency: "QZN", Amount: 0}, "")
+				return err
+			},
+			want: ledger.ErrInvalidAmount,
+		},
+		{
+			name: "invalid currency",
+			call: func() error {
+				_, err := svc.CreateAccount(ctx, ledger.Money{Amount: 1})
+				return err
+			},
+			want: ledger.ErrInvalidCurrency,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	_, err = v1.NewLedgerServiceClient(conn).GetAccount(ctx, &v1.GetAccountRequest{Id: "missing"})
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.NotFound {
+		t.Fatalf("unexpected status: %v", err)
+	}
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if v, ok := d.(*errdetails.ErrorInfo); ok {
+			info = v
+		}
+	}
+	if info == nil || info.GetReason() != "NOT_FOUND" || info.GetDomain() != ledgerErrorDomain {
+		t.Fatalf("unexpected error details: %+v", st.Details())
+	}
+}
+
+type identityRecorder struct {
+	ledger.Service
+	userID string
+	roles  []string
+}
+
+func (r *identityRecorder) GetAccount(ctx context.Context, id string) (ledger.Account, error) {
+	r.userID, _ = auth.UserIDFromContext(ctx)
+	r.roles = auth.RolesFromContext(ctx)
+	return r.Service.GetAccount(ctx, id)
+}
+
+func TestLedgerGRPCServer_PropagatesIdentity(t *testing.T) {
+	mem := ledger.NewInMemory()
+	rec := &identityRecorder{Service: mem}
+	client, _, cleanup := startLedgerGRPC(t, rec)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	acc, err := mem.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 1})
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	callCtx := auth.ContextWithUser(ctx, "user-42", []string{"admin", "auditor"})
+	if _, err := remote.NewService(client).GetAccount(callCtx, acc.ID); err != nil {
+		t.Fatalf("get account: %v", err)
+	}
+	if rec.userID != "user-42" {
+		t.Fatalf("expected user id propagated, got %q", rec.userID)
+	}
+	if len(rec.roles) != 2 || rec.roles[0] != "admin" || rec.roles[1] != "auditor" {
+		t.Fatalf("unexpected roles: %v", rec.roles)
+	}
+}
+
+func TestLedgerGRPCServer_PostEntries(t *testing.T) {
+	client, _, cleanup := startLedgerGRPC(t, ledger.NewInMemory())
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	svc := remote.NewService(client)
+	a, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 500})
+	b, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+	c, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+
+	entries := []ledger.Entry{
+		{AccountID: a.ID, Direction: ledger.Debit, Currency: "QZN", Amount: 300},
+		{AccountID: b.ID, Direction: ledger.Credit, Currency: "QZN", Amount: 100},
+		{AccountID: c.ID, Direction: ledger.Credit, Currency: "QZN", Amount: 200},
+	}
+	tx, err := svc.PostEntries(ctx, entries, "batch-1")
+	if err != nil {
+		t.Fatalf("post entries: %v", err)
+	}
+	if tx.Sequence != 1 || len(tx.Entries) != 3 || tx.Entries[0].Direction != ledger.Debit || tx.Entries[2].Amount != 200 {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+
+	bal, err := svc.GetBalance(ctx, c.ID, "QZN")
+	if err != nil || bal.Amount != 200 {
+		t.Fatalf("unexpected balance: %+v err=%v", bal, err)
+	}
+
+	_, err = svc.PostEntries(ctx, entries[:2], "")
+	if !errors.Is(err, ledger.ErrUnbalanced) {
+		t.Fatalf("expected ErrUnbalanced, got %v", err)
+	}
+}
+
+func TestLedgerGRPCServer_PropagatesOrganizationScope(t *testing.T) {
+	client, _, cleanup := startLedgerGRPC(t, ledger.NewInMemory())
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	svc := remote.NewService(client)
+	bankA := ledger.WithOrganizationScope(ctx, "org-a")
+	bankB := ledger.WithOrganizationScope(ctx, "org-b")
+
+	acc, err := svc.CreateAccount(bankA, ledger.Money{Currency: "QZN", Amount: 100})
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	if acc.OrganizationID != "org-a" {
+		t.Fatalf("expected owner org-a, got %q", acc.OrganizationID)
+	}
+	if _, err := svc.GetAccount(bankB, acc.ID); !errors.Is(err, ledger.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound across organizations, got %v", err)
+	}
+	if _, err := svc.GetAccount(ctx, acc.ID); err != nil {
+		t.Fatalf("unscoped caller should see the account: %v", err)
+	}
+}
+
+func TestLedgerGRPCServer_AccountLifecycle(t *testing.T) {
+	client, _, cleanup := startLedgerGRPC(t, ledger.NewInMemory())
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	svc := remote.NewService(client)
+	acc, err := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 50},
+		ledger.WithAccountType(ledger.AccountTypeSuspense), ledger.WithDisplayName("Unmatched inbound"))
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	if acc.Type != ledger.AccountTypeSuspense || acc.DisplayName != "Unmatched inbound" || acc.Status != ledger.AccountActive {
+		t.Fatalf("unexpected account: %+v", acc)
+	}
+	other, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN"})
+
+	frozen, err := svc.SetAccountStatus(ctx, acc.ID, ledger.AccountFrozen)
+	if err != nil || frozen.Status != ledger.AccountFrozen {
+		t.Fatalf("freeze: %+v %v", frozen, err)
+	}
+	if _, err := svc.Transfer(ctx, acc.ID, other.ID, ledger.Money{Currency: "QZN", Amount: 10}, ""); !errors.Is(err, ledger.ErrAccountFrozen) {
+		t.Fatalf("expected ErrAccountFrozen, got %v", err)
+	}
+	if _, err := svc.SetAccountStatus(ctx, acc.ID, ledger.AccountClosed); !errors.Is(err, ledger.ErrAccountNotEmpty) {
+		t.Fatalf("expected ErrAccountNotEmpty, got %v", err)
+	}
+}
+
+func TestLedgerGRPCServer_ListAccountTransactions(t *testing.T) {
+	client, _, cleanup := startLedgerGRPC(t, ledger.NewInMemory())
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	svc := remote.NewService(client)
+	a, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 100})
+	b, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 100})
+	if _, err := svc.Transfer(ctx, a.ID, b.ID, ledger.Money{Currency: "QZN", Amount: 10}, ""); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	in, err := svc.Transfer(ctx, b.ID, a.ID, ledger.Money{Currency: "QZN", Amount: 5}, "")
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	items, next, err := svc.ListAccountTransactions(ctx, a.ID, ledger.TransactionFilter{
+		Direction: ledger.Credit,
+		From:      time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != in.ID || next != in.Sequence {
+		t.Fatalf("unexpected credits: %+v next=%d", items, next)
+	}
+	if _, _, err := svc.ListAccountTransactions(ctx, "missing", ledger.TransactionFilter{}); !errors.Is(err, ledger.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLedgerGRPCServer_GetBalanceAt(t *testing.T) {
+	client, _, cleanup := startLedgerGRPC(t, ledger.NewInMemory())
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	svc := remote.NewService(client)
+	a, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 100})
+	b, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+	first, err := svc.Transfer(ctx, a.ID, b.ID, ledger.Money{Currency: "QZN", Amount: 10}, "")
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if _, err := svc.Transfer(ctx, a.ID, b.ID, ledger.Money{Currency: "QZN", Amount: 20}, ""); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	bal, err := svc.GetBalanceAt(ctx, a.ID, "QZN", ledger.BalancePoint{Sequence: first.Sequence})
+	if err != nil {
+		t.Fatalf("balance at sequence: %v", err)
+	}
+	if bal.Amount != 90 || bal.Sequence != first.Sequence {
+		t.Fatalf("unexpected balance: %+v", bal)
+	}
+	bal, err = svc.GetBalanceAt(ctx, b.ID, "QZN", ledger.BalancePoint{Time: time.Now().Add(time.Minute)})
+	if err != nil {
+		t.Fatalf("balance at time: %v", err)
+	}
+	if bal.Amount != 30 {
+		t.Fatalf("unexpected balance: %+v", bal)
+	}
+	if _, err := svc.GetBalanceAt(ctx, a.ID, "QZN", ledger.BalancePoint{}); !errors.Is(err, ledger.ErrInvalidBalancePoint) {
+		t.Fatalf("expected ErrInvalidBalancePoint, got %v", err)
+	}
+}
+
+func TestLedgerGRPCServer_Reverse(t *testing.T) {
+	client, _, cleanup := startLedgerGRPC(t, ledger.NewInMemory())
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	svc := remote.NewService(client)
+	a, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 100})
+	b, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+	orig, err := svc.Transfer(ctx, a.ID, b.ID, ledger.Money{Currency: "QZN", Amount: 40}, "")
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	rev, err := svc.Reverse(ctx, orig.ID, 15, "duplicate payment", "")
+	if err != nil {
+		t.Fatalf("reverse: %v", err)
+	}
+	if rev.ReversalOf != orig.ID || rev.ReversalReason != "duplicate payment" || rev.Amount != 15 {
+		t.Fatalf("unexpected reversal: %+v", rev)
+	}
+	items, _, err := svc.ListAccountTransactions(ctx, a.ID, ledger.TransactionFilter{Limit: 1})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if items[0].ReversalStatus != ledger.PartiallyReversed || items[0].ReversedAmount != 15 {
+		t.Fatalf("unexpected original: %+v", items[0])
+	}
+	if _, err := svc.Reverse(ctx, orig.ID, 50, "duplicate payment", ""); !errors.Is(err, ledger.ErrReversalExceedsOriginal) {
+		t.Fatalf("expected ErrReversalExceedsOriginal, got %v", err)
+	}
+	if _, err := svc.Reverse(ctx, rev.ID, 0, "undo", ""); !errors.Is(err, ledger.ErrNotReversible) {
+		t.Fatalf("expected ErrNotReversible, got %v", err)
+	}
+	if _, err := svc.Reverse(ctx, orig.ID, 0, " ", ""); !errors.Is(err, ledger.ErrInvalidReversalReason) {
+		t.Fatalf("expected ErrInvalidReversalReason, got %v", err)
+	}
+}
+
+func TestLedgerGRPCServer_Holds(t *testing.T) {
+	client, _, cleanup := startLedgerGRPC(t, ledger.NewInMemory())
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	svc := remote.NewService(client)
+	a, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 100})
+	b, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+
+	h, err := svc.CreateHold(ctx, a.ID, b.ID, ledger.Money{Currency: "QZN", Amount: 60}, time.Hour, "h-1")
+	if err != nil {
+		t.Fatalf("create hold: %v", err)
+	}
+	if h.Status != ledger.HoldPending || h.IdempotencyKey != "h-1" || h.ExpiresAt.Sub(h.CreatedAt) != time.Hour {
+		t.Fatalf("unexpected hold: %+v", h)
+	}
+	bal, err := svc.GetBalance(ctx, a.ID, "QZN")
+	if err != nil {
+		t.Fatalf("balance: %v", err)
+	}
+	if bal.Amount != 100 || bal.Held != 60 || bal.Available != 40 {
+		t.Fatalf("unexpected balance: %+v", bal)
+	}
+	if _, _, err := svc.CaptureHold(ctx, h.ID, 61); !errors.Is(err, ledger.ErrCaptureExceedsHold) {
+		t.Fatalf("expected ErrCaptureExceedsHold, got %v", err)
+	}
+	captured, tx, err := svc.CaptureHold(ctx, h.ID, 0)
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if captured.Status != ledger.HoldCaptured || captured.CapturedAmount != 60 || tx.Amount != 60 || tx.ToAccountID != b.ID {
+		t.Fatalf("unexpected capture: %+v %+v", captured, tx)
+	}
+	if _, err := svc.VoidHold(ctx, h.ID); !errors.Is(err, ledger.ErrHoldNotPending) {
+		t.Fatalf("expected ErrHoldNotPending, got %v", err)
+	}
+	if got, err := svc.GetHold(ctx, h.ID); err != nil || got.TransactionID != tx.ID {
+		t.Fatalf("get hold: %+v %v", got, err)
+	}
+	if _, err := svc.CreateHold(ctx, a.ID, b.ID, ledger.Money{Currency: "QZN", Amount: 1}, ledger.MaxHoldTTL+time.Hour, ""); !errors.Is(err, ledger.ErrInvalidHoldTTL) {
+		t.Fatalf("expected ErrInvalidHoldTTL, got %v", err)
+	}
+}
+
+func TestLedgerGRPCServer_Fees(t *testing.T) {
+	mem := ledger.NewInMemory()
+	rbac := ledgerRBAC(t, &stubRBACStore{
+		getOrgFn: func(_ context.Context, id string) (auth.Organization, error) {
+			if id == "org-corp" {
+				return auth.Organization{ID: id, ParticipantType: auth.ParticipantCorporate}, nil
+			}
+			return auth.Organization{}, auth.ErrNotFound
+		},
+	})
+	client, _, cleanup := startLedgerGRPC(t, mem, WithLedgerRBAC(rbac))
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	corp, _ := mem.CreateAccount(ledger.WithOrganizationScope(ctx, "org-corp"), ledger.Money{Currency: "QZN", Amount: 1_000_000})
+	payee, _ := mem.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+	feeAcct, _ := mem.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0}, ledger.WithAccountType(ledger.AccountTypeFee))
+	if _, err := mem.AddFeeSchedule(ctx, ledger.FeeSchedule{Rates: map[string]string{"corporate": "0.001"}, Alpha: "1", Beta: "1", AccountID: feeAcct.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := remote.NewService(client)
+	tx, err := svc.Transfer(ctx, corp.ID, payee.ID, ledger.Money{Currency: "QZN", Amount: 100_000}, "fee-1")
+	if err != nil || len(tx.Entries) != 4 {
+		t.Fatalf("unexpected transfer: %+v %v", tx, err)
+	}
+	h, err := svc.CreateHold(ctx, corp.ID, payee.ID, ledger.Money{Currency: "QZN", Amount: 50_000}, time.Hour, "")
+	if err != nil {
+		t.Fatalf("create hold: %v", err)
+	}
+	if _, tx, err := svc.CaptureHold(ctx, h.ID, 0); err != nil || len(tx.Entries) != 4 {
+		t.Fatalf("unexpected capture: %+v %v", tx, err)
+	}
+
+	// Payers without an organization pay nothing.
+	if tx, err := svc.Transfer(ctx, payee.ID, corp.ID, ledger.Money{Currency: "QZN", Amount: 1000}, ""); err != nil || len(tx.Entries) != 0 {
+		t.Fatalf("unexpected exempt transfer: %+v %v", tx, err)
+	}
+	if bal, err := mem.GetBalance(ctx, feeAcct.ID, "QZN"); err != nil || bal.Amount != 150 {
+		t.Fatalf("expected fees of 100 and 50 collected, got %+v %v", bal, err)
+	}
+	if bal, err := mem.GetBalance(ctx, corp.ID, "QZN"); err != nil || bal.Amount != 1_000_000-150_000-150+1000 {
+		t.Fatalf("unexpected payer balance: %+v %v", bal, err)
+	}
+}
+
+func TestLedgerGRPCServer_Screening(t *testing.T) {
+	sink := audit.NewMemorySink()
+	audit.SetSink(sink)
+	t.Cleanup(func() { audit.SetSink(nil) })
+
+	orgs := map[string]auth.Organization{
+		"org-ok":   {ID: "org-ok", Name: "Almaty Bakery"},
+		"org-hit":  {ID: "org-hit", Name: "Borealis Shipping Trading Ltd"},
+		"org-near": {ID: "org-near", Name: "Caspian Logistics", Metadata: map[string]any{"beneficial_owners": []any{"Sergei Ivanov"}}},
+	}
+	rbac := ledgerRBAC(t, &stubRBACStore{
+		getOrgFn: func(_ context.Context, id string) (auth.Organization, error) {
+			if org, ok := orgs[id]; ok {
+				return org, nil
+			}
+			return auth.Organization{}, auth.ErrNotFound
+		},
+	})
+	screener, err := screening.NewSanctionsScreener([]screening.SanctionsEntry{
+		{ID: "SL-1", Name: "Borealis Shipping Trading LLC", Program: "UN-1718"},
+		{ID: "SL-2", Name: "Ivanov Sergei Petrovich", Program: "EU-833"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reviews := screening.NewMemoryQueue()
+	mem := ledger.NewInMemory()
+	_, conn, cleanup := startLedgerGRPC(t, mem, WithLedgerRBAC(rbac), WithLedgerScreening(screener, reviews))
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	from, _ := mem.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 1_000_000})
+	payees := map[string]ledger.Account{}
+	for id := range orgs {
+		payees[id], _ = mem.CreateAccount(ledger.WithOrganizationScope(ctx, id), ledger.Money{Currency: "QZN", Amount: 0})
+	}
+	usdHit, _ := mem.CreateAccount(ledger.WithOrganizationScope(ctx, "org-hit"), ledger.Money{Currency: "USD", Amount: 0})
+	qznLiq, _ := mem.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+	usdLiq, _ := mem.CreateAccount(ctx, ledger.Money{Currency: "USD", Amount: 50_000})
+	if _, err := mem.AddFXRate(ctx, ledger.FXRate{Base: "QZN", Quote: "USD", Rate: "0.25"}); err != nil {
+		t.Fatal(err)
+	}
+	for currency, id := range map[string]string{"QZN": qznLiq.ID, "USD": usdLiq.ID} {
+		if err := mem.SetFXLiquidityAccount(ctx, currency, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := v1.NewLedgerServiceClient(conn)
+	expect := func(name string, err error, code codes.Code, reason string) *errdetails.ErrorInfo {
+		t.Helper()
+		st, _ := status.FromError(err)
+		var info *errdetails.ErrorInfo
+		for _, d := range st.Details() {
+			if v, ok := d.(*errdetails.ErrorInfo); ok {
+				info = v
+			}
+		}
+		if st.Code() != code || info == nil || info.GetReason() != reason {
+			t.Fatalf("%s: expected %v %s, got %v", name, code, reason, err)
+		}
+		return info
+	}
+
+	if _, err := client.Transfer(ctx, &v1.TransferRequest{FromId: from.ID, ToId: payees["org-ok"].ID, Currency: "QZN", Amount: 1000}); err != nil {
+		t.Fatalf("clean transfer: %v", err)
+	}
+	_, err = client.Transfer(ctx, &v1.TransferRequest{FromId: from.ID, ToId: payees["org-hit"].ID, Currency: "QZN", Amount: 1000})
+	expect("listed transfer", err, codes.PermissionDenied, "SCREENING_REJECTED")
+	_, err = client.Transfer(ctx, &v1.TransferRequest{FromId: from.ID, ToId: payees["org-near"].ID, Currency: "QZN", Amount: 1000, IdempotencyKey: "held-1"})
+	info := expect("near transfer", err, codes.FailedPrecondition, "SCREENING_REVIEW_PENDING")
+	if rv, err := reviews.GetReview(ctx, info.GetMetadata()["review_id"]); err != nil || rv.Transfer.IdempotencyKey != "held-1" {
+		t.Fatalf("expected the held transfer to wait for review: %+v %v", rv, err)
+	}
+
+	_, err = client.PostEntries(ctx, &v1.PostEntriesRequest{Entries: []*v1.Entry{
+		{AccountId: from.ID, Direction: v1.EntryDirection_ENTRY_DIRECTION_DEBIT, Currency: "QZN", Amount: 2000},
+		{AccountId: payees["org-ok"].ID, Direction: v1.EntryDirection_ENTRY_DIRECTION_CREDIT, Currency: "QZN", Amount: 1000},
+		{AccountId: payees["org-hit"].ID, Direction: v1.EntryDirection_ENTRY_DIRECTION_CREDIT, Currency: "QZN", Amount: 1000},
+	}})
+	expect("listed batch posting", err, codes.PermissionDenied, "SCREENING_REJECTED")
+	_, err = client.FXTransfer(ctx, &v1.FXTransferRequest{FromId: from.ID, ToId: usdHit.ID, Currency: "QZN", Amount: 1000, TargetCurrency: "USD"})
+	expect("listed fx transfer", err, codes.PermissionDenied, "SCREENING_REJECTED")
+	_, err = client.CreateHold(ctx, &v1.CreateHoldRequest{FromId: from.ID, ToId: payees["org-hit"].ID, Currency: "QZN", Amount: 1000, TtlSeconds: 60})
+	expect("listed hold", err, codes.PermissionDenied, "SCREENING_REJECTED")
+	_, err = client.CreateHold(ctx, &v1.CreateHoldRequest{FromId: from.ID, ToId: payees["org-near"].ID, Currency: "QZN", Amount: 1000, TtlSeconds: 60})
+	expect("near hold", err, codes.PermissionDenied, "SCREENING_HELD")
+
+	// A payee listed after its hold was created cannot be paid by capture.
+	h, err := client.CreateHold(ctx, &v1.CreateHoldRequest{FromId: from.ID, ToId: payees["org-ok"].ID, Currency: "QZN", Amount: 1000, TtlSeconds: 60})
+	if err != nil {
+		t.Fatalf("clean hold: %v", err)
+	}
+	orgs["org-ok"] = auth.Organization{ID: "org-ok", Name: "Borealis Shipping Trading Ltd"}
+	_, err = client.CaptureHold(ctx, &v1.CaptureHoldRequest{Id: h.GetId()})
+	expect("listed capture", err, codes.PermissionDenied, "SCREENING_REJECTED")
+
+	for org, want := range map[string]int64{"org-ok": 1000, "org-hit": 0, "org-near": 0} {
+		if bal, err := mem.GetBalance(ctx, payees[org].ID, "QZN"); err != nil || bal.Amount != want {
+			t.Fatalf("balance of %s: got %+v %v, want %d", org, bal, err, want)
+		}
+	}
+	if bal, err := mem.GetBalance(ctx, usdHit.ID, "USD"); err != nil || bal.Amount != 0 {
+		t.Fatalf("expected the fx transfer not to commit: %+v %v", bal, err)
+	}
+	if events, _ := sink.Query(ctx, audit.Filter{Action: "screening.decision"}); len(events) != 10 {
+		t.Fatalf("expected every screening decision audited, got %d", len(events))
+	}
+}
+
+func TestLedgerGRPCServer_FXTransfer(t *testing.T) {
+	mem := ledger.NewInMemory()
+	client, _, cleanup := startLedgerGRPC(t, mem)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	svc := remote.NewService(client)
+	from, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 1_000})
+	to, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "USD", Amount: 0})
+	qznLiq, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+	usdLiq, _ := svc.CreateAccount(ctx, ledger.Money{Currency: "USD", Amount: 1_000})
+
+	if _, err := svc.FXTransfer(ctx, from.ID, to.ID, ledger.Money{Currency: "QZN", Amount: 100}, "USD", ""); !errors.Is(err, ledger.ErrNoFXRate) {
+		t.Fatalf("expected ErrNoFXRate, got %v", err)
+	}
+	rate, _ := mem.AddFXRate(ctx, ledger.FXRate{Base: "QZN", Quote: "USD", Rate: "1.5"})
+	if _, err := svc.FXTransfer(ctx, from.ID, to.ID, ledger.Money{Currency: "QZN", Amount: 100}, "USD", ""); !errors.Is(err, ledger.ErrNoFXLiquidity) {
+		t.Fatalf("expected ErrNoFXLiquidity, got %v", err)
+	}
+	_ = mem.SetFXLiquidityAccount(ctx, "QZN", qznLiq.ID)
+	_ = mem.SetFXLiquidityAccount(ctx, "USD", usdLiq.ID)
+
+	tx, err := svc.FXTransfer(ctx, from.ID, to.ID, ledger.Money{Currency: "QZN", Amount: 100}, "USD", "fx-1")
+	if err != nil {
+		t.Fatalf("fx transfer: %v", err)
+	}
+	if tx.FXRateID != rate.ID || tx.FXRate != "1.5" || len(tx.Entries) != 4 || tx.IdempotencyKey != "fx-1" {
+		t.Fatalf("unexpected fx transaction: %+v", tx)
+	}
+	if bal, _ := svc.GetBalance(ctx, to.ID, "USD"); bal.Amount != 150 {
+		t.Fatalf("expected 150 USD credited, got %+v", bal)
+	}
+}
+
+func TestLedgerGRPCServer_WatchTransactions(t *testing.T) {
+	ctx := auth.ContextWithUser(context.Background(), ledgerOperator, nil)
+	rbac := ledgerRBAC(t, &stubRBACStore{})
+	mem := ledger.NewInMemory()
+	a, _ := mem.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 1_000})
+	b, _ := mem.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+	usd, _ := mem.CreateAccount(ctx, ledger.Money{Currency: "USD", Amount: 1_000})
+	usd2, _ := mem.CreateAccount(ctx, ledger.Money{Currency: "USD", Amount: 0})
+	transfer := func(from, to, currency string) ledger.Transaction {
+		t.Helper()
+		tx, err := mem.Transfer(ctx, from, to, ledger.Money{Currency: currency, Amount: 1}, "")
+		if err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+		return tx
+	}
+
+	// The server is restarted mid-test on a fresh listener; the client
+	// dials whichever is current.
+	var (
+		mu  sync.Mutex
+		lis *bufconn.Listener
+	)
+	serve := func() *grpc.Server {
+		l := bufconn.Listen(bufSize)
+		srv := grpc.NewServer()
+		v1.RegisterLedgerServiceServer(srv, NewLedgerGRPCServer(mem, WithLedgerRBAC(rbac)))
+		mu.Lock()
+		lis = l
+		mu.Unlock()
+		go func() { _ = srv.Serve(l) }()
+		return srv
+	}
+	srv := serve()
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		mu.Lock()
+		l := lis
+		mu.Unlock()
+		return l.DialContext(ctx)
+	}
+	client, err := remote.Dial(ctx, "passthrough:///bufnet",
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial bufnet: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.WatchTransactions(ctx, 0, ledger.WatchFilter{AccountID: "missing"}, func(ledger.Transaction) error {
+		return nil
+	}); !errors.Is(err, ledger.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for unknown account, got %v", err)
+	}
+
+	history := transfer(a.ID, b.ID, "QZN")
+	transfer(usd.ID, usd2.ID, "USD")
+
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	got := make(chan ledger.Transaction, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- client.WatchTransactions(wctx, 0, ledger.WatchFilter{Currency: "QZN"}, func(tx ledger.Transaction) error {
+			got <- tx
+			return nil
+		})
+	}()
+	expect := func(want ledger.Transaction) {
+		t.Helper()
+		select {
+		case tx := <-got:
+			if tx.ID != want.ID || tx.Sequence != want.Sequence {
+				t.Fatalf("expected sequence %d, got %d", want.Sequence, tx.Sequence)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("sequence %d not delivered", want.Sequence)
+		}
+	}
+
+	expect(history)
+	expect(transfer(a.ID, b.ID, "QZN"))
+
+	srv.Stop()
+	during := transfer(a.ID, b.ID, "QZN")
+	transfer(usd.ID, usd2.ID, "USD")
+	srv = serve()
+	defer srv.Stop()
+	after := transfer(b.ID, a.ID, "QZN")
+
+	expect(during)
+	expect(after)
+	select {
+	case tx := <-got:
+		t.Fatalf("unexpected duplicate or filtered transaction %+v", tx)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package httpapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -9,13 +10,30 @@ import (
 	"strings"
 	"time"
 
+	"qazna.org/internal/auth"
 	"qazna.org/internal/ledger"
 	"qazna.org/internal/stream"
 )
 
 type createAccountRequest struct {
-	Currency      string `json:"currency"`
-	InitialAmount int64  `json:"initial_amount"`
+	Currency       string `json:"currency"`
+	InitialAmount  int64  `json:"initial_amount"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	Type           string `json:"type,omitempty"`
+	DisplayName    string `json:"display_name,omitempty"`
+	ExternalRef    string `json:"external_ref,omitempty"`
+}
+
+type accountStatusRequest struct {
+	Reason string `json:"reason"`
+}
+
+// accountStatusActions maps the lifecycle sub-resources of an account to
+// the status they set.
+var accountStatusActions = map[string]ledger.AccountStatus{
+	"freeze":   ledger.AccountFrozen,
+	"unfreeze": ledger.AccountActive,
+	"close":    ledger.AccountClosed,
 }
 
 type transferRequest struct {
@@ -26,15 +44,24 @@ type transferRequest struct {
 	IdempotencyKey string `json:"idempotency_key"`
 }
 
+type reverseRequest struct {
+	Amount         int64  `json:"amount"` // 0 reverses the remainder
+	Reason         string `json:"reason"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
 type listTransactionsResponse struct {
-	Items     []ledger.Transaction `json:"items"`
-	NextAfter uint64               `json:"next_after"`
-	AsOf      time.Time            `json:"as_of"`
+	Items     []transactionView `json:"items"`
+	NextAfter uint64            `json:"next_after"`
+	AsOf      time.Time         `json:"as_of"`
 }
 
 func (a *API) handleAccountsCollection(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerAccountCreate) {
+			return
+		}
 		a.createAccount(w, r)
 	default:
 		methodNotAllowed(w, r, http.MethodPost)
@@ -55,17 +82,59 @@ func (a *API) handleAccountResource(w http.ResponseWriter, r *http.Request) {
 			writeError(w, r, http.StatusNotFound, "account not found")
 			return
 		}
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) {
+			return
+		}
 		a.getBalance(w, r, id)
 		return
 	}
 
-	if strings.Contains(path, "/") {
-		writeError(w, r, http.StatusNotFound, "resource not found")
+	if id, action, ok := strings.Cut(path, "/"); ok && action == "transactions" && id != "" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) {
+			return
+		}
+		a.listAccountTransactions(w, r, id)
+		return
+	}
+
+	if id, action, ok := strings.Cut(path, "/"); ok && action == "statements" && id != "" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) {
+			return
+		}
+		a.getStatement(w, r, id)
+		return
+	}
+
+	if id, action, ok := strings.Cut(path, "/"); ok {
+		status, known := accountStatusActions[action]
+		if !known || id == "" {
+			writeError(w, r, http.StatusNotFound, "resource not found")
+			return
+		}
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerAccountStatus) {
+			return
+		}
+		a.setAccountStatus(w, r, id, action, status)
 		return
 	}
 
 	switch r.Method {
 	case http.MethodGet:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) {
+			return
+		}
 		a.getAccount(w, r, path)
 	default:
 		methodNotAllowed(w, r, http.MethodGet)
@@ -75,6 +144,9 @@ func (a *API) handleAccountResource(w http.ResponseWriter, r *http.Request) {
 func (a *API) handleTransfers(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerTransfer) {
+			return
+		}
 		a.transfer(w, r)
 	default:
 		methodNotAllowed(w, r, http.MethodPost)
@@ -84,12 +156,32 @@ func (a *API) handleTransfers(w http.ResponseWriter, r *http.Request) {
 func (a *API) handleTransactions(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerRead) {
+			return
+		}
 		a.listTransactions(w, r)
 	default:
 		methodNotAllowed(w, r, http.MethodGet)
 	}
 }
 
+func (a *API) handleTransactionResource(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1/ledger/transactions/")
+	id, action, ok := strings.Cut(path, "/")
+	if !ok || action != "reverse" || id == "" {
+		writeError(w, r, http.StatusNotFound, "resource not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionLedgerReverse) {
+		return
+	}
+	a.reverse(w, r, id)
+}
+
 func (a *API) createAccount(w http.ResponseWriter, r *http.Request) {
 	var req createAccountRequest
 	if err := decodeJSON(w, r, &req); err != nil {
@@ -109,32 +201,88 @@ func (a *API) createAccount(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusBadRequest, "initial_amount must be >= 0")
 		return
 	}
+	if req.InitialAmount != 0 && !a.funding {
+		writeError(w, r, http.StatusBadRequest, "initial_amount is disabled; mint into an issuer account and transfer instead")
+		return
+	}
+
+	callerOrg, crossOrg := a.ledgerScope(r)
+	owner := strings.TrimSpace(req.OrganizationID)
+	if owner == "" {
+		owner = callerOrg
+	} else if owner != callerOrg {
+		if !crossOrg {
+			setWWWAuthenticate(w, "insufficient_scope", "missing required permission")
+			writeError(w, r, http.StatusForbidden, "creating accounts for another organization requires "+auth.PermissionLedgerCrossOrg)
+			return
+		}
+		if a.rbac != nil {
+			if _, err := a.rbac.GetOrganization(r.Context(), owner); err != nil {
+				handleRBACError(w, r, err)
+				return
+			}
+		}
+	}
 
-	acc, err := a.ledger.CreateAccount(r.Context(), ledger.Money{
+	acc, err := a.ledger.CreateAccount(ledger.WithOrganizationScope(r.Context(), owner), ledger.Money{
 		Currency: strings.ToUpper(req.Currency),
 		Amount:   req.InitialAmount,
-	})
+	},
+		ledger.WithAccountType(ledger.AccountType(req.Type)),
+		ledger.WithDisplayName(req.DisplayName),
+		ledger.WithExternalRef(req.ExternalRef),
+	)
 	if err != nil {
 		handleLedgerError(w, r, err)
 		return
 	}
 
 	a.audit(r.Context(), "ledger.account.create", "account", acc.ID, map[string]string{
-		"currency":       strings.ToUpper(req.Currency),
-		"initial_amount": strconv.FormatInt(req.InitialAmount, 10),
+		"currency":        strings.ToUpper(req.Currency),
+		"initial_amount":  strconv.FormatInt(req.InitialAmount, 10),
+		"organization_id": acc.OrganizationID,
+		"type":            string(acc.Type),
 	})
 
 	w.Header().Set("Location", "/v1/accounts/"+acc.ID)
-	writeJSON(w, http.StatusCreated, acc)
+	writeJSON(w, http.StatusCreated, a.formatter(r.Context()).account(acc))
 }
 
 func (a *API) getAccount(w http.ResponseWriter, r *http.Request, id string) {
-	acc, err := a.ledger.GetAccount(r.Context(), id)
+	acc, err := a.ledger.GetAccount(a.ledgerContext(r), id)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, a.formatter(r.Context()).account(acc))
+}
+
+func (a *API) setAccountStatus(w http.ResponseWriter, r *http.Request, id, action string, status ledger.AccountStatus) {
+	var req accountStatusRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, &req); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > 512 {
+		writeError(w, r, http.StatusBadRequest, "reason must be <=512 characters")
+		return
+	}
+
+	acc, err := a.ledger.SetAccountStatus(a.ledgerContext(r), id, status)
 	if err != nil {
 		handleLedgerError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, acc)
+
+	meta := map[string]string{"status": string(acc.Status)}
+	if reason != "" {
+		meta["reason"] = reason
+	}
+	a.audit(r.Context(), "ledger.account."+action, "account", acc.ID, meta)
+	writeJSON(w, http.StatusOK, a.formatter(r.Context()).account(acc))
 }
 
 func (a *API) getBalance(w http.ResponseWriter, r *http.Request, id string) {
@@ -143,12 +291,54 @@ func (a *API) getBalance(w http.ResponseWriter, r *http.Request, id string) {
 		writeError(w, r, http.StatusBadRequest, "currency query parameter is required")
 		return
 	}
-	mon, err := a.ledger.GetBalance(r.Context(), id, strings.ToUpper(currency))
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	at, historical, err := parseBalancePoint(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if historical {
+		bal, err := a.ledger.GetBalanceAt(a.ledgerContext(r), id, currency, at)
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, a.formatter(r.Context()).historicalBalance(bal))
+		return
+	}
+	bal, err := a.ledger.GetBalance(a.ledgerContext(r), id, currency)
 	if err != nil {
 		handleLedgerError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, mon)
+	writeJSON(w, http.StatusOK, a.formatter(r.Context()).balance(bal))
+}
+
+// parseBalancePoint reads the optional as_of_sequence / as_of query
+// parameters. ok is false when neither is given.
+func parseBalancePoint(r *http.Request) (at ledger.BalancePoint, ok bool, err error) {
+	q := r.URL.Query()
+	rawSeq := strings.TrimSpace(q.Get("as_of_sequence"))
+	rawTime := strings.TrimSpace(q.Get("as_of"))
+	switch {
+	case rawSeq == "" && rawTime == "":
+		return at, false, nil
+	case rawSeq != "" && rawTime != "":
+		return at, false, errors.New("as_of_sequence and as_of are mutually exclusive")
+	case rawSeq != "":
+		v, err := strconv.ParseUint(rawSeq, 10, 64)
+		if err != nil || v == 0 {
+			return at, false, errors.New("as_of_sequence must be a positive integer")
+		}
+		at.Sequence = v
+	default:
+		t, err := time.Parse(time.RFC3339, rawTime)
+		if err != nil {
+			return at, false, errors.New("as_of must be an RFC3339 timestamp")
+		}
+		at.Time = t
+	}
+	return at, true, nil
 }
 
 func (a *API) transfer(w http.ResponseWriter, r *http.Request) {
@@ -158,18 +348,9 @@ func (a *API) transfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	idem := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
-	if req.IdempotencyKey != "" {
-		bodyKey := strings.TrimSpace(req.IdempotencyKey)
-		if idem == "" {
-			idem = bodyKey
-		} else if idem != bodyKey {
-			writeError(w, r, http.StatusBadRequest, "Idempotency-Key header and body value must match")
-			return
-		}
-	}
-	if len(idem) > 128 {
-		writeError(w, r, http.StatusBadRequest, "Idempotency-Key too long")
+	idem, err := idempotencyKey(r, req.IdempotencyKey)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -197,36 +378,54 @@ func (a *API) transfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	start := time.Now().UTC()
-	tx, err := a.ledger.Transfer(
-		r.Context(),
-		fromID,
-		toID,
-		ledger.Money{
-			Currency: currency,
-			Amount:   req.Amount,
-		},
-		idem,
-	)
+	amt := ledger.Money{
+		Currency: currency,
+		Amount:   req.Amount,
+	}
+	ctx := a.ledgerContext(r)
+	if a.screener != nil && !a.screenTransfer(ctx, w, r, fromID, toID, amt, idem) {
+		return
+	}
+	tx, ok := a.executeTransfer(ctx, w, r, fromID, toID, amt, idem)
+	if !ok {
+		return
+	}
+	if idem != "" {
+		w.Header().Set("Idempotency-Key", idem)
+	}
+	writeJSON(w, http.StatusCreated, a.formatter(r.Context()).transaction(tx))
+}
+
+// executeTransfer commits a transfer with commitTransfer. On failure it
+// writes the error response and reports false.
+func (a *API) executeTransfer(ctx context.Context, w http.ResponseWriter, r *http.Request, fromID, toID string, amt ledger.Money, idem string) (ledger.Transaction, bool) {
+	tx, err := a.commitTransfer(ctx, r, fromID, toID, amt, idem)
 	if err != nil {
 		handleLedgerError(w, r, err)
-		return
+		return ledger.Transaction{}, false
+	}
+	return tx, true
+}
+
+// commitTransfer commits a transfer under ctx, charging the fee when a fee
+// engine is configured, and publishes and audits it.
+func (a *API) commitTransfer(ctx context.Context, r *http.Request, fromID, toID string, amt ledger.Money, idem string) (ledger.Transaction, error) {
+	start := time.Now().UTC()
+	tx, err := a.feeCharger().transfer(ctx, fromID, toID, amt, idem)
+	if err != nil {
+		return ledger.Transaction{}, err
 	}
 	replayed := false
 	if idem != "" && !tx.CreatedAt.After(start) {
 		replayed = true
 	}
 
-	if idem != "" {
-		w.Header().Set("Idempotency-Key", idem)
-	}
-
-	if a.stream != nil {
+	if a.stream != nil && !a.outbox {
 		event := stream.TransferEvent{
 			From:      a.resolveLocation(fromID),
 			To:        a.resolveLocation(toID),
-			Amount:    tx.Amount,
-			Currency:  tx.Currency,
+			Amount:    amt.Amount,
+			Currency:  amt.Currency,
 			Timestamp: time.Now().UTC(),
 		}
 		a.stream.Publish(event)
@@ -235,52 +434,213 @@ func (a *API) transfer(w http.ResponseWriter, r *http.Request) {
 	meta := map[string]string{
 		"from_account": fromID,
 		"to_account":   toID,
-		"currency":     currency,
-		"amount":       strconv.FormatInt(req.Amount, 10),
+		"currency":     amt.Currency,
+		"amount":       strconv.FormatInt(amt.Amount, 10),
 	}
 	if idem != "" {
 		meta["idempotency_key"] = idem
 	}
+	if tx.Fee != nil {
+		meta["fee"] = strconv.FormatInt(tx.Fee.Amount, 10)
+		meta["fee_schedule_version"] = strconv.Itoa(tx.Fee.ScheduleVersion)
+	}
 	event := "ledger.transfer.execute"
 	if replayed {
 		event = "ledger.transfer.idempotent_replay"
 	}
 	a.audit(r.Context(), event, "transaction", tx.ID, meta)
-
-	writeJSON(w, http.StatusCreated, tx)
+	return tx, nil
 }
 
-func (a *API) listTransactions(w http.ResponseWriter, r *http.Request) {
-	limit, err := parsePositiveInt(r.URL.Query().Get("limit"), 100, 1, 1000)
+func (a *API) reverse(w http.ResponseWriter, r *http.Request, txID string) {
+	var req reverseRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	idem, err := idempotencyKey(r, req.IdempotencyKey)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
-	afterParam := strings.TrimSpace(r.URL.Query().Get("after"))
-	var after uint64
-	if afterParam != "" {
-		v, err := strconv.ParseUint(afterParam, 10, 64)
-		if err != nil {
-			writeError(w, r, http.StatusBadRequest, "after must be a non-negative integer")
-			return
+	if req.Amount < 0 {
+		writeError(w, r, http.StatusBadRequest, "amount must be >= 0")
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+
+	start := time.Now().UTC()
+	tx, err := a.ledger.Reverse(a.ledgerContext(r), txID, req.Amount, reason, idem)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	replayed := idem != "" && !tx.CreatedAt.After(start)
+	if idem != "" {
+		w.Header().Set("Idempotency-Key", idem)
+	}
+
+	meta := map[string]string{
+		"original_transaction": txID,
+		"amount":               strconv.FormatInt(tx.Gross(), 10),
+		"reason":               reason,
+	}
+	if tx.Currency != "" {
+		meta["currency"] = tx.Currency
+	}
+	if idem != "" {
+		meta["idempotency_key"] = idem
+	}
+	event := "ledger.transfer.reverse"
+	if replayed {
+		event = "ledger.transfer.reverse.idempotent_replay"
+	}
+	a.audit(r.Context(), event, "transaction", tx.ID, meta)
+
+	writeJSON(w, http.StatusCreated, a.formatter(r.Context()).transaction(tx))
+}
+
+// idempotencyKey merges the Idempotency-Key header with the key given in the
+// request body; when both are present they must agree.
+func idempotencyKey(r *http.Request, bodyKey string) (string, error) {
+	idem := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+	if bodyKey = strings.TrimSpace(bodyKey); bodyKey != "" {
+		if idem == "" {
+			idem = bodyKey
+		} else if idem != bodyKey {
+			return "", errors.New("Idempotency-Key header and body value must match")
 		}
-		after = v
+	}
+	if len(idem) > 128 {
+		return "", errors.New("Idempotency-Key too long")
+	}
+	return idem, nil
+}
+
+func (a *API) listTransactions(w http.ResponseWriter, r *http.Request) {
+	limit, after, err := parseTransactionPage(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	items, next, err := a.ledger.ListTransactions(r.Context(), limit, after)
+	items, next, err := a.ledger.ListTransactions(a.ledgerContext(r), limit, after)
 	if err != nil {
 		handleLedgerError(w, r, err)
 		return
 	}
 
 	resp := listTransactionsResponse{
-		Items:     items,
+		Items:     a.formatter(r.Context()).transactions(items),
 		NextAfter: next,
 		AsOf:      time.Now().UTC(),
 	}
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// parseTransactionPage reads the limit and after query parameters of the
+// journal listings.
+func parseTransactionPage(r *http.Request) (int, uint64, error) {
+	limit, err := parsePositiveInt(r.URL.Query().Get("limit"), 100, 1, 1000)
+	if err != nil {
+		return 0, 0, err
+	}
+	var after uint64
+	if raw := strings.TrimSpace(r.URL.Query().Get("after")); raw != "" {
+		if after, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			return 0, 0, errors.New("after must be a non-negative integer")
+		}
+	}
+	return limit, after, nil
+}
+
+// ledgerScope returns the caller's organization and whether the caller may
+// act across organizations. Without an authenticated user (auth disabled)
+// the deployment is treated as single-tenant and nothing is scoped.
+func (a *API) ledgerScope(r *http.Request) (string, bool) {
+	if _, ok := auth.UserIDFromContext(r.Context()); !ok {
+		return "", true
+	}
+	orgID, _ := auth.OrganizationIDFromContext(r.Context())
+	granted, err := a.callerPermissions(r)
+	if err != nil {
+		return orgID, false
+	}
+	return orgID, hasAllPermissions(granted, []string{auth.PermissionLedgerCrossOrg})
+}
+
+// ledgerContext restricts ledger calls made for r to the caller's
+// organization. Accounts of other organizations then read as not found and
+// cannot be debited; crediting them is still allowed.
+func (a *API) ledgerContext(r *http.Request) context.Context {
+	orgID, crossOrg := a.ledgerScope(r)
+	if crossOrg {
+		return r.Context()
+	}
+	return ledger.WithOrganizationScope(r.Context(), orgID)
+}
+
+func (a *API) listAccountTransactions(w http.ResponseWriter, r *http.Request, id string) {
+	f, err := parseTransactionFilter(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, next, err := a.ledger.ListAccountTransactions(a.ledgerContext(r), id, f)
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listTransactionsResponse{
+		Items:     a.formatter(r.Context()).transactions(items),
+		NextAfter: next,
+		AsOf:      time.Now().UTC(),
+	})
+}
+
+func parseTransactionFilter(r *http.Request) (ledger.TransactionFilter, error) {
+	q := r.URL.Query()
+	limit, err := parsePositiveInt(q.Get("limit"), 100, 1, 1000)
+	if err != nil {
+		return ledger.TransactionFilter{}, err
+	}
+	f := ledger.TransactionFilter{
+		Currency: strings.ToUpper(strings.TrimSpace(q.Get("currency"))),
+		Limit:    limit,
+	}
+	switch dir := ledger.Direction(strings.ToLower(strings.TrimSpace(q.Get("direction")))); dir {
+	case "", ledger.Debit, ledger.Credit:
+		f.Direction = dir
+	default:
+		return ledger.TransactionFilter{}, errors.New("direction must be debit or credit")
+	}
+	if raw := strings.TrimSpace(q.Get("after")); raw != "" {
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return ledger.TransactionFilter{}, errors.New("after must be a non-negative integer")
+		}
+		f.AfterSeq = v
+	}
+	if raw := strings.TrimSpace(q.Get("from")); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return ledger.TransactionFilter{}, errors.New("from must be an RFC3339 timestamp")
+		}
+		f.From = t
+	}
+	if raw := strings.TrimSpace(q.Get("to")); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return ledger.TransactionFilter{}, errors.New("to must be an RFC3339 timestamp")
+		}
+		f.To = t
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return ledger.TransactionFilter{}, errors.New("from must be before to")
+	}
+	return f, nil
+}
+
 func parsePositiveInt(raw string, def, min, max int) (int, error) {
 	if strings.TrimSpace(raw) == "" {
 		return def, nil
@@ -316,15 +676,36 @@ func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
 }
 
 func handleLedgerError(w http.ResponseWriter, r *http.Request, err error) {
+	code := ledgerErrorStatus(err)
+	if code == http.StatusInternalServerError {
+		writeError(w, r, code, "internal error")
+		return
+	}
+	writeError(w, r, code, err.Error())
+}
+
+// ledgerErrorStatus returns the HTTP status of a ledger error: a client
+// error for the ledger's own errors, 500 for anything else.
+func ledgerErrorStatus(err error) int {
 	switch {
-	case errors.Is(err, ledger.ErrInvalidAmount), errors.Is(err, ledger.ErrInvalidCurrency):
-		writeError(w, r, http.StatusBadRequest, err.Error())
-	case errors.Is(err, ledger.ErrInsufficientFunds):
-		writeError(w, r, http.StatusConflict, err.Error())
+	case errors.Is(err, ledger.ErrInvalidAmount), errors.Is(err, ledger.ErrInvalidCurrency), errors.Is(err, ledger.ErrUnbalanced),
+		errors.Is(err, ledger.ErrInvalidAccountType), errors.Is(err, ledger.ErrAccountLabelTooLong), errors.Is(err, ledger.ErrInvalidAccountStatus),
+		errors.Is(err, ledger.ErrInvalidBalancePoint), errors.Is(err, ledger.ErrInvalidReversalReason), errors.Is(err, ledger.ErrInvalidHoldTTL),
+		errors.Is(err, ledger.ErrInvalidFXRate), errors.Is(err, ledger.ErrInvalidFeeSchedule), errors.Is(err, ledger.ErrInvalidLimit):
+		return http.StatusBadRequest
+	case errors.Is(err, ledger.ErrInsufficientFunds),
+		errors.Is(err, ledger.ErrAccountFrozen), errors.Is(err, ledger.ErrAccountClosed), errors.Is(err, ledger.ErrAccountNotEmpty),
+		errors.Is(err, ledger.ErrAlreadyReversed), errors.Is(err, ledger.ErrReversalExceedsOriginal), errors.Is(err, ledger.ErrNotReversible),
+		errors.Is(err, ledger.ErrHoldNotPending), errors.Is(err, ledger.ErrHoldExpired), errors.Is(err, ledger.ErrCaptureExceedsHold),
+		errors.Is(err, ledger.ErrNoFXRate), errors.Is(err, ledger.ErrNoFXLiquidity),
+		errors.Is(err, ledger.ErrNotIssuer), errors.Is(err, ledger.ErrIssuanceAccount):
+		return http.StatusConflict
+	case errors.Is(err, ledger.ErrLimitExceeded):
+		return http.StatusUnprocessableEntity
 	case errors.Is(err, ledger.ErrNotFound):
-		writeError(w, r, http.StatusNotFound, err.Error())
+		return http.StatusNotFound
 	default:
-		writeError(w, r, http.StatusInternalServerError, "internal error")
+		return http.StatusInternalServerError
 	}
 }
 
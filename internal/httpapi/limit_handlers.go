@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ledger"
+)
+
+type limitRequest struct {
+	MaxAmount     int64 `json:"max_amount"`
+	DailyAmount   int64 `json:"daily_amount"`
+	DailyCount    int64 `json:"daily_count"`
+	WindowAmount  int64 `json:"window_amount"`
+	WindowCount   int64 `json:"window_count"`
+	WindowSeconds int64 `json:"window_seconds"`
+}
+
+// handleLimits serves /v1/limits and /v1/limits/{scope}/{subject}/{currency}
+// with its /utilization. Limits expose per-organization activity, so every
+// route needs ledger.limits.manage rather than ledger.read.
+func (a *API) handleLimits(w http.ResponseWriter, r *http.Request) {
+	if !a.ensurePermissions(w, r, auth.PermissionLedgerLimitsManage) || !a.requireLimits(w, r) {
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/limits"), "/")
+	if rest == "" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		q := r.URL.Query()
+		limits, err := a.limits.ListLimits(r.Context(), ledger.LimitKey{
+			Scope:   ledger.LimitScope(q.Get("scope")),
+			Subject: q.Get("subject"),
+		})
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		if limits == nil {
+			limits = []ledger.Limit{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": limits})
+		return
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) < 3 || len(parts) > 4 || (len(parts) == 4 && parts[3] != "utilization") {
+		writeError(w, r, http.StatusNotFound, "not found")
+		return
+	}
+	key, err := ledger.NormalizeLimitKey(ledger.LimitKey{
+		Scope:    ledger.LimitScope(parts[0]),
+		Subject:  parts[1],
+		Currency: parts[2],
+	})
+	if err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	if len(parts) == 4 {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		u, err := a.limits.LimitUtilization(r.Context(), key)
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, u)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req limitRequest
+		if err := decodeJSON(w, r, &req); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		l, err := a.limits.SetLimit(r.Context(), ledger.Limit{
+			Scope:         key.Scope,
+			Subject:       key.Subject,
+			Currency:      key.Currency,
+			MaxAmount:     req.MaxAmount,
+			DailyAmount:   req.DailyAmount,
+			DailyCount:    req.DailyCount,
+			WindowAmount:  req.WindowAmount,
+			WindowCount:   req.WindowCount,
+			WindowSeconds: req.WindowSeconds,
+		})
+		if err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		a.audit(r.Context(), "ledger.limit.set", "limit", limitID(key), map[string]string{
+			"max_amount":     strconv.FormatInt(l.MaxAmount, 10),
+			"daily_amount":   strconv.FormatInt(l.DailyAmount, 10),
+			"daily_count":    strconv.FormatInt(l.DailyCount, 10),
+			"window_amount":  strconv.FormatInt(l.WindowAmount, 10),
+			"window_count":   strconv.FormatInt(l.WindowCount, 10),
+			"window_seconds": strconv.FormatInt(l.WindowSeconds, 10),
+		})
+		writeJSON(w, http.StatusOK, l)
+	case http.MethodDelete:
+		if err := a.limits.DeleteLimit(r.Context(), key); err != nil {
+			handleLedgerError(w, r, err)
+			return
+		}
+		a.audit(r.Context(), "ledger.limit.delete", "limit", limitID(key), nil)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r, http.MethodPut, http.MethodDelete)
+	}
+}
+
+// limitID names a limit in the audit log.
+func limitID(k ledger.LimitKey) string {
+	return string(k.Scope) + "/" + k.Subject + "/" + k.Currency
+}
+
+func (a *API) requireLimits(w http.ResponseWriter, r *http.Request) bool {
+	if a.limits == nil {
+		writeError(w, r, http.StatusServiceUnavailable, "transfer limits unavailable")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,309 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/netting"
+	"qazna.org/internal/screening"
+)
+
+type openCycleRequest struct {
+	SettlementAccountID string `json:"settlement_account_id"`
+	Note                string `json:"note"`
+}
+
+type cancelCycleRequest struct {
+	Note string `json:"note"`
+}
+
+// handleNettingCycles serves /v1/netting/cycles: GET lists cycles and POST
+// opens one, GET .../{id} returns a cycle, GET and POST .../{id}/payments
+// list and submit its payments, POST .../{id}/close, .../{id}/settle and
+// .../{id}/cancel move it along, and GET .../{id}/report reports on it.
+//
+// Submitting a payment takes ledger.transfer on the payer account; the rest
+// takes netting.manage.
+func (a *API) handleNettingCycles(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/netting/cycles"), "/")
+	id, action, _ := strings.Cut(rest, "/")
+	if action == "payments" && r.Method == http.MethodPost {
+		if !a.ensurePermissions(w, r, auth.PermissionLedgerTransfer) || !a.requireNetting(w, r) {
+			return
+		}
+		a.submitNettingPayment(w, r, id)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionNettingManage) || !a.requireNetting(w, r) {
+		return
+	}
+	switch {
+	case id == "":
+		switch r.Method {
+		case http.MethodGet:
+			a.listNettingCycles(w, r)
+		case http.MethodPost:
+			a.openNettingCycle(w, r)
+		default:
+			methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		}
+	case action == "":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		c, err := a.netting.Store().GetCycle(r.Context(), id)
+		if err != nil {
+			handleNettingError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, c)
+	case action == "payments":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+			return
+		}
+		payments, err := a.netting.Store().ListPayments(r.Context(), id)
+		if err != nil {
+			handleNettingError(w, r, err)
+			return
+		}
+		if payments == nil {
+			payments = []netting.Payment{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": payments})
+	case action == "report":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		rep, err := a.netting.Report(r.Context(), id)
+		if err != nil {
+			handleNettingError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, rep)
+	case action == "close" || action == "settle" || action == "cancel":
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+		a.moveNettingCycle(w, r, id, action)
+	default:
+		writeError(w, r, http.StatusNotFound, "resource not found")
+	}
+}
+
+func (a *API) listNettingCycles(w http.ResponseWriter, r *http.Request) {
+	status := netting.CycleStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", netting.CycleOpen, netting.CycleClosed, netting.CycleSettling, netting.CycleSettled, netting.CycleCancelled:
+	default:
+		writeError(w, r, http.StatusBadRequest, "status must be open, closed, settling, settled or cancelled")
+		return
+	}
+	cycles, err := a.netting.Store().ListCycles(r.Context(), status)
+	if err != nil {
+		handleNettingError(w, r, err)
+		return
+	}
+	if cycles == nil {
+		cycles = []netting.Cycle{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": cycles})
+}
+
+func (a *API) openNettingCycle(w http.ResponseWriter, r *http.Request) {
+	var req openCycleRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	userID, _ := auth.UserIDFromContext(r.Context())
+	orgID, _ := auth.OrganizationIDFromContext(r.Context())
+	c, err := a.netting.Open(a.ledgerContext(r), netting.Cycle{
+		SettlementAccountID: strings.TrimSpace(req.SettlementAccountID),
+		OpenedBy:            userID,
+		OpenedByOrg:         orgID,
+		Note:                strings.TrimSpace(req.Note),
+	})
+	if err != nil {
+		handleNettingError(w, r, err)
+		return
+	}
+	a.audit(r.Context(), "netting.cycle.open", "netting_cycle", c.ID, map[string]string{
+		"settlement_account": c.SettlementAccountID,
+	})
+	w.Header().Set("Location", "/v1/netting/cycles/"+c.ID)
+	writeJSON(w, http.StatusCreated, c)
+}
+
+// submitNettingPayment queues a payment in an open cycle. The payer account
+// must be visible to the caller, as for a transfer; the payee may belong to
+// any organization.
+//
+// Payments are screened like transfers before they are queued. A rejected
+// payment is answered with 403. A held one is not queued but waits in the
+// review queue, answered with 202 and the review; approving the review
+// settles it on its own as a transfer.
+func (a *API) submitNettingPayment(w http.ResponseWriter, r *http.Request, cycleID string) {
+	var req transferRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	idem, err := idempotencyKey(r, req.IdempotencyKey)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	fromID, toID := strings.TrimSpace(req.FromID), strings.TrimSpace(req.ToID)
+	if fromID == "" || toID == "" {
+		writeError(w, r, http.StatusBadRequest, "from_id and to_id are required")
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if len(currency) > 8 {
+		writeError(w, r, http.StatusBadRequest, "currency code too long")
+		return
+	}
+	if _, err := a.ledger.GetAccount(a.ledgerContext(r), fromID); err != nil {
+		handleLedgerError(w, r, err)
+		return
+	}
+	userID, _ := auth.UserIDFromContext(r.Context())
+	orgID, _ := auth.OrganizationIDFromContext(r.Context())
+	p := netting.Payment{
+		CycleID:        cycleID,
+		FromAccountID:  fromID,
+		ToAccountID:    toID,
+		Currency:       currency,
+		Amount:         req.Amount,
+		IdempotencyKey: idem,
+		SubmittedBy:    userID,
+		SubmittedByOrg: orgID,
+	}
+	if err := a.netting.Check(r.Context(), p); err != nil {
+		handleNettingError(w, r, err)
+		return
+	}
+	if a.screener != nil {
+		res, rv, err := a.transferScreen().transfer(r.Context(), fromID, toID, ledger.Money{Currency: currency, Amount: req.Amount}, idem)
+		if err != nil {
+			handleScreenedError(w, r, err)
+			return
+		}
+		switch {
+		case res.Decision == screening.Reject, res.Decision == screening.Hold && rv.Status == screening.ReviewRejected:
+			writeError(w, r, http.StatusForbidden, errScreeningRejected.Error())
+			return
+		case res.Decision == screening.Hold:
+			// Also for an approved review: the approval settled the payment.
+			w.Header().Set("Location", "/v1/screening/reviews/"+rv.ID)
+			writeJSON(w, http.StatusAccepted, heldTransferResponse{ReviewID: rv.ID, Status: rv.Status})
+			return
+		}
+	}
+	p, err = a.netting.Submit(r.Context(), p)
+	if err != nil {
+		handleNettingError(w, r, err)
+		return
+	}
+	meta := map[string]string{
+		"cycle_id":     p.CycleID,
+		"from_account": p.FromAccountID,
+		"to_account":   p.ToAccountID,
+		"currency":     p.Currency,
+		"amount":       strconv.FormatInt(p.Amount, 10),
+	}
+	if p.Fee != nil {
+		meta["fee"] = strconv.FormatInt(p.Fee.Amount, 10)
+		meta["fee_schedule_version"] = strconv.Itoa(p.Fee.ScheduleVersion)
+	}
+	if p.IdempotencyKey != "" {
+		meta["idempotency_key"] = p.IdempotencyKey
+	}
+	a.audit(r.Context(), "netting.payment.submit", "netting_payment", p.ID, meta)
+	writeJSON(w, http.StatusCreated, p)
+}
+
+// moveNettingCycle closes, settles or cancels a cycle. Settlement debits
+// participants of every organization, so it runs outside the caller's
+// organization scope.
+func (a *API) moveNettingCycle(w http.ResponseWriter, r *http.Request, id, action string) {
+	var (
+		c   netting.Cycle
+		err error
+	)
+	switch action {
+	case "close":
+		c, err = a.netting.Close(r.Context(), id)
+	case "settle":
+		c, err = a.netting.Settle(ledger.WithoutOrganizationScope(r.Context()), id)
+	case "cancel":
+		var req cancelCycleRequest
+		if r.ContentLength != 0 {
+			if err := decodeJSON(w, r, &req); err != nil {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		c, err = a.netting.Cancel(r.Context(), id, strings.TrimSpace(req.Note))
+	}
+	if err != nil {
+		handleNettingError(w, r, err)
+		return
+	}
+	meta := map[string]string{"status": string(c.Status)}
+	if c.TransactionID != "" {
+		meta["transaction_id"] = c.TransactionID
+	}
+	if c.Note != "" {
+		meta["note"] = c.Note
+	}
+	a.audit(r.Context(), "netting.cycle."+action, "netting_cycle", c.ID, meta)
+	writeJSON(w, http.StatusOK, c)
+}
+
+// quoteNettingFee fixes the fee of a payment as a transfer from its payer
+// would be charged.
+func (a *API) requireNetting(w http.ResponseWriter, r *http.Request) bool {
+	if a.netting == nil {
+		writeError(w, r, http.StatusServiceUnavailable, "netting unavailable without a persistent cycle store")
+		return false
+	}
+	return true
+}
+
+func (a *API) quoteNettingFee(ctx context.Context, p netting.Payment) (*ledger.Fee, error) {
+	return a.feeCharger().quote(ctx, p.FromAccountID, ledger.Money{Currency: p.Currency, Amount: p.Amount})
+}
+
+// screenNettingPayment screens a payment again before its cycle settles, in
+// case a party was listed since it was submitted. A payment screening would
+// hold cannot wait for review at this point and is turned down too.
+func (a *API) screenNettingPayment(ctx context.Context, p netting.Payment) (bool, error) {
+	err := a.transferScreen().posting(ctx, p.FromAccountID, p.ToAccountID, ledger.Money{Currency: p.Currency, Amount: p.Amount})
+	if errors.Is(err, errScreeningRejected) || errors.Is(err, errScreeningHeld) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func handleNettingError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, netting.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, err.Error())
+	case errors.Is(err, netting.ErrInvalidTransition), errors.Is(err, netting.ErrCycleNotOpen), errors.Is(err, netting.ErrCycleFull):
+		writeError(w, r, http.StatusConflict, err.Error())
+	case errors.Is(err, netting.ErrInvalidCycle), errors.Is(err, netting.ErrInvalidPayment):
+		writeError(w, r, http.StatusBadRequest, err.Error())
+	default:
+		handleScreenedError(w, r, err)
+	}
+}
@@ -5,18 +5,22 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"qazna.org/internal/auth"
+	"qazna.org/internal/obs"
 )
 
 type createOrganizationRequest struct {
-	Name     string         `json:"name"`
-	Metadata map[string]any `json:"metadata"`
+	Name            string         `json:"name"`
+	ParticipantType string         `json:"participant_type"`
+	Metadata        map[string]any `json:"metadata"`
 }
 
 type updateOrganizationRequest struct {
-	Name     *string         `json:"name"`
-	Metadata *map[string]any `json:"metadata"`
+	Name            *string         `json:"name"`
+	ParticipantType *string         `json:"participant_type"`
+	Metadata        *map[string]any `json:"metadata"`
 }
 
 type createUserRequest struct {
@@ -74,13 +78,14 @@ func (a *API) handleOrganizations(w http.ResponseWriter, r *http.Request) {
 			writeError(w, r, http.StatusBadRequest, err.Error())
 			return
 		}
-		org, err := a.rbac.CreateOrganization(r.Context(), req.Name, req.Metadata)
+		org, err := a.rbac.CreateOrganization(r.Context(), req.Name, req.ParticipantType, req.Metadata)
 		if err != nil {
 			handleRBACError(w, r, err)
 			return
 		}
 		a.audit(r.Context(), "rbac.organization.create", "organization", org.ID, map[string]string{
-			"name": org.Name,
+			"name":             org.Name,
+			"participant_type": org.ParticipantType,
 		})
 		w.Header().Set("Location", fmt.Sprintf("/v1/organizations/%s", org.ID))
 		writeJSON(w, http.StatusCreated, org)
@@ -152,6 +157,7 @@ func (a *API) handleOrganizationResource(w http.ResponseWriter, r *http.Request,
 		if req.Name != nil {
 			upd.Name = req.Name
 		}
+		upd.ParticipantType = req.ParticipantType
 		if req.Metadata != nil {
 			upd.Metadata = *req.Metadata
 		}
@@ -161,7 +167,8 @@ func (a *API) handleOrganizationResource(w http.ResponseWriter, r *http.Request,
 			return
 		}
 		a.audit(r.Context(), "rbac.organization.update", "organization", orgID, map[string]string{
-			"name": org.Name,
+			"name":             org.Name,
+			"participant_type": org.ParticipantType,
 		})
 		writeJSON(w, http.StatusOK, org)
 	case http.MethodDelete:
@@ -241,6 +248,7 @@ func (a *API) handleOrganizationUserResource(w http.ResponseWriter, r *http.Requ
 			handleRBACError(w, r, err)
 			return
 		}
+		a.revokeSessionsAfterUpdate(r, user, upd)
 		a.audit(r.Context(), "rbac.user.update", "user", userID, map[string]string{
 			"organization_id": user.OrganizationID,
 			"email":           user.Email,
@@ -439,6 +447,10 @@ func (a *API) handleUserResource(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	parts := strings.Split(path, "/")
+	if len(parts) == 2 && parts[1] == "sessions" {
+		a.handleUserSessions(w, r, parts[0])
+		return
+	}
 	if len(parts) < 2 || parts[1] != "assignments" {
 		writeError(w, r, http.StatusNotFound, "resource not found")
 		return
@@ -510,6 +522,51 @@ func (a *API) handleUserResource(w http.ResponseWriter, r *http.Request) {
 	writeError(w, r, http.StatusNotFound, "resource not found")
 }
 
+// handleUserSessions revokes every refresh token the user holds. Access
+// tokens already issued stay valid until they expire.
+func (a *API) handleUserSessions(w http.ResponseWriter, r *http.Request, userID string) {
+	if r.Method != http.MethodDelete {
+		methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+	if !a.ensurePermissions(w, r, auth.PermissionManageUsers) {
+		return
+	}
+	if a.auth == nil {
+		writeError(w, r, http.StatusNotImplemented, "authentication service unavailable")
+		return
+	}
+	revoked, err := a.auth.RevokeUserTokens(r.Context(), userID)
+	if err != nil {
+		handleRBACError(w, r, err)
+		return
+	}
+	a.audit(r.Context(), "auth.session.revoke_all", "user", userID, map[string]string{
+		"revoked": fmt.Sprintf("%d", revoked),
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// revokeSessionsAfterUpdate ends refresh sessions when a user's password
+// changes or the user is disabled.
+func (a *API) revokeSessionsAfterUpdate(r *http.Request, user auth.User, upd auth.UserUpdate) {
+	if a.auth == nil {
+		return
+	}
+	if upd.Password == nil && user.Status != auth.UserStatusDisabled {
+		return
+	}
+	if _, err := a.auth.RevokeUserTokens(r.Context(), user.ID); err != nil {
+		obs.LogRequest(map[string]any{
+			"ts":      time.Now().UTC().Format(time.RFC3339Nano),
+			"level":   "error",
+			"msg":     "session_revoke_failed",
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+}
+
 func handleRBACError(w http.ResponseWriter, r *http.Request, err error) {
 	switch {
 	case errors.Is(err, auth.ErrInvalidInput):
@@ -11,7 +11,7 @@ import (
 )
 
 type stubRBACStore struct {
-	createOrgFn       func(context.Context, string, map[string]any) (auth.Organization, error)
+	createOrgFn       func(context.Context, string, string, map[string]any) (auth.Organization, error)
 	listOrgFn         func(context.Context) ([]auth.Organization, error)
 	getOrgFn          func(context.Context, string) (auth.Organization, error)
 	updateOrgFn       func(context.Context, string, auth.OrganizationUpdate) (auth.Organization, error)
@@ -33,9 +33,9 @@ type stubRBACStore struct {
 	userPermissionsFn func(context.Context, string) ([]string, error)
 }
 
-func (s *stubRBACStore) CreateOrganization(ctx context.Context, name string, metadata map[string]any) (auth.Organization, error) {
+func (s *stubRBACStore) CreateOrganization(ctx context.Context, name, participantType string, metadata map[string]any) (auth.Organization, error) {
 	if s.createOrgFn != nil {
-		return s.createOrgFn(ctx, name, metadata)
+		return s.createOrgFn(ctx, name, participantType, metadata)
 	}
 	return auth.Organization{}, nil
 }
@@ -190,7 +190,7 @@ func TestRBACCreateOrganizationRequiresPermission(t *testing.T) {
 }
 
 func TestRBACCreateOrganizationSuccess(t *testing.T) {
-	var capturedName string
+	var capturedName, capturedType string
 	store := &stubRBACStore{
 		userPermissionsFn: func(_ context.Context, userID string) ([]string, error) {
 			if userID != "rbac-admin" {
@@ -198,14 +198,15 @@ func TestRBACCreateOrganizationSuccess(t *testing.T) {
 			}
 			return []string{auth.PermissionManageOrganizations}, nil
 		},
-		createOrgFn: func(_ context.Context, name string, metadata map[string]any) (auth.Organization, error) {
-			capturedName = name
+		createOrgFn: func(_ context.Context, name, participantType string, metadata map[string]any) (auth.Organization, error) {
+			capturedName, capturedType = name, participantType
 			return auth.Organization{
-				ID:        "org-123",
-				Name:      name,
-				Metadata:  metadata,
-				CreatedAt: time.Now().UTC(),
-				UpdatedAt: time.Now().UTC(),
+				ID:              "org-123",
+				Name:            name,
+				ParticipantType: participantType,
+				Metadata:        metadata,
+				CreatedAt:       time.Now().UTC(),
+				UpdatedAt:       time.Now().UTC(),
 			}, nil
 		},
 	}
@@ -230,12 +231,26 @@ func TestRBACCreateOrganizationSuccess(t *testing.T) {
 	if capturedName != "Strategic Ops" {
 		t.Fatalf("expected trimmed name, got %q", capturedName)
 	}
+	if capturedType != auth.DefaultParticipantType || payload.ParticipantType != auth.DefaultParticipantType {
+		t.Fatalf("expected the default participant type, got %q", capturedType)
+	}
 	if payload.ID != "org-123" {
 		t.Fatalf("unexpected organization id: %s", payload.ID)
 	}
 	if payload.Metadata["region"] != "EU" {
 		t.Fatalf("metadata not forwarded: %v", payload.Metadata)
 	}
+
+	resp = api.post("/v1/organizations", map[string]any{"name": "Treasury", "participant_type": " Sovereign "}, map[string]string{"Authorization": "Bearer " + token})
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || capturedType != auth.ParticipantSovereign {
+		t.Fatalf("expected a sovereign organization, got %d %q", resp.StatusCode, capturedType)
+	}
+	resp = api.post("/v1/organizations", map[string]any{"name": "Treasury", "participant_type": "bank"}, map[string]string{"Authorization": "Bearer " + token})
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown participant type: expected 400, got %d", resp.StatusCode)
+	}
 }
 
 func TestRBACAssignRoleRequiresPayload(t *testing.T) {
@@ -0,0 +1,380 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"qazna.org/internal/auth"
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/screening"
+)
+
+type heldTransferResponse struct {
+	ReviewID string                 `json:"review_id"`
+	Status   screening.ReviewStatus `json:"status"`
+}
+
+type reviewDecisionRequest struct {
+	Note string `json:"note"`
+}
+
+var (
+	// errScreeningUnavailable wraps a failure of the screener itself.
+	errScreeningUnavailable = errors.New("transfer screening unavailable")
+	errScreeningRejected    = errors.New("transfer rejected by screening")
+	// errScreeningHeld refuses a held posting that cannot wait in the
+	// review queue, which only holds plain transfers.
+	errScreeningHeld = errors.New("transfer held by screening; only plain transfers can wait for review")
+)
+
+// transferScreen screens the parties of postings before they are committed.
+// The HTTP handlers and the LedgerService RPCs share it, so funds move to the
+// same counterparties whichever way they are sent. Without a screener every
+// posting is allowed.
+type transferScreen struct {
+	ledger   ledger.Service
+	rbac     *auth.RBACService
+	screener screening.Screener
+	reviews  screening.ReviewQueue
+}
+
+func (a *API) transferScreen() transferScreen {
+	return transferScreen{ledger: a.ledger, rbac: a.rbac, screener: a.screener, reviews: a.reviews}
+}
+
+// screenTransfer runs the screen on a transfer about to be committed. It
+// reports true when the transfer may proceed; otherwise it has answered 403
+// for a rejection or 202 with the review a held transfer waits in. Neither
+// response tells the caller what matched.
+func (a *API) screenTransfer(ctx context.Context, w http.ResponseWriter, r *http.Request, fromID, toID string, amt ledger.Money, idem string) bool {
+	res, rv, err := a.transferScreen().transfer(ctx, fromID, toID, amt, idem)
+	if err != nil {
+		handleScreenedError(w, r, err)
+		return false
+	}
+	switch res.Decision {
+	case screening.Allow:
+		return true
+	case screening.Hold:
+		switch rv.Status {
+		case screening.ReviewApproved:
+			// A retry of an approved transfer replays its transaction.
+			return true
+		case screening.ReviewRejected:
+			writeError(w, r, http.StatusForbidden, errScreeningRejected.Error())
+			return false
+		}
+		w.Header().Set("Location", "/v1/screening/reviews/"+rv.ID)
+		writeJSON(w, http.StatusAccepted, heldTransferResponse{ReviewID: rv.ID, Status: rv.Status})
+		return false
+	case screening.Reject:
+		writeError(w, r, http.StatusForbidden, errScreeningRejected.Error())
+		return false
+	default:
+		writeError(w, r, http.StatusInternalServerError, "internal error")
+		return false
+	}
+}
+
+// handleScreenedError answers a posting refused by screening with 403, a
+// screener failure with 503 and anything else as a ledger error.
+func handleScreenedError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errScreeningRejected), errors.Is(err, errScreeningHeld):
+		writeError(w, r, http.StatusForbidden, err.Error())
+	case errors.Is(err, errScreeningUnavailable):
+		writeError(w, r, http.StatusServiceUnavailable, err.Error())
+	default:
+		handleLedgerError(w, r, err)
+	}
+}
+
+// transfer screens a plain transfer, queues it for review when it is held
+// and audits the decision. The review is returned for holds; for a retried
+// transfer it may already be decided.
+func (s transferScreen) transfer(ctx context.Context, fromID, toID string, amt ledger.Money, idem string) (screening.Result, screening.Review, error) {
+	return s.run(ctx, fromID, toID, amt, idem, true)
+}
+
+// posting screens a posting that cannot wait in the review queue, such as
+// an FX transfer or a hold: it fails with errScreeningRejected or
+// errScreeningHeld unless screening allows it.
+func (s transferScreen) posting(ctx context.Context, fromID, toID string, amt ledger.Money) error {
+	if s.screener == nil {
+		return nil
+	}
+	res, _, err := s.run(ctx, fromID, toID, amt, "", false)
+	if err != nil {
+		return err
+	}
+	switch res.Decision {
+	case screening.Allow:
+		return nil
+	case screening.Hold:
+		return errScreeningHeld
+	default:
+		return errScreeningRejected
+	}
+}
+
+// entries screens a batch posting as a posting from every debited account
+// to every account credited in the same currency.
+func (s transferScreen) entries(ctx context.Context, entries []ledger.Entry) error {
+	if s.screener == nil {
+		return nil
+	}
+	type pair struct{ from, to, currency string }
+	screened := make(map[pair]bool)
+	for _, d := range entries {
+		if d.Direction != ledger.Debit {
+			continue
+		}
+		for _, c := range entries {
+			p := pair{d.AccountID, c.AccountID, c.Currency}
+			if c.Direction != ledger.Credit || c.Currency != d.Currency || c.AccountID == d.AccountID || screened[p] {
+				continue
+			}
+			screened[p] = true
+			if err := s.posting(ctx, d.AccountID, c.AccountID, ledger.Money{Currency: c.Currency, Amount: c.Amount}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// capture screens the capture of amount (all of it when 0) of hold id. The
+// parties were screened when the hold was created, but may have been listed
+// since.
+func (s transferScreen) capture(ctx context.Context, id string, amount int64) error {
+	if s.screener == nil {
+		return nil
+	}
+	h, err := s.ledger.GetHold(ctx, id)
+	if err != nil {
+		return err
+	}
+	if amount == 0 {
+		amount = h.Amount
+	}
+	return s.posting(ctx, h.FromAccountID, h.ToAccountID, ledger.Money{Currency: h.Currency, Amount: amount})
+}
+
+func (s transferScreen) run(ctx context.Context, fromID, toID string, amt ledger.Money, idem string, queue bool) (screening.Result, screening.Review, error) {
+	payer, err := s.party(ctx, fromID)
+	if err != nil {
+		return screening.Result{}, screening.Review{}, err
+	}
+	// Payees may belong to any organization.
+	payee, err := s.party(ledger.WithoutOrganizationScope(ctx), toID)
+	if err != nil {
+		return screening.Result{}, screening.Review{}, err
+	}
+	res, err := s.screener.Screen(ctx, screening.Request{Payer: payer, Payee: payee, Amount: amt})
+	if err != nil {
+		return screening.Result{}, screening.Review{}, fmt.Errorf("%w: %v", errScreeningUnavailable, err)
+	}
+
+	var rv screening.Review
+	if res.Decision == screening.Hold && queue {
+		userID, _ := auth.UserIDFromContext(ctx)
+		orgID, _ := auth.OrganizationIDFromContext(ctx)
+		rv, err = s.reviews.CreateReview(ctx, screening.Review{
+			Transfer: screening.Transfer{
+				FromAccountID:  fromID,
+				ToAccountID:    toID,
+				Currency:       amt.Currency,
+				Amount:         amt.Amount,
+				IdempotencyKey: idem,
+			},
+			Result:         res,
+			RequestedBy:    userID,
+			RequestedByOrg: orgID,
+		})
+		if err != nil {
+			return screening.Result{}, screening.Review{}, err
+		}
+	}
+
+	meta := map[string]string{
+		"decision":     string(res.Decision),
+		"from_account": fromID,
+		"to_account":   toID,
+		"currency":     amt.Currency,
+		"amount":       strconv.FormatInt(amt.Amount, 10),
+	}
+	if res.Reason != "" {
+		meta["reason"] = res.Reason
+	}
+	if idem != "" {
+		meta["idempotency_key"] = idem
+	}
+	if rv.ID != "" {
+		meta["review_id"] = rv.ID
+	}
+	logAudit(ctx, "screening.decision", "transfer", rv.ID, meta)
+	return res, rv, nil
+}
+
+// party describes an account for the screener: its organization, with name
+// and metadata when RBAC is available.
+func (s transferScreen) party(ctx context.Context, accountID string) (screening.Party, error) {
+	acc, err := s.ledger.GetAccount(ctx, accountID)
+	if err != nil {
+		return screening.Party{}, err
+	}
+	p := screening.Party{AccountID: acc.ID, OrganizationID: acc.OrganizationID}
+	if acc.OrganizationID == "" || s.rbac == nil {
+		return p, nil
+	}
+	org, err := s.rbac.GetOrganization(ctx, acc.OrganizationID)
+	if errors.Is(err, auth.ErrNotFound) {
+		return p, nil
+	}
+	if err != nil {
+		return screening.Party{}, err
+	}
+	p.OrganizationName, p.Metadata = org.Name, org.Metadata
+	return p, nil
+}
+
+// handleScreeningReviews serves GET /v1/screening/reviews and
+// /v1/screening/reviews/{id}, and POST .../{id}/approve and .../{id}/reject.
+func (a *API) handleScreeningReviews(w http.ResponseWriter, r *http.Request) {
+	if !a.ensurePermissions(w, r, auth.PermissionScreeningReview) || !a.requireScreening(w, r) {
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/screening/reviews"), "/")
+	id, action, _ := strings.Cut(rest, "/")
+	switch {
+	case id == "":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		status := screening.ReviewStatus(r.URL.Query().Get("status"))
+		switch status {
+		case "", screening.ReviewPending, screening.ReviewApproved, screening.ReviewRejected:
+		default:
+			writeError(w, r, http.StatusBadRequest, "status must be pending, approved or rejected")
+			return
+		}
+		reviews, err := a.reviews.ListReviews(r.Context(), status)
+		if err != nil {
+			handleScreeningError(w, r, err)
+			return
+		}
+		if reviews == nil {
+			reviews = []screening.Review{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": reviews})
+	case action == "":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		rv, err := a.reviews.GetReview(r.Context(), id)
+		if err != nil {
+			handleScreeningError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, rv)
+	case action == "approve" || action == "reject":
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+		a.decideReview(w, r, id, action)
+	default:
+		writeError(w, r, http.StatusNotFound, "resource not found")
+	}
+}
+
+// decideReview approves or rejects a held transfer. Approval commits the
+// transfer first, under the transfer's idempotency key or one derived from
+// the review, so concurrent approvals commit it once; a failed transfer
+// leaves the review pending.
+func (a *API) decideReview(w http.ResponseWriter, r *http.Request, id, action string) {
+	var req reviewDecisionRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, &req); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	rv, err := a.reviews.GetReview(r.Context(), id)
+	if err != nil {
+		handleScreeningError(w, r, err)
+		return
+	}
+	if rv.Status != screening.ReviewPending {
+		handleScreeningError(w, r, screening.ErrReviewDecided)
+		return
+	}
+	userID, _ := auth.UserIDFromContext(r.Context())
+	d := screening.ReviewDecision{Status: screening.ReviewRejected, By: userID, Note: strings.TrimSpace(req.Note)}
+	if err := d.Validate(); err != nil {
+		handleScreeningError(w, r, err)
+		return
+	}
+	if action == "approve" {
+		t := rv.Transfer
+		idem := t.IdempotencyKey
+		if idem == "" {
+			idem = "screening-review-" + rv.ID
+		}
+		// The requester's access to the payer account was checked when the
+		// transfer was held.
+		ctx := ledger.WithoutOrganizationScope(r.Context())
+		tx, ok := a.executeTransfer(ctx, w, r, t.FromAccountID, t.ToAccountID, ledger.Money{Currency: t.Currency, Amount: t.Amount}, idem)
+		if !ok {
+			return
+		}
+		d.Status, d.TransactionID = screening.ReviewApproved, tx.ID
+	}
+	rv, err = a.reviews.DecideReview(r.Context(), id, d)
+	if err != nil {
+		handleScreeningError(w, r, err)
+		return
+	}
+	meta := map[string]string{
+		"decided_by":   d.By,
+		"from_account": rv.Transfer.FromAccountID,
+		"to_account":   rv.Transfer.ToAccountID,
+		"currency":     rv.Transfer.Currency,
+		"amount":       strconv.FormatInt(rv.Transfer.Amount, 10),
+	}
+	if d.Note != "" {
+		meta["note"] = d.Note
+	}
+	if d.TransactionID != "" {
+		meta["transaction_id"] = d.TransactionID
+	}
+	a.audit(r.Context(), "screening.review."+action, "screening_review", rv.ID, meta)
+	writeJSON(w, http.StatusOK, rv)
+}
+
+func handleScreeningError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, screening.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, err.Error())
+	case errors.Is(err, screening.ErrReviewDecided):
+		writeError(w, r, http.StatusConflict, err.Error())
+	case errors.Is(err, screening.ErrInvalidDecision):
+		writeError(w, r, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, r, http.StatusInternalServerError, "internal error")
+	}
+}
+
+func (a *API) requireScreening(w http.ResponseWriter, r *http.Request) bool {
+	if a.reviews == nil {
+		writeError(w, r, http.StatusServiceUnavailable, "transfer screening disabled")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,271 @@
+// Package iso20022 reads and writes the ISO 20022 payment clearing and
+// settlement messages the ledger exchanges with participants: pacs.008
+// customer and pacs.009 financial institution credit transfers, pacs.002
+// payment status reports, and camt.053 and camt.052 account statements.
+//
+// Only the elements the ledger acts on are modelled. Accounts are identified
+// by their ledger account ID in Acct/Id/Othr/Id; an IBAN is read as the
+// account ID when no other identification is given.
+package iso20022
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"qazna.org/internal/ledger"
+)
+
+// Message definitions this package reads and writes.
+const (
+	Pacs008 = "pacs.008.001.08"
+	Pacs009 = "pacs.009.001.08"
+	Pacs002 = "pacs.002.001.10"
+)
+
+// namespacePrefix precedes the message definition in document namespaces.
+const namespacePrefix = "urn:iso:std:iso:20022:tech:xsd:"
+
+// MaxTransactions bounds the transactions of one message.
+const MaxTransactions = 1000
+
+var (
+	ErrUnsupportedMessage = errors.New("unsupported ISO 20022 message")
+	ErrInvalidMessage     = errors.New("invalid ISO 20022 message")
+)
+
+// Transaction status codes (ExternalPaymentTransactionStatus1Code) and the
+// group status of a partially accepted message.
+const (
+	StatusSettled  = "ACSC"
+	StatusPending  = "PDNG"
+	StatusRejected = "RJCT"
+	StatusPartial  = "PART"
+)
+
+// Status reason codes (ExternalStatusReason1Code) the ledger reports.
+const (
+	ReasonIncorrectAccount  = "AC01"
+	ReasonCreditorAccount   = "AC03"
+	ReasonClosedAccount     = "AC04"
+	ReasonBlockedAccount    = "AC06"
+	ReasonForbidden         = "AG01"
+	ReasonCurrency          = "AM03"
+	ReasonInsufficientFunds = "AM04"
+	ReasonDuplicate         = "AM05"
+	ReasonInvalidAmount     = "AM12"
+	ReasonAmountLimit       = "AM14"
+	ReasonFormat            = "FF01"
+	ReasonNarrative         = "NARR"
+	ReasonRegulatory        = "RR04"
+)
+
+// NotProvided is the EndToEndId of a payment its initiator gave no reference.
+const NotProvided = "NOTPROVIDED"
+
+// ReasonError is a transaction that cannot be settled, with the status
+// reason code to report it under.
+type ReasonError struct {
+	Code    string
+	Message string
+}
+
+func (e *ReasonError) Error() string { return e.Message }
+
+func reasonf(code, format string, args ...any) error {
+	return &ReasonError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// CreditTransfer is a pacs.008 or pacs.009 message.
+type CreditTransfer struct {
+	Message   string // Pacs008 or Pacs009
+	MsgID     string
+	CreatedAt time.Time
+	// SettlementMethod is CLRG when empty.
+	SettlementMethod string
+	// ControlSum, when set, is the sum of all transaction amounts.
+	ControlSum   string
+	Transactions []CreditTransferTx
+}
+
+// CreditTransferTx is one CdtTrfTxInf of a credit transfer.
+type CreditTransferTx struct {
+	InstrID    string
+	EndToEndID string
+	TxID       string
+	UETR       string
+	Amount     Amount
+	// SettlementDate is omitted when zero.
+	SettlementDate time.Time
+	// ChargeBearer is a pacs.008 element, SLEV when empty there.
+	ChargeBearer  string
+	Charges       []Charge
+	Debtor        Party
+	DebtorAgent   Agent
+	CreditorAgent Agent
+	Creditor      Party
+	Remittance    string
+}
+
+// Amount is a decimal amount in major units, as written in messages.
+type Amount struct {
+	Currency string
+	Value    string
+}
+
+// Money converts a to minor units with exp fractional digits.
+func (a Amount) Money(exp int) (ledger.Money, error) {
+	v, err := ledger.ParseAmount(a.Value, exp)
+	if err != nil {
+		return ledger.Money{}, err
+	}
+	return ledger.Money{Currency: a.Currency, Amount: v}, nil
+}
+
+// AmountOf renders m in major units with exp fractional digits.
+func AmountOf(m ledger.Money, exp int) Amount {
+	return Amount{Currency: m.Currency, Value: ledger.FormatAmount(m.Amount, exp)}
+}
+
+// Party is a debtor or creditor. In pacs.008 it is named by Name; in
+// pacs.009 it is a financial institution identified by BIC, or by Name when
+// it has none.
+type Party struct {
+	Name    string
+	BIC     string
+	Account string
+}
+
+// Agent is a financial institution identified by BIC or by another ID, such
+// as the participant's organization ID.
+type Agent struct {
+	BIC string
+	ID  string
+}
+
+// Charge is a fee taken by Agent.
+type Charge struct {
+	Amount Amount
+	Agent  Agent
+}
+
+var (
+	currencyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{2,7}$`)
+	amountPattern   = regexp.MustCompile(`^[0-9]{1,18}(\.[0-9]{1,18})?$`)
+	bicPattern      = regexp.MustCompile(`^[A-Z0-9]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	chargeBearers   = map[string]bool{"DEBT": true, "CRED": true, "SHAR": true, "SLEV": true}
+)
+
+// ValidBIC reports whether bic is a well-formed 8 or 11 character BIC.
+func ValidBIC(bic string) bool {
+	return bicPattern.MatchString(bic)
+}
+
+// Validate checks what the ledger needs of a transaction before settling
+// it. Failures are ReasonErrors.
+func (t CreditTransferTx) Validate() error {
+	switch {
+	case t.EndToEndID == "" || len(t.EndToEndID) > 35:
+		return reasonf(ReasonFormat, "EndToEndId must be 1-35 characters")
+	case len(t.InstrID) > 35 || len(t.TxID) > 35 || len(t.UETR) > 36:
+		return reasonf(ReasonFormat, "InstrId and TxId must be at most 35 characters, UETR 36")
+	case !currencyPattern.MatchString(t.Amount.Currency):
+		return reasonf(ReasonCurrency, "invalid currency %q", t.Amount.Currency)
+	case !amountPattern.MatchString(t.Amount.Value) || isZero(t.Amount.Value):
+		return reasonf(ReasonInvalidAmount, "invalid amount %q", t.Amount.Value)
+	case t.ChargeBearer != "" && !chargeBearers[t.ChargeBearer]:
+		return reasonf(ReasonFormat, "invalid ChrgBr %q", t.ChargeBearer)
+	case t.Debtor.Account == "" || len(t.Debtor.Account) > 64:
+		return reasonf(ReasonIncorrectAccount, "DbtrAcct must name a ledger account")
+	case t.Creditor.Account == "" || len(t.Creditor.Account) > 64:
+		return reasonf(ReasonCreditorAccount, "CdtrAcct must name a ledger account")
+	case utf8.RuneCountInString(t.Debtor.Name) > 140 || utf8.RuneCountInString(t.Creditor.Name) > 140 ||
+		utf8.RuneCountInString(t.Remittance) > 140:
+		return reasonf(ReasonFormat, "names and remittance information must be at most 140 characters")
+	}
+	for _, bic := range []string{t.Debtor.BIC, t.Creditor.BIC, t.DebtorAgent.BIC, t.CreditorAgent.BIC} {
+		if bic != "" && !ValidBIC(bic) {
+			return reasonf(ReasonFormat, "invalid BIC %q", bic)
+		}
+	}
+	return nil
+}
+
+func isZero(v string) bool {
+	for _, r := range v {
+		if r != '0' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// StatusReport is a pacs.002 message. The original group information is
+// omitted when OriginalMsgID is empty, as in reports on ledger transactions
+// that did not arrive as a message.
+type StatusReport struct {
+	MsgID             string
+	CreatedAt         time.Time
+	OriginalMsgID     string
+	OriginalMessage   string
+	OriginalCreatedAt time.Time
+	GroupStatus       string
+	Transactions      []TransactionStatus
+}
+
+// TransactionStatus is the status of one original transaction.
+type TransactionStatus struct {
+	OriginalInstrID    string
+	OriginalEndToEndID string
+	OriginalTxID       string
+	OriginalUETR       string
+	Status             string
+	Reason             string
+	AdditionalInfo     string
+	// AcceptedAt is when the ledger committed the transaction.
+	AcceptedAt time.Time
+	// ClearingRef is the ledger transaction ID.
+	ClearingRef string
+}
+
+// GroupStatus summarises sts: the common status when all agree, otherwise
+// StatusPartial.
+func GroupStatus(sts []TransactionStatus) string {
+	if len(sts) == 0 {
+		return ""
+	}
+	for _, s := range sts[1:] {
+		if s.Status != sts[0].Status {
+			return StatusPartial
+		}
+	}
+	return sts[0].Status
+}
+
+// Original returns the status report entry for t with the given status.
+func (t CreditTransferTx) Original(status string) TransactionStatus {
+	return TransactionStatus{
+		OriginalInstrID:    t.InstrID,
+		OriginalEndToEndID: t.EndToEndID,
+		OriginalTxID:       t.TxID,
+		OriginalUETR:       t.UETR,
+		Status:             status,
+	}
+}
+
+// Reject returns the status report entry rejecting t for err, reported under
+// the code of a ReasonError and as NARR otherwise.
+func (t CreditTransferTx) Reject(err error) TransactionStatus {
+	s := t.Original(StatusRejected)
+	s.Reason, s.AdditionalInfo = ReasonNarrative, err.Error()
+	var re *ReasonError
+	if errors.As(err, &re) {
+		s.Reason = re.Code
+	}
+	if r := []rune(s.AdditionalInfo); len(r) > 105 {
+		s.AdditionalInfo = string(r[:105])
+	}
+	return s
+}
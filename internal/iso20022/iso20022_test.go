@@ -0,0 +1,449 @@
+package iso20022
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+func TestCreditTransferRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		file    string
+		message string
+		txs     int
+	}{
+		{"testdata/pacs008.xml", Pacs008, 2},
+		{"testdata/pacs009.xml", Pacs009, 1},
+	} {
+		raw, err := os.ReadFile(tc.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct, err := ParseCreditTransfer(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.file, err)
+		}
+		if ct.Message != tc.message || len(ct.Transactions) != tc.txs {
+			t.Fatalf("%s: unexpected message %+v", tc.file, ct)
+		}
+		for i, tx := range ct.Transactions {
+			if err := tx.Validate(); err != nil {
+				t.Fatalf("%s: transaction %d: %v", tc.file, i+1, err)
+			}
+		}
+		out, err := MarshalCreditTransfer(ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := ParseCreditTransfer(out)
+		if err != nil {
+			t.Fatalf("%s: re-reading the rendered message: %v\n%s", tc.file, err, out)
+		}
+		if !reflect.DeepEqual(ct, again) {
+			t.Fatalf("%s: round trip changed the message:\n%+v\n%+v", tc.file, ct, again)
+		}
+		if rendered, _ := MarshalCreditTransfer(again); string(rendered) != string(out) {
+			t.Fatalf("%s: rendering is not stable:\n%s\n%s", tc.file, out, rendered)
+		}
+	}
+
+	raw, _ := os.ReadFile("testdata/pacs008.xml")
+	ct, _ := ParseCreditTransfer(raw)
+	want := CreditTransferTx{
+		InstrID:        "INSTR-1",
+		EndToEndID:     "E2E-INV-4411",
+		TxID:           "TX-1",
+		UETR:           "8a562c67-ca16-48ba-b074-65581be6f001",
+		Amount:         Amount{Currency: "KZT", Value: "1250.50"},
+		SettlementDate: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		ChargeBearer:   "SLEV",
+		Debtor:         Party{Name: "Steppe Grain Cooperative", Account: "01JA0000000000000000000001"},
+		DebtorAgent:    Agent{BIC: "KZBAKZKAXXX"},
+		CreditorAgent:  Agent{ID: "org-almaty"},
+		Creditor:       Party{Name: "Almaty Bakery", Account: "01JA0000000000000000000002"},
+		Remittance:     "Invoice 4411",
+	}
+	if !reflect.DeepEqual(ct.Transactions[0], want) {
+		t.Fatalf("unexpected transaction:\n%+v\nwant\n%+v", ct.Transactions[0], want)
+	}
+	if !ct.CreatedAt.Equal(time.Date(2026, 10, 16, 4, 30, 0, 0, time.UTC)) || ct.ControlSum != "1500.75" {
+		t.Fatalf("unexpected group header: %+v", ct)
+	}
+	if m, err := ct.Transactions[1].Amount.Money(2); err != nil || m != (ledger.Money{Currency: "KZT", Amount: 25025}) {
+		t.Fatalf("unexpected amount: %+v %v", m, err)
+	}
+
+	raw, _ = os.ReadFile("testdata/pacs009.xml")
+	fi, _ := ParseCreditTransfer(raw)
+	if p := fi.Transactions[0].Creditor; p.BIC != "ALMBKZKA" || p.Name != "Almaty Commercial Bank" || p.Account != "01JA0000000000000000000011" {
+		t.Fatalf("unexpected pacs.009 creditor: %+v", p)
+	}
+}
+
+func TestParseCreditTransferRejects(t *testing.T) {
+	raw, err := os.ReadFile("testdata/pacs008.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := string(raw)
+	for _, tc := range []struct {
+		name string
+		doc  string
+		want error
+	}{
+		{"not xml", "{}", ErrInvalidMessage},
+		{"other message", strings.Replace(sample, "pacs.008.001.08", "pain.001.001.09", 1), ErrUnsupportedMessage},
+		{"other version", strings.Replace(sample, "pacs.008.001.08", "pacs.008.001.02", 1), ErrUnsupportedMessage},
+		{"wrong root", strings.ReplaceAll(sample, "FIToFICstmrCdtTrf", "FICdtTrf"), ErrInvalidMessage},
+		{"no message id", strings.Replace(sample, "KZBANK-20261016-0001", "", 1), ErrInvalidMessage},
+		{"count mismatch", strings.Replace(sample, "<NbOfTxs>2", "<NbOfTxs>3", 1), ErrInvalidMessage},
+		{"control sum mismatch", strings.Replace(sample, "1500.75", "1500.74", 1), ErrInvalidMessage},
+		{"bad creation time", strings.Replace(sample, "2026-10-16T09:30:00+05:00", "yesterday", 1), ErrInvalidMessage},
+	} {
+		if _, err := ParseCreditTransfer([]byte(tc.doc)); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestCreditTransferTxValidate(t *testing.T) {
+	valid := CreditTransferTx{
+		EndToEndID: "E2E-1",
+		Amount:     Amount{Currency: "KZT", Value: "10.00"},
+		Debtor:     Party{Account: "a1"},
+		Creditor:   Party{Account: "a2"},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name   string
+		mutate func(*CreditTransferTx)
+		reason string
+	}{
+		{"no end-to-end id", func(t *CreditTransferTx) { t.EndToEndID = "" }, ReasonFormat},
+		{"lower-case currency", func(t *CreditTransferTx) { t.Amount.Currency = "kzt" }, ReasonCurrency},
+		{"zero amount", func(t *CreditTransferTx) { t.Amount.Value = "0.00" }, ReasonInvalidAmount},
+		{"negative amount", func(t *CreditTransferTx) { t.Amount.Value = "-1" }, ReasonInvalidAmount},
+		{"no debtor account", func(t *CreditTransferTx) { t.Debtor.Account = "" }, ReasonIncorrectAccount},
+		{"no creditor account", func(t *CreditTransferTx) { t.Creditor.Account = "" }, ReasonCreditorAccount},
+		{"bad bic", func(t *CreditTransferTx) { t.DebtorAgent.BIC = "KZBA" }, ReasonFormat},
+		{"bad charge bearer", func(t *CreditTransferTx) { t.ChargeBearer = "OUR" }, ReasonFormat},
+	} {
+		tx := valid
+		tc.mutate(&tx)
+		err := tx.Validate()
+		var re *ReasonError
+		if !errors.As(err, &re) || re.Code != tc.reason {
+			t.Errorf("%s: expected reason %s, got %v", tc.name, tc.reason, err)
+			continue
+		}
+		if st := tx.Reject(err); st.Status != StatusRejected || st.Reason != tc.reason || st.OriginalEndToEndID != tx.EndToEndID {
+			t.Errorf("%s: unexpected status %+v", tc.name, st)
+		}
+	}
+}
+
+func TestStatusReportRoundTrip(t *testing.T) {
+	created := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	ok := CreditTransferTx{InstrID: "I1", EndToEndID: "E1", TxID: "T1"}
+	bad := CreditTransferTx{EndToEndID: "E2"}
+	rep := StatusReport{
+		MsgID:             "QAZNA-RPT-1",
+		CreatedAt:         created,
+		OriginalMsgID:     "KZBANK-20261016-0001",
+		OriginalMessage:   Pacs008,
+		OriginalCreatedAt: created.Add(-time.Hour),
+		Transactions: []TransactionStatus{
+			ok.Settled(ledger.Transaction{ID: "01JA00000000000000000000TX", CreatedAt: created.Add(-time.Minute)}),
+			bad.Reject(&ReasonError{Code: ReasonInsufficientFunds, Message: "insufficient funds"}),
+			{OriginalEndToEndID: "E3", Status: StatusPending, AdditionalInfo: "held for review"},
+		},
+	}
+	rep.GroupStatus = GroupStatus(rep.Transactions)
+	if rep.GroupStatus != StatusPartial {
+		t.Fatalf("expected a partial group status, got %s", rep.GroupStatus)
+	}
+	out, err := MarshalStatusReport(rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := MessageName(out); name != Pacs002 {
+		t.Fatalf("expected a %s document, got %s", Pacs002, name)
+	}
+	again, err := ParseStatusReport(out)
+	if err != nil {
+		t.Fatalf("re-reading the report: %v\n%s", err, out)
+	}
+	if !reflect.DeepEqual(rep, again) {
+		t.Fatalf("round trip changed the report:\n%+v\n%+v", rep, again)
+	}
+
+	rep = StatusReport{MsgID: "QAZNA-RPT-2", CreatedAt: created, Transactions: rep.Transactions[:1]}
+	out, _ = MarshalStatusReport(rep)
+	if strings.Contains(string(out), "OrgnlGrpInfAndSts") {
+		t.Fatalf("expected no original group information:\n%s", out)
+	}
+	if again, err := ParseStatusReport(out); err != nil || !reflect.DeepEqual(rep, again) {
+		t.Fatalf("round trip changed the report: %+v %v", again, err)
+	}
+	if GroupStatus(rep.Transactions) != StatusSettled {
+		t.Fatalf("expected a settled group status")
+	}
+}
+
+func TestFromTransaction(t *testing.T) {
+	at := time.Date(2026, 10, 16, 13, 45, 0, 0, time.UTC)
+	plain := ledger.Transaction{ID: "tx1", CreatedAt: at, FromAccountID: "a1", ToAccountID: "a2", Currency: "KZT", Amount: 125050, IdempotencyKey: "E2E-INV-4411"}
+	tx, ok := FromTransaction(plain, 2)
+	if !ok || tx.EndToEndID != "E2E-INV-4411" || tx.TxID != "tx1" || tx.Amount != (Amount{Currency: "KZT", Value: "1250.50"}) ||
+		tx.Debtor.Account != "a1" || tx.Creditor.Account != "a2" || !tx.SettlementDate.Equal(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+	if err := tx.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if st := tx.Settled(plain); st.Status != StatusSettled || st.ClearingRef != "tx1" || st.OriginalEndToEndID != "E2E-INV-4411" || !st.AcceptedAt.Equal(at) {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+
+	plain.IdempotencyKey = ""
+	if tx, _ := FromTransaction(plain, 2); tx.EndToEndID != NotProvided {
+		t.Fatalf("expected NOTPROVIDED without an idempotency key, got %q", tx.EndToEndID)
+	}
+
+	fee := ledger.Fee{AccountID: "fees", Currency: "KZT", Amount: 150}
+	withFee := ledger.PlanFeeTransfer("a1", "a2", ledger.Money{Currency: "KZT", Amount: 25025}, fee)
+	withFee.ID = "tx2"
+	tx, ok = FromTransaction(withFee, 2)
+	if !ok || tx.Amount.Value != "250.25" || tx.ChargeBearer != "DEBT" || len(tx.Charges) != 1 ||
+		tx.Charges[0].Amount.Value != "1.50" || tx.Charges[0].Agent.ID != "fees" || tx.Creditor.Account != "a2" {
+		t.Fatalf("unexpected transaction with fee: %+v", tx)
+	}
+
+	for _, other := range []ledger.Transaction{
+		{ID: "m", FromAccountID: "issuer", ToAccountID: "a1", Currency: "KZT", Amount: 1, Kind: ledger.KindMint},
+		{ID: "r", FromAccountID: "a2", ToAccountID: "a1", Currency: "KZT", Amount: 1, ReversalOf: "tx1"},
+		{ID: "b", Entries: []ledger.Entry{{AccountID: "a1", Direction: ledger.Debit, Currency: "KZT", Amount: 1}, {AccountID: "a2", Direction: ledger.Credit, Currency: "KZT", Amount: 1}}},
+	} {
+		if _, ok := FromTransaction(other, 2); ok {
+			t.Fatalf("expected %s not to map to a credit transfer", other.ID)
+		}
+	}
+}
+
+func TestLedgerReason(t *testing.T) {
+	for err, want := range map[error]string{
+		ledger.ErrInsufficientFunds: ReasonInsufficientFunds,
+		ledger.ErrAccountFrozen:     ReasonBlockedAccount,
+		ledger.ErrNotFound:          ReasonIncorrectAccount,
+		ledger.ErrLimitExceeded:     ReasonAmountLimit,
+		errors.New("disk full"):     ReasonNarrative,
+	} {
+		if got := LedgerReason(err); got != want {
+			t.Errorf("LedgerReason(%v) = %s, want %s", err, got, want)
+		}
+	}
+}
+
+// fixtureLedger is the history of one account read from testdata.
+type fixtureLedger struct {
+	Account      ledger.Account       `json:"account"`
+	Currency     string               `json:"currency"`
+	Exponent     int                  `json:"exponent"`
+	Funding      int64                `json:"funding"`
+	Transactions []ledger.Transaction `json:"transactions"`
+}
+
+// statement builds the statement of the fixture account over [from, to)
+// the way GenerateStatement does against a live ledger.
+func (l fixtureLedger) statement(message string, from, to time.Time) Statement {
+	in := StatementInput{
+		Message:  message,
+		Account:  StatementAccount{ID: l.Account.ID, Currency: l.Currency, Name: l.Account.DisplayName},
+		From:     from,
+		To:       to,
+		Opening:  l.Funding,
+		Exponent: l.Exponent,
+	}
+	for _, tx := range l.Transactions {
+		switch {
+		case tx.CreatedAt.Before(from):
+			for _, leg := range tx.Legs() {
+				if leg.AccountID == l.Account.ID && leg.Currency == l.Currency && leg.Direction == ledger.Debit {
+					in.Opening -= leg.Amount
+				} else if leg.AccountID == l.Account.ID && leg.Currency == l.Currency {
+					in.Opening += leg.Amount
+				}
+			}
+		case tx.CreatedAt.Before(to):
+			in.Transactions = append(in.Transactions, tx)
+		}
+	}
+	s := BuildStatement(in)
+	s.MsgID, s.CreatedAt = "STMT-1", to.Add(2*time.Hour)
+	return s
+}
+
+func readFixtureLedger(t *testing.T) fixtureLedger {
+	t.Helper()
+	raw, err := os.ReadFile("testdata/ledger.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var l fixtureLedger
+	if err := json.Unmarshal(raw, &l); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestBuildStatement(t *testing.T) {
+	l := readFixtureLedger(t)
+	day := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	s := l.statement(Camt053, day, day.AddDate(0, 0, 1))
+
+	open, _ := s.Balance(BalanceOpeningBooked)
+	closing, _ := s.Balance(BalanceClosingBooked)
+	if open.Amount.Value != "800.00" || open.Indicator != CreditIndicator || !open.Date.Equal(day) {
+		t.Fatalf("unexpected opening balance: %+v", open)
+	}
+	if closing.Amount.Value != "248.50" || closing.Indicator != CreditIndicator || !closing.Date.Equal(day) {
+		t.Fatalf("unexpected closing balance: %+v", closing)
+	}
+	want := Summary{
+		Entries:      Total{Count: 4, Sum: "591.50"},
+		Credits:      Total{Count: 1, Sum: "20.00"},
+		Debits:       Total{Count: 3, Sum: "571.50"},
+		Net:          "551.50",
+		NetIndicator: DebitIndicator,
+	}
+	if s.Summary != want {
+		t.Fatalf("unexpected summary: %+v", s.Summary)
+	}
+	for i, e := range []struct {
+		ref, value, ind, counterparty string
+		code                          BankTransactionCode
+		reversal                      bool
+	}{
+		{"3-1", "100.00", DebitIndicator, "acc-gamma", CodeIssuedTransfer, false},
+		{"3-3", "1.50", DebitIndicator, "acc-fees", CodeCharges, false},
+		{"4-2", "20.00", CreditIndicator, "acc-beta", CodeReceivedTransfer, true},
+		{"5-1", "470.00", DebitIndicator, "fx-kzt", CodeForeignExchange, false},
+	} {
+		got := s.Entries[i]
+		if got.Reference != e.ref || got.Amount.Value != e.value || got.Indicator != e.ind || got.Counterparty != e.counterparty ||
+			got.Code != e.code || got.Reversal != e.reversal || got.Status != StatusBooked {
+			t.Fatalf("entry %d: unexpected %+v", i+1, got)
+		}
+	}
+	if s.Entries[0].EndToEndID != "INV-2" || s.Entries[2].EndToEndID != NotProvided || s.Entries[2].Info != "duplicate invoice" {
+		t.Fatalf("unexpected references: %+v", s.Entries)
+	}
+
+	// An intraday report ends on an interim balance at the time it covers.
+	cut := day.Add(11 * time.Hour)
+	r := l.statement(Camt052, day, cut)
+	interim, ok := r.Balance(BalanceInterimBooked)
+	if !ok || interim.Amount.Value != "718.50" || !interim.Date.Equal(cut) || len(r.Entries) != 3 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+
+	// An overdrawn account closes on a debit balance.
+	l.Funding = -100000
+	if closing, _ := l.statement(Camt053, day, day.AddDate(0, 0, 1)).Balance(BalanceClosingBooked); closing.Amount.Value != "1751.50" ||
+		closing.Indicator != DebitIndicator {
+		t.Fatalf("unexpected closing balance: %+v", closing)
+	}
+}
+
+func TestStatementRoundTrip(t *testing.T) {
+	l := readFixtureLedger(t)
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for _, s := range []Statement{
+		l.statement(Camt053, from, from.AddDate(0, 0, 3)),
+		l.statement(Camt052, from.AddDate(0, 0, 1), from.AddDate(0, 0, 1).Add(11*time.Hour)),
+	} {
+		s.Account.Owner, s.Account.Servicer = "Alpha Bank", Agent{BIC: "ALPHKZKA", ID: "org-alpha"}
+		out, err := MarshalStatement(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name, _ := MessageName(out); name != s.Message {
+			t.Fatalf("expected %s, rendered %s", s.Message, name)
+		}
+		again, err := ParseStatement(out)
+		if err != nil {
+			t.Fatalf("%s: re-reading the rendered statement: %v\n%s", s.Message, err, out)
+		}
+		// Statement IDs default to the message ID, and end-of-day balances
+		// are dated without a time.
+		s.ID = s.MsgID
+		if !reflect.DeepEqual(s, again) {
+			t.Fatalf("%s: round trip changed the statement:\n%+v\n%+v", s.Message, s, again)
+		}
+	}
+
+	s := l.statement(Camt053, from, from.AddDate(0, 0, 3))
+	s.Summary.Debits.Count++
+	out, _ := MarshalStatement(s)
+	if _, err := ParseStatement(out); !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("expected totals not matching the entries to be rejected, got %v", err)
+	}
+	if _, err := ParseStatement([]byte(strings.Replace(string(out), Camt053, Pacs008, 1))); !errors.Is(err, ErrUnsupportedMessage) {
+		t.Fatalf("expected a pacs.008 document to be refused, got %v", err)
+	}
+}
+
+func TestGenerateStatement(t *testing.T) {
+	ctx := context.Background()
+	l := ledger.NewInMemory()
+	from := time.Now().UTC().Add(-time.Hour)
+	alpha, err := l.CreateAccount(ctx, ledger.Money{Currency: "KZT", Amount: 100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, _ := l.CreateAccount(ctx, ledger.Money{Currency: "KZT", Amount: 0})
+	for i, amt := range []int64{25000, 1250, 999} {
+		if _, err := l.Transfer(ctx, alpha.ID, beta.ID, ledger.Money{Currency: "KZT", Amount: amt}, "stmt-"+strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := l.Transfer(ctx, beta.ID, alpha.ID, ledger.Money{Currency: "KZT", Amount: 5000}, ""); err != nil {
+		t.Fatal(err)
+	}
+	to := time.Now().UTC().Add(time.Hour)
+
+	s, err := GenerateStatement(ctx, l, Camt052, alpha.ID, "KZT", from, to, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bal, _ := l.GetBalance(ctx, alpha.ID, "KZT")
+	// The account opened within the period, so its funding opens it.
+	open, _ := s.Balance(BalanceOpeningBooked)
+	closing, _ := s.Balance(BalanceInterimBooked)
+	if open.Amount.Value != "1000.00" || closing.Amount.Value != ledger.FormatAmount(bal.Amount, 2) || len(s.Entries) != 4 ||
+		s.Summary.Debits.Count != 3 || s.Summary.Credits.Count != 1 {
+		t.Fatalf("unexpected statement: %+v", s)
+	}
+
+	// A later period opens on the balance the earlier one closed on.
+	later, err := GenerateStatement(ctx, l, Camt052, alpha.ID, "KZT", to, to.Add(time.Hour), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open, _ := later.Balance(BalanceOpeningBooked); open.Amount != closing.Amount || len(later.Entries) != 0 {
+		t.Fatalf("unexpected later statement: %+v", later)
+	}
+	if _, err := GenerateStatement(ctx, l, Camt053, "missing", "KZT", from, to, 2); !errors.Is(err, ledger.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
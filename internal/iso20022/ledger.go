@@ -0,0 +1,142 @@
+package iso20022
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+// LedgerReason returns the status reason code for a failed ledger posting,
+// NARR for errors without one.
+func LedgerReason(err error) string {
+	switch {
+	case errors.Is(err, ledger.ErrNotFound):
+		return ReasonIncorrectAccount
+	case errors.Is(err, ledger.ErrAccountClosed):
+		return ReasonClosedAccount
+	case errors.Is(err, ledger.ErrAccountFrozen):
+		return ReasonBlockedAccount
+	case errors.Is(err, ledger.ErrInsufficientFunds):
+		return ReasonInsufficientFunds
+	case errors.Is(err, ledger.ErrInvalidCurrency):
+		return ReasonCurrency
+	case errors.Is(err, ledger.ErrInvalidAmount):
+		return ReasonInvalidAmount
+	case errors.Is(err, ledger.ErrLimitExceeded):
+		return ReasonAmountLimit
+	case errors.Is(err, ledger.ErrIssuanceAccount):
+		return ReasonForbidden
+	default:
+		return ReasonNarrative
+	}
+}
+
+// FromTransaction maps a committed transfer to a credit transfer
+// transaction with exp fractional digits. Only transfers between two
+// accounts map, with their fee as a charge borne by the debtor; mints,
+// burns, reversals, FX transfers and other batch postings report false.
+//
+// The idempotency key becomes the EndToEndId, NOTPROVIDED when there is
+// none or it is too long, and the transaction ID the TxId.
+func FromTransaction(tx ledger.Transaction, exp int) (CreditTransferTx, bool) {
+	if tx.Kind != "" || tx.ReversalOf != "" {
+		return CreditTransferTx{}, false
+	}
+	from, to, amt := tx.FromAccountID, tx.ToAccountID, ledger.Money{Currency: tx.Currency, Amount: tx.Amount}
+	if len(tx.Entries) > 0 {
+		// A transfer with a fee: the transfer legs come first.
+		if tx.Fee == nil || len(tx.Entries) != 4 {
+			return CreditTransferTx{}, false
+		}
+		from, to = tx.Entries[0].AccountID, tx.Entries[1].AccountID
+		amt = ledger.Money{Currency: tx.Entries[0].Currency, Amount: tx.Entries[0].Amount}
+	}
+	t := CreditTransferTx{
+		EndToEndID:     tx.IdempotencyKey,
+		TxID:           tx.ID,
+		Amount:         AmountOf(amt, exp),
+		SettlementDate: tx.CreatedAt.UTC().Truncate(24 * time.Hour),
+		ChargeBearer:   "SLEV",
+		Debtor:         Party{Account: from},
+		Creditor:       Party{Account: to},
+	}
+	if t.EndToEndID == "" || len(t.EndToEndID) > 35 {
+		t.EndToEndID = NotProvided
+	}
+	if tx.Fee != nil && tx.Fee.Amount > 0 {
+		t.ChargeBearer = "DEBT"
+		t.Charges = []Charge{{
+			Amount: AmountOf(ledger.Money{Currency: tx.Fee.Currency, Amount: tx.Fee.Amount}, exp),
+			Agent:  Agent{ID: tx.Fee.AccountID},
+		}}
+	}
+	return t, true
+}
+
+// Settled returns the status report entry for a transaction committed as
+// tx.
+func (t CreditTransferTx) Settled(tx ledger.Transaction) TransactionStatus {
+	s := t.Original(StatusSettled)
+	s.AcceptedAt, s.ClearingRef = tx.CreatedAt, tx.ID
+	return s
+}
+
+// MaxStatementEntries bounds the transactions one statement covers.
+const MaxStatementEntries = 10000
+
+// ErrStatementTooLarge is returned for periods with more than
+// MaxStatementEntries transactions.
+var ErrStatementTooLarge = fmt.Errorf("statement period has more than %d transactions", MaxStatementEntries)
+
+// GenerateStatement builds the message statement of accountID in currency
+// over [from, to) from svc, with exp fractional digits. The opening balance
+// is recomputed as of just before from; for an account opened within the
+// period it includes the initial funding. The account must be visible under
+// ctx.
+func GenerateStatement(ctx context.Context, svc ledger.Service, message, accountID, currency string, from, to time.Time, exp int) (Statement, error) {
+	acc, err := svc.GetAccount(ctx, accountID)
+	if err != nil {
+		return Statement{}, err
+	}
+	openAt := from.Add(-time.Nanosecond)
+	if !acc.CreatedAt.Before(from) && acc.CreatedAt.Before(to) {
+		openAt = acc.CreatedAt
+	}
+	opening, err := svc.GetBalanceAt(ctx, accountID, currency, ledger.BalancePoint{Time: openAt})
+	if err != nil {
+		return Statement{}, err
+	}
+	in := StatementInput{
+		Message:  message,
+		Account:  StatementAccount{ID: acc.ID, Currency: currency, Name: acc.DisplayName},
+		From:     from,
+		To:       to,
+		Opening:  opening.Amount,
+		Exponent: exp,
+	}
+	f := ledger.TransactionFilter{Currency: currency, From: from, To: to, Limit: 1000}
+	for {
+		page, next, err := svc.ListAccountTransactions(ctx, accountID, f)
+		if err != nil {
+			return Statement{}, err
+		}
+		for _, tx := range page {
+			// Transactions committed with the account's opening are in the
+			// opening balance already.
+			if tx.Sequence > opening.Sequence {
+				in.Transactions = append(in.Transactions, tx)
+			}
+		}
+		if len(in.Transactions) > MaxStatementEntries {
+			return Statement{}, ErrStatementTooLarge
+		}
+		if len(page) < f.Limit {
+			break
+		}
+		f.AfterSeq = next
+	}
+	return BuildStatement(in), nil
+}
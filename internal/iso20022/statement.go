@@ -0,0 +1,658 @@
+package iso20022
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+// Account statement definitions: camt.053 end-of-day statements and camt.052
+// intraday account reports.
+const (
+	Camt052 = "camt.052.001.08"
+	Camt053 = "camt.053.001.08"
+)
+
+// Balance type codes (ExternalBalanceType1Code) of statements.
+const (
+	BalanceOpeningBooked = "OPBD"
+	BalanceClosingBooked = "CLBD"
+	BalanceInterimBooked = "ITBD"
+)
+
+// Credit and debit indicators of balances and entries.
+const (
+	CreditIndicator = "CRDT"
+	DebitIndicator  = "DBIT"
+)
+
+// StatusBooked is the status of an entry posted to the account.
+const StatusBooked = "BOOK"
+
+// Statement is a camt.053 statement or camt.052 report on one account in
+// one currency, covering From inclusive to To exclusive.
+type Statement struct {
+	Message   string // Camt053 or Camt052
+	MsgID     string
+	CreatedAt time.Time
+	// ID identifies the statement within the message; MsgID when empty.
+	ID       string
+	From, To time.Time
+	Account  StatementAccount
+	Balances []Balance
+	Summary  Summary
+	Entries  []StatementEntry
+}
+
+// StatementAccount is the account a statement reports on, with its holder
+// and the institution servicing it.
+type StatementAccount struct {
+	ID       string
+	Currency string
+	Name     string
+	Owner    string
+	Servicer Agent
+}
+
+// Balance is a balance of the account at Date. Amount is never negative;
+// Indicator tells a debit balance from a credit one.
+type Balance struct {
+	Type      string
+	Amount    Amount
+	Indicator string
+	Date      time.Time
+}
+
+// Summary totals the entries of a statement.
+type Summary struct {
+	Entries Total
+	Credits Total
+	Debits  Total
+	// Net is the credits less the debits, positive with NetIndicator.
+	Net          string
+	NetIndicator string
+}
+
+// Total counts entries and sums their amounts.
+type Total struct {
+	Count int
+	Sum   string
+}
+
+// StatementEntry is one posting to the account.
+type StatementEntry struct {
+	// Reference is unique within the statement.
+	Reference string
+	Amount    Amount
+	Indicator string
+	Reversal  bool
+	Status    string
+	BookedAt  time.Time
+	ValueDate time.Time
+	// ServicerRef is the ledger transaction ID.
+	ServicerRef string
+	Code        BankTransactionCode
+	EndToEndID  string
+	// Counterparty is the account on the other side of the posting, empty
+	// when there is no single one.
+	Counterparty string
+	Info         string
+}
+
+// BankTransactionCode classifies an entry by domain, family and sub-family.
+type BankTransactionCode struct {
+	Domain    string
+	Family    string
+	SubFamily string
+}
+
+// Bank transaction codes of ledger postings.
+var (
+	CodeReceivedTransfer = BankTransactionCode{"PMNT", "RCDT", "OTHR"}
+	CodeIssuedTransfer   = BankTransactionCode{"PMNT", "ICDT", "OTHR"}
+	CodeCharges          = BankTransactionCode{"ACMT", "MDOP", "CHRG"}
+	CodeForeignExchange  = BankTransactionCode{"FORX", "SPOT", "OTHR"}
+	CodeMiscCredit       = BankTransactionCode{"ACMT", "MCOP", "OTHR"}
+	CodeMiscDebit        = BankTransactionCode{"ACMT", "MDOP", "OTHR"}
+)
+
+// StatementInput is what a statement is built from.
+type StatementInput struct {
+	Message string
+	Account StatementAccount
+	From    time.Time
+	To      time.Time
+	// Opening is the balance in minor units before the first transaction.
+	Opening int64
+	// Transactions are those posted in the period, in sequence order.
+	Transactions []ledger.Transaction
+	// Exponent is the number of fractional digits of the currency.
+	Exponent int
+}
+
+// BuildStatement lays out a statement from its opening balance and the
+// transactions of the period: an entry for every leg that moved the account
+// in its currency, the totals of those entries, and a closing balance
+// (interim in a camt.052 report) of the opening balance plus their net.
+// MsgID and CreatedAt are left to the caller.
+func BuildStatement(in StatementInput) Statement {
+	cur, exp := in.Account.Currency, in.Exponent
+	s := Statement{Message: in.Message, From: in.From, To: in.To, Account: in.Account}
+	var credits, debits int64
+	for _, tx := range in.Transactions {
+		legs := tx.Legs()
+		for i, leg := range legs {
+			if leg.AccountID != in.Account.ID || leg.Currency != cur {
+				continue
+			}
+			e := StatementEntry{
+				Reference:    strconv.FormatUint(tx.Sequence, 10) + "-" + strconv.Itoa(i+1),
+				Amount:       AmountOf(ledger.Money{Currency: cur, Amount: leg.Amount}, exp),
+				Indicator:    CreditIndicator,
+				Reversal:     tx.ReversalOf != "",
+				Status:       StatusBooked,
+				BookedAt:     tx.CreatedAt,
+				ValueDate:    tx.CreatedAt.UTC().Truncate(24 * time.Hour),
+				ServicerRef:  tx.ID,
+				Code:         entryCode(tx, i, leg.Direction),
+				EndToEndID:   tx.IdempotencyKey,
+				Counterparty: counterparty(legs, i),
+				Info:         tx.ReversalReason,
+			}
+			if e.EndToEndID == "" || len(e.EndToEndID) > 35 {
+				e.EndToEndID = NotProvided
+			}
+			if leg.Direction == ledger.Debit {
+				e.Indicator = DebitIndicator
+				debits += leg.Amount
+				s.Summary.Debits.Count++
+			} else {
+				credits += leg.Amount
+				s.Summary.Credits.Count++
+			}
+			s.Entries = append(s.Entries, e)
+		}
+	}
+	s.Summary.Entries = Total{Count: len(s.Entries), Sum: ledger.FormatAmount(credits+debits, exp)}
+	s.Summary.Credits.Sum = ledger.FormatAmount(credits, exp)
+	s.Summary.Debits.Sum = ledger.FormatAmount(debits, exp)
+	s.Summary.Net, s.Summary.NetIndicator = signed(credits-debits, exp)
+
+	closing, closingType, closingDate := in.Opening+credits-debits, BalanceInterimBooked, in.To
+	if in.Message == Camt053 {
+		// An end-of-day statement closes on the last day it covers.
+		closingType, closingDate = BalanceClosingBooked, in.To.Add(-time.Nanosecond).UTC().Truncate(24*time.Hour)
+	}
+	s.Balances = []Balance{
+		balance(BalanceOpeningBooked, cur, in.Opening, exp, in.From),
+		balance(closingType, cur, closing, exp, closingDate),
+	}
+	return s
+}
+
+func balance(typ, currency string, amount int64, exp int, date time.Time) Balance {
+	v, ind := signed(amount, exp)
+	return Balance{Type: typ, Amount: Amount{Currency: currency, Value: v}, Indicator: ind, Date: date}
+}
+
+// signed renders amount as a magnitude and a credit or debit indicator.
+func signed(amount int64, exp int) (string, string) {
+	if amount < 0 {
+		return ledger.FormatAmount(-amount, exp), DebitIndicator
+	}
+	return ledger.FormatAmount(amount, exp), CreditIndicator
+}
+
+// entryCode classifies leg i of tx.
+func entryCode(tx ledger.Transaction, i int, dir ledger.Direction) BankTransactionCode {
+	switch {
+	case tx.Fee != nil && len(tx.Entries) == 4 && i >= 2:
+		return CodeCharges
+	case tx.FXRateID != "":
+		return CodeForeignExchange
+	case tx.Kind != "" && dir == ledger.Credit:
+		return CodeMiscCredit
+	case tx.Kind != "":
+		return CodeMiscDebit
+	case dir == ledger.Credit:
+		return CodeReceivedTransfer
+	default:
+		return CodeIssuedTransfer
+	}
+}
+
+// counterparty returns the account leg i was posted against: its partner
+// when the legs come in matching debit and credit pairs, as transfers, fees
+// and FX conversions post them, otherwise the only account moved the other
+// way in the same currency.
+func counterparty(legs []ledger.Entry, i int) string {
+	leg := legs[i]
+	if len(legs)%2 == 0 {
+		if p := legs[i^1]; p.Direction != leg.Direction && p.Currency == leg.Currency && p.Amount == leg.Amount {
+			return p.AccountID
+		}
+	}
+	other := ""
+	for _, p := range legs {
+		if p.Direction == leg.Direction || p.Currency != leg.Currency || p.AccountID == leg.AccountID {
+			continue
+		}
+		if other != "" && other != p.AccountID {
+			return ""
+		}
+		other = p.AccountID
+	}
+	return other
+}
+
+// Balance returns the balance of type typ.
+func (s Statement) Balance(typ string) (Balance, bool) {
+	for _, b := range s.Balances {
+		if b.Type == typ {
+			return b, true
+		}
+	}
+	return Balance{}, false
+}
+
+// MarshalStatement renders s as a camt.053 or camt.052 document.
+func MarshalStatement(s Statement) ([]byte, error) {
+	if s.Message != Camt053 && s.Message != Camt052 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMessage, s.Message)
+	}
+	id := s.ID
+	if id == "" {
+		id = s.MsgID
+	}
+	w := wireStatement{
+		ID:      id,
+		CreDtTm: formatDateTime(s.CreatedAt),
+		FrToDt:  &wirePeriod{FrDtTm: formatDateTime(s.From), ToDtTm: formatDateTime(s.To)},
+		Acct: wireCashAccount{
+			Ccy: s.Account.Currency,
+			Nm:  s.Account.Name,
+		},
+		TxsSummry: &wireTxsSummary{
+			TtlNtries: wireTotalNet{
+				NbOfNtries: strconv.Itoa(s.Summary.Entries.Count),
+				Sum:        s.Summary.Entries.Sum,
+				TtlNetNtry: &wireNet{Amt: s.Summary.Net, CdtDbtInd: s.Summary.NetIndicator},
+			},
+			TtlCdtNtries: wireTotal{NbOfNtries: strconv.Itoa(s.Summary.Credits.Count), Sum: s.Summary.Credits.Sum},
+			TtlDbtNtries: wireTotal{NbOfNtries: strconv.Itoa(s.Summary.Debits.Count), Sum: s.Summary.Debits.Sum},
+		},
+	}
+	w.Acct.ID.Othr = &wireOther{ID: s.Account.ID}
+	if s.Account.Owner != "" {
+		w.Acct.Ownr = &wireOwner{Nm: s.Account.Owner}
+	}
+	if s.Account.Servicer != (Agent{}) {
+		w.Acct.Svcr = writeAgent(s.Account.Servicer)
+	}
+	for _, b := range s.Balances {
+		wb := wireBalance{
+			Amt:       wireAmount{Ccy: b.Amount.Currency, Value: b.Amount.Value},
+			CdtDbtInd: b.Indicator,
+			Dt:        writeDate(s.Message, b.Date),
+		}
+		wb.Tp.CdOrPrtry.Cd = b.Type
+		w.Bal = append(w.Bal, wb)
+	}
+	for _, e := range s.Entries {
+		w.Ntry = append(w.Ntry, writeEntry(e))
+	}
+	hdr := wireStatusHeader{MsgID: s.MsgID, CreDtTm: formatDateTime(s.CreatedAt)}
+	if s.Message == Camt052 {
+		return encode(s.Message, document{Report: &wireStatementMessage{GrpHdr: hdr, Rpt: []wireStatement{w}}})
+	}
+	return encode(s.Message, document{Statement: &wireStatementMessage{GrpHdr: hdr, Stmt: []wireStatement{w}}})
+}
+
+// writeDate writes the date of an end-of-day balance and the date and time
+// of an intraday one.
+func writeDate(message string, t time.Time) wireDate {
+	if message == Camt053 {
+		return wireDate{Dt: t.UTC().Format(time.DateOnly)}
+	}
+	return wireDate{DtTm: formatDateTime(t)}
+}
+
+func writeEntry(e StatementEntry) wireEntry {
+	w := wireEntry{
+		NtryRef:      e.Reference,
+		Amt:          wireAmount{Ccy: e.Amount.Currency, Value: e.Amount.Value},
+		CdtDbtInd:    e.Indicator,
+		Sts:          wireReasonCode{Cd: e.Status},
+		AcctSvcrRef:  e.ServicerRef,
+		AddtlNtryInf: e.Info,
+	}
+	if e.Reversal {
+		w.RvslInd = "true"
+	}
+	if !e.BookedAt.IsZero() {
+		w.BookgDt = &wireDate{DtTm: formatDateTime(e.BookedAt)}
+	}
+	if !e.ValueDate.IsZero() {
+		w.ValDt = &wireDate{Dt: e.ValueDate.UTC().Format(time.DateOnly)}
+	}
+	w.BkTxCd.Domn.Cd = e.Code.Domain
+	w.BkTxCd.Domn.Fmly.Cd = e.Code.Family
+	w.BkTxCd.Domn.Fmly.SubFmlyCd = e.Code.SubFamily
+
+	tx := wireEntryTx{
+		Refs:      wireRefs{AcctSvcrRef: e.ServicerRef, EndToEndID: e.EndToEndID},
+		Amt:       &wireAmount{Ccy: e.Amount.Currency, Value: e.Amount.Value},
+		CdtDbtInd: e.Indicator,
+	}
+	if e.Counterparty != "" {
+		// The counterparty paid a credit and received a debit.
+		if e.Indicator == CreditIndicator {
+			tx.RltdPties = &wireRelatedParties{DbtrAcct: writeAccount(e.Counterparty)}
+		} else {
+			tx.RltdPties = &wireRelatedParties{CdtrAcct: writeAccount(e.Counterparty)}
+		}
+	}
+	w.NtryDtls = []wireEntryDetails{{TxDtls: []wireEntryTx{tx}}}
+	return w
+}
+
+// ParseStatement reads a camt.053 statement or camt.052 report carrying one
+// statement and checks its totals, when given, against its entries.
+func ParseStatement(data []byte) (Statement, error) {
+	name, doc, err := decode(data, Camt053, Camt052)
+	if err != nil {
+		return Statement{}, err
+	}
+	m, root, child := doc.Statement, "BkToCstmrStmt", "Stmt"
+	if name == Camt052 {
+		m, root, child = doc.Report, "BkToCstmrAcctRpt", "Rpt"
+	}
+	if m == nil {
+		return Statement{}, fmt.Errorf("%w: %s has no %s", ErrInvalidMessage, name, root)
+	}
+	stmts := m.Stmt
+	if name == Camt052 {
+		stmts = m.Rpt
+	}
+	if len(stmts) != 1 {
+		return Statement{}, fmt.Errorf("%w: expected one %s, got %d", ErrInvalidMessage, child, len(stmts))
+	}
+	w := stmts[0]
+	s := Statement{Message: name, MsgID: strings.TrimSpace(m.GrpHdr.MsgID), ID: strings.TrimSpace(w.ID)}
+	if s.CreatedAt, err = parseDateTime(m.GrpHdr.CreDtTm); err != nil {
+		return Statement{}, fmt.Errorf("%w: CreDtTm: %v", ErrInvalidMessage, err)
+	}
+	if w.FrToDt != nil {
+		if s.From, err = parseDateTime(w.FrToDt.FrDtTm); err != nil {
+			return Statement{}, fmt.Errorf("%w: FrDtTm: %v", ErrInvalidMessage, err)
+		}
+		if s.To, err = parseDateTime(w.FrToDt.ToDtTm); err != nil {
+			return Statement{}, fmt.Errorf("%w: ToDtTm: %v", ErrInvalidMessage, err)
+		}
+	}
+	s.Account = StatementAccount{
+		ID:       readParty(wireParty{}, &wireAccount{ID: w.Acct.ID}).Account,
+		Currency: strings.TrimSpace(w.Acct.Ccy),
+		Name:     strings.TrimSpace(w.Acct.Nm),
+		Servicer: readAgent(w.Acct.Svcr),
+	}
+	if w.Acct.Ownr != nil {
+		s.Account.Owner = strings.TrimSpace(w.Acct.Ownr.Nm)
+	}
+	for _, wb := range w.Bal {
+		b := Balance{
+			Type:      strings.TrimSpace(wb.Tp.CdOrPrtry.Cd),
+			Amount:    Amount{Currency: strings.TrimSpace(wb.Amt.Ccy), Value: strings.TrimSpace(wb.Amt.Value)},
+			Indicator: strings.TrimSpace(wb.CdtDbtInd),
+		}
+		if b.Date, err = readDate(wb.Dt); err != nil {
+			return Statement{}, fmt.Errorf("%w: balance %s: %v", ErrInvalidMessage, b.Type, err)
+		}
+		s.Balances = append(s.Balances, b)
+	}
+	for i, we := range w.Ntry {
+		e, err := readEntry(we)
+		if err != nil {
+			return Statement{}, fmt.Errorf("%w: entry %d: %v", ErrInvalidMessage, i+1, err)
+		}
+		s.Entries = append(s.Entries, e)
+	}
+	if t := w.TxsSummry; t != nil {
+		s.Summary = Summary{
+			Entries: Total{Sum: strings.TrimSpace(t.TtlNtries.Sum)},
+			Credits: Total{Sum: strings.TrimSpace(t.TtlCdtNtries.Sum)},
+			Debits:  Total{Sum: strings.TrimSpace(t.TtlDbtNtries.Sum)},
+		}
+		for _, c := range []struct {
+			raw string
+			dst *int
+		}{
+			{t.TtlNtries.NbOfNtries, &s.Summary.Entries.Count},
+			{t.TtlCdtNtries.NbOfNtries, &s.Summary.Credits.Count},
+			{t.TtlDbtNtries.NbOfNtries, &s.Summary.Debits.Count},
+		} {
+			if raw := strings.TrimSpace(c.raw); raw != "" {
+				if *c.dst, err = strconv.Atoi(raw); err != nil {
+					return Statement{}, fmt.Errorf("%w: NbOfNtries %q", ErrInvalidMessage, raw)
+				}
+			}
+		}
+		if n := t.TtlNtries.TtlNetNtry; n != nil {
+			s.Summary.Net, s.Summary.NetIndicator = strings.TrimSpace(n.Amt), strings.TrimSpace(n.CdtDbtInd)
+		}
+		if err := s.checkSummary(); err != nil {
+			return Statement{}, err
+		}
+	}
+	return s, nil
+}
+
+// checkSummary compares the totals given in a statement with its entries.
+func (s Statement) checkSummary() error {
+	var credits, debits Total
+	creditSum, debitSum := new(big.Rat), new(big.Rat)
+	for _, e := range s.Entries {
+		v, ok := new(big.Rat).SetString(e.Amount.Value)
+		if !ok {
+			return fmt.Errorf("%w: entry %s has invalid amount %q", ErrInvalidMessage, e.Reference, e.Amount.Value)
+		}
+		if e.Indicator == DebitIndicator {
+			debits.Count++
+			debitSum.Add(debitSum, v)
+		} else {
+			credits.Count++
+			creditSum.Add(creditSum, v)
+		}
+	}
+	all := new(big.Rat).Add(creditSum, debitSum)
+	for _, c := range []struct {
+		name  string
+		given Total
+		count int
+		sum   *big.Rat
+	}{
+		{"TtlNtries", s.Summary.Entries, len(s.Entries), all},
+		{"TtlCdtNtries", s.Summary.Credits, credits.Count, creditSum},
+		{"TtlDbtNtries", s.Summary.Debits, debits.Count, debitSum},
+	} {
+		if c.given.Sum == "" {
+			continue
+		}
+		sum, ok := new(big.Rat).SetString(c.given.Sum)
+		if !ok || sum.Cmp(c.sum) != 0 || c.given.Count != c.count {
+			return fmt.Errorf("%w: %s does not match the entries", ErrInvalidMessage, c.name)
+		}
+	}
+	return nil
+}
+
+func readDate(w wireDate) (time.Time, error) {
+	if d := strings.TrimSpace(w.Dt); d != "" {
+		return time.Parse(time.DateOnly, d)
+	}
+	return parseDateTime(w.DtTm)
+}
+
+func readEntry(w wireEntry) (StatementEntry, error) {
+	e := StatementEntry{
+		Reference:   strings.TrimSpace(w.NtryRef),
+		Amount:      Amount{Currency: strings.TrimSpace(w.Amt.Ccy), Value: strings.TrimSpace(w.Amt.Value)},
+		Indicator:   strings.TrimSpace(w.CdtDbtInd),
+		Reversal:    strings.TrimSpace(w.RvslInd) == "true",
+		Status:      strings.TrimSpace(w.Sts.Cd),
+		ServicerRef: strings.TrimSpace(w.AcctSvcrRef),
+		Code: BankTransactionCode{
+			Domain:    strings.TrimSpace(w.BkTxCd.Domn.Cd),
+			Family:    strings.TrimSpace(w.BkTxCd.Domn.Fmly.Cd),
+			SubFamily: strings.TrimSpace(w.BkTxCd.Domn.Fmly.SubFmlyCd),
+		},
+		Info: strings.TrimSpace(w.AddtlNtryInf),
+	}
+	var err error
+	if w.BookgDt != nil {
+		if e.BookedAt, err = readDate(*w.BookgDt); err != nil {
+			return StatementEntry{}, fmt.Errorf("BookgDt: %v", err)
+		}
+	}
+	if w.ValDt != nil {
+		if e.ValueDate, err = readDate(*w.ValDt); err != nil {
+			return StatementEntry{}, fmt.Errorf("ValDt: %v", err)
+		}
+	}
+	if len(w.NtryDtls) > 0 && len(w.NtryDtls[0].TxDtls) > 0 {
+		tx := w.NtryDtls[0].TxDtls[0]
+		e.EndToEndID = strings.TrimSpace(tx.Refs.EndToEndID)
+		if p := tx.RltdPties; p != nil {
+			acct := p.CdtrAcct
+			if e.Indicator == CreditIndicator {
+				acct = p.DbtrAcct
+			}
+			e.Counterparty = readParty(wireParty{}, acct).Account
+		}
+	}
+	return e, nil
+}
+
+// The types below mirror the statement schemas, in schema element order.
+// camt.052 reports share the layout of camt.053 statements.
+
+type wireStatementMessage struct {
+	GrpHdr wireStatusHeader `xml:"GrpHdr"`
+	Stmt   []wireStatement  `xml:"Stmt"`
+	Rpt    []wireStatement  `xml:"Rpt"`
+}
+
+type wireStatement struct {
+	ID        string          `xml:"Id"`
+	CreDtTm   string          `xml:"CreDtTm"`
+	FrToDt    *wirePeriod     `xml:"FrToDt"`
+	Acct      wireCashAccount `xml:"Acct"`
+	Bal       []wireBalance   `xml:"Bal"`
+	TxsSummry *wireTxsSummary `xml:"TxsSummry"`
+	Ntry      []wireEntry     `xml:"Ntry"`
+}
+
+type wirePeriod struct {
+	FrDtTm string `xml:"FrDtTm"`
+	ToDtTm string `xml:"ToDtTm"`
+}
+
+type wireCashAccount struct {
+	ID struct {
+		IBAN string     `xml:"IBAN,omitempty"`
+		Othr *wireOther `xml:"Othr"`
+	} `xml:"Id"`
+	Ccy  string     `xml:"Ccy,omitempty"`
+	Nm   string     `xml:"Nm,omitempty"`
+	Ownr *wireOwner `xml:"Ownr"`
+	Svcr *wireAgent `xml:"Svcr"`
+}
+
+type wireOwner struct {
+	Nm string `xml:"Nm"`
+}
+
+type wireBalance struct {
+	Tp struct {
+		CdOrPrtry struct {
+			Cd string `xml:"Cd"`
+		} `xml:"CdOrPrtry"`
+	} `xml:"Tp"`
+	Amt       wireAmount `xml:"Amt"`
+	CdtDbtInd string     `xml:"CdtDbtInd"`
+	Dt        wireDate   `xml:"Dt"`
+}
+
+type wireDate struct {
+	Dt   string `xml:"Dt,omitempty"`
+	DtTm string `xml:"DtTm,omitempty"`
+}
+
+type wireTxsSummary struct {
+	TtlNtries    wireTotalNet `xml:"TtlNtries"`
+	TtlCdtNtries wireTotal    `xml:"TtlCdtNtries"`
+	TtlDbtNtries wireTotal    `xml:"TtlDbtNtries"`
+}
+
+type wireTotalNet struct {
+	NbOfNtries string   `xml:"NbOfNtries,omitempty"`
+	Sum        string   `xml:"Sum,omitempty"`
+	TtlNetNtry *wireNet `xml:"TtlNetNtry"`
+}
+
+type wireNet struct {
+	Amt       string `xml:"Amt"`
+	CdtDbtInd string `xml:"CdtDbtInd"`
+}
+
+type wireTotal struct {
+	NbOfNtries string `xml:"NbOfNtries,omitempty"`
+	Sum        string `xml:"Sum,omitempty"`
+}
+
+type wireEntry struct {
+	NtryRef     string         `xml:"NtryRef,omitempty"`
+	Amt         wireAmount     `xml:"Amt"`
+	CdtDbtInd   string         `xml:"CdtDbtInd"`
+	RvslInd     string         `xml:"RvslInd,omitempty"`
+	Sts         wireReasonCode `xml:"Sts"`
+	BookgDt     *wireDate      `xml:"BookgDt"`
+	ValDt       *wireDate      `xml:"ValDt"`
+	AcctSvcrRef string         `xml:"AcctSvcrRef,omitempty"`
+	BkTxCd      struct {
+		Domn struct {
+			Cd   string `xml:"Cd"`
+			Fmly struct {
+				Cd        string `xml:"Cd"`
+				SubFmlyCd string `xml:"SubFmlyCd"`
+			} `xml:"Fmly"`
+		} `xml:"Domn"`
+	} `xml:"BkTxCd"`
+	NtryDtls     []wireEntryDetails `xml:"NtryDtls"`
+	AddtlNtryInf string             `xml:"AddtlNtryInf,omitempty"`
+}
+
+type wireEntryDetails struct {
+	TxDtls []wireEntryTx `xml:"TxDtls"`
+}
+
+type wireEntryTx struct {
+	Refs      wireRefs            `xml:"Refs"`
+	Amt       *wireAmount         `xml:"Amt"`
+	CdtDbtInd string              `xml:"CdtDbtInd,omitempty"`
+	RltdPties *wireRelatedParties `xml:"RltdPties"`
+}
+
+type wireRefs struct {
+	AcctSvcrRef string `xml:"AcctSvcrRef,omitempty"`
+	EndToEndID  string `xml:"EndToEndId,omitempty"`
+}
+
+type wireRelatedParties struct {
+	DbtrAcct *wireAccount `xml:"DbtrAcct"`
+	CdtrAcct *wireAccount `xml:"CdtrAcct"`
+}
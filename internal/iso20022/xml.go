@@ -0,0 +1,493 @@
+package iso20022
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The types below mirror the subset of the message schemas this package
+// reads and writes, in schema element order.
+
+type document struct {
+	XMLName    xml.Name
+	CustomerCT *wireCreditTransfer   `xml:"FIToFICstmrCdtTrf,omitempty"`
+	FICT       *wireCreditTransfer   `xml:"FICdtTrf,omitempty"`
+	Status     *wireStatusReport     `xml:"FIToFIPmtStsRpt,omitempty"`
+	Statement  *wireStatementMessage `xml:"BkToCstmrStmt,omitempty"`
+	Report     *wireStatementMessage `xml:"BkToCstmrAcctRpt,omitempty"`
+}
+
+type wireCreditTransfer struct {
+	GrpHdr      wireGroupHeader   `xml:"GrpHdr"`
+	CdtTrfTxInf []wireCreditTxInf `xml:"CdtTrfTxInf"`
+}
+
+type wireGroupHeader struct {
+	MsgID    string          `xml:"MsgId"`
+	CreDtTm  string          `xml:"CreDtTm"`
+	NbOfTxs  string          `xml:"NbOfTxs"`
+	CtrlSum  string          `xml:"CtrlSum,omitempty"`
+	SttlmInf *wireSettlement `xml:"SttlmInf"`
+}
+
+type wireSettlement struct {
+	SttlmMtd string `xml:"SttlmMtd"`
+}
+
+type wireCreditTxInf struct {
+	PmtID          wirePaymentID `xml:"PmtId"`
+	IntrBkSttlmAmt wireAmount    `xml:"IntrBkSttlmAmt"`
+	IntrBkSttlmDt  string        `xml:"IntrBkSttlmDt,omitempty"`
+	ChrgBr         string        `xml:"ChrgBr,omitempty"`
+	ChrgsInf       []wireCharge  `xml:"ChrgsInf"`
+	Dbtr           wireParty     `xml:"Dbtr"`
+	DbtrAcct       *wireAccount  `xml:"DbtrAcct"`
+	DbtrAgt        *wireAgent    `xml:"DbtrAgt"`
+	CdtrAgt        *wireAgent    `xml:"CdtrAgt"`
+	Cdtr           wireParty     `xml:"Cdtr"`
+	CdtrAcct       *wireAccount  `xml:"CdtrAcct"`
+	RmtInf         *wireRemit    `xml:"RmtInf"`
+}
+
+type wirePaymentID struct {
+	InstrID    string `xml:"InstrId,omitempty"`
+	EndToEndID string `xml:"EndToEndId"`
+	TxID       string `xml:"TxId,omitempty"`
+	UETR       string `xml:"UETR,omitempty"`
+}
+
+type wireAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type wireCharge struct {
+	Amt wireAmount `xml:"Amt"`
+	Agt wireAgent  `xml:"Agt"`
+}
+
+// wireParty is a pacs.008 party, named by Nm, or a pacs.009 institution
+// under FinInstnId.
+type wireParty struct {
+	Nm         string          `xml:"Nm,omitempty"`
+	FinInstnID *wireFinInstnID `xml:"FinInstnId"`
+}
+
+type wireAgent struct {
+	FinInstnID wireFinInstnID `xml:"FinInstnId"`
+}
+
+type wireFinInstnID struct {
+	BICFI string     `xml:"BICFI,omitempty"`
+	Nm    string     `xml:"Nm,omitempty"`
+	Othr  *wireOther `xml:"Othr"`
+}
+
+type wireAccount struct {
+	ID struct {
+		IBAN string     `xml:"IBAN,omitempty"`
+		Othr *wireOther `xml:"Othr"`
+	} `xml:"Id"`
+}
+
+type wireOther struct {
+	ID string `xml:"Id"`
+}
+
+type wireRemit struct {
+	Ustrd []string `xml:"Ustrd"`
+}
+
+type wireStatusReport struct {
+	GrpHdr            wireStatusHeader   `xml:"GrpHdr"`
+	OrgnlGrpInfAndSts *wireOriginalGroup `xml:"OrgnlGrpInfAndSts"`
+	TxInfAndSts       []wireTxInfAndSts  `xml:"TxInfAndSts"`
+}
+
+type wireStatusHeader struct {
+	MsgID   string `xml:"MsgId"`
+	CreDtTm string `xml:"CreDtTm"`
+}
+
+type wireOriginalGroup struct {
+	OrgnlMsgID   string `xml:"OrgnlMsgId"`
+	OrgnlMsgNmID string `xml:"OrgnlMsgNmId"`
+	OrgnlCreDtTm string `xml:"OrgnlCreDtTm,omitempty"`
+	GrpSts       string `xml:"GrpSts,omitempty"`
+}
+
+type wireTxInfAndSts struct {
+	OrgnlInstrID    string            `xml:"OrgnlInstrId,omitempty"`
+	OrgnlEndToEndID string            `xml:"OrgnlEndToEndId,omitempty"`
+	OrgnlTxID       string            `xml:"OrgnlTxId,omitempty"`
+	OrgnlUETR       string            `xml:"OrgnlUETR,omitempty"`
+	TxSts           string            `xml:"TxSts"`
+	StsRsnInf       *wireStatusReason `xml:"StsRsnInf"`
+	AccptncDtTm     string            `xml:"AccptncDtTm,omitempty"`
+	ClrSysRef       string            `xml:"ClrSysRef,omitempty"`
+}
+
+type wireStatusReason struct {
+	Rsn      *wireReasonCode `xml:"Rsn"`
+	AddtlInf string          `xml:"AddtlInf,omitempty"`
+}
+
+type wireReasonCode struct {
+	Cd string `xml:"Cd"`
+}
+
+// MessageName returns the message definition of an XML document, such as
+// "pacs.008.001.08", taken from its namespace.
+func MessageName(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local != "Document" || !strings.HasPrefix(start.Name.Space, namespacePrefix) {
+				return "", fmt.Errorf("%w: expected an ISO 20022 Document", ErrUnsupportedMessage)
+			}
+			return strings.TrimPrefix(start.Name.Space, namespacePrefix), nil
+		}
+	}
+}
+
+func decode(data []byte, want ...string) (string, document, error) {
+	name, err := MessageName(data)
+	if err != nil {
+		return "", document{}, err
+	}
+	supported := false
+	for _, w := range want {
+		supported = supported || name == w
+	}
+	if !supported {
+		return "", document{}, fmt.Errorf("%w: %s", ErrUnsupportedMessage, name)
+	}
+	var doc document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return "", document{}, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	return name, doc, nil
+}
+
+func encode(name string, doc document) ([]byte, error) {
+	doc.XMLName = xml.Name{Space: namespacePrefix + name, Local: "Document"}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// ParseCreditTransfer reads a pacs.008 or pacs.009 message and checks its
+// group header: a message ID, a creation time, a transaction count matching
+// the transactions and a control sum, when given, matching their amounts.
+// Transactions are left to CreditTransferTx.Validate, so one bad
+// transaction can be rejected on its own.
+func ParseCreditTransfer(data []byte) (CreditTransfer, error) {
+	name, doc, err := decode(data, Pacs008, Pacs009)
+	if err != nil {
+		return CreditTransfer{}, err
+	}
+	w := doc.CustomerCT
+	root := "FIToFICstmrCdtTrf"
+	if name == Pacs009 {
+		w, root = doc.FICT, "FICdtTrf"
+	}
+	if w == nil {
+		return CreditTransfer{}, fmt.Errorf("%w: %s has no %s", ErrInvalidMessage, name, root)
+	}
+	h := w.GrpHdr
+	ct := CreditTransfer{Message: name, MsgID: strings.TrimSpace(h.MsgID), ControlSum: strings.TrimSpace(h.CtrlSum)}
+	if ct.MsgID == "" || len(ct.MsgID) > 35 {
+		return CreditTransfer{}, fmt.Errorf("%w: MsgId must be 1-35 characters", ErrInvalidMessage)
+	}
+	if ct.CreatedAt, err = parseDateTime(h.CreDtTm); err != nil {
+		return CreditTransfer{}, fmt.Errorf("%w: CreDtTm: %v", ErrInvalidMessage, err)
+	}
+	if h.SttlmInf != nil {
+		ct.SettlementMethod = strings.TrimSpace(h.SttlmInf.SttlmMtd)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(h.NbOfTxs))
+	if err != nil || n != len(w.CdtTrfTxInf) {
+		return CreditTransfer{}, fmt.Errorf("%w: NbOfTxs must equal the %d transactions", ErrInvalidMessage, len(w.CdtTrfTxInf))
+	}
+	if n == 0 || n > MaxTransactions {
+		return CreditTransfer{}, fmt.Errorf("%w: a message carries 1-%d transactions", ErrInvalidMessage, MaxTransactions)
+	}
+	sum := new(big.Rat)
+	for i, wt := range w.CdtTrfTxInf {
+		t, err := readCreditTx(wt)
+		if err != nil {
+			return CreditTransfer{}, fmt.Errorf("%w: transaction %d: %v", ErrInvalidMessage, i+1, err)
+		}
+		if v, ok := new(big.Rat).SetString(t.Amount.Value); ok {
+			sum.Add(sum, v)
+		}
+		ct.Transactions = append(ct.Transactions, t)
+	}
+	if ct.ControlSum != "" {
+		want, ok := new(big.Rat).SetString(ct.ControlSum)
+		if !ok || want.Cmp(sum) != 0 {
+			return CreditTransfer{}, fmt.Errorf("%w: CtrlSum %s does not match the transactions", ErrInvalidMessage, ct.ControlSum)
+		}
+	}
+	return ct, nil
+}
+
+func readCreditTx(w wireCreditTxInf) (CreditTransferTx, error) {
+	t := CreditTransferTx{
+		InstrID:       strings.TrimSpace(w.PmtID.InstrID),
+		EndToEndID:    strings.TrimSpace(w.PmtID.EndToEndID),
+		TxID:          strings.TrimSpace(w.PmtID.TxID),
+		UETR:          strings.TrimSpace(w.PmtID.UETR),
+		Amount:        Amount{Currency: strings.TrimSpace(w.IntrBkSttlmAmt.Ccy), Value: strings.TrimSpace(w.IntrBkSttlmAmt.Value)},
+		ChargeBearer:  strings.TrimSpace(w.ChrgBr),
+		Debtor:        readParty(w.Dbtr, w.DbtrAcct),
+		DebtorAgent:   readAgent(w.DbtrAgt),
+		CreditorAgent: readAgent(w.CdtrAgt),
+		Creditor:      readParty(w.Cdtr, w.CdtrAcct),
+	}
+	if d := strings.TrimSpace(w.IntrBkSttlmDt); d != "" {
+		date, err := time.Parse(time.DateOnly, d)
+		if err != nil {
+			return CreditTransferTx{}, fmt.Errorf("IntrBkSttlmDt: %v", err)
+		}
+		t.SettlementDate = date
+	}
+	for _, c := range w.ChrgsInf {
+		t.Charges = append(t.Charges, Charge{
+			Amount: Amount{Currency: strings.TrimSpace(c.Amt.Ccy), Value: strings.TrimSpace(c.Amt.Value)},
+			Agent:  readAgent(&c.Agt),
+		})
+	}
+	if w.RmtInf != nil {
+		t.Remittance = strings.TrimSpace(strings.Join(w.RmtInf.Ustrd, " "))
+	}
+	return t, nil
+}
+
+func readParty(w wireParty, acct *wireAccount) Party {
+	p := Party{Name: strings.TrimSpace(w.Nm)}
+	if w.FinInstnID != nil {
+		p.BIC = strings.TrimSpace(w.FinInstnID.BICFI)
+		if p.Name == "" {
+			p.Name = strings.TrimSpace(w.FinInstnID.Nm)
+		}
+	}
+	if acct != nil {
+		if acct.ID.Othr != nil {
+			p.Account = strings.TrimSpace(acct.ID.Othr.ID)
+		} else {
+			p.Account = strings.TrimSpace(acct.ID.IBAN)
+		}
+	}
+	return p
+}
+
+func readAgent(w *wireAgent) Agent {
+	if w == nil {
+		return Agent{}
+	}
+	a := Agent{BIC: strings.TrimSpace(w.FinInstnID.BICFI)}
+	if w.FinInstnID.Othr != nil {
+		a.ID = strings.TrimSpace(w.FinInstnID.Othr.ID)
+	}
+	if a.ID == NotProvided {
+		a.ID = ""
+	}
+	return a
+}
+
+// MarshalCreditTransfer renders ct as a pacs.008 or pacs.009 document.
+// NbOfTxs is derived from the transactions.
+func MarshalCreditTransfer(ct CreditTransfer) ([]byte, error) {
+	if ct.Message != Pacs008 && ct.Message != Pacs009 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMessage, ct.Message)
+	}
+	method := ct.SettlementMethod
+	if method == "" {
+		method = "CLRG"
+	}
+	w := &wireCreditTransfer{GrpHdr: wireGroupHeader{
+		MsgID:    ct.MsgID,
+		CreDtTm:  formatDateTime(ct.CreatedAt),
+		NbOfTxs:  strconv.Itoa(len(ct.Transactions)),
+		CtrlSum:  ct.ControlSum,
+		SttlmInf: &wireSettlement{SttlmMtd: method},
+	}}
+	for _, t := range ct.Transactions {
+		w.CdtTrfTxInf = append(w.CdtTrfTxInf, writeCreditTx(ct.Message, t))
+	}
+	doc := document{CustomerCT: w}
+	if ct.Message == Pacs009 {
+		doc = document{FICT: w}
+	}
+	return encode(ct.Message, doc)
+}
+
+func writeCreditTx(message string, t CreditTransferTx) wireCreditTxInf {
+	w := wireCreditTxInf{
+		PmtID:          wirePaymentID{InstrID: t.InstrID, EndToEndID: t.EndToEndID, TxID: t.TxID, UETR: t.UETR},
+		IntrBkSttlmAmt: wireAmount{Ccy: t.Amount.Currency, Value: t.Amount.Value},
+		DbtrAcct:       writeAccount(t.Debtor.Account),
+		CdtrAcct:       writeAccount(t.Creditor.Account),
+	}
+	if message == Pacs008 || t.DebtorAgent != (Agent{}) {
+		w.DbtrAgt = writeAgent(t.DebtorAgent)
+	}
+	if message == Pacs008 || t.CreditorAgent != (Agent{}) {
+		w.CdtrAgt = writeAgent(t.CreditorAgent)
+	}
+	if !t.SettlementDate.IsZero() {
+		w.IntrBkSttlmDt = t.SettlementDate.Format(time.DateOnly)
+	}
+	if message == Pacs008 {
+		w.ChrgBr = t.ChargeBearer
+		if w.ChrgBr == "" {
+			w.ChrgBr = "SLEV"
+		}
+		for _, c := range t.Charges {
+			w.ChrgsInf = append(w.ChrgsInf, wireCharge{Amt: wireAmount{Ccy: c.Amount.Currency, Value: c.Amount.Value}, Agt: *writeAgent(c.Agent)})
+		}
+		w.Dbtr, w.Cdtr = wireParty{Nm: t.Debtor.Name}, wireParty{Nm: t.Creditor.Name}
+	} else {
+		w.Dbtr = wireParty{FinInstnID: &wireFinInstnID{BICFI: t.Debtor.BIC, Nm: t.Debtor.Name}}
+		w.Cdtr = wireParty{FinInstnID: &wireFinInstnID{BICFI: t.Creditor.BIC, Nm: t.Creditor.Name}}
+	}
+	if t.Remittance != "" {
+		w.RmtInf = &wireRemit{Ustrd: []string{t.Remittance}}
+	}
+	return w
+}
+
+func writeAccount(id string) *wireAccount {
+	if id == "" {
+		return nil
+	}
+	a := &wireAccount{}
+	a.ID.Othr = &wireOther{ID: id}
+	return a
+}
+
+// writeAgent always returns an agent, as pacs.008 requires DbtrAgt and
+// CdtrAgt. An unidentified agent is written as NOTPROVIDED.
+func writeAgent(a Agent) *wireAgent {
+	w := &wireAgent{FinInstnID: wireFinInstnID{BICFI: a.BIC}}
+	switch {
+	case a.ID != "":
+		w.FinInstnID.Othr = &wireOther{ID: a.ID}
+	case a.BIC == "":
+		w.FinInstnID.Othr = &wireOther{ID: NotProvided}
+	}
+	return w
+}
+
+// ParseStatusReport reads a pacs.002 message.
+func ParseStatusReport(data []byte) (StatusReport, error) {
+	_, doc, err := decode(data, Pacs002)
+	if err != nil {
+		return StatusReport{}, err
+	}
+	w := doc.Status
+	if w == nil {
+		return StatusReport{}, fmt.Errorf("%w: %s has no FIToFIPmtStsRpt", ErrInvalidMessage, Pacs002)
+	}
+	rep := StatusReport{MsgID: strings.TrimSpace(w.GrpHdr.MsgID)}
+	if rep.CreatedAt, err = parseDateTime(w.GrpHdr.CreDtTm); err != nil {
+		return StatusReport{}, fmt.Errorf("%w: CreDtTm: %v", ErrInvalidMessage, err)
+	}
+	if g := w.OrgnlGrpInfAndSts; g != nil {
+		rep.OriginalMsgID = strings.TrimSpace(g.OrgnlMsgID)
+		rep.OriginalMessage = strings.TrimSpace(g.OrgnlMsgNmID)
+		rep.GroupStatus = strings.TrimSpace(g.GrpSts)
+		if g.OrgnlCreDtTm != "" {
+			if rep.OriginalCreatedAt, err = parseDateTime(g.OrgnlCreDtTm); err != nil {
+				return StatusReport{}, fmt.Errorf("%w: OrgnlCreDtTm: %v", ErrInvalidMessage, err)
+			}
+		}
+	}
+	for _, t := range w.TxInfAndSts {
+		s := TransactionStatus{
+			OriginalInstrID:    strings.TrimSpace(t.OrgnlInstrID),
+			OriginalEndToEndID: strings.TrimSpace(t.OrgnlEndToEndID),
+			OriginalTxID:       strings.TrimSpace(t.OrgnlTxID),
+			OriginalUETR:       strings.TrimSpace(t.OrgnlUETR),
+			Status:             strings.TrimSpace(t.TxSts),
+			ClearingRef:        strings.TrimSpace(t.ClrSysRef),
+		}
+		if r := t.StsRsnInf; r != nil {
+			s.AdditionalInfo = strings.TrimSpace(r.AddtlInf)
+			if r.Rsn != nil {
+				s.Reason = strings.TrimSpace(r.Rsn.Cd)
+			}
+		}
+		if t.AccptncDtTm != "" {
+			if s.AcceptedAt, err = parseDateTime(t.AccptncDtTm); err != nil {
+				return StatusReport{}, fmt.Errorf("%w: AccptncDtTm: %v", ErrInvalidMessage, err)
+			}
+		}
+		rep.Transactions = append(rep.Transactions, s)
+	}
+	return rep, nil
+}
+
+// MarshalStatusReport renders rep as a pacs.002 document.
+func MarshalStatusReport(rep StatusReport) ([]byte, error) {
+	w := &wireStatusReport{GrpHdr: wireStatusHeader{MsgID: rep.MsgID, CreDtTm: formatDateTime(rep.CreatedAt)}}
+	if rep.OriginalMsgID != "" {
+		w.OrgnlGrpInfAndSts = &wireOriginalGroup{
+			OrgnlMsgID:   rep.OriginalMsgID,
+			OrgnlMsgNmID: rep.OriginalMessage,
+			GrpSts:       rep.GroupStatus,
+		}
+		if !rep.OriginalCreatedAt.IsZero() {
+			w.OrgnlGrpInfAndSts.OrgnlCreDtTm = formatDateTime(rep.OriginalCreatedAt)
+		}
+	}
+	for _, s := range rep.Transactions {
+		t := wireTxInfAndSts{
+			OrgnlInstrID:    s.OriginalInstrID,
+			OrgnlEndToEndID: s.OriginalEndToEndID,
+			OrgnlTxID:       s.OriginalTxID,
+			OrgnlUETR:       s.OriginalUETR,
+			TxSts:           s.Status,
+			ClrSysRef:       s.ClearingRef,
+		}
+		if s.Reason != "" || s.AdditionalInfo != "" {
+			t.StsRsnInf = &wireStatusReason{AddtlInf: s.AdditionalInfo}
+			if s.Reason != "" {
+				t.StsRsnInf.Rsn = &wireReasonCode{Cd: s.Reason}
+			}
+		}
+		if !s.AcceptedAt.IsZero() {
+			t.AccptncDtTm = formatDateTime(s.AcceptedAt)
+		}
+		w.TxInfAndSts = append(w.TxInfAndSts, t)
+	}
+	return encode(Pacs002, document{Status: w})
+}
+
+// parseDateTime reads an ISODateTime. A time without a zone is taken as
+// UTC.
+func parseDateTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not an ISO date-time", s)
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,121 @@
+package ledger
+
+import (
+	"errors"
+	"strings"
+)
+
+// AccountType classifies what an account is used for.
+type AccountType string
+
+const (
+	AccountTypeReserve    AccountType = "reserve"
+	AccountTypeSettlement AccountType = "settlement"
+	AccountTypeFee        AccountType = "fee"
+	AccountTypeSuspense   AccountType = "suspense"
+)
+
+// Valid reports whether t is a known account type.
+func (t AccountType) Valid() bool {
+	switch t {
+	case AccountTypeReserve, AccountTypeSettlement, AccountTypeFee, AccountTypeSuspense:
+		return true
+	}
+	return false
+}
+
+// AccountStatus is the lifecycle state of an account.
+//
+//	active ⇄ frozen → closed
+//
+// Frozen accounts can still be credited; closed accounts accept nothing.
+type AccountStatus string
+
+const (
+	AccountActive AccountStatus = "active"
+	AccountFrozen AccountStatus = "frozen"
+	AccountClosed AccountStatus = "closed"
+)
+
+var (
+	ErrInvalidAccountType   = errors.New("invalid account type")
+	ErrAccountLabelTooLong  = errors.New("account display name or external reference too long")
+	ErrInvalidAccountStatus = errors.New("invalid account status")
+	ErrAccountFrozen        = errors.New("account frozen")
+	ErrAccountClosed        = errors.New("account closed")
+	ErrAccountNotEmpty      = errors.New("account has non-zero balance")
+)
+
+const maxAccountLabel = 128
+
+// CheckDebit returns the error a debit from an account in status s fails with.
+func (s AccountStatus) CheckDebit() error {
+	switch s {
+	case AccountFrozen:
+		return ErrAccountFrozen
+	case AccountClosed:
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// CheckCredit returns the error a credit to an account in status s fails with.
+func (s AccountStatus) CheckCredit() error {
+	if s == AccountClosed {
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// CheckTransition validates moving an account from s to next. Closing also
+// requires zero balances, which the caller checks.
+func (s AccountStatus) CheckTransition(next AccountStatus) error {
+	switch next {
+	case AccountActive, AccountFrozen, AccountClosed:
+	default:
+		return ErrInvalidAccountStatus
+	}
+	if s == AccountClosed {
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// AccountOption sets an attribute of an account being created.
+type AccountOption func(*Account)
+
+func WithAccountType(t AccountType) AccountOption {
+	return func(a *Account) { a.Type = t }
+}
+
+func WithDisplayName(name string) AccountOption {
+	return func(a *Account) { a.DisplayName = name }
+}
+
+// WithExternalRef records the participant's own identifier for the account,
+// e.g. an IBAN or a core-banking account number.
+func WithExternalRef(ref string) AccountOption {
+	return func(a *Account) { a.ExternalRef = ref }
+}
+
+// AccountTemplate applies opts to an active settlement account and validates
+// the result. Service implementations fill in the id, owner and balances.
+func AccountTemplate(opts ...AccountOption) (Account, error) {
+	acc := Account{Type: AccountTypeSettlement, Status: AccountActive}
+	for _, opt := range opts {
+		opt(&acc)
+	}
+	acc.Type = AccountType(strings.ToLower(strings.TrimSpace(string(acc.Type))))
+	if acc.Type == "" {
+		acc.Type = AccountTypeSettlement
+	}
+	if !acc.Type.Valid() {
+		return Account{}, ErrInvalidAccountType
+	}
+	acc.DisplayName = strings.TrimSpace(acc.DisplayName)
+	acc.ExternalRef = strings.TrimSpace(acc.ExternalRef)
+	if len(acc.DisplayName) > maxAccountLabel || len(acc.ExternalRef) > maxAccountLabel {
+		return Account{}, ErrAccountLabelTooLong
+	}
+	return acc, nil
+}
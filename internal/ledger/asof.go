@@ -0,0 +1,105 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidBalancePoint is returned when a BalancePoint sets both or neither
+// of its fields.
+var ErrInvalidBalancePoint = errors.New("invalid balance point")
+
+// BalancePoint selects a point in ledger history. Sequence selects the state
+// right after that transaction committed; Time selects the state after every
+// transaction created at or before it. Exactly one must be set.
+type BalancePoint struct {
+	Sequence uint64
+	Time     time.Time
+}
+
+func (p BalancePoint) validate() error {
+	if (p.Sequence == 0) == p.Time.IsZero() {
+		return ErrInvalidBalancePoint
+	}
+	return nil
+}
+
+// HistoricalBalance is a balance recomputed from transaction history.
+// Sequence is the last ledger sequence the amount reflects.
+type HistoricalBalance struct {
+	Money
+	Sequence uint64 `json:"sequence"`
+}
+
+// delta returns how much tx changed the balance of accountID in currency.
+func (tx Transaction) delta(accountID, currency string) int64 {
+	var d int64
+	if len(tx.Entries) == 0 {
+		if tx.Currency != currency {
+			return 0
+		}
+		if tx.FromAccountID == accountID {
+			d -= tx.Amount
+		}
+		if tx.ToAccountID == accountID {
+			d += tx.Amount
+		}
+		return d
+	}
+	for _, e := range tx.Entries {
+		if e.AccountID != accountID || e.Currency != currency {
+			continue
+		}
+		if e.Direction == Debit {
+			d -= e.Amount
+		} else {
+			d += e.Amount
+		}
+	}
+	return d
+}
+
+// opening is the funding an account was created with, which is not recorded
+// as a transaction. seq is the ledger sequence at creation time.
+type opening struct {
+	Money
+	seq uint64
+}
+
+func (s *InMemory) GetBalanceAt(ctx context.Context, id, currency string, at BalancePoint) (HistoricalBalance, error) {
+	if err := at.validate(); err != nil {
+		return HistoricalBalance{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	acc, ok := s.accts[id]
+	if !ok || !Visible(ctx, acc.OrganizationID) {
+		return HistoricalBalance{}, ErrNotFound
+	}
+	out := HistoricalBalance{Money: Money{Currency: currency}}
+	if at.Sequence == 0 {
+		if at.Time.Before(acc.CreatedAt) {
+			return out, nil
+		}
+		for _, tx := range s.txs {
+			if tx.CreatedAt.After(at.Time) {
+				break
+			}
+			out.Sequence = tx.Sequence
+		}
+	} else {
+		out.Sequence = at.Sequence
+	}
+
+	if o := s.openings[id]; o.Currency == currency && o.seq <= out.Sequence {
+		out.Amount = o.Amount
+	}
+	for _, tx := range s.txs {
+		if tx.Sequence > out.Sequence {
+			break
+		}
+		out.Amount += tx.delta(id, currency)
+	}
+	return out, nil
+}
@@ -0,0 +1,252 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Currency kinds. ISO 4217 currencies are built in; QZN and any other
+// registered code is a digital currency.
+const (
+	CurrencyISO4217 = "iso4217"
+	CurrencyDigital = "digital"
+)
+
+// MaxCurrencyExponent bounds the number of minor-unit digits of a currency.
+const MaxCurrencyExponent = 18
+
+// Currency describes a currency the ledger accepts. Amounts are kept in
+// minor units; Exponent is the number of minor-unit digits, so an amount of
+// 12345 with exponent 2 reads 123.45. MinAmount and MaxAmount bound a single
+// posting or hold in minor units, zero meaning no bound.
+//
+// Every ISO 4217 currency and QZN are built in and enabled. Registering a
+// currency overrides the built-in definition or adds a new one.
+type Currency struct {
+	Code      string    `json:"code"`
+	Name      string    `json:"name,omitempty"`
+	Kind      string    `json:"kind"`
+	Exponent  int       `json:"exponent"`
+	Enabled   bool      `json:"enabled"`
+	MinAmount int64     `json:"min_amount,omitempty"`
+	MaxAmount int64     `json:"max_amount,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{2,7}$`)
+
+// Check validates a posting of amount minor units in c: the currency must be
+// enabled and the amount within its limits.
+func (c Currency) Check(amount int64) error {
+	if err := c.CheckEnabled(); err != nil {
+		return err
+	}
+	if c.MinAmount > 0 && amount < c.MinAmount {
+		return fmt.Errorf("%w: %s postings must be at least %s", ErrInvalidAmount, c.Code, c.Format(c.MinAmount))
+	}
+	if c.MaxAmount > 0 && amount > c.MaxAmount {
+		return fmt.Errorf("%w: %s postings must be at most %s", ErrInvalidAmount, c.Code, c.Format(c.MaxAmount))
+	}
+	return nil
+}
+
+// CheckEnabled fails with ErrInvalidCurrency when c is disabled.
+func (c Currency) CheckEnabled() error {
+	if !c.Enabled {
+		return fmt.Errorf("%w: %s is disabled", ErrInvalidCurrency, c.Code)
+	}
+	return nil
+}
+
+// Format renders amount minor units in major units, e.g. "-1234.50".
+func (c Currency) Format(amount int64) string {
+	return FormatAmount(amount, c.Exponent)
+}
+
+// FormatAmount renders amount minor units with exp fractional digits.
+func FormatAmount(amount int64, exp int) string {
+	neg := amount < 0
+	// Work on the unsigned magnitude so math.MinInt64 formats too.
+	mag := uint64(amount)
+	if neg {
+		mag = -mag
+	}
+	digits := strconv.FormatUint(mag, 10)
+	if exp > 0 {
+		if len(digits) <= exp {
+			digits = strings.Repeat("0", exp-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+	}
+	if neg {
+		return "-" + digits
+	}
+	return digits
+}
+
+// ParseAmount reads a non-negative major-unit decimal such as "123.45" into
+// minor units with exp fractional digits. Digits beyond exp must be zeros.
+func ParseAmount(s string, exp int) (int64, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if !allDigits(whole) || (strings.Contains(s, ".") && !allDigits(frac)) {
+		return 0, fmt.Errorf("%w: %q is not a decimal amount", ErrInvalidAmount, s)
+	}
+	if len(frac) > exp {
+		if strings.Trim(frac[exp:], "0") != "" {
+			return 0, fmt.Errorf("%w: %q has more than %d fractional digits", ErrInvalidAmount, s, exp)
+		}
+		frac = frac[:exp]
+	}
+	digits := strings.TrimLeft(whole+frac+strings.Repeat("0", exp-len(frac)), "0")
+	if digits == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, s)
+	}
+	return v, nil
+}
+
+func allDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// BuiltinCurrency returns the built-in definition of code.
+func BuiltinCurrency(code string) (Currency, bool) {
+	c, ok := builtinCurrencies[code]
+	return c, ok
+}
+
+// CurrencyKind classifies code: built-in ISO 4217 codes are CurrencyISO4217,
+// anything else is CurrencyDigital.
+func CurrencyKind(code string) string {
+	if b, ok := builtinCurrencies[code]; ok {
+		return b.Kind
+	}
+	return CurrencyDigital
+}
+
+// NormalizeCurrency validates a currency about to be registered: a code of 3
+// to 8 upper-case letters or digits starting with a letter, an exponent up to
+// MaxCurrencyExponent and non-negative limits with MinAmount <= MaxAmount.
+// The kind follows from the code.
+func NormalizeCurrency(c Currency, now time.Time) (Currency, error) {
+	c.Code = strings.ToUpper(strings.TrimSpace(c.Code))
+	if !currencyCodePattern.MatchString(c.Code) {
+		return Currency{}, ErrInvalidCurrency
+	}
+	c.Name = strings.TrimSpace(c.Name)
+	if len(c.Name) > 128 || c.Exponent < 0 || c.Exponent > MaxCurrencyExponent {
+		return Currency{}, ErrInvalidCurrency
+	}
+	if c.MinAmount < 0 || c.MaxAmount < 0 || (c.MaxAmount > 0 && c.MinAmount > c.MaxAmount) {
+		return Currency{}, ErrInvalidAmount
+	}
+	c.Kind = CurrencyKind(c.Code)
+	c.UpdatedAt = now.UTC()
+	return c, nil
+}
+
+// ResolveCurrency looks code up in the registered currencies first and the
+// built-in ones second. Unknown codes fail with ErrInvalidCurrency.
+func ResolveCurrency(registered map[string]Currency, code string) (Currency, error) {
+	if c, ok := registered[code]; ok {
+		return c, nil
+	}
+	if c, ok := builtinCurrencies[code]; ok {
+		return c, nil
+	}
+	return Currency{}, fmt.Errorf("%w: unknown currency %q", ErrInvalidCurrency, code)
+}
+
+// MergeCurrencies lays registered currencies over the built-in ones and
+// returns them ordered by code.
+func MergeCurrencies(registered map[string]Currency) []Currency {
+	out := make([]Currency, 0, len(builtinCurrencies)+len(registered))
+	for code, c := range builtinCurrencies {
+		if _, ok := registered[code]; !ok {
+			out = append(out, c)
+		}
+	}
+	for _, c := range registered {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+// CurrencyRegistry manages the currencies a ledger accepts. Currencies are
+// never deleted; disabling one rejects new postings in it while existing
+// balances stay readable and can still be reversed or captured.
+type CurrencyRegistry interface {
+	// ListCurrencies returns built-in and registered currencies ordered by
+	// code.
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	// GetCurrency fails with ErrNotFound for unknown codes.
+	GetCurrency(ctx context.Context, code string) (Currency, error)
+	// PutCurrency registers c, replacing any earlier definition of its code.
+	PutCurrency(ctx context.Context, c Currency) (Currency, error)
+}
+
+func (s *InMemory) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return MergeCurrencies(s.currencies), nil
+}
+
+func (s *InMemory) GetCurrency(ctx context.Context, code string) (Currency, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, err := ResolveCurrency(s.currencies, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return Currency{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (s *InMemory) PutCurrency(ctx context.Context, c Currency) (Currency, error) {
+	c, err := NormalizeCurrency(c, time.Now())
+	if err != nil {
+		return Currency{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.currencies[c.Code] = c
+	s.logLocked(walRecord{Currency: &c})
+	return c, nil
+}
+
+// checkCurrencyLocked validates a posting of amount minor units in code.
+// Callers must hold s.mu.
+func (s *InMemory) checkCurrencyLocked(code string, amount int64) error {
+	c, err := ResolveCurrency(s.currencies, code)
+	if err != nil {
+		return err
+	}
+	return c.Check(amount)
+}
+
+// checkLegsLocked validates the currency of every leg. Callers must hold
+// s.mu.
+func (s *InMemory) checkLegsLocked(entries []Entry) error {
+	for _, e := range entries {
+		if err := s.checkCurrencyLocked(e.Currency, e.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
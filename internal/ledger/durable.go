@@ -0,0 +1,604 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const snapshotFile = "snapshot.json"
+
+// Durable is an InMemory ledger persisted to a directory: every committed
+// change is appended to a write-ahead log and fsynced before the call
+// returns, and snapshots of the whole state let the log be truncated.
+// OpenDurable replays the latest snapshot and the log written after it.
+//
+// Intended for single-node deployments without Postgres. Only one process
+// may open a directory at a time.
+type Durable struct {
+	*InMemory
+
+	dir    string
+	wal    *wal
+	snapMu sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// DurableOption configures OpenDurable.
+type DurableOption func(*durableConfig)
+
+type durableConfig struct {
+	syncDelay        time.Duration
+	snapshotInterval time.Duration
+}
+
+// WithSyncDelay makes each fsync wait up to d for further commits, so they
+// share the sync. Zero (the default) still batches commits that arrive while
+// a sync is in flight.
+func WithSyncDelay(d time.Duration) DurableOption {
+	return func(c *durableConfig) { c.syncDelay = d }
+}
+
+// WithSnapshotInterval snapshots the ledger every d in the background. By
+// default snapshots are only taken by Snapshot and Close.
+func WithSnapshotInterval(d time.Duration) DurableOption {
+	return func(c *durableConfig) { c.snapshotInterval = d }
+}
+
+// snapshot is the serialized state of an InMemory ledger. Generation is the
+// first log generation not covered by it. Idempotency keys are restored from
+// the transactions and holds that carry them.
+type snapshot struct {
+	Generation   uint64            `json:"generation"`
+	Sequence     uint64            `json:"sequence"`
+	TakenAt      time.Time         `json:"taken_at"`
+	Accounts     []storedAccount   `json:"accounts"`
+	Transactions []Transaction     `json:"transactions"`
+	Holds        []Hold            `json:"holds"`
+	FXRates      []FXRate          `json:"fx_rates"`
+	FXLiquidity  map[string]string `json:"fx_liquidity"`
+	Currencies   []Currency        `json:"currencies,omitempty"`
+	Issuers      map[string]string `json:"issuers,omitempty"`
+	FeeSchedules []FeeSchedule     `json:"fee_schedules,omitempty"`
+	Limits       []Limit           `json:"limits,omitempty"`
+}
+
+// OpenDurable opens or creates the ledger stored in dir and recovers its
+// state. A record torn by a crash at the end of the newest log file is
+// discarded; damage anywhere else fails the open.
+func OpenDurable(dir string, opts ...DurableOption) (*Durable, error) {
+	var cfg durableConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	s := NewInMemory()
+	var gen uint64 = 1
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFile))
+	switch {
+	case err == nil:
+		var snap snapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return nil, fmt.Errorf("ledger snapshot: %w", err)
+		}
+		s.restoreSnapshot(snap)
+		gen = snap.Generation
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	gens, err := walGenerations(dir)
+	if err != nil {
+		return nil, err
+	}
+	next := gen
+	for i, g := range gens {
+		path := walPath(dir, g)
+		if g < gen {
+			// Covered by the snapshot; left over from an interrupted cleanup.
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		last := i == len(gens)-1
+		if _, err := readWAL(path, last, s.replayLocked); err != nil {
+			return nil, err
+		}
+		next = g + 1
+	}
+
+	// Start a fresh generation rather than appending after a repaired tail.
+	w, err := openWAL(dir, next, cfg.syncDelay)
+	if err != nil {
+		return nil, err
+	}
+	s.wal = w
+	d := &Durable{InMemory: s, dir: dir, wal: w}
+	if cfg.snapshotInterval > 0 {
+		d.stop = make(chan struct{})
+		d.done = make(chan struct{})
+		go d.snapshotLoop(cfg.snapshotInterval)
+	}
+	return d, nil
+}
+
+// Snapshot writes the current state and drops the log it supersedes. New
+// commits wait while the state is serialized.
+func (d *Durable) Snapshot() error {
+	d.snapMu.Lock()
+	defer d.snapMu.Unlock()
+
+	d.mu.Lock()
+	snap := d.snapshotLocked()
+	gen, err := d.wal.rotate()
+	var data []byte
+	if err == nil {
+		snap.Generation = gen
+		data, err = json.Marshal(snap)
+	}
+	d.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(d.dir, snapshotFile, data); err != nil {
+		return err
+	}
+	gens, err := walGenerations(d.dir)
+	if err != nil {
+		return err
+	}
+	for _, g := range gens {
+		if g < snap.Generation {
+			if err := os.Remove(walPath(d.dir, g)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close takes a final snapshot and closes the log. Later calls that change
+// the ledger fail with ErrClosed.
+func (d *Durable) Close() error {
+	if d.stop != nil {
+		close(d.stop)
+		<-d.done
+		d.stop = nil
+	}
+	err := d.Snapshot()
+	if cerr := d.wal.close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (d *Durable) snapshotLoop(interval time.Duration) {
+	defer close(d.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			if err := d.Snapshot(); err != nil {
+				log.Printf("ledger snapshot: %v", err)
+			}
+		}
+	}
+}
+
+// commit waits for the records of a successful call to reach disk.
+func (d *Durable) commit(err error) error {
+	if err != nil {
+		return err
+	}
+	return d.wal.sync()
+}
+
+func (d *Durable) CreateAccount(ctx context.Context, initial Money, opts ...AccountOption) (Account, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Account{}, err
+	}
+	acc, err := d.InMemory.CreateAccount(ctx, initial, opts...)
+	if err := d.commit(err); err != nil {
+		return Account{}, err
+	}
+	return acc, nil
+}
+
+func (d *Durable) SetAccountStatus(ctx context.Context, id string, status AccountStatus) (Account, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Account{}, err
+	}
+	acc, err := d.InMemory.SetAccountStatus(ctx, id, status)
+	if err := d.commit(err); err != nil {
+		return Account{}, err
+	}
+	return acc, nil
+}
+
+func (d *Durable) Transfer(ctx context.Context, fromID, toID string, amt Money, idemKey string) (Transaction, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Transaction{}, err
+	}
+	tx, err := d.InMemory.Transfer(ctx, fromID, toID, amt, idemKey)
+	if err := d.commit(err); err != nil {
+		return Transaction{}, err
+	}
+	return tx, nil
+}
+
+func (d *Durable) PostEntries(ctx context.Context, entries []Entry, idemKey string) (Transaction, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Transaction{}, err
+	}
+	tx, err := d.InMemory.PostEntries(ctx, entries, idemKey)
+	if err := d.commit(err); err != nil {
+		return Transaction{}, err
+	}
+	return tx, nil
+}
+
+func (d *Durable) Reverse(ctx context.Context, txID string, amount int64, reason, idemKey string) (Transaction, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Transaction{}, err
+	}
+	tx, err := d.InMemory.Reverse(ctx, txID, amount, reason, idemKey)
+	if err := d.commit(err); err != nil {
+		return Transaction{}, err
+	}
+	return tx, nil
+}
+
+func (d *Durable) CreateHold(ctx context.Context, fromID, toID string, amt Money, ttl time.Duration, idemKey string) (Hold, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Hold{}, err
+	}
+	h, err := d.InMemory.CreateHold(ctx, fromID, toID, amt, ttl, idemKey)
+	if err := d.commit(err); err != nil {
+		return Hold{}, err
+	}
+	return h, nil
+}
+
+func (d *Durable) CaptureHold(ctx context.Context, id string, amount int64) (Hold, Transaction, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Hold{}, Transaction{}, err
+	}
+	h, tx, err := d.InMemory.CaptureHold(ctx, id, amount)
+	if err := d.commit(err); err != nil {
+		return Hold{}, Transaction{}, err
+	}
+	return h, tx, nil
+}
+
+func (d *Durable) VoidHold(ctx context.Context, id string) (Hold, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Hold{}, err
+	}
+	h, err := d.InMemory.VoidHold(ctx, id)
+	if err := d.commit(err); err != nil {
+		return Hold{}, err
+	}
+	return h, nil
+}
+
+func (d *Durable) FXTransfer(ctx context.Context, fromID, toID string, amt Money, toCurrency, idemKey string) (Transaction, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Transaction{}, err
+	}
+	tx, err := d.InMemory.FXTransfer(ctx, fromID, toID, amt, toCurrency, idemKey)
+	if err := d.commit(err); err != nil {
+		return Transaction{}, err
+	}
+	return tx, nil
+}
+
+func (d *Durable) AddFXRate(ctx context.Context, r FXRate) (FXRate, error) {
+	if err := d.wal.healthy(); err != nil {
+		return FXRate{}, err
+	}
+	r, err := d.InMemory.AddFXRate(ctx, r)
+	if err := d.commit(err); err != nil {
+		return FXRate{}, err
+	}
+	return r, nil
+}
+
+func (d *Durable) ExpireFXRate(ctx context.Context, id string) (FXRate, error) {
+	if err := d.wal.healthy(); err != nil {
+		return FXRate{}, err
+	}
+	r, err := d.InMemory.ExpireFXRate(ctx, id)
+	if err := d.commit(err); err != nil {
+		return FXRate{}, err
+	}
+	return r, nil
+}
+
+func (d *Durable) PutCurrency(ctx context.Context, c Currency) (Currency, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Currency{}, err
+	}
+	c, err := d.InMemory.PutCurrency(ctx, c)
+	if err := d.commit(err); err != nil {
+		return Currency{}, err
+	}
+	return c, nil
+}
+
+func (d *Durable) SetFXLiquidityAccount(ctx context.Context, currency, accountID string) error {
+	if err := d.wal.healthy(); err != nil {
+		return err
+	}
+	return d.commit(d.InMemory.SetFXLiquidityAccount(ctx, currency, accountID))
+}
+
+func (d *Durable) Mint(ctx context.Context, issuerID string, amt Money, idemKey string) (Transaction, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Transaction{}, err
+	}
+	tx, err := d.InMemory.Mint(ctx, issuerID, amt, idemKey)
+	if err := d.commit(err); err != nil {
+		return Transaction{}, err
+	}
+	return tx, nil
+}
+
+func (d *Durable) Burn(ctx context.Context, issuerID string, amt Money, idemKey string) (Transaction, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Transaction{}, err
+	}
+	tx, err := d.InMemory.Burn(ctx, issuerID, amt, idemKey)
+	if err := d.commit(err); err != nil {
+		return Transaction{}, err
+	}
+	return tx, nil
+}
+
+func (d *Durable) SetIssuerAccount(ctx context.Context, currency, accountID string) error {
+	if err := d.wal.healthy(); err != nil {
+		return err
+	}
+	return d.commit(d.InMemory.SetIssuerAccount(ctx, currency, accountID))
+}
+
+func (d *Durable) AddFeeSchedule(ctx context.Context, fs FeeSchedule) (FeeSchedule, error) {
+	if err := d.wal.healthy(); err != nil {
+		return FeeSchedule{}, err
+	}
+	fs, err := d.InMemory.AddFeeSchedule(ctx, fs)
+	if err := d.commit(err); err != nil {
+		return FeeSchedule{}, err
+	}
+	return fs, nil
+}
+
+func (d *Durable) TransferWithFee(ctx context.Context, fromID, toID string, amt Money, participant, idemKey string) (Transaction, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Transaction{}, err
+	}
+	tx, err := d.InMemory.TransferWithFee(ctx, fromID, toID, amt, participant, idemKey)
+	if err := d.commit(err); err != nil {
+		return Transaction{}, err
+	}
+	return tx, nil
+}
+
+func (d *Durable) CaptureHoldWithFee(ctx context.Context, id string, amount int64, participant string) (Hold, Transaction, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Hold{}, Transaction{}, err
+	}
+	h, tx, err := d.InMemory.CaptureHoldWithFee(ctx, id, amount, participant)
+	if err := d.commit(err); err != nil {
+		return Hold{}, Transaction{}, err
+	}
+	return h, tx, nil
+}
+
+func (d *Durable) SetLimit(ctx context.Context, l Limit) (Limit, error) {
+	if err := d.wal.healthy(); err != nil {
+		return Limit{}, err
+	}
+	l, err := d.InMemory.SetLimit(ctx, l)
+	if err := d.commit(err); err != nil {
+		return Limit{}, err
+	}
+	return l, nil
+}
+
+func (d *Durable) DeleteLimit(ctx context.Context, k LimitKey) error {
+	if err := d.wal.healthy(); err != nil {
+		return err
+	}
+	return d.commit(d.InMemory.DeleteLimit(ctx, k))
+}
+
+// snapshotLocked copies the ledger state. Callers must hold s.mu.
+func (s *InMemory) snapshotLocked() snapshot {
+	snap := snapshot{
+		Sequence:     s.seq,
+		TakenAt:      time.Now().UTC(),
+		Accounts:     make([]storedAccount, 0, len(s.accts)),
+		Transactions: s.txs,
+		Holds:        make([]Hold, 0, len(s.holds)),
+		FXRates:      s.rates,
+		FXLiquidity:  s.liquidity,
+		Issuers:      s.issuers,
+		FeeSchedules: s.fees,
+	}
+	for _, c := range s.currencies {
+		snap.Currencies = append(snap.Currencies, c)
+	}
+	for _, l := range s.limits {
+		snap.Limits = append(snap.Limits, l)
+	}
+	for id, acc := range s.accts {
+		o := s.openings[id]
+		snap.Accounts = append(snap.Accounts, storedAccount{Account: *acc, Opening: o.Money, OpeningSeq: o.seq})
+	}
+	for _, h := range s.holds {
+		snap.Holds = append(snap.Holds, *h)
+	}
+	return snap
+}
+
+// restoreSnapshot loads snap into an empty ledger.
+func (s *InMemory) restoreSnapshot(snap snapshot) {
+	for _, sa := range snap.Accounts {
+		s.restoreAccount(sa)
+	}
+	for _, tx := range snap.Transactions {
+		s.restoreTx(tx)
+	}
+	for _, h := range snap.Holds {
+		s.restoreHold(h)
+	}
+	s.rates = append(s.rates, snap.FXRates...)
+	for currency, id := range snap.FXLiquidity {
+		s.liquidity[currency] = id
+	}
+	for _, c := range snap.Currencies {
+		s.currencies[c.Code] = c
+	}
+	for currency, id := range snap.Issuers {
+		s.issuers[currency] = id
+	}
+	s.fees = append(s.fees, snap.FeeSchedules...)
+	for _, l := range snap.Limits {
+		s.limits[l.Key()] = l
+	}
+	s.seq = snap.Sequence
+}
+
+// replayLocked applies a logged record on top of the recovered state.
+func (s *InMemory) replayLocked(rec walRecord) error {
+	if rec.Account != nil {
+		s.restoreAccount(*rec.Account)
+	}
+	if c := rec.Status; c != nil {
+		acc, ok := s.accts[c.AccountID]
+		if !ok {
+			return fmt.Errorf("status change for unknown account %s", c.AccountID)
+		}
+		acc.Status = c.Status
+	}
+	if rec.Tx != nil {
+		tx := *rec.Tx
+		for _, e := range tx.Legs() {
+			acc, ok := s.accts[e.AccountID]
+			if !ok {
+				return fmt.Errorf("transaction %s touches unknown account %s", tx.ID, e.AccountID)
+			}
+			if e.Direction == Debit {
+				acc.Balances[e.Currency] -= e.Amount
+			} else {
+				acc.Balances[e.Currency] += e.Amount
+			}
+		}
+		if tx.ReversalOf != "" {
+			if i, ok := s.index[tx.ReversalOf]; ok {
+				s.txs[i].SetReversed(s.txs[i].ReversedAmount + tx.Gross())
+			}
+		}
+		s.restoreTx(tx)
+		s.seq = tx.Sequence
+	}
+	if rec.Hold != nil {
+		s.restoreHold(*rec.Hold)
+	}
+	if r := rec.FXRate; r != nil {
+		replaced := false
+		for i := range s.rates {
+			if s.rates[i].ID == r.ID {
+				s.rates[i], replaced = *r, true
+			}
+		}
+		if !replaced {
+			s.rates = append(s.rates, *r)
+		}
+	}
+	if c := rec.Liquidity; c != nil {
+		s.liquidity[c.Currency] = c.AccountID
+	}
+	if c := rec.Currency; c != nil {
+		s.currencies[c.Code] = *c
+	}
+	if c := rec.Issuer; c != nil {
+		s.issuers[c.Currency] = c.AccountID
+	}
+	if fs := rec.FeeSchedule; fs != nil {
+		s.fees = append(s.fees, *fs)
+	}
+	if c := rec.Limit; c != nil {
+		if c.Deleted {
+			delete(s.limits, c.Limit.Key())
+		} else {
+			s.limits[c.Limit.Key()] = c.Limit
+		}
+	}
+	return nil
+}
+
+func (s *InMemory) restoreAccount(sa storedAccount) {
+	acc := copyAccount(&sa.Account)
+	s.accts[acc.ID] = &acc
+	s.openings[acc.ID] = opening{Money: sa.Opening, seq: sa.OpeningSeq}
+}
+
+// restoreTx appends tx to the journal as it was recorded.
+func (s *InMemory) restoreTx(tx Transaction) {
+	s.index[tx.ID] = len(s.txs)
+	if tx.IdempotencyKey != "" {
+		s.idem[tx.IdempotencyKey] = len(s.txs)
+	}
+	s.txs = append(s.txs, tx)
+}
+
+func (s *InMemory) restoreHold(h Hold) {
+	s.holds[h.ID] = &h
+	if h.Status == HoldPending {
+		s.pending[h.ID] = &h
+	} else {
+		delete(s.pending, h.ID)
+	}
+	if h.IdempotencyKey != "" {
+		s.holdIdem[h.IdempotencyKey] = h.ID
+	}
+}
+
+func writeFileAtomic(dir, name string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
@@ -0,0 +1,321 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"qazna.org/internal/auth"
+)
+
+var ErrInvalidFeeSchedule = errors.New("invalid fee schedule")
+
+// Rounding selects how a fee is rounded to whole minor units.
+type Rounding string
+
+const (
+	RoundHalfUp   Rounding = "half_up"
+	RoundHalfEven Rounding = "half_even"
+	RoundDown     Rounding = "down"
+	RoundUp       Rounding = "up"
+)
+
+// Bounds of the load (α) and stress (β) factors set by the fee model.
+var (
+	minAlpha, maxAlpha = big.NewRat(8, 10), big.NewRat(12, 10)
+	minBeta, maxBeta   = big.NewRat(9, 10), big.NewRat(13, 10)
+)
+
+// rateBounds are the ranges the fee model allows for the rate of each
+// participant type: sovereign members and retail payments are free,
+// certified institutions pay 0.01%–0.05% and corporate entities 0.1%–0.2%.
+var rateBounds = map[string][2]*big.Rat{
+	auth.ParticipantSovereign:   {new(big.Rat), new(big.Rat)},
+	auth.ParticipantInstitution: {big.NewRat(1, 10_000), big.NewRat(5, 10_000)},
+	auth.ParticipantCorporate:   {big.NewRat(1, 1_000), big.NewRat(2, 1_000)},
+	auth.ParticipantRetail:      {new(big.Rat), new(big.Rat)},
+}
+
+// FeeSchedule is one version of the transfer fee model: the payer pays
+//
+//	fee = amount × Rates[participant type] × Alpha × Beta
+//
+// rounded to minor units by Rounding, to the fee collection account
+// AccountID. Rates, Alpha and Beta are decimals; rates are fractions of the
+// amount, so 0.0005 is 0.05%. Participant types without a rate pay nothing.
+//
+// Schedules are immutable. Each new one gets the next Version and applies
+// from EffectiveFrom until a later version takes effect.
+type FeeSchedule struct {
+	Version       int               `json:"version"`
+	Rates         map[string]string `json:"rates"`
+	Alpha         string            `json:"alpha"`
+	Beta          string            `json:"beta"`
+	Rounding      Rounding          `json:"rounding"`
+	AccountID     string            `json:"account_id"`
+	EffectiveFrom time.Time         `json:"effective_from"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// Fee is the breakdown of the fee charged on a transfer: the inputs of the
+// schedule that applied and the resulting amount.
+type Fee struct {
+	ScheduleVersion int      `json:"schedule_version"`
+	ParticipantType string   `json:"participant_type"`
+	Rate            string   `json:"rate"`
+	Alpha           string   `json:"alpha"`
+	Beta            string   `json:"beta"`
+	Rounding        Rounding `json:"rounding"`
+	AccountID       string   `json:"account_id"`
+	Currency        string   `json:"currency"`
+	Amount          int64    `json:"amount"`
+}
+
+// NormalizeFeeSchedule validates a schedule about to be added: decimal rates
+// keyed by known participant type within the bounds of that type, α in
+// [0.8, 1.2], β in [0.9, 1.3], a known rounding mode (half_up when empty)
+// and a fee account. A zero EffectiveFrom means now.
+func NormalizeFeeSchedule(s FeeSchedule, now time.Time) (FeeSchedule, error) {
+	rates := make(map[string]string, len(s.Rates))
+	for k, v := range s.Rates {
+		k = strings.ToLower(strings.TrimSpace(k))
+		bounds, ok := rateBounds[k]
+		if !ok {
+			return FeeSchedule{}, ErrInvalidFeeSchedule
+		}
+		r, ok := parseDecimal(v)
+		if !ok || r.Cmp(bounds[0]) < 0 || r.Cmp(bounds[1]) > 0 {
+			return FeeSchedule{}, ErrInvalidFeeSchedule
+		}
+		rates[k] = FormatFXRate(r)
+	}
+	s.Rates = rates
+	alpha, ok := parseDecimal(s.Alpha)
+	if !ok || alpha.Cmp(minAlpha) < 0 || alpha.Cmp(maxAlpha) > 0 {
+		return FeeSchedule{}, ErrInvalidFeeSchedule
+	}
+	beta, ok := parseDecimal(s.Beta)
+	if !ok || beta.Cmp(minBeta) < 0 || beta.Cmp(maxBeta) > 0 {
+		return FeeSchedule{}, ErrInvalidFeeSchedule
+	}
+	s.Alpha, s.Beta = FormatFXRate(alpha), FormatFXRate(beta)
+	switch s.Rounding {
+	case "":
+		s.Rounding = RoundHalfUp
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundUp:
+	default:
+		return FeeSchedule{}, ErrInvalidFeeSchedule
+	}
+	s.AccountID = strings.TrimSpace(s.AccountID)
+	if s.AccountID == "" {
+		return FeeSchedule{}, ErrInvalidFeeSchedule
+	}
+	if s.EffectiveFrom.IsZero() {
+		s.EffectiveFrom = now
+	}
+	s.EffectiveFrom = s.EffectiveFrom.UTC()
+	return s, nil
+}
+
+// parseDecimal reads a non-negative decimal with up to 12 fractional
+// digits.
+func parseDecimal(s string) (*big.Rat, bool) {
+	s = strings.TrimSpace(s)
+	if !fxRatePattern.MatchString(s) {
+		return nil, false
+	}
+	r, ok := new(big.Rat).SetString(s)
+	return r, ok
+}
+
+// Compute returns the fee s charges a payer of participant type on amt.
+// Fees that overflow fail with ErrInvalidAmount.
+func (s FeeSchedule) Compute(participant string, amt Money) (Fee, error) {
+	fee := Fee{
+		ScheduleVersion: s.Version,
+		ParticipantType: participant,
+		Rate:            "0",
+		Alpha:           s.Alpha,
+		Beta:            s.Beta,
+		Rounding:        s.Rounding,
+		AccountID:       s.AccountID,
+		Currency:        amt.Currency,
+	}
+	if r, ok := s.Rates[participant]; ok {
+		fee.Rate = r
+	}
+	v := new(big.Rat).SetInt64(amt.Amount)
+	for _, f := range []string{fee.Rate, fee.Alpha, fee.Beta} {
+		d, ok := new(big.Rat).SetString(f)
+		if !ok {
+			return Fee{}, ErrInvalidFeeSchedule
+		}
+		v.Mul(v, d)
+	}
+	q := round(v, s.Rounding)
+	if !q.IsInt64() {
+		return Fee{}, ErrInvalidAmount
+	}
+	fee.Amount = q.Int64()
+	return fee, nil
+}
+
+// round rounds a non-negative v to an integer in mode.
+func round(v *big.Rat, mode Rounding) *big.Int {
+	q, rem := new(big.Int).QuoRem(v.Num(), v.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return q
+	}
+	twice := new(big.Int).Lsh(rem, 1)
+	up := false
+	switch mode {
+	case RoundUp:
+		up = true
+	case RoundDown:
+	case RoundHalfEven:
+		c := twice.Cmp(v.Denom())
+		up = c > 0 || (c == 0 && q.Bit(0) == 1)
+	default:
+		up = twice.Cmp(v.Denom()) >= 0
+	}
+	if up {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// CurrentFeeSchedule picks the schedule in force at t: the highest version
+// whose EffectiveFrom is not after t. It reports false when none is.
+func CurrentFeeSchedule(schedules []FeeSchedule, t time.Time) (FeeSchedule, bool) {
+	var (
+		best  FeeSchedule
+		found bool
+	)
+	for _, s := range schedules {
+		if s.EffectiveFrom.After(t) {
+			continue
+		}
+		if !found || s.Version > best.Version {
+			best, found = s, true
+		}
+	}
+	return best, found
+}
+
+// PlanFeeTransfer builds the posting for a transfer of amt from fromID to
+// toID that charges fee to fromID. A zero fee leaves a plain transfer; a
+// positive one adds a debit of the payer and a credit of the fee account,
+// committed with the transfer as one batch posting. The breakdown is
+// recorded on the transaction either way.
+func PlanFeeTransfer(fromID, toID string, amt Money, fee Fee) Transaction {
+	tx := Transaction{Fee: &fee}
+	if fee.Amount == 0 {
+		tx.FromAccountID, tx.ToAccountID = fromID, toID
+		tx.Currency, tx.Amount = amt.Currency, amt.Amount
+		return tx
+	}
+	tx.Entries = []Entry{
+		{AccountID: fromID, Direction: Debit, Currency: amt.Currency, Amount: amt.Amount},
+		{AccountID: toID, Direction: Credit, Currency: amt.Currency, Amount: amt.Amount},
+		{AccountID: fromID, Direction: Debit, Currency: amt.Currency, Amount: fee.Amount},
+		{AccountID: fee.AccountID, Direction: Credit, Currency: amt.Currency, Amount: fee.Amount},
+	}
+	return tx
+}
+
+// FeeEngine charges transfer fees under versioned fee schedules.
+type FeeEngine interface {
+	// AddFeeSchedule adds the next version of the fee schedule. The fee
+	// account must be an existing account of type fee.
+	AddFeeSchedule(ctx context.Context, s FeeSchedule) (FeeSchedule, error)
+	// FeeSchedules lists every version in version order.
+	FeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	// TransferWithFee is Transfer charging fromID, a payer of the given
+	// participant type, the fee of the schedule in force when it commits.
+	// Without one it is a plain transfer.
+	TransferWithFee(ctx context.Context, fromID, toID string, amt Money, participant, idemKey string) (Transaction, error)
+	// CaptureHoldWithFee is CaptureHold charging the payer of the hold, of
+	// the given participant type, the fee of the schedule in force on the
+	// captured amount. The fee comes out of the payer's available balance,
+	// not out of the hold.
+	CaptureHoldWithFee(ctx context.Context, id string, amount int64, participant string) (Hold, Transaction, error)
+}
+
+func (s *InMemory) AddFeeSchedule(ctx context.Context, fs FeeSchedule) (FeeSchedule, error) {
+	now := time.Now().UTC()
+	fs, err := NormalizeFeeSchedule(fs, now)
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acc, ok := s.accts[fs.AccountID]
+	if !ok {
+		return FeeSchedule{}, ErrNotFound
+	}
+	if acc.Type != AccountTypeFee {
+		return FeeSchedule{}, ErrInvalidAccountType
+	}
+	fs.Version = len(s.fees) + 1
+	fs.CreatedAt = now
+	s.fees = append(s.fees, fs)
+	s.logLocked(walRecord{FeeSchedule: &fs})
+	return fs, nil
+}
+
+func (s *InMemory) FeeSchedules(ctx context.Context) ([]FeeSchedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]FeeSchedule{}, s.fees...), nil
+}
+
+func (s *InMemory) TransferWithFee(ctx context.Context, fromID, toID string, amt Money, participant, idemKey string) (Transaction, error) {
+	if !amt.IsPositive() {
+		return Transaction{}, ErrInvalidAmount
+	}
+	if amt.Currency == "" {
+		return Transaction{}, ErrInvalidCurrency
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idemKey != "" {
+		if i, ok := s.idem[idemKey]; ok {
+			return s.txs[i], nil
+		}
+	}
+	if err := s.checkCurrencyLocked(amt.Currency, amt.Amount); err != nil {
+		return Transaction{}, err
+	}
+	tx := Transaction{FromAccountID: fromID, ToAccountID: toID, Currency: amt.Currency, Amount: amt.Amount}
+	if schedule, ok := CurrentFeeSchedule(s.fees, time.Now()); ok {
+		fee, err := schedule.Compute(participant, amt)
+		if err != nil {
+			return Transaction{}, err
+		}
+		tx = PlanFeeTransfer(fromID, toID, amt, fee)
+	}
+	if err := s.checkLimitsLocked(tx.Legs()); err != nil {
+		return Transaction{}, err
+	}
+	var err error
+	if len(tx.Entries) == 0 {
+		err = s.applyTransfer(ctx, fromID, toID, amt)
+	} else {
+		err = s.applyEntries(ctx, tx.Entries)
+	}
+	if err != nil {
+		return Transaction{}, err
+	}
+	tx.IdempotencyKey = idemKey
+	tx = s.record(tx)
+	s.logLocked(walRecord{Tx: &tx})
+	return tx, nil
+}
+
+func (s *InMemory) CaptureHoldWithFee(ctx context.Context, id string, amount int64, participant string) (Hold, Transaction, error) {
+	return s.captureHold(ctx, id, amount, true, participant)
+}
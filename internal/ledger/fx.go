@@ -0,0 +1,285 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidFXRate = errors.New("invalid fx rate")
+	ErrNoFXRate      = errors.New("no fx rate for currency pair")
+	ErrNoFXLiquidity = errors.New("no fx liquidity account for currency")
+)
+
+// FXRate quotes how many minor units of Quote one minor unit of Base buys.
+// It applies from ValidFrom (inclusive) until ValidTo (exclusive); a zero
+// ValidTo leaves the window open. When windows overlap the rate with the
+// latest ValidFrom wins.
+type FXRate struct {
+	ID        string    `json:"id"`
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Rate      string    `json:"rate"` // decimal, up to 12 fractional digits
+	ValidFrom time.Time `json:"valid_from"`
+	ValidTo   time.Time `json:"valid_to,omitzero"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var fxRatePattern = regexp.MustCompile(`^[0-9]{1,12}(\.[0-9]{1,12})?$`)
+
+// ValidAt reports whether r applies at t.
+func (r FXRate) ValidAt(t time.Time) bool {
+	return !t.Before(r.ValidFrom) && (r.ValidTo.IsZero() || t.Before(r.ValidTo))
+}
+
+// Convert applies r to amount minor units of Base, rounding down to whole
+// minor units of Quote. Conversions that round to zero or overflow fail with
+// ErrInvalidAmount.
+func (r FXRate) Convert(amount int64) (int64, error) {
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return 0, ErrInvalidFXRate
+	}
+	v := new(big.Rat).Mul(rate, new(big.Rat).SetInt64(amount))
+	q := new(big.Int).Quo(v.Num(), v.Denom())
+	if !q.IsInt64() || q.Int64() <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	return q.Int64(), nil
+}
+
+// NormalizeFXRate validates a rate about to be registered: distinct
+// non-empty currency codes, a positive decimal rate and a non-empty window.
+// A zero ValidFrom means now.
+func NormalizeFXRate(r FXRate, now time.Time) (FXRate, error) {
+	r.Base = strings.ToUpper(strings.TrimSpace(r.Base))
+	r.Quote = strings.ToUpper(strings.TrimSpace(r.Quote))
+	if r.Base == "" || r.Quote == "" || r.Base == r.Quote || len(r.Base) > 8 || len(r.Quote) > 8 {
+		return FXRate{}, ErrInvalidCurrency
+	}
+	r.Rate = strings.TrimSpace(r.Rate)
+	if !fxRatePattern.MatchString(r.Rate) {
+		return FXRate{}, ErrInvalidFXRate
+	}
+	rate, _ := new(big.Rat).SetString(r.Rate)
+	if rate.Sign() <= 0 {
+		return FXRate{}, ErrInvalidFXRate
+	}
+	r.Rate = FormatFXRate(rate)
+	if r.ValidFrom.IsZero() {
+		r.ValidFrom = now
+	}
+	r.ValidFrom = r.ValidFrom.UTC()
+	if !r.ValidTo.IsZero() {
+		r.ValidTo = r.ValidTo.UTC()
+		if !r.ValidTo.After(r.ValidFrom) {
+			return FXRate{}, ErrInvalidFXRate
+		}
+	}
+	return r, nil
+}
+
+// FormatFXRate renders a rate in its shortest decimal form.
+func FormatFXRate(rate *big.Rat) string {
+	s := rate.FloatString(12)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// CurrentFXRate picks the rate for base→quote at t from rates.
+func CurrentFXRate(rates []FXRate, base, quote string, t time.Time) (FXRate, error) {
+	var (
+		best  FXRate
+		found bool
+	)
+	for _, r := range rates {
+		if r.Base != base || r.Quote != quote || !r.ValidAt(t) {
+			continue
+		}
+		if !found || r.ValidFrom.After(best.ValidFrom) ||
+			(r.ValidFrom.Equal(best.ValidFrom) && r.CreatedAt.After(best.CreatedAt)) {
+			best, found = r, true
+		}
+	}
+	if !found {
+		return FXRate{}, ErrNoFXRate
+	}
+	return best, nil
+}
+
+// PlanFXTransfer builds the posting for converting amt on fromID into
+// rate.Quote on toID. The source currency goes to sellLiquidity and the
+// target currency comes from buyLiquidity, so each currency balances on its
+// own. The applied rate is recorded on the transaction.
+func PlanFXTransfer(fromID, toID string, amt Money, rate FXRate, sellLiquidity, buyLiquidity string) (Transaction, error) {
+	converted, err := rate.Convert(amt.Amount)
+	if err != nil {
+		return Transaction{}, err
+	}
+	return Transaction{
+		Entries: []Entry{
+			{AccountID: fromID, Direction: Debit, Currency: amt.Currency, Amount: amt.Amount},
+			{AccountID: sellLiquidity, Direction: Credit, Currency: amt.Currency, Amount: amt.Amount},
+			{AccountID: buyLiquidity, Direction: Debit, Currency: rate.Quote, Amount: converted},
+			{AccountID: toID, Direction: Credit, Currency: rate.Quote, Amount: converted},
+		},
+		FXRateID: rate.ID,
+		FXRate:   rate.Rate,
+	}, nil
+}
+
+// FXRegistry manages conversion rates and the liquidity accounts FX
+// transfers settle against. Rates are never deleted; expiring one ends its
+// validity window.
+type FXRegistry interface {
+	AddFXRate(ctx context.Context, r FXRate) (FXRate, error)
+	// ExpireFXRate closes the window of a rate at the current time.
+	ExpireFXRate(ctx context.Context, id string) (FXRate, error)
+	// ListFXRates returns registered rates, optionally for one pair, ordered
+	// by pair and ValidFrom.
+	ListFXRates(ctx context.Context, base, quote string) ([]FXRate, error)
+	// SetFXLiquidityAccount designates the account that buys and sells
+	// currency in FX transfers.
+	SetFXLiquidityAccount(ctx context.Context, currency, accountID string) error
+	FXLiquidityAccounts(ctx context.Context) (map[string]string, error)
+}
+
+func (s *InMemory) AddFXRate(ctx context.Context, r FXRate) (FXRate, error) {
+	now := time.Now().UTC()
+	r, err := NormalizeFXRate(r, now)
+	if err != nil {
+		return FXRate{}, err
+	}
+	r.ID = newID()
+	r.CreatedAt = now
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates = append(s.rates, r)
+	s.logLocked(walRecord{FXRate: &r})
+	return r, nil
+}
+
+func (s *InMemory) ExpireFXRate(ctx context.Context, id string) (FXRate, error) {
+	now := time.Now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.rates {
+		r := &s.rates[i]
+		if r.ID != id {
+			continue
+		}
+		if r.ValidTo.IsZero() || r.ValidTo.After(now) {
+			r.ValidTo = now
+			if r.ValidFrom.After(now) {
+				r.ValidTo = r.ValidFrom
+			}
+			s.logLocked(walRecord{FXRate: r})
+		}
+		return *r, nil
+	}
+	return FXRate{}, ErrNotFound
+}
+
+func (s *InMemory) ListFXRates(ctx context.Context, base, quote string) ([]FXRate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []FXRate
+	for _, r := range s.rates {
+		if (base == "" || r.Base == base) && (quote == "" || r.Quote == quote) {
+			res = append(res, r)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Base != res[j].Base {
+			return res[i].Base < res[j].Base
+		}
+		if res[i].Quote != res[j].Quote {
+			return res[i].Quote < res[j].Quote
+		}
+		return res[i].ValidFrom.Before(res[j].ValidFrom)
+	})
+	return res, nil
+}
+
+func (s *InMemory) SetFXLiquidityAccount(ctx context.Context, currency, accountID string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return ErrInvalidCurrency
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acc, ok := s.accts[accountID]
+	if !ok {
+		return ErrNotFound
+	}
+	if acc.Type == AccountTypeIssuance {
+		return ErrIssuanceAccount
+	}
+	s.liquidity[currency] = accountID
+	s.logLocked(walRecord{Liquidity: &liquidityChange{Currency: currency, AccountID: accountID}})
+	return nil
+}
+
+func (s *InMemory) FXLiquidityAccounts(ctx context.Context) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]string, len(s.liquidity))
+	for k, v := range s.liquidity {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (s *InMemory) FXTransfer(ctx context.Context, fromID, toID string, amt Money, toCurrency, idemKey string) (Transaction, error) {
+	if !amt.IsPositive() {
+		return Transaction{}, ErrInvalidAmount
+	}
+	if amt.Currency == "" || toCurrency == "" || amt.Currency == toCurrency {
+		return Transaction{}, ErrInvalidCurrency
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idemKey != "" {
+		if i, ok := s.idem[idemKey]; ok {
+			return s.txs[i], nil
+		}
+	}
+	from, ok := s.accts[fromID]
+	if !ok || !Visible(ctx, from.OrganizationID) {
+		return Transaction{}, ErrNotFound
+	}
+	rate, err := CurrentFXRate(s.rates, amt.Currency, toCurrency, time.Now())
+	if err != nil {
+		return Transaction{}, err
+	}
+	sell, ok1 := s.liquidity[amt.Currency]
+	buy, ok2 := s.liquidity[toCurrency]
+	if !ok1 || !ok2 {
+		return Transaction{}, ErrNoFXLiquidity
+	}
+	tx, err := PlanFXTransfer(fromID, toID, amt, rate, sell, buy)
+	if err != nil {
+		return Transaction{}, err
+	}
+	if err := s.checkLegsLocked(tx.Entries); err != nil {
+		return Transaction{}, err
+	}
+	if err := s.checkLimitsLocked(tx.Entries); err != nil {
+		return Transaction{}, err
+	}
+	// The liquidity account is debited on the caller's behalf.
+	if err := s.applyEntries(WithoutOrganizationScope(ctx), tx.Entries); err != nil {
+		return Transaction{}, err
+	}
+	tx.IdempotencyKey = idemKey
+	tx = s.record(tx)
+	s.logLocked(walRecord{Tx: &tx})
+	return tx, nil
+}
@@ -0,0 +1,313 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// HoldStatus is the lifecycle state of a two-phase transfer.
+//
+//	pending → captured | voided | expired
+type HoldStatus string
+
+const (
+	HoldPending  HoldStatus = "pending"
+	HoldCaptured HoldStatus = "captured"
+	HoldVoided   HoldStatus = "voided"
+	HoldExpired  HoldStatus = "expired"
+)
+
+const (
+	DefaultHoldTTL = 24 * time.Hour
+	MaxHoldTTL     = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidHoldTTL     = errors.New("invalid hold ttl")
+	ErrHoldNotPending     = errors.New("hold is not pending")
+	ErrHoldExpired        = errors.New("hold expired")
+	ErrCaptureExceedsHold = errors.New("capture exceeds held amount")
+)
+
+// Hold earmarks funds of FromAccountID for a later transfer to ToAccountID.
+// A pending hold lowers the available balance of the source account but not
+// its ledger balance. Capturing it posts a transfer of up to Amount and
+// releases the rest; voiding or expiry releases everything.
+type Hold struct {
+	ID             string     `json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	FromAccountID  string     `json:"from_account_id"`
+	ToAccountID    string     `json:"to_account_id"`
+	Currency       string     `json:"currency"`
+	Amount         int64      `json:"amount"`
+	CapturedAmount int64      `json:"captured_amount,omitempty"`
+	Status         HoldStatus `json:"status"`
+	TransactionID  string     `json:"transaction_id,omitempty"` // capture transaction
+	IdempotencyKey string     `json:"idempotency_key,omitempty"`
+}
+
+// Active reports whether h still reserves funds at now.
+func (h Hold) Active(now time.Time) bool {
+	return h.Status == HoldPending && now.Before(h.ExpiresAt)
+}
+
+// CheckResolve returns the error resolving h at now fails with. Pending
+// holds past their expiry are reported as expired.
+func (h Hold) CheckResolve(now time.Time) error {
+	if h.Status != HoldPending {
+		return ErrHoldNotPending
+	}
+	if !now.Before(h.ExpiresAt) {
+		return ErrHoldExpired
+	}
+	return nil
+}
+
+// StatusAt is the status of h as observed at now: pending holds past their
+// expiry read as expired even before a store marks them so.
+func (h Hold) StatusAt(now time.Time) HoldStatus {
+	if h.Status == HoldPending && !h.Active(now) {
+		return HoldExpired
+	}
+	return h.Status
+}
+
+// CaptureAmount resolves the amount a capture of h transfers; zero means the
+// full held amount.
+func (h Hold) CaptureAmount(amount int64) (int64, error) {
+	switch {
+	case amount < 0:
+		return 0, ErrInvalidAmount
+	case amount == 0:
+		return h.Amount, nil
+	case amount > h.Amount:
+		return 0, ErrCaptureExceedsHold
+	}
+	return amount, nil
+}
+
+// HoldTTL applies the default to a zero ttl and validates the result.
+func HoldTTL(ttl time.Duration) (time.Duration, error) {
+	if ttl == 0 {
+		return DefaultHoldTTL, nil
+	}
+	if ttl < 0 || ttl > MaxHoldTTL {
+		return 0, ErrInvalidHoldTTL
+	}
+	return ttl, nil
+}
+
+// Balance is the balance of one account in one currency. Amount is the
+// ledger balance; Held is reserved by pending holds and Available is what
+// can still be debited.
+type Balance struct {
+	Money
+	Held      int64 `json:"held"`
+	Available int64 `json:"available"`
+}
+
+func (s *InMemory) CreateHold(ctx context.Context, fromID, toID string, amt Money, ttl time.Duration, idemKey string) (Hold, error) {
+	if !amt.IsPositive() {
+		return Hold{}, ErrInvalidAmount
+	}
+	if amt.Currency == "" {
+		return Hold{}, ErrInvalidCurrency
+	}
+	ttl, err := HoldTTL(ttl)
+	if err != nil {
+		return Hold{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idemKey != "" {
+		if id, ok := s.holdIdem[idemKey]; ok {
+			return s.viewHold(s.holds[id], time.Now()), nil
+		}
+	}
+	if err := s.checkCurrencyLocked(amt.Currency, amt.Amount); err != nil {
+		return Hold{}, err
+	}
+	from, ok := s.accts[fromID]
+	if !ok || !Visible(ctx, from.OrganizationID) {
+		return Hold{}, ErrNotFound
+	}
+	to, ok := s.accts[toID]
+	if !ok {
+		return Hold{}, ErrNotFound
+	}
+	if err := from.Status.CheckDebit(); err != nil {
+		return Hold{}, err
+	}
+	if err := to.Status.CheckCredit(); err != nil {
+		return Hold{}, err
+	}
+	if from.Type == AccountTypeIssuance || to.Type == AccountTypeIssuance {
+		return Hold{}, ErrIssuanceAccount
+	}
+	now := time.Now().UTC()
+	s.expireLocked(now)
+	if s.availableLocked(fromID, amt.Currency, now) < amt.Amount {
+		return Hold{}, ErrInsufficientFunds
+	}
+
+	h := &Hold{
+		ID:             newID(),
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(ttl),
+		FromAccountID:  fromID,
+		ToAccountID:    toID,
+		Currency:       amt.Currency,
+		Amount:         amt.Amount,
+		Status:         HoldPending,
+		IdempotencyKey: idemKey,
+	}
+	s.holds[h.ID] = h
+	s.pending[h.ID] = h
+	if idemKey != "" {
+		s.holdIdem[idemKey] = h.ID
+	}
+	s.logLocked(walRecord{Hold: h})
+	return *h, nil
+}
+
+func (s *InMemory) GetHold(ctx context.Context, id string) (Hold, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	h, err := s.visibleHold(ctx, id)
+	if err != nil {
+		return Hold{}, err
+	}
+	return s.viewHold(h, time.Now()), nil
+}
+
+func (s *InMemory) CaptureHold(ctx context.Context, id string, amount int64) (Hold, Transaction, error) {
+	return s.captureHold(ctx, id, amount, false, "")
+}
+
+// captureHold captures a pending hold, charging the payer the fee of the
+// current schedule for participant when withFee is set.
+func (s *InMemory) captureHold(ctx context.Context, id string, amount int64, withFee bool, participant string) (Hold, Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, err := s.resolvableHold(ctx, id)
+	if err != nil {
+		return Hold{}, Transaction{}, err
+	}
+	amount, err = h.CaptureAmount(amount)
+	if err != nil {
+		return Hold{}, Transaction{}, err
+	}
+
+	amt := Money{Currency: h.Currency, Amount: amount}
+	tx := Transaction{FromAccountID: h.FromAccountID, ToAccountID: h.ToAccountID, Currency: h.Currency, Amount: amount}
+	if withFee {
+		if schedule, ok := CurrentFeeSchedule(s.fees, time.Now()); ok {
+			fee, err := schedule.Compute(participant, amt)
+			if err != nil {
+				return Hold{}, Transaction{}, err
+			}
+			tx = PlanFeeTransfer(h.FromAccountID, h.ToAccountID, amt, fee)
+		}
+	}
+	if err := s.checkLimitsLocked(tx.Legs()); err != nil {
+		return Hold{}, Transaction{}, err
+	}
+	// Release the reservation first so the transfer can use the funds.
+	delete(s.pending, h.ID)
+	if len(tx.Entries) == 0 {
+		err = s.applyTransfer(ctx, h.FromAccountID, h.ToAccountID, amt)
+	} else {
+		err = s.applyEntries(ctx, tx.Entries)
+	}
+	if err != nil {
+		s.pending[h.ID] = h
+		return Hold{}, Transaction{}, err
+	}
+	h.Status = HoldCaptured
+	tx = s.record(tx)
+	h.CapturedAmount = amount
+	h.TransactionID = tx.ID
+	s.logLocked(walRecord{Tx: &tx, Hold: h})
+	return *h, tx, nil
+}
+
+func (s *InMemory) VoidHold(ctx context.Context, id string) (Hold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, err := s.resolvableHold(ctx, id)
+	if err != nil {
+		return Hold{}, err
+	}
+	h.Status = HoldVoided
+	delete(s.pending, h.ID)
+	s.logLocked(walRecord{Hold: h})
+	return *h, nil
+}
+
+// visibleHold looks up a hold whose source account is visible under ctx.
+// Callers must hold s.mu.
+func (s *InMemory) visibleHold(ctx context.Context, id string) (*Hold, error) {
+	h, ok := s.holds[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if acc, ok := s.accts[h.FromAccountID]; !ok || !Visible(ctx, acc.OrganizationID) {
+		return nil, ErrNotFound
+	}
+	return h, nil
+}
+
+// resolvableHold returns a visible pending hold, marking it expired if its
+// time has passed. Callers must hold s.mu for writing.
+func (s *InMemory) resolvableHold(ctx context.Context, id string) (*Hold, error) {
+	h, err := s.visibleHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.CheckResolve(time.Now()); err != nil {
+		if errors.Is(err, ErrHoldExpired) {
+			h.Status = HoldExpired
+			delete(s.pending, h.ID)
+		}
+		return nil, err
+	}
+	return h, nil
+}
+
+func (s *InMemory) viewHold(h *Hold, now time.Time) Hold {
+	out := *h
+	out.Status = out.StatusAt(now)
+	return out
+}
+
+// expireLocked marks pending holds past their expiry as expired. Callers
+// must hold s.mu for writing.
+func (s *InMemory) expireLocked(now time.Time) {
+	for id, h := range s.pending {
+		if !h.Active(now) {
+			h.Status = HoldExpired
+			delete(s.pending, id)
+		}
+	}
+}
+
+// heldLocked sums the active holds on an account. Callers must hold s.mu.
+func (s *InMemory) heldLocked(accountID, currency string, now time.Time) int64 {
+	var held int64
+	for _, h := range s.pending {
+		if h.FromAccountID == accountID && h.Currency == currency && h.Active(now) {
+			held += h.Amount
+		}
+	}
+	return held
+}
+
+// availableLocked is the balance that can still be debited. Callers must
+// hold s.mu.
+func (s *InMemory) availableLocked(accountID, currency string, now time.Time) int64 {
+	return s.accts[accountID].Balances[currency] - s.heldLocked(accountID, currency, now)
+}
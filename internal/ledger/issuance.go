@@ -0,0 +1,266 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AccountTypeIssuance marks the per-currency account that money is minted
+// from and burned into. The ledger creates it on the first mint; it cannot be
+// created, transferred to or from, or held against through the ordinary
+// operations, and its balance is minus the amount in circulation through
+// issuance.
+const AccountTypeIssuance AccountType = "issuance"
+
+// TransactionKind tells mint and burn transactions apart from ordinary
+// transfers, which leave it empty.
+type TransactionKind string
+
+const (
+	KindMint TransactionKind = "mint"
+	KindBurn TransactionKind = "burn"
+)
+
+var (
+	ErrNotIssuer       = errors.New("account is not the issuer of the currency")
+	ErrIssuanceAccount = errors.New("issuance accounts only take mint and burn postings")
+)
+
+// IssuanceAccountID is the id of the issuance account of currency.
+func IssuanceAccountID(currency string) string {
+	return "issuance-" + currency
+}
+
+// IssuanceAccount returns the issuance account of currency as the ledger
+// creates it, with a zero balance.
+func IssuanceAccount(currency string, now time.Time) Account {
+	return Account{
+		ID:          IssuanceAccountID(currency),
+		Type:        AccountTypeIssuance,
+		DisplayName: currency + " issuance",
+		Status:      AccountActive,
+		CreatedAt:   now.UTC(),
+		Balances:    map[string]int64{currency: 0},
+	}
+}
+
+// PlanIssuance returns the transaction that mints amt into, or burns it from,
+// the issuer account: a transfer from the issuance account for a mint and to
+// it for a burn.
+func PlanIssuance(kind TransactionKind, issuerID string, amt Money) (Transaction, error) {
+	if !amt.IsPositive() {
+		return Transaction{}, ErrInvalidAmount
+	}
+	if amt.Currency == "" {
+		return Transaction{}, ErrInvalidCurrency
+	}
+	tx := Transaction{Kind: kind, Currency: amt.Currency, Amount: amt.Amount}
+	switch kind {
+	case KindMint:
+		tx.FromAccountID, tx.ToAccountID = IssuanceAccountID(amt.Currency), issuerID
+	case KindBurn:
+		tx.FromAccountID, tx.ToAccountID = issuerID, IssuanceAccountID(amt.Currency)
+	default:
+		return Transaction{}, ErrInvalidAmount
+	}
+	return tx, nil
+}
+
+// Supply reports the money outstanding in one currency. Outstanding is the
+// sum of all balances outside the issuance account; Issued is what mints
+// have added to it net of burns, and Opening what accounts were funded with
+// when they were created.
+type Supply struct {
+	Currency    string `json:"currency"`
+	Outstanding int64  `json:"outstanding"`
+	Issued      int64  `json:"issued"`
+	Minted      int64  `json:"minted"`
+	Burned      int64  `json:"burned"`
+	Opening     int64  `json:"opening"`
+}
+
+// Issuance is implemented by ledgers that mint and burn money. Each currency
+// has at most one issuer account; Mint credits it from the currency's
+// issuance account and Burn debits it back. Both are recorded as
+// transactions of the matching Kind and cannot be reversed; a mint is undone
+// by burning.
+type Issuance interface {
+	Mint(ctx context.Context, issuerID string, amt Money, idemKey string) (Transaction, error)
+	Burn(ctx context.Context, issuerID string, amt Money, idemKey string) (Transaction, error)
+	// SetIssuerAccount designates the issuer account of currency.
+	SetIssuerAccount(ctx context.Context, currency, accountID string) error
+	IssuerAccounts(ctx context.Context) (map[string]string, error)
+	// Supply reports every currency held in the ledger, in code order,
+	// regardless of the organization scope of ctx.
+	Supply(ctx context.Context) ([]Supply, error)
+}
+
+// SupplyBuilder accumulates balances and issuance transactions into Supply
+// reports.
+type SupplyBuilder struct {
+	byCurrency map[string]*Supply
+}
+
+func NewSupplyBuilder() *SupplyBuilder {
+	return &SupplyBuilder{byCurrency: make(map[string]*Supply)}
+}
+
+func (b *SupplyBuilder) get(currency string) *Supply {
+	s, ok := b.byCurrency[currency]
+	if !ok {
+		s = &Supply{Currency: currency}
+		b.byCurrency[currency] = s
+	}
+	return s
+}
+
+// Balance adds the balance of an account of type t.
+func (b *SupplyBuilder) Balance(t AccountType, currency string, amount int64) {
+	s := b.get(currency)
+	if t == AccountTypeIssuance {
+		s.Issued -= amount
+	} else {
+		s.Outstanding += amount
+	}
+}
+
+// Issuance adds the total minted or burned in currency.
+func (b *SupplyBuilder) Issuance(kind TransactionKind, currency string, amount int64) {
+	s := b.get(currency)
+	switch kind {
+	case KindMint:
+		s.Minted += amount
+	case KindBurn:
+		s.Burned += amount
+	}
+}
+
+// Result returns the reports in currency order.
+func (b *SupplyBuilder) Result() []Supply {
+	out := make([]Supply, 0, len(b.byCurrency))
+	for _, s := range b.byCurrency {
+		s.Opening = s.Outstanding - s.Issued
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Currency < out[j].Currency })
+	return out
+}
+
+func (s *InMemory) Mint(ctx context.Context, issuerID string, amt Money, idemKey string) (Transaction, error) {
+	return s.issue(ctx, KindMint, issuerID, amt, idemKey)
+}
+
+func (s *InMemory) Burn(ctx context.Context, issuerID string, amt Money, idemKey string) (Transaction, error) {
+	return s.issue(ctx, KindBurn, issuerID, amt, idemKey)
+}
+
+func (s *InMemory) issue(ctx context.Context, kind TransactionKind, issuerID string, amt Money, idemKey string) (Transaction, error) {
+	tx, err := PlanIssuance(kind, issuerID, amt)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idemKey != "" {
+		if i, ok := s.idem[idemKey]; ok {
+			return s.txs[i], nil
+		}
+	}
+	if err := s.checkCurrencyLocked(amt.Currency, amt.Amount); err != nil {
+		return Transaction{}, err
+	}
+	issuer, ok := s.accts[issuerID]
+	if !ok || !Visible(ctx, issuer.OrganizationID) {
+		return Transaction{}, ErrNotFound
+	}
+	if s.issuers[amt.Currency] != issuerID {
+		return Transaction{}, ErrNotIssuer
+	}
+
+	var created *storedAccount
+	pool, ok := s.accts[IssuanceAccountID(amt.Currency)]
+	if !ok {
+		acc := IssuanceAccount(amt.Currency, time.Now())
+		created = &storedAccount{Account: acc, Opening: Money{Currency: amt.Currency}, OpeningSeq: s.seq}
+		pool = &acc
+	}
+	from, to := pool, issuer
+	if kind == KindBurn {
+		from, to = issuer, pool
+	}
+	if err := from.Status.CheckDebit(); err != nil {
+		return Transaction{}, err
+	}
+	if err := to.Status.CheckCredit(); err != nil {
+		return Transaction{}, err
+	}
+	// The issuance account may go negative; nothing else may.
+	if kind == KindBurn && s.availableLocked(issuerID, amt.Currency, time.Now()) < amt.Amount {
+		return Transaction{}, ErrInsufficientFunds
+	}
+
+	if created != nil {
+		s.accts[pool.ID] = pool
+		s.openings[pool.ID] = opening{Money: created.Opening, seq: created.OpeningSeq}
+	}
+	from.Balances[amt.Currency] -= amt.Amount
+	to.Balances[amt.Currency] += amt.Amount
+	tx.IdempotencyKey = idemKey
+	tx = s.record(tx)
+	s.logLocked(walRecord{Account: created, Tx: &tx})
+	return tx, nil
+}
+
+func (s *InMemory) SetIssuerAccount(ctx context.Context, currency, accountID string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return ErrInvalidCurrency
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := ResolveCurrency(s.currencies, currency); err != nil {
+		return err
+	}
+	acc, ok := s.accts[accountID]
+	if !ok {
+		return ErrNotFound
+	}
+	if acc.Type == AccountTypeIssuance {
+		return ErrIssuanceAccount
+	}
+	s.issuers[currency] = accountID
+	s.logLocked(walRecord{Issuer: &issuerChange{Currency: currency, AccountID: accountID}})
+	return nil
+}
+
+func (s *InMemory) IssuerAccounts(ctx context.Context) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]string, len(s.issuers))
+	for k, v := range s.issuers {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (s *InMemory) Supply(ctx context.Context) ([]Supply, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	b := NewSupplyBuilder()
+	for _, acc := range s.accts {
+		for cur, amt := range acc.Balances {
+			b.Balance(acc.Type, cur, amt)
+		}
+	}
+	for _, tx := range s.txs {
+		if tx.Kind != "" {
+			b.Issuance(tx.Kind, tx.Currency, tx.Amount)
+		}
+	}
+	return b.Result(), nil
+}
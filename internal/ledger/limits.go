@@ -0,0 +1,342 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrLimitExceeded = errors.New("transfer limit exceeded")
+	ErrInvalidLimit  = errors.New("invalid limit")
+)
+
+// LimitScope tells whether a limit applies to one account or to every
+// account of an organization.
+type LimitScope string
+
+const (
+	LimitAccount      LimitScope = "account"
+	LimitOrganization LimitScope = "organization"
+)
+
+// MaxLimitWindow bounds the rolling window of a limit.
+const MaxLimitWindow = 31 * 24 * time.Hour
+
+// Limit caps what a subject, an account or all accounts of an organization,
+// may send in one currency. MaxAmount caps a single posting; DailyAmount and
+// DailyCount cap the volume and number of postings since midnight UTC, and
+// WindowAmount and WindowCount those within the last WindowSeconds. Zero
+// leaves a cap off.
+//
+// What a posting sends is the sum of its debit legs on the subject, so the
+// fee of a transfer counts with its amount. Reversals, mints and burns are
+// neither limited nor counted.
+type Limit struct {
+	Scope         LimitScope `json:"scope"`
+	Subject       string     `json:"subject"`
+	Currency      string     `json:"currency"`
+	MaxAmount     int64      `json:"max_amount,omitempty"`
+	DailyAmount   int64      `json:"daily_amount,omitempty"`
+	DailyCount    int64      `json:"daily_count,omitempty"`
+	WindowAmount  int64      `json:"window_amount,omitempty"`
+	WindowCount   int64      `json:"window_count,omitempty"`
+	WindowSeconds int64      `json:"window_seconds,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// LimitKey identifies the limit of a subject in a currency.
+type LimitKey struct {
+	Scope    LimitScope
+	Subject  string
+	Currency string
+}
+
+func (l Limit) Key() LimitKey {
+	return LimitKey{Scope: l.Scope, Subject: l.Subject, Currency: l.Currency}
+}
+
+// NormalizeLimitKey trims and upper-cases the currency of k and checks its
+// scope.
+func NormalizeLimitKey(k LimitKey) (LimitKey, error) {
+	k.Subject = strings.TrimSpace(k.Subject)
+	k.Currency = strings.ToUpper(strings.TrimSpace(k.Currency))
+	if k.Scope != LimitAccount && k.Scope != LimitOrganization {
+		return LimitKey{}, ErrInvalidLimit
+	}
+	if k.Subject == "" || len(k.Subject) > 64 {
+		return LimitKey{}, ErrInvalidLimit
+	}
+	if k.Currency == "" {
+		return LimitKey{}, ErrInvalidCurrency
+	}
+	return k, nil
+}
+
+// NormalizeLimit validates a limit about to be set: a known scope, a subject
+// and currency, non-negative caps and a window of at most MaxLimitWindow
+// whenever a window cap is set.
+func NormalizeLimit(l Limit) (Limit, error) {
+	k, err := NormalizeLimitKey(l.Key())
+	if err != nil {
+		return Limit{}, err
+	}
+	l.Subject, l.Currency = k.Subject, k.Currency
+	for _, v := range []int64{l.MaxAmount, l.DailyAmount, l.DailyCount, l.WindowAmount, l.WindowCount, l.WindowSeconds} {
+		if v < 0 {
+			return Limit{}, ErrInvalidLimit
+		}
+	}
+	if time.Duration(l.WindowSeconds)*time.Second > MaxLimitWindow {
+		return Limit{}, ErrInvalidLimit
+	}
+	if (l.WindowAmount > 0 || l.WindowCount > 0) && l.WindowSeconds == 0 {
+		return Limit{}, ErrInvalidLimit
+	}
+	return l, nil
+}
+
+// Utilization is what the subject of a limit has sent in its currency as of
+// At, against the daily and rolling-window caps.
+type Utilization struct {
+	Limit        Limit     `json:"limit"`
+	At           time.Time `json:"at"`
+	DailyAmount  int64     `json:"daily_amount"`
+	DailyCount   int64     `json:"daily_count"`
+	WindowAmount int64     `json:"window_amount"`
+	WindowCount  int64     `json:"window_count"`
+}
+
+func NewUtilization(l Limit, at time.Time) Utilization {
+	return Utilization{Limit: l, At: at.UTC()}
+}
+
+// DayStart is midnight UTC of the day of At.
+func (u Utilization) DayStart() time.Time {
+	return u.At.Truncate(24 * time.Hour)
+}
+
+// WindowStart is the start of the rolling window ending at At; without a
+// window it is At itself, so nothing falls inside.
+func (u Utilization) WindowStart() time.Time {
+	return u.At.Add(-time.Duration(u.Limit.WindowSeconds) * time.Second)
+}
+
+// Since is the earliest time a posting can count toward u.
+func (u Utilization) Since() time.Time {
+	day, window := u.DayStart(), u.WindowStart()
+	if window.Before(day) {
+		return window
+	}
+	return day
+}
+
+// Add counts a posting that sent amount at time at.
+func (u *Utilization) Add(at time.Time, amount int64) {
+	if !at.Before(u.DayStart()) {
+		u.DailyAmount += amount
+		u.DailyCount++
+	}
+	if at.After(u.WindowStart()) {
+		u.WindowAmount += amount
+		u.WindowCount++
+	}
+}
+
+// Check fails with ErrLimitExceeded when one more posting sending amount
+// would break a cap of the limit.
+func (u Utilization) Check(amount int64) error {
+	l := u.Limit
+	exceeded := func(cap string) error {
+		return fmt.Errorf("%w: %s of %s %s in %s", ErrLimitExceeded, cap, l.Scope, l.Subject, l.Currency)
+	}
+	switch {
+	case l.MaxAmount > 0 && amount > l.MaxAmount:
+		return exceeded("max_amount")
+	case l.DailyAmount > 0 && u.DailyAmount+amount > l.DailyAmount:
+		return exceeded("daily_amount")
+	case l.DailyCount > 0 && u.DailyCount+1 > l.DailyCount:
+		return exceeded("daily_count")
+	case l.WindowAmount > 0 && u.WindowAmount+amount > l.WindowAmount:
+		return exceeded("window_amount")
+	case l.WindowCount > 0 && u.WindowCount+1 > l.WindowCount:
+		return exceeded("window_count")
+	}
+	return nil
+}
+
+// LimitDebits sums the debit legs of a posting per limit key they could
+// count toward: the debited account's and, through orgs, its
+// organization's, in the leg currency.
+func LimitDebits(legs []Entry, orgs map[string]string) map[LimitKey]int64 {
+	out := make(map[LimitKey]int64)
+	for _, e := range legs {
+		if e.Direction != Debit {
+			continue
+		}
+		out[LimitKey{LimitAccount, e.AccountID, e.Currency}] += e.Amount
+		if org := orgs[e.AccountID]; org != "" {
+			out[LimitKey{LimitOrganization, org, e.Currency}] += e.Amount
+		}
+	}
+	return out
+}
+
+// CountsTowardLimits reports whether tx is limited and counted: everything
+// but reversals, mints and burns.
+func CountsTowardLimits(tx Transaction) bool {
+	return tx.ReversalOf == "" && tx.Kind == ""
+}
+
+// Limits is implemented by ledgers that enforce transfer limits. Limits are
+// checked when a transfer, batch posting, FX transfer or hold capture
+// commits, and a posting that would break one fails with ErrLimitExceeded.
+type Limits interface {
+	// SetLimit creates or replaces the limit of its key.
+	SetLimit(ctx context.Context, l Limit) (Limit, error)
+	DeleteLimit(ctx context.Context, k LimitKey) error
+	// ListLimits lists limits by scope, subject and currency, narrowed to
+	// the scope and subject of filter when they are set.
+	ListLimits(ctx context.Context, filter LimitKey) ([]Limit, error)
+	// LimitUtilization reports the usage of the limit of k now.
+	LimitUtilization(ctx context.Context, k LimitKey) (Utilization, error)
+}
+
+// SortLimits orders limits by scope, subject and currency.
+func SortLimits(limits []Limit) {
+	sort.Slice(limits, func(i, j int) bool {
+		a, b := limits[i], limits[j]
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		if a.Subject != b.Subject {
+			return a.Subject < b.Subject
+		}
+		return a.Currency < b.Currency
+	})
+}
+
+func (s *InMemory) SetLimit(ctx context.Context, l Limit) (Limit, error) {
+	l, err := NormalizeLimit(l)
+	if err != nil {
+		return Limit{}, err
+	}
+	l.UpdatedAt = time.Now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := ResolveCurrency(s.currencies, l.Currency); err != nil {
+		return Limit{}, err
+	}
+	if l.Scope == LimitAccount {
+		if _, ok := s.accts[l.Subject]; !ok {
+			return Limit{}, ErrNotFound
+		}
+	}
+	s.limits[l.Key()] = l
+	s.logLocked(walRecord{Limit: &limitChange{Limit: l}})
+	return l, nil
+}
+
+func (s *InMemory) DeleteLimit(ctx context.Context, k LimitKey) error {
+	k, err := NormalizeLimitKey(k)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.limits[k]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.limits, k)
+	s.logLocked(walRecord{Limit: &limitChange{Limit: l, Deleted: true}})
+	return nil
+}
+
+func (s *InMemory) ListLimits(ctx context.Context, filter LimitKey) ([]Limit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Limit, 0, len(s.limits))
+	for _, l := range s.limits {
+		if (filter.Scope == "" || l.Scope == filter.Scope) && (filter.Subject == "" || l.Subject == filter.Subject) {
+			out = append(out, l)
+		}
+	}
+	SortLimits(out)
+	return out, nil
+}
+
+func (s *InMemory) LimitUtilization(ctx context.Context, k LimitKey) (Utilization, error) {
+	k, err := NormalizeLimitKey(k)
+	if err != nil {
+		return Utilization{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	l, ok := s.limits[k]
+	if !ok {
+		return Utilization{}, ErrNotFound
+	}
+	return s.utilizationLocked(l, time.Now()), nil
+}
+
+// utilizationLocked replays the journal back to the start of the day or
+// window of l. Callers must hold s.mu.
+func (s *InMemory) utilizationLocked(l Limit, now time.Time) Utilization {
+	u := NewUtilization(l, now)
+	since := u.Since()
+	for i := len(s.txs) - 1; i >= 0 && !s.txs[i].CreatedAt.Before(since); i-- {
+		tx := s.txs[i]
+		if !CountsTowardLimits(tx) {
+			continue
+		}
+		if amount := LimitDebits(tx.Legs(), s.orgsLocked(tx.Legs()))[l.Key()]; amount > 0 {
+			u.Add(tx.CreatedAt, amount)
+		}
+	}
+	return u
+}
+
+// orgsLocked maps the debited accounts of legs to their organizations.
+// Callers must hold s.mu.
+func (s *InMemory) orgsLocked(legs []Entry) map[string]string {
+	orgs := make(map[string]string, len(legs))
+	for _, e := range legs {
+		if acc, ok := s.accts[e.AccountID]; ok && e.Direction == Debit {
+			orgs[e.AccountID] = acc.OrganizationID
+		}
+	}
+	return orgs
+}
+
+// checkLimitsLocked fails with ErrLimitExceeded when the posting of legs
+// would break a limit of a debited account or its organization. Callers
+// must hold s.mu.
+func (s *InMemory) checkLimitsLocked(legs []Entry) error {
+	if len(s.limits) == 0 {
+		return nil
+	}
+	debits := LimitDebits(legs, s.orgsLocked(legs))
+	keys := make([]Limit, 0, len(debits))
+	for k := range debits {
+		if l, ok := s.limits[k]; ok {
+			keys = append(keys, l)
+		}
+	}
+	SortLimits(keys)
+	now := time.Now()
+	for _, l := range keys {
+		if err := s.utilizationLocked(l, now).Check(debits[l.Key()]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transferLegs lists the legs of a plain transfer of amt from fromID to toID.
+func transferLegs(fromID, toID string, amt Money) []Entry {
+	return Transaction{FromAccountID: fromID, ToAccountID: toID, Currency: amt.Currency, Amount: amt.Amount}.Legs()
+}
@@ -14,6 +14,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Client wraps the gRPC ledger service.
@@ -50,11 +51,18 @@ type Service struct {
 
 func NewService(client *Client) *Service { return &Service{client: client} }
 
-func (s *Service) CreateAccount(ctx context.Context, initial ledger.Money) (ledger.Account, error) {
+func (s *Service) CreateAccount(ctx context.Context, initial ledger.Money, opts ...ledger.AccountOption) (ledger.Account, error) {
+	tmpl, err := ledger.AccountTemplate(opts...)
+	if err != nil {
+		return ledger.Account{}, err
+	}
 	ctx = outgoingWithIdentity(ctx)
 	resp, err := s.client.svc.CreateAccount(ctx, &v1.CreateAccountRequest{
 		Currency:      initial.Currency,
 		InitialAmount: initial.Amount,
+		Type:          toProtoAccountType(tmpl.Type),
+		DisplayName:   tmpl.DisplayName,
+		ExternalRef:   tmpl.ExternalRef,
 	})
 	if err != nil {
 		return ledger.Account{}, mapLedgerError(err)
@@ -71,13 +79,45 @@ func (s *Service) GetAccount(ctx context.Context, id string) (ledger.Account, er
 	return fromProtoAccount(resp), nil
 }
 
-func (s *Service) GetBalance(ctx context.Context, id, currency string) (ledger.Money, error) {
+func (s *Service) SetAccountStatus(ctx context.Context, id string, status ledger.AccountStatus) (ledger.Account, error) {
+	ctx = outgoingWithIdentity(ctx)
+	resp, err := s.client.svc.SetAccountStatus(ctx, &v1.SetAccountStatusRequest{
+		Id:     id,
+		Status: toProtoAccountStatus(status),
+	})
+	if err != nil {
+		return ledger.Account{}, mapLedgerError(err)
+	}
+	return fromProtoAccount(resp), nil
+}
+
+func (s *Service) GetBalance(ctx context.Context, id, currency string) (ledger.Balance, error) {
 	ctx = outgoingWithIdentity(ctx)
 	resp, err := s.client.svc.GetBalance(ctx, &v1.GetBalanceRequest{Id: id, Currency: currency})
 	if err != nil {
-		return ledger.Money{}, mapLedgerError(err)
+		return ledger.Balance{}, mapLedgerError(err)
+	}
+	return ledger.Balance{
+		Money:     ledger.Money{Currency: resp.Currency, Amount: resp.Amount},
+		Held:      resp.Held,
+		Available: resp.Available,
+	}, nil
+}
+
+func (s *Service) GetBalanceAt(ctx context.Context, id, currency string, at ledger.BalancePoint) (ledger.HistoricalBalance, error) {
+	req := &v1.GetBalanceAtRequest{Id: id, Currency: currency, AsOfSequence: at.Sequence}
+	if !at.Time.IsZero() {
+		req.AsOfTime = timestamppb.New(at.Time)
+	}
+	ctx = outgoingWithIdentity(ctx)
+	resp, err := s.client.svc.GetBalanceAt(ctx, req)
+	if err != nil {
+		return ledger.HistoricalBalance{}, mapLedgerError(err)
 	}
-	return ledger.Money{Currency: resp.Currency, Amount: resp.Amount}, nil
+	return ledger.HistoricalBalance{
+		Money:    ledger.Money{Currency: resp.Currency, Amount: resp.Amount},
+		Sequence: resp.Sequence,
+	}, nil
 }
 
 func (s *Service) Transfer(ctx context.Context, fromID, toID string, amt ledger.Money, idemKey string) (ledger.Transaction, error) {
@@ -95,6 +135,98 @@ func (s *Service) Transfer(ctx context.Context, fromID, toID string, amt ledger.
 	return fromProtoTransaction(resp.Transaction), nil
 }
 
+func (s *Service) PostEntries(ctx context.Context, entries []ledger.Entry, idemKey string) (ledger.Transaction, error) {
+	ctx = outgoingWithIdentity(ctx)
+	req := &v1.PostEntriesRequest{
+		Entries:        make([]*v1.Entry, 0, len(entries)),
+		IdempotencyKey: idemKey,
+	}
+	for _, e := range entries {
+		req.Entries = append(req.Entries, toProtoEntry(e))
+	}
+	resp, err := s.client.svc.PostEntries(ctx, req)
+	if err != nil {
+		return ledger.Transaction{}, mapLedgerError(err)
+	}
+	return fromProtoTransaction(resp.Transaction), nil
+}
+
+func (s *Service) Reverse(ctx context.Context, txID string, amount int64, reason, idemKey string) (ledger.Transaction, error) {
+	ctx = outgoingWithIdentity(ctx)
+	resp, err := s.client.svc.Reverse(ctx, &v1.ReverseRequest{
+		TransactionId:  txID,
+		Amount:         amount,
+		Reason:         reason,
+		IdempotencyKey: idemKey,
+	})
+	if err != nil {
+		return ledger.Transaction{}, mapLedgerError(err)
+	}
+	return fromProtoTransaction(resp.Transaction), nil
+}
+
+func (s *Service) FXTransfer(ctx context.Context, fromID, toID string, amt ledger.Money, toCurrency, idemKey string) (ledger.Transaction, error) {
+	ctx = outgoingWithIdentity(ctx)
+	resp, err := s.client.svc.FXTransfer(ctx, &v1.FXTransferRequest{
+		FromId:         fromID,
+		ToId:           toID,
+		Currency:       amt.Currency,
+		Amount:         amt.Amount,
+		TargetCurrency: toCurrency,
+		IdempotencyKey: idemKey,
+	})
+	if err != nil {
+		return ledger.Transaction{}, mapLedgerError(err)
+	}
+	return fromProtoTransaction(resp.Transaction), nil
+}
+
+func (s *Service) CreateHold(ctx context.Context, fromID, toID string, amt ledger.Money, ttl time.Duration, idemKey string) (ledger.Hold, error) {
+	if ttl < 0 || ttl > ledger.MaxHoldTTL {
+		return ledger.Hold{}, ledger.ErrInvalidHoldTTL
+	}
+	ctx = outgoingWithIdentity(ctx)
+	resp, err := s.client.svc.CreateHold(ctx, &v1.CreateHoldRequest{
+		FromId:         fromID,
+		ToId:           toID,
+		Currency:       amt.Currency,
+		Amount:         amt.Amount,
+		TtlSeconds:     uint32(ttl / time.Second),
+		IdempotencyKey: idemKey,
+	})
+	if err != nil {
+		return ledger.Hold{}, mapLedgerError(err)
+	}
+	return fromProtoHold(resp), nil
+}
+
+func (s *Service) GetHold(ctx context.Context, id string) (ledger.Hold, error) {
+	ctx = outgoingWithIdentity(ctx)
+	resp, err := s.client.svc.GetHold(ctx, &v1.GetHoldRequest{Id: id})
+	if err != nil {
+		return ledger.Hold{}, mapLedgerError(err)
+	}
+	return fromProtoHold(resp), nil
+}
+
+func (s *Service) CaptureHold(ctx context.Context, id string, amount int64) (ledger.Hold, ledger.Transaction, error) {
+	ctx = outgoingWithIdentity(ctx)
+	resp, err := s.client.svc.CaptureHold(ctx, &v1.CaptureHoldRequest{Id: id, Amount: amount})
+	if err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, mapLedgerError(err)
+	}
+	return fromProtoHold(resp.Hold), fromProtoTransaction(resp.Transaction), nil
+}
+
+func (s *Service) VoidHold(ctx context.Context, id string) (ledger.Hold, error) {
+	ctx = outgoingWithIdentity(ctx)
+	resp, err := s.client.svc.VoidHold(ctx, &v1.VoidHoldRequest{Id: id})
+	if err != nil {
+		return ledger.Hold{}, mapLedgerError(err)
+	}
+	return fromProtoHold(resp), nil
+}
+
 func (s *Service) ListTransactions(ctx context.Context, limit int, afterSeq uint64) ([]ledger.Transaction, uint64, error) {
 	if limit <= 0 {
 		limit = 100
@@ -114,6 +246,35 @@ func (s *Service) ListTransactions(ctx context.Context, limit int, afterSeq uint
 	return items, resp.NextAfter, nil
 }
 
+func (s *Service) ListAccountTransactions(ctx context.Context, accountID string, f ledger.TransactionFilter) ([]ledger.Transaction, uint64, error) {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	req := &v1.ListAccountTransactionsRequest{
+		AccountId:     accountID,
+		Direction:     toProtoDirection(f.Direction),
+		Currency:      f.Currency,
+		AfterSequence: f.AfterSeq,
+		Limit:         uint32(f.Limit),
+	}
+	if !f.From.IsZero() {
+		req.From = timestamppb.New(f.From)
+	}
+	if !f.To.IsZero() {
+		req.To = timestamppb.New(f.To)
+	}
+	ctx = outgoingWithIdentity(ctx)
+	resp, err := s.client.svc.ListAccountTransactions(ctx, req)
+	if err != nil {
+		return nil, 0, mapLedgerError(err)
+	}
+	items := make([]ledger.Transaction, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		items = append(items, fromProtoTransaction(item))
+	}
+	return items, resp.NextAfter, nil
+}
+
 // Helpers -----------------------------------------------------------------
 
 func outgoingWithIdentity(ctx context.Context) context.Context {
@@ -127,6 +288,9 @@ func outgoingWithIdentity(ctx context.Context) context.Context {
 	if roles := auth.RolesFromContext(ctx); len(roles) > 0 {
 		pairs = append(pairs, "x-qazna-roles", strings.Join(roles, ","))
 	}
+	if orgID, ok := ledger.OrganizationScope(ctx); ok {
+		pairs = append(pairs, "x-qazna-org-scope", orgID)
+	}
 	if len(pairs) == 0 {
 		return ctx
 	}
@@ -143,10 +307,37 @@ func fromProtoAccount(a *v1.Account) ledger.Account {
 		created = ts.AsTime()
 	}
 	return ledger.Account{
-		ID:        a.Id,
-		CreatedAt: created,
-		Balances:  balances,
+		ID:             a.Id,
+		OrganizationID: a.GetOrganizationId(),
+		Type:           fromProtoAccountType(a.GetType()),
+		DisplayName:    a.GetDisplayName(),
+		ExternalRef:    a.GetExternalRef(),
+		Status:         fromProtoAccountStatus(a.GetStatus()),
+		CreatedAt:      created,
+		Balances:       balances,
+	}
+}
+
+func toProtoAccountType(t ledger.AccountType) v1.AccountType {
+	return v1.AccountType(v1.AccountType_value["ACCOUNT_TYPE_"+strings.ToUpper(string(t))])
+}
+
+func fromProtoAccountType(t v1.AccountType) ledger.AccountType {
+	if t == v1.AccountType_ACCOUNT_TYPE_UNSPECIFIED {
+		return ""
+	}
+	return ledger.AccountType(strings.ToLower(strings.TrimPrefix(t.String(), "ACCOUNT_TYPE_")))
+}
+
+func toProtoAccountStatus(st ledger.AccountStatus) v1.AccountStatus {
+	return v1.AccountStatus(v1.AccountStatus_value["ACCOUNT_STATUS_"+strings.ToUpper(string(st))])
+}
+
+func fromProtoAccountStatus(st v1.AccountStatus) ledger.AccountStatus {
+	if st == v1.AccountStatus_ACCOUNT_STATUS_UNSPECIFIED {
+		return ""
 	}
+	return ledger.AccountStatus(strings.ToLower(strings.TrimPrefix(st.String(), "ACCOUNT_STATUS_")))
 }
 
 func fromProtoTransaction(tx *v1.Transaction) ledger.Transaction {
@@ -154,7 +345,7 @@ func fromProtoTransaction(tx *v1.Transaction) ledger.Transaction {
 	if ts := tx.GetCreatedAt(); ts != nil {
 		created = ts.AsTime()
 	}
-	return ledger.Transaction{
+	out := ledger.Transaction{
 		ID:             tx.Id,
 		CreatedAt:      created,
 		FromAccountID:  tx.FromAccountId,
@@ -163,6 +354,87 @@ func fromProtoTransaction(tx *v1.Transaction) ledger.Transaction {
 		Amount:         tx.Amount,
 		IdempotencyKey: tx.IdempotencyKey,
 		Sequence:       tx.Sequence,
+		ReversalOf:     tx.ReversalOf,
+		ReversalReason: tx.ReversalReason,
+		FXRateID:       tx.FxRateId,
+		FXRate:         tx.FxRate,
+		Kind:           ledger.TransactionKind(tx.Kind),
+	}
+	if f := tx.GetFee(); f != nil {
+		out.Fee = &ledger.Fee{
+			ScheduleVersion: int(f.ScheduleVersion),
+			ParticipantType: f.ParticipantType,
+			Rate:            f.Rate,
+			Alpha:           f.Alpha,
+			Beta:            f.Beta,
+			Rounding:        ledger.Rounding(f.Rounding),
+			AccountID:       f.AccountId,
+			Currency:        f.Currency,
+			Amount:          f.Amount,
+		}
+	}
+	for _, e := range tx.GetEntries() {
+		out.Entries = append(out.Entries, fromProtoEntry(e))
+	}
+	out.SetReversed(tx.ReversedAmount)
+	return out
+}
+
+func fromProtoHold(h *v1.Hold) ledger.Hold {
+	out := ledger.Hold{
+		ID:             h.GetId(),
+		FromAccountID:  h.GetFromAccountId(),
+		ToAccountID:    h.GetToAccountId(),
+		Currency:       h.GetCurrency(),
+		Amount:         h.GetAmount(),
+		CapturedAmount: h.GetCapturedAmount(),
+		TransactionID:  h.GetTransactionId(),
+		IdempotencyKey: h.GetIdempotencyKey(),
+	}
+	if ts := h.GetCreatedAt(); ts != nil {
+		out.CreatedAt = ts.AsTime()
+	}
+	if ts := h.GetExpiresAt(); ts != nil {
+		out.ExpiresAt = ts.AsTime()
+	}
+	if st := h.GetStatus(); st != v1.HoldStatus_HOLD_STATUS_UNSPECIFIED {
+		out.Status = ledger.HoldStatus(strings.ToLower(strings.TrimPrefix(st.String(), "HOLD_STATUS_")))
+	}
+	return out
+}
+
+func toProtoDirection(d ledger.Direction) v1.EntryDirection {
+	switch d {
+	case ledger.Debit:
+		return v1.EntryDirection_ENTRY_DIRECTION_DEBIT
+	case ledger.Credit:
+		return v1.EntryDirection_ENTRY_DIRECTION_CREDIT
+	}
+	return v1.EntryDirection_ENTRY_DIRECTION_UNSPECIFIED
+}
+
+func toProtoEntry(e ledger.Entry) *v1.Entry {
+	return &v1.Entry{
+		AccountId: e.AccountID,
+		Direction: toProtoDirection(e.Direction),
+		Currency:  e.Currency,
+		Amount:    e.Amount,
+	}
+}
+
+func fromProtoEntry(e *v1.Entry) ledger.Entry {
+	var dir ledger.Direction
+	switch e.GetDirection() {
+	case v1.EntryDirection_ENTRY_DIRECTION_DEBIT:
+		dir = ledger.Debit
+	case v1.EntryDirection_ENTRY_DIRECTION_CREDIT:
+		dir = ledger.Credit
+	}
+	return ledger.Entry{
+		AccountID: e.GetAccountId(),
+		Direction: dir,
+		Currency:  e.GetCurrency(),
+		Amount:    e.GetAmount(),
 	}
 }
 
@@ -191,10 +463,28 @@ func mapLedgerError(err error) error {
 		return ledger.ErrNotFound
 	case codes.InvalidArgument:
 		switch msg {
+		case strings.ToLower(ledger.ErrUnbalanced.Error()):
+			return ledger.ErrUnbalanced
 		case strings.ToLower(ledger.ErrInvalidAmount.Error()), "invalid amount":
 			return ledger.ErrInvalidAmount
 		case strings.ToLower(ledger.ErrInvalidCurrency.Error()), "invalid currency":
 			return ledger.ErrInvalidCurrency
+		case strings.ToLower(ledger.ErrInvalidAccountType.Error()):
+			return ledger.ErrInvalidAccountType
+		case strings.ToLower(ledger.ErrAccountLabelTooLong.Error()):
+			return ledger.ErrAccountLabelTooLong
+		case strings.ToLower(ledger.ErrInvalidAccountStatus.Error()):
+			return ledger.ErrInvalidAccountStatus
+		case strings.ToLower(ledger.ErrInvalidBalancePoint.Error()):
+			return ledger.ErrInvalidBalancePoint
+		case strings.ToLower(ledger.ErrInvalidReversalReason.Error()):
+			return ledger.ErrInvalidReversalReason
+		case strings.ToLower(ledger.ErrInvalidHoldTTL.Error()):
+			return ledger.ErrInvalidHoldTTL
+		case strings.ToLower(ledger.ErrInvalidFXRate.Error()):
+			return ledger.ErrInvalidFXRate
+		case strings.ToLower(ledger.ErrInvalidLimit.Error()):
+			return ledger.ErrInvalidLimit
 		default:
 			if strings.Contains(msg, "currency") {
 				return ledger.ErrInvalidCurrency
@@ -205,10 +495,44 @@ func mapLedgerError(err error) error {
 			return ledger.ErrInvalidAmount
 		}
 	case codes.FailedPrecondition:
+		switch msg {
+		case strings.ToLower(ledger.ErrAccountFrozen.Error()):
+			return ledger.ErrAccountFrozen
+		case strings.ToLower(ledger.ErrAccountClosed.Error()):
+			return ledger.ErrAccountClosed
+		case strings.ToLower(ledger.ErrAccountNotEmpty.Error()):
+			return ledger.ErrAccountNotEmpty
+		case strings.ToLower(ledger.ErrAlreadyReversed.Error()):
+			return ledger.ErrAlreadyReversed
+		case strings.ToLower(ledger.ErrReversalExceedsOriginal.Error()):
+			return ledger.ErrReversalExceedsOriginal
+		case strings.ToLower(ledger.ErrNotReversible.Error()):
+			return ledger.ErrNotReversible
+		case strings.ToLower(ledger.ErrHoldNotPending.Error()):
+			return ledger.ErrHoldNotPending
+		case strings.ToLower(ledger.ErrHoldExpired.Error()):
+			return ledger.ErrHoldExpired
+		case strings.ToLower(ledger.ErrCaptureExceedsHold.Error()):
+			return ledger.ErrCaptureExceedsHold
+		case strings.ToLower(ledger.ErrNoFXRate.Error()):
+			return ledger.ErrNoFXRate
+		case strings.ToLower(ledger.ErrNoFXLiquidity.Error()):
+			return ledger.ErrNoFXLiquidity
+		case strings.ToLower(ledger.ErrNotIssuer.Error()):
+			return ledger.ErrNotIssuer
+		case strings.ToLower(ledger.ErrIssuanceAccount.Error()):
+			return ledger.ErrIssuanceAccount
+		}
 		if strings.Contains(msg, "insufficient") {
 			return ledger.ErrInsufficientFunds
 		}
 		return ledger.ErrInsufficientFunds
+	case codes.ResourceExhausted:
+		// gRPC reports oversized messages with the same code.
+		if strings.HasPrefix(msg, strings.ToLower(ledger.ErrLimitExceeded.Error())) {
+			return ledger.ErrLimitExceeded
+		}
+		return err
 	default:
 		return err
 	}
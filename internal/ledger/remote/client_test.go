@@ -33,11 +33,46 @@ func TestMapLedgerError(t *testing.T) {
 			err:  status.Error(codes.InvalidArgument, "invalid amount (must be > 0)"),
 			want: ledger.ErrInvalidAmount,
 		},
+		{
+			name: "unbalanced entries",
+			err:  status.Error(codes.InvalidArgument, "unbalanced entries"),
+			want: ledger.ErrUnbalanced,
+		},
+		{
+			name: "account frozen",
+			err:  status.Error(codes.FailedPrecondition, "account frozen"),
+			want: ledger.ErrAccountFrozen,
+		},
+		{
+			name: "account not empty",
+			err:  status.Error(codes.FailedPrecondition, "account has non-zero balance"),
+			want: ledger.ErrAccountNotEmpty,
+		},
+		{
+			name: "reversal exceeds original",
+			err:  status.Error(codes.FailedPrecondition, "reversal exceeds original amount"),
+			want: ledger.ErrReversalExceedsOriginal,
+		},
+		{
+			name: "invalid account type",
+			err:  status.Error(codes.InvalidArgument, "invalid account type"),
+			want: ledger.ErrInvalidAccountType,
+		},
 		{
 			name: "insufficient funds",
 			err:  status.Error(codes.FailedPrecondition, "insufficient funds"),
 			want: ledger.ErrInsufficientFunds,
 		},
+		{
+			name: "limit exceeded",
+			err:  status.Error(codes.ResourceExhausted, "transfer limit exceeded: daily_amount of account acc-1 in KZT"),
+			want: ledger.ErrLimitExceeded,
+		},
+		{
+			name: "resource exhausted pass through",
+			err:  status.Error(codes.ResourceExhausted, "grpc: received message larger than max"),
+			want: status.Error(codes.ResourceExhausted, "grpc: received message larger than max"),
+		},
 		{
 			name: "pass through",
 			err:  status.Error(codes.Internal, "internal"),
@@ -0,0 +1,109 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	v1 "qazna.org/api/gen/go/api/proto/qazna/v1"
+	"qazna.org/internal/ledger"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Reconnect backoff of WatchTransactions. It doubles after every failed
+// attempt and resets once a transaction arrives.
+var (
+	watchMinBackoff = 100 * time.Millisecond
+	watchMaxBackoff = 5 * time.Second
+)
+
+// WatchTransactions calls fn with every transaction after the given
+// sequence that passes f, first the history and then new commits as they
+// happen. When the stream breaks it reconnects with backoff and resumes
+// after the last transaction handed to fn, so fn sees each transaction once
+// and in sequence order. It returns when ctx ends, fn fails or the server
+// rejects the request.
+func (c *Client) WatchTransactions(ctx context.Context, after uint64, f ledger.WatchFilter, fn func(ledger.Transaction) error) error {
+	req := &v1.WatchTransactionsRequest{
+		AfterSequence: after,
+		AccountId:     f.AccountID,
+		Direction:     toProtoDirection(f.Direction),
+		Currency:      f.Currency,
+	}
+	octx := outgoingWithIdentity(ctx)
+	backoff := watchMinBackoff
+	for {
+		err := c.watchOnce(octx, req, func(tx ledger.Transaction) error {
+			if err := fn(tx); err != nil {
+				return err
+			}
+			req.AfterSequence = tx.Sequence
+			backoff = watchMinBackoff
+			return nil
+		})
+		var cbErr callbackError
+		switch {
+		case errors.As(err, &cbErr):
+			return cbErr.err
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !retryableWatchError(err):
+			return mapLedgerError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, watchMaxBackoff)
+	}
+}
+
+// callbackError marks an error returned by the caller's fn, which ends the
+// watch instead of triggering a reconnect.
+type callbackError struct{ err error }
+
+func (e callbackError) Error() string { return e.err.Error() }
+
+// watchOnce consumes one stream until it breaks.
+func (c *Client) watchOnce(ctx context.Context, req *v1.WatchTransactionsRequest, fn func(ledger.Transaction) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.svc.WatchTransactions(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := fn(fromProtoTransaction(msg)); err != nil {
+			return callbackError{err}
+		}
+	}
+}
+
+// retryableWatchError reports whether a broken stream is worth reopening. A
+// clean end of stream means the server went away, e.g. while restarting.
+func retryableWatchError(err error) bool {
+	if errors.Is(err, io.EOF) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.NotFound, codes.PermissionDenied, codes.Unauthenticated,
+		codes.Unimplemented, codes.FailedPrecondition, codes.OutOfRange:
+		return false
+	}
+	return true
+}
+
+var _ ledger.Watcher = (*Service)(nil)
+
+// WatchTransactions implements ledger.Watcher over the remote feed.
+func (s *Service) WatchTransactions(ctx context.Context, after uint64, fn func(ledger.Transaction) error) error {
+	return s.client.WatchTransactions(ctx, after, ledger.WatchFilter{}, fn)
+}
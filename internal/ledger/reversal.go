@@ -0,0 +1,139 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ReversalStatus reports how much of a transaction has been returned by
+// reversals.
+type ReversalStatus string
+
+const (
+	NotReversed       ReversalStatus = ""
+	PartiallyReversed ReversalStatus = "partially_reversed"
+	Reversed          ReversalStatus = "reversed"
+)
+
+var (
+	ErrAlreadyReversed         = errors.New("transaction already reversed")
+	ErrReversalExceedsOriginal = errors.New("reversal exceeds original amount")
+	ErrNotReversible           = errors.New("reversal transactions cannot be reversed")
+	ErrInvalidReversalReason   = errors.New("reversal reason is required and must be at most 512 characters")
+)
+
+const maxReversalReason = 512
+
+// Gross is the amount a full reversal of tx returns: the transfer amount, or
+// the sum of the debit legs of a batch posting.
+func (tx Transaction) Gross() int64 {
+	if len(tx.Entries) == 0 {
+		return tx.Amount
+	}
+	var total int64
+	for _, e := range tx.Entries {
+		if e.Direction == Debit {
+			total += e.Amount
+		}
+	}
+	return total
+}
+
+// SetReversed records that reversals have returned total of tx so far and
+// derives ReversalStatus from it.
+func (tx *Transaction) SetReversed(total int64) {
+	tx.ReversedAmount = total
+	switch {
+	case total <= 0:
+		tx.ReversalStatus = NotReversed
+	case total < tx.Gross():
+		tx.ReversalStatus = PartiallyReversed
+	default:
+		tx.ReversalStatus = Reversed
+	}
+}
+
+// PlanReversal validates a reversal of amount against orig and returns the
+// compensating transaction, with the original's legs swapped. An amount of
+// zero reverses whatever has not been reversed yet. Batch postings can only
+// be reversed in full.
+func PlanReversal(orig Transaction, amount int64, reason string) (Transaction, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxReversalReason {
+		return Transaction{}, ErrInvalidReversalReason
+	}
+	if amount < 0 {
+		return Transaction{}, ErrInvalidAmount
+	}
+	if orig.ReversalOf != "" {
+		return Transaction{}, ErrNotReversible
+	}
+	remaining := orig.Gross() - orig.ReversedAmount
+	if remaining <= 0 {
+		return Transaction{}, ErrAlreadyReversed
+	}
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		return Transaction{}, ErrReversalExceedsOriginal
+	}
+
+	rev := Transaction{ReversalOf: orig.ID, ReversalReason: reason}
+	if len(orig.Entries) == 0 {
+		rev.FromAccountID = orig.ToAccountID
+		rev.ToAccountID = orig.FromAccountID
+		rev.Currency = orig.Currency
+		rev.Amount = amount
+		return rev, nil
+	}
+	if amount != remaining {
+		return Transaction{}, ErrInvalidAmount
+	}
+	for _, e := range orig.Entries {
+		e.Direction = opposite(e.Direction)
+		rev.Entries = append(rev.Entries, e)
+	}
+	return rev, nil
+}
+
+func opposite(d Direction) Direction {
+	if d == Debit {
+		return Credit
+	}
+	return Debit
+}
+
+func (s *InMemory) Reverse(ctx context.Context, txID string, amount int64, reason, idemKey string) (Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idemKey != "" {
+		if i, ok := s.idem[idemKey]; ok {
+			return s.txs[i], nil
+		}
+	}
+	i, ok := s.index[txID]
+	if !ok || !s.touchesVisible(ctx, s.txs[i]) {
+		return Transaction{}, ErrNotFound
+	}
+	orig := &s.txs[i]
+	rev, err := PlanReversal(*orig, amount, reason)
+	if err != nil {
+		return Transaction{}, err
+	}
+	if len(rev.Entries) == 0 {
+		err = s.applyTransfer(ctx, rev.FromAccountID, rev.ToAccountID, Money{Currency: rev.Currency, Amount: rev.Amount})
+	} else {
+		err = s.applyEntries(ctx, rev.Entries)
+	}
+	if err != nil {
+		return Transaction{}, err
+	}
+	orig.SetReversed(orig.ReversedAmount + rev.Gross())
+	rev.IdempotencyKey = idemKey
+	rev = s.record(rev)
+	s.logLocked(walRecord{Tx: &rev})
+	return rev, nil
+}
@@ -0,0 +1,36 @@
+package ledger
+
+import "context"
+
+type scopeKey struct{}
+
+// WithOrganizationScope restricts ledger calls made with ctx to the accounts
+// owned by orgID. Accounts created under a scope are owned by orgID; an empty
+// orgID scopes the caller to accounts without an owner.
+func WithOrganizationScope(ctx context.Context, orgID string) context.Context {
+	return context.WithValue(ctx, scopeKey{}, orgID)
+}
+
+// OrganizationScope reports the organization ctx is restricted to. ok is
+// false for unrestricted callers such as platform operators.
+func OrganizationScope(ctx context.Context) (orgID string, ok bool) {
+	orgID, ok = ctx.Value(scopeKey{}).(string)
+	return orgID, ok
+}
+
+// Visible reports whether an account owned by ownerOrgID may be read or
+// debited under ctx. Accounts outside the scope are reported as ErrNotFound
+// so their existence does not leak across tenants.
+func Visible(ctx context.Context, ownerOrgID string) bool {
+	orgID, scoped := OrganizationScope(ctx)
+	return !scoped || orgID == ownerOrgID
+}
+
+type unscoped struct{}
+
+// WithoutOrganizationScope lifts the scope of ctx. Service implementations
+// use it for legs the caller does not own but the ledger posts on its behalf,
+// such as the liquidity side of an FX conversion.
+func WithoutOrganizationScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, unscoped{})
+}
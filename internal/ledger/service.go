@@ -7,76 +7,186 @@ import (
 )
 
 // Service defines ledger operations.
+//
+// Implementations honour the organization scope carried by ctx (see
+// WithOrganizationScope): new accounts belong to the scoped organization,
+// accounts of other organizations read as ErrNotFound and cannot be debited,
+// and ListTransactions only returns transactions touching a visible account.
+//
+// Accounts, transfers, postings and holds must use a currency known to the
+// CurrencyRegistry and enabled there, or fail with ErrInvalidCurrency;
+// amounts outside the currency limits fail with ErrInvalidAmount.
+//
+// Debits from frozen or closed accounts fail with ErrAccountFrozen or
+// ErrAccountClosed; credits only fail for closed accounts. Issuance accounts
+// are only touched by Issuance; other postings to or from them fail with
+// ErrIssuanceAccount. Debits are
+// checked against the available balance, net of pending holds.
 type Service interface {
-	CreateAccount(ctx context.Context, initial Money) (Account, error)
+	CreateAccount(ctx context.Context, initial Money, opts ...AccountOption) (Account, error)
 	GetAccount(ctx context.Context, id string) (Account, error)
-	GetBalance(ctx context.Context, id, currency string) (Money, error)
+	// SetAccountStatus freezes, unfreezes or closes an account. Closing
+	// requires all balances to be zero and cannot be undone.
+	SetAccountStatus(ctx context.Context, id string, status AccountStatus) (Account, error)
+	// GetBalance returns the ledger balance together with the amount held
+	// by pending holds and what remains available.
+	GetBalance(ctx context.Context, id, currency string) (Balance, error)
+	// GetBalanceAt recomputes the balance of an account as of a past sequence
+	// or time from the transaction history, including the initial funding.
+	// Points before the account was created yield zero.
+	GetBalanceAt(ctx context.Context, id, currency string, at BalancePoint) (HistoricalBalance, error)
 	Transfer(ctx context.Context, fromID, toID string, amt Money, idemKey string) (Transaction, error)
+	// PostEntries commits all legs atomically under a single sequence number.
+	PostEntries(ctx context.Context, entries []Entry, idemKey string) (Transaction, error)
+	// Reverse posts a compensating transaction that returns amount of txID
+	// (everything not yet reversed when amount is 0) and links it to the
+	// original. The accounts the reversal debits must be visible under ctx.
+	// Batch postings can only be reversed in full.
+	Reverse(ctx context.Context, txID string, amount int64, reason, idemKey string) (Transaction, error)
+	// CreateHold reserves amt on fromID for a later transfer to toID. A zero
+	// ttl means DefaultHoldTTL; pending holds expire after it.
+	CreateHold(ctx context.Context, fromID, toID string, amt Money, ttl time.Duration, idemKey string) (Hold, error)
+	GetHold(ctx context.Context, id string) (Hold, error)
+	// CaptureHold transfers amount (all of it when 0) of a pending hold and
+	// releases the rest.
+	CaptureHold(ctx context.Context, id string, amount int64) (Hold, Transaction, error)
+	VoidHold(ctx context.Context, id string) (Hold, error)
+	// FXTransfer converts amt on fromID into toCurrency on toID at the
+	// current registered rate, settling each side against the configured FX
+	// liquidity accounts.
+	FXTransfer(ctx context.Context, fromID, toID string, amt Money, toCurrency, idemKey string) (Transaction, error)
 	ListTransactions(ctx context.Context, limit int, afterSeq uint64) ([]Transaction, uint64, error)
+	// ListAccountTransactions pages the history of one account in sequence
+	// order. The account must be visible under ctx.
+	ListAccountTransactions(ctx context.Context, accountID string, f TransactionFilter) ([]Transaction, uint64, error)
 }
 
-// InMemory implements Service with in-process concurrency safety.
-// NOTE: Replace with durable storage later (FoundationDB/Postgres).
+// InMemory implements Service with in-process concurrency safety. State is
+// lost on restart unless the ledger is opened with OpenDurable.
 type InMemory struct {
-	mu    sync.RWMutex
-	accts map[string]*Account
-	seq   uint64
-	txs   []Transaction
-	idem  map[string]Transaction // idemKey -> tx
+	mu         sync.RWMutex
+	accts      map[string]*Account
+	openings   map[string]opening
+	seq        uint64
+	txs        []Transaction
+	index      map[string]int // tx id -> position in txs
+	idem       map[string]int // idemKey -> position in txs
+	holds      map[string]*Hold
+	pending    map[string]*Hold  // holds not yet captured, voided or found expired
+	holdIdem   map[string]string // idemKey -> hold id
+	rates      []FXRate
+	liquidity  map[string]string   // currency -> FX liquidity account
+	issuers    map[string]string   // currency -> issuer account
+	fees       []FeeSchedule       // fee schedules in version order
+	limits     map[LimitKey]Limit  // transfer limits by scope, subject and currency
+	currencies map[string]Currency // registered currencies, over the built-in ones
+	wal        *wal                // set by OpenDurable
+	commits    chan struct{}       // closed and replaced on every new transaction
 }
 
 // NewInMemory creates a fresh ledger.
 func NewInMemory() *InMemory {
 	return &InMemory{
-		accts: make(map[string]*Account),
-		idem:  make(map[string]Transaction),
+		accts:      make(map[string]*Account),
+		openings:   make(map[string]opening),
+		index:      make(map[string]int),
+		idem:       make(map[string]int),
+		holds:      make(map[string]*Hold),
+		pending:    make(map[string]*Hold),
+		holdIdem:   make(map[string]string),
+		liquidity:  make(map[string]string),
+		issuers:    make(map[string]string),
+		limits:     make(map[LimitKey]Limit),
+		currencies: make(map[string]Currency),
+		commits:    make(chan struct{}),
 	}
 }
 
-func (s *InMemory) CreateAccount(ctx context.Context, initial Money) (Account, error) {
+func (s *InMemory) CreateAccount(ctx context.Context, initial Money, opts ...AccountOption) (Account, error) {
 	if initial.Currency == "" {
 		return Account{}, ErrInvalidCurrency
 	}
 	if initial.Amount < 0 {
 		return Account{}, ErrInvalidAmount
 	}
+	acc, err := AccountTemplate(opts...)
+	if err != nil {
+		return Account{}, err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	id := newID()
-	acc := &Account{
-		ID:        id,
-		CreatedAt: time.Now().UTC(),
-		Balances:  map[string]int64{initial.Currency: initial.Amount},
+	cur, err := ResolveCurrency(s.currencies, initial.Currency)
+	if err != nil {
+		return Account{}, err
+	}
+	if err := cur.CheckEnabled(); err != nil {
+		return Account{}, err
 	}
-	s.accts[id] = acc
-	return *acc, nil
+	acc.ID = newID()
+	acc.OrganizationID, _ = OrganizationScope(ctx)
+	acc.CreatedAt = time.Now().UTC()
+	acc.Balances = map[string]int64{initial.Currency: initial.Amount}
+	s.accts[acc.ID] = &acc
+	s.openings[acc.ID] = opening{Money: initial, seq: s.seq}
+	s.logLocked(walRecord{Account: &storedAccount{Account: copyAccount(&acc), Opening: initial, OpeningSeq: s.seq}})
+	return copyAccount(&acc), nil
 }
 
 func (s *InMemory) GetAccount(ctx context.Context, id string) (Account, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	acc, ok := s.accts[id]
-	if !ok {
+	if !ok || !Visible(ctx, acc.OrganizationID) {
 		return Account{}, ErrNotFound
 	}
-	// return copy
+	return copyAccount(acc), nil
+}
+
+func (s *InMemory) SetAccountStatus(ctx context.Context, id string, status AccountStatus) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acc, ok := s.accts[id]
+	if !ok || !Visible(ctx, acc.OrganizationID) {
+		return Account{}, ErrNotFound
+	}
+	if err := acc.Status.CheckTransition(status); err != nil {
+		return Account{}, err
+	}
+	if status == AccountClosed {
+		for _, v := range acc.Balances {
+			if v != 0 {
+				return Account{}, ErrAccountNotEmpty
+			}
+		}
+	}
+	acc.Status = status
+	s.logLocked(walRecord{Status: &statusChange{AccountID: id, Status: status}})
+	return copyAccount(acc), nil
+}
+
+func copyAccount(acc *Account) Account {
 	out := *acc
-	out.Balances = map[string]int64{}
+	out.Balances = make(map[string]int64, len(acc.Balances))
 	for k, v := range acc.Balances {
 		out.Balances[k] = v
 	}
-	return out, nil
+	return out
 }
 
-func (s *InMemory) GetBalance(ctx context.Context, id, currency string) (Money, error) {
+func (s *InMemory) GetBalance(ctx context.Context, id, currency string) (Balance, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	acc, ok := s.accts[id]
-	if !ok {
-		return Money{}, ErrNotFound
+	if !ok || !Visible(ctx, acc.OrganizationID) {
+		return Balance{}, ErrNotFound
 	}
-	return Money{Currency: currency, Amount: acc.Balances[currency]}, nil
+	held := s.heldLocked(id, currency, time.Now())
+	return Balance{
+		Money:     Money{Currency: currency, Amount: acc.Balances[currency]},
+		Held:      held,
+		Available: acc.Balances[currency] - held,
+	}, nil
 }
 
 func (s *InMemory) Transfer(ctx context.Context, fromID, toID string, amt Money, idemKey string) (Transaction, error) {
@@ -92,48 +202,152 @@ func (s *InMemory) Transfer(ctx context.Context, fromID, toID string, amt Money,
 
 	// Idempotency
 	if idemKey != "" {
-		if tx, ok := s.idem[idemKey]; ok {
-			return tx, nil
+		if i, ok := s.idem[idemKey]; ok {
+			return s.txs[i], nil
 		}
 	}
+	if err := s.checkCurrencyLocked(amt.Currency, amt.Amount); err != nil {
+		return Transaction{}, err
+	}
+	if err := s.checkLimitsLocked(transferLegs(fromID, toID, amt)); err != nil {
+		return Transaction{}, err
+	}
+	if err := s.applyTransfer(ctx, fromID, toID, amt); err != nil {
+		return Transaction{}, err
+	}
+	tx := s.record(Transaction{
+		FromAccountID:  fromID,
+		ToAccountID:    toID,
+		Currency:       amt.Currency,
+		Amount:         amt.Amount,
+		IdempotencyKey: idemKey,
+	})
+	s.logLocked(walRecord{Tx: &tx})
+	return tx, nil
+}
 
+// applyTransfer checks and moves amt from fromID to toID. Callers must hold
+// s.mu for writing.
+func (s *InMemory) applyTransfer(ctx context.Context, fromID, toID string, amt Money) error {
 	from, ok := s.accts[fromID]
-	if !ok {
-		return Transaction{}, ErrNotFound
+	if !ok || !Visible(ctx, from.OrganizationID) {
+		return ErrNotFound
 	}
 	to, ok := s.accts[toID]
 	if !ok {
-		return Transaction{}, ErrNotFound
+		return ErrNotFound
+	}
+	if err := from.Status.CheckDebit(); err != nil {
+		return err
+	}
+	if err := to.Status.CheckCredit(); err != nil {
+		return err
+	}
+	if from.Type == AccountTypeIssuance || to.Type == AccountTypeIssuance {
+		return ErrIssuanceAccount
 	}
 
 	// Double-entry invariant: total debits == total credits (same currency).
 	// Enforce sufficient funds.
-	if from.Balances[amt.Currency] < amt.Amount {
-		return Transaction{}, ErrInsufficientFunds
+	if s.availableLocked(fromID, amt.Currency, time.Now()) < amt.Amount {
+		return ErrInsufficientFunds
 	}
 
 	// Apply mutation
 	from.Balances[amt.Currency] -= amt.Amount
 	to.Balances[amt.Currency] += amt.Amount
+	return nil
+}
 
-	s.seq++
-	tx := Transaction{
-		ID:             newID(),
-		CreatedAt:      time.Now().UTC(),
-		FromAccountID:  fromID,
-		ToAccountID:    toID,
-		Currency:       amt.Currency,
-		Amount:         amt.Amount,
-		IdempotencyKey: idemKey,
-		Sequence:       s.seq,
+func (s *InMemory) PostEntries(ctx context.Context, entries []Entry, idemKey string) (Transaction, error) {
+	if err := ValidateEntries(entries); err != nil {
+		return Transaction{}, err
 	}
-	s.txs = append(s.txs, tx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if idemKey != "" {
-		s.idem[idemKey] = tx
+		if i, ok := s.idem[idemKey]; ok {
+			return s.txs[i], nil
+		}
 	}
+	if err := s.checkLegsLocked(entries); err != nil {
+		return Transaction{}, err
+	}
+	if err := s.checkLimitsLocked(entries); err != nil {
+		return Transaction{}, err
+	}
+	if err := s.applyEntries(ctx, entries); err != nil {
+		return Transaction{}, err
+	}
+	tx := s.record(Transaction{
+		IdempotencyKey: idemKey,
+		Entries:        append([]Entry(nil), entries...),
+	})
+	s.logLocked(walRecord{Tx: &tx})
 	return tx, nil
 }
 
+// applyEntries checks and applies validated batch legs. Callers must hold
+// s.mu for writing.
+func (s *InMemory) applyEntries(ctx context.Context, entries []Entry) error {
+	// Net the legs per account and currency so an account that is both
+	// debited and credited in the same batch is only checked once.
+	type key struct{ account, currency string }
+	deltas := make(map[key]int64)
+	for _, e := range entries {
+		acc, ok := s.accts[e.AccountID]
+		if !ok || (e.Direction == Debit && !Visible(ctx, acc.OrganizationID)) {
+			return ErrNotFound
+		}
+		if acc.Type == AccountTypeIssuance {
+			return ErrIssuanceAccount
+		}
+		k := key{e.AccountID, e.Currency}
+		if e.Direction == Debit {
+			if err := acc.Status.CheckDebit(); err != nil {
+				return err
+			}
+			deltas[k] -= e.Amount
+		} else {
+			if err := acc.Status.CheckCredit(); err != nil {
+				return err
+			}
+			deltas[k] += e.Amount
+		}
+	}
+	now := time.Now()
+	for k, d := range deltas {
+		if d < 0 && s.availableLocked(k.account, k.currency, now) < -d {
+			return ErrInsufficientFunds
+		}
+	}
+
+	// Apply mutation
+	for k, d := range deltas {
+		s.accts[k.account].Balances[k.currency] += d
+	}
+	return nil
+}
+
+// record assigns tx the next sequence number and appends it to the journal.
+// Callers must hold s.mu for writing.
+func (s *InMemory) record(tx Transaction) Transaction {
+	s.seq++
+	tx.ID = newID()
+	tx.CreatedAt = time.Now().UTC()
+	tx.Sequence = s.seq
+	s.index[tx.ID] = len(s.txs)
+	if tx.IdempotencyKey != "" {
+		s.idem[tx.IdempotencyKey] = len(s.txs)
+	}
+	s.txs = append(s.txs, tx)
+	close(s.commits)
+	s.commits = make(chan struct{})
+	return tx
+}
+
 func (s *InMemory) ListTransactions(ctx context.Context, limit int, afterSeq uint64) ([]Transaction, uint64, error) {
 	if limit <= 0 || limit > 1000 {
 		limit = 100
@@ -143,7 +357,7 @@ func (s *InMemory) ListTransactions(ctx context.Context, limit int, afterSeq uin
 	var res []Transaction
 	var last uint64
 	for _, tx := range s.txs {
-		if tx.Sequence <= afterSeq {
+		if tx.Sequence <= afterSeq || !s.touchesVisible(ctx, tx) {
 			continue
 		}
 		res = append(res, tx)
@@ -154,3 +368,46 @@ func (s *InMemory) ListTransactions(ctx context.Context, limit int, afterSeq uin
 	}
 	return res, last, nil
 }
+
+func (s *InMemory) ListAccountTransactions(ctx context.Context, accountID string, f TransactionFilter) ([]Transaction, uint64, error) {
+	if f.Limit <= 0 || f.Limit > 1000 {
+		f.Limit = 100
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	acc, ok := s.accts[accountID]
+	if !ok || !Visible(ctx, acc.OrganizationID) {
+		return nil, 0, ErrNotFound
+	}
+	var res []Transaction
+	var last uint64
+	for _, tx := range s.txs {
+		if !f.match(accountID, tx) {
+			continue
+		}
+		res = append(res, tx)
+		last = tx.Sequence
+		if len(res) >= f.Limit {
+			break
+		}
+	}
+	return res, last, nil
+}
+
+// touchesVisible reports whether tx moves funds of an account visible under
+// ctx. Callers must hold s.mu.
+func (s *InMemory) touchesVisible(ctx context.Context, tx Transaction) bool {
+	if _, scoped := OrganizationScope(ctx); !scoped {
+		return true
+	}
+	ids := []string{tx.FromAccountID, tx.ToAccountID}
+	for _, e := range tx.Entries {
+		ids = append(ids, e.AccountID)
+	}
+	for _, id := range ids {
+		if acc, ok := s.accts[id]; ok && Visible(ctx, acc.OrganizationID) {
+			return true
+		}
+	}
+	return false
+}
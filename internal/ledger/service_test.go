@@ -2,8 +2,13 @@ package ledger
 
 import (
 	"context"
+	"errors"
+	"os"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestTransferSuccessAndBalance(t *testing.T) {
@@ -77,3 +82,1410 @@ func TestConcurrentTransfers(t *testing.T) {
 		t.Fatalf("conservation violated: a+b=%d", ba.Amount+bb.Amount)
 	}
 }
+
+func TestPostEntriesMultiLeg(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+	a, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 1000})
+	b, _ := s.CreateAccount(ctx, Money{Currency: "USD", Amount: 500})
+	c, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+
+	entries := []Entry{
+		{AccountID: a.ID, Direction: Debit, Currency: "QZN", Amount: 300},
+		{AccountID: b.ID, Direction: Credit, Currency: "QZN", Amount: 100},
+		{AccountID: c.ID, Direction: Credit, Currency: "QZN", Amount: 200},
+		{AccountID: b.ID, Direction: Debit, Currency: "USD", Amount: 50},
+		{AccountID: a.ID, Direction: Credit, Currency: "USD", Amount: 50},
+	}
+	tx, err := s.PostEntries(ctx, entries, "batch-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Sequence != 1 || len(tx.Entries) != len(entries) {
+		t.Fatalf("unexpected transaction: %#v", tx)
+	}
+
+	want := map[string]map[string]int64{
+		a.ID: {"QZN": 700, "USD": 50},
+		b.ID: {"QZN": 100, "USD": 450},
+		c.ID: {"QZN": 200},
+	}
+	for id, bals := range want {
+		for cur, amt := range bals {
+			got, _ := s.GetBalance(ctx, id, cur)
+			if got.Amount != amt {
+				t.Fatalf("balance %s/%s: want %d got %d", id, cur, amt, got.Amount)
+			}
+		}
+	}
+
+	again, err := s.PostEntries(ctx, entries, "batch-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != tx.ID {
+		t.Fatalf("idempotency violated: %s != %s", again.ID, tx.ID)
+	}
+}
+
+func TestPostEntriesAllOrNothing(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+	a, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 100})
+	b, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 10})
+	c, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+
+	_, err := s.PostEntries(ctx, []Entry{
+		{AccountID: a.ID, Direction: Debit, Currency: "QZN", Amount: 50},
+		{AccountID: b.ID, Direction: Debit, Currency: "QZN", Amount: 20},
+		{AccountID: c.ID, Direction: Credit, Currency: "QZN", Amount: 70},
+	}, "")
+	if err != ErrInsufficientFunds {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+	ba, _ := s.GetBalance(ctx, a.ID, "QZN")
+	bc, _ := s.GetBalance(ctx, c.ID, "QZN")
+	if ba.Amount != 100 || bc.Amount != 0 {
+		t.Fatalf("partial batch applied: a=%d c=%d", ba.Amount, bc.Amount)
+	}
+	if items, _, _ := s.ListTransactions(ctx, 10, 0); len(items) != 0 {
+		t.Fatalf("expected no transactions, got %d", len(items))
+	}
+}
+
+func TestValidateEntries(t *testing.T) {
+	cases := []struct {
+		name    string
+		entries []Entry
+		want    error
+	}{
+		{"single leg", []Entry{{AccountID: "a", Direction: Debit, Currency: "QZN", Amount: 1}}, ErrUnbalanced},
+		{"unbalanced", []Entry{
+			{AccountID: "a", Direction: Debit, Currency: "QZN", Amount: 2},
+			{AccountID: "b", Direction: Credit, Currency: "QZN", Amount: 1},
+		}, ErrUnbalanced},
+		{"cross currency", []Entry{
+			{AccountID: "a", Direction: Debit, Currency: "QZN", Amount: 1},
+			{AccountID: "b", Direction: Credit, Currency: "USD", Amount: 1},
+		}, ErrUnbalanced},
+		{"zero amount", []Entry{
+			{AccountID: "a", Direction: Debit, Currency: "QZN", Amount: 0},
+			{AccountID: "b", Direction: Credit, Currency: "QZN", Amount: 0},
+		}, ErrInvalidAmount},
+		{"missing currency", []Entry{
+			{AccountID: "a", Direction: Debit, Amount: 1},
+			{AccountID: "b", Direction: Credit, Amount: 1},
+		}, ErrInvalidCurrency},
+		{"unknown direction", []Entry{
+			{AccountID: "a", Direction: "sideways", Currency: "QZN", Amount: 1},
+			{AccountID: "b", Direction: Credit, Currency: "QZN", Amount: 1},
+		}, ErrUnbalanced},
+		{"balanced", []Entry{
+			{AccountID: "a", Direction: Debit, Currency: "QZN", Amount: 1},
+			{AccountID: "b", Direction: Credit, Currency: "QZN", Amount: 1},
+		}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateEntries(tc.entries); err != tc.want {
+				t.Fatalf("want %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestOrganizationScope(t *testing.T) {
+	s := NewInMemory()
+	kz := WithOrganizationScope(context.Background(), "org-kz")
+	eu := WithOrganizationScope(context.Background(), "org-eu")
+
+	a, _ := s.CreateAccount(kz, Money{Currency: "QZN", Amount: 1000})
+	b, _ := s.CreateAccount(eu, Money{Currency: "QZN", Amount: 1000})
+	if a.OrganizationID != "org-kz" || b.OrganizationID != "org-eu" {
+		t.Fatalf("accounts not owned by the scoped org: %+v %+v", a, b)
+	}
+
+	if _, err := s.GetAccount(eu, a.ID); err != ErrNotFound {
+		t.Fatalf("cross-org read: expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.GetBalance(eu, a.ID, "QZN"); err != ErrNotFound {
+		t.Fatalf("cross-org balance: expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.Transfer(eu, a.ID, b.ID, Money{Currency: "QZN", Amount: 1}, ""); err != ErrNotFound {
+		t.Fatalf("debiting a foreign account: expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.PostEntries(eu, []Entry{
+		{AccountID: a.ID, Direction: Debit, Currency: "QZN", Amount: 1},
+		{AccountID: b.ID, Direction: Credit, Currency: "QZN", Amount: 1},
+	}, ""); err != ErrNotFound {
+		t.Fatalf("batch debiting a foreign account: expected ErrNotFound, got %v", err)
+	}
+
+	// Paying into another organization is allowed; both sides see the result.
+	if _, err := s.Transfer(kz, a.ID, b.ID, Money{Currency: "QZN", Amount: 100}, ""); err != nil {
+		t.Fatalf("cross-org payment: %v", err)
+	}
+	c, _ := s.CreateAccount(kz, Money{Currency: "QZN", Amount: 50})
+	d, _ := s.CreateAccount(kz, Money{Currency: "QZN", Amount: 0})
+	if _, err := s.Transfer(kz, c.ID, d.ID, Money{Currency: "QZN", Amount: 10}, ""); err != nil {
+		t.Fatalf("internal transfer: %v", err)
+	}
+
+	euTxs, _, _ := s.ListTransactions(eu, 10, 0)
+	kzTxs, _, _ := s.ListTransactions(kz, 10, 0)
+	allTxs, _, _ := s.ListTransactions(context.Background(), 10, 0)
+	if len(euTxs) != 1 || len(kzTxs) != 2 || len(allTxs) != 2 {
+		t.Fatalf("unexpected listings: eu=%d kz=%d all=%d", len(euTxs), len(kzTxs), len(allTxs))
+	}
+
+	if _, err := s.GetAccount(context.Background(), a.ID); err != nil {
+		t.Fatalf("unscoped read: %v", err)
+	}
+}
+
+func TestAccountAttributes(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+
+	acc, err := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0},
+		WithAccountType(AccountTypeFee), WithDisplayName(" Fee income "), WithExternalRef("KZ00-FEE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc.Type != AccountTypeFee || acc.DisplayName != "Fee income" || acc.ExternalRef != "KZ00-FEE" || acc.Status != AccountActive {
+		t.Fatalf("unexpected attributes: %+v", acc)
+	}
+	def, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+	if def.Type != AccountTypeSettlement || def.Status != AccountActive {
+		t.Fatalf("unexpected defaults: %+v", def)
+	}
+	if _, err := s.CreateAccount(ctx, Money{Currency: "QZN"}, WithAccountType("nostro")); err != ErrInvalidAccountType {
+		t.Fatalf("expected ErrInvalidAccountType, got %v", err)
+	}
+}
+
+func TestAccountLifecycle(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+	a, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 100})
+	b, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+
+	if _, err := s.SetAccountStatus(ctx, a.ID, AccountFrozen); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 10}, ""); err != ErrAccountFrozen {
+		t.Fatalf("debit from frozen account: expected ErrAccountFrozen, got %v", err)
+	}
+	if _, err := s.PostEntries(ctx, []Entry{
+		{AccountID: a.ID, Direction: Debit, Currency: "QZN", Amount: 10},
+		{AccountID: b.ID, Direction: Credit, Currency: "QZN", Amount: 10},
+	}, ""); err != ErrAccountFrozen {
+		t.Fatalf("batch debit from frozen account: expected ErrAccountFrozen, got %v", err)
+	}
+
+	if _, err := s.SetAccountStatus(ctx, a.ID, AccountActive); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 100}, ""); err != nil {
+		t.Fatalf("transfer after unfreeze: %v", err)
+	}
+
+	if _, err := s.SetAccountStatus(ctx, b.ID, AccountClosed); err != ErrAccountNotEmpty {
+		t.Fatalf("closing funded account: expected ErrAccountNotEmpty, got %v", err)
+	}
+	closed, err := s.SetAccountStatus(ctx, a.ID, AccountClosed)
+	if err != nil || closed.Status != AccountClosed {
+		t.Fatalf("close: %+v %v", closed, err)
+	}
+	if _, err := s.Transfer(ctx, b.ID, a.ID, Money{Currency: "QZN", Amount: 1}, ""); err != ErrAccountClosed {
+		t.Fatalf("credit to closed account: expected ErrAccountClosed, got %v", err)
+	}
+	if _, err := s.SetAccountStatus(ctx, a.ID, AccountActive); err != ErrAccountClosed {
+		t.Fatalf("reopen: expected ErrAccountClosed, got %v", err)
+	}
+}
+
+func TestListAccountTransactions(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+	a, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 1000})
+	b, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 1000})
+	c, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+
+	out, _ := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 10}, "")
+	in, _ := s.Transfer(ctx, b.ID, a.ID, Money{Currency: "QZN", Amount: 20}, "")
+	if _, err := s.Transfer(ctx, b.ID, c.ID, Money{Currency: "QZN", Amount: 30}, ""); err != nil {
+		t.Fatal(err)
+	}
+	batch, err := s.PostEntries(ctx, []Entry{
+		{AccountID: a.ID, Direction: Debit, Currency: "QZN", Amount: 5},
+		{AccountID: c.ID, Direction: Credit, Currency: "QZN", Amount: 5},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, next, err := s.ListAccountTransactions(ctx, a.ID, TransactionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].ID != out.ID || all[1].ID != in.ID || all[2].ID != batch.ID || next != batch.Sequence {
+		t.Fatalf("unexpected history: %+v next=%d", all, next)
+	}
+
+	debits, _, _ := s.ListAccountTransactions(ctx, a.ID, TransactionFilter{Direction: Debit})
+	if len(debits) != 2 || debits[0].ID != out.ID || debits[1].ID != batch.ID {
+		t.Fatalf("unexpected debits: %+v", debits)
+	}
+	credits, _, _ := s.ListAccountTransactions(ctx, a.ID, TransactionFilter{Direction: Credit})
+	if len(credits) != 1 || credits[0].ID != in.ID {
+		t.Fatalf("unexpected credits: %+v", credits)
+	}
+
+	page, cursor, _ := s.ListAccountTransactions(ctx, a.ID, TransactionFilter{Limit: 1})
+	if len(page) != 1 || page[0].ID != out.ID {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+	page, _, _ = s.ListAccountTransactions(ctx, a.ID, TransactionFilter{Limit: 1, AfterSeq: cursor})
+	if len(page) != 1 || page[0].ID != in.ID {
+		t.Fatalf("unexpected second page: %+v", page)
+	}
+
+	future, _, _ := s.ListAccountTransactions(ctx, a.ID, TransactionFilter{From: time.Now().Add(time.Hour)})
+	if len(future) != 0 {
+		t.Fatalf("expected no transactions after the time window, got %d", len(future))
+	}
+	if got, _, _ := s.ListAccountTransactions(ctx, a.ID, TransactionFilter{Currency: "USD"}); len(got) != 0 {
+		t.Fatalf("expected no USD transactions, got %d", len(got))
+	}
+	if _, _, err := s.ListAccountTransactions(WithOrganizationScope(ctx, "org-x"), a.ID, TransactionFilter{}); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound out of scope, got %v", err)
+	}
+}
+
+func TestGetBalanceAt(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+	a, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 1000})
+	b, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+
+	t1, _ := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 100}, "")
+	c, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 50})
+	t2, _ := s.PostEntries(ctx, []Entry{
+		{AccountID: a.ID, Direction: Debit, Currency: "QZN", Amount: 30},
+		{AccountID: c.ID, Direction: Credit, Currency: "QZN", Amount: 30},
+	}, "")
+
+	cases := []struct {
+		id   string
+		seq  uint64
+		want int64
+	}{
+		{a.ID, t1.Sequence, 900},
+		{a.ID, t2.Sequence, 870},
+		{b.ID, t1.Sequence, 100},
+		{c.ID, t1.Sequence, 50},
+		{c.ID, t2.Sequence, 80},
+	}
+	for _, tc := range cases {
+		bal, err := s.GetBalanceAt(ctx, tc.id, "QZN", BalancePoint{Sequence: tc.seq})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bal.Amount != tc.want || bal.Sequence != tc.seq {
+			t.Fatalf("account %s at %d: got %+v, want %d", tc.id, tc.seq, bal, tc.want)
+		}
+	}
+
+	now, err := s.GetBalanceAt(ctx, a.ID, "QZN", BalancePoint{Time: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, _ := s.GetBalance(ctx, a.ID, "QZN")
+	if now.Amount != current.Amount || now.Sequence != t2.Sequence {
+		t.Fatalf("balance as of now %+v differs from current %+v", now, current)
+	}
+	if before, _ := s.GetBalanceAt(ctx, a.ID, "QZN", BalancePoint{Time: a.CreatedAt.Add(-time.Second)}); before.Amount != 0 {
+		t.Fatalf("expected zero before the account existed, got %+v", before)
+	}
+	if usd, _ := s.GetBalanceAt(ctx, a.ID, "USD", BalancePoint{Sequence: t2.Sequence}); usd.Amount != 0 {
+		t.Fatalf("expected zero USD balance, got %+v", usd)
+	}
+
+	if _, err := s.GetBalanceAt(ctx, a.ID, "QZN", BalancePoint{}); err != ErrInvalidBalancePoint {
+		t.Fatalf("expected ErrInvalidBalancePoint, got %v", err)
+	}
+	if _, err := s.GetBalanceAt(ctx, a.ID, "QZN", BalancePoint{Sequence: 1, Time: time.Now()}); err != ErrInvalidBalancePoint {
+		t.Fatalf("expected ErrInvalidBalancePoint, got %v", err)
+	}
+	if _, err := s.GetBalanceAt(WithOrganizationScope(ctx, "org-x"), a.ID, "QZN", BalancePoint{Sequence: 1}); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound out of scope, got %v", err)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+	a, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 1000})
+	b, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+	orig, _ := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 300}, "")
+
+	if _, err := s.Reverse(ctx, orig.ID, 100, "", ""); err != ErrInvalidReversalReason {
+		t.Fatalf("expected ErrInvalidReversalReason, got %v", err)
+	}
+	if _, err := s.Reverse(ctx, orig.ID, 400, "wrong beneficiary", ""); err != ErrReversalExceedsOriginal {
+		t.Fatalf("expected ErrReversalExceedsOriginal, got %v", err)
+	}
+	partial, err := s.Reverse(ctx, orig.ID, 100, "wrong beneficiary", "rev-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partial.ReversalOf != orig.ID || partial.FromAccountID != b.ID || partial.ToAccountID != a.ID || partial.Amount != 100 {
+		t.Fatalf("unexpected reversal: %+v", partial)
+	}
+	if again, _ := s.Reverse(ctx, orig.ID, 100, "wrong beneficiary", "rev-1"); again.ID != partial.ID {
+		t.Fatalf("expected idempotent replay, got %+v", again)
+	}
+	if _, err := s.Reverse(ctx, partial.ID, 0, "undo", ""); err != ErrNotReversible {
+		t.Fatalf("expected ErrNotReversible, got %v", err)
+	}
+
+	hist, _, _ := s.ListAccountTransactions(ctx, a.ID, TransactionFilter{})
+	if hist[0].ReversalStatus != PartiallyReversed || hist[0].ReversedAmount != 100 {
+		t.Fatalf("expected partially reversed original, got %+v", hist[0])
+	}
+
+	rest, err := s.Reverse(ctx, orig.ID, 0, "wrong beneficiary", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rest.Amount != 200 {
+		t.Fatalf("expected the remaining 200 to be reversed, got %d", rest.Amount)
+	}
+	if _, err := s.Reverse(ctx, orig.ID, 0, "wrong beneficiary", ""); err != ErrAlreadyReversed {
+		t.Fatalf("expected ErrAlreadyReversed, got %v", err)
+	}
+	if bal, _ := s.GetBalance(ctx, a.ID, "QZN"); bal.Amount != 1000 {
+		t.Fatalf("expected payer to be made whole, got %d", bal.Amount)
+	}
+	hist, _, _ = s.ListAccountTransactions(ctx, a.ID, TransactionFilter{})
+	if hist[0].ReversalStatus != Reversed {
+		t.Fatalf("expected reversed original, got %+v", hist[0])
+	}
+}
+
+func TestReverseBatchAndScope(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+	orgA := WithOrganizationScope(ctx, "org-a")
+	orgB := WithOrganizationScope(ctx, "org-b")
+	a, _ := s.CreateAccount(orgA, Money{Currency: "QZN", Amount: 100})
+	b, _ := s.CreateAccount(orgB, Money{Currency: "QZN", Amount: 0})
+	c, _ := s.CreateAccount(orgB, Money{Currency: "QZN", Amount: 0})
+	batch, err := s.PostEntries(ctx, []Entry{
+		{AccountID: a.ID, Direction: Debit, Currency: "QZN", Amount: 50},
+		{AccountID: b.ID, Direction: Credit, Currency: "QZN", Amount: 30},
+		{AccountID: c.ID, Direction: Credit, Currency: "QZN", Amount: 20},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Reverse(ctx, batch.ID, 10, "duplicate", ""); err != ErrInvalidAmount {
+		t.Fatalf("partial batch reversal: expected ErrInvalidAmount, got %v", err)
+	}
+	// The reversal debits org-b's accounts, so org-a cannot initiate it.
+	if _, err := s.Reverse(orgA, batch.ID, 0, "duplicate", ""); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound out of scope, got %v", err)
+	}
+	rev, err := s.Reverse(orgB, batch.ID, 0, "duplicate", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rev.Entries) != 3 || rev.Entries[0].Direction != Credit || rev.Entries[1].Direction != Debit {
+		t.Fatalf("unexpected reversal legs: %+v", rev.Entries)
+	}
+	if bal, _ := s.GetBalance(ctx, a.ID, "QZN"); bal.Amount != 100 {
+		t.Fatalf("expected batch to be undone, got %d", bal.Amount)
+	}
+}
+
+func TestHolds(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+	a, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 1000})
+	b, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+
+	h, err := s.CreateHold(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 600}, 0, "hold-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Status != HoldPending || h.ExpiresAt.Sub(h.CreatedAt) != DefaultHoldTTL {
+		t.Fatalf("unexpected hold: %+v", h)
+	}
+	if again, _ := s.CreateHold(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 600}, 0, "hold-1"); again.ID != h.ID {
+		t.Fatalf("expected idempotent replay, got %+v", again)
+	}
+	bal, _ := s.GetBalance(ctx, a.ID, "QZN")
+	if bal.Amount != 1000 || bal.Held != 600 || bal.Available != 400 {
+		t.Fatalf("unexpected balance with hold: %+v", bal)
+	}
+	if _, err := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 500}, ""); err != ErrInsufficientFunds {
+		t.Fatalf("transfer over available: expected ErrInsufficientFunds, got %v", err)
+	}
+	if _, err := s.CreateHold(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 500}, 0, ""); err != ErrInsufficientFunds {
+		t.Fatalf("hold over available: expected ErrInsufficientFunds, got %v", err)
+	}
+
+	if _, _, err := s.CaptureHold(ctx, h.ID, 700); err != ErrCaptureExceedsHold {
+		t.Fatalf("expected ErrCaptureExceedsHold, got %v", err)
+	}
+	captured, tx, err := s.CaptureHold(ctx, h.ID, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if captured.Status != HoldCaptured || captured.CapturedAmount != 250 || captured.TransactionID != tx.ID || tx.Amount != 250 {
+		t.Fatalf("unexpected capture: %+v %+v", captured, tx)
+	}
+	if bal, _ := s.GetBalance(ctx, a.ID, "QZN"); bal.Amount != 750 || bal.Held != 0 || bal.Available != 750 {
+		t.Fatalf("expected the uncaptured remainder to be released, got %+v", bal)
+	}
+	if _, _, err := s.CaptureHold(ctx, h.ID, 0); err != ErrHoldNotPending {
+		t.Fatalf("second capture: expected ErrHoldNotPending, got %v", err)
+	}
+
+	v, _ := s.CreateHold(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 100}, time.Minute, "")
+	if v, err = s.VoidHold(ctx, v.ID); err != nil || v.Status != HoldVoided {
+		t.Fatalf("void: %+v %v", v, err)
+	}
+	if _, err := s.VoidHold(ctx, v.ID); err != ErrHoldNotPending {
+		t.Fatalf("second void: expected ErrHoldNotPending, got %v", err)
+	}
+
+	if _, err := s.CreateHold(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 100}, MaxHoldTTL+time.Second, ""); err != ErrInvalidHoldTTL {
+		t.Fatalf("expected ErrInvalidHoldTTL, got %v", err)
+	}
+	e, _ := s.CreateHold(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 700}, 20*time.Millisecond, "")
+	time.Sleep(30 * time.Millisecond)
+	if got, _ := s.GetHold(ctx, e.ID); got.Status != HoldExpired {
+		t.Fatalf("expected expired hold, got %+v", got)
+	}
+	if bal, _ := s.GetBalance(ctx, a.ID, "QZN"); bal.Available != 750 {
+		t.Fatalf("expected expired hold to stop reserving funds, got %+v", bal)
+	}
+	if _, _, err := s.CaptureHold(ctx, e.ID, 0); err != ErrHoldExpired {
+		t.Fatalf("expected ErrHoldExpired, got %v", err)
+	}
+
+	orgB := WithOrganizationScope(ctx, "org-b")
+	if _, err := s.CreateHold(orgB, a.ID, b.ID, Money{Currency: "QZN", Amount: 1}, 0, ""); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound out of scope, got %v", err)
+	}
+}
+
+func TestFXTransfer(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+	org := WithOrganizationScope(ctx, "org-a")
+	from, _ := s.CreateAccount(org, Money{Currency: "QZN", Amount: 10_000})
+	to, _ := s.CreateAccount(ctx, Money{Currency: "USD", Amount: 0})
+	qznLiq, _ := s.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+	usdLiq, _ := s.CreateAccount(ctx, Money{Currency: "USD", Amount: 1_000})
+
+	if _, err := s.FXTransfer(org, from.ID, to.ID, Money{Currency: "QZN", Amount: 1000}, "USD", ""); err != ErrNoFXRate {
+		t.Fatalf("expected ErrNoFXRate, got %v", err)
+	}
+	now := time.Now().UTC()
+	if _, err := s.AddFXRate(ctx, FXRate{Base: "QZN", Quote: "USD", Rate: "0"}); err != ErrInvalidFXRate {
+		t.Fatalf("expected ErrInvalidFXRate, got %v", err)
+	}
+	if _, err := s.AddFXRate(ctx, FXRate{Base: "qzn", Quote: "QZN", Rate: "1"}); err != ErrInvalidCurrency {
+		t.Fatalf("expected ErrInvalidCurrency, got %v", err)
+	}
+	old, _ := s.AddFXRate(ctx, FXRate{Base: "qzn", Quote: "usd", Rate: "0.5", ValidFrom: now.Add(-time.Hour)})
+	cur, err := s.AddFXRate(ctx, FXRate{Base: "QZN", Quote: "USD", Rate: "0.0021000", ValidFrom: now.Add(-time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur.Rate != "0.0021" || old.Base != "QZN" {
+		t.Fatalf("rates not normalized: %+v %+v", cur, old)
+	}
+	if _, err := s.AddFXRate(ctx, FXRate{Base: "QZN", Quote: "USD", Rate: "9", ValidFrom: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.FXTransfer(org, from.ID, to.ID, Money{Currency: "QZN", Amount: 1000}, "USD", ""); err != ErrNoFXLiquidity {
+		t.Fatalf("expected ErrNoFXLiquidity, got %v", err)
+	}
+	if err := s.SetFXLiquidityAccount(ctx, "QZN", "missing"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound for unknown liquidity account, got %v", err)
+	}
+	_ = s.SetFXLiquidityAccount(ctx, "qzn", qznLiq.ID)
+	_ = s.SetFXLiquidityAccount(ctx, "USD", usdLiq.ID)
+
+	if _, err := s.FXTransfer(org, from.ID, to.ID, Money{Currency: "QZN", Amount: 100}, "USD", ""); err != ErrInvalidAmount {
+		t.Fatalf("expected conversion rounding to zero to fail, got %v", err)
+	}
+	tx, err := s.FXTransfer(org, from.ID, to.ID, Money{Currency: "QZN", Amount: 9_999}, "USD", "fx-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.FXRateID != cur.ID || tx.FXRate != "0.0021" || len(tx.Entries) != 4 {
+		t.Fatalf("unexpected fx transaction: %+v", tx)
+	}
+	// 9999 * 0.0021 = 20.9979, rounded down.
+	if tx.Entries[3].Currency != "USD" || tx.Entries[3].Amount != 20 {
+		t.Fatalf("unexpected converted leg: %+v", tx.Entries[3])
+	}
+	if again, _ := s.FXTransfer(org, from.ID, to.ID, Money{Currency: "QZN", Amount: 9_999}, "USD", "fx-1"); again.ID != tx.ID {
+		t.Fatalf("expected idempotent replay, got %+v", again)
+	}
+	for _, c := range []struct {
+		id, currency string
+		want         int64
+	}{
+		{from.ID, "QZN", 1},
+		{qznLiq.ID, "QZN", 9_999},
+		{usdLiq.ID, "USD", 980},
+		{to.ID, "USD", 20},
+	} {
+		if bal, _ := s.GetBalance(ctx, c.id, c.currency); bal.Amount != c.want {
+			t.Fatalf("balance of %s %s: want %d, got %d", c.id, c.currency, c.want, bal.Amount)
+		}
+	}
+
+	if _, err := s.FXTransfer(WithOrganizationScope(ctx, "org-b"), from.ID, to.ID, Money{Currency: "QZN", Amount: 1}, "USD", ""); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound out of scope, got %v", err)
+	}
+	if _, err := s.FXTransfer(ctx, to.ID, from.ID, Money{Currency: "USD", Amount: 1}, "QZN", ""); err != ErrNoFXRate {
+		t.Fatalf("rates are directional: expected ErrNoFXRate, got %v", err)
+	}
+
+	expired, err := s.ExpireFXRate(ctx, cur.ID)
+	if err != nil || expired.ValidTo.IsZero() {
+		t.Fatalf("expire: %+v %v", expired, err)
+	}
+	rates, _ := s.ListFXRates(ctx, "QZN", "USD")
+	if len(rates) != 3 || rates[0].ID != old.ID {
+		t.Fatalf("unexpected rate list: %+v", rates)
+	}
+	if got, _ := CurrentFXRate(rates, "QZN", "USD", time.Now()); got.ID != old.ID {
+		t.Fatalf("expected the older rate to apply again, got %+v", got)
+	}
+}
+
+func TestCurrencyRegistry(t *testing.T) {
+	ctx := context.Background()
+	s := NewInMemory()
+
+	if _, err := s.CreateAccount(ctx, Money{Currency: "XYZ", Amount: 0}); !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("expected unknown currency to be rejected, got %v", err)
+	}
+	if c, err := s.GetCurrency(ctx, "jpy"); err != nil || c.Exponent != 0 || c.Kind != CurrencyISO4217 {
+		t.Fatalf("unexpected built-in JPY: %+v %v", c, err)
+	}
+	if _, err := s.GetCurrency(ctx, "XYZ"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	// A sovereign digital currency with per-posting limits.
+	c, err := s.PutCurrency(ctx, Currency{Code: "ekzt", Name: "Digital Tenge", Exponent: 2, Enabled: true, MinAmount: 100, MaxAmount: 10_000})
+	if err != nil || c.Code != "EKZT" || c.Kind != CurrencyDigital {
+		t.Fatalf("unexpected registered currency: %+v %v", c, err)
+	}
+	a, err := s.CreateAccount(ctx, Money{Currency: "EKZT", Amount: 50_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := s.CreateAccount(ctx, Money{Currency: "EKZT", Amount: 0})
+	for _, amt := range []int64{99, 10_001} {
+		if _, err := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "EKZT", Amount: amt}, ""); !errors.Is(err, ErrInvalidAmount) {
+			t.Fatalf("expected %d to break the limits, got %v", amt, err)
+		}
+	}
+	if _, err := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "EKZT", Amount: 10_000}, "t-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PostEntries(ctx, []Entry{
+		{AccountID: a.ID, Direction: Debit, Currency: "EKZT", Amount: 20_000},
+		{AccountID: b.ID, Direction: Credit, Currency: "EKZT", Amount: 20_000},
+	}, ""); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("expected batch legs to be checked, got %v", err)
+	}
+
+	// Disabling stops new accounts and postings; replays still answer.
+	if _, err := s.PutCurrency(ctx, Currency{Code: "EKZT", Exponent: 2, Enabled: false}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateAccount(ctx, Money{Currency: "EKZT", Amount: 0}); !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("expected disabled currency to be rejected, got %v", err)
+	}
+	if _, err := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "EKZT", Amount: 500}, ""); !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("expected disabled currency to be rejected, got %v", err)
+	}
+	if _, err := s.CreateHold(ctx, a.ID, b.ID, Money{Currency: "EKZT", Amount: 500}, 0, ""); !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("expected disabled currency to be rejected for holds, got %v", err)
+	}
+	if _, err := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "EKZT", Amount: 10_000}, "t-1"); err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+
+	for _, bad := range []Currency{
+		{Code: "1AB", Exponent: 2},
+		{Code: "AB", Exponent: 2},
+		{Code: "ABCDEFGHI", Exponent: 2},
+		{Code: "ABC", Exponent: MaxCurrencyExponent + 1},
+		{Code: "ABC", Exponent: 2, MinAmount: 10, MaxAmount: 5},
+	} {
+		if _, err := s.PutCurrency(ctx, bad); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+	list, _ := s.ListCurrencies(ctx)
+	if len(list) < 150 || !sort.SliceIsSorted(list, func(i, j int) bool { return list[i].Code < list[j].Code }) {
+		t.Fatalf("unexpected currency list of %d entries", len(list))
+	}
+}
+
+func TestIssuance(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	d, err := OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, _ := d.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0}, WithAccountType(AccountTypeReserve))
+	holder, _ := d.CreateAccount(ctx, Money{Currency: "QZN", Amount: 100})
+
+	if _, err := d.Mint(ctx, issuer.ID, Money{Currency: "QZN", Amount: 500}, ""); !errors.Is(err, ErrNotIssuer) {
+		t.Fatalf("expected ErrNotIssuer before designation, got %v", err)
+	}
+	if err := d.SetIssuerAccount(ctx, "qzn", issuer.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Mint(ctx, holder.ID, Money{Currency: "QZN", Amount: 500}, ""); !errors.Is(err, ErrNotIssuer) {
+		t.Fatalf("expected holder mint to be refused, got %v", err)
+	}
+	mint, err := d.Mint(ctx, issuer.ID, Money{Currency: "QZN", Amount: 500}, "m-1")
+	if err != nil || mint.Kind != KindMint || mint.FromAccountID != IssuanceAccountID("QZN") || mint.ToAccountID != issuer.ID {
+		t.Fatalf("unexpected mint: %+v %v", mint, err)
+	}
+	if again, _ := d.Mint(ctx, issuer.ID, Money{Currency: "QZN", Amount: 500}, "m-1"); again.ID != mint.ID {
+		t.Fatalf("expected idempotent replay, got %+v", again)
+	}
+	pool, err := d.GetAccount(ctx, IssuanceAccountID("QZN"))
+	if err != nil || pool.Type != AccountTypeIssuance || pool.Balances["QZN"] != -500 {
+		t.Fatalf("unexpected issuance account: %+v %v", pool, err)
+	}
+
+	// The issuance account is out of reach of ordinary postings.
+	if _, err := d.Transfer(ctx, holder.ID, pool.ID, Money{Currency: "QZN", Amount: 10}, ""); !errors.Is(err, ErrIssuanceAccount) {
+		t.Fatalf("expected transfer to be refused, got %v", err)
+	}
+	if _, err := d.PostEntries(ctx, []Entry{
+		{AccountID: pool.ID, Direction: Debit, Currency: "QZN", Amount: 10},
+		{AccountID: holder.ID, Direction: Credit, Currency: "QZN", Amount: 10},
+	}, ""); !errors.Is(err, ErrIssuanceAccount) {
+		t.Fatalf("expected posting to be refused, got %v", err)
+	}
+	if _, err := d.CreateHold(ctx, holder.ID, pool.ID, Money{Currency: "QZN", Amount: 10}, 0, ""); !errors.Is(err, ErrIssuanceAccount) {
+		t.Fatalf("expected hold to be refused, got %v", err)
+	}
+	if _, err := d.Reverse(ctx, mint.ID, 0, "mistake", ""); !errors.Is(err, ErrIssuanceAccount) {
+		t.Fatalf("expected mint reversal to be refused, got %v", err)
+	}
+	if err := d.SetIssuerAccount(ctx, "QZN", pool.ID); !errors.Is(err, ErrIssuanceAccount) {
+		t.Fatalf("expected issuance account to be refused as issuer, got %v", err)
+	}
+
+	if _, err := d.Transfer(ctx, issuer.ID, holder.ID, Money{Currency: "QZN", Amount: 200}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Burn(ctx, issuer.ID, Money{Currency: "QZN", Amount: 400}, ""); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected burn beyond the issuer balance to fail, got %v", err)
+	}
+	burn, err := d.Burn(ctx, issuer.ID, Money{Currency: "QZN", Amount: 300}, "")
+	if err != nil || burn.Kind != KindBurn {
+		t.Fatalf("unexpected burn: %+v %v", burn, err)
+	}
+
+	want := []Supply{{Currency: "QZN", Outstanding: 300, Issued: 200, Minted: 500, Burned: 300, Opening: 100}}
+	check := func(l Issuance) {
+		t.Helper()
+		got, err := l.Supply(ctx)
+		if err != nil || len(got) != 1 || got[0] != want[0] {
+			t.Fatalf("unexpected supply: %+v %v", got, err)
+		}
+	}
+	check(d)
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	check(r)
+	if issuers, _ := r.IssuerAccounts(ctx); issuers["QZN"] != issuer.ID {
+		t.Fatalf("issuer designation lost: %v", issuers)
+	}
+}
+
+func TestFeeCompute(t *testing.T) {
+	s := FeeSchedule{
+		Version: 1,
+		Rates:   map[string]string{"institution": "0.0005", "corporate": "0.0015"},
+		Alpha:   "1",
+		Beta:    "1",
+	}
+	for _, tc := range []struct {
+		participant string
+		amount      int64
+		alpha, beta string
+		rounding    Rounding
+		want        int64
+	}{
+		{"corporate", 100_000, "1", "1", RoundHalfUp, 150},
+		{"corporate", 100_000, "1.2", "1.3", RoundHalfUp, 234},
+		{"institution", 1_000, "1", "1", RoundHalfUp, 1},   // 0.5
+		{"institution", 1_000, "1", "1", RoundHalfEven, 0}, // 0.5
+		{"institution", 3_000, "1", "1", RoundHalfEven, 2}, // 1.5
+		{"institution", 1_000, "1", "1", RoundDown, 0},     // 0.5
+		{"institution", 100, "1", "1", RoundUp, 1},         // 0.05
+		{"institution", 9_999, "0.8", "0.9", RoundDown, 3}, // 3.59964
+		{"sovereign", 1_000_000, "1.2", "1.3", RoundUp, 0}, // no rate
+		{"", 1_000_000, "1", "1", RoundUp, 0},
+	} {
+		s.Alpha, s.Beta, s.Rounding = tc.alpha, tc.beta, tc.rounding
+		fee, err := s.Compute(tc.participant, Money{Currency: "QZN", Amount: tc.amount})
+		if err != nil || fee.Amount != tc.want {
+			t.Errorf("%s %d α=%s β=%s %s: got %d %v, want %d", tc.participant, tc.amount, tc.alpha, tc.beta, tc.rounding, fee.Amount, err, tc.want)
+		}
+	}
+
+	now := time.Now()
+	for _, bad := range []FeeSchedule{
+		{Alpha: "0.7", Beta: "1", AccountID: "fee"},
+		{Alpha: "1", Beta: "1.4", AccountID: "fee"},
+		{Alpha: "1", Beta: "1", AccountID: "fee", Rates: map[string]string{"corporate": "-0.001"}},
+		{Alpha: "1", Beta: "1", AccountID: "fee", Rates: map[string]string{"": "0.001"}},
+		{Alpha: "1", Beta: "1", AccountID: "fee", Rounding: "bankers"},
+		{Alpha: "1", Beta: "1"},
+	} {
+		if _, err := NormalizeFeeSchedule(bad, now); !errors.Is(err, ErrInvalidFeeSchedule) {
+			t.Errorf("expected %+v to be rejected, got %v", bad, err)
+		}
+	}
+	got, err := NormalizeFeeSchedule(FeeSchedule{Alpha: "1.10", Beta: "0.90", AccountID: " fee ", Rates: map[string]string{" Corporate ": "0.00100"}}, now)
+	if err != nil || got.Alpha != "1.1" || got.Beta != "0.9" || got.Rates["corporate"] != "0.001" || got.Rounding != RoundHalfUp || got.AccountID != "fee" {
+		t.Fatalf("unexpected normalized schedule: %+v %v", got, err)
+	}
+}
+
+func TestFeeScheduleRateBounds(t *testing.T) {
+	for _, tc := range []struct {
+		participant, rate string
+		ok                bool
+	}{
+		{"sovereign", "0", true},
+		{"sovereign", "0.000001", false},
+		{"institution", "0.0001", true},
+		{"institution", "0.00009", false},
+		{"institution", "0.0005", true},
+		{"institution", "0.00051", false},
+		{"corporate", "0.001", true},
+		{"corporate", "0.00099", false},
+		{"corporate", "0.002", true},
+		{"corporate", "0.0021", false},
+		{"retail", "0", true},
+		{"retail", "0.0001", false},
+		{"Corporate", "0.0015", true},
+		{"central_bank", "0", false},
+		{"", "0", false},
+	} {
+		s := FeeSchedule{Alpha: "1", Beta: "1", AccountID: "fee", Rates: map[string]string{tc.participant: tc.rate}}
+		_, err := NormalizeFeeSchedule(s, time.Now())
+		if tc.ok && err != nil {
+			t.Errorf("%s %s: unexpected error %v", tc.participant, tc.rate, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrInvalidFeeSchedule) {
+			t.Errorf("%s %s: expected ErrInvalidFeeSchedule, got %v", tc.participant, tc.rate, err)
+		}
+	}
+}
+
+func TestTransferWithFee(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	d, err := OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payer, _ := d.CreateAccount(WithOrganizationScope(ctx, "org-a"), Money{Currency: "QZN", Amount: 1_000_000})
+	payee, _ := d.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+	feeAcct, _ := d.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0}, WithAccountType(AccountTypeFee))
+	scoped := WithOrganizationScope(ctx, "org-a")
+
+	// Without a schedule transfers are free.
+	tx, err := d.TransferWithFee(scoped, payer.ID, payee.ID, Money{Currency: "QZN", Amount: 1000}, "corporate", "")
+	if err != nil || tx.Fee != nil || tx.FromAccountID != payer.ID || tx.Amount != 1000 {
+		t.Fatalf("unexpected free transfer: %+v %v", tx, err)
+	}
+
+	if _, err := d.AddFeeSchedule(ctx, FeeSchedule{Alpha: "1", Beta: "1", AccountID: payee.ID}); !errors.Is(err, ErrInvalidAccountType) {
+		t.Fatalf("expected a non-fee account to be refused, got %v", err)
+	}
+	v1, err := d.AddFeeSchedule(ctx, FeeSchedule{
+		Rates:     map[string]string{"corporate": "0.001"},
+		Alpha:     "1.2",
+		Beta:      "1",
+		AccountID: feeAcct.ID,
+	})
+	if err != nil || v1.Version != 1 {
+		t.Fatalf("unexpected schedule: %+v %v", v1, err)
+	}
+	// A later version that is not in effect yet does not apply.
+	if v2, err := d.AddFeeSchedule(ctx, FeeSchedule{
+		Rates:         map[string]string{"corporate": "0.002"},
+		Alpha:         "1",
+		Beta:          "1",
+		AccountID:     feeAcct.ID,
+		EffectiveFrom: time.Now().Add(time.Hour),
+	}); err != nil || v2.Version != 2 {
+		t.Fatalf("unexpected schedule: %+v %v", v2, err)
+	}
+
+	tx, err = d.TransferWithFee(scoped, payer.ID, payee.ID, Money{Currency: "QZN", Amount: 10_000}, "corporate", "f-1")
+	if err != nil || tx.Fee == nil || tx.Fee.Amount != 12 || tx.Fee.ScheduleVersion != 1 || tx.Fee.Rate != "0.001" || len(tx.Entries) != 4 {
+		t.Fatalf("unexpected fee transfer: %+v %v", tx, err)
+	}
+	if again, _ := d.TransferWithFee(scoped, payer.ID, payee.ID, Money{Currency: "QZN", Amount: 10_000}, "corporate", "f-1"); again.ID != tx.ID {
+		t.Fatalf("expected idempotent replay, got %+v", again)
+	}
+	if tx, err := d.TransferWithFee(scoped, payer.ID, payee.ID, Money{Currency: "QZN", Amount: 10_000}, "sovereign", ""); err != nil || tx.Fee == nil || tx.Fee.Amount != 0 || len(tx.Entries) != 0 {
+		t.Fatalf("expected an exempt transfer with a zero fee, got %+v %v", tx, err)
+	}
+	// The payer must cover the amount and the fee.
+	if _, err := d.TransferWithFee(scoped, payer.ID, payee.ID, Money{Currency: "QZN", Amount: 978_980}, "corporate", ""); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected the fee to count against the balance, got %v", err)
+	}
+	if _, err := d.TransferWithFee(ctx, payee.ID, payer.ID, Money{Currency: "QZN", Amount: 10}, "corporate", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.TransferWithFee(WithOrganizationScope(ctx, "org-b"), payer.ID, payee.ID, Money{Currency: "QZN", Amount: 10}, "corporate", ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the payer to be out of scope, got %v", err)
+	}
+
+	check := func(l *Durable) {
+		t.Helper()
+		want := map[string]int64{payer.ID: 1_000_000 - 21_000 - 12 + 10, payee.ID: 21_000 - 10, feeAcct.ID: 12}
+		for id, amount := range want {
+			if b, err := l.GetBalance(ctx, id, "QZN"); err != nil || b.Amount != amount {
+				t.Fatalf("balance of %s: got %+v %v, want %d", id, b, err, amount)
+			}
+		}
+		schedules, err := l.FeeSchedules(ctx)
+		if err != nil || len(schedules) != 2 || schedules[0].Rates["corporate"] != "0.001" {
+			t.Fatalf("unexpected schedules: %+v %v", schedules, err)
+		}
+	}
+	check(d)
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	check(r)
+}
+
+func TestCaptureHoldWithFee(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	d, err := OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payer, _ := d.CreateAccount(ctx, Money{Currency: "QZN", Amount: 100_000})
+	payee, _ := d.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+	feeAcct, _ := d.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0}, WithAccountType(AccountTypeFee))
+	if _, err := d.AddFeeSchedule(ctx, FeeSchedule{Rates: map[string]string{"corporate": "0.001"}, Alpha: "1", Beta: "1", AccountID: feeAcct.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The fee is charged on the captured amount, from the available balance.
+	h, _ := d.CreateHold(ctx, payer.ID, payee.ID, Money{Currency: "QZN", Amount: 50_000}, time.Hour, "")
+	h, tx, err := d.CaptureHoldWithFee(ctx, h.ID, 40_000, "corporate")
+	if err != nil || h.Status != HoldCaptured || h.TransactionID != tx.ID || tx.Fee == nil || tx.Fee.Amount != 40 || len(tx.Entries) != 4 {
+		t.Fatalf("unexpected capture: %+v %+v %v", h, tx, err)
+	}
+	// A payer that cannot cover the fee keeps its hold.
+	h, _ = d.CreateHold(ctx, payer.ID, payee.ID, Money{Currency: "QZN", Amount: 59_960}, time.Hour, "")
+	if _, _, err := d.CaptureHoldWithFee(ctx, h.ID, 0, "corporate"); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected the fee to count against the balance, got %v", err)
+	}
+	if got, _ := d.GetHold(ctx, h.ID); got.Status != HoldPending {
+		t.Fatalf("expected the hold to stay pending, got %+v", got)
+	}
+	// Plain captures stay free.
+	if _, tx, err := d.CaptureHold(ctx, h.ID, 0); err != nil || tx.Fee != nil {
+		t.Fatalf("unexpected plain capture: %+v %v", tx, err)
+	}
+
+	check := func(l *Durable) {
+		t.Helper()
+		want := map[string]int64{payer.ID: 0, payee.ID: 99_960, feeAcct.ID: 40}
+		for id, amount := range want {
+			if b, err := l.GetBalance(ctx, id, "QZN"); err != nil || b.Amount != amount || b.Held != 0 {
+				t.Fatalf("balance of %s: got %+v %v, want %d", id, b, err, amount)
+			}
+		}
+	}
+	check(d)
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	check(r)
+}
+
+func TestUtilizationCheck(t *testing.T) {
+	at := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	l := Limit{Scope: LimitAccount, Subject: "acc-1", Currency: "QZN", MaxAmount: 500, DailyAmount: 1000, DailyCount: 3, WindowAmount: 600, WindowSeconds: 3600}
+	u := NewUtilization(l, at)
+	u.Add(at.Add(-13*time.Hour), 400) // yesterday, inside neither
+	u.Add(at.Add(-2*time.Hour), 300)  // today, outside the window
+	u.Add(at.Add(-time.Minute), 200)  // today, inside the window
+	if u.DailyAmount != 500 || u.DailyCount != 2 || u.WindowAmount != 200 || u.WindowCount != 1 {
+		t.Fatalf("unexpected utilization: %+v", u)
+	}
+	if err := u.Check(400); err != nil {
+		t.Fatalf("expected 400 to fit, got %v", err)
+	}
+	for _, tc := range []struct {
+		amount int64
+		limit  func(*Limit)
+		cap    string
+	}{
+		{501, nil, "max_amount"},
+		{450, nil, "window_amount"},
+		{100, func(l *Limit) { l.DailyAmount = 550 }, "daily_amount"},
+		{100, func(l *Limit) { l.DailyCount = 2 }, "daily_count"},
+		{100, func(l *Limit) { l.WindowCount = 1 }, "window_count"},
+	} {
+		v := u
+		if tc.limit != nil {
+			tc.limit(&v.Limit)
+		}
+		if err := v.Check(tc.amount); !errors.Is(err, ErrLimitExceeded) || !strings.Contains(err.Error(), tc.cap) {
+			t.Fatalf("expected %s to be exceeded by %d, got %v", tc.cap, tc.amount, err)
+		}
+	}
+
+	for _, bad := range []Limit{
+		{Scope: "team", Subject: "x", Currency: "QZN"},
+		{Scope: LimitAccount, Currency: "QZN"},
+		{Scope: LimitAccount, Subject: "x", Currency: "QZN", MaxAmount: -1},
+		{Scope: LimitAccount, Subject: "x", Currency: "QZN", WindowCount: 5},
+		{Scope: LimitAccount, Subject: "x", Currency: "QZN", WindowCount: 5, WindowSeconds: 32 * 86400},
+	} {
+		if _, err := NormalizeLimit(bad); !errors.Is(err, ErrInvalidLimit) {
+			t.Fatalf("expected %+v to be refused, got %v", bad, err)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	d, err := OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgA := WithOrganizationScope(ctx, "org-a")
+	a1, _ := d.CreateAccount(orgA, Money{Currency: "QZN", Amount: 1_000_000})
+	a2, _ := d.CreateAccount(orgA, Money{Currency: "QZN", Amount: 1_000_000})
+	payee, _ := d.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+
+	if _, err := d.SetLimit(ctx, Limit{Scope: LimitAccount, Subject: "missing", Currency: "QZN", MaxAmount: 1}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected an unknown account to be refused, got %v", err)
+	}
+	if _, err := d.SetLimit(ctx, Limit{Scope: LimitAccount, Subject: a1.ID, Currency: "NOPE", MaxAmount: 1}); !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("expected an unknown currency to be refused, got %v", err)
+	}
+	if _, err := d.SetLimit(ctx, Limit{Scope: LimitAccount, Subject: a1.ID, Currency: "qzn", MaxAmount: 5000, DailyCount: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.SetLimit(ctx, Limit{Scope: LimitOrganization, Subject: "org-a", Currency: "QZN", DailyAmount: 12_000}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.Transfer(ctx, a1.ID, payee.ID, Money{Currency: "QZN", Amount: 5001}, ""); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected the single transfer cap to apply, got %v", err)
+	}
+	first, err := d.Transfer(ctx, a1.ID, payee.ID, Money{Currency: "QZN", Amount: 5000}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Batch legs debiting a1 count toward its limits.
+	if _, err := d.PostEntries(ctx, []Entry{
+		{AccountID: a1.ID, Direction: Debit, Currency: "QZN", Amount: 1000},
+		{AccountID: payee.ID, Direction: Credit, Currency: "QZN", Amount: 1000},
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+	// a2 has no limit of its own, but shares the organization's volume.
+	if _, err := d.Transfer(ctx, a2.ID, payee.ID, Money{Currency: "QZN", Amount: 6001}, ""); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected the organization volume to apply, got %v", err)
+	}
+	h, err := d.CreateHold(ctx, a2.ID, payee.ID, Money{Currency: "QZN", Amount: 8000}, time.Minute, "")
+	if err != nil {
+		t.Fatalf("expected holds to be placed regardless of limits, got %v", err)
+	}
+	if _, _, err := d.CaptureHold(ctx, h.ID, 0); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected the capture to be limited, got %v", err)
+	}
+	if _, _, err := d.CaptureHold(ctx, h.ID, 6000); err != nil {
+		t.Fatalf("expected a partial capture within the limit, got %v", err)
+	}
+	// Reversals are neither limited nor counted.
+	if _, err := d.SetLimit(ctx, Limit{Scope: LimitAccount, Subject: payee.ID, Currency: "QZN", MaxAmount: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Reverse(ctx, first.ID, first.Amount, "sent in error", ""); err != nil {
+		t.Fatalf("expected the reversal to bypass the payee's limit, got %v", err)
+	}
+	if _, err := d.Transfer(ctx, a1.ID, payee.ID, Money{Currency: "QZN", Amount: 1}, ""); !errors.Is(err, ErrLimitExceeded) || !strings.Contains(err.Error(), "daily_count") {
+		t.Fatalf("expected the daily count to apply, got %v", err)
+	}
+
+	u, err := d.LimitUtilization(ctx, LimitKey{Scope: LimitOrganization, Subject: "org-a", Currency: "QZN"})
+	if err != nil || u.DailyAmount != 12_000 || u.DailyCount != 3 || u.WindowCount != 0 {
+		t.Fatalf("unexpected utilization: %+v %v", u, err)
+	}
+
+	if err := d.DeleteLimit(ctx, LimitKey{Scope: LimitAccount, Subject: a1.ID, Currency: "QZN"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	d, err = OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	limits, err := d.ListLimits(ctx, LimitKey{})
+	if err != nil || len(limits) != 2 || limits[0].Subject != payee.ID || limits[1].Scope != LimitOrganization || limits[1].DailyAmount != 12_000 {
+		t.Fatalf("expected the remaining limits to survive recovery, got %+v %v", limits, err)
+	}
+	if _, err := d.LimitUtilization(ctx, LimitKey{Scope: LimitAccount, Subject: a1.ID, Currency: "QZN"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the deleted limit to stay deleted, got %v", err)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	for _, tc := range []struct {
+		amount int64
+		exp    int
+		want   string
+	}{
+		{12345, 2, "123.45"},
+		{5, 2, "0.05"},
+		{-5, 2, "-0.05"},
+		{0, 2, "0.00"},
+		{1000, 0, "1000"},
+		{1, 3, "0.001"},
+		{-9223372036854775808, 2, "-92233720368547758.08"},
+	} {
+		if got := FormatAmount(tc.amount, tc.exp); got != tc.want {
+			t.Errorf("FormatAmount(%d, %d) = %q, want %q", tc.amount, tc.exp, got, tc.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		exp  int
+		want int64
+		ok   bool
+	}{
+		{"123.45", 2, 12345, true},
+		{"0.05", 2, 5, true},
+		{"7", 2, 700, true},
+		{"1.5", 3, 1500, true},
+		{"100.500", 2, 10050, true},
+		{"0", 0, 0, true},
+		{"92233720368547758.07", 2, 9223372036854775807, true},
+		{"92233720368547758.08", 2, 0, false},
+		{"1.005", 2, 0, false},
+		{"-1.00", 2, 0, false},
+		{"1.", 2, 0, false},
+		{".5", 2, 0, false},
+		{"1e3", 0, 0, false},
+	} {
+		got, err := ParseAmount(tc.in, tc.exp)
+		if tc.ok && (err != nil || got != tc.want) {
+			t.Errorf("ParseAmount(%q, %d) = %d, %v, want %d", tc.in, tc.exp, got, err, tc.want)
+		}
+		if !tc.ok && !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("ParseAmount(%q, %d) = %d, %v, want ErrInvalidAmount", tc.in, tc.exp, got, err)
+		}
+	}
+}
+
+func TestDurableRecovery(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	d, err := OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := d.CreateAccount(WithOrganizationScope(ctx, "org-a"), Money{Currency: "QZN", Amount: 1000}, WithAccountType(AccountTypeSettlement))
+	b, _ := d.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+	usd, _ := d.CreateAccount(ctx, Money{Currency: "USD", Amount: 500})
+	qznLiq, _ := d.CreateAccount(ctx, Money{Currency: "QZN", Amount: 0})
+	t1, _ := d.Transfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 300}, "t-1")
+	if _, err := d.Reverse(ctx, t1.ID, 100, "duplicate", ""); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := d.CreateHold(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 200}, time.Hour, "h-1")
+	if _, _, err := d.CaptureHold(ctx, h.ID, 50); err != nil {
+		t.Fatal(err)
+	}
+	pending, _ := d.CreateHold(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 100}, time.Hour, "")
+	if _, err := d.SetAccountStatus(ctx, b.ID, AccountFrozen); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.AddFXRate(ctx, FXRate{Base: "QZN", Quote: "USD", Rate: "0.5"}); err != nil {
+		t.Fatal(err)
+	}
+	_ = d.SetFXLiquidityAccount(ctx, "QZN", qznLiq.ID)
+	_ = d.SetFXLiquidityAccount(ctx, "USD", usd.ID)
+	fx, err := d.FXTransfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 100}, "USD", "fx-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.PutCurrency(ctx, Currency{Code: "EKZT", Exponent: 2, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen without Close, as after a crash: everything comes from the log.
+	check := func(r *Durable) {
+		t.Helper()
+		if bal, _ := r.GetBalance(ctx, a.ID, "QZN"); bal.Amount != 650 || bal.Held != 100 {
+			t.Fatalf("unexpected recovered balance of a: %+v", bal)
+		}
+		if acc, _ := r.GetAccount(ctx, b.ID); acc.Status != AccountFrozen || acc.Balances["QZN"] != 250 || acc.Balances["USD"] != 50 {
+			t.Fatalf("unexpected recovered account b: %+v", acc)
+		}
+		if acc, _ := r.GetAccount(WithOrganizationScope(ctx, "org-a"), a.ID); acc.Type != AccountTypeSettlement {
+			t.Fatalf("unexpected recovered account a: %+v", acc)
+		}
+		txs, _, _ := r.ListTransactions(ctx, 100, 0)
+		if len(txs) != 4 || txs[0].ReversalStatus != PartiallyReversed || txs[3].FXRate != "0.5" {
+			t.Fatalf("unexpected recovered journal: %+v", txs)
+		}
+		if again, _ := r.Transfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 300}, "t-1"); again.ID != t1.ID {
+			t.Fatalf("idempotency key lost: %+v", again)
+		}
+		if again, _ := r.FXTransfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 100}, "USD", "fx-1"); again.ID != fx.ID {
+			t.Fatalf("fx idempotency key lost: %+v", again)
+		}
+		if got, err := r.GetHold(ctx, pending.ID); err != nil || got.Status != HoldPending {
+			t.Fatalf("pending hold lost: %+v %v", got, err)
+		}
+		if hb, _ := r.GetBalanceAt(ctx, a.ID, "QZN", BalancePoint{Sequence: 1}); hb.Amount != 700 {
+			t.Fatalf("unexpected recovered history: %+v", hb)
+		}
+		if c, err := r.GetCurrency(ctx, "EKZT"); err != nil || c.Kind != CurrencyDigital || !c.Enabled {
+			t.Fatalf("registered currency lost: %+v %v", c, err)
+		}
+	}
+	r, err := OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(r)
+
+	// Snapshot, then commit more: recovery combines both.
+	if err := r.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.VoidHold(ctx, pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	after, err := r.Transfer(ctx, a.ID, usd.ID, Money{Currency: "QZN", Amount: 10}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gens, _ := walGenerations(dir)
+	if len(gens) != 1 {
+		t.Fatalf("expected the snapshot to drop older log files, got generations %v", gens)
+	}
+	// A crash in the middle of an append leaves a torn frame behind.
+	f, _ := os.OpenFile(walPath(dir, gens[0]), os.O_WRONLY|os.O_APPEND, 0)
+	_, _ = f.Write([]byte{0x40, 0, 0, 0, 1, 2})
+	_ = f.Close()
+
+	r2, err := OpenDurable(dir, WithSyncDelay(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r2.GetCurrency(ctx, "EKZT"); err != nil {
+		t.Fatalf("registered currency lost in snapshot: %v", err)
+	}
+	if got, _ := r2.GetHold(ctx, pending.ID); got.Status != HoldVoided {
+		t.Fatalf("expected voided hold, got %+v", got)
+	}
+	if bal, _ := r2.GetBalance(ctx, a.ID, "QZN"); bal.Amount != 640 || bal.Held != 0 {
+		t.Fatalf("unexpected balance after snapshot and replay: %+v", bal)
+	}
+	next, err := r2.Transfer(ctx, a.ID, usd.ID, Money{Currency: "QZN", Amount: 10}, "")
+	if err != nil || next.Sequence != after.Sequence+1 {
+		t.Fatalf("expected sequence to continue after %d, got %+v %v", after.Sequence, next, err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r2.Transfer(ctx, a.ID, usd.ID, Money{Currency: "QZN", Amount: 1}, ""); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := r2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r2.Transfer(ctx, a.ID, usd.ID, Money{Currency: "QZN", Amount: 1}, ""); err != ErrClosed {
+		t.Fatalf("expected ErrClosed after Close, got %v", err)
+	}
+
+	r3, err := OpenDurable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r3.Close()
+	if bal, _ := r3.GetBalance(ctx, usd.ID, "QZN"); bal.Amount != 40 {
+		t.Fatalf("expected 40 QZN after clean restart, got %+v", bal)
+	}
+}
+
+func TestWatchTransactions(t *testing.T) {
+	s := NewInMemory()
+	ctx := context.Background()
+	orgA := WithOrganizationScope(ctx, "org-a")
+	orgB := WithOrganizationScope(ctx, "org-b")
+	a, _ := s.CreateAccount(orgA, Money{Currency: "QZN", Amount: 1000})
+	b, _ := s.CreateAccount(orgA, Money{Currency: "QZN", Amount: 1000})
+	other, _ := s.CreateAccount(orgB, Money{Currency: "QZN", Amount: 1000})
+	otherB, _ := s.CreateAccount(orgB, Money{Currency: "QZN", Amount: 0})
+
+	// Seq 1 is history; the rest commits concurrently with the watch.
+	if _, err := s.Transfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 1}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	wctx, cancel := context.WithCancel(orgA)
+	got := make(chan Transaction, 128)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WatchTransactions(wctx, 0, func(tx Transaction) error {
+			got <- tx
+			return nil
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = s.Transfer(ctx, a.ID, b.ID, Money{Currency: "QZN", Amount: 1}, "")
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = s.Transfer(orgB, other.ID, otherB.ID, Money{Currency: "QZN", Amount: 1}, "")
+		}()
+	}
+	wg.Wait()
+
+	var last uint64
+	for n := 0; n < 21; n++ {
+		select {
+		case tx := <-got:
+			if tx.Sequence <= last {
+				t.Fatalf("sequence %d delivered after %d", tx.Sequence, last)
+			}
+			if tx.FromAccountID != a.ID {
+				t.Fatalf("transaction of another organization delivered: %+v", tx)
+			}
+			last = tx.Sequence
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d of 21 transactions delivered", n)
+		}
+	}
+	select {
+	case tx := <-got:
+		t.Fatalf("unexpected extra transaction %+v", tx)
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// Resuming after the last delivered sequence only yields newer ones.
+	tx, _ := s.Transfer(ctx, b.ID, a.ID, Money{Currency: "QZN", Amount: 5}, "")
+	stop := errors.New("stop")
+	err := s.WatchTransactions(ctx, last, func(got Transaction) error {
+		if got.Sequence <= last {
+			t.Fatalf("replayed sequence %d", got.Sequence)
+		}
+		if got.ID == tx.ID {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("expected callback error, got %v", err)
+	}
+}
+
+func TestWatchFilter(t *testing.T) {
+	single := Transaction{Sequence: 1, FromAccountID: "a", ToAccountID: "b", Currency: "QZN", Amount: 1}
+	batch := Transaction{Sequence: 2, Entries: []Entry{
+		{AccountID: "a", Direction: Debit, Currency: "USD", Amount: 1},
+		{AccountID: "c", Direction: Credit, Currency: "USD", Amount: 1},
+	}}
+	cases := []struct {
+		f     WatchFilter
+		tx    Transaction
+		match bool
+	}{
+		{WatchFilter{}, single, true},
+		{WatchFilter{Currency: "QZN"}, single, true},
+		{WatchFilter{Currency: "USD"}, single, false},
+		{WatchFilter{Currency: "USD"}, batch, true},
+		{WatchFilter{AccountID: "b"}, single, true},
+		{WatchFilter{AccountID: "b", Direction: Debit}, single, false},
+		{WatchFilter{AccountID: "a", Direction: Debit, Currency: "USD"}, batch, true},
+		{WatchFilter{AccountID: "b"}, batch, false},
+	}
+	for i, c := range cases {
+		if got := c.f.Match(c.tx); got != c.match {
+			t.Errorf("case %d: Match = %v, want %v", i, got, c.match)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package ledger
 
 import (
 	"errors"
+	"math"
 	"time"
 
 	"qazna.org/internal/ids"
@@ -19,21 +20,119 @@ func (m Money) IsZero() bool     { return m.Amount == 0 }
 // Account is a simple account with per-currency balances.
 // For MVP we typically use a single currency (e.g., "QZN").
 type Account struct {
-	ID        string           `json:"id"`
-	CreatedAt time.Time        `json:"created_at"`
-	Balances  map[string]int64 `json:"balances"` // currency -> minor units
+	ID             string           `json:"id"`
+	OrganizationID string           `json:"organization_id,omitempty"` // owning auth.Organization
+	Type           AccountType      `json:"type"`
+	DisplayName    string           `json:"display_name,omitempty"`
+	ExternalRef    string           `json:"external_ref,omitempty"`
+	Status         AccountStatus    `json:"status"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Balances       map[string]int64 `json:"balances"` // currency -> minor units
+}
+
+// Direction marks a posting leg as a debit or a credit.
+type Direction string
+
+const (
+	Debit  Direction = "debit"
+	Credit Direction = "credit"
+)
+
+// Entry is one leg of a batch posting.
+type Entry struct {
+	AccountID string    `json:"account_id"`
+	Direction Direction `json:"direction"`
+	Currency  string    `json:"currency"`
+	Amount    int64     `json:"amount"` // minor units, always > 0
 }
 
 // Transaction is a double-entry transfer result.
+// Batch postings leave the from/to/currency/amount fields empty and list
+// their legs in Entries instead.
+//
+// A reversal is an ordinary transaction with the legs of the original
+// swapped; ReversalOf links it to the original, whose ReversedAmount and
+// ReversalStatus track what has been returned so far.
+//
+// FX transfers are batch postings across two currencies; FXRateID and FXRate
+// record the rate that was applied.
+//
+// Mints and burns are transfers against an issuance account, marked by Kind.
+//
+// Fee is the breakdown of the fee charged on a transfer under a fee
+// schedule; a positive fee turns the transfer into a batch posting with the
+// fee legs after the transfer legs.
 type Transaction struct {
-	ID             string    `json:"id"`
-	CreatedAt      time.Time `json:"created_at"`
-	FromAccountID  string    `json:"from_account_id"`
-	ToAccountID    string    `json:"to_account_id"`
-	Currency       string    `json:"currency"`
-	Amount         int64     `json:"amount"` // minor units
-	IdempotencyKey string    `json:"idempotency_key,omitempty"`
-	Sequence       uint64    `json:"sequence"` // monotonic sequence number
+	ID             string          `json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	FromAccountID  string          `json:"from_account_id"`
+	ToAccountID    string          `json:"to_account_id"`
+	Currency       string          `json:"currency"`
+	Amount         int64           `json:"amount"` // minor units
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`
+	Sequence       uint64          `json:"sequence"` // monotonic sequence number
+	Entries        []Entry         `json:"entries,omitempty"`
+	ReversalOf     string          `json:"reversal_of,omitempty"`
+	ReversalReason string          `json:"reversal_reason,omitempty"`
+	ReversedAmount int64           `json:"reversed_amount,omitempty"`
+	ReversalStatus ReversalStatus  `json:"reversal_status,omitempty"`
+	FXRateID       string          `json:"fx_rate_id,omitempty"`
+	FXRate         string          `json:"fx_rate,omitempty"`
+	Kind           TransactionKind `json:"kind,omitempty"`
+	Fee            *Fee            `json:"fee,omitempty"`
+}
+
+// Legs lists the postings of tx, expanding a plain transfer into its debit
+// and credit.
+func (tx Transaction) Legs() []Entry {
+	if len(tx.Entries) > 0 {
+		return tx.Entries
+	}
+	return []Entry{
+		{AccountID: tx.FromAccountID, Direction: Debit, Currency: tx.Currency, Amount: tx.Amount},
+		{AccountID: tx.ToAccountID, Direction: Credit, Currency: tx.Currency, Amount: tx.Amount},
+	}
+}
+
+// TransactionFilter narrows the history of one account. Direction is
+// relative to that account: debit selects transactions that took funds from
+// it, credit those that paid into it. From is inclusive, To exclusive; zero
+// values leave the range open.
+type TransactionFilter struct {
+	Direction Direction
+	Currency  string
+	From      time.Time
+	To        time.Time
+	AfterSeq  uint64
+	Limit     int
+}
+
+// match reports whether tx touches accountID in the way f asks for.
+func (f TransactionFilter) match(accountID string, tx Transaction) bool {
+	if tx.Sequence <= f.AfterSeq {
+		return false
+	}
+	if !f.From.IsZero() && tx.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !tx.CreatedAt.Before(f.To) {
+		return false
+	}
+	if len(tx.Entries) == 0 {
+		if f.Currency != "" && tx.Currency != f.Currency {
+			return false
+		}
+		return (tx.FromAccountID == accountID && f.Direction != Credit) ||
+			(tx.ToAccountID == accountID && f.Direction != Debit)
+	}
+	for _, e := range tx.Entries {
+		if e.AccountID == accountID &&
+			(f.Direction == "" || e.Direction == f.Direction) &&
+			(f.Currency == "" || e.Currency == f.Currency) {
+			return true
+		}
+	}
+	return false
 }
 
 var (
@@ -41,8 +140,49 @@ var (
 	ErrInsufficientFunds = errors.New("insufficient funds")
 	ErrInvalidAmount     = errors.New("invalid amount (must be > 0)")
 	ErrInvalidCurrency   = errors.New("invalid currency")
+	ErrUnbalanced        = errors.New("unbalanced entries")
 )
 
+// ValidateEntries checks the shape of a batch posting: at least two legs,
+// positive amounts, known directions and debits == credits per currency.
+func ValidateEntries(entries []Entry) error {
+	if len(entries) < 2 {
+		return ErrUnbalanced
+	}
+	net := make(map[string]int64)
+	for _, e := range entries {
+		if e.AccountID == "" {
+			return ErrNotFound
+		}
+		if e.Currency == "" {
+			return ErrInvalidCurrency
+		}
+		if e.Amount <= 0 {
+			return ErrInvalidAmount
+		}
+		switch e.Direction {
+		case Debit:
+			if net[e.Currency] > math.MaxInt64-e.Amount {
+				return ErrInvalidAmount
+			}
+			net[e.Currency] += e.Amount
+		case Credit:
+			if net[e.Currency] < math.MinInt64+e.Amount {
+				return ErrInvalidAmount
+			}
+			net[e.Currency] -= e.Amount
+		default:
+			return ErrUnbalanced
+		}
+	}
+	for _, v := range net {
+		if v != 0 {
+			return ErrUnbalanced
+		}
+	}
+	return nil
+}
+
 func newID() string {
 	return ids.New()
 }
@@ -0,0 +1,343 @@
+package ledger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by a Durable ledger after Close, or once writing its
+// log has failed.
+var ErrClosed = errors.New("ledger closed")
+
+// walRecord is one committed state change. Records carry results rather than
+// requests (ids, timestamps and sequence numbers included), so replaying them
+// does not depend on the clock or on rates and holds that applied at the time.
+// A record that touches several kinds of state, such as a hold capture and
+// its transaction, is applied all or nothing.
+type walRecord struct {
+	Account     *storedAccount   `json:"account,omitempty"`
+	Status      *statusChange    `json:"status,omitempty"`
+	Tx          *Transaction     `json:"tx,omitempty"`
+	Hold        *Hold            `json:"hold,omitempty"`
+	FXRate      *FXRate          `json:"fx_rate,omitempty"`
+	Liquidity   *liquidityChange `json:"liquidity,omitempty"`
+	Currency    *Currency        `json:"currency,omitempty"`
+	Issuer      *issuerChange    `json:"issuer,omitempty"`
+	FeeSchedule *FeeSchedule     `json:"fee_schedule,omitempty"`
+	Limit       *limitChange     `json:"limit,omitempty"`
+}
+
+type storedAccount struct {
+	Account
+	Opening    Money  `json:"opening"`
+	OpeningSeq uint64 `json:"opening_seq"`
+}
+
+type statusChange struct {
+	AccountID string        `json:"account_id"`
+	Status    AccountStatus `json:"status"`
+}
+
+type liquidityChange struct {
+	Currency  string `json:"currency"`
+	AccountID string `json:"account_id"`
+}
+
+type issuerChange struct {
+	Currency  string `json:"currency"`
+	AccountID string `json:"account_id"`
+}
+
+type limitChange struct {
+	Limit   Limit `json:"limit"`
+	Deleted bool  `json:"deleted,omitempty"`
+}
+
+// logLocked hands rec to the write-ahead log, if there is one. Callers must
+// hold s.mu for writing, so records are logged in commit order.
+func (s *InMemory) logLocked(rec walRecord) {
+	if s.wal != nil {
+		s.wal.append(rec)
+	}
+}
+
+// Frames are a 4-byte little-endian payload length, the CRC-32C of the
+// payload and the JSON-encoded record.
+const walHeaderSize = 8
+
+// maxWALRecord bounds the frame length trusted while reading, so a corrupt
+// header cannot trigger a huge allocation.
+const maxWALRecord = 64 << 20
+
+var walCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// wal is an append-only log split into generations, one file each. A
+// snapshot covers every generation before the one it names.
+//
+// Appends only reach the OS page cache; sync makes them durable. Concurrent
+// callers of sync share one fsync: the first becomes the leader, optionally
+// waits delay for more appends to batch, and syncs on behalf of everyone
+// waiting.
+type wal struct {
+	dir   string
+	delay time.Duration
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	f        *os.File
+	w        *bufio.Writer
+	gen      uint64
+	appended uint64
+	synced   uint64
+	syncing  bool
+	err      error
+}
+
+func walPath(dir string, gen uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("wal-%020d.log", gen))
+}
+
+// walGenerations lists the generations present in dir in ascending order.
+func walGenerations(dir string) ([]uint64, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	if err != nil {
+		return nil, err
+	}
+	var gens []uint64
+	for _, name := range names {
+		base := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(name), "wal-"), ".log")
+		gen, err := strconv.ParseUint(base, 10, 64)
+		if err != nil {
+			continue
+		}
+		gens = append(gens, gen)
+	}
+	sort.Slice(gens, func(i, j int) bool { return gens[i] < gens[j] })
+	return gens, nil
+}
+
+func openWAL(dir string, gen uint64, delay time.Duration) (*wal, error) {
+	w := &wal{dir: dir, delay: delay}
+	w.cond = sync.NewCond(&w.mu)
+	if err := w.openLocked(gen); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *wal) openLocked(gen uint64) error {
+	f, err := os.OpenFile(walPath(w.dir, gen), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := syncDir(w.dir); err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.f, w.w, w.gen = f, bufio.NewWriterSize(f, 64<<10), gen
+	return nil
+}
+
+func (w *wal) append(rec walRecord) {
+	payload, err := json.Marshal(rec)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return
+	}
+	if err == nil {
+		var hdr [walHeaderSize]byte
+		binary.LittleEndian.PutUint32(hdr[0:4], uint32(len(payload)))
+		binary.LittleEndian.PutUint32(hdr[4:8], crc32.Checksum(payload, walCRC))
+		if _, err = w.w.Write(hdr[:]); err == nil {
+			_, err = w.w.Write(payload)
+		}
+	}
+	if err != nil {
+		w.fail(err)
+		return
+	}
+	w.appended++
+}
+
+// healthy reports the error that stopped the log, if any.
+func (w *wal) healthy() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// sync returns once every record appended before the call is on disk.
+func (w *wal) sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	target := w.appended
+	for w.synced < target && w.err == nil {
+		if w.syncing {
+			w.cond.Wait()
+			continue
+		}
+		w.syncing = true
+		if w.delay > 0 {
+			w.mu.Unlock()
+			time.Sleep(w.delay)
+			w.mu.Lock()
+		}
+		upto := w.appended
+		err := w.w.Flush()
+		f := w.f
+		w.mu.Unlock()
+		if err == nil {
+			err = f.Sync()
+		}
+		w.mu.Lock()
+		w.syncing = false
+		if err != nil {
+			w.fail(err)
+		} else if upto > w.synced {
+			w.synced = upto
+		}
+		w.cond.Broadcast()
+	}
+	return w.err
+}
+
+// rotate makes the current generation durable and starts the next one.
+// Callers must keep appends out, which the ledger does by holding its lock.
+func (w *wal) rotate() (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.finishLocked(); err != nil {
+		return 0, err
+	}
+	if err := w.openLocked(w.gen + 1); err != nil {
+		w.fail(err)
+		return 0, w.err
+	}
+	return w.gen, nil
+}
+
+func (w *wal) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.finishLocked(); err != nil {
+		return err
+	}
+	w.err = ErrClosed
+	w.cond.Broadcast()
+	return nil
+}
+
+// finishLocked flushes, syncs and closes the current file.
+func (w *wal) finishLocked() error {
+	for w.syncing {
+		w.cond.Wait()
+	}
+	if w.err != nil {
+		return w.err
+	}
+	err := w.w.Flush()
+	if err == nil {
+		err = w.f.Sync()
+	}
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		w.fail(err)
+		return w.err
+	}
+	w.synced = w.appended
+	w.cond.Broadcast()
+	return nil
+}
+
+func (w *wal) fail(err error) {
+	if w.err == nil {
+		w.err = fmt.Errorf("%w: wal: %v", ErrClosed, err)
+	}
+}
+
+// readWAL calls apply for every intact record of the file at path. A torn or
+// corrupt frame ends the log: when truncate is set the file is cut back to
+// the last intact record, which is what a crash in the middle of an append
+// leaves behind; otherwise it is reported as an error.
+func readWAL(path string, truncate bool, apply func(walRecord) error) (int, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var (
+		offset int64
+		n      int
+	)
+	for {
+		rec, size, err := readFrame(r)
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			if !truncate {
+				return n, fmt.Errorf("wal %s corrupt at offset %d: %v", filepath.Base(path), offset, err)
+			}
+			if err := f.Truncate(offset); err != nil {
+				return n, err
+			}
+			return n, f.Sync()
+		}
+		if err := apply(rec); err != nil {
+			return n, fmt.Errorf("wal %s at offset %d: %w", filepath.Base(path), offset, err)
+		}
+		offset += size
+		n++
+	}
+}
+
+// readFrame reads one record and returns the number of bytes it occupied.
+// It returns io.EOF only at a clean frame boundary.
+func readFrame(r io.Reader) (walRecord, int64, error) {
+	var hdr [walHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return walRecord{}, 0, err
+	}
+	size := binary.LittleEndian.Uint32(hdr[0:4])
+	if size > maxWALRecord {
+		return walRecord{}, 0, errors.New("frame too large")
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return walRecord{}, 0, io.ErrUnexpectedEOF
+	}
+	if crc32.Checksum(payload, walCRC) != binary.LittleEndian.Uint32(hdr[4:8]) {
+		return walRecord{}, 0, errors.New("checksum mismatch")
+	}
+	var rec walRecord
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return walRecord{}, 0, err
+	}
+	return rec, walHeaderSize + int64(size), nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
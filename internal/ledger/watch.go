@@ -0,0 +1,81 @@
+package ledger
+
+import (
+	"context"
+	"sort"
+)
+
+// Watcher is implemented by ledgers that can push commits to a consumer
+// instead of being polled with ListTransactions.
+type Watcher interface {
+	// WatchTransactions calls fn with every transaction visible under ctx
+	// whose sequence is above after, in sequence order and without gaps:
+	// first the committed history, then new transactions as they commit.
+	// It only returns once ctx ends or fn fails, with that error.
+	WatchTransactions(ctx context.Context, after uint64, fn func(Transaction) error) error
+}
+
+// WatchFilter narrows a transaction feed; the zero value passes everything.
+// With AccountID it selects like TransactionFilter does for that account's
+// history. Without it, Currency keeps transactions moving that currency and
+// Direction is ignored.
+type WatchFilter struct {
+	AccountID string
+	Direction Direction
+	Currency  string
+}
+
+// Match reports whether tx passes f.
+func (f WatchFilter) Match(tx Transaction) bool {
+	if f.AccountID != "" {
+		return TransactionFilter{Direction: f.Direction, Currency: f.Currency}.match(f.AccountID, tx)
+	}
+	if f.Currency == "" {
+		return true
+	}
+	if len(tx.Entries) == 0 {
+		return tx.Currency == f.Currency
+	}
+	for _, e := range tx.Entries {
+		if e.Currency == f.Currency {
+			return true
+		}
+	}
+	return false
+}
+
+// watchBatch bounds how many transactions a watcher copies per lock hold.
+const watchBatch = 1000
+
+// WatchTransactions implements Watcher. Transactions are delivered outside
+// the ledger lock, so a slow consumer never holds up commits.
+func (s *InMemory) WatchTransactions(ctx context.Context, after uint64, fn func(Transaction) error) error {
+	for {
+		s.mu.RLock()
+		i := sort.Search(len(s.txs), func(i int) bool { return s.txs[i].Sequence > after })
+		var batch []Transaction
+		for ; i < len(s.txs) && len(batch) < watchBatch; i++ {
+			if s.touchesVisible(ctx, s.txs[i]) {
+				batch = append(batch, s.txs[i])
+			}
+			after = s.txs[i].Sequence
+		}
+		caughtUp := i == len(s.txs)
+		commits := s.commits
+		s.mu.RUnlock()
+
+		for _, tx := range batch {
+			if err := fn(tx); err != nil {
+				return err
+			}
+		}
+		if !caughtUp {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-commits:
+		}
+	}
+}
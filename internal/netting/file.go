@@ -0,0 +1,198 @@
+package netting
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// FileStore is a MemoryStore whose changes are appended to a journal file
+// and fsynced before each call returns. OpenFileStore replays the journal,
+// so cycles, including one caught settling, survive a restart. It keeps the
+// cycles of a durable in-memory ledger (ledger.OpenDurable) next to its
+// data; only one process may open a journal at a time.
+type FileStore struct {
+	mem *MemoryStore
+
+	mu  sync.Mutex // serializes changes and their journal records
+	f   *os.File
+	err error // first write failure; the store refuses changes after it
+}
+
+var _ Store = (*FileStore)(nil)
+
+// journalRecord is one line of the journal: a new payment, a new cycle, or
+// a cycle after Update was applied to it.
+type journalRecord struct {
+	Payment *Payment     `json:"payment,omitempty"`
+	Cycle   *Cycle       `json:"cycle,omitempty"`
+	Update  *CycleUpdate `json:"update,omitempty"`
+}
+
+// OpenFileStore opens or creates the journal at path and recovers the
+// cycles it records. A record torn by a crash at the end of the journal is
+// discarded; damage anywhere else fails the open.
+func OpenFileStore(path string) (*FileStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	mem := NewMemoryStore()
+	good, err := replayJournal(f, mem)
+	if err == nil {
+		err = f.Truncate(good)
+	}
+	if err == nil {
+		_, err = f.Seek(good, io.SeekStart)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("netting journal %s: %w", path, err)
+	}
+	return &FileStore{mem: mem, f: f}, nil
+}
+
+// replayJournal applies the records of f to mem and returns the length of
+// the journal up to the last complete record.
+func replayJournal(f *os.File, mem *MemoryStore) (int64, error) {
+	r := bufio.NewReader(f)
+	var good int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A line without its newline was torn by a crash.
+			return good, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		var rec journalRecord
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			return 0, fmt.Errorf("record at offset %d: %w", good, err)
+		}
+		mem.restore(rec)
+		good += int64(len(line))
+	}
+}
+
+// Close closes the journal.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+func (s *FileStore) CreateCycle(ctx context.Context, c Cycle) (Cycle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return Cycle{}, s.err
+	}
+	c, err := s.mem.CreateCycle(ctx, c)
+	if err != nil {
+		return Cycle{}, err
+	}
+	if err := s.appendLocked(journalRecord{Cycle: &c}); err != nil {
+		return Cycle{}, err
+	}
+	return c, nil
+}
+
+func (s *FileStore) GetCycle(ctx context.Context, id string) (Cycle, error) {
+	return s.mem.GetCycle(ctx, id)
+}
+
+func (s *FileStore) ListCycles(ctx context.Context, status CycleStatus) ([]Cycle, error) {
+	return s.mem.ListCycles(ctx, status)
+}
+
+func (s *FileStore) AddPayment(ctx context.Context, p Payment) (Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return Payment{}, s.err
+	}
+	s.mem.mu.Lock()
+	seq := s.mem.seq
+	s.mem.mu.Unlock()
+	p, err := s.mem.AddPayment(ctx, p)
+	if err != nil {
+		return Payment{}, err
+	}
+	if p.Sequence > seq {
+		if err := s.appendLocked(journalRecord{Payment: &p}); err != nil {
+			return Payment{}, err
+		}
+	}
+	return p, nil
+}
+
+func (s *FileStore) ListPayments(ctx context.Context, cycleID string) ([]Payment, error) {
+	return s.mem.ListPayments(ctx, cycleID)
+}
+
+func (s *FileStore) UpdateCycle(ctx context.Context, id string, from CycleStatus, u CycleUpdate) (Cycle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return Cycle{}, s.err
+	}
+	c, err := s.mem.UpdateCycle(ctx, id, from, u)
+	if err != nil {
+		return Cycle{}, err
+	}
+	if err := s.appendLocked(journalRecord{Cycle: &c, Update: &u}); err != nil {
+		return Cycle{}, err
+	}
+	return c, nil
+}
+
+// appendLocked writes rec to the journal and syncs it. A failure leaves the
+// memory ahead of the journal, so it is kept and returned by every later
+// change.
+func (s *FileStore) appendLocked(rec journalRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := s.f.Write(append(data, '\n')); err != nil {
+		s.err = fmt.Errorf("netting journal: %w", err)
+		return s.err
+	}
+	if err := s.f.Sync(); err != nil {
+		s.err = fmt.Errorf("netting journal: %w", err)
+		return s.err
+	}
+	return nil
+}
+
+// restore applies a journal record as it was recorded.
+func (s *MemoryStore) restore(rec journalRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p := rec.Payment; p != nil {
+		s.payments[p.CycleID] = append(s.payments[p.CycleID], *p)
+		if p.IdempotencyKey != "" {
+			s.byKey[p.IdempotencyKey] = *p
+		}
+		s.seq = max(s.seq, p.Sequence)
+	}
+	if c := rec.Cycle; c != nil {
+		i, ok := s.byID[c.ID]
+		if !ok {
+			s.cycles = append(s.cycles, *c)
+			s.byID[c.ID] = len(s.cycles) - 1
+		} else {
+			s.cycles[i] = *c
+		}
+		if rec.Update != nil {
+			s.updatePaymentsLocked(c.ID, *rec.Update)
+		}
+	}
+}
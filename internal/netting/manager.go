@@ -0,0 +1,372 @@
+package netting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"qazna.org/internal/ledger"
+)
+
+// Manager runs cycles kept in a Store against a ledger. Callers check who
+// may act on which accounts; the manager acts with the context it is given,
+// so settlement needs one that can debit every participant.
+type Manager struct {
+	store      Store
+	ledger     ledger.Service
+	currencies ledger.CurrencyRegistry
+	fees       FeeFunc
+	screen     ScreenFunc
+}
+
+// FeeFunc quotes the fee the payer of p is charged, nil for none.
+type FeeFunc func(ctx context.Context, p Payment) (*ledger.Fee, error)
+
+// ScreenFunc reports whether p may still settle. An error stops the
+// settlement.
+type ScreenFunc func(ctx context.Context, p Payment) (bool, error)
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithCurrencies checks the currency and amount of submitted payments
+// against reg. By default they are checked against the ledger's registry
+// when it has one.
+func WithCurrencies(reg ledger.CurrencyRegistry) Option {
+	return func(m *Manager) {
+		m.currencies = reg
+	}
+}
+
+// WithFees fixes the fee of each payment with quote when it is submitted.
+func WithFees(quote FeeFunc) Option {
+	return func(m *Manager) {
+		m.fees = quote
+	}
+}
+
+// WithScreen has Settle run every pending payment past screen before it
+// plans; the payments screen turns down are removed.
+func WithScreen(screen ScreenFunc) Option {
+	return func(m *Manager) {
+		m.screen = screen
+	}
+}
+
+// NewManager runs the cycles of store against l.
+func NewManager(store Store, l ledger.Service, opts ...Option) *Manager {
+	m := &Manager{store: store, ledger: l}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if reg, ok := l.(ledger.CurrencyRegistry); ok && m.currencies == nil {
+		m.currencies = reg
+	}
+	return m
+}
+
+// Store returns the store the manager keeps cycles in.
+func (m *Manager) Store() Store { return m.store }
+
+// Open opens a cycle settling against c.SettlementAccountID, which must
+// exist.
+func (m *Manager) Open(ctx context.Context, c Cycle) (Cycle, error) {
+	if c.SettlementAccountID == "" {
+		return Cycle{}, fmt.Errorf("%w: settlement account is required", ErrInvalidCycle)
+	}
+	if _, err := m.ledger.GetAccount(ctx, c.SettlementAccountID); err != nil {
+		return Cycle{}, err
+	}
+	return m.store.CreateCycle(ctx, c)
+}
+
+// Submit queues a payment in an open cycle, with the fee in force for its
+// payer. It must pass Check.
+func (m *Manager) Submit(ctx context.Context, p Payment) (Payment, error) {
+	if err := m.Check(ctx, p); err != nil {
+		return Payment{}, err
+	}
+	if m.fees != nil {
+		fee, err := m.fees(ctx, p)
+		if err != nil {
+			return Payment{}, err
+		}
+		p.Fee = fee
+	}
+	return m.store.AddPayment(ctx, p)
+}
+
+// Check validates a payment for its cycle without queuing it. Both accounts
+// must exist and differ from each other and from the settlement account,
+// and the currency must be known, enabled and allow the amount.
+func (m *Manager) Check(ctx context.Context, p Payment) error {
+	c, err := m.store.GetCycle(ctx, p.CycleID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case p.Amount <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidPayment)
+	case p.Currency == "":
+		return fmt.Errorf("%w: currency is required", ErrInvalidPayment)
+	case p.FromAccountID == "" || p.ToAccountID == "":
+		return fmt.Errorf("%w: from and to accounts are required", ErrInvalidPayment)
+	case p.FromAccountID == p.ToAccountID:
+		return fmt.Errorf("%w: from and to accounts must differ", ErrInvalidPayment)
+	case p.FromAccountID == c.SettlementAccountID || p.ToAccountID == c.SettlementAccountID:
+		return fmt.Errorf("%w: the settlement account cannot take part", ErrInvalidPayment)
+	case len(p.IdempotencyKey) > 128:
+		return fmt.Errorf("%w: idempotency key must be at most 128 characters", ErrInvalidPayment)
+	}
+	for _, id := range []string{p.FromAccountID, p.ToAccountID} {
+		if _, err := m.ledger.GetAccount(ledger.WithoutOrganizationScope(ctx), id); err != nil {
+			return err
+		}
+	}
+	if m.currencies == nil {
+		return nil
+	}
+	cur, err := m.currencies.GetCurrency(ctx, p.Currency)
+	if errors.Is(err, ledger.ErrNotFound) {
+		return fmt.Errorf("%w: unknown currency %q", ledger.ErrInvalidCurrency, p.Currency)
+	}
+	if err != nil {
+		return err
+	}
+	return cur.Check(p.Amount)
+}
+
+// Close stops an open cycle from taking payments.
+func (m *Manager) Close(ctx context.Context, id string) (Cycle, error) {
+	return m.store.UpdateCycle(ctx, id, CycleOpen, CycleUpdate{Status: CycleClosed})
+}
+
+// Cancel cancels an open or closed cycle; none of its payments settle.
+func (m *Manager) Cancel(ctx context.Context, id, note string) (Cycle, error) {
+	c, err := m.store.GetCycle(ctx, id)
+	if err != nil {
+		return Cycle{}, err
+	}
+	return m.store.UpdateCycle(ctx, id, c.Status, CycleUpdate{Status: CycleCancelled, Note: note})
+}
+
+// Settle settles a closed cycle. Payments to accounts that cannot be
+// credited are removed first, then those the screen turns down, then those
+// Plan removes against the participants' available balances. The positions
+// of the rest, fees included, are posted in one transaction under the
+// idempotency key "netting-<cycle id>". Limits apply to that posting, so
+// they cap what each participant pays net rather than what it submitted;
+// a posting over a limit is refused like any other.
+//
+// The removals are stored, with the cycle settling, before the posting is
+// made, so settling a cycle left settling by a failure posts the same
+// transaction. When the ledger refuses the posting, as when a balance
+// dropped in between, the cycle returns to closed and the error is
+// returned; settling it again plans anew. A settled cycle is returned as
+// it is.
+func (m *Manager) Settle(ctx context.Context, id string) (Cycle, error) {
+	c, err := m.store.GetCycle(ctx, id)
+	if err != nil {
+		return Cycle{}, err
+	}
+	switch c.Status {
+	case CycleSettled:
+		return c, nil
+	case CycleClosed:
+		removed, err := m.plan(ctx, c)
+		if err != nil {
+			return Cycle{}, err
+		}
+		if c, err = m.store.UpdateCycle(ctx, id, CycleClosed, CycleUpdate{Status: CycleSettling, Removed: removed}); err != nil {
+			return Cycle{}, err
+		}
+	case CycleSettling:
+	default:
+		return Cycle{}, ErrInvalidTransition
+	}
+
+	payments, err := m.store.ListPayments(ctx, id)
+	if err != nil {
+		return Cycle{}, err
+	}
+	var kept []Payment
+	for _, p := range payments {
+		if p.Status == PaymentPending {
+			kept = append(kept, p)
+		}
+	}
+	var txID string
+	if entries := Entries(c.SettlementAccountID, Net(kept)); len(entries) > 0 {
+		tx, err := m.ledger.PostEntries(ctx, entries, "netting-"+c.ID)
+		if refused(err) {
+			if _, rerr := m.store.UpdateCycle(ctx, id, CycleSettling, CycleUpdate{Status: CycleClosed}); rerr != nil {
+				return Cycle{}, errors.Join(err, rerr)
+			}
+			return Cycle{}, err
+		}
+		if err != nil {
+			return Cycle{}, err
+		}
+		txID = tx.ID
+	}
+	return m.store.UpdateCycle(ctx, id, CycleSettling, CycleUpdate{Status: CycleSettled, TransactionID: txID})
+}
+
+// refused reports whether the ledger turned a posting down, as opposed to
+// failing in a way that leaves open whether it was committed.
+func refused(err error) bool {
+	for _, target := range []error{
+		ledger.ErrInsufficientFunds, ledger.ErrAccountFrozen, ledger.ErrAccountClosed, ledger.ErrNotFound,
+		ledger.ErrLimitExceeded, ledger.ErrInvalidAmount, ledger.ErrInvalidCurrency, ledger.ErrIssuanceAccount,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// plan decides the removals of a closed cycle.
+func (m *Manager) plan(ctx context.Context, c Cycle) ([]Removal, error) {
+	payments, err := m.store.ListPayments(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		removals   []Removal
+		pending    []Payment
+		creditable = make(map[string]bool)
+	)
+	for _, p := range payments {
+		if p.Status != PaymentPending {
+			continue
+		}
+		ok, seen := creditable[p.ToAccountID]
+		if !seen {
+			acc, err := m.ledger.GetAccount(ctx, p.ToAccountID)
+			switch {
+			case errors.Is(err, ledger.ErrNotFound):
+			case err != nil:
+				return nil, err
+			default:
+				ok = acc.Status.CheckCredit() == nil && acc.Type != ledger.AccountTypeIssuance
+			}
+			creditable[p.ToAccountID] = ok
+		}
+		if !ok {
+			removals = append(removals, Removal{PaymentID: p.ID, Reason: ReasonPayeeUnavailable})
+			continue
+		}
+		if m.screen != nil {
+			allowed, err := m.screen(ctx, p)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				removals = append(removals, Removal{PaymentID: p.ID, Reason: ReasonScreened})
+				continue
+			}
+		}
+		pending = append(pending, p)
+	}
+
+	// Every payer needs its liquidity: removals can leave a net creditor
+	// owing.
+	liquidity := make(map[Key]int64)
+	for _, pos := range Net(pending) {
+		if pos.Sent == 0 {
+			continue
+		}
+		k := Key{pos.AccountID, pos.Currency}
+		acc, err := m.ledger.GetAccount(ctx, pos.AccountID)
+		if errors.Is(err, ledger.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if acc.Status.CheckDebit() != nil || acc.Type == ledger.AccountTypeIssuance {
+			continue
+		}
+		bal, err := m.ledger.GetBalance(ctx, pos.AccountID, pos.Currency)
+		if err != nil {
+			return nil, err
+		}
+		liquidity[k] = bal.Available
+	}
+	_, removed := Plan(pending, liquidity)
+	for _, p := range removed {
+		removals = append(removals, Removal{PaymentID: p.ID, Reason: ReasonInsufficientLiquidity})
+	}
+	return removals, nil
+}
+
+// Report summarises a cycle: the positions of the payments that settled,
+// or would settle as things stand for a cycle not yet settled, and the
+// payments removed.
+type Report struct {
+	Cycle      Cycle           `json:"cycle"`
+	Payments   int             `json:"payments"`
+	Currencies []CurrencyTotal `json:"currencies"`
+	Positions  []Position      `json:"positions"`
+	Removed    []Payment       `json:"removed"`
+}
+
+// CurrencyTotal compares the gross value of a cycle's payments in one
+// currency with the net value that moves to settle them.
+type CurrencyTotal struct {
+	Currency string `json:"currency"`
+	Payments int    `json:"payments"`
+	Gross    int64  `json:"gross"`
+	Fees     int64  `json:"fees"`
+	// Net is the sum of the net debit positions.
+	Net int64 `json:"net"`
+	// Saved is the liquidity netting saved: Gross and Fees less Net.
+	Saved int64 `json:"saved"`
+}
+
+// Report reports on cycle id.
+func (m *Manager) Report(ctx context.Context, id string) (Report, error) {
+	c, err := m.store.GetCycle(ctx, id)
+	if err != nil {
+		return Report{}, err
+	}
+	payments, err := m.store.ListPayments(ctx, id)
+	if err != nil {
+		return Report{}, err
+	}
+	rep := Report{Cycle: c, Payments: len(payments), Currencies: []CurrencyTotal{}, Removed: []Payment{}}
+	var counted []Payment
+	totals := make(map[string]*CurrencyTotal)
+	for _, p := range payments {
+		switch p.Status {
+		case PaymentRemoved:
+			rep.Removed = append(rep.Removed, p)
+			continue
+		case PaymentCancelled:
+			continue
+		}
+		counted = append(counted, p)
+		t, ok := totals[p.Currency]
+		if !ok {
+			t = &CurrencyTotal{Currency: p.Currency}
+			totals[p.Currency] = t
+		}
+		t.Payments++
+		t.Gross += p.Amount
+		t.Fees += p.feeAmount()
+	}
+	rep.Positions = Net(counted)
+	for _, pos := range rep.Positions {
+		if pos.Net < 0 {
+			totals[pos.Currency].Net -= pos.Net
+		}
+	}
+	for _, t := range totals {
+		t.Saved = t.Gross + t.Fees - t.Net
+		rep.Currencies = append(rep.Currencies, *t)
+	}
+	sort.Slice(rep.Currencies, func(i, j int) bool { return rep.Currencies[i].Currency < rep.Currencies[j].Currency })
+	return rep, nil
+}
@@ -0,0 +1,156 @@
+package netting
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"qazna.org/internal/ids"
+)
+
+// MemoryStore keeps cycles in process memory. It is meant for development
+// and tests; cycles are lost on restart.
+type MemoryStore struct {
+	mu       sync.Mutex
+	cycles   []Cycle
+	byID     map[string]int
+	payments map[string][]Payment // cycle id -> payments in sequence order
+	byKey    map[string]Payment
+	seq      int64
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{byID: make(map[string]int), payments: make(map[string][]Payment), byKey: make(map[string]Payment)}
+}
+
+func (s *MemoryStore) CreateCycle(_ context.Context, c Cycle) (Cycle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.ID, c.Status, c.CreatedAt = ids.New(), CycleOpen, time.Now().UTC()
+	c.ClosedAt, c.SettledAt, c.CancelledAt, c.TransactionID = nil, nil, nil, ""
+	s.cycles = append(s.cycles, c)
+	s.byID[c.ID] = len(s.cycles) - 1
+	return c, nil
+}
+
+func (s *MemoryStore) GetCycle(_ context.Context, id string) (Cycle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.byID[id]
+	if !ok {
+		return Cycle{}, ErrNotFound
+	}
+	return s.cycles[i], nil
+}
+
+func (s *MemoryStore) ListCycles(_ context.Context, status CycleStatus) ([]Cycle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Cycle
+	for _, c := range s.cycles {
+		if status == "" || c.Status == status {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) AddPayment(_ context.Context, p Payment) (Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prev, ok := s.byKey[p.IdempotencyKey]; ok && p.IdempotencyKey != "" {
+		return s.paymentLocked(prev), nil
+	}
+	i, ok := s.byID[p.CycleID]
+	if !ok {
+		return Payment{}, ErrNotFound
+	}
+	if s.cycles[i].Status != CycleOpen {
+		return Payment{}, ErrCycleNotOpen
+	}
+	if len(s.payments[p.CycleID]) >= MaxPayments {
+		return Payment{}, ErrCycleFull
+	}
+	s.seq++
+	p.ID, p.Sequence, p.Status, p.Reason, p.CreatedAt = ids.New(), s.seq, PaymentPending, "", time.Now().UTC()
+	s.payments[p.CycleID] = append(s.payments[p.CycleID], p)
+	if p.IdempotencyKey != "" {
+		s.byKey[p.IdempotencyKey] = p
+	}
+	return p, nil
+}
+
+// paymentLocked returns the current state of p.
+func (s *MemoryStore) paymentLocked(p Payment) Payment {
+	for _, cur := range s.payments[p.CycleID] {
+		if cur.ID == p.ID {
+			return cur
+		}
+	}
+	return p
+}
+
+func (s *MemoryStore) ListPayments(_ context.Context, cycleID string) ([]Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.byID[cycleID]; !ok {
+		return nil, ErrNotFound
+	}
+	return append([]Payment(nil), s.payments[cycleID]...), nil
+}
+
+func (s *MemoryStore) UpdateCycle(_ context.Context, id string, from CycleStatus, u CycleUpdate) (Cycle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.byID[id]
+	if !ok {
+		return Cycle{}, ErrNotFound
+	}
+	c := &s.cycles[i]
+	if c.Status != from || !CanTransition(from, u.Status) {
+		return Cycle{}, ErrInvalidTransition
+	}
+	now := time.Now().UTC()
+	switch u.Status {
+	case CycleClosed:
+		if c.ClosedAt == nil {
+			c.ClosedAt = &now
+		}
+	case CycleSettled:
+		c.SettledAt, c.TransactionID = &now, u.TransactionID
+	case CycleCancelled:
+		c.CancelledAt = &now
+	}
+	if u.Note != "" {
+		c.Note = u.Note
+	}
+	c.Status = u.Status
+	s.updatePaymentsLocked(id, u)
+	return *c, nil
+}
+
+// updatePaymentsLocked applies u to the payments of cycle id as CycleUpdate
+// describes.
+func (s *MemoryStore) updatePaymentsLocked(id string, u CycleUpdate) {
+	removed := make(map[string]string, len(u.Removed))
+	for _, r := range u.Removed {
+		removed[r.PaymentID] = r.Reason
+	}
+	ps := s.payments[id]
+	for j := range ps {
+		p := &ps[j]
+		if reason, ok := removed[p.ID]; ok && u.Status == CycleSettling && p.Status == PaymentPending {
+			p.Status, p.Reason = PaymentRemoved, reason
+			continue
+		}
+		if st, changed := PaymentStatusAfter(p.Status, u.Status); changed {
+			p.Status = st
+			if st == PaymentPending {
+				p.Reason = ""
+			}
+		}
+	}
+}
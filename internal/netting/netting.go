@@ -0,0 +1,325 @@
+// Package netting runs deferred net settlement. Payments submitted to an
+// open cycle are not settled one by one; when the cycle is settled they are
+// offset against each other, and each participant account only pays or
+// receives its net position per currency, in one atomic ledger posting
+// against the cycle's settlement account.
+//
+// A cycle is opened, collects payments until it is closed, and is then
+// either settled or cancelled. Payments whose payer cannot fund its net
+// position are removed before settlement, in an order fixed by the payments
+// and balances alone (see Plan). Each payment carries the fee fixed when it
+// was submitted, which the payer pays with its net position.
+package netting
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+var (
+	ErrNotFound          = errors.New("netting cycle not found")
+	ErrInvalidTransition = errors.New("netting cycle cannot make this transition")
+	ErrCycleNotOpen      = errors.New("netting cycle is not open")
+	ErrCycleFull         = errors.New("netting cycle is full")
+	ErrInvalidCycle      = errors.New("invalid netting cycle")
+	ErrInvalidPayment    = errors.New("invalid netting payment")
+)
+
+// MaxPayments bounds the payments of one cycle.
+const MaxPayments = 10000
+
+// CycleStatus tracks a cycle through its lifecycle. A settling cycle has
+// its removals decided and its posting under way; it goes on to settled,
+// or back to closed when the ledger refuses the posting.
+type CycleStatus string
+
+const (
+	CycleOpen      CycleStatus = "open"
+	CycleClosed    CycleStatus = "closed"
+	CycleSettling  CycleStatus = "settling"
+	CycleSettled   CycleStatus = "settled"
+	CycleCancelled CycleStatus = "cancelled"
+)
+
+// transitions lists the statuses each status may move to.
+var transitions = map[CycleStatus][]CycleStatus{
+	CycleOpen:     {CycleClosed, CycleCancelled},
+	CycleClosed:   {CycleSettling, CycleCancelled},
+	CycleSettling: {CycleClosed, CycleSettled},
+}
+
+// CanTransition reports whether a cycle in status from may move to to.
+func CanTransition(from, to CycleStatus) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Cycle is a deferred net settlement cycle. TransactionID is the ledger
+// posting that settled it, empty when all positions netted to zero.
+type Cycle struct {
+	ID                  string      `json:"id"`
+	Status              CycleStatus `json:"status"`
+	SettlementAccountID string      `json:"settlement_account_id"`
+	OpenedBy            string      `json:"opened_by,omitempty"`
+	OpenedByOrg         string      `json:"opened_by_org,omitempty"`
+	CreatedAt           time.Time   `json:"created_at"`
+	ClosedAt            *time.Time  `json:"closed_at,omitempty"`
+	SettledAt           *time.Time  `json:"settled_at,omitempty"`
+	CancelledAt         *time.Time  `json:"cancelled_at,omitempty"`
+	TransactionID       string      `json:"transaction_id,omitempty"`
+	Note                string      `json:"note,omitempty"`
+}
+
+// PaymentStatus tracks a payment through its cycle.
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentSettled   PaymentStatus = "settled"
+	PaymentRemoved   PaymentStatus = "removed"
+	PaymentCancelled PaymentStatus = "cancelled"
+)
+
+// Reasons a payment is removed from a cycle.
+const (
+	ReasonInsufficientLiquidity = "insufficient_liquidity"
+	ReasonPayeeUnavailable      = "payee_unavailable"
+	ReasonScreened              = "screened"
+)
+
+// Payment is an instruction to pay Amount from one account to another,
+// settled with the rest of its cycle. Sequence orders the payments of a
+// cycle by submission. Fee is the fee fixed when the payment was submitted,
+// nil when no fee schedule was in force; the payer pays it on top of
+// Amount.
+type Payment struct {
+	ID             string        `json:"id"`
+	CycleID        string        `json:"cycle_id"`
+	Sequence       int64         `json:"sequence"`
+	FromAccountID  string        `json:"from_account_id"`
+	ToAccountID    string        `json:"to_account_id"`
+	Currency       string        `json:"currency"`
+	Amount         int64         `json:"amount"`
+	Fee            *ledger.Fee   `json:"fee,omitempty"`
+	IdempotencyKey string        `json:"idempotency_key,omitempty"`
+	Status         PaymentStatus `json:"status"`
+	Reason         string        `json:"reason,omitempty"`
+	SubmittedBy    string        `json:"submitted_by,omitempty"`
+	SubmittedByOrg string        `json:"submitted_by_org,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// feeAmount returns the fee the payer of p pays on top of its amount.
+func (p Payment) feeAmount() int64 {
+	if p.Fee == nil {
+		return 0
+	}
+	return p.Fee.Amount
+}
+
+// Removal takes a payment out of settlement.
+type Removal struct {
+	PaymentID string
+	Reason    string
+}
+
+// CycleUpdate moves a cycle to Status. Moving to settling marks Removed
+// payments removed; moving back to closed restores them. Settling marks the
+// remaining pending payments settled and cancelling marks them cancelled.
+type CycleUpdate struct {
+	Status        CycleStatus
+	Removed       []Removal
+	TransactionID string
+	Note          string
+}
+
+// PaymentStatusAfter returns the status a payment in status p takes when its
+// cycle moves to to, and whether it changes.
+func PaymentStatusAfter(p PaymentStatus, to CycleStatus) (PaymentStatus, bool) {
+	switch {
+	case to == CycleClosed && p == PaymentRemoved:
+		return PaymentPending, true
+	case to == CycleSettled && p == PaymentPending:
+		return PaymentSettled, true
+	case to == CycleCancelled && p == PaymentPending:
+		return PaymentCancelled, true
+	}
+	return p, false
+}
+
+// Store keeps cycles and their payments.
+type Store interface {
+	// CreateCycle stores c as a new open cycle.
+	CreateCycle(ctx context.Context, c Cycle) (Cycle, error)
+	GetCycle(ctx context.Context, id string) (Cycle, error)
+	// ListCycles lists cycles oldest first, narrowed to status when it is
+	// set.
+	ListCycles(ctx context.Context, status CycleStatus) ([]Cycle, error)
+	// AddPayment queues p as pending in its cycle, failing with
+	// ErrCycleNotOpen unless the cycle is open and with ErrCycleFull once it
+	// holds MaxPayments. A payment already queued with the same non-empty
+	// idempotency key is returned instead.
+	AddPayment(ctx context.Context, p Payment) (Payment, error)
+	// ListPayments lists the payments of a cycle in sequence order.
+	ListPayments(ctx context.Context, cycleID string) ([]Payment, error)
+	// UpdateCycle applies u to a cycle in status from, and to its payments
+	// as CycleUpdate describes. It fails with ErrInvalidTransition when the
+	// cycle is in another status or cannot move to u.Status.
+	UpdateCycle(ctx context.Context, id string, from CycleStatus, u CycleUpdate) (Cycle, error)
+}
+
+// Key identifies a participant position: an account in one currency.
+type Key struct {
+	AccountID string
+	Currency  string
+}
+
+// Position is the net obligation of a participant in one currency over a
+// set of payments. Net is what it receives less what it sends; a negative
+// Net is what it owes. Fees count as sent by the payer and received by the
+// fee account.
+type Position struct {
+	AccountID string `json:"account_id"`
+	Currency  string `json:"currency"`
+	Sent      int64  `json:"sent"`
+	Received  int64  `json:"received"`
+	Net       int64  `json:"net"`
+}
+
+// Net computes the position of every account the payments touch, ordered
+// by currency and account.
+func Net(payments []Payment) []Position {
+	byKey := make(map[Key]*Position)
+	at := func(k Key) *Position {
+		p, ok := byKey[k]
+		if !ok {
+			p = &Position{AccountID: k.AccountID, Currency: k.Currency}
+			byKey[k] = p
+		}
+		return p
+	}
+	for _, p := range payments {
+		from, to := at(Key{p.FromAccountID, p.Currency}), at(Key{p.ToAccountID, p.Currency})
+		from.Sent += p.Amount
+		from.Net -= p.Amount
+		to.Received += p.Amount
+		to.Net += p.Amount
+		if fee := p.feeAmount(); fee > 0 {
+			acc := at(Key{p.Fee.AccountID, p.Currency})
+			from.Sent += fee
+			from.Net -= fee
+			acc.Received += fee
+			acc.Net += fee
+		}
+	}
+	out := make([]Position, 0, len(byKey))
+	for _, p := range byKey {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Currency != out[j].Currency {
+			return out[i].Currency < out[j].Currency
+		}
+		return out[i].AccountID < out[j].AccountID
+	})
+	return out
+}
+
+// Plan decides which payments settle when each participant can pay at most
+// its liquidity: the funds available to it per currency. While some
+// participant owes more than its liquidity, counting the fees it pays, the
+// one short by the most (ties going to the lowest currency, then account
+// ID) loses its latest outgoing payment in sequence order, and positions
+// are recomputed. The outcome depends only on the payments, their sequence
+// and the liquidity. Payments are returned in sequence order.
+func Plan(payments []Payment, liquidity map[Key]int64) (kept, removed []Payment) {
+	ordered := append([]Payment(nil), payments...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Sequence < ordered[j].Sequence })
+
+	net := make(map[Key]int64)
+	outgoing := make(map[Key][]int)
+	for i, p := range ordered {
+		from := Key{p.FromAccountID, p.Currency}
+		net[from] -= p.Amount + p.feeAmount()
+		net[Key{p.ToAccountID, p.Currency}] += p.Amount
+		if p.Fee != nil {
+			net[Key{p.Fee.AccountID, p.Currency}] += p.Fee.Amount
+		}
+		outgoing[from] = append(outgoing[from], i)
+	}
+	out := make([]bool, len(ordered))
+	for {
+		var (
+			worst     Key
+			shortfall int64
+		)
+		for k, n := range net {
+			short := -n - liquidity[k]
+			if n >= 0 || short <= 0 {
+				continue
+			}
+			if short > shortfall || (short == shortfall && less(k, worst)) {
+				worst, shortfall = k, short
+			}
+		}
+		if shortfall == 0 {
+			break
+		}
+		// A participant short of liquidity owes something, so it has an
+		// outgoing payment left.
+		stack := outgoing[worst]
+		i := stack[len(stack)-1]
+		outgoing[worst] = stack[:len(stack)-1]
+		out[i] = true
+		p := ordered[i]
+		net[worst] += p.Amount + p.feeAmount()
+		net[Key{p.ToAccountID, p.Currency}] -= p.Amount
+		if p.Fee != nil {
+			net[Key{p.Fee.AccountID, p.Currency}] -= p.Fee.Amount
+		}
+	}
+	for i, p := range ordered {
+		if out[i] {
+			removed = append(removed, p)
+		} else {
+			kept = append(kept, p)
+		}
+	}
+	return kept, removed
+}
+
+func less(a, b Key) bool {
+	if a.Currency != b.Currency {
+		return a.Currency < b.Currency
+	}
+	return a.AccountID < b.AccountID
+}
+
+// Entries lays out the settlement posting of positions against the
+// settlement account: each net debtor pays its debit into it and each net
+// creditor is paid its credit out of it, so it ends every currency flat.
+// Positions that net to zero have no legs; no positions yield no entries.
+func Entries(settlementAccountID string, positions []Position) []ledger.Entry {
+	var debits, credits []ledger.Entry
+	for _, p := range positions {
+		switch {
+		case p.Net < 0:
+			debits = append(debits,
+				ledger.Entry{AccountID: p.AccountID, Direction: ledger.Debit, Currency: p.Currency, Amount: -p.Net},
+				ledger.Entry{AccountID: settlementAccountID, Direction: ledger.Credit, Currency: p.Currency, Amount: -p.Net})
+		case p.Net > 0:
+			credits = append(credits,
+				ledger.Entry{AccountID: settlementAccountID, Direction: ledger.Debit, Currency: p.Currency, Amount: p.Net},
+				ledger.Entry{AccountID: p.AccountID, Direction: ledger.Credit, Currency: p.Currency, Amount: p.Net})
+		}
+	}
+	return append(debits, credits...)
+}
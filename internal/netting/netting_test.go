@@ -0,0 +1,494 @@
+package netting
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"qazna.org/internal/ledger"
+)
+
+func pay(seq int64, from, to string, amount int64) Payment {
+	return Payment{ID: from + "-" + to, Sequence: seq, FromAccountID: from, ToAccountID: to, Currency: "KZT", Amount: amount}
+}
+
+func TestNet(t *testing.T) {
+	got := Net([]Payment{pay(1, "a", "b", 100), pay(2, "b", "c", 70), pay(3, "c", "a", 50), {FromAccountID: "a", ToAccountID: "b", Currency: "USD", Amount: 5}})
+	want := []Position{
+		{AccountID: "a", Currency: "KZT", Sent: 100, Received: 50, Net: -50},
+		{AccountID: "b", Currency: "KZT", Sent: 70, Received: 100, Net: 30},
+		{AccountID: "c", Currency: "KZT", Sent: 50, Received: 70, Net: 20},
+		{AccountID: "a", Currency: "USD", Sent: 5, Net: -5},
+		{AccountID: "b", Currency: "USD", Received: 5, Net: 5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected positions:\n%+v\n%+v", got, want)
+	}
+
+	entries := Entries("settle", got)
+	if err := ledger.ValidateEntries(entries); err != nil || len(entries) != 10 {
+		t.Fatalf("expected 10 balanced legs, got %v: %+v", err, entries)
+	}
+	if entries[0] != (ledger.Entry{AccountID: "a", Direction: ledger.Debit, Currency: "KZT", Amount: 50}) ||
+		entries[1] != (ledger.Entry{AccountID: "settle", Direction: ledger.Credit, Currency: "KZT", Amount: 50}) {
+		t.Fatalf("expected debtors to pay in first, got %+v", entries[:2])
+	}
+	if len(Entries("settle", Net([]Payment{pay(1, "a", "b", 10), pay(2, "b", "a", 10)}))) != 0 {
+		t.Fatal("expected offsetting payments to need no legs")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	payments := []Payment{
+		pay(1, "a", "b", 100),
+		pay(2, "b", "c", 300),
+		pay(3, "c", "a", 40),
+		pay(4, "b", "a", 50),
+		pay(5, "a", "c", 80),
+	}
+	// All positions covered: nothing is removed.
+	kept, removed := Plan(payments, map[Key]int64{{"a", "KZT"}: 1000, {"b", "KZT"}: 1000})
+	if len(kept) != 5 || len(removed) != 0 {
+		t.Fatalf("expected every payment to settle, removed %+v", removed)
+	}
+
+	// b owes 250 with 100 available; its latest payment goes first, which
+	// leaves it owing 200, then the one before, which turns it into a
+	// creditor. That leaves a owing 140 against 100, losing its payment 5,
+	// and then c, with nothing available, owing 40 for payment 3.
+	kept, removed = Plan(payments, map[Key]int64{{"a", "KZT"}: 100, {"b", "KZT"}: 100})
+	var ids []int64
+	for _, p := range removed {
+		ids = append(ids, p.Sequence)
+	}
+	if !reflect.DeepEqual(ids, []int64{2, 3, 4, 5}) {
+		t.Fatalf("expected payments 2 to 5 to be removed, got %v", ids)
+	}
+	for _, pos := range Net(kept) {
+		if pos.Net < 0 && -pos.Net > map[string]int64{"a": 100, "b": 100}[pos.AccountID] {
+			t.Fatalf("position %+v still exceeds its liquidity", pos)
+		}
+	}
+
+	// The order payments are passed in does not matter.
+	reversed := []Payment{payments[4], payments[3], payments[2], payments[1], payments[0]}
+	if _, again := Plan(reversed, map[Key]int64{{"a", "KZT"}: 100, {"b", "KZT"}: 100}); !reflect.DeepEqual(again, removed) {
+		t.Fatalf("expected the same removals, got %+v", again)
+	}
+
+	// Equal shortfalls go to the lowest account first.
+	_, removed = Plan([]Payment{pay(1, "y", "z", 10), pay(2, "x", "z", 10)}, map[Key]int64{{"x", "KZT"}: 5, {"y", "KZT"}: 5})
+	if len(removed) != 2 || removed[0].FromAccountID != "y" || removed[1].FromAccountID != "x" {
+		t.Fatalf("unexpected removals: %+v", removed)
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	c, _ := s.CreateCycle(ctx, Cycle{SettlementAccountID: "settle"})
+	if _, err := s.UpdateCycle(ctx, c.ID, CycleOpen, CycleUpdate{Status: CycleSettled}); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected an open cycle not to settle directly, got %v", err)
+	}
+	if _, err := s.UpdateCycle(ctx, c.ID, CycleClosed, CycleUpdate{Status: CycleSettling}); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected the from status to be checked, got %v", err)
+	}
+	if _, err := s.UpdateCycle(ctx, "missing", CycleOpen, CycleUpdate{Status: CycleClosed}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	p, _ := s.AddPayment(ctx, Payment{CycleID: c.ID, FromAccountID: "a", ToAccountID: "b", Currency: "KZT", Amount: 1, IdempotencyKey: "k"})
+	if again, _ := s.AddPayment(ctx, Payment{CycleID: c.ID, FromAccountID: "a", ToAccountID: "b", Currency: "KZT", Amount: 2, IdempotencyKey: "k"}); again.ID != p.ID {
+		t.Fatalf("expected the payment to be replayed, got %+v", again)
+	}
+	if c, err := s.UpdateCycle(ctx, c.ID, CycleOpen, CycleUpdate{Status: CycleCancelled, Note: "holiday"}); err != nil || c.CancelledAt == nil || c.Note != "holiday" {
+		t.Fatalf("unexpected cancel: %+v, %v", c, err)
+	}
+	if ps, _ := s.ListPayments(ctx, c.ID); ps[0].Status != PaymentCancelled {
+		t.Fatalf("expected the payment to be cancelled, got %+v", ps[0])
+	}
+	if _, err := s.AddPayment(ctx, Payment{CycleID: c.ID, FromAccountID: "a", ToAccountID: "b", Currency: "KZT", Amount: 1}); !errors.Is(err, ErrCycleNotOpen) {
+		t.Fatalf("expected ErrCycleNotOpen, got %v", err)
+	}
+}
+
+func TestFileStoreRecovers(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "netting.journal")
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := s.CreateCycle(ctx, Cycle{SettlementAccountID: "settle"})
+	p1, _ := s.AddPayment(ctx, Payment{CycleID: c.ID, FromAccountID: "a", ToAccountID: "b", Currency: "KZT", Amount: 10, IdempotencyKey: "k"})
+	p2, _ := s.AddPayment(ctx, Payment{CycleID: c.ID, FromAccountID: "b", ToAccountID: "a", Currency: "KZT", Amount: 5})
+	if again, _ := s.AddPayment(ctx, Payment{CycleID: c.ID, FromAccountID: "a", ToAccountID: "b", Currency: "KZT", Amount: 10, IdempotencyKey: "k"}); again.ID != p1.ID {
+		t.Fatalf("expected the payment to be replayed, got %+v", again)
+	}
+	if _, err := s.UpdateCycle(ctx, c.ID, CycleOpen, CycleUpdate{Status: CycleClosed}); err != nil {
+		t.Fatal(err)
+	}
+	settling, err := s.UpdateCycle(ctx, c.ID, CycleClosed, CycleUpdate{Status: CycleSettling, Removed: []Removal{{PaymentID: p2.ID, Reason: "insufficient liquidity"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// A crash while appending leaves a torn record behind.
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.WriteString(`{"payment":{"id":"torn"`)
+	f.Close()
+
+	s, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.GetCycle(ctx, c.ID); err != nil || !reflect.DeepEqual(got, settling) {
+		t.Fatalf("cycle not recovered: %+v %v, want %+v", got, err, settling)
+	}
+	ps, _ := s.ListPayments(ctx, c.ID)
+	if len(ps) != 2 || ps[0].Status != PaymentPending || ps[1].Status != PaymentRemoved || ps[1].Reason != "insufficient liquidity" {
+		t.Fatalf("payments not recovered: %+v", ps)
+	}
+	if _, err := s.UpdateCycle(ctx, c.ID, CycleSettling, CycleUpdate{Status: CycleSettled, TransactionID: "tx-1"}); err != nil {
+		t.Fatalf("expected the recovered cycle to settle, got %v", err)
+	}
+	next, _ := s.CreateCycle(ctx, Cycle{SettlementAccountID: "settle"})
+	if p, _ := s.AddPayment(ctx, Payment{CycleID: next.ID, FromAccountID: "a", ToAccountID: "b", Currency: "KZT", Amount: 1, IdempotencyKey: "k"}); p.ID != p1.ID {
+		t.Fatalf("expected idempotency keys to survive, got %+v", p)
+	}
+	if p, _ := s.AddPayment(ctx, Payment{CycleID: next.ID, FromAccountID: "a", ToAccountID: "b", Currency: "KZT", Amount: 1}); p.Sequence <= p2.Sequence {
+		t.Fatalf("expected sequences to continue after %d, got %d", p2.Sequence, p.Sequence)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got, _ := s.GetCycle(ctx, c.ID); got.Status != CycleSettled || got.TransactionID != "tx-1" {
+		t.Fatalf("settlement not recovered: %+v", got)
+	}
+	if ps, _ := s.ListPayments(ctx, c.ID); ps[0].Status != PaymentSettled || ps[1].Status != PaymentRemoved {
+		t.Fatalf("payment statuses not recovered: %+v", ps)
+	}
+}
+
+// netFixture is a ledger with three participants and a settlement account.
+type netFixture struct {
+	ctx             context.Context
+	ledger          *ledger.InMemory
+	m               *Manager
+	a, b, c, settle string
+}
+
+func newNetFixture(t *testing.T, a, b, c int64) netFixture {
+	t.Helper()
+	f := netFixture{ctx: context.Background(), ledger: ledger.NewInMemory()}
+	f.m = NewManager(NewMemoryStore(), f.ledger)
+	for _, acc := range []struct {
+		id     *string
+		amount int64
+	}{{&f.a, a}, {&f.b, b}, {&f.c, c}, {&f.settle, 0}} {
+		created, err := f.ledger.CreateAccount(f.ctx, ledger.Money{Currency: "KZT", Amount: acc.amount})
+		if err != nil {
+			t.Fatal(err)
+		}
+		*acc.id = created.ID
+	}
+	return f
+}
+
+func (f netFixture) balance(id string) int64 {
+	b, _ := f.ledger.GetBalance(f.ctx, id, "KZT")
+	return b.Amount
+}
+
+func (f netFixture) submit(t *testing.T, cycle, from, to string, amount int64) Payment {
+	t.Helper()
+	p, err := f.m.Submit(f.ctx, Payment{CycleID: cycle, FromAccountID: from, ToAccountID: to, Currency: "KZT", Amount: amount})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestSettle(t *testing.T) {
+	f := newNetFixture(t, 10_000, 500, 0)
+	cycle, err := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle, OpenedBy: "ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.submit(t, cycle.ID, f.a, f.b, 7_000)
+	f.submit(t, cycle.ID, f.b, f.c, 6_000)
+	f.submit(t, cycle.ID, f.c, f.a, 2_000)
+	for _, bad := range []Payment{
+		{CycleID: cycle.ID, FromAccountID: f.a, ToAccountID: f.a, Currency: "KZT", Amount: 1},
+		{CycleID: cycle.ID, FromAccountID: f.a, ToAccountID: f.settle, Currency: "KZT", Amount: 1},
+		{CycleID: cycle.ID, FromAccountID: f.a, ToAccountID: f.b, Currency: "KZT", Amount: 0},
+	} {
+		if _, err := f.m.Submit(f.ctx, bad); !errors.Is(err, ErrInvalidPayment) {
+			t.Fatalf("expected ErrInvalidPayment for %+v, got %v", bad, err)
+		}
+	}
+	if _, err := f.m.Submit(f.ctx, Payment{CycleID: cycle.ID, FromAccountID: f.a, ToAccountID: "missing", Currency: "KZT", Amount: 1}); !errors.Is(err, ledger.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown payee, got %v", err)
+	}
+	if _, err := f.m.Settle(f.ctx, cycle.ID); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected an open cycle not to settle, got %v", err)
+	}
+	if _, err := f.m.Close(f.ctx, cycle.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.m.Submit(f.ctx, Payment{CycleID: cycle.ID, FromAccountID: f.a, ToAccountID: f.b, Currency: "KZT", Amount: 1}); !errors.Is(err, ErrCycleNotOpen) {
+		t.Fatalf("expected ErrCycleNotOpen, got %v", err)
+	}
+
+	// b sends 6000 with only 500, but receives 7000 first: gross it could
+	// not pay, net it is owed 1000.
+	settled, err := f.m.Settle(f.ctx, cycle.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settled.Status != CycleSettled || settled.TransactionID == "" || settled.SettledAt == nil {
+		t.Fatalf("unexpected cycle: %+v", settled)
+	}
+	for id, want := range map[string]int64{f.a: 5_000, f.b: 1_500, f.c: 4_000, f.settle: 0} {
+		if got := f.balance(id); got != want {
+			t.Fatalf("balance of %s: expected %d, got %d", id, want, got)
+		}
+	}
+	txs, _, _ := f.ledger.ListTransactions(f.ctx, 10, 0)
+	if len(txs) != 1 || len(txs[0].Entries) != 6 || txs[0].IdempotencyKey != "netting-"+cycle.ID {
+		t.Fatalf("expected a single settlement posting, got %+v", txs)
+	}
+	if again, err := f.m.Settle(f.ctx, cycle.ID); err != nil || again.TransactionID != settled.TransactionID {
+		t.Fatalf("expected settling again to return the settled cycle, got %+v, %v", again, err)
+	}
+	if _, err := f.m.Cancel(f.ctx, cycle.ID, ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected a settled cycle not to cancel, got %v", err)
+	}
+
+	rep, err := f.m.Report(f.ctx, cycle.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Payments != 3 || len(rep.Removed) != 0 || len(rep.Positions) != 3 ||
+		!reflect.DeepEqual(rep.Currencies, []CurrencyTotal{{Currency: "KZT", Payments: 3, Gross: 15_000, Net: 5_000, Saved: 10_000}}) {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+}
+
+func TestSettleRemovesUnfundedPayments(t *testing.T) {
+	f := newNetFixture(t, 1_000, 0, 0)
+	closed, err := f.ledger.CreateAccount(f.ctx, ledger.Money{Currency: "KZT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cycle, _ := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle})
+	f.submit(t, cycle.ID, f.a, f.b, 800)
+	f.submit(t, cycle.ID, f.b, f.a, 900)
+	f.submit(t, cycle.ID, f.a, f.c, 300)
+	f.submit(t, cycle.ID, f.a, closed.ID, 50)
+	f.ledger.SetAccountStatus(f.ctx, f.b, ledger.AccountFrozen)
+	f.ledger.SetAccountStatus(f.ctx, closed.ID, ledger.AccountClosed)
+	f.m.Close(f.ctx, cycle.ID)
+
+	// The payment to the closed account goes first. b is frozen, so it
+	// cannot pay its 100 net debit and loses its payment; without it a owes
+	// 1100 against 1000 and loses its latest remaining payment.
+	if _, err := f.m.Settle(f.ctx, cycle.ID); err != nil {
+		t.Fatal(err)
+	}
+	payments, _ := f.m.Store().ListPayments(f.ctx, cycle.ID)
+	var got []string
+	for _, p := range payments {
+		got = append(got, string(p.Status)+":"+p.Reason)
+	}
+	want := []string{"settled:", "removed:" + ReasonInsufficientLiquidity, "removed:" + ReasonInsufficientLiquidity, "removed:" + ReasonPayeeUnavailable}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for id, want := range map[string]int64{f.a: 200, f.b: 800, f.c: 0, f.settle: 0} {
+		if got := f.balance(id); got != want {
+			t.Fatalf("balance of %s: expected %d, got %d", id, want, got)
+		}
+	}
+	if rep, _ := f.m.Report(f.ctx, cycle.ID); len(rep.Removed) != 3 || rep.Currencies[0].Gross != 800 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+}
+
+func TestSettleRefusedReturnsToClosed(t *testing.T) {
+	f := newNetFixture(t, 1_000, 0, 0)
+	cycle, _ := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle})
+	f.submit(t, cycle.ID, f.a, f.b, 400)
+	f.m.Close(f.ctx, cycle.ID)
+	// A frozen settlement account cannot pay out.
+	f.ledger.SetAccountStatus(f.ctx, f.settle, ledger.AccountFrozen)
+	if _, err := f.m.Settle(f.ctx, cycle.ID); !errors.Is(err, ledger.ErrAccountFrozen) {
+		t.Fatalf("expected ErrAccountFrozen, got %v", err)
+	}
+	if c, _ := f.m.Store().GetCycle(f.ctx, cycle.ID); c.Status != CycleClosed {
+		t.Fatalf("expected the cycle back to closed, got %s", c.Status)
+	}
+
+	f.ledger.SetAccountStatus(f.ctx, f.settle, ledger.AccountActive)
+	if c, err := f.m.Settle(f.ctx, cycle.ID); err != nil || c.Status != CycleSettled || f.balance(f.b) != 400 {
+		t.Fatalf("expected the retry to settle, got %+v, %v", c, err)
+	}
+
+	// Offsetting payments settle without a posting.
+	again, _ := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle})
+	f.submit(t, again.ID, f.a, f.b, 10)
+	f.submit(t, again.ID, f.b, f.a, 10)
+	f.m.Close(f.ctx, again.ID)
+	if c, err := f.m.Settle(f.ctx, again.ID); err != nil || c.Status != CycleSettled || c.TransactionID != "" {
+		t.Fatalf("unexpected settlement: %+v, %v", c, err)
+	}
+}
+
+func TestSubmitChecksCurrency(t *testing.T) {
+	f := newNetFixture(t, 1_000, 0, 0)
+	cycle, _ := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle})
+	if _, err := f.ledger.PutCurrency(f.ctx, ledger.Currency{Code: "EKZT", Exponent: 2, Enabled: false}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.ledger.PutCurrency(f.ctx, ledger.Currency{Code: "KZT", Exponent: 2, Enabled: true, MaxAmount: 500}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		currency string
+		amount   int64
+		want     error
+	}{
+		{"QQQ", 10, ledger.ErrInvalidCurrency},
+		{"EKZT", 10, ledger.ErrInvalidCurrency},
+		{"KZT", 600, ledger.ErrInvalidAmount},
+	} {
+		_, err := f.m.Submit(f.ctx, Payment{CycleID: cycle.ID, FromAccountID: f.a, ToAccountID: f.b, Currency: tc.currency, Amount: tc.amount})
+		if !errors.Is(err, tc.want) {
+			t.Fatalf("%s %d: expected %v, got %v", tc.currency, tc.amount, tc.want, err)
+		}
+	}
+	if payments, _ := f.m.Store().ListPayments(f.ctx, cycle.ID); len(payments) != 0 {
+		t.Fatalf("expected no payments queued, got %+v", payments)
+	}
+	f.submit(t, cycle.ID, f.a, f.b, 500)
+}
+
+func TestSettleChargesFees(t *testing.T) {
+	f := newNetFixture(t, 1_000, 1_000, 0)
+	feeAccount, _ := f.ledger.CreateAccount(f.ctx, ledger.Money{Currency: "KZT"})
+	f.m = NewManager(f.m.Store(), f.ledger, WithFees(func(ctx context.Context, p Payment) (*ledger.Fee, error) {
+		return &ledger.Fee{ScheduleVersion: 1, AccountID: feeAccount.ID, Currency: p.Currency, Amount: p.Amount / 100}, nil
+	}))
+	cycle, _ := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle})
+	if p := f.submit(t, cycle.ID, f.a, f.b, 800); p.Fee == nil || p.Fee.Amount != 8 {
+		t.Fatalf("expected the fee fixed at submission, got %+v", p.Fee)
+	}
+	f.submit(t, cycle.ID, f.b, f.a, 600)
+	// a cannot pay 200 net and its fee of 8 with 150 left after a
+	// withdrawal, so its payment goes and b pays 600 and 6.
+	if _, err := f.ledger.Transfer(f.ctx, f.a, f.c, ledger.Money{Currency: "KZT", Amount: 850}, ""); err != nil {
+		t.Fatal(err)
+	}
+	f.m.Close(f.ctx, cycle.ID)
+	if _, err := f.m.Settle(f.ctx, cycle.ID); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]int64{f.a: 750, f.b: 394, feeAccount.ID: 6, f.settle: 0} {
+		if got := f.balance(id); got != want {
+			t.Fatalf("balance of %s: expected %d, got %d", id, want, got)
+		}
+	}
+	rep, _ := f.m.Report(f.ctx, cycle.ID)
+	if !reflect.DeepEqual(rep.Currencies, []CurrencyTotal{{Currency: "KZT", Payments: 1, Gross: 600, Fees: 6, Net: 606}}) {
+		t.Fatalf("unexpected report: %+v", rep.Currencies)
+	}
+
+	// Both fees settle with the net positions.
+	again, _ := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle})
+	f.submit(t, again.ID, f.a, f.b, 300)
+	f.submit(t, again.ID, f.b, f.a, 100)
+	f.m.Close(f.ctx, again.ID)
+	if _, err := f.m.Settle(f.ctx, again.ID); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]int64{f.a: 547, f.b: 593, feeAccount.ID: 10, f.settle: 0} {
+		if got := f.balance(id); got != want {
+			t.Fatalf("balance of %s: expected %d, got %d", id, want, got)
+		}
+	}
+}
+
+func TestSettleScreensPayments(t *testing.T) {
+	f := newNetFixture(t, 1_000, 0, 0)
+	listed := f.c
+	f.m = NewManager(f.m.Store(), f.ledger, WithScreen(func(ctx context.Context, p Payment) (bool, error) {
+		return p.ToAccountID != listed, nil
+	}))
+	cycle, _ := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle})
+	f.submit(t, cycle.ID, f.a, f.b, 300)
+	f.submit(t, cycle.ID, f.a, f.c, 200)
+	f.m.Close(f.ctx, cycle.ID)
+	if _, err := f.m.Settle(f.ctx, cycle.ID); err != nil {
+		t.Fatal(err)
+	}
+	payments, _ := f.m.Store().ListPayments(f.ctx, cycle.ID)
+	if payments[0].Status != PaymentSettled || payments[1].Status != PaymentRemoved || payments[1].Reason != ReasonScreened {
+		t.Fatalf("expected the payment to the listed account removed, got %+v", payments)
+	}
+	if f.balance(f.a) != 700 || f.balance(f.c) != 0 {
+		t.Fatalf("unexpected balances: a %d, c %d", f.balance(f.a), f.balance(f.c))
+	}
+
+	// A screen that fails stops the settlement before anything is removed.
+	failing := NewManager(f.m.Store(), f.ledger, WithScreen(func(context.Context, Payment) (bool, error) {
+		return false, errors.New("screener down")
+	}))
+	again, _ := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle})
+	f.submit(t, again.ID, f.a, f.b, 100)
+	f.m.Close(f.ctx, again.ID)
+	if _, err := failing.Settle(f.ctx, again.ID); err == nil {
+		t.Fatal("expected the screener failure")
+	}
+	if c, _ := f.m.Store().GetCycle(f.ctx, again.ID); c.Status != CycleClosed {
+		t.Fatalf("expected the cycle to stay closed, got %s", c.Status)
+	}
+}
+
+// Limits apply to the settlement posting: a participant may submit more
+// than its limit as long as what it pays net stays within it.
+func TestSettleLimits(t *testing.T) {
+	f := newNetFixture(t, 1_000, 0, 0)
+	if _, err := f.ledger.SetLimit(f.ctx, ledger.Limit{Scope: ledger.LimitAccount, Subject: f.a, Currency: "KZT", MaxAmount: 300}); err != nil {
+		t.Fatal(err)
+	}
+	cycle, _ := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle})
+	f.submit(t, cycle.ID, f.a, f.b, 500)
+	f.submit(t, cycle.ID, f.b, f.a, 250)
+	f.m.Close(f.ctx, cycle.ID)
+	if _, err := f.m.Settle(f.ctx, cycle.ID); err != nil {
+		t.Fatal(err)
+	}
+	if f.balance(f.a) != 750 {
+		t.Fatalf("expected a to pay 250 net, has %d", f.balance(f.a))
+	}
+
+	over, _ := f.m.Open(f.ctx, Cycle{SettlementAccountID: f.settle})
+	f.submit(t, over.ID, f.a, f.b, 400)
+	f.m.Close(f.ctx, over.ID)
+	if _, err := f.m.Settle(f.ctx, over.ID); !errors.Is(err, ledger.ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+	if c, _ := f.m.Store().GetCycle(f.ctx, over.ID); c.Status != CycleClosed || f.balance(f.a) != 750 {
+		t.Fatalf("expected the cycle back to closed and nothing paid, got %s and %d", c.Status, f.balance(f.a))
+	}
+}
@@ -38,10 +38,23 @@ var (
 		Name: "qazna_ready",
 		Help: "Readiness state (1 when ready).",
 	})
+
+	outboxCursor = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "qazna_outbox_cursor",
+			Help: "Last outbox sequence delivered to each consumer.",
+		},
+		[]string{"consumer"},
+	)
+
+	ledgerDrift = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "qazna_ledger_drift",
+		Help: "Problems found by the last ledger reconciliation (0 when balances match the history).",
+	})
 )
 
 func Init() {
-	prometheus.MustRegister(httpInFlight, httpRequestsTotal, httpRequestDuration, readyGauge)
+	prometheus.MustRegister(httpInFlight, httpRequestsTotal, httpRequestDuration, readyGauge, outboxCursor, ledgerDrift)
 	readyGauge.Set(0)
 }
 
@@ -102,6 +115,16 @@ func SetReady(state bool) {
 	readyGauge.Set(0)
 }
 
+// SetOutboxCursor records the delivery progress of an outbox consumer.
+func SetOutboxCursor(consumer string, seq uint64) {
+	outboxCursor.WithLabelValues(consumer).Set(float64(seq))
+}
+
+// SetLedgerDrift records the outcome of a ledger reconciliation.
+func SetLedgerDrift(problems int) {
+	ledgerDrift.Set(float64(problems))
+}
+
 type statusWriter struct {
 	http.ResponseWriter
 	code int
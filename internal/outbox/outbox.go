@@ -0,0 +1,215 @@
+// Package outbox delivers committed ledger postings to sinks.
+//
+// The Postgres store writes one event per posting in the same database
+// transaction as the posting itself. A Dispatcher reads those events in
+// sequence order and hands them to each registered sink, recording a cursor
+// per sink after every successful delivery. A sink that fails, or a process
+// that dies between delivering and saving the cursor, sees the same events
+// again: delivery is at least once, and sinks should tolerate duplicates by
+// Sequence.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+// EventTransactionPosted is the type of the event written for every posting.
+const EventTransactionPosted = "ledger.transaction.posted"
+
+// Event is one outbox row.
+type Event struct {
+	Sequence    uint64             `json:"sequence"`
+	Type        string             `json:"type"`
+	Transaction ledger.Transaction `json:"transaction"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// Source is an outbox store.
+type Source interface {
+	// OutboxHorizon returns a sequence at or below which every event that
+	// will ever exist has committed, so cursors never skip an event that is
+	// still in flight.
+	OutboxHorizon(ctx context.Context) (uint64, error)
+	// ReadOutbox returns up to limit events with after < Sequence <= upTo in
+	// sequence order.
+	ReadOutbox(ctx context.Context, after, upTo uint64, limit int) ([]Event, error)
+	// OutboxCursor returns the last sequence delivered to consumer. ok is
+	// false for consumers that have no cursor yet.
+	OutboxCursor(ctx context.Context, consumer string) (seq uint64, ok bool, err error)
+	SaveOutboxCursor(ctx context.Context, consumer string, seq uint64) error
+}
+
+// Sink receives events in sequence order. Returning an error makes the
+// dispatcher retry the whole batch later.
+type Sink interface {
+	Deliver(ctx context.Context, events []Event) error
+}
+
+// SinkFunc adapts a function to Sink.
+type SinkFunc func(ctx context.Context, events []Event) error
+
+func (f SinkFunc) Deliver(ctx context.Context, events []Event) error { return f(ctx, events) }
+
+// Dispatcher polls a Source and feeds its sinks. Each sink advances on its
+// own cursor, so a failing sink does not hold back the others.
+type Dispatcher struct {
+	src      Source
+	interval time.Duration
+	batch    int
+	observe  func(consumer string, seq uint64)
+
+	mu    sync.Mutex
+	sinks []*consumer
+}
+
+type consumer struct {
+	name        string
+	sink        Sink
+	startAtHead bool
+	cursor      uint64
+	loaded      bool
+}
+
+// Option configures a Dispatcher.
+type Option func(*Dispatcher)
+
+// WithPollInterval sets how often the dispatcher looks for new events.
+// Defaults to 500ms.
+func WithPollInterval(d time.Duration) Option {
+	return func(dp *Dispatcher) { dp.interval = d }
+}
+
+// WithBatchSize bounds the events handed to a sink at once. Defaults to 500.
+func WithBatchSize(n int) Option {
+	return func(dp *Dispatcher) { dp.batch = n }
+}
+
+// WithCursorObserver is called whenever a consumer's cursor advances, e.g.
+// to export delivery progress as a metric.
+func WithCursorObserver(fn func(consumer string, seq uint64)) Option {
+	return func(dp *Dispatcher) { dp.observe = fn }
+}
+
+// SinkOption configures a sink registered with AddSink.
+type SinkOption func(*consumer)
+
+// StartAtHead makes a sink without a cursor start from the current horizon
+// instead of the oldest event. Use it for sinks that only care about live
+// events, such as the in-process stream.
+func StartAtHead() SinkOption {
+	return func(c *consumer) { c.startAtHead = true }
+}
+
+func NewDispatcher(src Source, opts ...Option) *Dispatcher {
+	d := &Dispatcher{src: src, interval: 500 * time.Millisecond, batch: 500}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// AddSink registers sink under name, which keys its cursor. Names must be
+// unique among everything reading the same outbox.
+func (d *Dispatcher) AddSink(name string, sink Sink, opts ...SinkOption) {
+	c := &consumer{name: name, sink: sink}
+	for _, opt := range opts {
+		opt(c)
+	}
+	d.mu.Lock()
+	d.sinks = append(d.sinks, c)
+	d.mu.Unlock()
+}
+
+// Run dispatches until ctx ends.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		if err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox dispatch: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch delivers everything up to the current horizon once. Errors of
+// individual sinks are joined; the remaining sinks are still served.
+func (d *Dispatcher) Dispatch(ctx context.Context) error {
+	horizon, err := d.src.OutboxHorizon(ctx)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	sinks := append([]*consumer(nil), d.sinks...)
+	d.mu.Unlock()
+
+	var errs []error
+	for _, c := range sinks {
+		if err := d.drain(ctx, c, horizon); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) drain(ctx context.Context, c *consumer, horizon uint64) error {
+	if !c.loaded {
+		seq, ok, err := d.src.OutboxCursor(ctx, c.name)
+		if err != nil {
+			return err
+		}
+		if !ok && c.startAtHead {
+			if err := d.src.SaveOutboxCursor(ctx, c.name, horizon); err != nil {
+				return err
+			}
+			seq = horizon
+		}
+		c.cursor, c.loaded = seq, true
+	}
+	for c.cursor < horizon {
+		events, err := d.src.ReadOutbox(ctx, c.cursor, horizon, d.batch)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			// Only sequences of rolled-back postings are left below the
+			// horizon; they will never show up.
+			if err := d.src.SaveOutboxCursor(ctx, c.name, horizon); err != nil {
+				return err
+			}
+			c.cursor = horizon
+			break
+		}
+		if err := c.sink.Deliver(ctx, events); err != nil {
+			return &SinkError{Consumer: c.name, Err: err}
+		}
+		last := events[len(events)-1].Sequence
+		if err := d.src.SaveOutboxCursor(ctx, c.name, last); err != nil {
+			return err
+		}
+		c.cursor = last
+		if d.observe != nil {
+			d.observe(c.name, last)
+		}
+	}
+	return nil
+}
+
+// SinkError reports a failed delivery.
+type SinkError struct {
+	Consumer string
+	Err      error
+}
+
+func (e *SinkError) Error() string { return "sink " + e.Consumer + ": " + e.Err.Error() }
+func (e *SinkError) Unwrap() error { return e.Err }
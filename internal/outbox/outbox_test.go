@@ -0,0 +1,256 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/stream"
+)
+
+// memSource is an outbox whose horizon is set by the test, standing in for
+// postings that hold a sequence but have not committed yet.
+type memSource struct {
+	mu      sync.Mutex
+	events  []Event
+	horizon uint64
+	cursors map[string]uint64
+}
+
+func newMemSource() *memSource {
+	return &memSource{cursors: make(map[string]uint64)}
+}
+
+func (m *memSource) add(seqs ...uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, seq := range seqs {
+		m.events = append(m.events, Event{
+			Sequence: seq,
+			Type:     EventTransactionPosted,
+			Transaction: ledger.Transaction{
+				Sequence:      seq,
+				FromAccountID: "a",
+				ToAccountID:   "b",
+				Currency:      "QZN",
+				Amount:        int64(seq),
+			},
+		})
+		m.horizon = max(m.horizon, seq)
+	}
+}
+
+func (m *memSource) OutboxHorizon(context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.horizon, nil
+}
+
+func (m *memSource) ReadOutbox(_ context.Context, after, upTo uint64, limit int) ([]Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Event
+	for _, e := range m.events {
+		if e.Sequence > after && e.Sequence <= upTo && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *memSource) OutboxCursor(_ context.Context, consumer string) (uint64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seq, ok := m.cursors[consumer]
+	return seq, ok, nil
+}
+
+func (m *memSource) SaveOutboxCursor(_ context.Context, consumer string, seq uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cursors[consumer] = seq
+	return nil
+}
+
+type recorder struct {
+	seqs    []uint64
+	batches int
+	fail    bool
+}
+
+func (r *recorder) Deliver(_ context.Context, events []Event) error {
+	if r.fail {
+		return errors.New("unavailable")
+	}
+	r.batches++
+	for _, e := range events {
+		r.seqs = append(r.seqs, e.Sequence)
+	}
+	return nil
+}
+
+func TestDispatcherDeliversInOrder(t *testing.T) {
+	ctx := context.Background()
+	src := newMemSource()
+	src.add(1, 2, 3, 4, 5)
+
+	var observed []uint64
+	d := NewDispatcher(src, WithBatchSize(2), WithCursorObserver(func(_ string, seq uint64) {
+		observed = append(observed, seq)
+	}))
+	r := &recorder{}
+	d.AddSink("r", r)
+
+	if err := d.Dispatch(ctx); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if want := []uint64{1, 2, 3, 4, 5}; !slices.Equal(r.seqs, want) {
+		t.Fatalf("delivered %v, want %v", r.seqs, want)
+	}
+	if r.batches != 3 {
+		t.Fatalf("expected 3 batches of at most 2, got %d", r.batches)
+	}
+	if want := []uint64{2, 4, 5}; !slices.Equal(observed, want) {
+		t.Fatalf("observed cursors %v, want %v", observed, want)
+	}
+
+	// Nothing new: nothing delivered again.
+	if err := d.Dispatch(ctx); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if len(r.seqs) != 5 {
+		t.Fatalf("events redelivered: %v", r.seqs)
+	}
+
+	// A new dispatcher resumes from the saved cursor.
+	src.add(6)
+	r2 := &recorder{}
+	d2 := NewDispatcher(src)
+	d2.AddSink("r", r2)
+	if err := d2.Dispatch(ctx); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if want := []uint64{6}; !slices.Equal(r2.seqs, want) {
+		t.Fatalf("resumed with %v, want %v", r2.seqs, want)
+	}
+}
+
+func TestDispatcherRetriesFailingSinkAlone(t *testing.T) {
+	ctx := context.Background()
+	src := newMemSource()
+	src.add(1, 2)
+
+	good, bad := &recorder{}, &recorder{fail: true}
+	d := NewDispatcher(src)
+	d.AddSink("bad", bad)
+	d.AddSink("good", good)
+
+	err := d.Dispatch(ctx)
+	var sinkErr *SinkError
+	if !errors.As(err, &sinkErr) || sinkErr.Consumer != "bad" {
+		t.Fatalf("expected sink error for bad, got %v", err)
+	}
+	if want := []uint64{1, 2}; !slices.Equal(good.seqs, want) {
+		t.Fatalf("healthy sink got %v, want %v", good.seqs, want)
+	}
+	if _, ok := src.cursors["bad"]; ok {
+		t.Fatalf("failed sink must not advance its cursor")
+	}
+
+	bad.fail = false
+	if err := d.Dispatch(ctx); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if want := []uint64{1, 2}; !slices.Equal(bad.seqs, want) {
+		t.Fatalf("retried sink got %v, want %v", bad.seqs, want)
+	}
+}
+
+func TestDispatcherStartAtHead(t *testing.T) {
+	ctx := context.Background()
+	src := newMemSource()
+	src.add(1, 2, 3)
+
+	r := &recorder{}
+	d := NewDispatcher(src)
+	d.AddSink("live", r, StartAtHead())
+	if err := d.Dispatch(ctx); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if len(r.seqs) != 0 {
+		t.Fatalf("expected no history, got %v", r.seqs)
+	}
+	src.add(4)
+	if err := d.Dispatch(ctx); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if want := []uint64{4}; !slices.Equal(r.seqs, want) {
+		t.Fatalf("delivered %v, want %v", r.seqs, want)
+	}
+}
+
+func TestDispatcherHonoursHorizon(t *testing.T) {
+	ctx := context.Background()
+	src := newMemSource()
+	src.add(1, 3)
+	// Sequence 2 is still in flight: 3 has committed but must wait.
+	src.horizon = 1
+
+	r := &recorder{}
+	d := NewDispatcher(src)
+	d.AddSink("r", r)
+	if err := d.Dispatch(ctx); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if want := []uint64{1}; !slices.Equal(r.seqs, want) {
+		t.Fatalf("delivered %v beyond the horizon", r.seqs)
+	}
+
+	// Sequence 2 rolled back: the gap is skipped once below the horizon.
+	src.horizon = 3
+	if err := d.Dispatch(ctx); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if want := []uint64{1, 3}; !slices.Equal(r.seqs, want) {
+		t.Fatalf("delivered %v, want %v", r.seqs, want)
+	}
+	if src.cursors["r"] != 3 {
+		t.Fatalf("cursor = %d, want 3", src.cursors["r"])
+	}
+}
+
+func TestStreamSink(t *testing.T) {
+	s := stream.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := s.Subscribe(ctx)
+
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	fx := ledger.Transaction{
+		CreatedAt: created,
+		Entries: []ledger.Entry{
+			{AccountID: "payer", Direction: ledger.Debit, Currency: "USD", Amount: 100},
+			{AccountID: "liq-usd", Direction: ledger.Credit, Currency: "USD", Amount: 100},
+			{AccountID: "liq-eur", Direction: ledger.Debit, Currency: "EUR", Amount: 90},
+			{AccountID: "payee", Direction: ledger.Credit, Currency: "EUR", Amount: 90},
+		},
+	}
+	if err := (StreamSink{Stream: s}).Deliver(ctx, []Event{{Sequence: 1, Transaction: fx}}); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	select {
+	case evt := <-ch:
+		if evt.From != s.LocationForID("payer") || evt.To != s.LocationForID("payee") {
+			t.Fatalf("unexpected flow %q -> %q", evt.From.Name, evt.To.Name)
+		}
+		if evt.Amount != 100 || evt.Currency != "USD" || !evt.Timestamp.Equal(created) {
+			t.Fatalf("unexpected event %+v", evt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event published")
+	}
+}
@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"context"
+
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/stream"
+)
+
+// StreamSink publishes postings to the live transfer map. Postings made of
+// entries are shown as a flow from their first debited to their last
+// credited account, which for an FX transfer is the payer and the payee, for
+// the debited total in the currency of the first debit.
+type StreamSink struct {
+	Stream *stream.Stream
+}
+
+func (s StreamSink) Deliver(_ context.Context, events []Event) error {
+	for _, evt := range events {
+		tx := evt.Transaction
+		from, to, currency, amount := tx.FromAccountID, tx.ToAccountID, tx.Currency, tx.Amount
+		if len(tx.Entries) > 0 {
+			from, to, currency, amount = "", "", "", 0
+			for _, e := range tx.Entries {
+				switch {
+				case e.Direction == ledger.Debit && from == "":
+					from, currency, amount = e.AccountID, e.Currency, e.Amount
+				case e.Direction == ledger.Debit && e.Currency == currency:
+					amount += e.Amount
+				case e.Direction == ledger.Credit:
+					to = e.AccountID
+				}
+			}
+		}
+		s.Stream.Publish(stream.TransferEvent{
+			From:      s.locate(from),
+			To:        s.locate(to),
+			Amount:    amount,
+			Currency:  currency,
+			Timestamp: tx.CreatedAt,
+		})
+	}
+	return nil
+}
+
+func (s StreamSink) locate(id string) stream.Location {
+	loc := s.Stream.LocationForID(id)
+	if loc.Name == "" {
+		loc.Name = id
+	}
+	return loc
+}
@@ -0,0 +1,220 @@
+// Package reconcile recomputes ledger balances from history and compares
+// them with the balances a store keeps.
+//
+// Stored balances are a cache of the transaction history: every balance must
+// equal the account's opening funding plus the legs posted to it since. And
+// because every posting balances per currency, the stored balances of a
+// currency must add up to the opening funding of that currency, whatever
+// moved between accounts.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+// Source is a ledger that can be reconciled.
+type Source interface {
+	// ReadBooks feeds c with the opening funding of every account, every
+	// transaction and every stored balance, all read from one consistent
+	// snapshot.
+	ReadBooks(ctx context.Context, c *Checker) error
+}
+
+// Run reconciles src.
+func Run(ctx context.Context, src Source) (Report, error) {
+	c := NewChecker()
+	if err := src.ReadBooks(ctx, c); err != nil {
+		return Report{}, err
+	}
+	return c.Report(), nil
+}
+
+// Drift is a stored balance that disagrees with the history.
+type Drift struct {
+	AccountID string `json:"account_id"`
+	Currency  string `json:"currency"`
+	Expected  int64  `json:"expected"` // opening funding plus posted legs
+	Stored    int64  `json:"stored"`
+	Diff      int64  `json:"diff"` // stored - expected
+}
+
+// Unbalanced is a transaction whose legs do not net to zero in a currency.
+type Unbalanced struct {
+	TransactionID string `json:"transaction_id"`
+	Sequence      uint64 `json:"sequence"`
+	Currency      string `json:"currency"`
+	Net           int64  `json:"net"` // credits - debits
+}
+
+// CurrencyTotal sums one currency over all accounts. Conservation holds when
+// Stored equals Opening.
+type CurrencyTotal struct {
+	Currency string `json:"currency"`
+	Opening  int64  `json:"opening"`
+	Expected int64  `json:"expected"`
+	Stored   int64  `json:"stored"`
+	Diff     int64  `json:"diff"` // stored - opening
+}
+
+// Report is the outcome of a reconciliation run.
+type Report struct {
+	CheckedAt    time.Time       `json:"checked_at"`
+	Sequence     uint64          `json:"sequence"` // last transaction replayed
+	Accounts     int             `json:"accounts"`
+	Transactions int             `json:"transactions"`
+	Currencies   []CurrencyTotal `json:"currencies"`
+	Drift        []Drift         `json:"drift,omitempty"`
+	Unbalanced   []Unbalanced    `json:"unbalanced,omitempty"`
+}
+
+// OK reports whether history and stored balances agree.
+func (r Report) OK() bool {
+	if len(r.Drift) > 0 || len(r.Unbalanced) > 0 {
+		return false
+	}
+	for _, c := range r.Currencies {
+		if c.Diff != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Problems counts drifting balances, unbalanced transactions and currencies
+// that are not conserved.
+func (r Report) Problems() int {
+	n := len(r.Drift) + len(r.Unbalanced)
+	for _, c := range r.Currencies {
+		if c.Diff != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+type key struct{ account, currency string }
+
+// Checker accumulates the books of a ledger. Feed it with Open, Post and
+// Stored in any order and read the result with Report. Posting the same
+// transaction twice is an error, as the store would count it twice too.
+type Checker struct {
+	opening  map[key]int64
+	expected map[key]int64
+	stored   map[key]int64
+	accounts map[string]struct{}
+	seen     map[string]struct{}
+	report   Report
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		opening:  make(map[key]int64),
+		expected: make(map[key]int64),
+		stored:   make(map[key]int64),
+		accounts: make(map[string]struct{}),
+		seen:     make(map[string]struct{}),
+	}
+}
+
+// Open records the funding accountID was created with.
+func (c *Checker) Open(accountID, currency string, amount int64) {
+	k := key{accountID, currency}
+	c.opening[k] += amount
+	c.expected[k] += amount
+	c.accounts[accountID] = struct{}{}
+}
+
+// Post replays the legs of tx.
+func (c *Checker) Post(tx ledger.Transaction) error {
+	if _, dup := c.seen[tx.ID]; dup {
+		return fmt.Errorf("transaction %s replayed twice", tx.ID)
+	}
+	c.seen[tx.ID] = struct{}{}
+
+	net := make(map[string]int64)
+	for _, e := range tx.Legs() {
+		d := e.Amount
+		if e.Direction == ledger.Debit {
+			d = -d
+		}
+		c.expected[key{e.AccountID, e.Currency}] += d
+		net[e.Currency] += d
+	}
+	for _, cur := range sortedKeys(net) {
+		if net[cur] != 0 {
+			c.report.Unbalanced = append(c.report.Unbalanced, Unbalanced{
+				TransactionID: tx.ID, Sequence: tx.Sequence, Currency: cur, Net: net[cur],
+			})
+		}
+	}
+	c.report.Transactions++
+	c.report.Sequence = max(c.report.Sequence, tx.Sequence)
+	return nil
+}
+
+// Stored records the balance the store keeps for accountID.
+func (c *Checker) Stored(accountID, currency string, amount int64) {
+	c.stored[key{accountID, currency}] += amount
+	c.accounts[accountID] = struct{}{}
+}
+
+// Report compares the replayed history with the stored balances. Drift and
+// currencies are ordered by account and currency.
+func (c *Checker) Report() Report {
+	r := c.report
+	r.CheckedAt = time.Now().UTC()
+	r.Accounts = len(c.accounts)
+	r.Drift = nil
+	r.Currencies = nil
+
+	keys := make(map[key]struct{}, len(c.expected))
+	for k := range c.expected {
+		keys[k] = struct{}{}
+	}
+	for k := range c.stored {
+		keys[k] = struct{}{}
+	}
+	totals := make(map[string]*CurrencyTotal)
+	for k := range keys {
+		t, ok := totals[k.currency]
+		if !ok {
+			t = &CurrencyTotal{Currency: k.currency}
+			totals[k.currency] = t
+		}
+		t.Opening += c.opening[k]
+		t.Expected += c.expected[k]
+		t.Stored += c.stored[k]
+		if exp, got := c.expected[k], c.stored[k]; exp != got {
+			r.Drift = append(r.Drift, Drift{
+				AccountID: k.account, Currency: k.currency,
+				Expected: exp, Stored: got, Diff: got - exp,
+			})
+		}
+	}
+	sort.Slice(r.Drift, func(i, j int) bool {
+		if r.Drift[i].AccountID != r.Drift[j].AccountID {
+			return r.Drift[i].AccountID < r.Drift[j].AccountID
+		}
+		return r.Drift[i].Currency < r.Drift[j].Currency
+	})
+	for _, cur := range sortedKeys(totals) {
+		t := totals[cur]
+		t.Diff = t.Stored - t.Opening
+		r.Currencies = append(r.Currencies, *t)
+	}
+	return r
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
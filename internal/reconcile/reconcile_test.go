@@ -0,0 +1,159 @@
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"qazna.org/internal/ledger"
+)
+
+type books struct {
+	openings []ledger.Entry
+	txs      []ledger.Transaction
+	stored   map[string]map[string]int64
+}
+
+func (b books) ReadBooks(_ context.Context, c *Checker) error {
+	for _, o := range b.openings {
+		c.Open(o.AccountID, o.Currency, o.Amount)
+	}
+	for _, tx := range b.txs {
+		if err := c.Post(tx); err != nil {
+			return err
+		}
+	}
+	for id, balances := range b.stored {
+		for cur, amt := range balances {
+			c.Stored(id, cur, amt)
+		}
+	}
+	return nil
+}
+
+// ledgerBooks reads the books of an in-memory ledger whose accounts were
+// opened with the given funding.
+func ledgerBooks(t *testing.T, s *ledger.InMemory, openings []ledger.Entry) books {
+	t.Helper()
+	ctx := context.Background()
+	b := books{openings: openings, stored: make(map[string]map[string]int64)}
+	txs, _, err := s.ListTransactions(ctx, 1000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.txs = txs
+	for _, o := range openings {
+		acc, err := s.GetAccount(ctx, o.AccountID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.stored[acc.ID] = acc.Balances
+	}
+	return b
+}
+
+func TestRunAgreesWithLedger(t *testing.T) {
+	ctx := context.Background()
+	s := ledger.NewInMemory()
+	a, _ := s.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 1000})
+	b, _ := s.CreateAccount(ctx, ledger.Money{Currency: "QZN", Amount: 0})
+	c, _ := s.CreateAccount(ctx, ledger.Money{Currency: "USD", Amount: 500})
+	if _, err := s.Transfer(ctx, a.ID, b.ID, ledger.Money{Currency: "QZN", Amount: 300}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PostEntries(ctx, []ledger.Entry{
+		{AccountID: b.ID, Direction: ledger.Debit, Currency: "QZN", Amount: 100},
+		{AccountID: c.ID, Direction: ledger.Credit, Currency: "QZN", Amount: 100},
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	openings := []ledger.Entry{
+		{AccountID: a.ID, Currency: "QZN", Amount: 1000},
+		{AccountID: b.ID, Currency: "QZN", Amount: 0},
+		{AccountID: c.ID, Currency: "USD", Amount: 500},
+	}
+	report, err := Run(ctx, ledgerBooks(t, s, openings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || report.Problems() != 0 {
+		t.Fatalf("expected a clean report, got %+v", report)
+	}
+	if report.Accounts != 3 || report.Transactions != 2 || report.Sequence != 2 {
+		t.Fatalf("unexpected counts: %+v", report)
+	}
+	want := []CurrencyTotal{
+		{Currency: "QZN", Opening: 1000, Expected: 1000, Stored: 1000},
+		{Currency: "USD", Opening: 500, Expected: 500, Stored: 500},
+	}
+	if len(report.Currencies) != len(want) {
+		t.Fatalf("unexpected currencies: %+v", report.Currencies)
+	}
+	for i := range want {
+		if report.Currencies[i] != want[i] {
+			t.Fatalf("currency %d = %+v, want %+v", i, report.Currencies[i], want[i])
+		}
+	}
+}
+
+func TestRunReportsDrift(t *testing.T) {
+	b := books{
+		openings: []ledger.Entry{
+			{AccountID: "a", Currency: "QZN", Amount: 100},
+			{AccountID: "b", Currency: "QZN", Amount: 0},
+		},
+		txs: []ledger.Transaction{
+			{ID: "t1", Sequence: 1, FromAccountID: "a", ToAccountID: "b", Currency: "QZN", Amount: 40},
+			{ID: "t2", Sequence: 3, Entries: []ledger.Entry{
+				{AccountID: "a", Direction: ledger.Debit, Currency: "QZN", Amount: 10},
+				{AccountID: "b", Direction: ledger.Credit, Currency: "QZN", Amount: 7},
+			}},
+		},
+		stored: map[string]map[string]int64{
+			"a": {"QZN": 50},
+			"b": {"QZN": 49},
+			"c": {"EUR": 5},
+		},
+	}
+	report, err := Run(context.Background(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() {
+		t.Fatal("expected drift")
+	}
+	wantDrift := []Drift{
+		{AccountID: "b", Currency: "QZN", Expected: 47, Stored: 49, Diff: 2},
+		{AccountID: "c", Currency: "EUR", Expected: 0, Stored: 5, Diff: 5},
+	}
+	if len(report.Drift) != len(wantDrift) {
+		t.Fatalf("unexpected drift: %+v", report.Drift)
+	}
+	for i := range wantDrift {
+		if report.Drift[i] != wantDrift[i] {
+			t.Fatalf("drift %d = %+v, want %+v", i, report.Drift[i], wantDrift[i])
+		}
+	}
+	if len(report.Unbalanced) != 1 || report.Unbalanced[0] != (Unbalanced{TransactionID: "t2", Sequence: 3, Currency: "QZN", Net: -3}) {
+		t.Fatalf("unexpected unbalanced: %+v", report.Unbalanced)
+	}
+	if report.Currencies[0].Currency != "EUR" || report.Currencies[0].Diff != 5 ||
+		report.Currencies[1].Currency != "QZN" || report.Currencies[1].Diff != -1 {
+		t.Fatalf("unexpected conservation totals: %+v", report.Currencies)
+	}
+	// Two drifting balances, one unbalanced transaction, two currencies.
+	if report.Problems() != 5 {
+		t.Fatalf("Problems() = %d, want 5", report.Problems())
+	}
+}
+
+func TestCheckerRejectsDuplicateTransaction(t *testing.T) {
+	c := NewChecker()
+	tx := ledger.Transaction{ID: "t1", Sequence: 1, FromAccountID: "a", ToAccountID: "b", Currency: "QZN", Amount: 1}
+	if err := c.Post(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Post(tx); err == nil {
+		t.Fatal("expected an error for a transaction posted twice")
+	}
+}
@@ -0,0 +1,86 @@
+package screening
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"qazna.org/internal/ids"
+)
+
+// MemoryQueue keeps reviews in process memory. It is meant for development
+// and tests; pending reviews are lost on restart.
+type MemoryQueue struct {
+	mu      sync.Mutex
+	reviews []Review
+	byID    map[string]int
+	byKey   map[string]int
+}
+
+var _ ReviewQueue = (*MemoryQueue)(nil)
+
+// NewMemoryQueue creates an empty queue.
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{byID: make(map[string]int), byKey: make(map[string]int)}
+}
+
+func (q *MemoryQueue) CreateReview(_ context.Context, rv Review) (Review, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	key := rv.Transfer.IdempotencyKey
+	if i, ok := q.byKey[key]; ok && key != "" {
+		return q.reviews[i], nil
+	}
+	rv.ID = ids.New()
+	rv.Status = ReviewPending
+	rv.CreatedAt = time.Now().UTC()
+	rv.DecidedBy, rv.DecidedAt, rv.Note, rv.TransactionID = "", nil, "", ""
+	q.reviews = append(q.reviews, rv)
+	q.byID[rv.ID] = len(q.reviews) - 1
+	if key != "" {
+		q.byKey[key] = len(q.reviews) - 1
+	}
+	return rv, nil
+}
+
+func (q *MemoryQueue) GetReview(_ context.Context, id string) (Review, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i, ok := q.byID[id]
+	if !ok {
+		return Review{}, ErrNotFound
+	}
+	return q.reviews[i], nil
+}
+
+func (q *MemoryQueue) ListReviews(_ context.Context, status ReviewStatus) ([]Review, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var out []Review
+	for _, rv := range q.reviews {
+		if status == "" || rv.Status == status {
+			out = append(out, rv)
+		}
+	}
+	return out, nil
+}
+
+func (q *MemoryQueue) DecideReview(_ context.Context, id string, d ReviewDecision) (Review, error) {
+	if err := d.Validate(); err != nil {
+		return Review{}, err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i, ok := q.byID[id]
+	if !ok {
+		return Review{}, ErrNotFound
+	}
+	rv := &q.reviews[i]
+	if rv.Status != ReviewPending {
+		return Review{}, ErrReviewDecided
+	}
+	now := time.Now().UTC()
+	rv.Status, rv.DecidedBy, rv.DecidedAt = d.Status, d.By, &now
+	rv.Note, rv.TransactionID = d.Note, d.TransactionID
+	return *rv, nil
+}
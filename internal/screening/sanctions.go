@@ -0,0 +1,286 @@
+package screening
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SanctionsEntry is one listed person or organization.
+type SanctionsEntry struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	Program string   `json:"program,omitempty"`
+}
+
+// LoadSanctionsList reads a JSON array of entries from path.
+func LoadSanctionsList(path string) ([]SanctionsEntry, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []SanctionsEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("sanctions list %s: %w", path, err)
+	}
+	for i, e := range entries {
+		if strings.TrimSpace(e.ID) == "" || strings.TrimSpace(e.Name) == "" {
+			return nil, fmt.Errorf("sanctions list %s: entry %d needs an id and a name", path, i)
+		}
+	}
+	return entries, nil
+}
+
+// Default score thresholds of a SanctionsScreener.
+const (
+	DefaultHoldScore   = 0.85
+	DefaultRejectScore = 0.98
+)
+
+// metadataNameKeys are the organization metadata keys screened besides the
+// organization name. Values may be a string or a list of strings.
+var metadataNameKeys = []string{"legal_name", "trade_name", "former_names", "aliases", "directors", "beneficial_owners"}
+
+// legalForms are dropped from names before matching, so "Acme LLC" and
+// "ACME Ltd." compare as equal.
+var legalForms = map[string]bool{
+	"llc": true, "llp": true, "ltd": true, "limited": true, "inc": true, "corp": true, "corporation": true,
+	"co": true, "company": true, "plc": true, "gmbh": true, "ag": true, "sa": true, "jsc": true,
+	"ojsc": true, "pjsc": true, "cjsc": true, "too": true, "ao": true, "oao": true, "zao": true, "ooo": true,
+	"the": true, "of": true, "and": true,
+}
+
+// SanctionsScreener matches the organization names and name-like metadata of
+// both parties against a sanctions list. Names are compared after folding
+// case, punctuation and legal forms, word by word with Jaro-Winkler
+// similarity, so reordered, abbreviated or misspelt names still score high.
+// A best score of at least the reject threshold rejects the transfer, one of
+// at least the hold threshold holds it for review.
+type SanctionsScreener struct {
+	entries     []listedName
+	holdScore   float64
+	rejectScore float64
+}
+
+type listedName struct {
+	entry  SanctionsEntry
+	name   string
+	tokens []string
+}
+
+// SanctionsOption configures NewSanctionsScreener.
+type SanctionsOption func(*SanctionsScreener)
+
+// WithScoreThresholds sets the scores, between 0 and 1, from which a match
+// holds or rejects a transfer.
+func WithScoreThresholds(hold, reject float64) SanctionsOption {
+	return func(s *SanctionsScreener) {
+		s.holdScore, s.rejectScore = hold, reject
+	}
+}
+
+var _ Screener = (*SanctionsScreener)(nil)
+
+// NewSanctionsScreener builds a screener over entries.
+func NewSanctionsScreener(entries []SanctionsEntry, opts ...SanctionsOption) (*SanctionsScreener, error) {
+	s := &SanctionsScreener{holdScore: DefaultHoldScore, rejectScore: DefaultRejectScore}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.holdScore <= 0 || s.holdScore > s.rejectScore || s.rejectScore > 1 {
+		return nil, errors.New("sanctions thresholds must satisfy 0 < hold <= reject <= 1")
+	}
+	for _, e := range entries {
+		for _, name := range append([]string{e.Name}, e.Aliases...) {
+			if tokens := nameTokens(name); len(tokens) > 0 {
+				s.entries = append(s.entries, listedName{entry: e, name: name, tokens: tokens})
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *SanctionsScreener) Screen(_ context.Context, req Request) (Result, error) {
+	var matches []Match
+	for _, side := range []struct {
+		role  string
+		party Party
+	}{{"payer", req.Payer}, {"payee", req.Payee}} {
+		for _, c := range partyNames(side.party) {
+			tokens := nameTokens(c.name)
+			if len(tokens) == 0 {
+				continue
+			}
+			// Keep the best scoring name of each entry.
+			best := make(map[string]Match)
+			for _, l := range s.entries {
+				score := nameScore(tokens, l.tokens)
+				if score < s.holdScore || score <= best[l.entry.ID].Score {
+					continue
+				}
+				best[l.entry.ID] = Match{
+					Party:     side.role,
+					Field:     c.field,
+					Name:      c.name,
+					EntryID:   l.entry.ID,
+					EntryName: l.name,
+					Program:   l.entry.Program,
+					Score:     math.Round(score*1000) / 1000,
+				}
+			}
+			for _, m := range best {
+				matches = append(matches, m)
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return Result{Decision: Allow}, nil
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].Party != matches[j].Party {
+			return matches[i].Party < matches[j].Party
+		}
+		return matches[i].EntryID < matches[j].EntryID
+	})
+	top := matches[0]
+	decision := Hold
+	if top.Score >= s.rejectScore {
+		decision = Reject
+	}
+	return Result{
+		Decision: decision,
+		Reason:   fmt.Sprintf("%s %s %q matches sanctions entry %s %q (score %.3f)", top.Party, top.Field, top.Name, top.EntryID, top.EntryName, top.Score),
+		Matches:  matches,
+	}, nil
+}
+
+type candidateName struct {
+	field string
+	name  string
+}
+
+// partyNames lists the names screened for p: the organization name and the
+// name-like metadata values.
+func partyNames(p Party) []candidateName {
+	var out []candidateName
+	if p.OrganizationName != "" {
+		out = append(out, candidateName{"name", p.OrganizationName})
+	}
+	for _, key := range metadataNameKeys {
+		switch v := p.Metadata[key].(type) {
+		case string:
+			out = append(out, candidateName{key, v})
+		case []string:
+			for _, s := range v {
+				out = append(out, candidateName{key, s})
+			}
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					out = append(out, candidateName{key, s})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// nameTokens lower-cases name, splits it into words on anything but letters
+// and digits and drops legal forms.
+func nameTokens(name string) []string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := make([]string, 0, len(words))
+	for _, w := range words {
+		if !legalForms[w] {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+// nameScore compares two tokenized names. Each word is paired with its most
+// similar word in the other name and the averages are taken both ways; the
+// lower one counts, so a name does not match a longer one it only starts.
+// Names of similar length are also compared with the words run together, to
+// catch differences in spacing.
+func nameScore(a, b []string) float64 {
+	score := math.Min(bestPairs(a, b), bestPairs(b, a))
+	x, y := strings.Join(a, ""), strings.Join(b, "")
+	lx, ly := len([]rune(x)), len([]rune(y))
+	if float64(min(lx, ly)) >= 0.8*float64(max(lx, ly)) {
+		score = math.Max(score, jaroWinkler(x, y))
+	}
+	return score
+}
+
+func bestPairs(a, b []string) float64 {
+	var sum float64
+	for _, x := range a {
+		var best float64
+		for _, y := range b {
+			best = math.Max(best, jaroWinkler(x, y))
+		}
+		sum += best
+	}
+	return sum / float64(len(a))
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of a and b, from 0 for
+// nothing in common to 1 for equal strings.
+func jaroWinkler(a, b string) float64 {
+	s, t := []rune(a), []rune(b)
+	if len(s) == 0 || len(t) == 0 {
+		return 0
+	}
+	window := max(len(s), len(t))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	sm, tm := make([]bool, len(s)), make([]bool, len(t))
+	matches := 0
+	for i := range s {
+		lo, hi := max(0, i-window), min(len(t), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !tm[j] && s[i] == t[j] {
+				sm[i], tm[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+	transpositions, j := 0, 0
+	for i := range s {
+		if !sm[i] {
+			continue
+		}
+		for !tm[j] {
+			j++
+		}
+		if s[i] != t[j] {
+			transpositions++
+		}
+		j++
+	}
+	m := float64(matches)
+	jaro := (m/float64(len(s)) + m/float64(len(t)) + (m-float64(transpositions)/2)/m) / 3
+	prefix := 0
+	for prefix < min(4, len(s), len(t)) && s[prefix] == t[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,144 @@
+// Package screening checks the parties of a transfer against compliance
+// rules before it is committed.
+//
+// A Screener decides whether a transfer may go ahead, must be rejected, or
+// must wait for a compliance officer. Held transfers are parked in a
+// ReviewQueue and committed only when a reviewer approves them.
+package screening
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+var (
+	ErrNotFound        = errors.New("review not found")
+	ErrReviewDecided   = errors.New("review already decided")
+	ErrInvalidDecision = errors.New("invalid review decision")
+)
+
+// Decision is the outcome of screening a transfer.
+type Decision string
+
+const (
+	Allow  Decision = "allow"
+	Reject Decision = "reject"
+	Hold   Decision = "hold"
+)
+
+// Party is one side of a screened transfer: the account and, when it has
+// one, the organization owning it with its name and metadata.
+type Party struct {
+	AccountID        string         `json:"account_id"`
+	OrganizationID   string         `json:"organization_id,omitempty"`
+	OrganizationName string         `json:"organization_name,omitempty"`
+	Metadata         map[string]any `json:"metadata,omitempty"`
+}
+
+// Request is a transfer about to be committed.
+type Request struct {
+	Payer  Party        `json:"payer"`
+	Payee  Party        `json:"payee"`
+	Amount ledger.Money `json:"amount"`
+}
+
+// Match is a name of a party that resembles a listed one.
+type Match struct {
+	Party     string  `json:"party"` // payer or payee
+	Field     string  `json:"field"` // name, or the metadata key the name came from
+	Name      string  `json:"name"`
+	EntryID   string  `json:"entry_id"`
+	EntryName string  `json:"entry_name"`
+	Program   string  `json:"program,omitempty"`
+	Score     float64 `json:"score"`
+}
+
+// Result is a screening decision and what it was based on.
+type Result struct {
+	Decision Decision `json:"decision"`
+	Reason   string   `json:"reason,omitempty"`
+	Matches  []Match  `json:"matches,omitempty"`
+}
+
+// Screener screens transfers before they are committed.
+type Screener interface {
+	Screen(ctx context.Context, req Request) (Result, error)
+}
+
+// ReviewStatus tracks a held transfer through review.
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+// Transfer is the transfer a review holds back.
+type Transfer struct {
+	FromAccountID  string `json:"from_account_id"`
+	ToAccountID    string `json:"to_account_id"`
+	Currency       string `json:"currency"`
+	Amount         int64  `json:"amount"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// Review is a held transfer awaiting, or decided by, a compliance officer.
+// An approved review records the transaction its transfer committed as.
+type Review struct {
+	ID             string       `json:"id"`
+	Status         ReviewStatus `json:"status"`
+	Transfer       Transfer     `json:"transfer"`
+	Result         Result       `json:"result"`
+	RequestedBy    string       `json:"requested_by,omitempty"`
+	RequestedByOrg string       `json:"requested_by_org,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	DecidedBy      string       `json:"decided_by,omitempty"`
+	DecidedAt      *time.Time   `json:"decided_at,omitempty"`
+	Note           string       `json:"note,omitempty"`
+	TransactionID  string       `json:"transaction_id,omitempty"`
+}
+
+// ReviewDecision resolves a pending review.
+type ReviewDecision struct {
+	Status        ReviewStatus
+	By            string
+	Note          string
+	TransactionID string
+}
+
+// Validate checks that d approves or rejects, and that only an approval
+// names a transaction.
+func (d ReviewDecision) Validate() error {
+	switch d.Status {
+	case ReviewApproved:
+	case ReviewRejected:
+		if d.TransactionID != "" {
+			return ErrInvalidDecision
+		}
+	default:
+		return ErrInvalidDecision
+	}
+	if len(d.Note) > 1024 {
+		return ErrInvalidDecision
+	}
+	return nil
+}
+
+// ReviewQueue stores held transfers.
+type ReviewQueue interface {
+	// CreateReview queues rv as pending. A review already queued with the
+	// same non-empty idempotency key is returned instead, whatever its
+	// status, so a retried transfer is not held twice.
+	CreateReview(ctx context.Context, rv Review) (Review, error)
+	GetReview(ctx context.Context, id string) (Review, error)
+	// ListReviews lists reviews oldest first, narrowed to status when it is
+	// set.
+	ListReviews(ctx context.Context, status ReviewStatus) ([]Review, error)
+	// DecideReview resolves a pending review, failing with ErrReviewDecided
+	// once it has been resolved.
+	DecideReview(ctx context.Context, id string, d ReviewDecision) (Review, error)
+}
@@ -0,0 +1,114 @@
+package screening
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"qazna.org/internal/ledger"
+)
+
+func TestSanctionsScreener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sanctions.json")
+	list := `[
+		{"id": "SL-1", "name": "Borealis Shipping Trading LLC", "aliases": ["Borealis Marine"], "program": "UN-1718"},
+		{"id": "SL-2", "name": "Ivanov Sergei Petrovich", "program": "EU-833"}
+	]`
+	if err := os.WriteFile(path, []byte(list), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := LoadSanctionsList(path)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("unexpected list: %+v %v", entries, err)
+	}
+	s, err := NewSanctionsScreener(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	screen := func(payee Party) Result {
+		t.Helper()
+		res, err := s.Screen(context.Background(), Request{
+			Payer:  Party{AccountID: "a1", OrganizationName: "Steppe Grain Cooperative"},
+			Payee:  payee,
+			Amount: ledger.Money{Currency: "KZT", Amount: 100},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	for _, tc := range []struct {
+		name  string
+		payee Party
+		want  Decision
+		field string
+		entry string
+	}{
+		{"unrelated", Party{OrganizationName: "Almaty Bakery"}, Allow, "", ""},
+		{"exact name with another legal form", Party{OrganizationName: "BOREALIS SHIPPING TRADING LTD."}, Reject, "name", "SL-1"},
+		{"alias", Party{OrganizationName: "Borealis Marine"}, Reject, "name", "SL-1"},
+		{"misspelt name", Party{OrganizationName: "Borealys Shiping Trading"}, Hold, "name", "SL-1"},
+		{"reordered person in metadata", Party{OrganizationName: "Caspian Logistics", Metadata: map[string]any{"beneficial_owners": []any{"Sergei Ivanov"}}}, Hold, "beneficial_owners", "SL-2"},
+		{"prefix of a listed name", Party{OrganizationName: "Borealis"}, Allow, "", ""},
+	} {
+		res := screen(tc.payee)
+		if res.Decision != tc.want {
+			t.Fatalf("%s: expected %s, got %+v", tc.name, tc.want, res)
+		}
+		if tc.want == Allow {
+			continue
+		}
+		if m := res.Matches[0]; m.Party != "payee" || m.Field != tc.field || m.EntryID != tc.entry || res.Reason == "" {
+			t.Fatalf("%s: unexpected match: %+v", tc.name, res)
+		}
+	}
+
+	if _, err := NewSanctionsScreener(entries, WithScoreThresholds(0.9, 0.8)); err == nil {
+		t.Fatal("expected a hold threshold above the reject threshold to be refused")
+	}
+	if err := os.WriteFile(path, []byte(`[{"id": "SL-3"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSanctionsList(path); err == nil {
+		t.Fatal("expected an entry without a name to be refused")
+	}
+}
+
+func TestMemoryQueue(t *testing.T) {
+	ctx := context.Background()
+	q := NewMemoryQueue()
+	held := Result{Decision: Hold, Reason: "payee name matches"}
+	a, err := q.CreateReview(ctx, Review{Transfer: Transfer{FromAccountID: "a1", ToAccountID: "a2", Currency: "KZT", Amount: 5, IdempotencyKey: "k1"}, Result: held})
+	if err != nil || a.ID == "" || a.Status != ReviewPending {
+		t.Fatalf("unexpected review: %+v %v", a, err)
+	}
+	if again, _ := q.CreateReview(ctx, Review{Transfer: Transfer{IdempotencyKey: "k1"}}); again.ID != a.ID {
+		t.Fatalf("expected the retried transfer to reuse its review, got %+v", again)
+	}
+	b, _ := q.CreateReview(ctx, Review{Transfer: Transfer{FromAccountID: "a1", ToAccountID: "a3", Currency: "KZT", Amount: 7}, Result: held})
+
+	if _, err := q.DecideReview(ctx, a.ID, ReviewDecision{Status: ReviewPending}); !errors.Is(err, ErrInvalidDecision) {
+		t.Fatalf("expected pending to be refused as a decision, got %v", err)
+	}
+	if _, err := q.DecideReview(ctx, a.ID, ReviewDecision{Status: ReviewRejected, TransactionID: "tx"}); !errors.Is(err, ErrInvalidDecision) {
+		t.Fatalf("expected a rejection naming a transaction to be refused, got %v", err)
+	}
+	rv, err := q.DecideReview(ctx, a.ID, ReviewDecision{Status: ReviewApproved, By: "officer", Note: "false positive", TransactionID: "tx-1"})
+	if err != nil || rv.Status != ReviewApproved || rv.DecidedAt == nil || rv.TransactionID != "tx-1" {
+		t.Fatalf("unexpected decision: %+v %v", rv, err)
+	}
+	if _, err := q.DecideReview(ctx, a.ID, ReviewDecision{Status: ReviewRejected}); !errors.Is(err, ErrReviewDecided) {
+		t.Fatalf("expected a second decision to be refused, got %v", err)
+	}
+	if _, err := q.GetReview(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	pending, _ := q.ListReviews(ctx, ReviewPending)
+	all, _ := q.ListReviews(ctx, "")
+	if len(pending) != 1 || pending[0].ID != b.ID || len(all) != 2 || all[0].ID != a.ID {
+		t.Fatalf("unexpected reviews: pending %+v, all %+v", pending, all)
+	}
+}
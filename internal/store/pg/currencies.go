@@ -0,0 +1,122 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+const currencyColumns = `code, name, exponent, enabled, min_amount, max_amount, updated_at`
+
+func (s *Store) ListCurrencies(ctx context.Context) ([]ledger.Currency, error) {
+	registered, err := registeredCurrencies(ctx, s.db, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ledger.MergeCurrencies(registered), nil
+}
+
+func (s *Store) GetCurrency(ctx context.Context, code string) (ledger.Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	registered, err := registeredCurrencies(ctx, s.db, []string{code})
+	if err != nil {
+		return ledger.Currency{}, err
+	}
+	c, err := ledger.ResolveCurrency(registered, code)
+	if err != nil {
+		return ledger.Currency{}, ledger.ErrNotFound
+	}
+	return c, nil
+}
+
+func (s *Store) PutCurrency(ctx context.Context, c ledger.Currency) (ledger.Currency, error) {
+	c, err := ledger.NormalizeCurrency(c, time.Now())
+	if err != nil {
+		return ledger.Currency{}, err
+	}
+	if err := s.db.QueryRowContext(ctx, `
+		insert into currencies(code, name, exponent, enabled, min_amount, max_amount)
+		values ($1,$2,$3,$4,$5,$6)
+		on conflict (code) do update
+		set name = excluded.name, exponent = excluded.exponent, enabled = excluded.enabled,
+		    min_amount = excluded.min_amount, max_amount = excluded.max_amount, updated_at = now()
+		returning updated_at
+	`, c.Code, c.Name, c.Exponent, c.Enabled, c.MinAmount, c.MaxAmount).Scan(&c.UpdatedAt); err != nil {
+		return ledger.Currency{}, err
+	}
+	c.UpdatedAt = c.UpdatedAt.UTC()
+	return c, nil
+}
+
+// registeredCurrencies loads the registered definitions of codes, or of all
+// currencies when codes is nil.
+func registeredCurrencies(ctx context.Context, q queryer, codes []string) (map[string]ledger.Currency, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if codes == nil {
+		rows, err = q.QueryContext(ctx, `select `+currencyColumns+` from currencies`)
+	} else {
+		rows, err = q.QueryContext(ctx, `select `+currencyColumns+` from currencies where code = any($1)`, codes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]ledger.Currency)
+	for rows.Next() {
+		var c ledger.Currency
+		if err := rows.Scan(&c.Code, &c.Name, &c.Exponent, &c.Enabled, &c.MinAmount, &c.MaxAmount, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		c.Kind = ledger.CurrencyKind(c.Code)
+		c.UpdatedAt = c.UpdatedAt.UTC()
+		out[c.Code] = c
+	}
+	return out, rows.Err()
+}
+
+// resolveCurrency returns the registered or built-in definition of code
+// inside tx, failing with ErrInvalidCurrency for unknown codes.
+func resolveCurrency(ctx context.Context, tx *sql.Tx, code string) (ledger.Currency, error) {
+	registered, err := registeredCurrencies(ctx, tx, []string{code})
+	if err != nil {
+		return ledger.Currency{}, err
+	}
+	return ledger.ResolveCurrency(registered, code)
+}
+
+// checkAmount validates the currency and size of amt inside tx.
+func checkAmount(ctx context.Context, tx *sql.Tx, amt ledger.Money) error {
+	c, err := resolveCurrency(ctx, tx, amt.Currency)
+	if err != nil {
+		return err
+	}
+	return c.Check(amt.Amount)
+}
+
+// checkLegs validates the currency and amount of every leg inside tx.
+func checkLegs(ctx context.Context, tx *sql.Tx, legs []ledger.Entry) error {
+	codes := make([]string, 0, len(legs))
+	for _, e := range legs {
+		codes = append(codes, e.Currency)
+	}
+	registered, err := registeredCurrencies(ctx, tx, codes)
+	if err != nil {
+		return err
+	}
+	for _, e := range legs {
+		c, err := ledger.ResolveCurrency(registered, e.Currency)
+		if err != nil {
+			return err
+		}
+		if err := c.Check(e.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
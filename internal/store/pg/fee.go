@@ -0,0 +1,156 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+var _ ledger.FeeEngine = (*Store)(nil)
+
+const feeScheduleColumns = `version, rates, alpha::text, beta::text, rounding, account_id, effective_from, created_at`
+
+// AddFeeSchedule numbers the schedule under a table lock so concurrent
+// additions get consecutive versions.
+func (s *Store) AddFeeSchedule(ctx context.Context, fs ledger.FeeSchedule) (ledger.FeeSchedule, error) {
+	fs, err := ledger.NormalizeFeeSchedule(fs, time.Now().UTC())
+	if err != nil {
+		return ledger.FeeSchedule{}, err
+	}
+	rates, err := json.Marshal(fs.Rates)
+	if err != nil {
+		return ledger.FeeSchedule{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ledger.FeeSchedule{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var typ ledger.AccountType
+	err = tx.QueryRowContext(ctx, `select type from accounts where id=$1`, fs.AccountID).Scan(&typ)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ledger.FeeSchedule{}, ledger.ErrNotFound
+	}
+	if err != nil {
+		return ledger.FeeSchedule{}, err
+	}
+	if typ != ledger.AccountTypeFee {
+		return ledger.FeeSchedule{}, ledger.ErrInvalidAccountType
+	}
+	if _, err := tx.ExecContext(ctx, `lock table fee_schedules in exclusive mode`); err != nil {
+		return ledger.FeeSchedule{}, err
+	}
+	if err := tx.QueryRowContext(ctx, `
+		insert into fee_schedules(version, rates, alpha, beta, rounding, account_id, effective_from)
+		select coalesce(max(version),0)+1, $1, $2::numeric, $3::numeric, $4, $5, $6 from fee_schedules
+		returning version, created_at
+	`, rates, fs.Alpha, fs.Beta, string(fs.Rounding), fs.AccountID, fs.EffectiveFrom).Scan(&fs.Version, &fs.CreatedAt); err != nil {
+		return ledger.FeeSchedule{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.FeeSchedule{}, err
+	}
+	fs.CreatedAt = fs.CreatedAt.UTC()
+	return fs, nil
+}
+
+func (s *Store) FeeSchedules(ctx context.Context) ([]ledger.FeeSchedule, error) {
+	return feeSchedules(ctx, s.db)
+}
+
+func feeSchedules(ctx context.Context, q queryer) ([]ledger.FeeSchedule, error) {
+	rows, err := q.QueryContext(ctx, `select `+feeScheduleColumns+` from fee_schedules order by version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []ledger.FeeSchedule
+	for rows.Next() {
+		var (
+			fs    ledger.FeeSchedule
+			rates []byte
+		)
+		if err := rows.Scan(&fs.Version, &rates, &fs.Alpha, &fs.Beta, &fs.Rounding, &fs.AccountID, &fs.EffectiveFrom, &fs.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(rates, &fs.Rates); err != nil {
+			return nil, err
+		}
+		fs.Alpha, fs.Beta = normalizeRate(fs.Alpha), normalizeRate(fs.Beta)
+		fs.EffectiveFrom, fs.CreatedAt = fs.EffectiveFrom.UTC(), fs.CreatedAt.UTC()
+		res = append(res, fs)
+	}
+	return res, rows.Err()
+}
+
+// TransferWithFee picks the schedule inside the transaction, so the fee and
+// the transfer commit against the same version.
+func (s *Store) TransferWithFee(ctx context.Context, fromID, toID string, amt ledger.Money, participant, idemKey string) (ledger.Transaction, error) {
+	return retrySerialization(ctx, func() (ledger.Transaction, error) {
+		return s.transferWithFee(ctx, fromID, toID, amt, participant, idemKey)
+	})
+}
+
+func (s *Store) transferWithFee(ctx context.Context, fromID, toID string, amt ledger.Money, participant, idemKey string) (ledger.Transaction, error) {
+	if !amt.IsPositive() {
+		return ledger.Transaction{}, ledger.ErrInvalidAmount
+	}
+	if amt.Currency == "" {
+		return ledger.Transaction{}, ledger.ErrInvalidCurrency
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if t, ok, err := findByIdempotencyKey(ctx, tx, idemKey); err != nil || ok {
+		return t, err
+	}
+	if err := checkAmount(ctx, tx, amt); err != nil {
+		return ledger.Transaction{}, err
+	}
+	schedules, err := feeSchedules(ctx, tx)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	t := ledger.Transaction{FromAccountID: fromID, ToAccountID: toID, Currency: amt.Currency, Amount: amt.Amount}
+	if schedule, ok := ledger.CurrentFeeSchedule(schedules, time.Now()); ok {
+		fee, err := schedule.Compute(participant, amt)
+		if err != nil {
+			return ledger.Transaction{}, err
+		}
+		t = ledger.PlanFeeTransfer(fromID, toID, amt, fee)
+	}
+	if err := checkLimits(ctx, tx, t.Legs()); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if len(t.Entries) == 0 {
+		err = applyTransfer(ctx, tx, fromID, toID, amt)
+	} else {
+		err = applyEntries(ctx, tx, t.Entries)
+	}
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	t.IdempotencyKey = idemKey
+	if err := insertTransaction(ctx, tx, &t); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.Transaction{}, err
+	}
+	return t, nil
+}
+
+func (s *Store) CaptureHoldWithFee(ctx context.Context, id string, amount int64, participant string) (ledger.Hold, ledger.Transaction, error) {
+	return s.captureHold(ctx, id, amount, true, participant)
+}
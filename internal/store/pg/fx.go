@@ -0,0 +1,221 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"qazna.org/internal/ids"
+	"qazna.org/internal/ledger"
+)
+
+const fxRateColumns = `id, base, quote, rate::text, valid_from, valid_to, created_at`
+
+func (s *Store) AddFXRate(ctx context.Context, r ledger.FXRate) (ledger.FXRate, error) {
+	r, err := ledger.NormalizeFXRate(r, time.Now().UTC())
+	if err != nil {
+		return ledger.FXRate{}, err
+	}
+	r.ID = ids.New()
+	var validTo sql.NullTime
+	if !r.ValidTo.IsZero() {
+		validTo = sql.NullTime{Time: r.ValidTo, Valid: true}
+	}
+	if err := s.db.QueryRowContext(ctx, `
+		insert into fx_rates(id, base, quote, rate, valid_from, valid_to)
+		values ($1,$2,$3,$4::numeric,$5,$6) returning created_at
+	`, r.ID, r.Base, r.Quote, r.Rate, r.ValidFrom, validTo).Scan(&r.CreatedAt); err != nil {
+		return ledger.FXRate{}, err
+	}
+	r.CreatedAt = r.CreatedAt.UTC()
+	return r, nil
+}
+
+func (s *Store) ExpireFXRate(ctx context.Context, id string) (ledger.FXRate, error) {
+	row := s.db.QueryRowContext(ctx, `
+		update fx_rates set valid_to = greatest(valid_from, now())
+		where id=$1 and (valid_to is null or valid_to > now())
+		returning `+fxRateColumns, id)
+	r, err := scanFXRate(row)
+	if errors.Is(err, ledger.ErrNotFound) {
+		// Already expired, or unknown.
+		return scanFXRate(s.db.QueryRowContext(ctx, `select `+fxRateColumns+` from fx_rates where id=$1`, id))
+	}
+	return r, err
+}
+
+func (s *Store) ListFXRates(ctx context.Context, base, quote string) ([]ledger.FXRate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		select `+fxRateColumns+` from fx_rates
+		where ($1 = '' or base = $1) and ($2 = '' or quote = $2)
+		order by base, quote, valid_from, created_at
+	`, base, quote)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []ledger.FXRate
+	for rows.Next() {
+		r, err := scanFXRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, rows.Err()
+}
+
+func (s *Store) SetFXLiquidityAccount(ctx context.Context, currency, accountID string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return ledger.ErrInvalidCurrency
+	}
+	if err := checkDesignated(ctx, s.db, accountID); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, `
+		insert into fx_liquidity_accounts(currency, account_id) values ($1,$2)
+		on conflict (currency) do update set account_id = excluded.account_id, updated_at = now()
+	`, currency, accountID)
+	return err
+}
+
+func (s *Store) FXLiquidityAccounts(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `select currency, account_id from fx_liquidity_accounts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]string)
+	for rows.Next() {
+		var currency, accountID string
+		if err := rows.Scan(&currency, &accountID); err != nil {
+			return nil, err
+		}
+		out[currency] = accountID
+	}
+	return out, rows.Err()
+}
+
+// FXTransfer picks the rate and liquidity accounts inside the posting
+// transaction, so the recorded rate is the one the legs were computed with.
+func (s *Store) FXTransfer(ctx context.Context, fromID, toID string, amt ledger.Money, toCurrency, idemKey string) (ledger.Transaction, error) {
+	return retrySerialization(ctx, func() (ledger.Transaction, error) {
+		return s.fxTransfer(ctx, fromID, toID, amt, toCurrency, idemKey)
+	})
+}
+
+func (s *Store) fxTransfer(ctx context.Context, fromID, toID string, amt ledger.Money, toCurrency, idemKey string) (ledger.Transaction, error) {
+	if !amt.IsPositive() {
+		return ledger.Transaction{}, ledger.ErrInvalidAmount
+	}
+	if amt.Currency == "" || toCurrency == "" || amt.Currency == toCurrency {
+		return ledger.Transaction{}, ledger.ErrInvalidCurrency
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if t, ok, err := findByIdempotencyKey(ctx, tx, idemKey); err != nil || ok {
+		return t, err
+	}
+	var orgID string
+	err = tx.QueryRowContext(ctx, `select coalesce(organization_id,'') from accounts where id=$1`, fromID).Scan(&orgID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !ledger.Visible(ctx, orgID)) {
+		return ledger.Transaction{}, ledger.ErrNotFound
+	}
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	rate, err := scanFXRate(tx.QueryRowContext(ctx, `
+		select `+fxRateColumns+` from fx_rates
+		where base=$1 and quote=$2 and valid_from <= now() and (valid_to is null or valid_to > now())
+		order by valid_from desc, created_at desc
+		limit 1
+	`, amt.Currency, toCurrency))
+	if errors.Is(err, ledger.ErrNotFound) {
+		return ledger.Transaction{}, ledger.ErrNoFXRate
+	}
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	var sell, buy string
+	err = tx.QueryRowContext(ctx, `
+		select
+		  coalesce((select account_id from fx_liquidity_accounts where currency=$1),''),
+		  coalesce((select account_id from fx_liquidity_accounts where currency=$2),'')
+	`, amt.Currency, toCurrency).Scan(&sell, &buy)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	if sell == "" || buy == "" {
+		return ledger.Transaction{}, ledger.ErrNoFXLiquidity
+	}
+
+	t, err := ledger.PlanFXTransfer(fromID, toID, amt, rate, sell, buy)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := checkLegs(ctx, tx, t.Entries); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := checkLimits(ctx, tx, t.Entries); err != nil {
+		return ledger.Transaction{}, err
+	}
+	// The liquidity account is debited on the caller's behalf.
+	if err := applyEntries(ledger.WithoutOrganizationScope(ctx), tx, t.Entries); err != nil {
+		return ledger.Transaction{}, err
+	}
+	t.IdempotencyKey = idemKey
+	if err := insertTransaction(ctx, tx, &t); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.Transaction{}, err
+	}
+	return t, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanFXRate(row rowScanner) (ledger.FXRate, error) {
+	var (
+		r       ledger.FXRate
+		validTo sql.NullTime
+	)
+	err := row.Scan(&r.ID, &r.Base, &r.Quote, &r.Rate, &r.ValidFrom, &validTo, &r.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ledger.FXRate{}, ledger.ErrNotFound
+	}
+	if err != nil {
+		return ledger.FXRate{}, err
+	}
+	r.Rate = normalizeRate(r.Rate)
+	r.ValidFrom = r.ValidFrom.UTC()
+	if validTo.Valid {
+		r.ValidTo = validTo.Time.UTC()
+	}
+	r.CreatedAt = r.CreatedAt.UTC()
+	return r, nil
+}
+
+// normalizeRate strips the trailing zeros numeric(24,12) pads rates with.
+func normalizeRate(s string) string {
+	if s == "" {
+		return ""
+	}
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return s
+	}
+	return ledger.FormatFXRate(rate)
+}
@@ -0,0 +1,288 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"qazna.org/internal/ids"
+	"qazna.org/internal/ledger"
+)
+
+// heldSum is the amount reserved by active holds on the account and currency
+// bound to $1 and $2.
+const heldSum = `(select coalesce(sum(h.amount),0)::bigint from holds h
+	where h.from_account_id=$1 and h.currency=$2 and h.status='pending' and h.expires_at > now())`
+
+// holdColumns is the select list scanHold expects, over holds aliased as h
+// joined to the source account aliased as a (see holdFrom).
+const holdColumns = `h.id, h.created_at, h.expires_at, h.from_account_id, h.to_account_id, h.currency,
+	h.amount, h.captured_amount, h.status, coalesce(h.transaction_id,''), coalesce(h.idempotency_key,''),
+	coalesce(a.organization_id,'')`
+
+const holdFrom = `from holds h join accounts a on a.id = h.from_account_id`
+
+// CreateHold locks both accounts, so holds and transfers debiting the same
+// account serialize on the account row.
+func (s *Store) CreateHold(ctx context.Context, fromID, toID string, amt ledger.Money, ttl time.Duration, idemKey string) (ledger.Hold, error) {
+	return retrySerialization(ctx, func() (ledger.Hold, error) {
+		return s.createHold(ctx, fromID, toID, amt, ttl, idemKey)
+	})
+}
+
+func (s *Store) createHold(ctx context.Context, fromID, toID string, amt ledger.Money, ttl time.Duration, idemKey string) (ledger.Hold, error) {
+	if !amt.IsPositive() {
+		return ledger.Hold{}, ledger.ErrInvalidAmount
+	}
+	if amt.Currency == "" {
+		return ledger.Hold{}, ledger.ErrInvalidCurrency
+	}
+	ttl, err := ledger.HoldTTL(ttl)
+	if err != nil {
+		return ledger.Hold{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return ledger.Hold{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if idemKey != "" {
+		h, _, err := scanHold(tx.QueryRowContext(ctx, `select `+holdColumns+` `+holdFrom+` where h.idempotency_key=$1`, idemKey))
+		if err == nil {
+			h.Status = h.StatusAt(time.Now())
+			return h, nil
+		}
+		if !errors.Is(err, ledger.ErrNotFound) {
+			return ledger.Hold{}, err
+		}
+	}
+
+	if err := checkAmount(ctx, tx, amt); err != nil {
+		return ledger.Hold{}, err
+	}
+	locks := make(map[string]accountLock, 2)
+	for _, acc := range sorted(fromID, toID) {
+		lock, err := lockAccount(ctx, tx, acc)
+		if err != nil {
+			return ledger.Hold{}, err
+		}
+		locks[acc] = lock
+	}
+	if !ledger.Visible(ctx, locks[fromID].orgID) {
+		return ledger.Hold{}, ledger.ErrNotFound
+	}
+	if err := locks[fromID].status.CheckDebit(); err != nil {
+		return ledger.Hold{}, err
+	}
+	if err := locks[toID].status.CheckCredit(); err != nil {
+		return ledger.Hold{}, err
+	}
+	if locks[fromID].typ == ledger.AccountTypeIssuance || locks[toID].typ == ledger.AccountTypeIssuance {
+		return ledger.Hold{}, ledger.ErrIssuanceAccount
+	}
+
+	var available int64
+	if err := tx.QueryRowContext(ctx, `
+		select coalesce((select amount from balances where account_id=$1 and currency=$2),0) - `+heldSum+`
+	`, fromID, amt.Currency).Scan(&available); err != nil {
+		return ledger.Hold{}, err
+	}
+	if available < amt.Amount {
+		return ledger.Hold{}, ledger.ErrInsufficientFunds
+	}
+
+	h := ledger.Hold{
+		ID:             ids.New(),
+		FromAccountID:  fromID,
+		ToAccountID:    toID,
+		Currency:       amt.Currency,
+		Amount:         amt.Amount,
+		Status:         ledger.HoldPending,
+		IdempotencyKey: idemKey,
+	}
+	if err := tx.QueryRowContext(ctx, `
+		insert into holds(id, from_account_id, to_account_id, currency, amount, idempotency_key, expires_at)
+		values ($1,$2,$3,$4,$5,nullif($6,''), now() + make_interval(secs => $7))
+		returning created_at, expires_at
+	`, h.ID, fromID, toID, amt.Currency, amt.Amount, idemKey, ttl.Seconds()).Scan(&h.CreatedAt, &h.ExpiresAt); err != nil {
+		return ledger.Hold{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.Hold{}, err
+	}
+	h.CreatedAt = h.CreatedAt.UTC()
+	h.ExpiresAt = h.ExpiresAt.UTC()
+	return h, nil
+}
+
+func (s *Store) GetHold(ctx context.Context, id string) (ledger.Hold, error) {
+	h, orgID, err := scanHold(s.db.QueryRowContext(ctx, `select `+holdColumns+` `+holdFrom+` where h.id=$1`, id))
+	if err != nil {
+		return ledger.Hold{}, err
+	}
+	if !ledger.Visible(ctx, orgID) {
+		return ledger.Hold{}, ledger.ErrNotFound
+	}
+	h.Status = h.StatusAt(time.Now())
+	return h, nil
+}
+
+// CaptureHold marks the hold captured before moving funds, so the transfer
+// is checked against a balance the hold no longer reserves.
+func (s *Store) CaptureHold(ctx context.Context, id string, amount int64) (ledger.Hold, ledger.Transaction, error) {
+	return s.captureHold(ctx, id, amount, false, "")
+}
+
+// captureHold captures a pending hold, charging the payer the fee of the
+// schedule in force for participant when withFee is set.
+func (s *Store) captureHold(ctx context.Context, id string, amount int64, withFee bool, participant string) (ledger.Hold, ledger.Transaction, error) {
+	var h ledger.Hold
+	t, err := retrySerialization(ctx, func() (t ledger.Transaction, err error) {
+		h, t, err = s.captureHoldOnce(ctx, id, amount, withFee, participant)
+		return t, err
+	})
+	return h, t, err
+}
+
+func (s *Store) captureHoldOnce(ctx context.Context, id string, amount int64, withFee bool, participant string) (ledger.Hold, ledger.Transaction, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	h, err := resolvableHold(ctx, tx, id)
+	if err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	amount, err = h.CaptureAmount(amount)
+	if err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		update holds set status='captured', captured_amount=$2, resolved_at=now() where id=$1
+	`, h.ID, amount); err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	amt := ledger.Money{Currency: h.Currency, Amount: amount}
+	t := ledger.Transaction{
+		FromAccountID: h.FromAccountID,
+		ToAccountID:   h.ToAccountID,
+		Currency:      h.Currency,
+		Amount:        amount,
+	}
+	if withFee {
+		schedules, err := feeSchedules(ctx, tx)
+		if err != nil {
+			return ledger.Hold{}, ledger.Transaction{}, err
+		}
+		if schedule, ok := ledger.CurrentFeeSchedule(schedules, time.Now()); ok {
+			fee, err := schedule.Compute(participant, amt)
+			if err != nil {
+				return ledger.Hold{}, ledger.Transaction{}, err
+			}
+			t = ledger.PlanFeeTransfer(h.FromAccountID, h.ToAccountID, amt, fee)
+		}
+	}
+	if err := checkLimits(ctx, tx, t.Legs()); err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	if len(t.Entries) == 0 {
+		err = applyTransfer(ctx, tx, h.FromAccountID, h.ToAccountID, amt)
+	} else {
+		err = applyEntries(ctx, tx, t.Entries)
+	}
+	if err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	if err := insertTransaction(ctx, tx, &t); err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `update holds set transaction_id=$2 where id=$1`, h.ID, t.ID); err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.Hold{}, ledger.Transaction{}, err
+	}
+	h.Status = ledger.HoldCaptured
+	h.CapturedAmount = amount
+	h.TransactionID = t.ID
+	return h, t, nil
+}
+
+func (s *Store) VoidHold(ctx context.Context, id string) (ledger.Hold, error) {
+	return retrySerialization(ctx, func() (ledger.Hold, error) {
+		return s.voidHold(ctx, id)
+	})
+}
+
+func (s *Store) voidHold(ctx context.Context, id string) (ledger.Hold, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return ledger.Hold{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	h, err := resolvableHold(ctx, tx, id)
+	if err != nil {
+		return ledger.Hold{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `update holds set status='voided', resolved_at=now() where id=$1`, h.ID); err != nil {
+		return ledger.Hold{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.Hold{}, err
+	}
+	h.Status = ledger.HoldVoided
+	return h, nil
+}
+
+// resolvableHold locks a visible pending hold. A hold found past its expiry
+// is marked expired, committing tx, and reported as ledger.ErrHoldExpired.
+func resolvableHold(ctx context.Context, tx *sql.Tx, id string) (ledger.Hold, error) {
+	h, orgID, err := scanHold(tx.QueryRowContext(ctx, `select `+holdColumns+` `+holdFrom+` where h.id=$1 for update of h`, id))
+	if err != nil {
+		return ledger.Hold{}, err
+	}
+	if !ledger.Visible(ctx, orgID) {
+		return ledger.Hold{}, ledger.ErrNotFound
+	}
+	err = h.CheckResolve(time.Now())
+	if errors.Is(err, ledger.ErrHoldExpired) {
+		if _, uerr := tx.ExecContext(ctx, `update holds set status='expired', resolved_at=now() where id=$1`, h.ID); uerr != nil {
+			return ledger.Hold{}, uerr
+		}
+		if cerr := tx.Commit(); cerr != nil {
+			return ledger.Hold{}, cerr
+		}
+	}
+	if err != nil {
+		return ledger.Hold{}, err
+	}
+	return h, nil
+}
+
+// scanHold reads a row selected with holdColumns and returns the hold, with
+// its stored status, and the organization owning its source account.
+func scanHold(row *sql.Row) (ledger.Hold, string, error) {
+	var (
+		h      ledger.Hold
+		status string
+		orgID  string
+	)
+	err := row.Scan(&h.ID, &h.CreatedAt, &h.ExpiresAt, &h.FromAccountID, &h.ToAccountID, &h.Currency,
+		&h.Amount, &h.CapturedAmount, &status, &h.TransactionID, &h.IdempotencyKey, &orgID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ledger.Hold{}, "", ledger.ErrNotFound
+	}
+	if err != nil {
+		return ledger.Hold{}, "", err
+	}
+	h.CreatedAt = h.CreatedAt.UTC()
+	h.ExpiresAt = h.ExpiresAt.UTC()
+	h.Status = ledger.HoldStatus(status)
+	return h, orgID, nil
+}
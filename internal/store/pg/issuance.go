@@ -0,0 +1,264 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+var _ ledger.Issuance = (*Store)(nil)
+
+func (s *Store) Mint(ctx context.Context, issuerID string, amt ledger.Money, idemKey string) (ledger.Transaction, error) {
+	return s.issue(ctx, ledger.KindMint, issuerID, amt, idemKey)
+}
+
+func (s *Store) Burn(ctx context.Context, issuerID string, amt ledger.Money, idemKey string) (ledger.Transaction, error) {
+	return s.issue(ctx, ledger.KindBurn, issuerID, amt, idemKey)
+}
+
+// issue posts a mint or burn against the issuance account of the currency,
+// creating that account on first use.
+func (s *Store) issue(ctx context.Context, kind ledger.TransactionKind, issuerID string, amt ledger.Money, idemKey string) (ledger.Transaction, error) {
+	return retrySerialization(ctx, func() (ledger.Transaction, error) {
+		return s.issueOnce(ctx, kind, issuerID, amt, idemKey)
+	})
+}
+
+func (s *Store) issueOnce(ctx context.Context, kind ledger.TransactionKind, issuerID string, amt ledger.Money, idemKey string) (ledger.Transaction, error) {
+	t, err := ledger.PlanIssuance(kind, issuerID, amt)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if t, ok, err := findByIdempotencyKey(ctx, tx, idemKey); err != nil || ok {
+		return t, err
+	}
+	if err := checkAmount(ctx, tx, amt); err != nil {
+		return ledger.Transaction{}, err
+	}
+	poolID := ledger.IssuanceAccountID(amt.Currency)
+	if err := ensureIssuanceAccount(ctx, tx, amt.Currency); err != nil {
+		return ledger.Transaction{}, err
+	}
+	locks := make(map[string]accountLock, 2)
+	for _, acc := range sorted(issuerID, poolID) {
+		lock, err := lockAccount(ctx, tx, acc)
+		if err != nil {
+			return ledger.Transaction{}, err
+		}
+		locks[acc] = lock
+	}
+	if !ledger.Visible(ctx, locks[issuerID].orgID) {
+		return ledger.Transaction{}, ledger.ErrNotFound
+	}
+	var designated string
+	if err := tx.QueryRowContext(ctx, `
+		select coalesce((select account_id from issuer_accounts where currency=$1),'')
+	`, amt.Currency).Scan(&designated); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if designated != issuerID {
+		return ledger.Transaction{}, ledger.ErrNotIssuer
+	}
+	if err := locks[t.FromAccountID].status.CheckDebit(); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := locks[t.ToAccountID].status.CheckCredit(); err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		insert into balances(account_id, currency, amount)
+		values ($1,$2,0) on conflict do nothing
+	`, issuerID, amt.Currency); err != nil {
+		return ledger.Transaction{}, err
+	}
+	// The issuance account may go negative; nothing else may.
+	if kind == ledger.KindBurn {
+		var available int64
+		if err := tx.QueryRowContext(ctx, `
+			select amount - `+heldSum+` from balances where account_id=$1 and currency=$2 for update
+		`, issuerID, amt.Currency).Scan(&available); err != nil {
+			return ledger.Transaction{}, err
+		}
+		if available < amt.Amount {
+			return ledger.Transaction{}, ledger.ErrInsufficientFunds
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		update balances set amount = amount - $3
+		where account_id=$1 and currency=$2
+	`, t.FromAccountID, amt.Currency, amt.Amount); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		update balances set amount = amount + $3
+		where account_id=$1 and currency=$2
+	`, t.ToAccountID, amt.Currency, amt.Amount); err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	t.IdempotencyKey = idemKey
+	if err := insertTransaction(ctx, tx, &t); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.Transaction{}, err
+	}
+	return t, nil
+}
+
+// ensureIssuanceAccount creates the issuance account of currency inside tx
+// unless it exists, with a zero balance and opening snapshot.
+func ensureIssuanceAccount(ctx context.Context, tx *sql.Tx, currency string) error {
+	acc := ledger.IssuanceAccount(currency, time.Now())
+	res, err := tx.ExecContext(ctx, `
+		insert into accounts(id, type, display_name, status, created_at)
+		values ($1,$2,$3,$4,now())
+		on conflict (id) do nothing
+	`, acc.ID, string(acc.Type), acc.DisplayName, string(acc.Status))
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		insert into balances(account_id, currency, amount) values ($1,$2,0)
+	`, acc.ID, currency); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		insert into balance_snapshots(account_id, currency, sequence, amount)
+		select $1, $2, coalesce(max(sequence),0), 0 from transactions
+	`, acc.ID, currency)
+	return err
+}
+
+func (s *Store) SetIssuerAccount(ctx context.Context, currency, accountID string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return ledger.ErrInvalidCurrency
+	}
+	registered, err := registeredCurrencies(ctx, s.db, []string{currency})
+	if err != nil {
+		return err
+	}
+	if _, err := ledger.ResolveCurrency(registered, currency); err != nil {
+		return err
+	}
+	if err := checkDesignated(ctx, s.db, accountID); err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		insert into issuer_accounts(currency, account_id) values ($1,$2)
+		on conflict (currency) do update set account_id = excluded.account_id, updated_at = now()
+	`, currency, accountID)
+	return err
+}
+
+func (s *Store) IssuerAccounts(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `select currency, account_id from issuer_accounts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]string)
+	for rows.Next() {
+		var currency, accountID string
+		if err := rows.Scan(&currency, &accountID); err != nil {
+			return nil, err
+		}
+		out[currency] = accountID
+	}
+	return out, rows.Err()
+}
+
+// Supply reads balances and issuance totals from one snapshot, so
+// Outstanding and Issued are consistent with each other.
+func (s *Store) Supply(ctx context.Context) ([]ledger.Supply, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	b := ledger.NewSupplyBuilder()
+	rows, err := tx.QueryContext(ctx, `
+		select b.currency, a.type, sum(b.amount)::bigint
+		from balances b join accounts a on a.id = b.account_id
+		group by b.currency, a.type
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			currency string
+			typ      ledger.AccountType
+			amount   int64
+		)
+		if err := rows.Scan(&currency, &typ, &amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.Balance(typ, currency, amount)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		select currency, kind, sum(amount)::bigint
+		from transactions
+		where kind <> ''
+		group by currency, kind
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			currency string
+			kind     ledger.TransactionKind
+			amount   int64
+		)
+		if err := rows.Scan(&currency, &kind, &amount); err != nil {
+			return nil, err
+		}
+		b.Issuance(kind, currency, amount)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return b.Result(), nil
+}
+
+// checkDesignated checks that accountID exists and may serve as an FX
+// liquidity or issuer account.
+func checkDesignated(ctx context.Context, db *sql.DB, accountID string) error {
+	var typ ledger.AccountType
+	err := db.QueryRowContext(ctx, `select type from accounts where id=$1`, accountID).Scan(&typ)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ledger.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if typ == ledger.AccountTypeIssuance {
+		return ledger.ErrIssuanceAccount
+	}
+	return nil
+}
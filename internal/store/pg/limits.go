@@ -0,0 +1,212 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+var _ ledger.Limits = (*Store)(nil)
+
+const limitColumns = `scope, subject, currency, max_amount, daily_amount, daily_count, window_amount, window_count, window_seconds, updated_at`
+
+func (s *Store) SetLimit(ctx context.Context, l ledger.Limit) (ledger.Limit, error) {
+	l, err := ledger.NormalizeLimit(l)
+	if err != nil {
+		return ledger.Limit{}, err
+	}
+	registered, err := registeredCurrencies(ctx, s.db, []string{l.Currency})
+	if err != nil {
+		return ledger.Limit{}, err
+	}
+	if _, err := ledger.ResolveCurrency(registered, l.Currency); err != nil {
+		return ledger.Limit{}, err
+	}
+	if l.Scope == ledger.LimitAccount {
+		var one int
+		err := s.db.QueryRowContext(ctx, `select 1 from accounts where id=$1`, l.Subject).Scan(&one)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ledger.Limit{}, ledger.ErrNotFound
+		}
+		if err != nil {
+			return ledger.Limit{}, err
+		}
+	}
+	if err := s.db.QueryRowContext(ctx, `
+		insert into transfer_limits(scope, subject, currency, max_amount, daily_amount, daily_count, window_amount, window_count, window_seconds)
+		values ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		on conflict (scope, subject, currency) do update
+		set max_amount = excluded.max_amount, daily_amount = excluded.daily_amount, daily_count = excluded.daily_count,
+		    window_amount = excluded.window_amount, window_count = excluded.window_count,
+		    window_seconds = excluded.window_seconds, updated_at = now()
+		returning updated_at
+	`, l.Scope, l.Subject, l.Currency, l.MaxAmount, l.DailyAmount, l.DailyCount, l.WindowAmount, l.WindowCount, l.WindowSeconds).Scan(&l.UpdatedAt); err != nil {
+		return ledger.Limit{}, err
+	}
+	l.UpdatedAt = l.UpdatedAt.UTC()
+	return l, nil
+}
+
+func (s *Store) DeleteLimit(ctx context.Context, k ledger.LimitKey) error {
+	k, err := ledger.NormalizeLimitKey(k)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, `delete from transfer_limits where scope=$1 and subject=$2 and currency=$3`, k.Scope, k.Subject, k.Currency)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ledger.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) ListLimits(ctx context.Context, filter ledger.LimitKey) ([]ledger.Limit, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		select `+limitColumns+` from transfer_limits
+		where ($1 = '' or scope = $1) and ($2 = '' or subject = $2)
+		order by scope, subject, currency
+	`, string(filter.Scope), filter.Subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []ledger.Limit
+	for rows.Next() {
+		l, err := scanLimit(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, l)
+	}
+	return res, rows.Err()
+}
+
+func (s *Store) LimitUtilization(ctx context.Context, k ledger.LimitKey) (ledger.Utilization, error) {
+	k, err := ledger.NormalizeLimitKey(k)
+	if err != nil {
+		return ledger.Utilization{}, err
+	}
+	l, err := scanLimit(s.db.QueryRowContext(ctx, `
+		select `+limitColumns+` from transfer_limits where scope=$1 and subject=$2 and currency=$3
+	`, k.Scope, k.Subject, k.Currency))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ledger.Utilization{}, ledger.ErrNotFound
+	}
+	if err != nil {
+		return ledger.Utilization{}, err
+	}
+	return utilization(ctx, s.db, l, time.Now())
+}
+
+func scanLimit(row interface{ Scan(...any) error }) (ledger.Limit, error) {
+	var l ledger.Limit
+	if err := row.Scan(&l.Scope, &l.Subject, &l.Currency, &l.MaxAmount, &l.DailyAmount, &l.DailyCount,
+		&l.WindowAmount, &l.WindowCount, &l.WindowSeconds, &l.UpdatedAt); err != nil {
+		return ledger.Limit{}, err
+	}
+	l.UpdatedAt = l.UpdatedAt.UTC()
+	return l, nil
+}
+
+// utilization sums what the subject of l sent per posting since the start
+// of its day or window, counting the debit legs of its accounts.
+func utilization(ctx context.Context, q queryer, l ledger.Limit, now time.Time) (ledger.Utilization, error) {
+	u := ledger.NewUtilization(l, now)
+	rows, err := q.QueryContext(ctx, `
+		with subject_accounts as (
+			select id from accounts
+			where ($1 = 'account' and id = $2) or ($1 = 'organization' and organization_id = $2)
+		), sent as (
+			select t.id, t.amount from transactions t
+			where t.from_account_id in (select id from subject_accounts) and t.currency = $3
+			union all
+			select e.transaction_id, e.amount from transaction_entries e
+			where e.account_id in (select id from subject_accounts) and e.currency = $3 and e.direction = 'debit'
+		)
+		select t.created_at, sum(d.amount)
+		from sent d join transactions t on t.id = d.id
+		where t.created_at >= $4 and t.reversal_of is null and t.kind = ''
+		group by t.id, t.created_at
+	`, l.Scope, l.Subject, l.Currency, u.Since())
+	if err != nil {
+		return ledger.Utilization{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			at     time.Time
+			amount int64
+		)
+		if err := rows.Scan(&at, &amount); err != nil {
+			return ledger.Utilization{}, err
+		}
+		u.Add(at, amount)
+	}
+	return u, rows.Err()
+}
+
+// checkLimits fails with ErrLimitExceeded when the posting of legs would
+// break a limit of a debited account or its organization. It rewrites each
+// applicable limit row, so concurrent postings against the same limit
+// conflict instead of both passing on usage neither sees; the loser fails
+// with a serialization failure and is run again by retrySerialization.
+func checkLimits(ctx context.Context, tx *sql.Tx, legs []ledger.Entry) error {
+	var debited []string
+	for _, e := range legs {
+		if e.Direction == ledger.Debit {
+			debited = append(debited, e.AccountID)
+		}
+	}
+	rows, err := tx.QueryContext(ctx, `select id, coalesce(organization_id,'') from accounts where id = any($1)`, debited)
+	if err != nil {
+		return err
+	}
+	orgs := make(map[string]string, len(debited))
+	for rows.Next() {
+		var id, org string
+		if err := rows.Scan(&id, &org); err != nil {
+			rows.Close()
+			return err
+		}
+		orgs[id] = org
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	debits := ledger.LimitDebits(legs, orgs)
+	keys := make([]ledger.Limit, 0, len(debits))
+	for k := range debits {
+		keys = append(keys, ledger.Limit{Scope: k.Scope, Subject: k.Subject, Currency: k.Currency})
+	}
+	ledger.SortLimits(keys)
+	now := time.Now()
+	for _, k := range keys {
+		l, err := scanLimit(tx.QueryRowContext(ctx, `
+			update transfer_limits set updated_at = updated_at
+			where scope=$1 and subject=$2 and currency=$3
+			returning `+limitColumns, k.Scope, k.Subject, k.Currency))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		u, err := utilization(ctx, tx, l, now)
+		if err != nil {
+			return err
+		}
+		if err := u.Check(debits[l.Key()]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"qazna.org/internal/ids"
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/migrate"
+)
+
+// openTestStore opens the database named by QAZNA_TEST_PG_DSN and migrates
+// it, skipping the test when the variable is unset.
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	dsn := os.Getenv("QAZNA_TEST_PG_DSN")
+	if dsn == "" {
+		t.Skip("QAZNA_TEST_PG_DSN not set")
+	}
+	s, err := Open(dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	if err := migrate.NewManager(s.db, "../../../ops/migrations/sql", "").Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return s
+}
+
+func TestConcurrentTransfersOrganizationLimit(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	org := "org-" + ids.New()
+	if _, err := s.db.ExecContext(ctx, `insert into organizations(id, name) values ($1, $1)`, org); err != nil {
+		t.Fatalf("organization: %v", err)
+	}
+	orgCtx := ledger.WithOrganizationScope(ctx, org)
+	var payers []ledger.Account
+	for range 4 {
+		acc, err := s.CreateAccount(orgCtx, ledger.Money{Currency: "USD", Amount: 1000})
+		if err != nil {
+			t.Fatalf("create payer: %v", err)
+		}
+		payers = append(payers, acc)
+	}
+	payee, err := s.CreateAccount(ctx, ledger.Money{Currency: "USD"})
+	if err != nil {
+		t.Fatalf("create payee: %v", err)
+	}
+	if _, err := s.SetLimit(ctx, ledger.Limit{Scope: ledger.LimitOrganization, Subject: org, Currency: "USD", DailyAmount: 500}); err != nil {
+		t.Fatalf("set limit: %v", err)
+	}
+
+	const transfers = 20
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		ok, over int
+		failures []error
+	)
+	for i := range transfers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			from := payers[i%len(payers)]
+			_, err := s.Transfer(ctx, from.ID, payee.ID, ledger.Money{Currency: "USD", Amount: 100}, "")
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, ledger.ErrLimitExceeded):
+				over++
+			default:
+				failures = append(failures, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		t.Fatalf("unexpected errors: %v", failures)
+	}
+	if ok != 5 || over != transfers-5 {
+		t.Fatalf("transfers ok=%d over=%d, want 5 and %d", ok, over, transfers-5)
+	}
+	bal, err := s.GetBalance(ctx, payee.ID, "USD")
+	if err != nil {
+		t.Fatalf("balance: %v", err)
+	}
+	if bal.Amount != 500 {
+		t.Fatalf("payee balance = %d, want 500", bal.Amount)
+	}
+}
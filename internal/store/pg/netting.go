@@ -0,0 +1,240 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"qazna.org/internal/ids"
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/netting"
+)
+
+var _ netting.Store = (*Store)(nil)
+
+const cycleColumns = `id, status, settlement_account_id, opened_by, opened_by_org, created_at, closed_at, settled_at, cancelled_at,
+	coalesce(transaction_id,''), note`
+
+const nettingPaymentColumns = `id, cycle_id, seq, from_account_id, to_account_id, currency, amount, fee, coalesce(idempotency_key,''),
+	status, reason, submitted_by, submitted_by_org, created_at`
+
+func (s *Store) CreateCycle(ctx context.Context, c netting.Cycle) (netting.Cycle, error) {
+	return scanCycle(s.db.QueryRowContext(ctx, `
+		insert into netting_cycles(id, settlement_account_id, opened_by, opened_by_org, note)
+		values ($1,$2,$3,$4,$5)
+		returning `+cycleColumns,
+		ids.New(), c.SettlementAccountID, c.OpenedBy, c.OpenedByOrg, c.Note))
+}
+
+func (s *Store) GetCycle(ctx context.Context, id string) (netting.Cycle, error) {
+	c, err := scanCycle(s.db.QueryRowContext(ctx, `select `+cycleColumns+` from netting_cycles where id=$1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return netting.Cycle{}, netting.ErrNotFound
+	}
+	return c, err
+}
+
+func (s *Store) ListCycles(ctx context.Context, status netting.CycleStatus) ([]netting.Cycle, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		select `+cycleColumns+` from netting_cycles
+		where $1 = '' or status = $1
+		order by created_at, id
+	`, string(status))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []netting.Cycle
+	for rows.Next() {
+		c, err := scanCycle(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+// AddPayment locks the cycle row, so a payment is only added while the
+// cycle is open and the count check holds against concurrent submissions.
+func (s *Store) AddPayment(ctx context.Context, p netting.Payment) (netting.Payment, error) {
+	if p.IdempotencyKey != "" {
+		prev, err := scanNettingPayment(s.db.QueryRowContext(ctx, `select `+nettingPaymentColumns+` from netting_payments where idempotency_key=$1`, p.IdempotencyKey))
+		if !errors.Is(err, sql.ErrNoRows) {
+			return prev, err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return netting.Payment{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status netting.CycleStatus
+	err = tx.QueryRowContext(ctx, `select status from netting_cycles where id=$1 for update`, p.CycleID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return netting.Payment{}, netting.ErrNotFound
+	}
+	if err != nil {
+		return netting.Payment{}, err
+	}
+	if status != netting.CycleOpen {
+		return netting.Payment{}, netting.ErrCycleNotOpen
+	}
+	var n int
+	if err := tx.QueryRowContext(ctx, `select count(*) from netting_payments where cycle_id=$1`, p.CycleID).Scan(&n); err != nil {
+		return netting.Payment{}, err
+	}
+	if n >= netting.MaxPayments {
+		return netting.Payment{}, netting.ErrCycleFull
+	}
+	var fee []byte
+	if p.Fee != nil {
+		if fee, err = json.Marshal(p.Fee); err != nil {
+			return netting.Payment{}, err
+		}
+	}
+	created, err := scanNettingPayment(tx.QueryRowContext(ctx, `
+		insert into netting_payments(id, cycle_id, from_account_id, to_account_id, currency, amount, fee, idempotency_key, submitted_by, submitted_by_org)
+		values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		on conflict (idempotency_key) where idempotency_key is not null do nothing
+		returning `+nettingPaymentColumns,
+		ids.New(), p.CycleID, p.FromAccountID, p.ToAccountID, p.Currency, p.Amount, fee, nullIfEmpty(p.IdempotencyKey), p.SubmittedBy, p.SubmittedByOrg))
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return scanNettingPayment(s.db.QueryRowContext(ctx, `select `+nettingPaymentColumns+` from netting_payments where idempotency_key=$1`, p.IdempotencyKey))
+	}
+	if err != nil {
+		return netting.Payment{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return netting.Payment{}, err
+	}
+	return created, nil
+}
+
+func (s *Store) ListPayments(ctx context.Context, cycleID string) ([]netting.Payment, error) {
+	if _, err := s.GetCycle(ctx, cycleID); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, `select `+nettingPaymentColumns+` from netting_payments where cycle_id=$1 order by seq`, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []netting.Payment
+	for rows.Next() {
+		p, err := scanNettingPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
+
+// UpdateCycle locks the cycle row and moves it and its payments together,
+// so of two concurrent updates from the same status the second finds the
+// cycle moved on.
+func (s *Store) UpdateCycle(ctx context.Context, id string, from netting.CycleStatus, u netting.CycleUpdate) (netting.Cycle, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return netting.Cycle{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status netting.CycleStatus
+	err = tx.QueryRowContext(ctx, `select status from netting_cycles where id=$1 for update`, id).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return netting.Cycle{}, netting.ErrNotFound
+	}
+	if err != nil {
+		return netting.Cycle{}, err
+	}
+	if status != from || !netting.CanTransition(from, u.Status) {
+		return netting.Cycle{}, netting.ErrInvalidTransition
+	}
+	c, err := scanCycle(tx.QueryRowContext(ctx, `
+		update netting_cycles
+		set status=$2,
+			closed_at = case when $2 = 'closed' then coalesce(closed_at, now()) else closed_at end,
+			settled_at = case when $2 = 'settled' then now() else settled_at end,
+			cancelled_at = case when $2 = 'cancelled' then now() else cancelled_at end,
+			transaction_id = case when $2 = 'settled' then $3 else transaction_id end,
+			note = case when $4 <> '' then $4 else note end
+		where id=$1
+		returning `+cycleColumns,
+		id, string(u.Status), nullIfEmpty(u.TransactionID), u.Note))
+	if err != nil {
+		return netting.Cycle{}, err
+	}
+
+	switch u.Status {
+	case netting.CycleSettling:
+		for _, r := range u.Removed {
+			if _, err := tx.ExecContext(ctx, `
+				update netting_payments set status='removed', reason=$3
+				where id=$1 and cycle_id=$2 and status='pending'
+			`, r.PaymentID, id, r.Reason); err != nil {
+				return netting.Cycle{}, err
+			}
+		}
+	case netting.CycleClosed:
+		_, err = tx.ExecContext(ctx, `update netting_payments set status='pending', reason='' where cycle_id=$1 and status='removed'`, id)
+	case netting.CycleSettled:
+		_, err = tx.ExecContext(ctx, `update netting_payments set status='settled' where cycle_id=$1 and status='pending'`, id)
+	case netting.CycleCancelled:
+		_, err = tx.ExecContext(ctx, `update netting_payments set status='cancelled' where cycle_id=$1 and status='pending'`, id)
+	}
+	if err != nil {
+		return netting.Cycle{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return netting.Cycle{}, err
+	}
+	return c, nil
+}
+
+func scanCycle(row interface{ Scan(...any) error }) (netting.Cycle, error) {
+	var (
+		c                              netting.Cycle
+		closedAt, settledAt, cancelled sql.NullTime
+	)
+	if err := row.Scan(&c.ID, &c.Status, &c.SettlementAccountID, &c.OpenedBy, &c.OpenedByOrg, &c.CreatedAt,
+		&closedAt, &settledAt, &cancelled, &c.TransactionID, &c.Note); err != nil {
+		return netting.Cycle{}, err
+	}
+	c.CreatedAt = c.CreatedAt.UTC()
+	c.ClosedAt, c.SettledAt, c.CancelledAt = utcTime(closedAt), utcTime(settledAt), utcTime(cancelled)
+	return c, nil
+}
+
+func utcTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	at := t.Time.UTC()
+	return &at
+}
+
+func scanNettingPayment(row interface{ Scan(...any) error }) (netting.Payment, error) {
+	var (
+		p   netting.Payment
+		fee []byte
+	)
+	if err := row.Scan(&p.ID, &p.CycleID, &p.Sequence, &p.FromAccountID, &p.ToAccountID, &p.Currency, &p.Amount, &fee, &p.IdempotencyKey,
+		&p.Status, &p.Reason, &p.SubmittedBy, &p.SubmittedByOrg, &p.CreatedAt); err != nil {
+		return netting.Payment{}, err
+	}
+	if len(fee) > 0 {
+		p.Fee = new(ledger.Fee)
+		if err := json.Unmarshal(fee, p.Fee); err != nil {
+			return netting.Payment{}, err
+		}
+	}
+	p.CreatedAt = p.CreatedAt.UTC()
+	return p, nil
+}
@@ -0,0 +1,105 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"qazna.org/internal/ledger"
+	"qazna.org/internal/outbox"
+)
+
+var _ outbox.Source = (*Store)(nil)
+
+func insertOutboxEvent(ctx context.Context, tx *sql.Tx, t ledger.Transaction) error {
+	payload, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		insert into outbox(sequence, transaction_id, event_type, payload, created_at)
+		values ($1,$2,$3,$4::jsonb,$5)
+	`, t.Sequence, t.ID, outbox.EventTransactionPosted, string(payload), t.CreatedAt)
+	return err
+}
+
+// OutboxHorizon waits for postings in flight and returns the latest
+// sequence. Sequences are drawn before commit, so a lower one may still
+// commit after a higher one; the share lock (as in SnapshotBalances) ensures
+// none is pending below the returned value. New postings wait only for the
+// duration of the lookup. The result is shared with WatchTransactions.
+func (s *Store) OutboxHorizon(ctx context.Context) (uint64, error) {
+	seq, err := s.takeHorizon(ctx)
+	if err != nil {
+		return 0, err
+	}
+	s.horizon.record(seq)
+	return seq, nil
+}
+
+func (s *Store) takeHorizon(ctx context.Context) (uint64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `lock table transactions in share mode`); err != nil {
+		return 0, err
+	}
+	var seq uint64
+	if err := tx.QueryRowContext(ctx, `select coalesce(max(sequence),0) from transactions`).Scan(&seq); err != nil {
+		return 0, err
+	}
+	return seq, tx.Commit()
+}
+
+func (s *Store) ReadOutbox(ctx context.Context, after, upTo uint64, limit int) ([]outbox.Event, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		select sequence, event_type, payload, created_at from outbox
+		where sequence > $1 and sequence <= $2
+		order by sequence
+		limit $3
+	`, after, upTo, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []outbox.Event
+	for rows.Next() {
+		var (
+			evt     outbox.Event
+			payload []byte
+		)
+		if err := rows.Scan(&evt.Sequence, &evt.Type, &payload, &evt.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &evt.Transaction); err != nil {
+			return nil, err
+		}
+		evt.CreatedAt = evt.CreatedAt.UTC()
+		res = append(res, evt)
+	}
+	return res, rows.Err()
+}
+
+func (s *Store) OutboxCursor(ctx context.Context, consumer string) (uint64, bool, error) {
+	var seq uint64
+	err := s.db.QueryRowContext(ctx, `select sequence from outbox_cursors where consumer=$1`, consumer).Scan(&seq)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return seq, true, nil
+}
+
+func (s *Store) SaveOutboxCursor(ctx context.Context, consumer string, seq uint64) error {
+	_, err := s.db.ExecContext(ctx, `
+		insert into outbox_cursors(consumer, sequence) values ($1,$2)
+		on conflict (consumer) do update set sequence = excluded.sequence, updated_at = now()
+	`, consumer, seq)
+	return err
+}
@@ -3,7 +3,9 @@ package pg
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"sort"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -13,7 +15,8 @@ import (
 )
 
 type Store struct {
-	db *sql.DB
+	db      *sql.DB
+	horizon horizonCache
 }
 
 var _ ledger.Service = (*Store)(nil)
@@ -35,14 +38,20 @@ func (s *Store) Close() error { return s.db.Close() }
 
 func (s *Store) DB() *sql.DB { return s.db }
 
-func (s *Store) CreateAccount(ctx context.Context, initial ledger.Money) (ledger.Account, error) {
+func (s *Store) CreateAccount(ctx context.Context, initial ledger.Money, opts ...ledger.AccountOption) (ledger.Account, error) {
 	if initial.Currency == "" {
 		return ledger.Account{}, ledger.ErrInvalidCurrency
 	}
 	if initial.Amount < 0 {
 		return ledger.Account{}, ledger.ErrInvalidAmount
 	}
-	id := ids.New()
+	acc, err := ledger.AccountTemplate(opts...)
+	if err != nil {
+		return ledger.Account{}, err
+	}
+	acc.ID = ids.New()
+	acc.OrganizationID, _ = ledger.OrganizationScope(ctx)
+	id := acc.ID
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -50,7 +59,17 @@ func (s *Store) CreateAccount(ctx context.Context, initial ledger.Money) (ledger
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	if _, err := tx.ExecContext(ctx, `insert into accounts(id, created_at) values($1, now())`, id); err != nil {
+	cur, err := resolveCurrency(ctx, tx, initial.Currency)
+	if err != nil {
+		return ledger.Account{}, err
+	}
+	if err := cur.CheckEnabled(); err != nil {
+		return ledger.Account{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		insert into accounts(id, organization_id, type, display_name, external_ref, status, created_at)
+		values ($1, nullif($2,''), $3, $4, nullif($5,''), $6, now())
+	`, id, acc.OrganizationID, string(acc.Type), acc.DisplayName, acc.ExternalRef, string(acc.Status)); err != nil {
 		return ledger.Account{}, err
 	}
 	if _, err := tx.ExecContext(ctx, `
@@ -61,21 +80,30 @@ func (s *Store) CreateAccount(ctx context.Context, initial ledger.Money) (ledger
 	`, id, initial.Currency, initial.Amount); err != nil {
 		return ledger.Account{}, err
 	}
+	// Any transaction touching the new account locks its row first and so
+	// draws a higher sequence than the current maximum.
+	if _, err := tx.ExecContext(ctx, `
+		insert into balance_snapshots(account_id, currency, sequence, amount)
+		select $1, $2, coalesce(max(sequence),0), $3 from transactions
+	`, id, initial.Currency, initial.Amount); err != nil {
+		return ledger.Account{}, err
+	}
 	if err := tx.Commit(); err != nil {
 		return ledger.Account{}, err
 	}
 
-	return ledger.Account{
-		ID:        id,
-		CreatedAt: time.Now().UTC(),
-		Balances:  map[string]int64{initial.Currency: initial.Amount},
-	}, nil
+	acc.CreatedAt = time.Now().UTC()
+	acc.Balances = map[string]int64{initial.Currency: initial.Amount}
+	return acc, nil
 }
 
 func (s *Store) GetAccount(ctx context.Context, id string) (ledger.Account, error) {
-	var created time.Time
-	err := s.db.QueryRowContext(ctx, `select created_at from accounts where id=$1`, id).Scan(&created)
-	if errors.Is(err, sql.ErrNoRows) {
+	acc := ledger.Account{ID: id}
+	err := s.db.QueryRowContext(ctx, `
+		select created_at, coalesce(organization_id,''), type, display_name, coalesce(external_ref,''), status
+		from accounts where id=$1
+	`, id).Scan(&acc.CreatedAt, &acc.OrganizationID, &acc.Type, &acc.DisplayName, &acc.ExternalRef, &acc.Status)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !ledger.Visible(ctx, acc.OrganizationID)) {
 		return ledger.Account{}, ledger.ErrNotFound
 	}
 	if err != nil {
@@ -97,27 +125,78 @@ func (s *Store) GetAccount(ctx context.Context, id string) (ledger.Account, erro
 		}
 		bals[c] = a
 	}
-	return ledger.Account{ID: id, CreatedAt: created, Balances: bals}, nil
+	acc.Balances = bals
+	return acc, rows.Err()
 }
 
-func (s *Store) GetBalance(ctx context.Context, id, currency string) (ledger.Money, error) {
-	var amt int64
+func (s *Store) SetAccountStatus(ctx context.Context, id string, status ledger.AccountStatus) (ledger.Account, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ledger.Account{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	lock, err := lockAccount(ctx, tx, id)
+	if err != nil {
+		return ledger.Account{}, err
+	}
+	if !ledger.Visible(ctx, lock.orgID) {
+		return ledger.Account{}, ledger.ErrNotFound
+	}
+	if err := lock.status.CheckTransition(status); err != nil {
+		return ledger.Account{}, err
+	}
+	if status == ledger.AccountClosed {
+		var funded bool
+		if err := tx.QueryRowContext(ctx, `select exists(select 1 from balances where account_id=$1 and amount <> 0)`, id).Scan(&funded); err != nil {
+			return ledger.Account{}, err
+		}
+		if funded {
+			return ledger.Account{}, ledger.ErrAccountNotEmpty
+		}
+	}
+	if status != lock.status {
+		if _, err := tx.ExecContext(ctx, `update accounts set status=$2, status_changed_at=now() where id=$1`, id, string(status)); err != nil {
+			return ledger.Account{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.Account{}, err
+	}
+	return s.GetAccount(ctx, id)
+}
+
+func (s *Store) GetBalance(ctx context.Context, id, currency string) (ledger.Balance, error) {
+	var (
+		amt, held int64
+		orgID     string
+	)
 	err := s.db.QueryRowContext(ctx, `
-		select coalesce(b.amount,0)
+		select coalesce(b.amount,0), `+heldSum+`, coalesce(a.organization_id,'')
 		from accounts a
 		left join balances b on b.account_id=a.id and b.currency=$2
 		where a.id=$1
-	`, id, currency).Scan(&amt)
-	if errors.Is(err, sql.ErrNoRows) {
-		return ledger.Money{}, ledger.ErrNotFound
+	`, id, currency).Scan(&amt, &held, &orgID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !ledger.Visible(ctx, orgID)) {
+		return ledger.Balance{}, ledger.ErrNotFound
 	}
 	if err != nil {
-		return ledger.Money{}, err
+		return ledger.Balance{}, err
 	}
-	return ledger.Money{Currency: currency, Amount: amt}, nil
+	return ledger.Balance{
+		Money:     ledger.Money{Currency: currency, Amount: amt},
+		Held:      held,
+		Available: amt - held,
+	}, nil
 }
 
 func (s *Store) Transfer(ctx context.Context, fromID, toID string, amt ledger.Money, idemKey string) (ledger.Transaction, error) {
+	return retrySerialization(ctx, func() (ledger.Transaction, error) {
+		return s.transfer(ctx, fromID, toID, amt, idemKey)
+	})
+}
+
+func (s *Store) transfer(ctx context.Context, fromID, toID string, amt ledger.Money, idemKey string) (ledger.Transaction, error) {
 	if !amt.IsPositive() {
 		return ledger.Transaction{}, ledger.ErrInvalidAmount
 	}
@@ -132,34 +211,58 @@ func (s *Store) Transfer(ctx context.Context, fromID, toID string, amt ledger.Mo
 	defer func() { _ = tx.Rollback() }()
 
 	// Idempotency: return existing tx if idemKey already recorded
-	if idemKey != "" {
-		var t ledger.Transaction
-		var created time.Time
-		var idem sql.NullString
-		err := tx.QueryRowContext(ctx, `
-			select id, created_at, from_account_id, to_account_id, currency, amount, sequence, idempotency_key
-			from transactions where idempotency_key=$1
-		`, idemKey).Scan(&t.ID, &created, &t.FromAccountID, &t.ToAccountID, &t.Currency, &t.Amount, &t.Sequence, &idem)
-		if err == nil {
-			t.CreatedAt = created
-			if idem.Valid {
-				t.IdempotencyKey = idem.String
-			}
-			return t, nil
-		} else if err != sql.ErrNoRows {
-			return ledger.Transaction{}, err
-		}
+	if t, ok, err := findByIdempotencyKey(ctx, tx, idemKey); err != nil || ok {
+		return t, err
+	}
+	if err := checkAmount(ctx, tx, amt); err != nil {
+		return ledger.Transaction{}, err
 	}
 
+	t := ledger.Transaction{
+		FromAccountID:  fromID,
+		ToAccountID:    toID,
+		Currency:       amt.Currency,
+		Amount:         amt.Amount,
+		IdempotencyKey: idemKey,
+	}
+	if err := checkLimits(ctx, tx, t.Legs()); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := applyTransfer(ctx, tx, fromID, toID, amt); err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	if err := insertTransaction(ctx, tx, &t); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.Transaction{}, err
+	}
+	return t, nil
+}
+
+// applyTransfer checks and moves amt from fromID to toID inside tx.
+func applyTransfer(ctx context.Context, tx *sql.Tx, fromID, toID string, amt ledger.Money) error {
 	// Lock accounts to ensure existence and stable ordering to avoid deadlocks
+	locks := make(map[string]accountLock, 2)
 	for _, acc := range sorted(fromID, toID) {
-		var dummy int
-		if err := tx.QueryRowContext(ctx, `select 1 from accounts where id=$1 for update`, acc).Scan(&dummy); err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				return ledger.Transaction{}, ledger.ErrNotFound
-			}
-			return ledger.Transaction{}, err
+		lock, err := lockAccount(ctx, tx, acc)
+		if err != nil {
+			return err
 		}
+		locks[acc] = lock
+	}
+	if !ledger.Visible(ctx, locks[fromID].orgID) {
+		return ledger.ErrNotFound
+	}
+	if err := locks[fromID].status.CheckDebit(); err != nil {
+		return err
+	}
+	if err := locks[toID].status.CheckCredit(); err != nil {
+		return err
+	}
+	if locks[fromID].typ == ledger.AccountTypeIssuance || locks[toID].typ == ledger.AccountTypeIssuance {
+		return ledger.ErrIssuanceAccount
 	}
 
 	// Ensure balance rows exist
@@ -167,24 +270,24 @@ func (s *Store) Transfer(ctx context.Context, fromID, toID string, amt ledger.Mo
 		insert into balances(account_id, currency, amount)
 		values ($1,$2,0) on conflict do nothing
 	`, fromID, amt.Currency); err != nil {
-		return ledger.Transaction{}, err
+		return err
 	}
 	if _, err := tx.ExecContext(ctx, `
 		insert into balances(account_id, currency, amount)
 		values ($1,$2,0) on conflict do nothing
 	`, toID, amt.Currency); err != nil {
-		return ledger.Transaction{}, err
+		return err
 	}
 
-	// Check sufficient funds (lock row)
-	var fromBal int64
+	// Check sufficient available funds (lock row)
+	var available int64
 	if err := tx.QueryRowContext(ctx, `
-		select amount from balances where account_id=$1 and currency=$2 for update
-	`, fromID, amt.Currency).Scan(&fromBal); err != nil {
-		return ledger.Transaction{}, ledger.ErrNotFound
+		select amount - `+heldSum+` from balances where account_id=$1 and currency=$2 for update
+	`, fromID, amt.Currency).Scan(&available); err != nil {
+		return ledger.ErrNotFound
 	}
-	if fromBal < amt.Amount {
-		return ledger.Transaction{}, ledger.ErrInsufficientFunds
+	if available < amt.Amount {
+		return ledger.ErrInsufficientFunds
 	}
 
 	// Apply delta
@@ -192,75 +295,477 @@ func (s *Store) Transfer(ctx context.Context, fromID, toID string, amt ledger.Mo
 		update balances set amount = amount - $3
 		where account_id=$1 and currency=$2
 	`, fromID, amt.Currency, amt.Amount); err != nil {
-		return ledger.Transaction{}, err
+		return err
 	}
-	if _, err := tx.ExecContext(ctx, `
+	_, err := tx.ExecContext(ctx, `
 		update balances set amount = amount + $3
 		where account_id=$1 and currency=$2
-	`, toID, amt.Currency, amt.Amount); err != nil {
+	`, toID, amt.Currency, amt.Amount)
+	return err
+}
+
+func (s *Store) PostEntries(ctx context.Context, entries []ledger.Entry, idemKey string) (ledger.Transaction, error) {
+	return retrySerialization(ctx, func() (ledger.Transaction, error) {
+		return s.postEntries(ctx, entries, idemKey)
+	})
+}
+
+func (s *Store) postEntries(ctx context.Context, entries []ledger.Entry, idemKey string) (ledger.Transaction, error) {
+	if err := ledger.ValidateEntries(entries); err != nil {
 		return ledger.Transaction{}, err
 	}
 
-	// Record transaction
-	tid := ids.New()
-	var seq uint64
-	if err := tx.QueryRowContext(ctx, `
-		insert into transactions(id, from_account_id, to_account_id, currency, amount, idempotency_key)
-		values ($1,$2,$3,$4,$5,nullif($6,'')) returning sequence
-	`, tid, fromID, toID, amt.Currency, amt.Amount, idemKey).Scan(&seq); err != nil {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
 		return ledger.Transaction{}, err
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	if err := tx.Commit(); err != nil {
+	if t, ok, err := findByIdempotencyKey(ctx, tx, idemKey); err != nil || ok {
+		return t, err
+	}
+	if err := checkLegs(ctx, tx, entries); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := checkLimits(ctx, tx, entries); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := applyEntries(ctx, tx, entries); err != nil {
 		return ledger.Transaction{}, err
 	}
 
-	return ledger.Transaction{
-		ID:             tid,
-		CreatedAt:      time.Now().UTC(),
-		FromAccountID:  fromID,
-		ToAccountID:    toID,
-		Currency:       amt.Currency,
-		Amount:         amt.Amount,
+	t := ledger.Transaction{
 		IdempotencyKey: idemKey,
-		Sequence:       seq,
-	}, nil
+		Entries:        append([]ledger.Entry(nil), entries...),
+	}
+	if err := insertTransaction(ctx, tx, &t); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.Transaction{}, err
+	}
+	return t, nil
+}
+
+// applyEntries checks and applies validated batch legs inside tx.
+func applyEntries(ctx context.Context, tx *sql.Tx, entries []ledger.Entry) error {
+	// Net legs per account/currency; iterate in sorted order to avoid deadlocks.
+	deltas := make(map[balanceKey]int64)
+	accountSet := make(map[string]struct{})
+	debited := make(map[string]bool)
+	for _, e := range entries {
+		k := balanceKey{account: e.AccountID, currency: e.Currency}
+		if e.Direction == ledger.Debit {
+			deltas[k] -= e.Amount
+			debited[e.AccountID] = true
+		} else {
+			deltas[k] += e.Amount
+		}
+		accountSet[e.AccountID] = struct{}{}
+	}
+	accounts := make([]string, 0, len(accountSet))
+	for id := range accountSet {
+		accounts = append(accounts, id)
+	}
+	sort.Strings(accounts)
+	keys := make([]balanceKey, 0, len(deltas))
+	for k := range deltas {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].account != keys[j].account {
+			return keys[i].account < keys[j].account
+		}
+		return keys[i].currency < keys[j].currency
+	})
+
+	for _, acc := range accounts {
+		lock, err := lockAccount(ctx, tx, acc)
+		if err != nil {
+			return err
+		}
+		if lock.typ == ledger.AccountTypeIssuance {
+			return ledger.ErrIssuanceAccount
+		}
+		if debited[acc] {
+			if !ledger.Visible(ctx, lock.orgID) {
+				return ledger.ErrNotFound
+			}
+			if err := lock.status.CheckDebit(); err != nil {
+				return err
+			}
+		}
+		if err := lock.status.CheckCredit(); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range keys {
+		if _, err := tx.ExecContext(ctx, `
+			insert into balances(account_id, currency, amount)
+			values ($1,$2,0) on conflict do nothing
+		`, k.account, k.currency); err != nil {
+			return err
+		}
+		var available int64
+		if err := tx.QueryRowContext(ctx, `
+			select amount - `+heldSum+` from balances where account_id=$1 and currency=$2 for update
+		`, k.account, k.currency).Scan(&available); err != nil {
+			return err
+		}
+		if available+deltas[k] < 0 {
+			return ledger.ErrInsufficientFunds
+		}
+	}
+
+	for _, k := range keys {
+		if deltas[k] == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			update balances set amount = amount + $3
+			where account_id=$1 and currency=$2
+		`, k.account, k.currency, deltas[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reverse locks the original transaction row so concurrent reversals of the
+// same transaction serialize on reversed_amount.
+func (s *Store) Reverse(ctx context.Context, txID string, amount int64, reason, idemKey string) (ledger.Transaction, error) {
+	return retrySerialization(ctx, func() (ledger.Transaction, error) {
+		return s.reverse(ctx, txID, amount, reason, idemKey)
+	})
+}
+
+func (s *Store) reverse(ctx context.Context, txID string, amount int64, reason, idemKey string) (ledger.Transaction, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if t, ok, err := findByIdempotencyKey(ctx, tx, idemKey); err != nil || ok {
+		return t, err
+	}
+	if _, err := tx.ExecContext(ctx, `select 1 from transactions where id=$1 for update`, txID); err != nil {
+		return ledger.Transaction{}, err
+	}
+	orgID, scoped := ledger.OrganizationScope(ctx)
+	orig, err := findTransaction(ctx, tx, `t.id = $1
+		and (not $2 or exists (
+		  select 1 from accounts a
+		  where coalesce(a.organization_id,'') = $3
+		    and (a.id = t.from_account_id or a.id = t.to_account_id
+		         or a.id in (select e.account_id from transaction_entries e where e.transaction_id = t.id))
+		))`, txID, scoped, orgID)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	rev, err := ledger.PlanReversal(orig, amount, reason)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	if len(rev.Entries) == 0 {
+		err = applyTransfer(ctx, tx, rev.FromAccountID, rev.ToAccountID, ledger.Money{Currency: rev.Currency, Amount: rev.Amount})
+	} else {
+		err = applyEntries(ctx, tx, rev.Entries)
+	}
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	rev.IdempotencyKey = idemKey
+	if err := insertTransaction(ctx, tx, &rev); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		update transactions set reversed_amount = reversed_amount + $2 where id=$1
+	`, orig.ID, rev.Gross()); err != nil {
+		return ledger.Transaction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ledger.Transaction{}, err
+	}
+	return rev, nil
+}
+
+// insertTransaction records t, and its legs for batch postings, assigning
+// its id, sequence and creation time. Its outbox event is queued in the same
+// database transaction.
+func insertTransaction(ctx context.Context, tx *sql.Tx, t *ledger.Transaction) error {
+	t.ID = ids.New()
+	var from, to, currency sql.NullString
+	var amount sql.NullInt64
+	if len(t.Entries) == 0 {
+		from = sql.NullString{String: t.FromAccountID, Valid: true}
+		to = sql.NullString{String: t.ToAccountID, Valid: true}
+		currency = sql.NullString{String: t.Currency, Valid: true}
+		amount = sql.NullInt64{Int64: t.Amount, Valid: true}
+	}
+	var fee []byte
+	if t.Fee != nil {
+		b, err := json.Marshal(t.Fee)
+		if err != nil {
+			return err
+		}
+		fee = b
+	}
+	if err := tx.QueryRowContext(ctx, `
+		insert into transactions(id, from_account_id, to_account_id, currency, amount, idempotency_key, reversal_of, reversal_reason,
+		                         fx_rate_id, fx_rate, kind, fee)
+		values ($1,$2,$3,$4,$5,nullif($6,''),nullif($7,''),$8,nullif($9,''),nullif($10,'')::numeric,$11,$12) returning sequence, created_at
+	`, t.ID, from, to, currency, amount, t.IdempotencyKey, t.ReversalOf, t.ReversalReason,
+		t.FXRateID, t.FXRate, string(t.Kind), fee).Scan(&t.Sequence, &t.CreatedAt); err != nil {
+		return err
+	}
+	t.CreatedAt = t.CreatedAt.UTC()
+	for i, e := range t.Entries {
+		if _, err := tx.ExecContext(ctx, `
+			insert into transaction_entries(transaction_id, leg, account_id, direction, currency, amount)
+			values ($1,$2,$3,$4,$5,$6)
+		`, t.ID, i, e.AccountID, string(e.Direction), e.Currency, e.Amount); err != nil {
+			return err
+		}
+	}
+	return insertOutboxEvent(ctx, tx, *t)
 }
 
 func (s *Store) ListTransactions(ctx context.Context, limit int, afterSeq uint64) ([]ledger.Transaction, uint64, error) {
 	if limit <= 0 || limit > 1000 {
 		limit = 100
 	}
+	return s.listTransactions(ctx, limit, afterSeq, 0)
+}
+
+// listTransactions reads the transactions visible under ctx with afterSeq <
+// sequence <= upTo in sequence order; upTo 0 leaves the range open.
+func (s *Store) listTransactions(ctx context.Context, limit int, afterSeq, upTo uint64) ([]ledger.Transaction, uint64, error) {
+	orgID, scoped := ledger.OrganizationScope(ctx)
 	rows, err := s.db.QueryContext(ctx, `
-		select id, created_at, from_account_id, to_account_id, currency, amount, sequence, coalesce(idempotency_key,'')
-		from transactions
+		select `+transactionColumns+`
+		from transactions t
 		where sequence > $1
+		  and ($5 = 0 or sequence <= $5)
+		  and (not $3 or exists (
+		    select 1 from accounts a
+		    where coalesce(a.organization_id,'') = $4
+		      and (a.id = t.from_account_id or a.id = t.to_account_id
+		           or a.id in (select e.account_id from transaction_entries e where e.transaction_id = t.id))
+		  ))
 		order by sequence asc
 		limit $2
-	`, afterSeq, limit)
+	`, afterSeq, limit, scoped, orgID, upTo)
+	if err != nil {
+		return nil, 0, err
+	}
+	return scanTransactions(ctx, s.db, rows)
+}
+
+// ListAccountTransactions reads one account's history. Single transfers are
+// found through idx_transactions_from / idx_transactions_to and batch legs
+// through idx_transaction_entries_account.
+func (s *Store) ListAccountTransactions(ctx context.Context, accountID string, f ledger.TransactionFilter) ([]ledger.Transaction, uint64, error) {
+	if f.Limit <= 0 || f.Limit > 1000 {
+		f.Limit = 100
+	}
+	var orgID string
+	err := s.db.QueryRowContext(ctx, `select coalesce(organization_id,'') from accounts where id=$1`, accountID).Scan(&orgID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !ledger.Visible(ctx, orgID)) {
+		return nil, 0, ledger.ErrNotFound
+	}
 	if err != nil {
 		return nil, 0, err
 	}
+
+	var from, to sql.NullTime
+	if !f.From.IsZero() {
+		from = sql.NullTime{Time: f.From, Valid: true}
+	}
+	if !f.To.IsZero() {
+		to = sql.NullTime{Time: f.To, Valid: true}
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		select `+transactionColumns+`
+		from transactions t
+		where t.id in (
+		    select id from transactions
+		    where from_account_id = $1 and $2 <> 'credit' and ($3 = '' or currency = $3)
+		    union all
+		    select id from transactions
+		    where to_account_id = $1 and $2 <> 'debit' and ($3 = '' or currency = $3)
+		    union all
+		    select transaction_id from transaction_entries
+		    where account_id = $1 and ($2 = '' or direction = $2) and ($3 = '' or currency = $3)
+		  )
+		  and t.sequence > $4
+		  and ($5::timestamptz is null or t.created_at >= $5)
+		  and ($6::timestamptz is null or t.created_at < $6)
+		order by t.sequence asc
+		limit $7
+	`, accountID, string(f.Direction), f.Currency, f.AfterSeq, from, to, f.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return scanTransactions(ctx, s.db, rows)
+}
+
+// --- helpers ---
+
+type accountLock struct {
+	orgID  string
+	status ledger.AccountStatus
+	typ    ledger.AccountType
+}
+
+// lockAccount takes the row lock on an account and returns its owner, status
+// and type.
+func lockAccount(ctx context.Context, tx *sql.Tx, id string) (accountLock, error) {
+	var lock accountLock
+	err := tx.QueryRowContext(ctx, `select coalesce(organization_id,''), status, type from accounts where id=$1 for update`, id).Scan(&lock.orgID, &lock.status, &lock.typ)
+	if errors.Is(err, sql.ErrNoRows) {
+		return accountLock{}, ledger.ErrNotFound
+	}
+	return lock, err
+}
+
+type balanceKey struct {
+	account  string
+	currency string
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// transactionColumns is the select list scanTransactions expects, over the
+// transactions table aliased as t.
+const transactionColumns = `t.id, t.created_at, coalesce(t.from_account_id,''), coalesce(t.to_account_id,''),
+	coalesce(t.currency,''), coalesce(t.amount,0), t.sequence, coalesce(t.idempotency_key,''),
+	coalesce(t.reversal_of,''), t.reversal_reason, t.reversed_amount, coalesce(t.fx_rate_id,''), coalesce(t.fx_rate::text,''), t.kind, t.fee`
+
+// scanTransactions reads transaction rows selected with transactionColumns,
+// closes rows and attaches batch legs. It returns the last sequence read as
+// the next cursor.
+func scanTransactions(ctx context.Context, q queryer, rows *sql.Rows) ([]ledger.Transaction, uint64, error) {
 	defer rows.Close()
 
-	var res []ledger.Transaction
-	var last uint64
+	var (
+		res      []ledger.Transaction
+		reversed []int64
+		last     uint64
+	)
 	for rows.Next() {
 		var tx ledger.Transaction
-		var idem string
-		if err := rows.Scan(&tx.ID, &tx.CreatedAt, &tx.FromAccountID, &tx.ToAccountID, &tx.Currency, &tx.Amount, &tx.Sequence, &idem); err != nil {
+		var rev int64
+		var fee []byte
+		if err := rows.Scan(&tx.ID, &tx.CreatedAt, &tx.FromAccountID, &tx.ToAccountID, &tx.Currency, &tx.Amount, &tx.Sequence,
+			&tx.IdempotencyKey, &tx.ReversalOf, &tx.ReversalReason, &rev, &tx.FXRateID, &tx.FXRate, &tx.Kind, &fee); err != nil {
 			return nil, 0, err
 		}
-		if idem != "" {
-			tx.IdempotencyKey = idem
+		if len(fee) > 0 {
+			tx.Fee = new(ledger.Fee)
+			if err := json.Unmarshal(fee, tx.Fee); err != nil {
+				return nil, 0, err
+			}
 		}
+		tx.FXRate = normalizeRate(tx.FXRate)
 		res = append(res, tx)
+		reversed = append(reversed, rev)
 		last = tx.Sequence
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	rows.Close()
+
+	batch := make([]*ledger.Transaction, 0)
+	for i := range res {
+		if res[i].FromAccountID == "" {
+			batch = append(batch, &res[i])
+		}
+	}
+	if err := attachEntries(ctx, q, batch); err != nil {
+		return nil, 0, err
+	}
+	// The reversal status of a batch depends on its legs.
+	for i := range res {
+		res[i].SetReversed(reversed[i])
+	}
 	return res, last, nil
 }
 
-// --- helpers ---
+// findTransaction loads the single transaction matching cond, or
+// ledger.ErrNotFound.
+func findTransaction(ctx context.Context, tx *sql.Tx, cond string, args ...any) (ledger.Transaction, error) {
+	rows, err := tx.QueryContext(ctx, `select `+transactionColumns+` from transactions t where `+cond, args...)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	res, _, err := scanTransactions(ctx, tx, rows)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	if len(res) == 0 {
+		return ledger.Transaction{}, ledger.ErrNotFound
+	}
+	return res[0], nil
+}
+
+// findByIdempotencyKey returns the transaction already recorded under key.
+func findByIdempotencyKey(ctx context.Context, tx *sql.Tx, key string) (ledger.Transaction, bool, error) {
+	if key == "" {
+		return ledger.Transaction{}, false, nil
+	}
+	t, err := findTransaction(ctx, tx, `t.idempotency_key = $1`, key)
+	if errors.Is(err, ledger.ErrNotFound) {
+		return ledger.Transaction{}, false, nil
+	}
+	if err != nil {
+		return ledger.Transaction{}, false, err
+	}
+	return t, true, nil
+}
+
+// attachEntries loads the legs of batch postings in leg order.
+func attachEntries(ctx context.Context, q queryer, txs []*ledger.Transaction) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	byID := make(map[string]*ledger.Transaction, len(txs))
+	idList := make([]string, 0, len(txs))
+	for _, t := range txs {
+		byID[t.ID] = t
+		idList = append(idList, t.ID)
+	}
+	rows, err := q.QueryContext(ctx, `
+		select transaction_id, account_id, direction, currency, amount
+		from transaction_entries
+		where transaction_id = any($1)
+		order by transaction_id, leg
+	`, idList)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			tid string
+			e   ledger.Entry
+			dir string
+		)
+		if err := rows.Scan(&tid, &e.AccountID, &dir, &e.Currency, &e.Amount); err != nil {
+			return err
+		}
+		e.Direction = ledger.Direction(dir)
+		if t, ok := byID[tid]; ok {
+			t.Entries = append(t.Entries, e)
+		}
+	}
+	return rows.Err()
+}
 func sorted(a, b string) []string {
 	if a <= b {
 		return []string{a, b}
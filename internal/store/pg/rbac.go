@@ -21,7 +21,7 @@ const (
 
 var _ auth.RBACStore = (*Store)(nil)
 
-func (s *Store) CreateOrganization(ctx context.Context, name string, metadata map[string]any) (auth.Organization, error) {
+func (s *Store) CreateOrganization(ctx context.Context, name, participantType string, metadata map[string]any) (auth.Organization, error) {
 	if s.db == nil {
 		return auth.Organization{}, errors.New("database connection unavailable")
 	}
@@ -41,11 +41,11 @@ func (s *Store) CreateOrganization(ctx context.Context, name string, metadata ma
 		rawMet []byte
 	)
 	row := s.db.QueryRowContext(ctx, `
-		insert into organizations (id, name, metadata)
-		values ($1, $2, $3)
-		returning id, name, metadata, created_at, updated_at
-	`, id, name, metaJSON)
-	if err := row.Scan(&org.ID, &org.Name, &rawMet, &org.CreatedAt, &org.UpdatedAt); err != nil {
+		insert into organizations (id, name, participant_type, metadata)
+		values ($1, $2, $3, $4)
+		returning id, name, participant_type, metadata, created_at, updated_at
+	`, id, name, participantType, metaJSON)
+	if err := row.Scan(&org.ID, &org.Name, &org.ParticipantType, &rawMet, &org.CreatedAt, &org.UpdatedAt); err != nil {
 		if pgErr, ok := maybePgError(err); ok && pgErr.Code == pgErrUniqueViolation {
 			return auth.Organization{}, auth.ErrConflict
 		}
@@ -65,7 +65,7 @@ func (s *Store) ListOrganizations(ctx context.Context) ([]auth.Organization, err
 		return nil, errors.New("database connection unavailable")
 	}
 	rows, err := s.db.QueryContext(ctx, `
-		select id, name, metadata, created_at, updated_at
+		select id, name, participant_type, metadata, created_at, updated_at
 		from organizations
 		order by name
 	`)
@@ -80,7 +80,7 @@ func (s *Store) ListOrganizations(ctx context.Context) ([]auth.Organization, err
 			org    auth.Organization
 			rawMet []byte
 		)
-		if err := rows.Scan(&org.ID, &org.Name, &rawMet, &org.CreatedAt, &org.UpdatedAt); err != nil {
+		if err := rows.Scan(&org.ID, &org.Name, &org.ParticipantType, &rawMet, &org.CreatedAt, &org.UpdatedAt); err != nil {
 			return nil, err
 		}
 		org.Metadata = map[string]any{}
@@ -106,10 +106,10 @@ func (s *Store) GetOrganization(ctx context.Context, id string) (auth.Organizati
 		rawMet []byte
 	)
 	err := s.db.QueryRowContext(ctx, `
-		select id, name, metadata, created_at, updated_at
+		select id, name, participant_type, metadata, created_at, updated_at
 		from organizations
 		where id = $1
-	`, id).Scan(&org.ID, &org.Name, &rawMet, &org.CreatedAt, &org.UpdatedAt)
+	`, id).Scan(&org.ID, &org.Name, &org.ParticipantType, &rawMet, &org.CreatedAt, &org.UpdatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return auth.Organization{}, auth.ErrNotFound
 	}
@@ -140,6 +140,11 @@ func (s *Store) UpdateOrganization(ctx context.Context, id string, upd auth.Orga
 		args = append(args, *upd.Name)
 		idx++
 	}
+	if upd.ParticipantType != nil {
+		setClauses = append(setClauses, fmt.Sprintf("participant_type = $%d", idx))
+		args = append(args, *upd.ParticipantType)
+		idx++
+	}
 	if upd.Metadata != nil {
 		bytes, err := json.Marshal(upd.Metadata)
 		if err != nil {
@@ -0,0 +1,85 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+
+	"qazna.org/internal/reconcile"
+)
+
+var _ reconcile.Source = (*Store)(nil)
+
+// ReadBooks implements reconcile.Source from one repeatable-read snapshot.
+//
+// Initial funding is not a transaction; CreateAccount records it as the
+// account's first balance snapshot, so the snapshots at each account's
+// lowest sequence are its opening balances. Accounts that predate snapshots
+// were given an opening snapshot by migration 0012 holding whatever their
+// history did not explain, which this check therefore cannot question.
+func (s *Store) ReadBooks(ctx context.Context, c *reconcile.Checker) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := readAmounts(ctx, tx, `
+		select s.account_id, s.currency, s.amount
+		from balance_snapshots s
+		join (select account_id, min(sequence) as sequence from balance_snapshots group by account_id) o
+		  on o.account_id = s.account_id and o.sequence = s.sequence
+	`, c.Open); err != nil {
+		return err
+	}
+
+	var after uint64
+	for {
+		rows, err := tx.QueryContext(ctx, `
+			select `+transactionColumns+`
+			from transactions t
+			where sequence > $1
+			order by sequence asc
+			limit 1000
+		`, after)
+		if err != nil {
+			return err
+		}
+		txs, last, err := scanTransactions(ctx, tx, rows)
+		if err != nil {
+			return err
+		}
+		for _, t := range txs {
+			if err := c.Post(t); err != nil {
+				return err
+			}
+		}
+		if len(txs) < 1000 {
+			break
+		}
+		after = last
+	}
+
+	if err := readAmounts(ctx, tx, `select account_id, currency, amount from balances`, c.Stored); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func readAmounts(ctx context.Context, tx *sql.Tx, query string, fn func(accountID, currency string, amount int64)) error {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id, currency string
+			amount       int64
+		)
+		if err := rows.Scan(&id, &currency, &amount); err != nil {
+			return err
+		}
+		fn(id, currency, amount)
+	}
+	return rows.Err()
+}
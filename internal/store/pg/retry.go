@@ -0,0 +1,44 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// maxPostingAttempts bounds how often a posting is tried before its last
+// serialization failure is returned.
+const maxPostingAttempts = 10
+
+// retrySerialization runs fn, one repeatable read transaction, until it
+// commits or fails for another reason than a serialization failure or a
+// deadlock. Postings fail so when a concurrent posting changed a row they
+// lock since their snapshot was taken: an account, a balance or a limit
+// shared by the accounts of an organization. The retry reads the committed
+// change, so it sees the usage and balances the failed attempt missed.
+func retrySerialization[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	backoff := 2 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		v, err := fn()
+		if !retryable(err) || attempt == maxPostingAttempts {
+			return v, err
+		}
+		select {
+		case <-ctx.Done():
+			return v, err
+		case <-time.After(backoff/2 + rand.N(backoff)):
+		}
+		backoff = min(2*backoff, 100*time.Millisecond)
+	}
+}
+
+// retryable reports whether err is a serialization failure (SQLSTATE 40001)
+// or a deadlock (40P01), after which the transaction can simply be run
+// again.
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
@@ -0,0 +1,63 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestRetrySerialization(t *testing.T) {
+	conflict := fmt.Errorf("commit: %w", &pgconn.PgError{Code: "40001"})
+	other := errors.New("boom")
+
+	tests := []struct {
+		name     string
+		failures int
+		err      error
+		calls    int
+		wantErr  error
+	}{
+		{"first attempt", 0, nil, 1, nil},
+		{"serialization failures", 3, conflict, 4, nil},
+		{"deadlock", 1, &pgconn.PgError{Code: "40P01"}, 2, nil},
+		{"other error", 1, other, 1, other},
+		{"gives up", maxPostingAttempts + 5, conflict, maxPostingAttempts, conflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			v, err := retrySerialization(context.Background(), func() (int, error) {
+				calls++
+				if calls <= tt.failures {
+					return 0, tt.err
+				}
+				return calls, nil
+			})
+			if calls != tt.calls {
+				t.Fatalf("calls = %d, want %d", calls, tt.calls)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && v != calls {
+				t.Fatalf("value = %d, want %d", v, calls)
+			}
+		})
+	}
+}
+
+func TestRetrySerializationStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	_, err := retrySerialization(ctx, func() (struct{}, error) {
+		calls++
+		return struct{}{}, &pgconn.PgError{Code: "40001"}
+	})
+	if calls != 1 || !retryable(err) {
+		t.Fatalf("calls = %d, err = %v", calls, err)
+	}
+}
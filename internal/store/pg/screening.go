@@ -0,0 +1,106 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"qazna.org/internal/ids"
+	"qazna.org/internal/screening"
+)
+
+var _ screening.ReviewQueue = (*Store)(nil)
+
+const reviewColumns = `id, status, from_account_id, to_account_id, currency, amount, coalesce(idempotency_key,''), result,
+	requested_by, requested_by_org, created_at, decided_by, decided_at, note, coalesce(transaction_id,'')`
+
+func (s *Store) CreateReview(ctx context.Context, rv screening.Review) (screening.Review, error) {
+	result, err := json.Marshal(rv.Result)
+	if err != nil {
+		return screening.Review{}, err
+	}
+	t := rv.Transfer
+	created, err := scanReview(s.db.QueryRowContext(ctx, `
+		insert into screening_reviews(id, from_account_id, to_account_id, currency, amount, idempotency_key, result, requested_by, requested_by_org)
+		values ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		on conflict (idempotency_key) where idempotency_key is not null do nothing
+		returning `+reviewColumns,
+		ids.New(), t.FromAccountID, t.ToAccountID, t.Currency, t.Amount, nullIfEmpty(t.IdempotencyKey), result, rv.RequestedBy, rv.RequestedByOrg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return scanReview(s.db.QueryRowContext(ctx, `select `+reviewColumns+` from screening_reviews where idempotency_key=$1`, t.IdempotencyKey))
+	}
+	return created, err
+}
+
+func (s *Store) GetReview(ctx context.Context, id string) (screening.Review, error) {
+	rv, err := scanReview(s.db.QueryRowContext(ctx, `select `+reviewColumns+` from screening_reviews where id=$1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return screening.Review{}, screening.ErrNotFound
+	}
+	return rv, err
+}
+
+func (s *Store) ListReviews(ctx context.Context, status screening.ReviewStatus) ([]screening.Review, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		select `+reviewColumns+` from screening_reviews
+		where $1 = '' or status = $1
+		order by created_at, id
+	`, string(status))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []screening.Review
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rv)
+	}
+	return res, rows.Err()
+}
+
+// DecideReview only updates a pending review, so of two concurrent
+// decisions the second finds it decided.
+func (s *Store) DecideReview(ctx context.Context, id string, d screening.ReviewDecision) (screening.Review, error) {
+	if err := d.Validate(); err != nil {
+		return screening.Review{}, err
+	}
+	rv, err := scanReview(s.db.QueryRowContext(ctx, `
+		update screening_reviews
+		set status=$2, decided_by=$3, decided_at=now(), note=$4, transaction_id=$5
+		where id=$1 and status='pending'
+		returning `+reviewColumns,
+		id, string(d.Status), d.By, d.Note, nullIfEmpty(d.TransactionID)))
+	if !errors.Is(err, sql.ErrNoRows) {
+		return rv, err
+	}
+	if _, err := s.GetReview(ctx, id); err != nil {
+		return screening.Review{}, err
+	}
+	return screening.Review{}, screening.ErrReviewDecided
+}
+
+func scanReview(row interface{ Scan(...any) error }) (screening.Review, error) {
+	var (
+		rv        screening.Review
+		result    []byte
+		decidedAt sql.NullTime
+	)
+	t := &rv.Transfer
+	if err := row.Scan(&rv.ID, &rv.Status, &t.FromAccountID, &t.ToAccountID, &t.Currency, &t.Amount, &t.IdempotencyKey, &result,
+		&rv.RequestedBy, &rv.RequestedByOrg, &rv.CreatedAt, &rv.DecidedBy, &decidedAt, &rv.Note, &rv.TransactionID); err != nil {
+		return screening.Review{}, err
+	}
+	if err := json.Unmarshal(result, &rv.Result); err != nil {
+		return screening.Review{}, err
+	}
+	rv.CreatedAt = rv.CreatedAt.UTC()
+	if decidedAt.Valid {
+		at := decidedAt.Time.UTC()
+		rv.DecidedAt = &at
+	}
+	return rv, nil
+}
@@ -0,0 +1,100 @@
+package pg
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"qazna.org/internal/ledger"
+)
+
+var _ ledger.Watcher = (*Store)(nil)
+
+// watchInterval is how often WatchTransactions looks for new postings once
+// it has caught up.
+const watchInterval = 250 * time.Millisecond
+
+// horizonCache holds the latest outbox horizon, so that watchers share one
+// instead of each share-locking the transactions table.
+type horizonCache struct {
+	take sync.Mutex // held by the watcher taking a new horizon
+
+	mu  sync.Mutex
+	seq uint64
+	at  time.Time
+}
+
+// record notes a horizon just taken. Horizons only grow, so an older one
+// recorded late does not replace a higher one.
+func (h *horizonCache) record(seq uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seq, h.at = max(h.seq, seq), time.Now()
+}
+
+// recent returns the horizon if it was taken within watchInterval.
+func (h *horizonCache) recent() (uint64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.seq, !h.at.IsZero() && time.Since(h.at) < watchInterval
+}
+
+// watchHorizon returns a horizon taken within watchInterval, by the outbox
+// relay or by another watcher, and takes a new one only when there is none.
+// However many watchers poll, the table is locked at most once per interval
+// on their behalf, and not at all while the relay keeps the horizon fresh.
+func (s *Store) watchHorizon(ctx context.Context) (uint64, error) {
+	if seq, ok := s.horizon.recent(); ok {
+		return seq, nil
+	}
+	s.horizon.take.Lock()
+	defer s.horizon.take.Unlock()
+	if seq, ok := s.horizon.recent(); ok {
+		return seq, nil
+	}
+	return s.OutboxHorizon(ctx)
+}
+
+// WatchTransactions implements ledger.Watcher by polling. Reads stop at the
+// outbox horizon, so a posting that commits after one with a higher sequence
+// is still delivered, in order. The horizon is only looked up when the table
+// has moved past the cursor, and is shared with other watchers and the
+// outbox relay (see watchHorizon), so watchers do not contend with writers.
+func (s *Store) WatchTransactions(ctx context.Context, after uint64, fn func(ledger.Transaction) error) error {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		var latest uint64
+		if err := s.db.QueryRowContext(ctx, `select coalesce(max(sequence),0) from transactions`).Scan(&latest); err != nil {
+			return err
+		}
+		if latest > after {
+			horizon, err := s.watchHorizon(ctx)
+			if err != nil {
+				return err
+			}
+			for after < horizon {
+				txs, _, err := s.listTransactions(ctx, 1000, after, horizon)
+				if err != nil {
+					return err
+				}
+				for _, tx := range txs {
+					if err := fn(tx); err != nil {
+						return err
+					}
+					after = tx.Sequence
+				}
+				if len(txs) < 1000 {
+					// The rest up to the horizon is invisible under ctx or
+					// belongs to rolled-back postings.
+					after = horizon
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package pg
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchHorizonShared(t *testing.T) {
+	// Without a database, only a shared horizon can be returned.
+	s := &Store{}
+	if _, ok := s.horizon.recent(); ok {
+		t.Fatal("expected no horizon before one is taken")
+	}
+	s.horizon.record(42)
+	s.horizon.record(40) // taken earlier, recorded late
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if seq, err := s.watchHorizon(context.Background()); err != nil || seq != 42 {
+				t.Errorf("watchHorizon = %d, %v; want 42", seq, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.horizon.mu.Lock()
+	s.horizon.at = time.Now().Add(-watchInterval)
+	s.horizon.mu.Unlock()
+	if _, ok := s.horizon.recent(); ok {
+		t.Fatal("expected a horizon older than the watch interval to be stale")
+	}
+}
@@ -1,10 +1,10 @@
 -- Seed data for demonstration environments.
 
-insert into organizations (id, name, metadata)
+insert into organizations (id, name, participant_type, metadata)
 values
-  ('org-central-kaz', 'National Bank of Qazakhstan', jsonb_build_object('region', 'Eurasia')),
-  ('org-central-sng', 'Union Reserve Cooperative', jsonb_build_object('region', 'CIS')),
-  ('org-monetary-eu', 'European Monetary Authority', jsonb_build_object('region', 'EU'))
+  ('org-central-kaz', 'National Bank of Qazakhstan', 'sovereign', jsonb_build_object('region', 'Eurasia')),
+  ('org-central-sng', 'Union Reserve Cooperative', 'institution', jsonb_build_object('region', 'CIS')),
+  ('org-monetary-eu', 'European Monetary Authority', 'sovereign', jsonb_build_object('region', 'EU'))
 on conflict (id) do nothing;
 
 insert into users (id, organization_id, email, password_hash)
@@ -16,13 +16,25 @@ on conflict (id) do nothing;
 insert into roles (id, organization_id, name, description)
 values
   ('role-sysadmin', 'org-central-kaz', 'system_admin', 'Full platform administration'),
-  ('role-supervisor', 'org-monetary-eu', 'supervisor', 'Comprehensive oversight role')
+  ('role-supervisor', 'org-monetary-eu', 'supervisor', 'Comprehensive oversight role'),
+  ('role-bank-operator', 'org-central-sng', 'bank_operator', 'Executes transfers without administration rights')
 on conflict (id) do nothing;
 
 insert into permissions (id, key, description)
 values
   ('perm-ledger-transfer', 'ledger.transfer', 'Authorize ledger transfers'),
   ('perm-ledger-account', 'ledger.account.create', 'Authorize account creation'),
+  ('perm-ledger-read', 'ledger.read', 'Read ledger accounts, balances and transactions'),
+  ('perm-ledger-cross-org', 'ledger.cross_org', 'Access ledger accounts of other organizations'),
+  ('perm-ledger-account-status', 'ledger.account.status', 'Freeze, unfreeze and close ledger accounts'),
+  ('perm-ledger-reverse', 'ledger.reverse', 'Reverse ledger transactions'),
+  ('perm-ledger-fx', 'ledger.fx.manage', 'Manage FX rates and liquidity accounts'),
+  ('perm-ledger-currency', 'ledger.currency.manage', 'Manage the currency registry'),
+  ('perm-ledger-issuance', 'ledger.issuance.manage', 'Mint and burn money and designate issuer accounts'),
+  ('perm-ledger-fee', 'ledger.fee.manage', 'Manage transfer fee schedules'),
+  ('perm-ledger-limits', 'ledger.limits.manage', 'Manage transfer limits'),
+  ('perm-screening-review', 'screening.review', 'Review transfers held by screening'),
+  ('perm-netting-manage', 'netting.manage', 'Open, close, settle and cancel netting cycles'),
   ('perm-observe', 'platform.observe', 'View audit and observability data'),
   ('perm-auth-org', 'auth.manage_organizations', 'Manage organizations'),
   ('perm-auth-users', 'auth.manage_users', 'Manage organization users'),
@@ -33,12 +45,28 @@ on conflict (id) do nothing;
 insert into role_permissions (role_id, permission_id) values
   ('role-sysadmin', 'perm-ledger-transfer'),
   ('role-sysadmin', 'perm-ledger-account'),
+  ('role-sysadmin', 'perm-ledger-read'),
+  ('role-sysadmin', 'perm-ledger-cross-org'),
+  ('role-sysadmin', 'perm-ledger-account-status'),
+  ('role-sysadmin', 'perm-ledger-reverse'),
+  ('role-sysadmin', 'perm-ledger-fx'),
+  ('role-sysadmin', 'perm-ledger-currency'),
+  ('role-sysadmin', 'perm-ledger-issuance'),
+  ('role-sysadmin', 'perm-ledger-fee'),
+  ('role-sysadmin', 'perm-ledger-limits'),
+  ('role-sysadmin', 'perm-screening-review'),
+  ('role-sysadmin', 'perm-netting-manage'),
   ('role-sysadmin', 'perm-observe'),
   ('role-sysadmin', 'perm-auth-org'),
   ('role-sysadmin', 'perm-auth-users'),
   ('role-sysadmin', 'perm-auth-roles'),
   ('role-sysadmin', 'perm-auth-perms'),
-  ('role-supervisor', 'perm-observe')
+  ('role-supervisor', 'perm-observe'),
+  ('role-supervisor', 'perm-ledger-read'),
+  ('role-supervisor', 'perm-ledger-account-status'),
+  ('role-supervisor', 'perm-ledger-reverse'),
+  ('role-bank-operator', 'perm-ledger-transfer'),
+  ('role-bank-operator', 'perm-ledger-read')
 on conflict do nothing;
 
 insert into user_roles (user_id, role_id, organization_id) values
@@ -46,10 +74,10 @@ insert into user_roles (user_id, role_id, organization_id) values
   ('usr-ops-eu', 'role-supervisor', 'org-monetary-eu')
 on conflict do nothing;
 
-insert into accounts (id) values
-  ('acct-sovereign-001'),
-  ('acct-sovereign-002'),
-  ('acct-sovereign-003')
+insert into accounts (id, organization_id, type, display_name) values
+  ('acct-sovereign-001', 'org-central-kaz', 'reserve', 'NBQ sovereign reserve'),
+  ('acct-sovereign-002', 'org-central-sng', 'reserve', 'URC sovereign reserve'),
+  ('acct-sovereign-003', 'org-monetary-eu', 'reserve', 'EMA sovereign reserve')
 on conflict do nothing;
 
 insert into balances(account_id, currency, amount) values
@@ -63,3 +91,18 @@ values
   ('txn-demo-0001', 'acct-sovereign-001', 'acct-sovereign-002', 'QZN', 500000000, 'demo-0001'),
   ('txn-demo-0002', 'acct-sovereign-002', 'acct-sovereign-003', 'USD', 250000000, 'demo-0002')
 on conflict (id) do nothing;
+
+-- Opening snapshots so point-in-time balances reproduce the seeded amounts.
+insert into balance_snapshots(account_id, currency, sequence, amount)
+select b.account_id, b.currency, 0, b.amount - coalesce((
+  select sum(d.delta) from (
+    select -t.amount as delta from transactions t
+    where t.from_account_id = b.account_id and t.currency = b.currency
+    union all
+    select t.amount from transactions t
+    where t.to_account_id = b.account_id and t.currency = b.currency
+  ) d
+), 0)
+from balances b
+where b.account_id like 'acct-sovereign-%'
+on conflict (account_id, currency, sequence) do update set amount = excluded.amount;
@@ -0,0 +1,9 @@
+drop index if exists idx_transaction_entries_account;
+drop table if exists transaction_entries;
+
+delete from transactions where from_account_id is null;
+
+alter table transactions alter column amount set not null;
+alter table transactions alter column currency set not null;
+alter table transactions alter column to_account_id set not null;
+alter table transactions alter column from_account_id set not null;
@@ -0,0 +1,19 @@
+-- Multi-leg batch postings: a batch is one transactions row whose legs live in
+-- transaction_entries; the single-transfer columns stay empty for batches.
+
+alter table transactions alter column from_account_id drop not null;
+alter table transactions alter column to_account_id drop not null;
+alter table transactions alter column currency drop not null;
+alter table transactions alter column amount drop not null;
+
+create table if not exists transaction_entries (
+  transaction_id text not null references transactions(id) on delete cascade,
+  leg integer not null,
+  account_id text not null references accounts(id),
+  direction text not null check (direction in ('debit', 'credit')),
+  currency text not null,
+  amount bigint not null check (amount > 0),
+  primary key (transaction_id, leg)
+);
+
+create index if not exists idx_transaction_entries_account on transaction_entries(account_id);
@@ -0,0 +1,6 @@
+drop index if exists idx_audit_log_occurred;
+drop index if exists idx_audit_log_seq;
+
+alter table audit_log drop column if exists hash;
+alter table audit_log drop column if exists prev_hash;
+alter table audit_log drop column if exists seq;
@@ -0,0 +1,9 @@
+-- Hash-chained audit trail: every row stores its position in the chain, the
+-- previous row's hash and its own hash (see internal/audit).
+
+alter table audit_log add column if not exists seq bigint;
+alter table audit_log add column if not exists prev_hash text not null default '';
+alter table audit_log add column if not exists hash text not null default '';
+
+create unique index if not exists idx_audit_log_seq on audit_log(seq);
+create index if not exists idx_audit_log_occurred on audit_log(occurred_at);
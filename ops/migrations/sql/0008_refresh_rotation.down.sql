@@ -0,0 +1,6 @@
+drop index if exists idx_refresh_tokens_family;
+drop index if exists idx_refresh_tokens_hash;
+
+alter table refresh_tokens drop column if exists revoked_at;
+alter table refresh_tokens drop column if exists replaced_by;
+alter table refresh_tokens drop column if exists family_id;
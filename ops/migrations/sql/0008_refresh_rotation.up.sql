@@ -0,0 +1,12 @@
+-- Rotating refresh tokens: each token belongs to a family started at login.
+-- Presenting an already-rotated token revokes the whole family.
+
+alter table refresh_tokens add column if not exists family_id text;
+alter table refresh_tokens add column if not exists replaced_by text;
+alter table refresh_tokens add column if not exists revoked_at timestamptz;
+
+update refresh_tokens set family_id = id where family_id is null;
+alter table refresh_tokens alter column family_id set not null;
+
+create unique index if not exists idx_refresh_tokens_hash on refresh_tokens(token_hash);
+create index if not exists idx_refresh_tokens_family on refresh_tokens(family_id);
@@ -0,0 +1 @@
+delete from permissions where key = 'ledger.read';
@@ -0,0 +1,14 @@
+-- Ledger reads get their own permission so operators and observers can be
+-- granted them without account creation rights.
+
+insert into permissions (id, key, description)
+values ('perm-ledger-read', 'ledger.read', 'Read ledger accounts, balances and transactions')
+on conflict (key) do nothing;
+
+insert into role_permissions (role_id, permission_id)
+select distinct rp.role_id, p_read.id
+from role_permissions rp
+join permissions p on p.id = rp.permission_id
+join permissions p_read on p_read.key = 'ledger.read'
+where p.key in ('ledger.transfer', 'ledger.account.create', 'platform.observe')
+on conflict do nothing;
@@ -0,0 +1,5 @@
+delete from permissions where key = 'ledger.cross_org';
+
+drop index if exists idx_accounts_org;
+
+alter table accounts drop column if exists organization_id;
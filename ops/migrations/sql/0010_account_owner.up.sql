@@ -0,0 +1,11 @@
+-- Accounts belong to an organization. Existing accounts stay unowned and are
+-- only reachable by callers holding ledger.cross_org or without an
+-- organization.
+
+alter table accounts add column if not exists organization_id text references organizations(id);
+
+create index if not exists idx_accounts_org on accounts(organization_id);
+
+insert into permissions (id, key, description)
+values ('perm-ledger-cross-org', 'ledger.cross_org', 'Access ledger accounts of other organizations')
+on conflict (key) do nothing;
@@ -0,0 +1,9 @@
+delete from permissions where key = 'ledger.account.status';
+
+drop index if exists idx_accounts_external_ref;
+
+alter table accounts drop column if exists status_changed_at;
+alter table accounts drop column if exists status;
+alter table accounts drop column if exists external_ref;
+alter table accounts drop column if exists display_name;
+alter table accounts drop column if exists type;
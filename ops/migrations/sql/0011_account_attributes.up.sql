@@ -0,0 +1,16 @@
+-- Account classification and lifecycle. Frozen accounts cannot be debited;
+-- closed accounts cannot be debited or credited.
+
+alter table accounts add column if not exists type text not null default 'settlement'
+  check (type in ('reserve', 'settlement', 'fee', 'suspense'));
+alter table accounts add column if not exists display_name text not null default '';
+alter table accounts add column if not exists external_ref text;
+alter table accounts add column if not exists status text not null default 'active'
+  check (status in ('active', 'frozen', 'closed'));
+alter table accounts add column if not exists status_changed_at timestamptz;
+
+create index if not exists idx_accounts_external_ref on accounts(organization_id, external_ref) where external_ref is not null;
+
+insert into permissions (id, key, description)
+values ('perm-ledger-account-status', 'ledger.account.status', 'Freeze, unfreeze and close ledger accounts')
+on conflict (key) do nothing;
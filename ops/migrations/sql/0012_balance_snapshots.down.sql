@@ -0,0 +1,3 @@
+drop index if exists idx_transactions_created_at;
+
+drop table if exists balance_snapshots;
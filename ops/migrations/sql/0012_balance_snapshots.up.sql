@@ -0,0 +1,34 @@
+-- Point-in-time balances. A snapshot records the balance of an account in one
+-- currency right after the transaction with the given sequence; historical
+-- queries start from the latest snapshot at or before the requested sequence
+-- and replay the transactions after it.
+
+create table if not exists balance_snapshots (
+  account_id text not null references accounts(id) on delete cascade,
+  currency text not null,
+  sequence bigint not null,
+  amount bigint not null,
+  taken_at timestamptz not null default now(),
+  primary key (account_id, currency, sequence)
+);
+
+create index if not exists idx_transactions_created_at on transactions(created_at);
+
+-- Initial funding is not a transaction, so existing accounts get an opening
+-- snapshot at sequence 0 holding whatever their history does not explain.
+insert into balance_snapshots(account_id, currency, sequence, amount)
+select b.account_id, b.currency, 0, b.amount - coalesce((
+  select sum(d.delta) from (
+    select -t.amount as delta from transactions t
+    where t.from_account_id = b.account_id and t.currency = b.currency
+    union all
+    select t.amount from transactions t
+    where t.to_account_id = b.account_id and t.currency = b.currency
+    union all
+    select case when e.direction = 'debit' then -e.amount else e.amount end
+    from transaction_entries e
+    where e.account_id = b.account_id and e.currency = b.currency
+  ) d
+), 0)
+from balances b
+on conflict (account_id, currency, sequence) do nothing;
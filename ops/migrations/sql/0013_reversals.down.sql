@@ -0,0 +1,7 @@
+delete from permissions where key = 'ledger.reverse';
+
+drop index if exists idx_transactions_reversal_of;
+
+alter table transactions drop column if exists reversed_amount;
+alter table transactions drop column if exists reversal_reason;
+alter table transactions drop column if exists reversal_of;
@@ -0,0 +1,14 @@
+-- Reversals are ordinary transactions with the legs of the original swapped.
+-- reversal_of links them to the original, whose reversed_amount tracks how
+-- much has been returned so far.
+
+alter table transactions add column if not exists reversal_of text references transactions(id);
+alter table transactions add column if not exists reversal_reason text not null default '';
+alter table transactions add column if not exists reversed_amount bigint not null default 0
+  check (reversed_amount >= 0);
+
+create index if not exists idx_transactions_reversal_of on transactions(reversal_of) where reversal_of is not null;
+
+insert into permissions (id, key, description)
+values ('perm-ledger-reverse', 'ledger.reverse', 'Reverse ledger transactions')
+on conflict (key) do nothing;
@@ -0,0 +1,3 @@
+drop index if exists idx_holds_pending;
+
+drop table if exists holds;
@@ -0,0 +1,21 @@
+-- Two-phase transfers. A pending hold reserves funds of the source account:
+-- it lowers the available balance (balance minus active holds) without
+-- touching balances. Capturing posts a regular transaction; holds past
+-- expires_at stop counting even before their status is updated.
+
+create table if not exists holds (
+  id text primary key,
+  from_account_id text not null references accounts(id),
+  to_account_id text not null references accounts(id),
+  currency text not null,
+  amount bigint not null check (amount > 0),
+  captured_amount bigint not null default 0 check (captured_amount >= 0 and captured_amount <= amount),
+  status text not null default 'pending' check (status in ('pending', 'captured', 'voided', 'expired')),
+  transaction_id text references transactions(id),
+  idempotency_key text unique,
+  created_at timestamptz not null default now(),
+  expires_at timestamptz not null,
+  resolved_at timestamptz
+);
+
+create index if not exists idx_holds_pending on holds(from_account_id, currency, expires_at) where status = 'pending';
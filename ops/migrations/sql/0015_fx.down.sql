@@ -0,0 +1,10 @@
+delete from permissions where key = 'ledger.fx.manage';
+
+alter table transactions drop column if exists fx_rate;
+alter table transactions drop column if exists fx_rate_id;
+
+drop table if exists fx_liquidity_accounts;
+
+drop index if exists idx_fx_rates_pair;
+
+drop table if exists fx_rates;
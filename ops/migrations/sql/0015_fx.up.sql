@@ -0,0 +1,32 @@
+-- FX conversion transfers. fx_rates holds quotes in minor units of quote per
+-- minor unit of base, each valid over [valid_from, valid_to); the latest
+-- valid_from wins when windows overlap. FX transfers settle each currency
+-- against the liquidity account registered for it and record the applied
+-- rate on the transaction.
+
+create table if not exists fx_rates (
+  id text primary key,
+  base text not null,
+  quote text not null,
+  rate numeric(24,12) not null check (rate > 0),
+  valid_from timestamptz not null,
+  valid_to timestamptz,
+  created_at timestamptz not null default now(),
+  check (base <> quote),
+  check (valid_to is null or valid_to >= valid_from)
+);
+
+create index if not exists idx_fx_rates_pair on fx_rates(base, quote, valid_from desc);
+
+create table if not exists fx_liquidity_accounts (
+  currency text primary key,
+  account_id text not null references accounts(id),
+  updated_at timestamptz not null default now()
+);
+
+alter table transactions add column if not exists fx_rate_id text references fx_rates(id);
+alter table transactions add column if not exists fx_rate numeric(24,12);
+
+insert into permissions (id, key, description)
+values ('perm-ledger-fx', 'ledger.fx.manage', 'Manage FX rates and liquidity accounts')
+on conflict (key) do nothing;
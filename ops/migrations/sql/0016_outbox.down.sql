@@ -0,0 +1,3 @@
+drop table if exists outbox_cursors;
+
+drop table if exists outbox;
@@ -0,0 +1,18 @@
+-- Transactional outbox. Every posting writes one row in the same database
+-- transaction as its ledger changes, keyed by the transaction sequence, so
+-- events exist exactly when the posting committed. Dispatchers deliver rows
+-- in sequence order and record per-consumer progress in outbox_cursors.
+
+create table if not exists outbox (
+  sequence bigint primary key,
+  transaction_id text not null references transactions(id),
+  event_type text not null,
+  payload jsonb not null,
+  created_at timestamptz not null default now()
+);
+
+create table if not exists outbox_cursors (
+  consumer text primary key,
+  sequence bigint not null default 0,
+  updated_at timestamptz not null default now()
+);
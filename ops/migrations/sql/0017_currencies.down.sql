@@ -0,0 +1,3 @@
+delete from permissions where key = 'ledger.currency.manage';
+
+drop table if exists currencies;
@@ -0,0 +1,19 @@
+-- Currency registry. ISO 4217 currencies and QZN are built into the ledger;
+-- rows here override them or add digital currencies. exponent is the number
+-- of minor-unit digits, min_amount/max_amount bound a single posting in minor
+-- units (0 = no bound), and disabled currencies reject new postings.
+
+create table if not exists currencies (
+  code text primary key check (code ~ '^[A-Z][A-Z0-9]{2,7}$'),
+  name text not null default '',
+  exponent int not null check (exponent between 0 and 18),
+  enabled boolean not null default true,
+  min_amount bigint not null default 0 check (min_amount >= 0),
+  max_amount bigint not null default 0 check (max_amount >= 0),
+  updated_at timestamptz not null default now(),
+  check (max_amount = 0 or min_amount <= max_amount)
+);
+
+insert into permissions (id, key, description)
+values ('perm-ledger-currency', 'ledger.currency.manage', 'Manage the currency registry')
+on conflict (key) do nothing;
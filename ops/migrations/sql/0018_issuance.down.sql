@@ -0,0 +1,11 @@
+delete from permissions where key = 'ledger.issuance.manage';
+
+drop index if exists idx_transactions_kind;
+
+alter table transactions drop column if exists kind;
+
+drop table if exists issuer_accounts;
+
+alter table accounts drop constraint if exists accounts_type_check;
+alter table accounts add constraint accounts_type_check
+  check (type in ('reserve', 'settlement', 'fee', 'suspense'));
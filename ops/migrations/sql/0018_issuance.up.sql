@@ -0,0 +1,24 @@
+-- Governed issuance. Money enters and leaves circulation only through mint
+-- and burn transactions between a currency's issuer account and its
+-- issuance account, which the ledger creates on the first mint and whose
+-- balance is minus the amount issued. transactions.kind marks mints and
+-- burns; ordinary transfers leave it empty.
+
+alter table accounts drop constraint if exists accounts_type_check;
+alter table accounts add constraint accounts_type_check
+  check (type in ('reserve', 'settlement', 'fee', 'suspense', 'issuance'));
+
+create table if not exists issuer_accounts (
+  currency text primary key,
+  account_id text not null references accounts(id),
+  updated_at timestamptz not null default now()
+);
+
+alter table transactions add column if not exists kind text not null default ''
+  check (kind in ('', 'mint', 'burn'));
+
+create index if not exists idx_transactions_kind on transactions(kind) where kind <> '';
+
+insert into permissions (id, key, description)
+values ('perm-ledger-issuance', 'ledger.issuance.manage', 'Mint and burn money and designate issuer accounts')
+on conflict (key) do nothing;
@@ -0,0 +1,7 @@
+delete from permissions where key = 'ledger.fee.manage';
+
+alter table transactions drop column if exists fee;
+
+drop table if exists fee_schedules;
+
+alter table organizations drop column if exists participant_type;
@@ -0,0 +1,24 @@
+-- Transfer fees. Organizations carry the participant type the fee model
+-- classifies payers by. fee_schedules holds the immutable, versioned fee
+-- schedules; the highest version already in effect applies. transactions.fee
+-- records the breakdown of the fee charged on a transfer.
+
+alter table organizations add column if not exists participant_type text not null default 'corporate'
+  check (participant_type in ('sovereign', 'institution', 'corporate', 'retail'));
+
+create table if not exists fee_schedules (
+  version integer primary key check (version > 0),
+  rates jsonb not null default '{}'::jsonb,
+  alpha numeric(24,12) not null check (alpha between 0.8 and 1.2),
+  beta numeric(24,12) not null check (beta between 0.9 and 1.3),
+  rounding text not null check (rounding in ('half_up', 'half_even', 'down', 'up')),
+  account_id text not null references accounts(id),
+  effective_from timestamptz not null,
+  created_at timestamptz not null default now()
+);
+
+alter table transactions add column if not exists fee jsonb;
+
+insert into permissions (id, key, description)
+values ('perm-ledger-fee', 'ledger.fee.manage', 'Manage transfer fee schedules')
+on conflict (key) do nothing;
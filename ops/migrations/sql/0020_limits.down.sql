@@ -0,0 +1,3 @@
+delete from permissions where key = 'ledger.limits.manage';
+
+drop table if exists transfer_limits;
@@ -0,0 +1,21 @@
+-- Transfer limits. Each row caps what an account, or every account of an
+-- organization, may send in one currency: a single posting, the volume and
+-- count since midnight UTC and within a rolling window. Zero leaves a cap off.
+
+create table if not exists transfer_limits (
+  scope text not null check (scope in ('account', 'organization')),
+  subject text not null,
+  currency text not null,
+  max_amount bigint not null default 0 check (max_amount >= 0),
+  daily_amount bigint not null default 0 check (daily_amount >= 0),
+  daily_count bigint not null default 0 check (daily_count >= 0),
+  window_amount bigint not null default 0 check (window_amount >= 0),
+  window_count bigint not null default 0 check (window_count >= 0),
+  window_seconds bigint not null default 0 check (window_seconds between 0 and 2678400),
+  updated_at timestamptz not null default now(),
+  primary key (scope, subject, currency)
+);
+
+insert into permissions (id, key, description)
+values ('perm-ledger-limits', 'ledger.limits.manage', 'Manage transfer limits')
+on conflict (key) do nothing;
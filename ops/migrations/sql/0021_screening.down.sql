@@ -0,0 +1,3 @@
+delete from permissions where key = 'screening.review';
+
+drop table if exists screening_reviews;
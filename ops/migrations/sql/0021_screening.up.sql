@@ -0,0 +1,29 @@
+-- Transfer screening. Transfers a screener holds for review wait in
+-- screening_reviews until a compliance officer approves them, which commits
+-- the transfer and records its transaction, or rejects them. A retried
+-- transfer finds its review by idempotency key instead of queuing another.
+
+create table if not exists screening_reviews (
+  id text primary key,
+  status text not null default 'pending' check (status in ('pending', 'approved', 'rejected')),
+  from_account_id text not null references accounts(id),
+  to_account_id text not null references accounts(id),
+  currency text not null,
+  amount bigint not null check (amount > 0),
+  idempotency_key text,
+  result jsonb not null,
+  requested_by text not null default '',
+  requested_by_org text not null default '',
+  created_at timestamptz not null default now(),
+  decided_by text not null default '',
+  decided_at timestamptz,
+  note text not null default '',
+  transaction_id text references transactions(id)
+);
+
+create unique index if not exists idx_screening_reviews_idempotency_key on screening_reviews(idempotency_key) where idempotency_key is not null;
+create index if not exists idx_screening_reviews_status on screening_reviews(status, created_at);
+
+insert into permissions (id, key, description)
+values ('perm-screening-review', 'screening.review', 'Review transfers held by screening')
+on conflict (key) do nothing;
@@ -0,0 +1,4 @@
+delete from permissions where key = 'netting.manage';
+
+drop table if exists netting_payments;
+drop table if exists netting_cycles;
@@ -0,0 +1,43 @@
+-- Deferred net settlement. Payments submitted to an open netting cycle wait
+-- in netting_payments until the cycle is settled, when each participant's
+-- net position is posted against the cycle's settlement account in one
+-- transaction. Payments removed for want of liquidity keep their reason.
+
+create table if not exists netting_cycles (
+  id text primary key,
+  status text not null default 'open' check (status in ('open', 'closed', 'settling', 'settled', 'cancelled')),
+  settlement_account_id text not null references accounts(id),
+  opened_by text not null default '',
+  opened_by_org text not null default '',
+  created_at timestamptz not null default now(),
+  closed_at timestamptz,
+  settled_at timestamptz,
+  cancelled_at timestamptz,
+  transaction_id text references transactions(id),
+  note text not null default ''
+);
+
+create index if not exists idx_netting_cycles_status on netting_cycles(status, created_at);
+
+create table if not exists netting_payments (
+  id text primary key,
+  cycle_id text not null references netting_cycles(id),
+  seq bigserial not null,
+  from_account_id text not null references accounts(id),
+  to_account_id text not null references accounts(id),
+  currency text not null,
+  amount bigint not null check (amount > 0),
+  idempotency_key text,
+  status text not null default 'pending' check (status in ('pending', 'settled', 'removed', 'cancelled')),
+  reason text not null default '',
+  submitted_by text not null default '',
+  submitted_by_org text not null default '',
+  created_at timestamptz not null default now()
+);
+
+create unique index if not exists idx_netting_payments_idempotency_key on netting_payments(idempotency_key) where idempotency_key is not null;
+create index if not exists idx_netting_payments_cycle on netting_payments(cycle_id, seq);
+
+insert into permissions (id, key, description)
+values ('perm-netting-manage', 'netting.manage', 'Open, close, settle and cancel netting cycles')
+on conflict (key) do nothing;
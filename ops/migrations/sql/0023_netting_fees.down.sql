@@ -0,0 +1 @@
+alter table netting_payments drop column if exists fee;
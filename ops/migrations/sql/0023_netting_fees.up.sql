@@ -0,0 +1,4 @@
+-- The fee fixed for a netted payment when it was submitted, settled with
+-- the payer's net position.
+
+alter table netting_payments add column if not exists fee jsonb;
@@ -4,6 +4,8 @@ set -euo pipefail
 
 BASE_URL="${BASE_URL:-http://localhost:8080}"
 READY_TIMEOUT="${READY_TIMEOUT:-60}"
+: "${QAZNA_EMAIL:?QAZNA_EMAIL must be set}"
+: "${QAZNA_PASSWORD:?QAZNA_PASSWORD must be set}"
 
 deadline=$((SECONDS + READY_TIMEOUT))
 while true; do
@@ -19,7 +21,7 @@ done
 
 token=$(curl -sf -X POST "${BASE_URL}/v1/auth/token" \
 	-H 'Content-Type: application/json' \
-	-d '{"user":"demo","roles":["admin"]}' | jq -r '.token')
+	-d "$(jq -nc --arg e "${QAZNA_EMAIL}" --arg p "${QAZNA_PASSWORD}" '{grant_type:"password",email:$e,password:$p}')" | jq -r '.token')
 
 if [[ -z "${token}" || "${token}" == "null" ]]; then
 	echo "failed to obtain token" >&2